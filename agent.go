@@ -137,6 +137,10 @@ type Hooks struct {
 	// PostGeneration hooks are called after the LLM generation loop completes.
 	PostGeneration []PostGenerationHook
 
+	// PreToolBatch hooks are called once per turn, before any of the turn's
+	// tool calls execute.
+	PreToolBatch []PreToolBatchHook
+
 	// PreToolUse hooks are called before each tool execution.
 	PreToolUse []PreToolUseHook
 
@@ -169,22 +173,26 @@ type Hooks struct {
 	PostBackgroundToolUse []PostBackgroundToolUseHook
 }
 
-// cloneSlices returns a copy of h with every hook slice cloned, so appends
-// to the copy never write into the original slices' backing arrays. Used by
-// NewAgent before merging extension hooks, so a caller reusing one
-// AgentOptions value across multiple NewAgent calls doesn't get
-// cross-contaminated hook registrations.
-func (h Hooks) cloneSlices() Hooks {
-	h.SessionStart = slices.Clone(h.SessionStart)
-	h.PreGeneration = slices.Clone(h.PreGeneration)
-	h.PostGeneration = slices.Clone(h.PostGeneration)
-	h.PreToolUse = slices.Clone(h.PreToolUse)
-	h.PostToolUse = slices.Clone(h.PostToolUse)
-	h.PostToolUseFailure = slices.Clone(h.PostToolUseFailure)
-	h.Stop = slices.Clone(h.Stop)
-	h.PreIteration = slices.Clone(h.PreIteration)
-	h.OnSuspend = slices.Clone(h.OnSuspend)
-	h.PostBackgroundToolUse = slices.Clone(h.PostBackgroundToolUse)
+// concatHooks returns the hooks of each group in order, in newly allocated
+// slices, so the result never writes into the groups' backing arrays. Used by
+// NewAgent to merge extension hooks, so a caller reusing one AgentOptions
+// value across multiple NewAgent calls doesn't get cross-contaminated hook
+// registrations.
+func concatHooks(groups ...Hooks) Hooks {
+	var h Hooks
+	for _, g := range groups {
+		h.SessionStart = slices.Concat(h.SessionStart, g.SessionStart)
+		h.PreGeneration = slices.Concat(h.PreGeneration, g.PreGeneration)
+		h.PostGeneration = slices.Concat(h.PostGeneration, g.PostGeneration)
+		h.PreToolBatch = slices.Concat(h.PreToolBatch, g.PreToolBatch)
+		h.PreToolUse = slices.Concat(h.PreToolUse, g.PreToolUse)
+		h.PostToolUse = slices.Concat(h.PostToolUse, g.PostToolUse)
+		h.PostToolUseFailure = slices.Concat(h.PostToolUseFailure, g.PostToolUseFailure)
+		h.Stop = slices.Concat(h.Stop, g.Stop)
+		h.PreIteration = slices.Concat(h.PreIteration, g.PreIteration)
+		h.OnSuspend = slices.Concat(h.OnSuspend, g.OnSuspend)
+		h.PostBackgroundToolUse = slices.Concat(h.PostBackgroundToolUse, g.PostBackgroundToolUse)
+	}
 	return h
 }
 
@@ -203,6 +211,16 @@ type Extension interface {
 	Rules() string
 }
 
+// EarlyHooksExtension is an Extension whose hooks run before those set on
+// AgentOptions.Hooks and those of other extensions. A redactor uses it so
+// that no other hook sees the unredacted tool result.
+type EarlyHooksExtension interface {
+	Extension
+
+	// EarlyHooks reports whether the extension's hooks run first.
+	EarlyHooks() bool
+}
+
 // AgentOptions are used to configure an Agent.
 type AgentOptions struct {
 	// SystemPrompt is the system prompt sent to the LLM.
@@ -222,12 +240,19 @@ type AgentOptions struct {
 	// Extensions provide additional tools, hooks, and system prompt rules.
 	// Extensions are merged in order: tools are appended, hooks are appended
 	// to their respective slices, and rules are appended to the system prompt.
-	// Extension tools and hooks come after those set directly on AgentOptions.
+	// Extension tools and hooks come after those set directly on AgentOptions,
+	// except the hooks of an EarlyHooksExtension, which come before them.
 	Extensions []Extension
 
 	// Hooks groups all agent-level hooks.
 	Hooks Hooks
 
+	// Middleware wraps every CreateResponse call, including its retries and
+	// tool calls, for cross-cutting concerns such as logging, metrics, and
+	// request tagging. The first middleware is the outermost. See
+	// ResponseMiddleware.
+	Middleware []ResponseMiddleware
+
 	// Infrastructure
 	Logger        llm.Logger
 	ModelSettings *ModelSettings
@@ -270,12 +295,74 @@ type AgentOptions struct {
 
 	// ParallelToolExecution enables concurrent execution of tool calls when
 	// the LLM returns multiple tool calls in a single message. When false
-	// (the default), tool calls are executed sequentially in order.
+	// (the default), tool calls are executed one at a time in the order the
+	// LLM emitted them, each finishing before the next starts, so a tool
+	// sees the side effects of the calls before it.
 	//
 	// When enabled, ToolCallResult events and PostToolUse hooks fire in
 	// completion order (fastest tool first), not in the order the LLM
-	// declared the tool calls.
+	// declared the tool calls. In both modes, tool results are sent back to
+	// the LLM in the emitted order.
+	//
+	// Tools whose Annotations declare them not read-only (non-nil
+	// annotations with ReadOnlyHint false) run one at a time, with no other
+	// tool running alongside them, so writes don't race. Tools without
+	// annotations are treated as parallel-safe.
 	ParallelToolExecution bool
+
+	// MaxParallelTools caps how many tool calls run at once when
+	// ParallelToolExecution is enabled. Zero means no limit.
+	MaxParallelTools int
+
+	// ToolCircuitBreaker, when set, temporarily disables tools that fail
+	// repeatedly. See ToolCircuitBreaker.
+	ToolCircuitBreaker *ToolCircuitBreaker
+
+	// ToolCallLimits caps how many times each named tool may run within one
+	// CreateResponse call (e.g. {"web_search": 10}). Calls over the limit
+	// return an error result to the model instead of running the tool.
+	ToolCallLimits map[string]int
+
+	// ToolTimeout bounds how long a single tool call may run. A call that
+	// exceeds it has its context canceled and returns an error result. A
+	// tool that hasn't returned a second later is abandoned, so a hung tool
+	// can't block the agent. Zero means no timeout.
+	ToolTimeout time.Duration
+
+	// ToolTimeouts overrides ToolTimeout for the named tools (e.g.
+	// {"bash": 10 * time.Minute}). A zero value disables the timeout for
+	// that tool.
+	ToolTimeouts map[string]time.Duration
+
+	// IncludeToolExamples appends each tool's ToolAnnotations.UsageHint and
+	// Examples to the description sent to the model. Examples can improve
+	// tool-call accuracy but cost tokens on every request, so this is off by
+	// default.
+	IncludeToolExamples bool
+
+	// Clock supplies the timestamps recorded on responses. Defaults to
+	// SystemClock; tests can pass a FakeClock for deterministic output.
+	Clock Clock
+
+	// IDSource generates the IDs assigned while the agent runs, including
+	// the IDs tools create with NewID. Defaults to SystemIDSource; pass a
+	// SeededIDSource for reproducible runs.
+	IDSource IDSource
+
+	// ContextInjection, when set, prepends the current date, workspace, or
+	// OS to the system prompt on every call. WithContextInjection overrides
+	// it per call.
+	ContextInjection *ContextInjection
+
+	// ThinkingHistory controls whether thinking blocks from earlier turns
+	// are sent back to the model. Defaults to
+	// ThinkingHistoryProviderDefault. WithThinkingHistory overrides it per
+	// call.
+	ThinkingHistory ThinkingHistory
+
+	// ThinkingSummarizer converts earlier turns' thinking blocks to text
+	// under ThinkingHistorySummarize. Defaults to DefaultThinkingSummarizer.
+	ThinkingSummarizer ThinkingSummarizer
 }
 
 // Agent represents an intelligent AI entity that can autonomously use tools to
@@ -294,10 +381,21 @@ type Agent struct {
 	logger                llm.Logger
 	toolIterationLimit    int
 	parallelToolExecution bool
+	maxParallelTools      int
 	modelSettings         *ModelSettings
 	systemPrompt          string
 	session               Session
 	tracer                Tracer
+	clock                 Clock
+	idSource              IDSource
+	contextInjection      *ContextInjection
+	thinkingHistory       ThinkingHistory
+	thinkingSummarizer    ThinkingSummarizer
+	circuitBreaker        *ToolCircuitBreaker
+	toolCallLimits        map[string]int
+	toolTimeout           time.Duration
+	toolTimeouts          map[string]time.Duration
+	includeToolExamples   bool
 
 	// mu protects model and systemPrompt for concurrent access via
 	// SetModel/SetSystemPrompt while CreateResponse is running.
@@ -305,6 +403,9 @@ type Agent struct {
 
 	// Agent hooks
 	hooks Hooks
+
+	// respond is createResponse wrapped in the agent's middleware.
+	respond ResponseFunc
 }
 
 // NewAgent returns a new Agent configured with the given options.
@@ -327,27 +428,22 @@ func NewAgent(opts AgentOptions) (*Agent, error) {
 	// across multiple NewAgent calls.
 	if len(opts.Extensions) > 0 {
 		opts.Tools = slices.Clone(opts.Tools)
-		opts.Hooks = opts.Hooks.cloneSlices()
-	}
-	for _, ext := range opts.Extensions {
-		if ext == nil {
-			continue
-		}
-		opts.Tools = append(opts.Tools, ext.Tools()...)
-		extHooks := ext.Hooks()
-		opts.Hooks.SessionStart = append(opts.Hooks.SessionStart, extHooks.SessionStart...)
-		opts.Hooks.PreGeneration = append(opts.Hooks.PreGeneration, extHooks.PreGeneration...)
-		opts.Hooks.PostGeneration = append(opts.Hooks.PostGeneration, extHooks.PostGeneration...)
-		opts.Hooks.PreToolUse = append(opts.Hooks.PreToolUse, extHooks.PreToolUse...)
-		opts.Hooks.PostToolUse = append(opts.Hooks.PostToolUse, extHooks.PostToolUse...)
-		opts.Hooks.PostToolUseFailure = append(opts.Hooks.PostToolUseFailure, extHooks.PostToolUseFailure...)
-		opts.Hooks.Stop = append(opts.Hooks.Stop, extHooks.Stop...)
-		opts.Hooks.PreIteration = append(opts.Hooks.PreIteration, extHooks.PreIteration...)
-		opts.Hooks.OnSuspend = append(opts.Hooks.OnSuspend, extHooks.OnSuspend...)
-		opts.Hooks.PostBackgroundToolUse = append(opts.Hooks.PostBackgroundToolUse, extHooks.PostBackgroundToolUse...)
-		if rules := ext.Rules(); rules != "" {
-			opts.SystemPrompt = strings.TrimRight(opts.SystemPrompt, "\n") + "\n\n" + rules
+		var earlyHooks, extHooks []Hooks
+		for _, ext := range opts.Extensions {
+			if ext == nil {
+				continue
+			}
+			opts.Tools = append(opts.Tools, ext.Tools()...)
+			if early, ok := ext.(EarlyHooksExtension); ok && early.EarlyHooks() {
+				earlyHooks = append(earlyHooks, ext.Hooks())
+			} else {
+				extHooks = append(extHooks, ext.Hooks())
+			}
+			if rules := ext.Rules(); rules != "" {
+				opts.SystemPrompt = strings.TrimRight(opts.SystemPrompt, "\n") + "\n\n" + rules
+			}
 		}
+		opts.Hooks = concatHooks(slices.Concat(earlyHooks, []Hooks{opts.Hooks}, extHooks)...)
 	}
 	opts.SystemPrompt = ensureReminderPriming(opts.SystemPrompt)
 
@@ -363,6 +459,7 @@ func NewAgent(opts AgentOptions) (*Agent, error) {
 		responseTimeout:       opts.ResponseTimeout,
 		toolIterationLimit:    opts.ToolIterationLimit,
 		parallelToolExecution: opts.ParallelToolExecution,
+		maxParallelTools:      opts.MaxParallelTools,
 		llmHooks:              opts.LLMHooks,
 		logger:                opts.Logger,
 		systemPrompt:          opts.SystemPrompt,
@@ -371,7 +468,18 @@ func NewAgent(opts AgentOptions) (*Agent, error) {
 		session:               opts.Session,
 		toolsets:              opts.Toolsets,
 		tracer:                opts.Tracer,
-	}
+		clock:                 ClockOrDefault(opts.Clock),
+		idSource:              IDSourceOrDefault(opts.IDSource),
+		contextInjection:      opts.ContextInjection,
+		thinkingHistory:       opts.ThinkingHistory,
+		thinkingSummarizer:    opts.ThinkingSummarizer,
+		circuitBreaker:        opts.ToolCircuitBreaker,
+		toolCallLimits:        opts.ToolCallLimits,
+		toolTimeout:           opts.ToolTimeout,
+		toolTimeouts:          opts.ToolTimeouts,
+		includeToolExamples:   opts.IncludeToolExamples,
+	}
+	agent.respond = chainResponseMiddleware(agent.createResponse, slices.Clone(opts.Middleware))
 	tools := make([]Tool, len(opts.Tools))
 	if len(opts.Tools) > 0 {
 		copy(tools, opts.Tools)
@@ -489,9 +597,22 @@ func ensureReminderPriming(prompt string) string {
 	return strings.TrimRight(prompt, "\n") + "\n\n" + reminderPrimingRule
 }
 
-func (a *Agent) CreateResponse(ctx context.Context, opts ...CreateResponseOption) (response *Response, err error) {
+// CreateResponse generates a response to the given input, calling tools
+// as the model requests them, through the agent's middleware.
+func (a *Agent) CreateResponse(ctx context.Context, opts ...CreateResponseOption) (*Response, error) {
+	if a.respond == nil {
+		return a.createResponse(ctx, opts...)
+	}
+	return a.respond(ctx, opts...)
+}
+
+func (a *Agent) createResponse(ctx context.Context, opts ...CreateResponseOption) (response *Response, err error) {
 	var options CreateResponseOptions
 	options.Apply(opts)
+	var deadline time.Time
+	if options.Deadline > 0 {
+		deadline = time.Now().Add(options.Deadline)
+	}
 	for _, reminder := range options.ModelOnlyReminders {
 		if err := validateReminder(reminder); err != nil {
 			return nil, err
@@ -505,7 +626,24 @@ func (a *Agent) CreateResponse(ctx context.Context, opts ...CreateResponseOption
 	systemPrompt := strings.TrimSpace(a.systemPrompt)
 	a.mu.Unlock()
 
+	if len(options.InputParts) > 0 {
+		input, err := buildInputMessage(options.InputParts, a.modelInfo(model))
+		if err != nil {
+			return nil, err
+		}
+		options.Messages = append(slices.Clone(options.Messages), input)
+	}
+
+	injection := a.contextInjection
+	if options.ContextInjection != nil {
+		injection = options.ContextInjection
+	}
+	systemPrompt = injectContext(systemPrompt, injection, a.clock.Now())
+
 	logger := a.logger.With("agent_name", a.name)
+	if len(options.Metadata) > 0 {
+		logger = logger.With("metadata", options.Metadata)
+	}
 	logger.Info("creating response")
 
 	// Save the caller's input messages before session history is prepended.
@@ -708,9 +846,13 @@ func (a *Agent) CreateResponse(ctx context.Context, opts ...CreateResponseOption
 		runSpan.End(err)
 	}()
 
+	// Tools share state for the length of this response
+	ctx = WithResponseScope(ctx, &ResponseScope{})
+
 	// Initialize hook context shared across all phases
 	hctx := NewHookContext()
 	hctx.Agent = a
+	hctx.toolCache = options.ToolCache
 	hctx.Session = sess
 	hctx.SystemPrompt = systemPrompt
 	hctx.Messages = messages
@@ -746,14 +888,21 @@ func (a *Agent) CreateResponse(ctx context.Context, opts ...CreateResponseOption
 
 	response = &Response{
 		Model:     model.Name(),
-		CreatedAt: time.Now(),
+		CreatedAt: a.clock.Now(),
+		Metadata:  options.Metadata,
 	}
 
 	eventCallback := func(ctx context.Context, item *ResponseItem) error {
-		if options.EventCallback != nil {
-			return options.EventCallback(ctx, item)
+		if options.EventCallback == nil {
+			return nil
 		}
-		return nil
+		if len(options.Metadata) > 0 {
+			// Stamp a copy so the items kept on the Response stay lean
+			stamped := *item
+			stamped.Metadata = options.Metadata
+			item = &stamped
+		}
+		return options.EventCallback(ctx, item)
 	}
 
 	// Resume-specific handling before entering the generate loop:
@@ -824,7 +973,7 @@ func (a *Agent) CreateResponse(ctx context.Context, opts ...CreateResponseOption
 				resumeItemsMu.Unlock()
 				return eventCallback(ctx, item)
 			}
-			batch, err := a.executeToolCalls(ctx, hctx, rs.NotStartedToolCalls, resumeToolsByName, resumeCallback)
+			batch, err := a.executeToolCalls(ctx, hctx, rs.NotStartedToolCalls, nil, resumeToolsByName, resumeCallback)
 			if err != nil {
 				// Mirror the generate loop: expose the items accumulated
 				// during the resume phase via a *GenerationError so callers
@@ -912,8 +1061,17 @@ func (a *Agent) CreateResponse(ctx context.Context, opts ...CreateResponseOption
 		}
 	}
 
+	// The WithDeadline budget applies to generation only, so a call that
+	// finishes in time can still run its hooks and save its session.
+	runCtx := ctx
+	if !deadline.IsZero() {
+		var cancelRun context.CancelFunc
+		runCtx, cancelRun = context.WithDeadlineCause(ctx, deadline, errRunDeadline)
+		defer cancelRun()
+	}
+
 generateLoop:
-	genResult, err := a.generate(ctx, hctx, messages, systemPrompt, eventCallback, model)
+	genResult, err := a.generate(runCtx, hctx, messages, systemPrompt, eventCallback, model, &options)
 	if err != nil {
 		logger.Error("failed to generate response", "error", err)
 		// generate wraps loop failures in *GenerationError scoped to that
@@ -935,6 +1093,10 @@ generateLoop:
 			turnItems = append(turnItems, genErr.Items...)
 			genErr.Items = turnItems
 		}
+		if deadlineExceeded(ctx, runCtx, err) {
+			logger.Info("response deadline reached", "deadline", options.Deadline)
+			return deadlineResponse(response, err, a.clock.Now()), nil
+		}
 		return nil, err
 	}
 
@@ -945,10 +1107,14 @@ generateLoop:
 	}
 	accumulatedBackgroundTasks = append(accumulatedBackgroundTasks, genResult.BackgroundTasks...)
 
-	response.FinishedAt = Ptr(time.Now())
+	response.FinishedAt = Ptr(a.clock.Now())
 	response.Usage = accumulatedUsage
 	response.Items = accumulatedItems
 	response.OutputMessages = accumulatedOutput
+	response.Refusal = genResult.Refusal
+	response.StopReason = genResult.StopReason
+	response.Truncated = genResult.Truncated
+	response.OmittedParts = genResult.OmittedParts
 
 	// Merge any resume-phase items into the response, keeping chronological order.
 	if len(resumeExtraItems) > 0 {
@@ -961,8 +1127,9 @@ generateLoop:
 		return a.finishSuspended(ctx, logger, hctx, response, inputMessages, genResult.Suspended, nil, eventCallback, sess, rs, false)
 	}
 
-	// Run Stop hooks before PostGeneration
-	if len(a.hooks.Stop) > 0 {
+	// Run Stop hooks before PostGeneration, unless a stop condition
+	// already decided to stop
+	if len(a.hooks.Stop) > 0 && genResult.StopReason == "" {
 		hctx.Response = response
 		hctx.OutputMessages = accumulatedOutput
 		hctx.Usage = accumulatedUsage
@@ -1063,6 +1230,17 @@ generateLoop:
 		}
 	}
 
+	if response.Truncated {
+		response.continuation = &continuation{agent: a, session: sess}
+		if sess == nil {
+			turn := response.OutputMessages
+			if response.Suspension != nil {
+				turn = response.Suspension.TurnMessages
+			}
+			response.continuation.messages = slices.Concat(inputMessages, turn)
+		}
+	}
+
 	response.Status = ResponseStatusCompleted
 	if len(accumulatedBackgroundTasks) > 0 {
 		response.BackgroundTasks = accumulatedBackgroundTasks
@@ -1098,7 +1276,7 @@ func (a *Agent) finishSuspended(
 	skipSuspendNotifications bool,
 ) (*Response, error) {
 	if response.FinishedAt == nil {
-		response.FinishedAt = Ptr(time.Now())
+		response.FinishedAt = Ptr(a.clock.Now())
 	}
 
 	// Build the turn the caller will need on resume. For a generate-driven
@@ -1798,7 +1976,7 @@ func (a *Agent) prepareMessages(options CreateResponseOptions) []*llm.Message {
 // generate runs the LLM generation and tool execution loop. It handles the
 // interaction between the agent and the LLM, including tool calls. Returns the
 // final LLM response, updated messages, and any error that occurred.
-func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm.Message, systemPrompt string, callback EventCallback, model llm.LLM) (result *generateResult, err error) {
+func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm.Message, systemPrompt string, callback EventCallback, model llm.LLM, options *CreateResponseOptions) (result *generateResult, err error) {
 
 	// Contains the message history we pass to the LLM
 	updatedMessages := make([]*llm.Message, len(messages))
@@ -1811,6 +1989,9 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 	// New messages that are the output
 	var outputMessages []*llm.Message
 
+	// Checks each request's size, when a size policy is set
+	var fitter *requestFitter
+
 	// All response items in chronological order
 	var items []*ResponseItem
 
@@ -1832,6 +2013,10 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 	// Accumulates usage across multiple LLM calls
 	totalUsage := &llm.Usage{}
 
+	// Estimated savings from prompt cache reads, logged at the end of the
+	// turn when auto prompt caching is enabled.
+	var cacheSavings float64
+
 	// Wrap any loop failure in a *GenerationError carrying the state
 	// accumulated before the failure, so callers can recover cost
 	// accounting and partial work via errors.As. The items snapshot is
@@ -1873,7 +2058,12 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 	// running tool-uses and responding with the results.
 	generationLimit := a.toolIterationLimit + 1
 	lastIteration := false
-	for i := range generationLimit {
+	continuations := 0
+	refusalRetries := 0
+	var refusal *Refusal
+	var truncated bool
+	summarizeThinkingBlock := cachedThinkingSummarizer(a.thinkingSummarizer)
+	for i := 0; i < generationLimit; i++ {
 		// Refresh per-iteration hook context state unconditionally, so every
 		// hook that fires during this iteration (PreIteration, PreToolUse,
 		// PostToolUse, ...) observes the current message set rather than a
@@ -1914,12 +2104,82 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 			return nil, fmt.Errorf("tool resolution error: %w", resolveErr)
 		}
 
+		if a.includeToolExamples {
+			resolvedTools = withToolExamples(resolvedTools)
+		}
+
+		// Fit tool definitions to the provider's limits
+		fitted, fitErr := a.fitToolSchemas(model, resolvedTools, options.ToolSchemaStrategy)
+		if fitErr != nil {
+			return nil, fitErr
+		}
+		for _, tool := range fitted.Tools {
+			if _, ok := toolsByName[tool.Name()]; !ok {
+				toolsByName[tool.Name()] = tool
+			}
+		}
+
 		// Build per-iteration LLM options
-		baseOpts := a.getGenerationOptions(systemPrompt, resolvedTools)
-		iterOpts := append(slices.Clone(baseOpts), llm.WithMessages(updatedMessages...))
+		baseOpts := a.getGenerationOptions(systemPrompt, fitted.Tools)
+		if options.PreviousResponseID != "" {
+			baseOpts = append(baseOpts, llm.WithPreviousResponseID(options.PreviousResponseID))
+		}
+		budgetCfg := &llm.Config{}
+		budgetCfg.Apply(baseOpts...)
+		if fitter == nil {
+			fitter = newRequestFitter(model, budgetCfg.Model,
+				options.ReservedOutputTokens, options.OversizedMessagePolicy)
+		}
+		// Truncate before trimming, so an oversized latest message doesn't
+		// overflow the context window on its own
+		sized, sizeErr := fitter.truncate(updatedMessages)
+		if sizeErr != nil {
+			return nil, sizeErr
+		}
+		updatedMessages = sized
+		if options.ReservedOutputTokens > 0 {
+			// Keep room in the context window for the reply. Like compaction,
+			// only the model-facing history is trimmed.
+			trimmed, budgetErr := fitContextWindow(model, budgetCfg.Model, systemPrompt, fitted.Tools,
+				updatedMessages, options.ReservedOutputTokens, options.ContextOverflowStrategy)
+			if budgetErr != nil {
+				return nil, budgetErr
+			}
+			if dropped := len(updatedMessages) - len(trimmed); dropped > 0 {
+				a.logger.Debug("trimmed history to reserve output tokens",
+					"agent_name", a.name,
+					"dropped_messages", dropped,
+					"reserved_output_tokens", options.ReservedOutputTokens,
+				)
+				updatedMessages = trimmed
+			}
+		}
+		if sizeErr := fitter.check(updatedMessages); sizeErr != nil {
+			return nil, sizeErr
+		}
+		thinkingHistory := a.thinkingHistory
+		if options.ThinkingHistory != "" {
+			thinkingHistory = options.ThinkingHistory
+		}
+		modelMessages := filterThinkingHistory(updatedMessages, thinkingHistory)
+		if thinkingHistory == ThinkingHistorySummarize {
+			summarized, summarizeErr := summarizeThinking(ctx, updatedMessages, currentTurnStart(updatedMessages), summarizeThinkingBlock)
+			if summarizeErr != nil {
+				return nil, fmt.Errorf("thinking summarizer error: %w", summarizeErr)
+			}
+			modelMessages = summarized
+		}
+		iterOpts := append(slices.Clone(baseOpts), llm.WithMessages(modelMessages...))
 		if lastIteration {
 			iterOpts = append(iterOpts, llm.WithToolChoice(llm.ToolChoiceNone))
 		}
+		if options.AutoPromptCaching != nil {
+			iterOpts = append(iterOpts, llm.WithCaching(*options.AutoPromptCaching))
+			if *options.AutoPromptCaching {
+				iterOpts = append(iterOpts, llm.WithCacheBreakpoints(
+					autoCacheBreakpoints(systemPrompt, modelMessages)...))
+			}
+		}
 
 		// Open chat span before invoking the model. The returned ctx carries
 		// the span so any HTTP-client middleware (e.g. otelhttp) the provider
@@ -1974,13 +2234,39 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 			"generation_number", i+1,
 		)
 
+		// Track total token usage
+		totalUsage.Add(&response.Usage)
+		if savings, ok := llm.CacheSavings(response.Model, &response.Usage); ok {
+			cacheSavings += savings
+		}
+
+		// Retry a refused prompt with a rephrased version. The refused
+		// response is discarded and, like continuations, retries don't count
+		// against the tool iteration limit.
+		refusal = DetectRefusal(response)
+		if refusal != nil {
+			refusal.Retries = refusalRetries
+			if retry := options.RefusalRetry; retry != nil && refusal.Retryable() && refusalRetries < retry.MaxAttempts {
+				if rephrased, ok := rephrasePrompt(updatedMessages, retry.Rephrase); ok {
+					refusalRetries++
+					generationLimit++
+					a.logger.Debug("retrying refused response",
+						"agent_name", a.name,
+						"attempt", refusalRetries,
+					)
+					updatedMessages = rephrased
+					continue
+				}
+			}
+		}
+
+		// Salvage tool call inputs cut off by the output token limit
+		invalidInputs := repairToolInputs(response)
+
 		// Remember the assistant response message
 		assistantMsg := response.Message()
 		newMessage(assistantMsg)
 
-		// Track total token usage
-		totalUsage.Add(&response.Usage)
-
 		// Always call callback for every LLM-generated message
 		if err := collectingCallback(ctx, &ResponseItem{
 			Type:    ResponseItemTypeMessage,
@@ -1991,13 +2277,27 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 		}
 
 		// Check for tool calls
-		toolCalls := response.ToolCalls()
+		toolCalls := fitted.resolveCallTool(response.ToolCalls())
 		if len(toolCalls) == 0 {
+			// Ask the model to pick up where it left off if the response was
+			// cut off by the output token limit. Continuations don't count
+			// against the tool iteration limit.
+			if response.Truncated() && continuations < options.AutoContinue {
+				continuations++
+				generationLimit++
+				a.logger.Debug("continuing truncated response",
+					"agent_name", a.name,
+					"continuation", continuations,
+				)
+				newMessage(llm.NewUserTextMessage(AutoContinuePrompt))
+				continue
+			}
+			truncated = response.Truncated()
 			break
 		}
 
 		// Execute all requested tool calls
-		batch, err := a.executeToolCalls(ctx, hctx, toolCalls, toolsByName, collectingCallback)
+		batch, err := a.executeToolCalls(ctx, hctx, toolCalls, invalidInputs, toolsByName, collectingCallback)
 		if err != nil {
 			return nil, err
 		}
@@ -2034,6 +2334,7 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 				Usage:           totalUsage,
 				Suspended:       snapshot,
 				BackgroundTasks: backgroundTasks,
+				OmittedParts:    fitter.omittedParts(),
 			}, nil
 		}
 
@@ -2047,6 +2348,26 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 			newMessage(toolResultMessage)
 		}
 
+		if len(options.StopConditions) > 0 {
+			partial := &Response{
+				Model:          model.Name(),
+				Items:          slices.Clone(items),
+				OutputMessages: slices.Clone(outputMessages),
+				Usage:          totalUsage.Copy(),
+			}
+			if reason, stop := checkStopConditions(ctx, options.StopConditions, partial, updatedMessages); stop {
+				a.logger.Debug("stop condition met", "agent_name", a.name, "reason", reason)
+				return &generateResult{
+					OutputMessages:  outputMessages,
+					Items:           items,
+					Usage:           totalUsage,
+					BackgroundTasks: backgroundTasks,
+					StopReason:      reason,
+					OmittedParts:    fitter.omittedParts(),
+				}, nil
+			}
+		}
+
 		// Add instructions to the message to not use any more tools if we have
 		// only one generation left
 		if i == generationLimit-2 {
@@ -2058,11 +2379,24 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 		}
 	}
 
+	if options.AutoPromptCaching != nil && *options.AutoPromptCaching {
+		a.logger.Info("prompt cache usage",
+			"agent_name", a.name,
+			"input_tokens", totalUsage.InputTokens,
+			"cache_read_input_tokens", totalUsage.CacheReadInputTokens,
+			"cache_creation_input_tokens", totalUsage.CacheCreationInputTokens,
+			"estimated_savings", cacheSavings,
+		)
+	}
+
 	return &generateResult{
 		OutputMessages:  outputMessages,
 		Items:           items,
 		Usage:           totalUsage,
 		BackgroundTasks: backgroundTasks,
+		Refusal:         refusal,
+		Truncated:       truncated,
+		OmittedParts:    fitter.omittedParts(),
 	}, nil
 }
 
@@ -2070,6 +2404,25 @@ func (a *Agent) generate(ctx context.Context, hctx *HookContext, messages []*llm
 // receiving and republishing events, and accumulating a complete response.
 // Returns the accumulated response, the time-to-first-chunk in seconds
 // (zero for pre-first-chunk failures), and any error.
+// autoCacheBreakpoints returns the cache breakpoints WithAutoPromptCaching
+// places: the end of the system prompt, which also caches the tool
+// definitions ahead of it, the last message before the current turn, and the
+// last message. The last two move forward as the conversation grows, so each
+// request reads the prefix the previous one wrote.
+func autoCacheBreakpoints(systemPrompt string, messages []*llm.Message) []llm.CacheBreakpoint {
+	var breakpoints []llm.CacheBreakpoint
+	if systemPrompt != "" {
+		breakpoints = append(breakpoints, llm.SystemCacheBreakpoint(0))
+	}
+	if len(messages) == 0 {
+		return breakpoints
+	}
+	if start := currentTurnStart(messages); start > 0 {
+		breakpoints = append(breakpoints, llm.MessageCacheBreakpoint(start-1))
+	}
+	return append(breakpoints, llm.MessageCacheBreakpoint(len(messages)-1))
+}
+
 func (a *Agent) generateStreaming(
 	ctx context.Context,
 	streamingLLM llm.StreamingLLM,
@@ -2137,18 +2490,65 @@ func eventHasContent(event *llm.Event) bool {
 // concurrently using a three-phase approach: PreToolUse hooks run sequentially,
 // then tool executions run in parallel, then PostToolUse hooks and result
 // events run sequentially. This keeps hooks single-threaded while parallelizing
-// the expensive tool execution.
+// the expensive tool execution. At most maxParallelTools tools run at once,
+// and tools that are not read-only run alone.
 func (a *Agent) executeToolCalls(
 	ctx context.Context,
 	hctx *HookContext,
 	toolCalls []*llm.ToolUseContent,
+	invalidInputs map[string]bool,
 	toolsByName map[string]Tool,
 	callback EventCallback,
 ) (*toolBatchResult, error) {
+	// Tools draw IDs and times from the agent's sources so replays
+	// reproduce them
+	ctx = WithIDSource(ctx, a.idSource)
+	ctx = WithClock(ctx, a.clock)
+	if err := a.runPreToolBatchHooks(ctx, hctx, toolCalls, toolsByName); err != nil {
+		return nil, err
+	}
 	if a.parallelToolExecution && len(toolCalls) > 1 && !batchHasSequentialOnlyTool(toolCalls, toolsByName) {
-		return a.executeToolCallsParallel(ctx, hctx, toolCalls, toolsByName, callback)
+		return a.executeToolCallsParallel(ctx, hctx, toolCalls, invalidInputs, toolsByName, callback)
 	}
-	return a.executeToolCallsSequential(ctx, hctx, toolCalls, toolsByName, callback)
+	return a.executeToolCallsSequential(ctx, hctx, toolCalls, invalidInputs, toolsByName, callback)
+}
+
+// runPreToolBatchHooks runs PreToolBatch hooks over a turn's tool calls.
+// Only a HookAbortError stops the turn; other errors are logged.
+func (a *Agent) runPreToolBatchHooks(
+	ctx context.Context,
+	hctx *HookContext,
+	toolCalls []*llm.ToolUseContent,
+	toolsByName map[string]Tool,
+) error {
+	if len(a.hooks.PreToolBatch) == 0 || len(toolCalls) == 0 {
+		return nil
+	}
+	toolBatch := make([]ToolBatchCall, len(toolCalls))
+	for i, call := range toolCalls {
+		toolBatch[i] = ToolBatchCall{Tool: toolsByName[call.Name], Call: call}
+	}
+	batchHctx := &HookContext{
+		Agent:        a,
+		Session:      hctx.Session,
+		Values:       hctx.Values,
+		SystemPrompt: hctx.SystemPrompt,
+		Messages:     hctx.Messages,
+		ToolBatch:    toolBatch,
+		reminders:    hctx.reminders,
+	}
+	for _, hook := range a.hooks.PreToolBatch {
+		if err := hook(ctx, batchHctx); err != nil {
+			var abortErr *HookAbortError
+			if errors.As(err, &abortErr) {
+				abortErr.HookType = "PreToolBatch"
+				a.logger.Error("pre-tool-batch hook aborted", "error", abortErr)
+				return abortErr
+			}
+			a.logger.Error("pre-tool-batch hook error", "error", err)
+		}
+	}
+	return nil
 }
 
 // batchHasSequentialOnlyTool reports whether any tool in the batch carries
@@ -2169,7 +2569,16 @@ func batchHasSequentialOnlyTool(toolCalls []*llm.ToolUseContent, toolsByName map
 	return false
 }
 
-// executeToolCallsSequential executes tool calls one at a time in order.
+// toolMayWrite reports whether a tool declares, through its annotations, that
+// it is not read-only. Tools without annotations are assumed parallel-safe.
+func toolMayWrite(tool Tool) bool {
+	ann := tool.Annotations()
+	return ann != nil && !ann.ReadOnlyHint
+}
+
+// executeToolCallsSequential executes tool calls one at a time in the order
+// the model emitted them. Tools with side effects rely on this, such as a
+// read that follows a write.
 // If any tool returns a SuspendResult, the remaining trailing tool calls are
 // NOT executed; their outcomes stay zero-valued ("not started") and are
 // re-scheduled on resume.
@@ -2177,12 +2586,13 @@ func (a *Agent) executeToolCallsSequential(
 	ctx context.Context,
 	hctx *HookContext,
 	toolCalls []*llm.ToolUseContent,
+	invalidInputs map[string]bool,
 	toolsByName map[string]Tool,
 	callback EventCallback,
 ) (*toolBatchResult, error) {
 	batch := &toolBatchResult{Outcomes: make([]toolCallOutcome, len(toolCalls))}
 	for i, toolCall := range toolCalls {
-		result, err := a.executeOneToolCall(ctx, hctx, toolCall, toolsByName, callback)
+		result, err := a.executeOneToolCall(ctx, hctx, toolCall, invalidInputs[toolCall.ID], toolsByName, callback)
 		if err != nil {
 			return nil, err
 		}
@@ -2224,6 +2634,7 @@ func (a *Agent) executeToolCallsParallel(
 	ctx context.Context,
 	hctx *HookContext,
 	toolCalls []*llm.ToolUseContent,
+	invalidInputs map[string]bool,
 	toolsByName map[string]Tool,
 	callback EventCallback,
 ) (*toolBatchResult, error) {
@@ -2324,12 +2735,34 @@ func (a *Agent) executeToolCallsParallel(
 		}
 	}
 
+	// Limit concurrency to maxParallelTools, and give tools that may write
+	// exclusive use of the batch so they never overlap another tool.
+	var slots chan struct{}
+	if a.maxParallelTools > 0 {
+		slots = make(chan struct{}, a.maxParallelTools)
+	}
+	var writeMu sync.RWMutex
+
 	// Launch tool executions.
 	for i, prep := range preps {
 		if prep.denied {
 			continue
 		}
 		go func() {
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-childCtx.Done():
+				}
+			}
+			if toolMayWrite(prep.tool) {
+				writeMu.Lock()
+				defer writeMu.Unlock()
+			} else {
+				writeMu.RLock()
+				defer writeMu.RUnlock()
+			}
 			if err := childCtx.Err(); err != nil {
 				ch <- completedTool{index: i, err: err}
 				return
@@ -2340,7 +2773,7 @@ func (a *Agent) executeToolCallsParallel(
 				Tool:    prep.tool,
 				Call:    toolCalls[i],
 			})
-			result := a.executeTool(toolCtx, prep.tool, toolCalls[i], prep.input, prep.preview, callback)
+			result := a.executeGuardedTool(toolCtx, hctx, prep.tool, toolCalls[i], prep.input, invalidInputs[toolCalls[i].ID], prep.preview, callback)
 			toolSpan.SetResult(result)
 			if result != nil && result.Error != nil {
 				toolSpan.End(result.Error)
@@ -2489,6 +2922,7 @@ func (a *Agent) executeOneToolCall(
 	ctx context.Context,
 	hctx *HookContext,
 	toolCall *llm.ToolUseContent,
+	invalidInput bool,
 	toolsByName map[string]Tool,
 	callback EventCallback,
 ) (*ToolCallResult, error) {
@@ -2560,7 +2994,7 @@ func (a *Agent) executeOneToolCall(
 			Tool:    tool,
 			Call:    toolCall,
 		})
-		result = a.executeTool(toolCtx, tool, toolCall, input, preview, callback)
+		result = a.executeGuardedTool(toolCtx, hctx, tool, toolCall, input, invalidInput, preview, callback)
 		toolSpan.SetResult(result)
 		if result != nil && result.Error != nil {
 			toolSpan.End(result.Error)
@@ -2683,7 +3117,8 @@ func (a *Agent) executeOneToolCall(
 
 // executeTool runs the tool and returns the result. Panics in tool.Call are
 // recovered and converted to error results so the LLM can see the failure
-// and adapt, rather than crashing the process.
+// and adapt, rather than crashing the process. The result's Error includes
+// the panicking goroutine's stack.
 func (a *Agent) executeTool(
 	ctx context.Context,
 	tool Tool,
@@ -2694,10 +3129,14 @@ func (a *Agent) executeTool(
 ) (result *ToolCallResult) {
 	defer func() {
 		if r := recover(); r != nil {
+			stack := debug.Stack()
+			if p, ok := r.(*toolPanic); ok {
+				r, stack = p.value, p.stack
+			}
 			a.logger.Error("tool panic recovered",
 				"tool", tool.Name(),
 				"panic", fmt.Sprint(r),
-				"stack", string(debug.Stack()),
+				"stack", string(stack),
 			)
 			result = &ToolCallResult{
 				ID:      call.ID,
@@ -2713,7 +3152,7 @@ func (a *Agent) executeTool(
 					},
 					IsError: true,
 				},
-				Error: fmt.Errorf("tool %s panicked: %v", tool.Name(), r),
+				Error: fmt.Errorf("tool %s panicked: %v\n%s", tool.Name(), r, stack),
 			}
 		}
 	}()
@@ -2739,9 +3178,31 @@ func (a *Agent) executeTool(
 				},
 			})
 		})
+		toolCtx = WithStatusFunc(toolCtx, func(toolCallID, text string) {
+			_ = callback(ctx, &ResponseItem{
+				Type: ResponseItemTypeStatus,
+				Status: &StatusEvent{
+					ToolCallID: toolCallID,
+					Text:       text,
+				},
+			})
+		})
+	}
+
+	var checkpoints *toolCheckpoints
+	if policy := toolCheckpointPolicy(tool); policy != nil {
+		toolCtx, checkpoints = a.startToolCheckpoints(ctx, toolCtx, policy, tool, call, callback)
+		defer checkpoints.stop()
 	}
 
-	output, err := tool.Call(toolCtx, input)
+	output, err := a.callTool(toolCtx, tool, input)
+	if checkpoints != nil {
+		// A tool stopped at a checkpoint typically returns a context error;
+		// report the stop and the output so far instead
+		if stopped := checkpoints.stop(); stopped != nil {
+			output, err = stopped, nil
+		}
+	}
 	if err != nil {
 		return &ToolCallResult{
 			ID:      call.ID,
@@ -2824,6 +3285,18 @@ func (a *Agent) createDeniedResult(call *llm.ToolUseContent, message string, pre
 	}
 }
 
+// modelInfo returns the limits of the agent's configured model, or a zero
+// ModelInfo if model doesn't implement llm.ModelInfoProvider.
+func (a *Agent) modelInfo(model llm.LLM) llm.ModelInfo {
+	provider, ok := model.(llm.ModelInfoProvider)
+	if !ok {
+		return llm.ModelInfo{}
+	}
+	config := &llm.Config{}
+	config.Apply(a.modelSettings.Options()...)
+	return provider.ModelInfo(config.Model)
+}
+
 // getGenerationOptions builds LLM options for a generation iteration using
 // the resolved tool set and effective system prompt.
 func (a *Agent) getGenerationOptions(systemPrompt string, tools []Tool) []llm.Option {
@@ -2832,11 +3305,7 @@ func (a *Agent) getGenerationOptions(systemPrompt string, tools []Tool) []llm.Op
 		generateOpts = append(generateOpts, llm.WithSystemPrompt(systemPrompt))
 	}
 	if len(tools) > 0 {
-		defs := make([]llm.Tool, len(tools))
-		for i, tool := range tools {
-			defs[i] = tool
-		}
-		generateOpts = append(generateOpts, llm.WithTools(defs...))
+		generateOpts = append(generateOpts, llm.WithTools(AsLLMTools(tools)...))
 	}
 	if a.llmHooks != nil {
 		generateOpts = append(generateOpts, llm.WithHooks(a.llmHooks))
@@ -2862,6 +3331,19 @@ type generateResult struct {
 	// started during this generate() call. Populated from ToolCallResult
 	// entries that have BackgroundHandle set.
 	BackgroundTasks []*BackgroundTaskHandle
+
+	// Refusal is the refusal of the final model response, if any.
+	Refusal *Refusal
+
+	// StopReason is set when a StopCondition ended the loop.
+	StopReason string
+
+	// Truncated is true when the final model response was cut off by the
+	// output token limit and not continued.
+	Truncated bool
+
+	// OmittedParts holds the message parts OversizedMessageTruncate left out.
+	OmittedParts []*llm.Message
 }
 
 // suspendedSnapshot describes the state captured when generate() returns
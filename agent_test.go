@@ -1048,6 +1048,13 @@ func (e *mockExtension) Tools() []Tool { return e.tools }
 func (e *mockExtension) Hooks() Hooks  { return e.hooks }
 func (e *mockExtension) Rules() string { return e.rules }
 
+// earlyMockExtension implements EarlyHooksExtension for testing.
+type earlyMockExtension struct {
+	mockExtension
+}
+
+func (e *earlyMockExtension) EarlyHooks() bool { return true }
+
 func TestReminderPrimingRuleAccumulatesIndependentSameNameContext(t *testing.T) {
 	assert.Contains(t, reminderPrimingRule, "same name accumulate")
 	assert.Contains(t, reminderPrimingRule, "unless their facts or instructions conflict")
@@ -1113,6 +1120,31 @@ func TestExtensionMerge(t *testing.T) {
 		assert.Equal(t, []string{"direct", "ext"}, order)
 	})
 
+	t.Run("early hooks run first", func(t *testing.T) {
+		var order []string
+		hook := func(name string) PostToolUseHook {
+			return func(ctx context.Context, hctx *HookContext) error {
+				order = append(order, name)
+				return nil
+			}
+		}
+		agent, err := NewAgent(AgentOptions{
+			Model: mock,
+			Hooks: Hooks{PostToolUse: []PostToolUseHook{hook("direct")}},
+			Extensions: []Extension{
+				&mockExtension{hooks: Hooks{PostToolUse: []PostToolUseHook{hook("ext")}}},
+				&earlyMockExtension{mockExtension{hooks: Hooks{PostToolUse: []PostToolUseHook{hook("early 1")}}}},
+				&earlyMockExtension{mockExtension{hooks: Hooks{PostToolUse: []PostToolUseHook{hook("early 2")}}}},
+			},
+		})
+		assert.NoError(t, err)
+
+		for _, hook := range agent.hooks.PostToolUse {
+			hook(context.Background(), &HookContext{})
+		}
+		assert.Equal(t, []string{"early 1", "early 2", "direct", "ext"}, order)
+	})
+
 	t.Run("merges rules into system prompt", func(t *testing.T) {
 		agent, err := NewAgent(AgentOptions{
 			Model:        mock,
@@ -1318,3 +1350,128 @@ func TestParallelToolStreaming_NoDataRace(t *testing.T) {
 	}
 	assert.Equal(t, streamItems, 2*chunksPerTool)
 }
+
+func TestAutoContinueTruncatedResponse(t *testing.T) {
+	var lastMessages []*llm.Message
+	callCount := 0
+	mock := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			callCount++
+			config := &llm.Config{}
+			config.Apply(opts...)
+			lastMessages = config.Messages
+			stopReason := llm.StopReasonMaxTokens
+			if callCount == 3 {
+				stopReason = llm.StopReasonEndTurn
+			}
+			return &llm.Response{
+				ID:         fmt.Sprintf("resp_%d", callCount),
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: fmt.Sprintf("part %d", callCount)}},
+				StopReason: stopReason,
+				Usage:      llm.Usage{InputTokens: 10, OutputTokens: 5},
+			}, nil
+		},
+	}
+
+	agent, err := NewAgent(AgentOptions{Model: mock, ToolIterationLimit: 1})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("Write a long story"), WithAutoContinue())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, callCount)
+	assert.Equal(t, "part 3", resp.OutputText())
+	assert.Equal(t, 30, resp.Usage.InputTokens)
+
+	// assistant, continue prompt, assistant, continue prompt, assistant
+	assert.Len(t, resp.OutputMessages, 5)
+	assert.Equal(t, llm.User, resp.OutputMessages[1].Role)
+	assert.Equal(t, AutoContinuePrompt, resp.OutputMessages[1].Text())
+	assert.Equal(t, AutoContinuePrompt, lastMessages[len(lastMessages)-1].Text())
+
+	// Without the option a truncated response is returned as-is.
+	callCount = 0
+	resp, err = agent.CreateResponse(context.Background(), WithInput("Write a long story"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, callCount)
+	assert.Len(t, resp.OutputMessages, 1)
+}
+
+func TestAutoContinueLimit(t *testing.T) {
+	callCount := 0
+	mock := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			callCount++
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "more"}},
+				StopReason: llm.StopReasonMaxTokens,
+			}, nil
+		},
+	}
+
+	agent, err := NewAgent(AgentOptions{Model: mock})
+	assert.NoError(t, err)
+
+	_, err = agent.CreateResponse(context.Background(), WithInput("Go"), WithAutoContinue())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultAutoContinueLimit+1, callCount)
+}
+
+func TestAutoPromptCaching(t *testing.T) {
+	var configs []*llm.Config
+	mock := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			config := &llm.Config{}
+			config.Apply(opts...)
+			configs = append(configs, config)
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "ok"}},
+				StopReason: llm.StopReasonEndTurn,
+				Usage:      llm.Usage{InputTokens: 10, CacheReadInputTokens: 1000},
+			}, nil
+		},
+	}
+
+	agent, err := NewAgent(AgentOptions{Model: mock})
+	assert.NoError(t, err)
+
+	_, err = agent.CreateResponse(context.Background(), WithInput("Hi"))
+	assert.NoError(t, err)
+	_, err = agent.CreateResponse(context.Background(), WithInput("Hi"), WithAutoPromptCaching(true))
+	assert.NoError(t, err)
+	_, err = agent.CreateResponse(context.Background(), WithInput("Hi"), WithAutoPromptCaching(false))
+	assert.NoError(t, err)
+
+	assert.Len(t, configs, 3)
+	assert.Nil(t, configs[0].Caching)
+	assert.Len(t, configs[0].CacheBreakpoints, 0)
+	assert.True(t, *configs[1].Caching)
+	assert.Equal(t, []llm.CacheBreakpoint{
+		llm.SystemCacheBreakpoint(0),
+		llm.MessageCacheBreakpoint(0),
+	}, configs[1].CacheBreakpoints)
+	assert.False(t, *configs[2].Caching)
+	assert.Len(t, configs[2].CacheBreakpoints, 0)
+}
+
+func TestAutoCacheBreakpoints(t *testing.T) {
+	history := []*llm.Message{
+		llm.NewUserTextMessage("first question"),
+		llm.NewAssistantTextMessage("first answer"),
+		llm.NewUserTextMessage("second question"),
+		{Role: llm.Assistant, Content: []llm.Content{&llm.ToolUseContent{ID: "call_1", Name: "lookup"}}},
+		{Role: llm.User, Content: []llm.Content{&llm.ToolResultContent{ToolUseID: "call_1"}}},
+	}
+	assert.Equal(t, []llm.CacheBreakpoint{
+		llm.SystemCacheBreakpoint(0),
+		llm.MessageCacheBreakpoint(1),
+		llm.MessageCacheBreakpoint(4),
+	}, autoCacheBreakpoints("Be brief.", history))
+
+	// The first turn has no earlier conversation to cache
+	assert.Equal(t, []llm.CacheBreakpoint{llm.MessageCacheBreakpoint(0)},
+		autoCacheBreakpoints("", history[:1]))
+	assert.Len(t, autoCacheBreakpoints("", nil), 0)
+}
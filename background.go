@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/deepnoodle-ai/dive/llm"
-	"github.com/google/uuid"
 )
 
 // backgroundCtxKey is the context key used to propagate a long-lived context
@@ -110,7 +109,7 @@ func NewBackgroundResultFull(ctx context.Context, description string, fn func(ct
 
 func newBackgroundResult(ctx context.Context, description string, fn func(ctx context.Context) *ToolResult) *ToolResult {
 	ch := make(chan *ToolResult, 1)
-	id := uuid.New().String()
+	id := NewID(ctx)
 
 	// Use the outer (pre-batch-cancel) context so the goroutine lives past
 	// the end of the tool batch. backgroundCtxFrom falls back to ctx when no
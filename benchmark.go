@@ -0,0 +1,319 @@
+package dive
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// BenchmarkConfig configures a Benchmark run.
+type BenchmarkConfig struct {
+	// Models are the models to compare. Each is labeled with its provider
+	// name and, for providers that implement llm.ModelInfoProvider, its
+	// configured model, such as "anthropic/claude-sonnet-4-6".
+	Models []llm.LLM
+
+	// Prompts are the requests sent to each model, typically of different
+	// sizes.
+	Prompts []BenchmarkPrompt
+
+	// Options are applied to every request, for example llm.WithMaxTokens
+	// to bound the output length.
+	Options []llm.Option
+
+	// Concurrency is the number of requests in flight at once for each
+	// model and prompt. Defaults to 1.
+	Concurrency int
+
+	// Iterations is the number of measured requests for each model and
+	// prompt. Defaults to 1.
+	Iterations int
+
+	// WarmUp is the number of requests sent for each model and prompt
+	// before measuring, to prime connections and provider caches. Their
+	// results are discarded.
+	WarmUp int
+
+	// Clock times the requests. Defaults to SystemClock.
+	Clock Clock
+}
+
+// BenchmarkPrompt is a named request used by Benchmark.
+type BenchmarkPrompt struct {
+	Name     string
+	Messages []*llm.Message
+}
+
+// BenchmarkReport holds the results of a Benchmark run, one per model and
+// prompt, in the order of BenchmarkConfig.Models and then Prompts. It can be
+// encoded as JSON or written as CSV with WriteCSV.
+type BenchmarkReport struct {
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	Results    []*BenchmarkResult `json:"results"`
+}
+
+// BenchmarkResult measures one model on one prompt. Latencies cover
+// successful requests only.
+type BenchmarkResult struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+
+	// Requests is the number of measured requests and Errors the number
+	// that failed. Error is the first failure's message.
+	Requests int    `json:"requests"`
+	Errors   int    `json:"errors"`
+	Error    string `json:"error,omitempty"`
+
+	// Latency is the time from sending a request to receiving the whole
+	// response.
+	Latency BenchmarkLatency `json:"latency"`
+
+	// TimeToFirstToken is the time from sending a request to receiving the
+	// first content. It is only measured for models that implement
+	// llm.StreamingLLM and is zero otherwise.
+	TimeToFirstToken BenchmarkLatency `json:"time_to_first_token"`
+
+	// TokensPerSecond is the output tokens of all successful requests
+	// divided by their total latency.
+	TokensPerSecond float64 `json:"tokens_per_second"`
+
+	// RequestsPerSecond is the number of successful requests divided by
+	// the time taken to run all of them, which reflects Concurrency.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// BenchmarkLatency summarizes a set of durations. Durations are encoded in
+// JSON as nanoseconds.
+type BenchmarkLatency struct {
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	Mean time.Duration `json:"mean"`
+}
+
+// Benchmark measures the latency, time to first token, and throughput of
+// each model on each prompt. Models and prompts are measured one pair at a
+// time so they don't compete for bandwidth or rate limits, and a failing
+// model is recorded in its results without stopping the run. Benchmark only
+// returns an error for an invalid config or a canceled context.
+func Benchmark(ctx context.Context, cfg BenchmarkConfig) (*BenchmarkReport, error) {
+	if len(cfg.Models) == 0 {
+		return nil, errors.New("benchmark: no models")
+	}
+	if len(cfg.Prompts) == 0 {
+		return nil, errors.New("benchmark: no prompts")
+	}
+	cfg.Concurrency = max(cfg.Concurrency, 1)
+	cfg.Iterations = max(cfg.Iterations, 1)
+	cfg.Clock = ClockOrDefault(cfg.Clock)
+
+	report := &BenchmarkReport{StartedAt: cfg.Clock.Now()}
+	for _, model := range cfg.Models {
+		for _, prompt := range cfg.Prompts {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			opts := append(slices.Clone(cfg.Options), llm.WithMessages(prompt.Messages...))
+			runBenchmarkRequests(ctx, cfg, model, opts, cfg.WarmUp)
+			start := cfg.Clock.Now()
+			samples := runBenchmarkRequests(ctx, cfg, model, opts, cfg.Iterations)
+			elapsed := cfg.Clock.Now().Sub(start)
+			result := summarizeBenchmark(samples, elapsed)
+			result.Model = benchmarkModelName(model)
+			result.Prompt = prompt.Name
+			report.Results = append(report.Results, result)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	report.FinishedAt = cfg.Clock.Now()
+	return report, nil
+}
+
+// benchmarkSample is the measurement of a single request.
+type benchmarkSample struct {
+	latency time.Duration
+	ttft    time.Duration
+	usage   llm.Usage
+	err     error
+}
+
+// runBenchmarkRequests sends n requests to model, at most cfg.Concurrency
+// at a time, and returns their samples in the order they were sent.
+func runBenchmarkRequests(ctx context.Context, cfg BenchmarkConfig, model llm.LLM, opts []llm.Option, n int) []benchmarkSample {
+	samples := make([]benchmarkSample, n)
+	sem := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i := range n {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			samples[i] = measureBenchmarkRequest(ctx, cfg.Clock, model, opts)
+		}()
+	}
+	wg.Wait()
+	return samples
+}
+
+// measureBenchmarkRequest sends one request, streaming it when the model
+// supports streaming so the first token can be timed.
+func measureBenchmarkRequest(ctx context.Context, clock Clock, model llm.LLM, opts []llm.Option) benchmarkSample {
+	start := clock.Now()
+	streamingLLM, ok := model.(llm.StreamingLLM)
+	if !ok {
+		response, err := model.Generate(ctx, opts...)
+		if err != nil {
+			return benchmarkSample{err: err}
+		}
+		return benchmarkSample{latency: clock.Now().Sub(start), usage: response.Usage}
+	}
+
+	iter, err := streamingLLM.Stream(ctx, opts...)
+	if err != nil {
+		return benchmarkSample{err: err}
+	}
+	defer iter.Close()
+	var sample benchmarkSample
+	accum := llm.NewResponseAccumulator()
+	for iter.Next() {
+		event := iter.Event()
+		if sample.ttft == 0 && eventHasContent(event) {
+			sample.ttft = clock.Now().Sub(start)
+		}
+		if err := accum.AddEvent(event); err != nil {
+			return benchmarkSample{err: err}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return benchmarkSample{err: err}
+	}
+	sample.latency = clock.Now().Sub(start)
+	if response := accum.Response(); response != nil {
+		sample.usage = response.Usage
+	}
+	return sample
+}
+
+// summarizeBenchmark computes a result from the samples of one model and
+// prompt, measured over elapsed.
+func summarizeBenchmark(samples []benchmarkSample, elapsed time.Duration) *BenchmarkResult {
+	result := &BenchmarkResult{Requests: len(samples)}
+	var latencies, ttfts []time.Duration
+	var total time.Duration
+	for _, sample := range samples {
+		if sample.err != nil {
+			if result.Errors == 0 {
+				result.Error = sample.err.Error()
+			}
+			result.Errors++
+			continue
+		}
+		latencies = append(latencies, sample.latency)
+		if sample.ttft > 0 {
+			ttfts = append(ttfts, sample.ttft)
+		}
+		total += sample.latency
+		result.InputTokens += sample.usage.InputTokens
+		result.OutputTokens += sample.usage.OutputTokens
+	}
+	result.Latency = summarizeLatencies(latencies)
+	result.TimeToFirstToken = summarizeLatencies(ttfts)
+	if total > 0 {
+		result.TokensPerSecond = float64(result.OutputTokens) / total.Seconds()
+	}
+	if elapsed > 0 {
+		result.RequestsPerSecond = float64(len(latencies)) / elapsed.Seconds()
+	}
+	return result
+}
+
+// summarizeLatencies returns the nearest-rank percentiles and the mean of
+// durations.
+func summarizeLatencies(durations []time.Duration) BenchmarkLatency {
+	if len(durations) == 0 {
+		return BenchmarkLatency{}
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return sorted[max(rank, 1)-1]
+	}
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	return BenchmarkLatency{
+		P50:  percentile(50),
+		P95:  percentile(95),
+		Mean: sum / time.Duration(len(sorted)),
+	}
+}
+
+// benchmarkModelName labels a model in benchmark results.
+func benchmarkModelName(model llm.LLM) string {
+	if provider, ok := model.(llm.ModelInfoProvider); ok {
+		if name := provider.ModelInfo("").Model; name != "" {
+			return model.Name() + "/" + name
+		}
+	}
+	return model.Name()
+}
+
+// benchmarkCSVHeader is the header row written by WriteCSV. Durations are
+// in milliseconds.
+var benchmarkCSVHeader = []string{
+	"model", "prompt", "requests", "errors",
+	"latency_p50_ms", "latency_p95_ms", "latency_mean_ms",
+	"ttft_p50_ms", "ttft_p95_ms", "ttft_mean_ms",
+	"tokens_per_second", "requests_per_second",
+	"input_tokens", "output_tokens", "error",
+}
+
+// WriteCSV writes the results as CSV with a header row, one row per model
+// and prompt. Durations are written in milliseconds.
+func (r *BenchmarkReport) WriteCSV(w io.Writer) error {
+	ms := func(d time.Duration) string {
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+	}
+	rate := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(benchmarkCSVHeader); err != nil {
+		return err
+	}
+	for _, result := range r.Results {
+		row := []string{
+			result.Model, result.Prompt,
+			strconv.Itoa(result.Requests), strconv.Itoa(result.Errors),
+			ms(result.Latency.P50), ms(result.Latency.P95), ms(result.Latency.Mean),
+			ms(result.TimeToFirstToken.P50), ms(result.TimeToFirstToken.P95), ms(result.TimeToFirstToken.Mean),
+			rate(result.TokensPerSecond), rate(result.RequestsPerSecond),
+			strconv.Itoa(result.InputTokens), strconv.Itoa(result.OutputTokens),
+			result.Error,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("write benchmark result: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
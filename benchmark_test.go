@@ -0,0 +1,167 @@
+package dive
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// streamingBenchLLM streams a short text response.
+type streamingBenchLLM struct {
+	calls atomic.Int32
+}
+
+func (m *streamingBenchLLM) Name() string { return "streaming" }
+
+func (m *streamingBenchLLM) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	return nil, errors.New("not used")
+}
+
+func (m *streamingBenchLLM) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIterator, error) {
+	m.calls.Add(1)
+	index := 0
+	return &benchStreamIterator{events: []*llm.Event{
+		{Type: llm.EventTypeMessageStart, Message: &llm.Response{
+			Role:  llm.Assistant,
+			Usage: llm.Usage{InputTokens: 10, OutputTokens: 4},
+		}},
+		{Type: llm.EventTypeContentBlockStart, Index: &index, ContentBlock: &llm.EventContentBlock{Type: llm.ContentTypeText}},
+		{Type: llm.EventTypeContentBlockDelta, Index: &index, Delta: &llm.EventDelta{Type: llm.EventDeltaTypeText, Text: "hello"}},
+		{Type: llm.EventTypeMessageStop},
+	}}, nil
+}
+
+type benchStreamIterator struct {
+	events []*llm.Event
+	cur    *llm.Event
+}
+
+func (s *benchStreamIterator) Next() bool {
+	if len(s.events) == 0 {
+		return false
+	}
+	s.cur, s.events = s.events[0], s.events[1:]
+	return true
+}
+
+func (s *benchStreamIterator) Event() *llm.Event { return s.cur }
+func (s *benchStreamIterator) Err() error        { return nil }
+func (s *benchStreamIterator) Close() error      { return nil }
+
+func TestBenchmark(t *testing.T) {
+	streaming := &streamingBenchLLM{}
+	failing := &mockLLM{
+		nameFunc: func() string { return "failing" },
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			return nil, errors.New("rate limited")
+		},
+	}
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.SetAutoAdvance(10 * time.Millisecond)
+
+	report, err := Benchmark(context.Background(), BenchmarkConfig{
+		Models: []llm.LLM{streaming, failing},
+		Prompts: []BenchmarkPrompt{
+			{Name: "short", Messages: []*llm.Message{llm.NewUserTextMessage("hi")}},
+		},
+		Iterations: 3,
+		WarmUp:     2,
+		Clock:      clock,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), streaming.calls.Load())
+	assert.Len(t, report.Results, 2)
+
+	// Each streamed request reads the clock at its start, first token, and
+	// end, 10ms apart
+	result := report.Results[0]
+	assert.Equal(t, "streaming", result.Model)
+	assert.Equal(t, "short", result.Prompt)
+	assert.Equal(t, 3, result.Requests)
+	assert.Equal(t, 0, result.Errors)
+	assert.Equal(t, BenchmarkLatency{P50: 20 * time.Millisecond, P95: 20 * time.Millisecond, Mean: 20 * time.Millisecond}, result.Latency)
+	assert.Equal(t, 10*time.Millisecond, result.TimeToFirstToken.P50)
+	assert.Equal(t, 30, result.InputTokens)
+	assert.Equal(t, 12, result.OutputTokens)
+	assert.Equal(t, 200.0, result.TokensPerSecond)
+
+	// A failing model doesn't stop the run
+	failed := report.Results[1]
+	assert.Equal(t, "failing", failed.Model)
+	assert.Equal(t, 3, failed.Errors)
+	assert.Equal(t, "rate limited", failed.Error)
+	assert.Equal(t, BenchmarkLatency{}, failed.Latency)
+
+	data, err := json.Marshal(report)
+	assert.NoError(t, err)
+	var decoded BenchmarkReport
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, result, decoded.Results[0])
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteCSV(&buf))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, benchmarkCSVHeader, rows[0])
+	assert.Equal(t, []string{
+		"streaming", "short", "3", "0",
+		"20.000", "20.000", "20.000",
+		"10.000", "10.000", "10.000",
+		"200.00", "30.00", "30", "12", "",
+	}, rows[1])
+}
+
+func TestBenchmarkConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	model := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return &llm.Response{Role: llm.Assistant, Usage: llm.Usage{OutputTokens: 1}}, nil
+		},
+	}
+	report, err := Benchmark(context.Background(), BenchmarkConfig{
+		Models:      []llm.LLM{model},
+		Prompts:     []BenchmarkPrompt{{Name: "p", Messages: []*llm.Message{llm.NewUserTextMessage("hi")}}},
+		Iterations:  8,
+		Concurrency: 4,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, report.Results[0].Requests)
+	assert.True(t, peak.Load() > 1)
+	assert.True(t, peak.Load() <= 4)
+	// Non-streaming models have no time to first token
+	assert.Equal(t, BenchmarkLatency{}, report.Results[0].TimeToFirstToken)
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	var durations []time.Duration
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	stats := summarizeLatencies(durations)
+	assert.Equal(t, 10*time.Millisecond, stats.P50)
+	assert.Equal(t, 19*time.Millisecond, stats.P95)
+	assert.Equal(t, 10500*time.Microsecond, stats.Mean)
+}
+
+func TestBenchmarkInvalidConfig(t *testing.T) {
+	_, err := Benchmark(context.Background(), BenchmarkConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package dive
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time. The agent and the session package read
+// timestamps through a Clock so tests can substitute a FakeClock and get
+// deterministic response and event times.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the default Clock, backed by time.Now.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// ClockOrDefault returns c, or SystemClock if c is nil.
+func ClockOrDefault(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
+
+// WithClock returns a context carrying clock. The agent sets this on the
+// context passed to tools, so tools that read the time with
+// ClockFromContext use the agent's Clock.
+func WithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey, clock)
+}
+
+// ClockFromContext returns the Clock carried by ctx, or SystemClock if there
+// is none.
+func ClockFromContext(ctx context.Context) Clock {
+	clock, _ := ctx.Value(clockKey).(Clock)
+	return ClockOrDefault(clock)
+}
+
+// FakeClock is a manually controlled Clock for tests. Time only moves when
+// Set or Advance is called, or by the auto-advance step on each Now call, so
+// a run seeded with the same start time and step produces the same
+// timestamps every time. It is safe for concurrent use.
+type FakeClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+// NewFakeClock returns a FakeClock that starts at start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the current fake time, then advances it by the auto-advance
+// step, if one is set.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance moves the clock forward by d and returns the new time.
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// SetAutoAdvance makes every call to Now advance the clock by step
+// afterwards, so consecutive timestamps are distinct but still
+// reproducible. A zero step (the default) disables auto-advance.
+func (c *FakeClock) SetAutoAdvance(step time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.step = step
+}
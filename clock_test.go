@@ -0,0 +1,59 @@
+package dive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+	assert.Equal(t, start, clock.Now())
+
+	assert.Equal(t, start.Add(time.Hour), clock.Advance(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+
+	clock.Set(start)
+	clock.SetAutoAdvance(time.Second)
+	assert.Equal(t, start, clock.Now())
+	assert.Equal(t, start.Add(time.Second), clock.Now())
+}
+
+func TestClockOrDefault(t *testing.T) {
+	assert.Equal(t, SystemClock, ClockOrDefault(nil))
+	clock := NewFakeClock(time.Time{})
+	assert.Equal(t, Clock(clock), ClockOrDefault(clock))
+}
+
+func TestClockFromContext(t *testing.T) {
+	assert.Equal(t, SystemClock, ClockFromContext(context.Background()))
+	clock := NewFakeClock(time.Time{})
+	assert.Equal(t, Clock(clock), ClockFromContext(WithClock(context.Background(), clock)))
+}
+
+func TestAgentClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	model := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			clock.Advance(time.Second)
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "hi"}},
+				StopReason: llm.StopReasonEndTurn,
+			}, nil
+		},
+	}
+	agent, err := NewAgent(AgentOptions{Model: model, Clock: clock})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("Hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, start, resp.CreatedAt)
+	assert.Equal(t, start.Add(time.Second), *resp.FinishedAt)
+}
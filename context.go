@@ -1,6 +1,9 @@
 package dive
 
-import "context"
+import (
+	"context"
+	"sync"
+)
 
 type contextKey string
 
@@ -8,6 +11,10 @@ const (
 	toolCallIDKey     contextKey = "tool_call_id"
 	toolStreamFnKey   contextKey = "tool_stream_fn"
 	toolProgressFnKey contextKey = "tool_progress_fn"
+	statusFnKey       contextKey = "status_fn"
+	idSourceKey       contextKey = "id_source"
+	clockKey          contextKey = "clock"
+	responseScopeKey  contextKey = "response_scope"
 )
 
 // WithToolCallID returns a context with the given tool call ID.
@@ -77,3 +84,56 @@ func ReportProgress(ctx context.Context, progress *ToolProgress) {
 	}
 	fn(ToolCallID(ctx), progress)
 }
+
+// WithStatusFunc returns a context with a status function. This is set by the
+// agent before calling a tool, enabling tools to show the user what the agent
+// is doing. The agent re-emits each status as a ResponseItem of type
+// ResponseItemTypeStatus.
+func WithStatusFunc(ctx context.Context, fn func(toolCallID string, text string)) context.Context {
+	return context.WithValue(ctx, statusFnKey, fn)
+}
+
+// ReportStatus shows the user a short status line, such as "Searching the
+// web...", from a running tool. Statuses are ephemeral: they reach the
+// EventCallback but are not part of the response text or the conversation.
+// Safe to call even if no status function is configured (it's a no-op).
+// Empty statuses are dropped.
+func ReportStatus(ctx context.Context, text string) {
+	if text == "" {
+		return
+	}
+	fn, ok := ctx.Value(statusFnKey).(func(string, string))
+	if !ok || fn == nil {
+		return
+	}
+	fn(ToolCallID(ctx), text)
+}
+
+// ResponseScope holds state that lasts for one CreateResponse call, shared
+// by every tool call the response makes. Tools use it to remember what they
+// have already done for the response, such as which search results they
+// have returned. A ResponseScope is safe for concurrent use.
+type ResponseScope struct {
+	values sync.Map
+}
+
+// LoadOrStore returns the value stored for key, or stores and returns value
+// if there is none. Keys should be of an unexported type, as with context
+// values, to avoid collisions between tools.
+func (s *ResponseScope) LoadOrStore(key, value any) any {
+	actual, _ := s.values.LoadOrStore(key, value)
+	return actual
+}
+
+// WithResponseScope returns a context carrying scope. The agent sets a new
+// scope on the context of each CreateResponse call.
+func WithResponseScope(ctx context.Context, scope *ResponseScope) context.Context {
+	return context.WithValue(ctx, responseScopeKey, scope)
+}
+
+// ResponseScopeFrom returns the ResponseScope carried by ctx, or nil when a
+// tool is called outside an agent response.
+func ResponseScopeFrom(ctx context.Context) *ResponseScope {
+	scope, _ := ctx.Value(responseScopeKey).(*ResponseScope)
+	return scope
+}
@@ -0,0 +1,122 @@
+package dive
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// ContextOverflowStrategy controls what the agent does when a request leaves
+// less room than WithReservedOutputTokens asks for. The zero value trims
+// history like ContextOverflowTrimHistory.
+type ContextOverflowStrategy string
+
+const (
+	// ContextOverflowTrimHistory drops the oldest messages of the
+	// model-facing history, a whole exchange at a time, until the request
+	// fits. The saved conversation is not changed. If the request still
+	// doesn't fit with only the latest exchange, it fails with a
+	// *ContextOverflowError.
+	ContextOverflowTrimHistory ContextOverflowStrategy = "trim_history"
+
+	// ContextOverflowFail fails the request with a *ContextOverflowError
+	// before anything is sent to the provider.
+	ContextOverflowFail ContextOverflowStrategy = "fail"
+)
+
+// ContextOverflowError is returned when a request's estimated input leaves
+// less than the reserved number of tokens of the model's context window for
+// output.
+type ContextOverflowError struct {
+	Model                string
+	ContextWindow        int
+	ReservedOutputTokens int
+	InputTokens          int
+}
+
+func (e *ContextOverflowError) Error() string {
+	return fmt.Sprintf("estimated input of %d tokens leaves less than %d of the %d-token context window of model %q for output",
+		e.InputTokens, e.ReservedOutputTokens, e.ContextWindow, e.Model)
+}
+
+// WithReservedOutputTokens reserves n tokens of the model's context window
+// for the response in this call. Before each generation the agent estimates
+// the size of the request (see llm.EstimateTokens) and, when it would leave
+// less than n tokens, handles it according to the ContextOverflowStrategy.
+// It requires a model that implements llm.ModelInfoProvider and reports its
+// context window; other models are sent requests unchanged.
+func WithReservedOutputTokens(n int) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.ReservedOutputTokens = n
+	}
+}
+
+// WithContextOverflowStrategy sets how the agent handles requests that leave
+// less room than WithReservedOutputTokens reserves for this call.
+func WithContextOverflowStrategy(strategy ContextOverflowStrategy) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.ContextOverflowStrategy = strategy
+	}
+}
+
+// fitContextWindow returns the suffix of messages that fits in the model's
+// context window with reserved tokens left for output, or messages itself
+// when it already fits or the window is unknown. A trimmed history always
+// starts at a user message that isn't a tool result, so tool calls stay
+// paired with their results.
+func fitContextWindow(model llm.LLM, modelName, systemPrompt string, tools []Tool, messages []*llm.Message, reserved int, strategy ContextOverflowStrategy) ([]*llm.Message, error) {
+	provider, ok := model.(llm.ModelInfoProvider)
+	if reserved <= 0 || !ok {
+		return messages, nil
+	}
+	info := provider.ModelInfo(modelName)
+	if info.ContextWindow <= 0 {
+		return messages, nil
+	}
+	budget := info.ContextWindow - reserved
+
+	fixed := llm.EstimateTextTokens(systemPrompt, info.Model)
+	for _, tool := range tools {
+		schema, err := json.Marshal(tool.Schema())
+		if err != nil {
+			return nil, fmt.Errorf("tool %q schema: %w", tool.Name(), err)
+		}
+		fixed += llm.EstimateTextTokens(tool.Name()+" "+tool.Description()+" "+string(schema), info.Model)
+	}
+	sizes := make([]int, len(messages))
+	total := fixed
+	for i, msg := range messages {
+		tokens, _, err := llm.EstimateTokens(msg, info.Model)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		sizes[i] = tokens
+		total += tokens
+	}
+	if total <= budget {
+		return messages, nil
+	}
+
+	overflow := &ContextOverflowError{
+		Model:                info.Model,
+		ContextWindow:        info.ContextWindow,
+		ReservedOutputTokens: reserved,
+		InputTokens:          total,
+	}
+	if strategy == ContextOverflowFail {
+		return nil, overflow
+	}
+	for start := 1; start < len(messages); start++ {
+		total -= sizes[start-1]
+		msg := messages[start]
+		if msg.Role != llm.User || hasToolResult(msg) {
+			continue
+		}
+		overflow.InputTokens = total
+		if total <= budget {
+			return messages[start:], nil
+		}
+	}
+	return nil, overflow
+}
@@ -0,0 +1,138 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// windowedLLM is a mockLLM that reports a context window and records the
+// messages of each request.
+type windowedLLM struct {
+	mockLLM
+	window   int
+	requests [][]*llm.Message
+}
+
+func (m *windowedLLM) ModelInfo(model string) llm.ModelInfo {
+	return llm.ModelInfo{Model: "test-model", ContextWindow: m.window}
+}
+
+func newWindowedLLM(window int) *windowedLLM {
+	m := &windowedLLM{window: window}
+	m.generateFunc = func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		cfg := &llm.Config{}
+		cfg.Apply(opts...)
+		m.requests = append(m.requests, cfg.Messages)
+		return &llm.Response{
+			Role:       llm.Assistant,
+			Content:    []llm.Content{&llm.TextContent{Text: "ok"}},
+			StopReason: llm.StopReasonEndTurn,
+		}, nil
+	}
+	return m
+}
+
+// longHistory returns n user/assistant exchanges of about 50 tokens each,
+// followed by a short question.
+func longHistory(n int) []*llm.Message {
+	text := strings.Repeat("word ", 20)
+	var messages []*llm.Message
+	for range n {
+		messages = append(messages, llm.NewUserTextMessage(text), llm.NewAssistantTextMessage(text))
+	}
+	return append(messages, llm.NewUserTextMessage("question"))
+}
+
+func TestReservedOutputTokens(t *testing.T) {
+	t.Run("fits without trimming", func(t *testing.T) {
+		model := newWindowedLLM(10_000)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(),
+			WithMessages(longHistory(3)...),
+			WithReservedOutputTokens(1000))
+		assert.NoError(t, err)
+		assert.Len(t, model.requests[0], 7)
+	})
+
+	t.Run("trims oldest exchanges", func(t *testing.T) {
+		model := newWindowedLLM(300)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		resp, err := agent.CreateResponse(context.Background(),
+			WithMessages(longHistory(5)...),
+			WithReservedOutputTokens(100))
+		assert.NoError(t, err)
+		sent := model.requests[0]
+		assert.True(t, len(sent) < 11)
+		assert.Equal(t, llm.User, sent[0].Role)
+		assert.Equal(t, "question", sent[len(sent)-1].Text())
+		assert.Equal(t, "ok", resp.OutputText())
+	})
+
+	t.Run("fail strategy", func(t *testing.T) {
+		model := newWindowedLLM(300)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(),
+			WithMessages(longHistory(5)...),
+			WithReservedOutputTokens(100),
+			WithContextOverflowStrategy(ContextOverflowFail))
+		var overflow *ContextOverflowError
+		assert.True(t, errors.As(err, &overflow))
+		assert.Equal(t, 300, overflow.ContextWindow)
+		assert.Equal(t, 100, overflow.ReservedOutputTokens)
+		assert.Len(t, model.requests, 0)
+	})
+
+	t.Run("latest exchange too large", func(t *testing.T) {
+		model := newWindowedLLM(300)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(),
+			WithInput(strings.Repeat("word ", 500)),
+			WithReservedOutputTokens(100))
+		var overflow *ContextOverflowError
+		assert.True(t, errors.As(err, &overflow))
+		assert.True(t, overflow.InputTokens > 200)
+	})
+
+	t.Run("unknown window is ignored", func(t *testing.T) {
+		model := newWindowedLLM(0)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(),
+			WithMessages(longHistory(5)...),
+			WithReservedOutputTokens(100))
+		assert.NoError(t, err)
+		assert.Len(t, model.requests[0], 11)
+	})
+}
+
+func TestFitContextWindowKeepsToolResultsPaired(t *testing.T) {
+	model := newWindowedLLM(200)
+	text := strings.Repeat("word ", 20)
+	messages := []*llm.Message{
+		llm.NewUserTextMessage(text),
+		{Role: llm.Assistant, Content: []llm.Content{&llm.ToolUseContent{ID: "call_1", Name: "Read", Input: []byte(`{}`)}}},
+		{Role: llm.User, Content: []llm.Content{&llm.ToolResultContent{ToolUseID: "call_1", Content: text}}},
+		llm.NewAssistantTextMessage(text),
+		llm.NewUserTextMessage("question"),
+	}
+
+	fitted, err := fitContextWindow(model, "", "", nil, messages, 150, "")
+	assert.NoError(t, err)
+	// The tool result can't start the history, so trimming skips past it
+	assert.Len(t, fitted, 1)
+	assert.Equal(t, "question", fitted[0].Text())
+}
@@ -0,0 +1,110 @@
+package dive
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DateGranularity sets how precisely ContextInjection reports the current
+// time. Coarser granularity keeps the system prompt identical for longer,
+// which preserves provider prompt caches.
+type DateGranularity string
+
+const (
+	// DateGranularityDay reports the date only. This is the default, and
+	// changes the system prompt at most once a day.
+	DateGranularityDay DateGranularity = "day"
+
+	// DateGranularityHour reports the date and the hour.
+	DateGranularityHour DateGranularity = "hour"
+
+	// DateGranularityMinute reports the date and time to the minute. The
+	// system prompt changes every minute, so prompt caches rarely hit
+	// across turns.
+	DateGranularityMinute DateGranularity = "minute"
+)
+
+// ContextInjection selects environment facts that the agent prepends to the
+// system prompt on each CreateResponse call, so the model doesn't have to
+// guess them. Models trained months ago often assume the wrong date.
+//
+// The block is rendered from the agent's Clock and the process environment.
+// It is identical across calls until one of its facts changes, so it doesn't
+// invalidate prompt caches mid-session unless Granularity is too fine.
+type ContextInjection struct {
+	// Date includes the current date, and with a Granularity finer than
+	// DateGranularityDay, the time.
+	Date bool
+
+	// Workspace includes the working directory.
+	Workspace bool
+
+	// OS includes the operating system and architecture.
+	OS bool
+
+	// Location is the time zone the date is reported in. Defaults to
+	// time.Local.
+	Location *time.Location
+
+	// Granularity is how precisely the time is reported. Defaults to
+	// DateGranularityDay.
+	Granularity DateGranularity
+
+	// WorkspaceDir overrides the reported working directory. Defaults to the
+	// process working directory.
+	WorkspaceDir string
+}
+
+// render returns the context block for the given time, or "" if no facts
+// are selected.
+func (c *ContextInjection) render(now time.Time) string {
+	var lines []string
+	if c.Date {
+		loc := c.Location
+		if loc == nil {
+			loc = time.Local
+		}
+		now = now.In(loc)
+		switch c.Granularity {
+		case DateGranularityMinute:
+			lines = append(lines, "Current date and time: "+now.Format("Monday, January 2, 2006, 3:04 PM MST"))
+		case DateGranularityHour:
+			lines = append(lines, "Current date and time: "+now.Format("Monday, January 2, 2006, 3 PM MST"))
+		default:
+			lines = append(lines, "Current date: "+now.Format("Monday, January 2, 2006")+" ("+loc.String()+")")
+		}
+	}
+	if c.Workspace {
+		dir := c.WorkspaceDir
+		if dir == "" {
+			dir, _ = os.Getwd()
+		}
+		if dir != "" {
+			lines = append(lines, "Workspace: "+dir)
+		}
+	}
+	if c.OS {
+		lines = append(lines, "OS: "+runtime.GOOS+"/"+runtime.GOARCH)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "<environment>\n" + strings.Join(lines, "\n") + "\n</environment>"
+}
+
+// injectContext prepends the rendered context block to systemPrompt.
+func injectContext(systemPrompt string, injection *ContextInjection, now time.Time) string {
+	if injection == nil {
+		return systemPrompt
+	}
+	block := injection.render(now)
+	if block == "" {
+		return systemPrompt
+	}
+	if systemPrompt == "" {
+		return block
+	}
+	return block + "\n\n" + systemPrompt
+}
@@ -0,0 +1,78 @@
+package dive
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestContextInjectionRender(t *testing.T) {
+	now := time.Date(2026, 3, 5, 14, 37, 0, 0, time.UTC)
+	ny, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	day := &ContextInjection{Date: true, Location: ny}
+	assert.Equal(t, "<environment>\nCurrent date: Thursday, March 5, 2026 (America/New_York)\n</environment>",
+		day.render(now))
+
+	// Day granularity is stable within a day, so the prompt prefix stays cacheable.
+	assert.Equal(t, day.render(now), day.render(now.Add(time.Hour)))
+
+	hour := &ContextInjection{Date: true, Location: time.UTC, Granularity: DateGranularityHour}
+	assert.Contains(t, hour.render(now), "Current date and time: Thursday, March 5, 2026, 2 PM UTC")
+	assert.Equal(t, hour.render(now), hour.render(now.Add(10*time.Minute)))
+
+	minute := &ContextInjection{Date: true, Location: time.UTC, Granularity: DateGranularityMinute}
+	assert.Contains(t, minute.render(now), "2:37 PM UTC")
+
+	env := &ContextInjection{Workspace: true, WorkspaceDir: "/srv/app", OS: true}
+	assert.Equal(t, "<environment>\nWorkspace: /srv/app\nOS: "+runtime.GOOS+"/"+runtime.GOARCH+"\n</environment>",
+		env.render(now))
+
+	assert.Equal(t, "", (&ContextInjection{}).render(now))
+	assert.Equal(t, "prompt", injectContext("prompt", nil, now))
+	assert.Equal(t, "prompt", injectContext("prompt", &ContextInjection{}, now))
+}
+
+func TestAgentContextInjection(t *testing.T) {
+	var prompts []string
+	model := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			var config llm.Config
+			config.Apply(opts...)
+			prompts = append(prompts, config.SystemPrompt)
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "ok"}},
+				StopReason: llm.StopReasonEndTurn,
+			}, nil
+		},
+	}
+	agent, err := NewAgent(AgentOptions{
+		Model:            model,
+		SystemPrompt:     "You are helpful.",
+		Clock:            NewFakeClock(time.Date(2026, 3, 5, 14, 0, 0, 0, time.UTC)),
+		ContextInjection: &ContextInjection{Date: true, Location: time.UTC},
+	})
+	assert.NoError(t, err)
+
+	_, err = agent.CreateResponse(context.Background(), WithInput("Hi"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(prompts[0],
+		"<environment>\nCurrent date: Thursday, March 5, 2026 (UTC)\n</environment>\n\nYou are helpful."))
+
+	// The per-call option overrides the agent default.
+	_, err = agent.CreateResponse(context.Background(), WithInput("Hi"),
+		WithContextInjection(ContextInjection{Workspace: true, WorkspaceDir: "/srv/app"}))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(prompts[1], "<environment>\nWorkspace: /srv/app\n</environment>\n\nYou are helpful."))
+
+	_, err = agent.CreateResponse(context.Background(), WithInput("Hi"), WithContextInjection(ContextInjection{}))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(prompts[2], "You are helpful."))
+}
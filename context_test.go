@@ -71,3 +71,61 @@ func TestStreamOutputAndReportProgress_AreIndependent(t *testing.T) {
 	assert.Equal(t, 2, streamCalls)
 	assert.Equal(t, 1, progressCalls)
 }
+
+// TestReportStatus_EmitsResponseItem verifies that statuses reported by a
+// tool reach the event callback as status items, not as response text.
+func TestReportStatus_EmitsResponseItem(t *testing.T) {
+	ReportStatus(context.Background(), "no-op without a status function")
+
+	tool := &mockTool{
+		name: "status",
+		callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			ReportStatus(ctx, "Searching the web...")
+			ReportStatus(ctx, "")
+			return NewToolResultText("ok"), nil
+		},
+	}
+	agent, err := NewAgent(AgentOptions{Model: toolCallingLLM("status", 1), Tools: []Tool{tool}})
+	assert.NoError(t, err)
+
+	var statuses []*StatusEvent
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"),
+		WithEventCallback(func(ctx context.Context, item *ResponseItem) error {
+			if item.Type == ResponseItemTypeStatus {
+				statuses = append(statuses, item.Status)
+			}
+			return nil
+		}))
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, "call_1", statuses[0].ToolCallID)
+	assert.Equal(t, "Searching the web...", statuses[0].Text)
+	assert.Equal(t, "done", resp.OutputText())
+}
+
+func TestResponseScope_SharedWithinResponse(t *testing.T) {
+	assert.Nil(t, ResponseScopeFrom(context.Background()))
+
+	var scopes []*ResponseScope
+	tool := &mockTool{
+		name: "scope",
+		callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			scopes = append(scopes, ResponseScopeFrom(ctx))
+			return NewToolResultText("ok"), nil
+		},
+	}
+	for range 2 {
+		agent, err := NewAgent(AgentOptions{Model: toolCallingLLM("scope", 2), Tools: []Tool{tool}})
+		assert.NoError(t, err)
+		_, err = agent.CreateResponse(context.Background(), WithInput("go"))
+		assert.NoError(t, err)
+	}
+	assert.Len(t, scopes, 4)
+	assert.NotNil(t, scopes[0])
+	assert.True(t, scopes[0] == scopes[1])
+	assert.True(t, scopes[1] != scopes[2])
+	assert.True(t, scopes[2] == scopes[3])
+
+	assert.Equal(t, 1, scopes[0].LoadOrStore("key", 1))
+	assert.Equal(t, 1, scopes[0].LoadOrStore("key", 2))
+}
@@ -0,0 +1,106 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// ErrNotContinuable is returned by Response.Continue for a response that
+// wasn't truncated or that wasn't returned by an agent in this process,
+// such as one decoded from JSON.
+var ErrNotContinuable = errors.New("dive: response cannot be continued")
+
+// continuation is the state Response.Continue needs to resume a truncated
+// response: the agent that generated it and either the session holding the
+// conversation or, for stateless calls, the conversation itself.
+type continuation struct {
+	agent    *Agent
+	session  Session
+	messages []*llm.Message
+}
+
+// Continue generates the rest of a response that was cut off by the output
+// token limit. It sends AutoContinuePrompt after the partial assistant
+// message, through the same session when the response used one, and
+// returns a Response that joins both parts: Items and OutputMessages hold
+// the original output, the prompt, and the continuation, and Usage is the
+// total. The text of the answer is spread across the assistant messages,
+// as with WithAutoContinue. The returned response can itself be continued
+// if it is also truncated.
+//
+// When the model's provider stores responses (see llm.ResponseContinuer),
+// the continuation references the partial response with
+// WithPreviousResponseID instead of resending the conversation. opts, such
+// as WithEventCallback, are applied after the options Continue sets.
+func (r *Response) Continue(ctx context.Context, opts ...CreateResponseOption) (*Response, error) {
+	if !r.Truncated {
+		return nil, fmt.Errorf("%w: it was not truncated", ErrNotContinuable)
+	}
+	c := r.continuation
+	if c == nil {
+		return nil, fmt.Errorf("%w: it was not returned by an agent in this process", ErrNotContinuable)
+	}
+
+	prompt := llm.NewUserTextMessage(AutoContinuePrompt)
+	var callOpts []CreateResponseOption
+	if c.session != nil {
+		callOpts = append(callOpts, WithSession(c.session), WithMessages(prompt))
+	} else {
+		callOpts = append(callOpts, WithMessages(append(slices.Clone(c.messages), prompt)...))
+	}
+	if id := lastAssistantMessageID(r.OutputMessages); id != "" && c.agent.continuesResponses() {
+		callOpts = append(callOpts, WithPreviousResponseID(id))
+	}
+	if len(r.Metadata) > 0 {
+		callOpts = append(callOpts, WithMetadata(r.Metadata))
+	}
+	next, err := c.agent.CreateResponse(ctx, append(callOpts, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &llm.Usage{}
+	if r.Usage != nil {
+		usage.Add(r.Usage)
+	}
+	if next.Usage != nil {
+		usage.Add(next.Usage)
+	}
+	joined := *next
+	joined.CreatedAt = r.CreatedAt
+	joined.Usage = usage
+	joined.Items = slices.Concat(r.Items, next.Items)
+	joined.OutputMessages = slices.Concat(r.OutputMessages, []*llm.Message{prompt}, next.OutputMessages)
+	joined.BackgroundTasks = slices.Concat(r.BackgroundTasks, next.BackgroundTasks)
+	return &joined, nil
+}
+
+// continuesResponses reports whether the agent's model stores responses so
+// a continuation can reference them by ID.
+func (a *Agent) continuesResponses() bool {
+	a.mu.Lock()
+	model := a.model
+	a.mu.Unlock()
+	continuer, ok := model.(llm.ResponseContinuer)
+	if !ok {
+		return false
+	}
+	config := &llm.Config{}
+	config.Apply(a.getGenerationOptions("", nil)...)
+	return continuer.ContinuesResponses(config)
+}
+
+// lastAssistantMessageID returns the ID of the last assistant message, or
+// an empty string if it has none.
+func lastAssistantMessageID(messages []*llm.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == llm.Assistant {
+			return messages[i].ID
+		}
+	}
+	return ""
+}
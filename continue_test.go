@@ -0,0 +1,119 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// truncatingLLM returns responses cut off by the output token limit until
+// call number complete, recording the config of each request.
+func truncatingLLM(complete int, configs *[]*llm.Config) *mockLLM {
+	return &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			config := &llm.Config{}
+			config.Apply(opts...)
+			*configs = append(*configs, config)
+			stopReason := llm.StopReasonMaxTokens
+			if len(*configs) >= complete {
+				stopReason = llm.StopReasonEndTurn
+			}
+			return &llm.Response{
+				ID:         fmt.Sprintf("resp_%d", len(*configs)),
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: fmt.Sprintf("part %d", len(*configs))}},
+				StopReason: stopReason,
+				Usage:      llm.Usage{InputTokens: 10, OutputTokens: 5},
+			}, nil
+		},
+	}
+}
+
+// storingLLM is a model whose provider stores responses.
+type storingLLM struct {
+	*mockLLM
+}
+
+func (storingLLM) ContinuesResponses(*llm.Config) bool { return true }
+
+func TestResponseContinue(t *testing.T) {
+	var configs []*llm.Config
+	agent, err := NewAgent(AgentOptions{Model: truncatingLLM(3, &configs)})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("Write a long story"))
+	assert.NoError(t, err)
+	assert.True(t, resp.Truncated)
+
+	resp, err = resp.Continue(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, resp.Truncated)
+	resp, err = resp.Continue(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, resp.Truncated)
+
+	// The last request carries the whole conversation, without a previous
+	// response ID since the model doesn't store responses
+	last := configs[2]
+	assert.Equal(t, "", last.PreviousResponseID)
+	assert.Len(t, last.Messages, 5)
+	assert.Equal(t, "Write a long story", last.Messages[0].Text())
+	assert.Equal(t, "part 2", last.Messages[3].Text())
+	assert.Equal(t, AutoContinuePrompt, last.Messages[4].Text())
+
+	// assistant, continue prompt, assistant, continue prompt, assistant
+	assert.Len(t, resp.OutputMessages, 5)
+	assert.Equal(t, AutoContinuePrompt, resp.OutputMessages[1].Text())
+	assert.Equal(t, "part 3", resp.OutputText())
+	assert.Len(t, resp.Items, 3)
+	assert.Equal(t, 30, resp.Usage.InputTokens)
+	assert.Equal(t, 15, resp.Usage.OutputTokens)
+
+	_, err = resp.Continue(context.Background())
+	assert.True(t, errors.Is(err, ErrNotContinuable))
+}
+
+func TestResponseContinueWithSession(t *testing.T) {
+	var configs []*llm.Config
+	agent, err := NewAgent(AgentOptions{Model: truncatingLLM(2, &configs)})
+	assert.NoError(t, err)
+	sess := &seededSession{id: "continue"}
+
+	resp, err := agent.CreateResponse(context.Background(), WithSession(sess), WithInput("Write a long story"))
+	assert.NoError(t, err)
+	resp, err = resp.Continue(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, resp.Truncated)
+	assert.Len(t, resp.OutputMessages, 3)
+
+	// Both turns are saved to the session
+	assert.Len(t, sess.messages, 4)
+	assert.Equal(t, AutoContinuePrompt, sess.messages[2].Text())
+	assert.Equal(t, "part 2", sess.messages[3].Text())
+}
+
+func TestResponseContinueStoredResponse(t *testing.T) {
+	var configs []*llm.Config
+	agent, err := NewAgent(AgentOptions{Model: storingLLM{truncatingLLM(2, &configs)}})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("Write a long story"))
+	assert.NoError(t, err)
+	_, err = resp.Continue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "", configs[0].PreviousResponseID)
+	assert.Equal(t, "resp_1", configs[1].PreviousResponseID)
+}
+
+func TestResponseContinueNotContinuable(t *testing.T) {
+	_, err := (&Response{}).Continue(context.Background())
+	assert.True(t, errors.Is(err, ErrNotContinuable))
+
+	// A truncated response decoded from JSON has no agent to continue it
+	_, err = (&Response{Truncated: true}).Continue(context.Background())
+	assert.True(t, errors.Is(err, ErrNotContinuable))
+}
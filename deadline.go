@@ -0,0 +1,58 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// StopReasonDeadline is the Response.StopReason of a call that ran out of
+// the time given by WithDeadline.
+const StopReasonDeadline = "deadline"
+
+// errRunDeadline is the cancellation cause when a WithDeadline budget runs
+// out.
+var errRunDeadline = errors.New("run deadline exceeded")
+
+// WithDeadline caps the wall-clock time of this call, counted from when
+// CreateResponse is called. When the time runs out, the model request or
+// tool calls in flight are canceled, and CreateResponse returns the partial
+// Response with StopReason set to StopReasonDeadline instead of an error.
+// The partial response has the usage, items, and output messages produced
+// so far, and may end with a tool results message rather than an answer.
+// Like a failed turn, it isn't saved to the session (see GenerationError).
+//
+// Unlike AgentOptions.ResponseTimeout, which fails the call, WithDeadline
+// bounds an autonomous run and keeps its work. A deadline on ctx still
+// fails the call with an error.
+func WithDeadline(d time.Duration) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.Deadline = d
+	}
+}
+
+// deadlineExceeded reports whether err ended a call because the
+// WithDeadline budget of runCtx ran out, rather than ctx being canceled.
+func deadlineExceeded(ctx, runCtx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil && context.Cause(runCtx) == errRunDeadline
+}
+
+// deadlineResponse fills in response as the partial result of a call that
+// ran out of time, from the work in err if it is a *GenerationError.
+func deadlineResponse(response *Response, err error, now time.Time) *Response {
+	response.Usage = &llm.Usage{}
+	var genErr *GenerationError
+	if errors.As(err, &genErr) {
+		if genErr.Usage != nil {
+			response.Usage = genErr.Usage
+		}
+		response.Items = slices.Clone(genErr.Items)
+		response.OutputMessages = slices.Clone(genErr.OutputMessages)
+	}
+	response.FinishedAt = Ptr(now)
+	response.StopReason = StopReasonDeadline
+	return response
+}
@@ -0,0 +1,75 @@
+package dive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestWithDeadline(t *testing.T) {
+	// The model calls a tool that runs until it is canceled
+	generations := 0
+	model := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			generations++
+			return &llm.Response{
+				Role: llm.Assistant,
+				Content: []llm.Content{&llm.ToolUseContent{
+					ID: "call_1", Name: "build", Input: []byte(`{}`),
+				}},
+				StopReason: llm.StopReasonToolUse,
+				Usage:      llm.Usage{InputTokens: 10, OutputTokens: 5},
+			}, nil
+		},
+	}
+	build := &mockTool{name: "build", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	}}
+	sess := newMemSession("deadline")
+	agent, err := NewAgent(AgentOptions{Model: model, Tools: []Tool{build}, Session: sess})
+	assert.NoError(t, err)
+
+	start := time.Now()
+	resp, err := agent.CreateResponse(context.Background(), WithInput("build it"), WithDeadline(50*time.Millisecond))
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.Equal(t, StopReasonDeadline, resp.StopReason)
+	assert.Equal(t, 1, generations)
+	assert.Equal(t, 10, resp.Usage.InputTokens)
+	assert.NotNil(t, resp.FinishedAt)
+
+	// The tool call and its canceled result are kept
+	assert.Len(t, resp.OutputMessages, 2)
+	results := resp.ToolCallResults()
+	assert.Len(t, results, 1)
+	assert.Error(t, results[0].Error)
+
+	// The partial turn isn't saved
+	messages, err := sess.Messages(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 0)
+}
+
+func TestWithDeadlineCallerCanceled(t *testing.T) {
+	model := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	agent, err := NewAgent(AgentOptions{Model: model})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	resp, err := agent.CreateResponse(ctx, WithInput("hi"), WithDeadline(time.Minute))
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
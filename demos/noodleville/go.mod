@@ -10,7 +10,9 @@ require (
 require (
 	github.com/google/go-cmp v0.7.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/image v0.41.0 // indirect
 	golang.org/x/sys v0.45.0 // indirect
+	golang.org/x/time v0.14.0 // indirect
 )
 
 replace github.com/deepnoodle-ai/dive => ../..
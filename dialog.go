@@ -16,6 +16,7 @@ import (
 //   - Select mode: set Options (pick one)
 //   - MultiSelect mode: set Options and MultiSelect=true (pick many)
 //   - Input mode: none of the above (free-form text)
+//   - Batch mode: set Items (several prompts answered together)
 //
 // Example implementation for auto-approve:
 //
@@ -68,6 +69,13 @@ type DialogInput struct {
 
 	// Call is the specific tool invocation (optional, for context).
 	Call *llm.ToolUseContent
+
+	// Items holds the prompts of a batch dialog, which presents them all in
+	// one interaction, for example every tool confirmation of a turn. The
+	// response's Items field holds one answer per item, in the same order.
+	// Title and Message describe the batch as a whole; the other fields are
+	// unused.
+	Items []*DialogInput
 }
 
 // DialogOption represents a selectable choice.
@@ -103,6 +111,25 @@ type DialogOutput struct {
 
 	// Feedback contains user-provided text when denying (e.g., "try a different approach").
 	Feedback string
+
+	// Items contains the answers for a batch dialog, one per input item, in
+	// the same order. Missing answers are treated as canceled.
+	Items []*DialogOutput
+}
+
+// ShowBatch presents the items of a batch dialog one at a time using d and
+// collects the answers. Dialog implementations with no batch presentation of
+// their own can use it to handle DialogInput.Items.
+func ShowBatch(ctx context.Context, d Dialog, in *DialogInput) (*DialogOutput, error) {
+	out := &DialogOutput{Items: make([]*DialogOutput, len(in.Items))}
+	for i, item := range in.Items {
+		itemOut, err := d.Show(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		out.Items[i] = itemOut
+	}
+	return out, nil
 }
 
 // AutoApproveDialog automatically approves confirmations and selects first/default options.
@@ -111,6 +138,11 @@ type AutoApproveDialog struct{}
 var _ Dialog = &AutoApproveDialog{}
 
 func (d *AutoApproveDialog) Show(ctx context.Context, in *DialogInput) (*DialogOutput, error) {
+	// Batch mode: answer each item
+	if len(in.Items) > 0 {
+		return ShowBatch(ctx, d, in)
+	}
+
 	// Confirm mode: always approve
 	if in.Confirm {
 		return &DialogOutput{Confirmed: true}, nil
@@ -134,6 +166,9 @@ type DenyAllDialog struct{}
 var _ Dialog = &DenyAllDialog{}
 
 func (d *DenyAllDialog) Show(ctx context.Context, in *DialogInput) (*DialogOutput, error) {
+	if len(in.Items) > 0 {
+		return ShowBatch(ctx, d, in)
+	}
 	if in.Confirm {
 		return &DialogOutput{Confirmed: false}, nil
 	}
@@ -48,6 +48,10 @@ func NewTerminalDialogWithOptions(opts TerminalDialogOptions) *TerminalDialog {
 }
 
 func (d *TerminalDialog) Show(ctx context.Context, in *DialogInput) (*DialogOutput, error) {
+	if len(in.Items) > 0 {
+		d.printHeader(in)
+		return ShowBatch(ctx, d, in)
+	}
 	if in.Confirm {
 		return d.showConfirm(ctx, in)
 	}
@@ -3,6 +3,8 @@ package dive
 import (
 	"context"
 	"errors"
+	"maps"
+	"time"
 
 	"github.com/deepnoodle-ai/dive/llm"
 )
@@ -111,6 +113,10 @@ type CreateResponseOptions struct {
 	// but excluded from OutputMessages and session persistence.
 	ModelOnlyReminders []Reminder
 
+	// InputParts are assembled into a user message appended after
+	// Messages. Set via WithInputParts.
+	InputParts []InputPart
+
 	// EventCallback is invoked for each response item during generation.
 	// Callbacks include messages, tool calls, and tool results.
 	EventCallback EventCallback
@@ -120,6 +126,11 @@ type CreateResponseOptions struct {
 	// data to hooks (e.g. session IDs) through CreateResponse options.
 	Values map[string]any
 
+	// Metadata holds caller-defined identifiers echoed on the Response,
+	// its events, and its logs, but never sent to the model. Set via
+	// WithMetadata.
+	Metadata map[string]any
+
 	// Session overrides AgentOptions.Session for this call.
 	// Useful in server scenarios where one agent serves multiple sessions.
 	Session Session
@@ -147,6 +158,63 @@ type CreateResponseOptions struct {
 	// start of the next generation. Set via WithBackgroundResults.
 	BackgroundHandles []*BackgroundTaskHandle
 	BackgroundResults map[string]*ToolResult
+
+	// AutoContinue is the maximum number of times the agent resends a
+	// response that was truncated by the output token limit, asking the
+	// model to continue where it left off. Zero disables auto-continuation.
+	// Set via WithAutoContinue.
+	AutoContinue int
+
+	// PreviousResponseID names a response stored by the provider that this
+	// call continues, so the messages up to it aren't resent. Set via
+	// WithPreviousResponseID.
+	PreviousResponseID string
+
+	// AutoPromptCaching, when non-nil, overrides the provider's prompt
+	// caching setting for this call. Set via WithAutoPromptCaching.
+	AutoPromptCaching *bool
+
+	// ToolSchemaStrategy controls how tool definitions that exceed the
+	// provider's limits are handled. Set via WithToolSchemaStrategy.
+	ToolSchemaStrategy ToolSchemaStrategy
+
+	// ContextInjection, when non-nil, overrides AgentOptions.ContextInjection
+	// for this call. Set via WithContextInjection.
+	ContextInjection *ContextInjection
+
+	// ToolCache, when non-nil, serves repeated read-only tool calls from
+	// cached results. Set via WithToolCache.
+	ToolCache *ToolCache
+
+	// ThinkingHistory, when non-empty, overrides AgentOptions.ThinkingHistory
+	// for this call. Set via WithThinkingHistory.
+	ThinkingHistory ThinkingHistory
+
+	// RefusalRetry, when non-nil, retries responses the model refused with a
+	// rephrased prompt. Set via WithRefusalRetry.
+	RefusalRetry *RefusalRetry
+
+	// ReservedOutputTokens is the number of tokens of the model's context
+	// window to keep free for the response. Zero disables the check. Set via
+	// WithReservedOutputTokens.
+	ReservedOutputTokens int
+
+	// ContextOverflowStrategy controls what happens when ReservedOutputTokens
+	// can't be met. Set via WithContextOverflowStrategy.
+	ContextOverflowStrategy ContextOverflowStrategy
+
+	// OversizedMessagePolicy enables the request size check and controls
+	// what happens when a single message is too large for the model's
+	// context window. Set via WithOversizedMessagePolicy.
+	OversizedMessagePolicy OversizedMessagePolicy
+
+	// StopConditions can end the call early, before the next model request.
+	// Set via WithStopCondition.
+	StopConditions []StopCondition
+
+	// Deadline caps the wall-clock time of the call. Zero means no cap. Set
+	// via WithDeadline.
+	Deadline time.Duration
 }
 
 // EventCallback is a function called with each item produced while an agent
@@ -207,6 +275,20 @@ func WithValue(key string, value any) CreateResponseOption {
 	}
 }
 
+// WithMetadata attaches metadata, such as request, user, or trace IDs, to
+// this call for correlation. It is echoed on Response.Metadata, on every
+// ResponseItem passed to the event callback, and as a "metadata" attribute
+// on the agent's logs for the call. It is kept out of the model's context.
+// Multiple WithMetadata calls merge, with later keys winning.
+func WithMetadata(metadata map[string]any) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		if opts.Metadata == nil {
+			opts.Metadata = make(map[string]any, len(metadata))
+		}
+		maps.Copy(opts.Metadata, metadata)
+	}
+}
+
 // WithSession overrides the agent's default session for a single call.
 // This is useful in server scenarios where one agent handles multiple sessions.
 func WithSession(s Session) CreateResponseOption {
@@ -215,6 +297,108 @@ func WithSession(s Session) CreateResponseOption {
 	}
 }
 
+// DefaultAutoContinueLimit is the number of continuations WithAutoContinue
+// allows per CreateResponse call.
+const DefaultAutoContinueLimit = 3
+
+// AutoContinuePrompt is the user message sent after a response is truncated
+// by the output token limit when auto-continuation is enabled.
+const AutoContinuePrompt = "Your previous response was cut off by the output token limit. Continue exactly where you left off, without repeating any content."
+
+// WithAutoContinue makes the agent continue responses that stop because they
+// hit the output token limit (llm.StopReasonMaxTokens). The agent appends
+// AutoContinuePrompt as a user message and generates again, up to
+// DefaultAutoContinueLimit times. The continuation messages are included in
+// Response.OutputMessages, and the full text is spread across the assistant
+// messages. Set CreateResponseOptions.AutoContinue directly for a different
+// limit.
+func WithAutoContinue() CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.AutoContinue = DefaultAutoContinueLimit
+	}
+}
+
+// WithPreviousResponseID continues a response the provider stored, such as
+// an OpenAI Responses API response generated with the openai:store provider
+// option. The ID is passed to each model request with
+// llm.WithPreviousResponseID, and the messages up to and including the
+// assistant message with that ID are not resent. Providers that don't store
+// responses ignore it. Response.Continue sets it automatically.
+func WithPreviousResponseID(id string) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.PreviousResponseID = id
+	}
+}
+
+// WithAutoPromptCaching controls automatic prompt caching for this call. When
+// enabled, the agent passes llm.WithCacheBreakpoints at the end of the system
+// prompt (caching the tool definitions too), after the last message of the
+// previous turn, and after the last message, moving the latter two forward
+// with each request of the turn. Providers that support explicit breakpoints
+// (Anthropic) mark only those; providers that cache automatically, such as
+// OpenAI, ignore them. The agent logs cache read and write token counts for
+// the turn, with estimated savings when model pricing is known.
+//
+// Pass false to disable caching for a call. Without this option, providers
+// place breakpoints their own way, and ModelSettings.Caching sets the
+// agent-wide default.
+func WithAutoPromptCaching(enabled bool) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.AutoPromptCaching = &enabled
+	}
+}
+
+// WithContextInjection prepends a block of environment facts to the system
+// prompt for this call, such as the current date, the working directory, and
+// the OS:
+//
+//	resp, err := agent.CreateResponse(ctx,
+//	    dive.WithInput("What day is it?"),
+//	    dive.WithContextInjection(dive.ContextInjection{Date: true, OS: true}),
+//	)
+//
+// Pass an empty ContextInjection to disable an agent-wide default.
+func WithContextInjection(injection ContextInjection) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.ContextInjection = &injection
+	}
+}
+
+// WithThinkingHistory controls whether thinking blocks from earlier turns
+// are sent back to the model for this call. Excluding them saves input tokens
+// on long reasoning conversations; thinking in the turn in progress is always
+// kept, as providers require it for tool-use continuations. See
+// ThinkingHistory.
+func WithThinkingHistory(mode ThinkingHistory) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.ThinkingHistory = mode
+	}
+}
+
+// WithRefusalRetry retries a response the model refused, sending the user
+// prompt rewritten by rephrase instead, up to maxAttempts times. Only the
+// prompt sent to the model changes; the session keeps the original input. If
+// the model still refuses, the refusal is reported in Response.Refusal.
+//
+// Only refusals the model explains itself (RefusalKindModel) are retried.
+// Provider policy blocks and content filter stops concern genuinely
+// disallowed content and are returned as-is.
+func WithRefusalRetry(rephrase func(prompt string) string, maxAttempts int) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.RefusalRetry = &RefusalRetry{Rephrase: rephrase, MaxAttempts: maxAttempts}
+	}
+}
+
+// WithToolCache caches the results of read-only tool calls for this call,
+// so repeated identical calls (such as reading an unchanged file) return the
+// earlier result. Pass the same cache to later calls to share results across
+// them. See ToolCache.
+func WithToolCache(cache *ToolCache) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.ToolCache = cache
+	}
+}
+
 // WithToolResults supplies externally-obtained tool results to resume a
 // session-backed suspended agent. The keys are tool_call IDs taken from a
 // prior Response.Suspension.PendingToolCalls. The values are
@@ -0,0 +1,88 @@
+// Package embedding provides vector math for comparing embeddings, such as
+// those returned by an embeddings API, without a separate vector library.
+package embedding
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrDimensionMismatch is returned when two vectors have different lengths.
+var ErrDimensionMismatch = errors.New("embedding: vectors have different dimensions")
+
+// Match is a corpus vector found by TopK.
+type Match struct {
+	// Index is the position of the vector in the corpus.
+	Index int
+
+	// Score is the cosine similarity of the vector to the query.
+	Score float64
+}
+
+// DotProduct returns the dot product of a and b. It returns an error
+// wrapping ErrDimensionMismatch if their lengths differ.
+func DotProduct(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("%w: %d and %d", ErrDimensionMismatch, len(a), len(b))
+	}
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum, nil
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, from -1
+// for opposite vectors to 1 for vectors pointing the same way. The
+// similarity of a zero vector, including an empty one, to any vector is 0.
+// It returns an error wrapping ErrDimensionMismatch if their lengths differ.
+func CosineSimilarity(a, b []float64) (float64, error) {
+	dot, err := DotProduct(a, b)
+	if err != nil {
+		return 0, err
+	}
+	normA, normB := norm(a), norm(b)
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+	// Clamp rounding error so identical vectors score exactly 1
+	return math.Max(-1, math.Min(1, dot/(normA*normB))), nil
+}
+
+// TopK returns the k corpus vectors most similar to query by cosine
+// similarity, best first. Vectors with equal scores are ordered by index.
+// It returns all of the corpus, ranked, if k exceeds its size, and nothing
+// if k is zero or less. It returns an error wrapping ErrDimensionMismatch,
+// naming the corpus index, if a vector's length differs from the query's.
+func TopK(query []float64, corpus [][]float64, k int) ([]Match, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	matches := make([]Match, len(corpus))
+	for i, vector := range corpus {
+		if len(vector) != len(query) {
+			return nil, fmt.Errorf("corpus vector %d: %w: %d and %d",
+				i, ErrDimensionMismatch, len(query), len(vector))
+		}
+		score, _ := CosineSimilarity(query, vector)
+		matches[i] = Match{Index: i, Score: score}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if k < len(matches) {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+// norm returns the Euclidean length of v.
+func norm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,98 @@
+package embedding
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestDotProduct(t *testing.T) {
+	dot, err := DotProduct([]float64{1, 2, 3}, []float64{4, -5, 6})
+	assert.NoError(t, err)
+	assert.Equal(t, 12.0, dot)
+
+	dot, err = DotProduct(nil, []float64{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, dot)
+
+	_, err = DotProduct([]float64{1, 2}, []float64{1, 2, 3})
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+	assert.Equal(t, "embedding: vectors have different dimensions: 2 and 3", err.Error())
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{"identical", []float64{0.1, 0.2, 0.3}, []float64{0.1, 0.2, 0.3}, 1},
+		{"scaled", []float64{1, 2}, []float64{3, 6}, 1},
+		{"opposite", []float64{1, -2}, []float64{-1, 2}, -1},
+		{"orthogonal", []float64{1, 0}, []float64{0, 5}, 0},
+		{"diagonal", []float64{1, 0}, []float64{1, 1}, 1 / math.Sqrt2},
+		{"zero vector", []float64{0, 0}, []float64{1, 2}, 0},
+		{"both zero", []float64{0, 0}, []float64{0, 0}, 0},
+		{"empty", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CosineSimilarity(tt.a, tt.b)
+			assert.NoError(t, err)
+			assert.True(t, math.Abs(got-tt.want) < 1e-12, "got %v, want %v", got, tt.want)
+		})
+	}
+
+	// Rounding never pushes a score past 1
+	v := []float64{0.1, 0.7, 0.3, 0.9, 0.123456789}
+	got, err := CosineSimilarity(v, v)
+	assert.NoError(t, err)
+	assert.True(t, got <= 1)
+
+	_, err = CosineSimilarity([]float64{1}, nil)
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+}
+
+func TestTopK(t *testing.T) {
+	corpus := [][]float64{
+		{0, 1},  // orthogonal
+		{1, 0},  // same direction
+		{-1, 0}, // opposite
+		{1, 1},  // 45 degrees
+		{0, 0},  // zero vector
+		{2, 0},  // same direction, ties with index 1
+	}
+	query := []float64{3, 0}
+
+	matches, err := TopK(query, corpus, 3)
+	assert.NoError(t, err)
+	assert.Len(t, matches, 3)
+	assert.Equal(t, Match{Index: 1, Score: 1}, matches[0])
+	assert.Equal(t, Match{Index: 5, Score: 1}, matches[1])
+	assert.Equal(t, 3, matches[2].Index)
+	assert.True(t, math.Abs(matches[2].Score-1/math.Sqrt2) < 1e-12)
+
+	all, err := TopK(query, corpus, 100)
+	assert.NoError(t, err)
+	indices := make([]int, len(all))
+	for i, m := range all {
+		indices[i] = m.Index
+	}
+	assert.Equal(t, []int{1, 5, 3, 0, 4, 2}, indices)
+
+	none, err := TopK(query, corpus, 0)
+	assert.NoError(t, err)
+	assert.Len(t, none, 0)
+
+	empty, err := TopK(query, nil, 3)
+	assert.NoError(t, err)
+	assert.Len(t, empty, 0)
+}
+
+func TestTopKDimensionMismatch(t *testing.T) {
+	_, err := TopK([]float64{1, 0}, [][]float64{{1, 0}, {1, 0, 0}}, 1)
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+	assert.Equal(t, "corpus vector 1: embedding: vectors have different dimensions: 2 and 3", err.Error())
+}
@@ -53,18 +53,9 @@ func main() {
 	fmt.Println("Answer:")
 	fmt.Println(response.Message().Text())
 
-	// Citations are attached to the assistant's text content blocks.
 	fmt.Println("\nCitations:")
-	for _, content := range response.Content {
-		text, ok := content.(*llm.TextContent)
-		if !ok {
-			continue
-		}
-		for _, citation := range text.Citations {
-			if loc, ok := citation.(*llm.WebSearchResultLocation); ok {
-				fmt.Printf("  - %s (%s)\n", loc.Title, loc.URL)
-			}
-		}
+	for _, citation := range response.Citations() {
+		fmt.Printf("  - %s (%s)\n", citation.Title, citation.URL)
 	}
 
 	fmt.Printf("\nUsage: input=%d output=%d reasoning=%d\n",
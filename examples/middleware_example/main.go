@@ -0,0 +1,117 @@
+// middleware_example demonstrates agent response middleware.
+//
+// A ResponseMiddleware wraps every CreateResponse call, including its LLM
+// requests, retries, and tool calls, so cross-cutting concerns live in one
+// place instead of in each agent's hooks. This example installs two:
+//
+//   - tagRequests adds a request ID with WithMetadata, so it appears on the
+//     response and on every event the call emits.
+//   - recordMetrics records each call's latency and total tokens to a
+//     metrics sink, including the tokens spent by calls that fail partway.
+//
+// Requires ANTHROPIC_API_KEY.
+// Run: cd examples && go run ./middleware_example
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers/anthropic"
+)
+
+// MetricsSink receives measurements. In production this would forward to
+// Prometheus, StatsD, or OpenTelemetry.
+type MetricsSink interface {
+	Observe(name string, value float64, labels map[string]string)
+}
+
+// printSink is a MetricsSink that prints each measurement.
+type printSink struct {
+	mu sync.Mutex
+}
+
+func (s *printSink) Observe(name string, value float64, labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Printf("  metric %s=%g %v\n", name, value, labels)
+}
+
+// recordMetrics returns middleware that records the latency and total
+// tokens of every response for the named agent.
+func recordMetrics(sink MetricsSink, agentName string) dive.ResponseMiddleware {
+	return func(next dive.ResponseFunc) dive.ResponseFunc {
+		return func(ctx context.Context, opts ...dive.CreateResponseOption) (*dive.Response, error) {
+			start := time.Now()
+			resp, err := next(ctx, opts...)
+
+			labels := map[string]string{"agent": agentName, "status": "ok"}
+			var usage *llm.Usage
+			if err != nil {
+				labels["status"] = "error"
+				// Failed calls still report what they spent
+				var genErr *dive.GenerationError
+				if errors.As(err, &genErr) {
+					usage = genErr.Usage
+				}
+			} else {
+				usage = resp.Usage
+			}
+
+			sink.Observe("agent_response_seconds", time.Since(start).Seconds(), labels)
+			if usage != nil {
+				sink.Observe("agent_response_tokens", float64(usage.InputTokens+usage.OutputTokens), labels)
+			}
+			return resp, err
+		}
+	}
+}
+
+// tagRequests returns middleware that gives every response a request ID.
+func tagRequests() dive.ResponseMiddleware {
+	var counter atomic.Int64
+	return func(next dive.ResponseFunc) dive.ResponseFunc {
+		return func(ctx context.Context, opts ...dive.CreateResponseOption) (*dive.Response, error) {
+			id := fmt.Sprintf("req-%d", counter.Add(1))
+			opts = append(opts, dive.WithMetadata(map[string]any{"request_id": id}))
+			return next(ctx, opts...)
+		}
+	}
+}
+
+func main() {
+	ctx := context.Background()
+
+	agent, err := dive.NewAgent(dive.AgentOptions{
+		Name:         "assistant",
+		SystemPrompt: "You are a concise assistant.",
+		Model:        anthropic.New(),
+		// The first middleware is the outermost, so latency includes tagging
+		Middleware: []dive.ResponseMiddleware{
+			recordMetrics(&printSink{}, "assistant"),
+			tagRequests(),
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, question := range []string{
+		"What is the capital of France?",
+		"Name three primary colors.",
+	} {
+		fmt.Println("Q:", question)
+		resp, err := agent.CreateResponse(ctx, dive.WithInput(question))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("A: %s (request %v)\n\n", resp.OutputText(), resp.Metadata["request_id"])
+	}
+}
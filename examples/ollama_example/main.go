@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 
 	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/dive/providers/ollama"
@@ -12,8 +15,9 @@ import (
 )
 
 func main() {
-	var modelName string
+	var modelName, imagePath string
 	flag.StringVar(&modelName, "model", ollama.ModelLlama32_3B, "The model to use")
+	flag.StringVar(&imagePath, "image", "", "An image to describe, with a vision model such as llava:7b")
 	flag.Parse()
 
 	// Create an Ollama provider. Assumes Ollama is running locally on the
@@ -31,6 +35,9 @@ func main() {
 	simpleTextGeneration(ctx, provider)
 	streamingResponse(ctx, provider)
 	toolUsageExample(ctx, provider)
+	if imagePath != "" {
+		imageExample(ctx, provider, imagePath)
+	}
 }
 
 // simpleTextGeneration demonstrates basic text generation
@@ -121,3 +128,28 @@ func toolUsageExample(ctx context.Context, provider llm.StreamingLLM) {
 		}
 	}
 }
+
+// imageExample demonstrates sending an image alongside text to a vision model
+func imageExample(ctx context.Context, provider llm.StreamingLLM, path string) {
+	fmt.Println("=== Image Example ===")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Error reading image: %v", err)
+	}
+	response, err := provider.Generate(ctx,
+		llm.WithMessages(llm.NewUserMessage(
+			llm.NewTextContent("Describe this image in one sentence."),
+			llm.NewImageContent(llm.EncodedData(
+				http.DetectContentType(data),
+				base64.StdEncoding.EncodeToString(data),
+			)),
+		)),
+		llm.WithMaxTokens(300),
+	)
+	if err != nil {
+		log.Fatalf("Error describing image: %v", err)
+	}
+
+	fmt.Printf("Image description: %s\n", response.Message().Text())
+}
@@ -0,0 +1,54 @@
+// prometheus_example records Prometheus metrics for an agent's model calls.
+//
+// metrics.WithMetrics wraps the model so every request, including each call
+// the agent makes while using tools, is counted and timed, and its tokens and
+// errors are recorded by provider and model. The metrics are registered with
+// Prometheus' default registry and served on /metrics, where a Prometheus
+// server can scrape them. Errors are labeled by type, so you can alert on
+// rate limits separately from authentication failures, for example with
+// increase(dive_llm_errors_total{error_type="rate_limit"}[5m]) > 0.
+//
+// Requires ANTHROPIC_API_KEY.
+// Run: cd examples && go run ./prometheus_example
+// Then: curl -s localhost:2112/metrics | grep dive_llm
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/providers/anthropic"
+	"github.com/deepnoodle-ai/dive/providers/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	ctx := context.Background()
+
+	model, err := metrics.WithMetrics(anthropic.New(), prometheus.DefaultRegisterer)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	agent, err := dive.NewAgent(dive.AgentOptions{
+		SystemPrompt: "You are a concise assistant.",
+		Model:        model,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resp, err := agent.CreateResponse(ctx, dive.WithInput("Give me one fun fact about octopuses."))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(resp.OutputText())
+
+	fmt.Println("\nServing metrics on http://localhost:2112/metrics (Ctrl-C to stop)")
+	http.Handle("/metrics", promhttp.Handler())
+	log.Fatal(http.ListenAndServe("localhost:2112", nil))
+}
@@ -116,6 +116,13 @@ type toolProgressEvent struct {
 	display    string
 }
 
+// agentStatusEvent carries a user-facing status line reported by the agent
+// (via the Status tool), shown in place of the generic progress label.
+type agentStatusEvent struct {
+	baseEvent
+	text string
+}
+
 // nativeBgTasksReadyEvent is sent when one or more native dive.BackgroundTaskHandle
 // goroutines have all completed, signaling the agent should re-enter with results.
 type nativeBgTasksReadyEvent struct {
@@ -285,6 +292,7 @@ type App struct {
 	frame               uint64
 	processing          bool
 	processingStartTime time.Time
+	agentStatus         string // latest status reported by the agent this turn
 
 	// Todo list state
 	todos     []Todo
@@ -819,6 +827,8 @@ func (a *App) HandleEvent(event tui.Event) []tui.Cmd {
 		a.handleToolStream(e)
 	case toolProgressEvent:
 		a.handleToolProgress(e)
+	case agentStatusEvent:
+		a.agentStatus = e.text
 	case nativeBgTasksReadyEvent:
 		a.handleNativeBgTasksReady(e)
 	case monitorNotificationEvent:
@@ -1337,6 +1347,10 @@ func (a *App) agentEventCallback(lastUsage **llm.Usage) dive.EventCallback {
 					display:    item.ToolProgress.Progress.Display,
 				})
 			}
+		case dive.ResponseItemTypeStatus:
+			if item.Status != nil {
+				a.runner.SendEvent(agentStatusEvent{baseEvent: newBaseEvent(), text: item.Status.Text})
+			}
 		}
 		return nil
 	}
@@ -1577,6 +1591,7 @@ func (a *App) handleProcessingStart(e processingStartEvent) {
 
 	a.processing = true
 	a.processingStartTime = time.Now()
+	a.agentStatus = ""
 	a.interactionUsage = &llm.Usage{}
 	if a.sessionUsage == nil {
 		a.sessionUsage = &llm.Usage{}
@@ -1773,6 +1788,7 @@ func (a *App) handleProcessingEnd(err error) {
 	// This ensures the live view rendered inside Print() matches the final state
 	// (without thinking animation), preventing orphaned blank lines.
 	a.processing = false
+	a.agentStatus = ""
 	a.currentMessage = nil
 	a.streamingMessageIndex = -1
 	a.thinkingMessageIndex = -1
@@ -2649,9 +2665,13 @@ func (a *App) buildLiveView() tui.View {
 
 	// Show generation progress indicator (below tool calls)
 	if a.streamingMessageIndex >= 0 {
+		label := "thinking"
+		if a.agentStatus != "" {
+			label = truncateText(a.agentStatus, 60)
+		}
 		views = append(views, tui.Group(
 			tui.Loading(a.frame).CharSet(tui.SpinnerBounce.Frames).Speed(6).Fg(tui.ColorCyan),
-			tui.Text(" thinking").Animate(tui.Slide(3, tui.NewRGB(80, 80, 80), tui.NewRGB(80, 200, 220))),
+			tui.Text(" %s", label).Animate(tui.Slide(3, tui.NewRGB(80, 80, 80), tui.NewRGB(80, 200, 220))),
 			tui.Text(" (%s)", formatDuration(elapsed)).Hint(),
 			tui.Text("  ").Hint(),
 			tui.Text("esc to interrupt").Hint(),
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/deepnoodle-ai/dive/session"
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+func runExport(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("usage: dive export <session-id> [flags]")
+	}
+	sessionID := args[0]
+
+	var export func(*session.Session, io.Writer, ...session.ExportOption) error
+	switch format := ctx.String("format"); format {
+	case "markdown", "md":
+		export = session.ExportMarkdown
+	case "html":
+		export = session.ExportHTML
+	default:
+		return fmt.Errorf("unknown format %q (use markdown or html)", format)
+	}
+
+	store, err := session.NewFileStore("~/.dive/sessions")
+	if err != nil {
+		return fmt.Errorf("failed to open session store: %w", err)
+	}
+	bgCtx := context.Background()
+	// Open creates missing sessions, so check that it exists first
+	list, err := store.List(bgCtx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	found := false
+	for _, info := range list.Sessions {
+		if info.ID == sessionID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("session %q not found", sessionID)
+	}
+	sess, err := store.Open(bgCtx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+
+	var opts []session.ExportOption
+	if ctx.Bool("thinking") {
+		opts = append(opts, session.WithThinking())
+	}
+	out := ctx.String("out")
+	if out == "" {
+		return export(sess, os.Stdout, opts...)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := export(sess, f, opts...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -53,7 +53,7 @@ func securityAwarenessSuccessHook() dive.PostToolUseHook {
 		}
 		input := toolInput(hctx.Call)
 		switch hctx.Call.Name {
-		case "Write", "Edit":
+		case "Write", "Edit", "MultiEdit":
 			if category, ok := classifySecuritySensitivePath(firstString(input, "file_path", "path")); ok {
 				state.observeSecurityRisk(category, securityRiskFileChange)
 			}
@@ -16,7 +16,7 @@ func verificationCollectorHook() dive.PostToolUseHook {
 		}
 		input := toolInput(hctx.Call)
 		switch hctx.Call.Name {
-		case "Write", "Edit":
+		case "Write", "Edit", "MultiEdit":
 			path := firstString(input, "file_path", "path")
 			if path == "" {
 				path = "unknown path"
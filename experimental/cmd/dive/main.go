@@ -7,7 +7,6 @@ import (
 	_ "embed"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -101,6 +100,23 @@ func main() {
 		).
 		Run(runModels)
 
+	// Session export subcommand
+	app.Command("export").
+		Description("Export a session as Markdown or HTML").
+		Args("session").
+		Flags(
+			cli.String("format", "f").
+				Default("markdown").
+				Help("Output format: markdown, html"),
+			cli.String("out", "o").
+				Default("").
+				Help("Output file path (default: stdout)"),
+			cli.Bool("thinking").
+				Default(false).
+				Help("Include the model's thinking"),
+		).
+		Run(runExport)
+
 	app.Command("context-demos").
 		Description("List runtime context demo presets").
 		Run(func(_ *cli.Context) error { return writeContextDemoCatalog(os.Stdout) })
@@ -110,7 +126,7 @@ func main() {
 		Flags(
 			cli.String("model", "m").
 				Env("DIVE_MODEL").
-				Help("Model to use (auto-detected from available API keys if not specified)"),
+				Help("Model or alias (fast, smart, cheap) to use (auto-detected from available API keys if not specified)"),
 			cli.String("workspace", "w").
 				Default("").
 				Help("Workspace directory (defaults to current directory)"),
@@ -192,10 +208,7 @@ func runInteractive(ctx *cli.Context) error {
 	}
 
 	// Parse model
-	modelName := ctx.String("model")
-	if modelName == "" {
-		modelName = getDefaultModel()
-	}
+	modelName := resolveModelName(ctx.String("model"))
 
 	// Create model
 	model := createModel(modelName, ctx.String("api-endpoint"))
@@ -502,10 +515,7 @@ func runPrint(ctx *cli.Context) error {
 	}
 
 	// Get model
-	modelName := ctx.String("model")
-	if modelName == "" {
-		modelName = getDefaultModel()
-	}
+	modelName := resolveModelName(ctx.String("model"))
 
 	// Build system prompt
 	systemPrompt := ctx.String("system-prompt")
@@ -612,19 +622,18 @@ func parseThinkingEffort(value string) llm.ReasoningEffort {
 	}
 }
 
+// loadStartupInstructionAttachment renders the project instructions that
+// apply to cwd as file attachments for the first message.
 func loadStartupInstructionAttachment(cwd string) (string, error) {
-	candidates := []string{"AGENTS.md", "CLAUDE.md"}
-	for _, name := range candidates {
-		path := filepath.Join(cwd, name)
-		content, err := os.ReadFile(path)
-		if err == nil {
-			return fmt.Sprintf("\n<file path=\"%s\">\n%s\n</file>\n", name, string(content)), nil
-		}
-		if !errors.Is(err, os.ErrNotExist) {
-			return "", err
-		}
+	instructions, err := dive.LoadProjectInstructions(cwd)
+	if err != nil {
+		return "", err
 	}
-	return "", nil
+	var sb strings.Builder
+	for _, source := range instructions.Sources {
+		fmt.Fprintf(&sb, "\n<file path=\"%s\">\n%s\n</file>\n", source.RelPath, source.Content)
+	}
+	return sb.String(), nil
 }
 
 func appendAttachedContent(input, attachment string) string {
@@ -1025,6 +1034,26 @@ func buildToolPreview(call *llm.ToolUseContent) toolPreview {
 		}
 		return tp
 
+	case "MultiEdit":
+		edits, _ := parsed["edits"].([]any)
+		tp := toolPreview{
+			title:    "Edit file",
+			subtitle: filePath,
+			question: fmt.Sprintf("Do you want to make these %d edits to %s?", len(edits), fileName),
+		}
+		var previews []string
+		for _, e := range edits {
+			edit, _ := e.(map[string]any)
+			oldStr, _ := edit["old_string"].(string)
+			newStr, _ := edit["new_string"].(string)
+			replaceAll, _ := edit["replace_all"].(bool)
+			if oldStr != "" || newStr != "" {
+				previews = append(previews, buildEditDiffPreview(filePath, oldStr, newStr, replaceAll))
+			}
+		}
+		tp.preview = strings.Join(previews, "\n\n")
+		return tp
+
 	case "Write":
 		tp := toolPreview{
 			title:    "Write file",
@@ -1104,6 +1133,10 @@ func createTools(validator *toolkit.PathValidator, dialog dive.Dialog) []dive.To
 	if dialog == nil {
 		dialog = &dive.AutoApproveDialog{}
 	}
+	var workspaceDir string
+	if validator != nil {
+		workspaceDir = validator.WorkspaceDir
+	}
 	tools := []dive.Tool{
 		// Read-only file tools
 		toolkit.NewReadFileTool(toolkit.ReadFileToolOptions{
@@ -1118,6 +1151,9 @@ func createTools(validator *toolkit.PathValidator, dialog dive.Dialog) []dive.To
 		toolkit.NewListDirectoryTool(toolkit.ListDirectoryToolOptions{
 			Validator: validator,
 		}),
+		toolkit.NewWorkspaceInfoTool(toolkit.WorkspaceInfoToolOptions{
+			WorkspaceDir: workspaceDir,
+		}),
 
 		// Write tools
 		toolkit.NewWriteFileTool(toolkit.WriteFileToolOptions{
@@ -1126,6 +1162,12 @@ func createTools(validator *toolkit.PathValidator, dialog dive.Dialog) []dive.To
 		toolkit.NewEditTool(toolkit.EditToolOptions{
 			Validator: validator,
 		}),
+		toolkit.NewMultiEditTool(toolkit.MultiEditToolOptions{
+			Validator: validator,
+		}),
+		toolkit.NewApplyPatchTool(toolkit.ApplyPatchToolOptions{
+			Validator: validator,
+		}),
 		toolkit.NewBashTool(toolkit.BashToolOptions{
 			Validator: validator,
 		}),
@@ -1134,18 +1176,17 @@ func createTools(validator *toolkit.PathValidator, dialog dive.Dialog) []dive.To
 		toolkit.NewAskUserTool(toolkit.AskUserToolOptions{
 			Dialog: dialog,
 		}),
+		toolkit.NewStatusTool(),
 	}
 
-	// Add web fetch if available
+	// Add web fetch, using firecrawl if available
+	var fetcher fetch.Fetcher = toolkit.NewContentFetcher()
 	if firecrawlClient, err := firecrawl.New(); err == nil {
-		tools = append(tools, toolkit.NewFetchTool(toolkit.FetchToolOptions{
-			Fetcher: firecrawlClient,
-		}))
-	} else {
-		tools = append(tools, toolkit.NewFetchTool(toolkit.FetchToolOptions{
-			Fetcher: fetch.NewHTTPFetcher(fetch.HTTPFetcherOptions{}),
-		}))
+		fetcher = firecrawlClient
 	}
+	tools = append(tools, toolkit.NewFetchTool(toolkit.FetchToolOptions{
+		Fetcher: toolkit.NewCachingFetcher(fetcher),
+	}))
 
 	// Add web search if available
 	if kagiClient, err := kagi.New(); err == nil {
@@ -1226,22 +1267,3 @@ func getDefaultVideoModel() string {
 	}
 	return ""
 }
-
-func getDefaultModel() string {
-	if os.Getenv("ANTHROPIC_API_KEY") != "" {
-		return "claude-haiku-4-5"
-	}
-	if os.Getenv("GOOGLE_API_KEY") != "" || os.Getenv("GEMINI_API_KEY") != "" {
-		return "gemini-3.6-flash"
-	}
-	if os.Getenv("OPENAI_API_KEY") != "" {
-		return "gpt-5.6-sol"
-	}
-	if os.Getenv("XAI_API_KEY") != "" || os.Getenv("GROK_API_KEY") != "" {
-		return defaultGrokModel
-	}
-	if os.Getenv("MISTRAL_API_KEY") != "" {
-		return "mistral-small-latest"
-	}
-	return "claude-haiku-4-5"
-}
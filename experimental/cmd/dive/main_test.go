@@ -220,3 +220,15 @@ func TestLoadStartupInstructionAttachment_FallsBackToClaude(t *testing.T) {
 	assert.Contains(t, attachment, `<file path="CLAUDE.md">`)
 	assert.Contains(t, attachment, "claude")
 }
+
+func TestResolveModelName(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "test")
+	t.Setenv("DIVE_MODEL_SMART", "")
+
+	assert.Equal(t, "claude-haiku-4-5", resolveModelName(""))
+	assert.Equal(t, "claude-opus-4-8", resolveModelName("smart"))
+	assert.Equal(t, "gpt-5.6", resolveModelName("gpt-5.6"))
+
+	t.Setenv("DIVE_MODEL_SMART", "grok-4.5")
+	assert.Equal(t, "grok-4.5", resolveModelName("smart"))
+}
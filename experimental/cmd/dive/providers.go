@@ -10,6 +10,9 @@ import (
 
 	// Import providers to trigger their init() registration
 	_ "github.com/deepnoodle-ai/dive/providers/anthropic"
+	_ "github.com/deepnoodle-ai/dive/providers/cohere"
+	_ "github.com/deepnoodle-ai/dive/providers/deepseek"
+	_ "github.com/deepnoodle-ai/dive/providers/fireworks"
 	_ "github.com/deepnoodle-ai/dive/providers/google"
 	_ "github.com/deepnoodle-ai/dive/providers/grok"
 	_ "github.com/deepnoodle-ai/dive/providers/mistral"
@@ -17,10 +20,28 @@ import (
 	_ "github.com/deepnoodle-ai/dive/providers/openai"
 	_ "github.com/deepnoodle-ai/dive/providers/openaicompletions"
 	_ "github.com/deepnoodle-ai/dive/providers/openrouter"
+	_ "github.com/deepnoodle-ai/dive/providers/perplexity"
+	_ "github.com/deepnoodle-ai/dive/providers/replicate"
 )
 
-// defaultGrokModel is the default model used when a Grok API key is detected.
-var defaultGrokModel = grok.ModelGrok45
+// getDefaultModel returns the recommended model for the first provider with
+// an API key set. See providers.ResolveDefaultModel.
+func getDefaultModel() string {
+	return providers.ResolveDefaultModel()
+}
+
+// resolveModelName returns the model to use for the --model flag value,
+// expanding aliases such as "fast" and "smart" so provider-specific checks
+// see the concrete model name. An empty value selects the default model.
+func resolveModelName(name string) string {
+	if name == "" {
+		return getDefaultModel()
+	}
+	if model, ok := providers.ResolveAlias(name); ok {
+		return model
+	}
+	return name
+}
 
 // createModel creates an LLM provider using the global registry.
 // Providers are registered via init() when imported above.
@@ -534,7 +534,7 @@ func formatToolCall(title, apiName, inputJSON string) string {
 
 	// Special handling for file tools - show as title(filepath)
 	switch apiName {
-	case "Read", "Write", "Edit":
+	case "Read", "Write", "Edit", "MultiEdit":
 		if filePath, ok := params["file_path"].(string); ok {
 			return fmt.Sprintf("%s(%s)", title, filePath)
 		}
@@ -8,8 +8,11 @@ import (
 )
 
 // HookWithModel returns a PreGenerationHook that triggers context
-// compaction using the provided LLM model when the estimated token count
-// exceeds the given threshold.
+// compaction using the provided LLM model when the token count of the
+// messages exceeds the given threshold. Tokens are counted with the agent's
+// model through llm.CountTokens, so providers with a token counting
+// endpoint, such as Anthropic, report exact counts; other models are
+// estimated locally.
 //
 // This hook uses the CompactMessages function to generate summaries. The
 // compaction event is stored in state.Values[dive.StateKeyCompactionEvent] for access
@@ -36,12 +39,7 @@ func HookWithModel(model llm.LLM, tokenThreshold int, systemPrompt string) dive.
 		tokenThreshold = DefaultContextTokenThreshold
 	}
 	return func(ctx context.Context, state *dive.HookContext) error {
-		// Rough token estimation: ~4 chars per token
-		estimatedTokens := 0
-		for _, msg := range state.Messages {
-			estimatedTokens += len(msg.Text()) / 4
-		}
-
+		estimatedTokens := countTokens(ctx, state)
 		if estimatedTokens < tokenThreshold {
 			return nil
 		}
@@ -56,3 +54,19 @@ func HookWithModel(model llm.LLM, tokenThreshold int, systemPrompt string) dive.
 		return nil
 	}
 }
+
+// countTokens counts the tokens of the messages with the agent's model,
+// falling back to estimateTokens without an agent or if counting fails.
+func countTokens(ctx context.Context, state *dive.HookContext) int {
+	if state.Agent != nil {
+		tokens, err := llm.CountTokens(ctx, state.Agent.Model(), "", state.Messages, nil)
+		if err == nil {
+			return tokens
+		}
+	}
+	total := 0
+	for _, msg := range state.Messages {
+		total += estimateTokens(msg)
+	}
+	return total
+}
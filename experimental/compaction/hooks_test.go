@@ -0,0 +1,46 @@
+package compaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// countingLLM reports a fixed token count, like a provider's token
+// counting endpoint.
+type countingLLM struct {
+	stubLLM
+	tokens int
+}
+
+func (c *countingLLM) CountTokens(ctx context.Context, model string, messages []*llm.Message, tools []llm.Tool) (int, error) {
+	return c.tokens, nil
+}
+
+func TestHookWithModelCountsWithAgentModel(t *testing.T) {
+	ctx := context.Background()
+	messages := []*llm.Message{llm.NewUserTextMessage("hi"), llm.NewAssistantTextMessage("hello")}
+
+	for _, tt := range []struct {
+		tokens    int
+		compacted bool
+	}{
+		{tokens: 5000, compacted: true},
+		{tokens: 10, compacted: false},
+	} {
+		agent, err := dive.NewAgent(dive.AgentOptions{Model: &countingLLM{tokens: tt.tokens}})
+		assert.NoError(t, err)
+		summarizer := &stubLLM{}
+		hctx := dive.NewHookContext()
+		hctx.Agent = agent
+		hctx.Messages = messages
+
+		assert.NoError(t, HookWithModel(summarizer, 1000, "")(ctx, hctx))
+		_, compacted := hctx.Values[dive.StateKeyCompactionEvent]
+		assert.Equal(t, tt.compacted, compacted)
+		assert.Equal(t, tt.compacted, summarizer.sawMessages > 0)
+	}
+}
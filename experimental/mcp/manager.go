@@ -17,8 +17,16 @@ type MCPServerConnection struct {
 	Tools  []dive.Tool
 }
 
-// Manager manages multiple MCP server connections and tool discovery
+var _ dive.Toolset = &Manager{}
+
+// Manager manages multiple MCP server connections and tool discovery.
+//
+// Manager implements dive.Toolset, so it can be set on
+// AgentOptions.Toolsets or added to a dive.ToolRegistry. Tools are resolved
+// per LLM request, so servers that connect or disconnect are reflected on
+// the agent's next request.
 type Manager struct {
+	name    string
 	servers map[string]*MCPServerConnection
 	tools   map[string]dive.Tool
 	logger  llm.Logger
@@ -28,16 +36,23 @@ type Manager struct {
 // ManagerOptions configures a new MCP manager
 type ManagerOptions struct {
 	Logger llm.Logger
+
+	// Name identifies the manager as a dive.Toolset. Defaults to "mcp".
+	Name string
 }
 
 // NewManager creates a new MCP manager
 func NewManager(opts ...ManagerOptions) *Manager {
 	m := &Manager{
+		name:    "mcp",
 		servers: make(map[string]*MCPServerConnection),
 		tools:   make(map[string]dive.Tool),
 	}
 	if len(opts) > 0 {
 		m.logger = opts[0].Logger
+		if opts[0].Name != "" {
+			m.name = opts[0].Name
+		}
 	}
 	return m
 }
@@ -143,6 +158,34 @@ func (m *Manager) GetAllTools() map[string]dive.Tool {
 	return result
 }
 
+// Name returns the manager's toolset name.
+func (m *Manager) Name() string {
+	return m.name
+}
+
+// Tools returns the tools of all connected servers, ordered by server name.
+// It implements dive.Toolset.
+func (m *Manager) Tools(ctx context.Context) ([]dive.Tool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	names := make([]string, 0, len(m.servers))
+	for name := range m.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tools []dive.Tool
+	for _, name := range names {
+		server := m.servers[name]
+		if server.Client == nil || !server.Client.IsConnected() {
+			continue
+		}
+		tools = append(tools, server.Tools...)
+	}
+	return tools, nil
+}
+
 // GetToolsByServer returns tools from a specific MCP server
 func (m *Manager) GetToolsByServer(serverName string) []dive.Tool {
 	m.mutex.RLock()
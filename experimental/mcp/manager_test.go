@@ -409,3 +409,34 @@ func TestMCPManager_RefreshTools_DuplicateToolName(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "server-two", adapter.serverName)
 }
+
+func TestMCPManager_Toolset(t *testing.T) {
+	manager := NewManager()
+	assert.Equal(t, "mcp", manager.Name())
+	assert.Equal(t, "remote", NewManager(ManagerOptions{Name: "remote"}).Name())
+
+	for _, name := range []string{"b-server", "a-server"} {
+		config := &ServerConfig{Type: "http", Name: name, URL: "http://localhost:8080"}
+		client, err := NewClient(config)
+		assert.NoError(t, err)
+		client.connected = true
+		manager.servers[name] = &MCPServerConnection{
+			Client: client,
+			Config: config,
+			Tools:  []dive.Tool{NewToolAdapter(client, mcp.Tool{Name: name + "-tool"}, name)},
+		}
+	}
+
+	tools, err := manager.Tools(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, tools, 2)
+	assert.Equal(t, "a-server-tool", tools[0].Name())
+	assert.Equal(t, "b-server-tool", tools[1].Name())
+
+	// Disconnected servers drop out on the next resolution.
+	manager.servers["a-server"].Client.connected = false
+	tools, err = manager.Tools(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, tools, 1)
+	assert.Equal(t, "b-server-tool", tools[0].Name())
+}
@@ -193,6 +193,9 @@ func parseParameterizedPattern(toolName, args string, ruleType permission.RuleTy
 	case toolNameLower == "edit":
 		return parsePathPattern("Edit", args, ruleType)
 
+	case toolNameLower == "multiedit" || toolNameLower == "multi_edit":
+		return parsePathPattern("MultiEdit", args, ruleType)
+
 	case toolNameLower == "webfetch" || toolNameLower == "web_fetch":
 		return parseWebFetchPattern(args, ruleType)
 
@@ -103,6 +103,12 @@ func (t *GetShellOutputTool) PreviewCall(ctx context.Context, input *GetShellOut
 	}
 }
 
+// CachePolicy opts out of dive.ToolCache: shell output changes while the
+// shell runs.
+func (t *GetShellOutputTool) CachePolicy(input *GetShellOutputInput) dive.ToolCachePolicy {
+	return dive.ToolCachePolicy{Disabled: true}
+}
+
 func (t *GetShellOutputTool) Call(ctx context.Context, input *GetShellOutputInput) (*dive.ToolResult, error) {
 	if t.shellManager == nil {
 		return dive.NewToolResultError("shell manager not configured"), nil
@@ -236,6 +242,12 @@ func (t *ListShellsTool) PreviewCall(ctx context.Context, input *ListShellsInput
 	return &dive.ToolCallPreview{Summary: "List all shells"}
 }
 
+// CachePolicy opts out of dive.ToolCache: shells start and exit between
+// calls.
+func (t *ListShellsTool) CachePolicy(input *ListShellsInput) dive.ToolCachePolicy {
+	return dive.ToolCachePolicy{Disabled: true}
+}
+
 func (t *ListShellsTool) Call(ctx context.Context, input *ListShellsInput) (*dive.ToolResult, error) {
 	if t.shellManager == nil {
 		return dive.NewToolResultError("shell manager not configured"), nil
@@ -2,6 +2,7 @@ package dive
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/deepnoodle-ai/wonton/assert"
@@ -125,3 +126,59 @@ func TestFuncToolWithStructInput(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, result.Content[0].Text, "hello")
 }
+
+func TestNewTypedTool(t *testing.T) {
+	type addInput struct {
+		A     int    `json:"a" description:"First addend"`
+		B     int    `json:"b" description:"Second addend"`
+		Label string `json:"label" description:"Label for the sum" required:"false"`
+	}
+	type addOutput struct {
+		Sum   int    `json:"sum"`
+		Label string `json:"label,omitempty"`
+	}
+
+	tool := NewTypedTool("add", "Add two numbers",
+		func(ctx context.Context, input addInput) (addOutput, error) {
+			if input.A < 0 || input.B < 0 {
+				return addOutput{}, errors.New("addends must not be negative")
+			}
+			return addOutput{Sum: input.A + input.B, Label: input.Label}, nil
+		},
+		WithFuncToolAnnotations(&ToolAnnotations{ReadOnlyHint: true}),
+	)
+
+	assert.Equal(t, "add", tool.Name())
+	assert.True(t, tool.Annotations().ReadOnlyHint)
+	s := tool.Schema()
+	assert.Equal(t, Object, s.Type)
+	assert.Equal(t, "First addend", s.Properties["a"].Description)
+	assert.Equal(t, []string{"a", "b"}, s.Required)
+
+	result, err := tool.Call(context.Background(), []byte(`{"a":2,"b":3,"label":"total"}`))
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, `{"sum":5,"label":"total"}`, result.Content[0].Text)
+
+	result, err = tool.Call(context.Background(), []byte(`{"a":-1,"b":3}`))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "addends must not be negative", result.Content[0].Text)
+
+	result, err = tool.Call(context.Background(), []byte(`{"a":"two"}`))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestNewTypedToolStringOutput(t *testing.T) {
+	tool := NewTypedTool("greet", "Greet someone",
+		func(ctx context.Context, input struct {
+			Name string `json:"name"`
+		}) (string, error) {
+			return "Hello, " + input.Name, nil
+		},
+	)
+	result, err := tool.Call(context.Background(), []byte(`{"name":"Ada"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, Ada", result.Content[0].Text)
+}
@@ -18,6 +18,7 @@ import (
 // Hook types:
 //   - PreGenerationHook: runs before the LLM generation loop
 //   - PostGenerationHook: runs after the generation loop completes
+//   - PreToolBatchHook: runs once before a turn's tool calls execute
 //   - PreToolUseHook: runs before each tool execution
 //   - PostToolUseHook: runs after a tool call succeeds
 //   - PostToolUseFailureHook: runs after a tool call fails
@@ -51,6 +52,7 @@ import (
 //
 //   - PreGeneration: Agent, Session, Values, SystemPrompt, Messages
 //   - PostGeneration: Agent, Session, Values, SystemPrompt, Messages, Response, OutputMessages, Usage
+//   - PreToolBatch: Agent, Session, Values, SystemPrompt, Messages, ToolBatch
 //   - PreToolUse: Agent, Session, Values, Tool, Call
 //   - PostToolUse: Agent, Session, Values, Tool, Call, Result
 //   - PostToolUseFailure: Agent, Session, Values, Tool, Call, Result
@@ -101,6 +103,12 @@ type HookContext struct {
 	// Usage contains token usage statistics for this generation.
 	Usage *llm.Usage
 
+	// Tool-batch-level (available in PreToolBatch)
+
+	// ToolBatch lists the tool calls the model requested in one turn, in
+	// order.
+	ToolBatch []ToolBatchCall
+
 	// Tool-level (available in PreToolUse, PostToolUse, PostToolUseFailure)
 
 	// Tool is the tool being executed.
@@ -137,6 +145,8 @@ type HookContext struct {
 	reminders          *reminderState
 	reminderDeliveries []reminderDelivery
 	toolScoped         bool
+	toolCalls          *toolCallCounter
+	toolCache          *ToolCache
 }
 
 // PreGenerationHook is called before the LLM generation loop begins.
@@ -165,6 +175,26 @@ type PreGenerationHook func(ctx context.Context, hctx *HookContext) error
 // to post-processing failures (e.g., if saving to a database fails).
 type PostGenerationHook func(ctx context.Context, hctx *HookContext) error
 
+// ToolBatchCall is one tool call in HookContext.ToolBatch.
+type ToolBatchCall struct {
+	// Tool is the tool being called, or nil if the model named an unknown
+	// tool.
+	Tool Tool
+
+	// Call contains the tool invocation details including input.
+	Call *llm.ToolUseContent
+}
+
+// PreToolBatchHook is called once per turn with all of the turn's tool calls
+// in hctx.ToolBatch, before PreToolUse hooks run for any of them. Use it to
+// make decisions about the calls together, such as presenting every pending
+// confirmation to the user at once (see permission.BatchHookFromManager).
+// The calls still go through PreToolUse hooks individually afterwards.
+//
+// Returning a HookAbortError aborts generation. Other errors are logged and
+// do not affect the tool calls.
+type PreToolBatchHook func(ctx context.Context, hctx *HookContext) error
+
 // PreToolUseHook is called before a tool is executed.
 //
 // All hooks run in order. If any hook returns an error, the tool is denied
@@ -322,6 +352,7 @@ func NewHookContext() *HookContext {
 	return &HookContext{
 		Values:    make(map[string]any),
 		reminders: &reminderState{},
+		toolCalls: &toolCallCounter{},
 	}
 }
 
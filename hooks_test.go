@@ -1057,6 +1057,117 @@ func TestPreToolUseHookIntegration(t *testing.T) {
 	})
 }
 
+func TestPreToolBatchHookIntegration(t *testing.T) {
+	multiCallLLM := func() *mockLLM {
+		calls := 0
+		return &mockLLM{
+			generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+				calls++
+				if calls > 1 {
+					return &llm.Response{
+						Role:       llm.Assistant,
+						Content:    []llm.Content{&llm.TextContent{Text: "done"}},
+						StopReason: llm.StopReasonEndTurn,
+					}, nil
+				}
+				return &llm.Response{
+					Role: llm.Assistant,
+					Content: []llm.Content{
+						&llm.ToolUseContent{ID: "call_1", Name: "test_tool", Input: []byte(`{}`)},
+						&llm.ToolUseContent{ID: "call_2", Name: "missing_tool", Input: []byte(`{}`)},
+						&llm.ToolUseContent{ID: "call_3", Name: "test_tool", Input: []byte(`{"n":3}`)},
+					},
+					StopReason: llm.StopReasonToolUse,
+				}, nil
+			},
+		}
+	}
+	tool := &mockTool{
+		name: "test_tool",
+		callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			return NewToolResultText("tool output"), nil
+		},
+	}
+
+	t.Run("hook sees the whole turn before PreToolUse", func(t *testing.T) {
+		var events []string
+		agent, err := NewAgent(AgentOptions{
+			Model: multiCallLLM(),
+			Tools: []Tool{tool},
+			Hooks: Hooks{
+				PreToolBatch: []PreToolBatchHook{
+					func(ctx context.Context, hctx *HookContext) error {
+						assert.Len(t, hctx.ToolBatch, 3)
+						assert.Equal(t, "test_tool", hctx.ToolBatch[0].Tool.Name())
+						assert.Nil(t, hctx.ToolBatch[1].Tool)
+						assert.Equal(t, "call_3", hctx.ToolBatch[2].Call.ID)
+						events = append(events, "batch")
+						return nil
+					},
+				},
+				PreToolUse: []PreToolUseHook{
+					func(ctx context.Context, hctx *HookContext) error {
+						events = append(events, "pre:"+hctx.Call.ID)
+						return nil
+					},
+				},
+			},
+		})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(), WithInput("Use the tools"))
+		assert.Error(t, err) // unknown tool
+		assert.Equal(t, []string{"batch", "pre:call_1"}, events)
+	})
+
+	t.Run("HookAbortError aborts generation", func(t *testing.T) {
+		toolCalled := false
+		agent, err := NewAgent(AgentOptions{
+			Model: newToolCallingMockLLM("test_tool"),
+			Tools: []Tool{&mockTool{
+				name: "test_tool",
+				callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+					toolCalled = true
+					return NewToolResultText("tool output"), nil
+				},
+			}},
+			Hooks: Hooks{
+				PreToolBatch: []PreToolBatchHook{
+					func(ctx context.Context, hctx *HookContext) error {
+						return AbortGeneration("too many calls")
+					},
+				},
+			},
+		})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(), WithInput("Use the tool"))
+		var abortErr *HookAbortError
+		assert.ErrorAs(t, err, &abortErr)
+		assert.Equal(t, "PreToolBatch", abortErr.HookType)
+		assert.False(t, toolCalled)
+	})
+
+	t.Run("other errors are ignored", func(t *testing.T) {
+		agent, err := NewAgent(AgentOptions{
+			Model: newToolCallingMockLLM("test_tool"),
+			Tools: []Tool{tool},
+			Hooks: Hooks{
+				PreToolBatch: []PreToolBatchHook{
+					func(ctx context.Context, hctx *HookContext) error {
+						return fmt.Errorf("dialog unavailable")
+					},
+				},
+			},
+		})
+		assert.NoError(t, err)
+
+		resp, err := agent.CreateResponse(context.Background(), WithInput("Use the tool"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool output"}, toolResultTexts(resp))
+	})
+}
+
 func TestPostToolUseHookIntegration(t *testing.T) {
 	t.Run("hook runs after successful tool call", func(t *testing.T) {
 		var capturedToolName string
@@ -0,0 +1,90 @@
+package dive
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	mrand "math/rand/v2"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// IDSource generates the random identifiers assigned during a run, such as
+// background task IDs and session event IDs. Like Clock, it can be replaced
+// so a recorded execution replays with identical IDs: record the seed of a
+// SeededIDSource alongside the execution and pass a new SeededIDSource with
+// the same seed, and the same FakeClock settings, when replaying.
+type IDSource interface {
+	// NewID returns a new identifier. It must be safe for concurrent use.
+	NewID() string
+}
+
+// SystemIDSource is the default IDSource. It returns random UUIDs read from
+// crypto/rand.
+var SystemIDSource IDSource = systemIDSource{}
+
+type systemIDSource struct{}
+
+func (systemIDSource) NewID() string {
+	return uuid.Must(uuid.NewRandomFromReader(rand.Reader)).String()
+}
+
+// IDSourceOrDefault returns s, or SystemIDSource if s is nil.
+func IDSourceOrDefault(s IDSource) IDSource {
+	if s == nil {
+		return SystemIDSource
+	}
+	return s
+}
+
+// SeededIDSource is a deterministic IDSource for tests and replay. Sources
+// created with the same seed return the same sequence of UUID-formatted
+// IDs. The IDs are not suitable where unpredictability matters. It is safe
+// for concurrent use, though concurrent callers receive IDs in whatever
+// order they reach it.
+type SeededIDSource struct {
+	mu   sync.Mutex
+	seed uint64
+	rng  *mrand.Rand
+}
+
+// NewSeededIDSource returns a SeededIDSource that starts from seed.
+func NewSeededIDSource(seed uint64) *SeededIDSource {
+	return &SeededIDSource{seed: seed, rng: mrand.New(mrand.NewPCG(seed, 0))}
+}
+
+// Seed returns the seed the source was created with, for recording.
+func (s *SeededIDSource) Seed() uint64 {
+	return s.seed
+}
+
+// NewID returns the next ID in the seeded sequence.
+func (s *SeededIDSource) NewID() string {
+	s.mu.Lock()
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:8], s.rng.Uint64())
+	binary.LittleEndian.PutUint64(b[8:], s.rng.Uint64())
+	s.mu.Unlock()
+	// Set the version 4 and variant bits, as uuid.NewRandom does
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return uuid.UUID(b).String()
+}
+
+// WithIDSource returns a context carrying source. The agent sets this on the
+// context passed to tools, so IDs that tools generate with NewID come from
+// the agent's IDSource.
+func WithIDSource(ctx context.Context, source IDSource) context.Context {
+	return context.WithValue(ctx, idSourceKey, source)
+}
+
+// NewID returns a new ID from the IDSource carried by ctx, or from
+// SystemIDSource if there is none. Tools should use it for IDs that end up
+// in their results so a replayed run produces the same output.
+func NewID(ctx context.Context) string {
+	if source, ok := ctx.Value(idSourceKey).(IDSource); ok && source != nil {
+		return source.NewID()
+	}
+	return SystemIDSource.NewID()
+}
@@ -0,0 +1,33 @@
+package dive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/google/uuid"
+)
+
+func TestSeededIDSource(t *testing.T) {
+	a := NewSeededIDSource(42)
+	b := NewSeededIDSource(42)
+	assert.Equal(t, uint64(42), a.Seed())
+	first := a.NewID()
+	assert.Equal(t, first, b.NewID())
+	assert.NotEqual(t, first, a.NewID())
+
+	parsed, err := uuid.Parse(first)
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Version(4), parsed.Version())
+	assert.Equal(t, uuid.RFC4122, parsed.Variant())
+}
+
+func TestNewIDUsesContextSource(t *testing.T) {
+	ctx := WithIDSource(context.Background(), NewSeededIDSource(1))
+	assert.Equal(t, NewSeededIDSource(1).NewID(), NewID(ctx))
+
+	// Without a source, IDs are random UUIDs
+	_, err := uuid.Parse(NewID(context.Background()))
+	assert.NoError(t, err)
+	assert.NotEqual(t, NewID(context.Background()), NewID(context.Background()))
+}
@@ -0,0 +1,209 @@
+package dive
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// InputPart is one part of a user message assembled by WithInputParts. Set
+// exactly one of Text, Path, or URL, or use TextPart, FilePart, and URLPart.
+type InputPart struct {
+	// Text is sent as a text block.
+	Text string
+
+	// Path is a local file. Images and PDFs are sent as native content
+	// blocks and text files are inlined in a <file> tag.
+	Path string
+
+	// URL is a remote image or PDF that the provider fetches.
+	URL string
+
+	// MediaType overrides the media type detected for a Path or URL part,
+	// such as "application/pdf" for a URL without a file extension.
+	MediaType string
+}
+
+// TextPart returns an input part with text.
+func TextPart(text string) InputPart {
+	return InputPart{Text: text}
+}
+
+// FilePart returns an input part for a local file.
+func FilePart(path string) InputPart {
+	return InputPart{Path: path}
+}
+
+// URLPart returns an input part for a remote image or PDF.
+func URLPart(url string) InputPart {
+	return InputPart{URL: url}
+}
+
+// maxInputFileBytes caps a file read for an input part when the model's
+// request size limit is unknown.
+const maxInputFileBytes = 32 << 20
+
+// WithInputParts adds a user message assembled from text, files, and URLs,
+// so asking about a set of files doesn't require building content blocks by
+// hand:
+//
+//	agent.CreateResponse(ctx, dive.WithInputParts(
+//	    dive.TextPart("What changed between these two versions?"),
+//	    dive.FilePart("report-v1.pdf"),
+//	    dive.FilePart("report-v2.pdf"),
+//	))
+//
+// The media type of a file or URL is detected from its extension, falling
+// back to the file's content. Images (PNG, JPEG, GIF, WebP) become image
+// blocks and PDFs become document blocks; other text files are inlined. The
+// message is appended after any WithMessages or WithInput messages. Files
+// are read when CreateResponse runs, which returns an error for a missing
+// or unsupported file, or for images over the model's limits reported by
+// llm.ModelInfoProvider. Multiple calls add parts to the same message.
+func WithInputParts(parts ...InputPart) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.InputParts = append(opts.InputParts, parts...)
+	}
+}
+
+// buildInputMessage assembles parts into a user message, checked against
+// the limits in info.
+func buildInputMessage(parts []InputPart, info llm.ModelInfo) (*llm.Message, error) {
+	msg := &llm.Message{Role: llm.User}
+	images := 0
+	for i, part := range parts {
+		content, err := buildInputContent(part, info)
+		if err != nil {
+			return nil, fmt.Errorf("input part %d: %w", i, err)
+		}
+		if _, ok := content.(*llm.ImageContent); ok {
+			images++
+		}
+		msg.Content = append(msg.Content, content)
+	}
+	if info.MaxImages > 0 && images > info.MaxImages {
+		return nil, fmt.Errorf("input has %d images, over the limit of %d for model %q", images, info.MaxImages, info.Model)
+	}
+	return msg, nil
+}
+
+// buildInputContent converts one part into a content block.
+func buildInputContent(part InputPart, info llm.ModelInfo) (llm.Content, error) {
+	switch {
+	case part.Path != "" && part.URL == "" && part.Text == "":
+		return buildFileContent(part, info)
+	case part.URL != "" && part.Path == "" && part.Text == "":
+		return buildURLContent(part)
+	case part.Path == "" && part.URL == "":
+		return &llm.TextContent{Text: part.Text}, nil
+	}
+	return nil, fmt.Errorf("set only one of Text, Path, or URL")
+}
+
+func buildFileContent(part InputPart, info llm.ModelInfo) (llm.Content, error) {
+	stat, err := os.Stat(part.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", part.Path)
+	}
+	limit := maxInputFileBytes
+	if info.MaxRequestBytes > 0 {
+		limit = info.MaxRequestBytes
+	}
+	if stat.Size() > int64(limit) {
+		return nil, fmt.Errorf("%s is %d bytes, over the %d-byte limit", part.Path, stat.Size(), limit)
+	}
+	data, err := os.ReadFile(part.Path)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(part.Path)
+	mediaType := part.MediaType
+	if mediaType == "" {
+		mediaType = mediaTypeByExtension(name)
+	}
+	if mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+
+	source := &llm.ContentSource{
+		Type:      llm.ContentSourceTypeBase64,
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(data),
+	}
+	switch {
+	case isInputImageType(mediaType):
+		if info.MaxImageBytes > 0 && len(data) > info.MaxImageBytes {
+			return nil, fmt.Errorf("image %s is %d bytes, over the %d-byte limit of model %q",
+				part.Path, len(data), info.MaxImageBytes, info.Model)
+		}
+		return &llm.ImageContent{Source: source}, nil
+	case mediaType == "application/pdf":
+		return &llm.DocumentContent{Source: source, Title: name}, nil
+	case isInputTextType(mediaType) && utf8.Valid(data):
+		return &llm.TextContent{Text: fmt.Sprintf("<file path=%q>\n%s\n</file>", part.Path, data)}, nil
+	}
+	return nil, fmt.Errorf("unsupported media type %q for %s", mediaType, part.Path)
+}
+
+func buildURLContent(part InputPart) (llm.Content, error) {
+	u, err := url.Parse(part.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	mediaType := part.MediaType
+	if mediaType == "" {
+		mediaType = mediaTypeByExtension(path.Base(u.Path))
+	}
+	source := &llm.ContentSource{Type: llm.ContentSourceTypeURL, MediaType: mediaType, URL: part.URL}
+	switch {
+	case isInputImageType(mediaType):
+		return &llm.ImageContent{Source: source}, nil
+	case mediaType == "application/pdf":
+		return &llm.DocumentContent{Source: source, Title: path.Base(u.Path)}, nil
+	case mediaType == "":
+		return nil, fmt.Errorf("can't detect the media type of %s; set InputPart.MediaType", part.URL)
+	}
+	return nil, fmt.Errorf("unsupported media type %q for %s", mediaType, part.URL)
+}
+
+// mediaTypeByExtension returns the media type for name's extension without
+// parameters, or "" if it is unknown.
+func mediaTypeByExtension(name string) string {
+	mediaType, _, _ := mime.ParseMediaType(mime.TypeByExtension(strings.ToLower(filepath.Ext(name))))
+	return mediaType
+}
+
+// isInputImageType reports whether mediaType is an image type providers
+// accept.
+func isInputImageType(mediaType string) bool {
+	switch llm.ImageType(mediaType) {
+	case llm.ImageTypePNG, llm.ImageTypeJPEG, llm.ImageTypeGIF, llm.ImageTypeWEBP:
+		return true
+	}
+	return false
+}
+
+// isInputTextType reports whether mediaType is sent as inline text.
+func isInputTextType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/xml" ||
+		mediaType == "application/x-yaml" ||
+		mediaType == "application/yaml" ||
+		mediaType == "application/octet-stream" // unknown extension, checked for UTF-8
+}
@@ -0,0 +1,114 @@
+package dive
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestWithInputParts(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "chart.png")
+	assert.NoError(t, os.WriteFile(image, testPNG, 0o644))
+	pdf := filepath.Join(dir, "report.pdf")
+	assert.NoError(t, os.WriteFile(pdf, []byte("%PDF-1.7 report"), 0o644))
+	notes := filepath.Join(dir, "notes")
+	assert.NoError(t, os.WriteFile(notes, []byte("plain notes"), 0o644))
+
+	model := newWindowedLLM(0)
+	agent, err := NewAgent(AgentOptions{Model: model})
+	assert.NoError(t, err)
+	history := llm.NewUserTextMessage("earlier question")
+	_, err = agent.CreateResponse(context.Background(),
+		WithMessages(history),
+		WithInputParts(
+			TextPart("Compare these"),
+			FilePart(image),
+			FilePart(pdf),
+			FilePart(notes),
+		),
+		WithInputParts(
+			URLPart("https://example.com/photo.JPG"),
+			InputPart{URL: "https://example.com/download?id=1", MediaType: "application/pdf"},
+		),
+	)
+	assert.NoError(t, err)
+
+	messages := model.requests[0]
+	assert.Len(t, messages, 2)
+	assert.Equal(t, history, messages[0])
+	content := messages[1].Content
+	assert.Equal(t, llm.User, messages[1].Role)
+	assert.Len(t, content, 6)
+
+	assert.Equal(t, &llm.TextContent{Text: "Compare these"}, content[0])
+	assert.Equal(t, &llm.ImageContent{Source: &llm.ContentSource{
+		Type:      llm.ContentSourceTypeBase64,
+		MediaType: "image/png",
+		Data:      base64.StdEncoding.EncodeToString(testPNG),
+	}}, content[1])
+	doc := content[2].(*llm.DocumentContent)
+	assert.Equal(t, "report.pdf", doc.Title)
+	assert.Equal(t, "application/pdf", doc.Source.MediaType)
+	assert.Equal(t, "<file path=\""+notes+"\">\nplain notes\n</file>", content[3].(*llm.TextContent).Text)
+	assert.Equal(t, &llm.ImageContent{Source: &llm.ContentSource{
+		Type:      llm.ContentSourceTypeURL,
+		MediaType: "image/jpeg",
+		URL:       "https://example.com/photo.JPG",
+	}}, content[4])
+	assert.Equal(t, llm.ContentSourceTypeURL, content[5].(*llm.DocumentContent).Source.Type)
+}
+
+// imageLimitedLLM reports image limits for its model.
+type imageLimitedLLM struct {
+	mockLLM
+}
+
+func (m *imageLimitedLLM) ModelInfo(model string) llm.ModelInfo {
+	return llm.ModelInfo{Model: "test-model", MaxImages: 1, MaxImageBytes: 8}
+}
+
+func TestWithInputPartsErrors(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "chart.png")
+	assert.NoError(t, os.WriteFile(image, testPNG, 0o644))
+	binary := filepath.Join(dir, "blob.bin")
+	assert.NoError(t, os.WriteFile(binary, []byte{0xff, 0x00, 0xfe}, 0o644))
+
+	called := false
+	model := &imageLimitedLLM{}
+	model.generateFunc = func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		called = true
+		return &llm.Response{Role: llm.Assistant}, nil
+	}
+	agent, err := NewAgent(AgentOptions{Model: model})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		parts []InputPart
+		want  string
+	}{
+		{"missing file", []InputPart{FilePart(filepath.Join(dir, "missing.txt"))}, "no such file"},
+		{"binary file", []InputPart{FilePart(binary)}, "unsupported media type"},
+		{"image too large", []InputPart{FilePart(image)}, "over the 8-byte limit"},
+		{"too many images", []InputPart{URLPart("https://a.test/1.png"), URLPart("https://a.test/2.png")}, "2 images, over the limit of 1"},
+		{"unknown URL type", []InputPart{URLPart("https://a.test/file")}, "set InputPart.MediaType"},
+		{"ambiguous part", []InputPart{{Text: "hi", Path: image}}, "only one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := agent.CreateResponse(context.Background(), WithInputParts(tt.parts...))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+	assert.False(t, called)
+}
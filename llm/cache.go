@@ -0,0 +1,424 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+// DefaultCacheSize is the number of responses an LRUCache holds when
+// NewLRUCache is given a size of zero or less.
+const DefaultCacheSize = 1000
+
+// Cache stores LLM responses by request key for WithCache. Implementations
+// must be safe for concurrent use. A Cache may keep the responses it is
+// given; WithCache never modifies them.
+type Cache interface {
+	// Get returns the response stored under key, if any.
+	Get(key string) (*Response, bool)
+
+	// Set stores response under key.
+	Set(key string, response *Response)
+}
+
+// WithCache returns an LLM that answers a request from cache when an
+// identical request has been answered before, and otherwise passes it to
+// model and stores the response. Requests are identical when they have the
+// same model, endpoint, previous response ID, system prompt, messages,
+// tools, tool choice, response format, request headers, service tier, and
+// sampling, speed, and reasoning settings. Failed requests aren't cached, and
+// WithCacheBypass skips the cache for a single request. A request that
+// doesn't set a model is keyed by model.Name() in its place, so LLMs with
+// different default models should share a Cache only if their requests
+// name a model.
+//
+// A cached response is returned with the usage of the request that produced
+// it. When a stream is answered from cache, its events are replayed from the
+// stored response: each content block arrives whole in a single delta, and
+// blocks that streams don't carry, such as server tool results, are left
+// out. Like WithMiddleware, the returned LLM streams if model does and
+// forwards ModelInfoProvider, ToolLimiter, ResponseContinuer, and
+// TokenCounter.
+func WithCache(model LLM, cache Cache) LLM {
+	c := &cacheLLM{middlewareLLM: &middlewareLLM{llm: model}, cache: cache}
+	if _, ok := model.(StreamingLLM); ok {
+		return &streamingCacheLLM{c}
+	}
+	return c
+}
+
+type cacheLLM struct {
+	*middlewareLLM
+	cache Cache
+}
+
+func (c *cacheLLM) Generate(ctx context.Context, opts ...Option) (*Response, error) {
+	key, ok := c.key(opts)
+	if !ok {
+		return c.llm.Generate(ctx, opts...)
+	}
+	if cached, found := c.cache.Get(key); found {
+		if response, err := cloneResponse(cached); err == nil {
+			return response, nil
+		}
+	}
+	response, err := c.llm.Generate(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, response)
+	return response, nil
+}
+
+// key returns the cache key of the request built from opts, or false if
+// the request bypasses the cache.
+func (c *cacheLLM) key(opts []Option) (string, bool) {
+	var config Config
+	config.Apply(opts...)
+	if config.BypassCache {
+		return "", false
+	}
+	key, err := cacheKey(c.llm.Name(), &config)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// store caches a copy of response, so the caller's changes to it don't
+// reach the cache.
+func (c *cacheLLM) store(key string, response *Response) {
+	if stored, err := cloneResponse(response); err == nil {
+		c.cache.Set(key, stored)
+	}
+}
+
+type streamingCacheLLM struct {
+	*cacheLLM
+}
+
+func (c *streamingCacheLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	key, ok := c.key(opts)
+	if !ok {
+		return c.llm.(StreamingLLM).Stream(ctx, opts...)
+	}
+	if cached, found := c.cache.Get(key); found {
+		if response, err := cloneResponse(cached); err == nil {
+			return &replayStream{events: replayEvents(response)}, nil
+		}
+	}
+	stream, err := c.llm.(StreamingLLM).Stream(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &cachingStream{
+		StreamIterator: stream,
+		llm:            c.cacheLLM,
+		key:            key,
+		accumulator:    NewResponseAccumulator(),
+	}, nil
+}
+
+// cachingStream passes a stream through, caching its response once the
+// message stops.
+type cachingStream struct {
+	StreamIterator
+	llm         *cacheLLM
+	key         string
+	accumulator *ResponseAccumulator
+	failed      bool
+}
+
+func (s *cachingStream) Next() bool {
+	if !s.StreamIterator.Next() {
+		return false
+	}
+	event := s.StreamIterator.Event()
+	if s.failed || event == nil {
+		return true
+	}
+	if event.Type == EventTypeMessageStart && event.Message != nil {
+		// The caller's accumulator adds usage to the message too
+		start, message := *event, *event.Message
+		start.Message = &message
+		event = &start
+	}
+	if err := s.accumulator.AddEvent(event); err != nil {
+		s.failed = true
+		return true
+	}
+	if event.Type == EventTypeMessageStop {
+		s.llm.store(s.key, s.accumulator.Response())
+	}
+	return true
+}
+
+// replayStream emits a fixed list of events.
+type replayStream struct {
+	events []*Event
+	index  int
+}
+
+func (s *replayStream) Next() bool {
+	if s.index >= len(s.events) {
+		return false
+	}
+	s.index++
+	return true
+}
+
+func (s *replayStream) Event() *Event {
+	if s.index == 0 {
+		return nil
+	}
+	return s.events[s.index-1]
+}
+
+func (s *replayStream) Err() error   { return nil }
+func (s *replayStream) Close() error { return nil }
+
+// replayEvents returns the stream events that build up response. The
+// events are the same each time for the same response.
+func replayEvents(response *Response) []*Event {
+	start := *response
+	start.Content = nil
+	start.StopReason = ""
+	start.StopSequence = nil
+	events := []*Event{{Type: EventTypeMessageStart, Message: &start}}
+
+	index := 0
+	for _, content := range response.Content {
+		var block *EventContentBlock
+		var deltas []*EventDelta
+		switch c := content.(type) {
+		case *TextContent:
+			block = &EventContentBlock{Type: ContentTypeText}
+			deltas = append(deltas, &EventDelta{Type: EventDeltaTypeText, Text: c.Text})
+			for _, citation := range c.Citations {
+				deltas = append(deltas, &EventDelta{Type: EventDeltaTypeCitations, Citation: citation})
+			}
+		case *ToolUseContent:
+			block = &EventContentBlock{Type: ContentTypeToolUse, ID: c.ID, Name: c.Name, Metadata: c.Metadata}
+			deltas = append(deltas, &EventDelta{Type: EventDeltaTypeInputJSON, PartialJSON: string(c.Input)})
+		case *ThinkingContent:
+			block = &EventContentBlock{Type: ContentTypeThinking}
+			deltas = append(deltas, &EventDelta{Type: EventDeltaTypeThinking, Thinking: c.Thinking})
+			if c.Signature != "" {
+				deltas = append(deltas, &EventDelta{Type: EventDeltaTypeSignature, Signature: c.Signature})
+			}
+		case *RedactedThinkingContent:
+			block = &EventContentBlock{Type: ContentTypeRedactedThinking}
+		case *FileContent:
+			block = &EventContentBlock{Type: ContentTypeFile, Filename: c.Filename, Source: c.Source, ContainerID: c.ContainerID}
+		default:
+			continue
+		}
+		blockIndex := index
+		events = append(events, &Event{Type: EventTypeContentBlockStart, Index: &blockIndex, ContentBlock: block})
+		for _, delta := range deltas {
+			events = append(events, &Event{Type: EventTypeContentBlockDelta, Index: &blockIndex, Delta: delta})
+		}
+		events = append(events, &Event{Type: EventTypeContentBlockStop, Index: &blockIndex})
+		index++
+	}
+
+	delta := &EventDelta{StopReason: response.StopReason}
+	if response.StopSequence != nil {
+		delta.StopSequence = *response.StopSequence
+	}
+	events = append(events,
+		&Event{Type: EventTypeMessageDelta, Delta: delta},
+		&Event{Type: EventTypeMessageStop},
+	)
+	return events
+}
+
+// cloneResponse returns a deep copy of response.
+func cloneResponse(response *Response) (*Response, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	var clone Response
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// cacheTool is the part of a tool that affects a response.
+type cacheTool struct {
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Schema        *schema.Schema `json:"schema,omitempty"`
+	Configuration map[string]any `json:"configuration,omitempty"`
+}
+
+// cacheKey returns a hash of the parts of config that affect the response.
+// provider is the name of the LLM the request is sent to.
+func cacheKey(provider string, config *Config) (string, error) {
+	tools := make([]cacheTool, len(config.Tools))
+	for i, tool := range config.Tools {
+		tools[i] = cacheTool{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			Schema:      tool.Schema(),
+		}
+		if configurable, ok := tool.(ToolConfiguration); ok {
+			tools[i].Configuration = configurable.ToolConfiguration(provider)
+		}
+	}
+	data, err := json.Marshal(struct {
+		Provider           string                   `json:"provider"`
+		Model              string                   `json:"model"`
+		Endpoint           string                   `json:"endpoint"`
+		PreviousResponseID string                   `json:"previous_response_id"`
+		ServiceTier        string                   `json:"service_tier"`
+		Speed              Speed                    `json:"speed"`
+		ThinkingDisplay    ThinkingDisplay          `json:"thinking_display"`
+		RequestHeaders     http.Header              `json:"request_headers"`
+		SystemPrompt       string                   `json:"system_prompt"`
+		Prefill            string                   `json:"prefill"`
+		PrefillClosingTag  string                   `json:"prefill_closing_tag"`
+		Messages           Messages                 `json:"messages"`
+		MaxTokens          *int                     `json:"max_tokens"`
+		Temperature        *float64                 `json:"temperature"`
+		PresencePenalty    *float64                 `json:"presence_penalty"`
+		FrequencyPenalty   *float64                 `json:"frequency_penalty"`
+		ReasoningBudget    *int                     `json:"reasoning_budget"`
+		ReasoningEffort    ReasoningEffort          `json:"reasoning_effort"`
+		ReasoningSummary   ReasoningSummary         `json:"reasoning_summary"`
+		Thinking           ThinkingType             `json:"thinking"`
+		ContextManagement  *ContextManagementConfig `json:"context_management"`
+		Tools              []cacheTool              `json:"tools"`
+		ToolChoice         *ToolChoice              `json:"tool_choice"`
+		ParallelToolCalls  *bool                    `json:"parallel_tool_calls"`
+		Features           []string                 `json:"features"`
+		MCPServers         []MCPServerConfig        `json:"mcp_servers"`
+		ResponseFormat     *ResponseFormat          `json:"response_format"`
+		ProviderOptions    map[string]interface{}   `json:"provider_options"`
+	}{
+		Provider:           provider,
+		Model:              config.Model,
+		Endpoint:           config.Endpoint,
+		PreviousResponseID: config.PreviousResponseID,
+		ServiceTier:        config.ServiceTier,
+		Speed:              config.Speed,
+		ThinkingDisplay:    config.ThinkingDisplay,
+		RequestHeaders:     config.RequestHeaders,
+		SystemPrompt:       config.SystemPrompt,
+		Prefill:            config.Prefill,
+		PrefillClosingTag:  config.PrefillClosingTag,
+		Messages:           config.Messages,
+		MaxTokens:          config.MaxTokens,
+		Temperature:        config.Temperature,
+		PresencePenalty:    config.PresencePenalty,
+		FrequencyPenalty:   config.FrequencyPenalty,
+		ReasoningBudget:    config.ReasoningBudget,
+		ReasoningEffort:    config.ReasoningEffort,
+		ReasoningSummary:   config.ReasoningSummary,
+		Thinking:           config.Thinking,
+		ContextManagement:  config.ContextManagement,
+		Tools:              tools,
+		ToolChoice:         config.ToolChoice,
+		ParallelToolCalls:  config.ParallelToolCalls,
+		Features:           config.Features,
+		MCPServers:         config.MCPServers,
+		ResponseFormat:     config.ResponseFormat,
+		ProviderOptions:    config.ProviderOptions,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used
+// response once it holds its maximum number, and optionally expires
+// responses after a time to live.
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List // front is most recently used
+	now     func() time.Time
+}
+
+type lruEntry struct {
+	key      string
+	response *Response
+	expires  time.Time
+}
+
+// NewLRUCache returns an LRUCache holding up to size responses, or
+// DefaultCacheSize if size is zero or less. Responses expire ttl after they
+// are stored; a ttl of zero keeps them until they are evicted.
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &LRUCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get returns the response stored under key, unless it has expired.
+func (c *LRUCache) Get(key string) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.response, true
+}
+
+// Set stores response under key, evicting the least recently used response
+// if the cache is full.
+func (c *LRUCache) Set(key string, response *Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &lruEntry{key: key, response: response}
+	if c.ttl > 0 {
+		entry.expires = c.now().Add(c.ttl)
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of responses in the cache, including any that
+// have expired but not yet been removed.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -18,3 +18,44 @@ const (
 	CacheTTL5m = "5m"
 	CacheTTL1h = "1h"
 )
+
+// CacheBreakpoint marks the end of a prompt prefix for the provider to cache,
+// for providers that support explicit breakpoints. Create one with
+// SystemCacheBreakpoint or MessageCacheBreakpoint and pass it to
+// WithCacheBreakpoints.
+type CacheBreakpoint struct {
+	// System places the breakpoint in the system prompt rather than after a
+	// message.
+	System bool `json:"system,omitempty"`
+
+	// SystemOffset is the byte offset in the system prompt at which the
+	// cached prefix ends. Zero means the end of the system prompt.
+	SystemOffset int `json:"system_offset,omitempty"`
+
+	// Message is the index of the message that ends the cached prefix.
+	// Negative indices count back from the end, so -1 is the last message.
+	Message int `json:"message,omitempty"`
+
+	// TTL is the cache lifetime, CacheTTL5m or CacheTTL1h. Empty uses the
+	// provider's default.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// SystemCacheBreakpoint returns a breakpoint that caches the system prompt up
+// to offset bytes, or all of it if offset is zero. Put stable instructions
+// first and per-request text after the offset.
+func SystemCacheBreakpoint(offset int) CacheBreakpoint {
+	return CacheBreakpoint{System: true, SystemOffset: offset}
+}
+
+// MessageCacheBreakpoint returns a breakpoint that caches the conversation
+// through the message at index. Negative indices count back from the end.
+func MessageCacheBreakpoint(index int) CacheBreakpoint {
+	return CacheBreakpoint{Message: index}
+}
+
+// WithTTL returns a copy of the breakpoint with the given TTL.
+func (b CacheBreakpoint) WithTTL(ttl string) CacheBreakpoint {
+	b.TTL = ttl
+	return b
+}
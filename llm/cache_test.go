@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+// countingLLM answers every request with the same response, counting calls.
+type countingLLM struct {
+	calls int
+}
+
+func (c *countingLLM) Name() string { return "counting" }
+
+func (c *countingLLM) Generate(ctx context.Context, opts ...Option) (*Response, error) {
+	c.calls++
+	return &Response{
+		Role:       Assistant,
+		Model:      "test-model",
+		Content:    []Content{&TextContent{Text: "hello"}},
+		StopReason: "end_turn",
+		Usage:      Usage{InputTokens: 10, OutputTokens: 2},
+	}, nil
+}
+
+// countingStreamLLM streams a response with thinking, text, and a tool call.
+type countingStreamLLM struct {
+	countingLLM
+}
+
+func (c *countingStreamLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	c.calls++
+	index := func(i int) *int { return &i }
+	return &sliceStream{events: []*Event{
+		{Type: EventTypeMessageStart, Message: &Response{Role: Assistant, Model: "test-model", Usage: Usage{InputTokens: 10}}},
+		{Type: EventTypeContentBlockStart, Index: index(0), ContentBlock: &EventContentBlock{Type: ContentTypeThinking}},
+		{Type: EventTypeContentBlockDelta, Index: index(0), Delta: &EventDelta{Type: EventDeltaTypeThinking, Thinking: "Look it "}},
+		{Type: EventTypeContentBlockDelta, Index: index(0), Delta: &EventDelta{Type: EventDeltaTypeThinking, Thinking: "up."}},
+		{Type: EventTypeContentBlockDelta, Index: index(0), Delta: &EventDelta{Type: EventDeltaTypeSignature, Signature: "sig"}},
+		{Type: EventTypeContentBlockStop, Index: index(0)},
+		{Type: EventTypeContentBlockStart, Index: index(1), ContentBlock: &EventContentBlock{Type: ContentTypeText}},
+		{Type: EventTypeContentBlockDelta, Index: index(1), Delta: &EventDelta{Type: EventDeltaTypeText, Text: "Checking "}},
+		{Type: EventTypeContentBlockDelta, Index: index(1), Delta: &EventDelta{Type: EventDeltaTypeText, Text: "the weather."}},
+		{Type: EventTypeContentBlockStop, Index: index(1)},
+		{Type: EventTypeContentBlockStart, Index: index(2), ContentBlock: &EventContentBlock{Type: ContentTypeToolUse, ID: "call_1", Name: "weather"}},
+		{Type: EventTypeContentBlockDelta, Index: index(2), Delta: &EventDelta{Type: EventDeltaTypeInputJSON, PartialJSON: `{"city":`}},
+		{Type: EventTypeContentBlockDelta, Index: index(2), Delta: &EventDelta{Type: EventDeltaTypeInputJSON, PartialJSON: `"Oslo"}`}},
+		{Type: EventTypeContentBlockStop, Index: index(2)},
+		{Type: EventTypeMessageDelta, Delta: &EventDelta{StopReason: "tool_use"}, Usage: &Usage{OutputTokens: 25}},
+		{Type: EventTypeMessageStop},
+	}}, nil
+}
+
+func accumulate(t *testing.T, stream StreamIterator) (*Response, []*Event) {
+	t.Helper()
+	defer stream.Close()
+	accumulator := NewResponseAccumulator()
+	var events []*Event
+	for stream.Next() {
+		events = append(events, stream.Event())
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Err())
+	assert.True(t, accumulator.IsComplete())
+	return accumulator.Response(), events
+}
+
+func TestWithCacheGenerate(t *testing.T) {
+	inner := &countingLLM{}
+	model := WithCache(inner, NewLRUCache(0, 0))
+	_, isStreaming := model.(StreamingLLM)
+	assert.False(t, isStreaming)
+	ctx := context.Background()
+
+	first, err := model.Generate(ctx, WithUserTextMessage("hi"), WithTemperature(0))
+	assert.NoError(t, err)
+	first.Content[0].(*TextContent).Text = "changed by caller"
+
+	second, err := model.Generate(ctx, WithUserTextMessage("hi"), WithTemperature(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, "hello", second.Message().Text())
+	assert.Equal(t, 10, second.Usage.InputTokens)
+
+	_, err = model.Generate(ctx, WithUserTextMessage("hi"), WithTemperature(0.5))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+
+	_, err = model.Generate(ctx, WithUserTextMessage("hi"), WithTemperature(0), WithCacheBypass())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, inner.calls)
+}
+
+func TestWithCacheStreamReplay(t *testing.T) {
+	inner := &countingStreamLLM{}
+	cache := NewLRUCache(0, 0)
+	model := WithCache(inner, cache).(StreamingLLM)
+	ctx := context.Background()
+
+	stream, err := model.Stream(ctx, WithUserTextMessage("weather?"))
+	assert.NoError(t, err)
+	live, _ := accumulate(t, stream)
+	assert.Equal(t, 1, cache.Len())
+
+	stream, err = model.Stream(ctx, WithUserTextMessage("weather?"))
+	assert.NoError(t, err)
+	replayed, events := accumulate(t, stream)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, live, replayed)
+	assert.Equal(t, "tool_use", replayed.StopReason)
+	assert.Equal(t, 25, replayed.Usage.OutputTokens)
+	assert.Equal(t, `{"city":"Oslo"}`, string(replayed.ToolCalls()[0].Input))
+
+	// A second replay emits the same events
+	stream, err = model.Stream(ctx, WithUserTextMessage("weather?"))
+	assert.NoError(t, err)
+	_, again := accumulate(t, stream)
+	want, _ := json.Marshal(events)
+	got, _ := json.Marshal(again)
+	assert.Equal(t, string(want), string(got))
+
+	// Generate shares the cache with Stream
+	response, err := model.(LLM).Generate(ctx, WithUserTextMessage("weather?"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Checking the weather.", response.Message().Text())
+	assert.Equal(t, 1, inner.calls)
+}
+
+func TestCacheKey(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			Model:              "test-model",
+			PreviousResponseID: "resp_1",
+			Messages:           Messages{NewUserTextMessage("hi")},
+			Tools: []Tool{NewToolDefinition().WithName("search").WithSchema(&schema.Schema{
+				Type:       schema.Object,
+				Properties: map[string]*schema.Property{"q": {Type: schema.String}},
+			})},
+		}
+	}
+	key := func(provider string, config *Config) string {
+		k, err := cacheKey(provider, config)
+		assert.NoError(t, err)
+		return k
+	}
+	baseKey := key("test", base())
+	assert.Equal(t, baseKey, key("test", base()))
+	assert.NotEqual(t, baseKey, key("other", base()))
+
+	changes := map[string]func(*Config){
+		"model":    func(c *Config) { c.Model = "other-model" },
+		"messages": func(c *Config) { c.Messages = append(c.Messages, NewAssistantTextMessage("hello")) },
+		"tools":    func(c *Config) { c.Tools = append(c.Tools, NewToolDefinition().WithName("fetch")) },
+		"format":   func(c *Config) { c.ResponseFormat = &ResponseFormat{Type: ResponseFormatTypeJSON} },
+		"system":   func(c *Config) { c.SystemPrompt = "be brief" },
+		"previous": func(c *Config) { c.PreviousResponseID = "resp_2" },
+		"endpoint": func(c *Config) { c.Endpoint = "https://example.com/v1" },
+		"headers":  func(c *Config) { c.RequestHeaders = http.Header{"Anthropic-Beta": {"x"}} },
+		"tier":     func(c *Config) { c.ServiceTier = "flex" },
+		"speed":    func(c *Config) { c.Speed = SpeedFast },
+		"display":  func(c *Config) { c.ThinkingDisplay = ThinkingDisplayOmitted },
+	}
+	for name, change := range changes {
+		config := base()
+		change(config)
+		assert.NotEqual(t, baseKey, key("test", config), name)
+	}
+
+	// Settings that don't change the response don't change the key
+	config := base()
+	config.Logger = &NullLogger{}
+	config.APIKey = "secret"
+	assert.Equal(t, baseKey, key("test", config))
+}
+
+func TestLRUCache(t *testing.T) {
+	now := time.Now()
+	cache := NewLRUCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+	response := func(text string) *Response {
+		return &Response{Content: []Content{&TextContent{Text: text}}}
+	}
+
+	cache.Set("a", response("a"))
+	cache.Set("b", response("b"))
+	_, ok := cache.Get("a") // a is now more recent than b
+	assert.True(t, ok)
+	cache.Set("c", response("c"))
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, cache.Len())
+
+	now = now.Add(time.Minute)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.Len())
+}
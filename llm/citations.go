@@ -12,6 +12,7 @@ const (
 	CitationTypeCharLocation            CitationType = "char_location"
 	CitationTypeWebSearchResultLocation CitationType = "web_search_result_location"
 	CitationTypeURLCitation             CitationType = "url_citation"
+	CitationTypeContent                 CitationType = "citation"
 )
 
 // CitationSettings contains settings for citations in a message.
@@ -61,6 +62,82 @@ func (c *WebSearchResultLocation) IsCitation() bool {
 	return true
 }
 
+// CitationContent is a provider-neutral citation. OpenAI and Google
+// responses carry citations of this type, tools report their sources with
+// it (see dive.ToolResult.Sources), and NormalizeCitation converts the
+// Anthropic citation types to it.
+//
+// On a TextContent block, StartIndex and EndIndex are byte offsets of the
+// cited span within the block's Text. In the results of Message.Citations
+// they are offsets within Message.Text. A citation without a span has both
+// set to zero.
+type CitationContent struct {
+	Type       string `json:"type"` // "citation"
+	Title      string `json:"title,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Snippet    string `json:"snippet,omitempty"`
+	StartIndex int    `json:"start_index,omitempty"`
+	EndIndex   int    `json:"end_index,omitempty"`
+
+	// SourceID identifies the source, such as a file ID or document index,
+	// when there is no URL. It defaults to the URL.
+	SourceID string `json:"source_id,omitempty"`
+}
+
+func (c *CitationContent) IsCitation() bool {
+	return true
+}
+
+// HasSpan reports whether the citation covers a span of the answer text.
+func (c *CitationContent) HasSpan() bool {
+	return c.EndIndex > c.StartIndex
+}
+
+// Source returns the key identifying the cited source: SourceID if set,
+// otherwise URL.
+func (c *CitationContent) Source() string {
+	if c.SourceID != "" {
+		return c.SourceID
+	}
+	return c.URL
+}
+
+func (c *CitationContent) MarshalJSON() ([]byte, error) {
+	type citationAlias CitationContent
+	alias := citationAlias(*c)
+	alias.Type = string(CitationTypeContent)
+	return json.Marshal(alias)
+}
+
+// NormalizeCitation converts a citation of any type to a CitationContent.
+// Anthropic citations have no span in the answer text; a CharLocation is
+// identified by its document index, as "document:N". It returns nil for
+// citation types it doesn't know.
+func NormalizeCitation(c Citation) *CitationContent {
+	switch c := c.(type) {
+	case *CitationContent:
+		result := *c
+		result.Type = string(CitationTypeContent)
+		return &result
+	case *WebSearchResultLocation:
+		return &CitationContent{
+			Type:     string(CitationTypeContent),
+			Title:    c.Title,
+			URL:      c.URL,
+			Snippet:  c.CitedText,
+			SourceID: c.URL,
+		}
+	case *CharLocation:
+		return &CitationContent{
+			Type:     string(CitationTypeContent),
+			Title:    c.DocumentTitle,
+			Snippet:  c.CitedText,
+			SourceID: fmt.Sprintf("document:%d", c.DocumentIndex),
+		}
+	}
+	return nil
+}
+
 type citationTypeIndicator struct {
 	Type CitationType `json:"type"`
 }
@@ -83,6 +160,12 @@ func unmarshalCitation(data []byte) (Citation, error) {
 			return nil, err
 		}
 		return c, nil
+	case CitationTypeContent:
+		var c *CitationContent
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, err
+		}
+		return c, nil
 	default:
 		return nil, fmt.Errorf("unknown citation type: %s", ct.Type)
 	}
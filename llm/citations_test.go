@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/deepnoodle-ai/wonton/assert"
@@ -179,3 +180,53 @@ func TestWebSearchResultLocation_IsCitation(t *testing.T) {
 	citation := &WebSearchResultLocation{}
 	assert.True(t, citation.IsCitation())
 }
+
+func TestCitationContentJSON(t *testing.T) {
+	data, err := json.Marshal(&CitationContent{Title: "Go", URL: "https://go.dev", StartIndex: 2, EndIndex: 5})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"citation"`)
+
+	result, err := unmarshalCitation(data)
+	assert.NoError(t, err)
+	assert.Equal(t, &CitationContent{
+		Type:       "citation",
+		Title:      "Go",
+		URL:        "https://go.dev",
+		StartIndex: 2,
+		EndIndex:   5,
+	}, result)
+}
+
+func TestNormalizeCitation(t *testing.T) {
+	web := NormalizeCitation(&WebSearchResultLocation{URL: "https://go.dev", Title: "Go", CitedText: "Go is"})
+	assert.Equal(t, "https://go.dev", web.URL)
+	assert.Equal(t, "Go is", web.Snippet)
+	assert.Equal(t, "https://go.dev", web.Source())
+
+	doc := NormalizeCitation(&CharLocation{DocumentIndex: 2, DocumentTitle: "Spec", CitedText: "text", StartCharIndex: 4, EndCharIndex: 8})
+	assert.Equal(t, "Spec", doc.Title)
+	assert.Equal(t, "document:2", doc.Source())
+	assert.False(t, doc.HasSpan())
+
+	assert.Nil(t, NormalizeCitation(&MockCitation{Text: "other"}))
+}
+
+func TestMessageCitations(t *testing.T) {
+	msg := &Message{Role: Assistant, Content: []Content{
+		&TextContent{Text: "Go is fast.", Citations: []Citation{
+			&CitationContent{URL: "https://go.dev", StartIndex: 0, EndIndex: 5},
+		}},
+		&ToolUseContent{ID: "call_1", Name: "search"},
+		&TextContent{Text: "It compiles quickly.", Citations: []Citation{
+			&WebSearchResultLocation{URL: "https://example.com", Title: "Example"},
+		}},
+	}}
+	citations := msg.Citations()
+	assert.Len(t, citations, 2)
+
+	text := msg.Text()
+	assert.Equal(t, "Go is", text[citations[0].StartIndex:citations[0].EndIndex])
+	// Without offsets, a citation covers its whole block
+	assert.Equal(t, "It compiles quickly.", text[citations[1].StartIndex:citations[1].EndIndex])
+	assert.Equal(t, "Example", citations[1].Title)
+}
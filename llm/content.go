@@ -454,6 +454,10 @@ type ToolUseContent struct {
 	// must be replayed to the provider on later requests — for example a Google
 	// function-call thought signature.
 	Metadata ProviderMetadata `json:"metadata,omitempty"`
+	// Repaired is true when Input was cut off mid-stream (typically by the
+	// output token limit) and completed by RepairJSON. Repaired input is
+	// valid JSON but may be missing trailing fields. Not sent to providers.
+	Repaired bool `json:"-"`
 }
 
 func (c *ToolUseContent) Type() ContentType {
@@ -1301,6 +1305,57 @@ func (c *TextEditorCodeExecutionToolResultContent) IsError() bool {
 	return c.Content.Type == "text_editor_code_execution_tool_result_error"
 }
 
+//// FileContent ////////////////////////////////////////////////////////////////
+
+// FileContent represents a file generated by the model, typically by a
+// server-side tool such as a code interpreter: a CSV, a chart, or an image.
+// Source.MediaType carries the MIME type when known. The file data may be
+// inline (base64), at a URL, or referenced by a provider file ID.
+//
+// FileContent only appears in responses. Providers skip it when a message
+// containing it is sent back, since the provider's own tool blocks already
+// describe the file.
+
+/* Example:
+{
+  "type": "file",
+  "filename": "chart.png",
+  "source": {
+    "type": "file",
+    "media_type": "image/png",
+    "file_id": "cfile_abc123"
+  },
+  "container_id": "cntr_abc123"
+}
+*/
+
+type FileContent struct {
+	// Filename is the name of the file, if known.
+	Filename string `json:"filename,omitempty"`
+
+	// Source locates the file data.
+	Source *ContentSource `json:"source"`
+
+	// ContainerID identifies the code execution container that holds the
+	// file, for providers that scope file IDs to a container (e.g. OpenAI).
+	ContainerID string `json:"container_id,omitempty"`
+}
+
+func (c *FileContent) Type() ContentType {
+	return ContentTypeFile
+}
+
+func (c *FileContent) MarshalJSON() ([]byte, error) {
+	type Alias FileContent
+	return json.Marshal(struct {
+		Type ContentType `json:"type"`
+		*Alias
+	}{
+		Type:  ContentTypeFile,
+		Alias: (*Alias)(c),
+	})
+}
+
 //// SummaryContent /////////////////////////////////////////////////////////////
 
 // SummaryContent represents a compacted conversation summary that replaces
@@ -1407,6 +1462,8 @@ func UnmarshalContent(data []byte) (Content, error) {
 		content = &TextEditorCodeExecutionToolResultContent{}
 	case ContentTypeRefusal:
 		content = &RefusalContent{}
+	case ContentTypeFile:
+		content = &FileContent{}
 	case ContentTypeMCPApprovalResponse:
 		content = &MCPApprovalResponseContent{}
 	case ContentTypeSummary:
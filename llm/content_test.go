@@ -762,6 +762,45 @@ func TestRedactedThinkingContent(t *testing.T) {
 	})
 }
 
+func TestFileContent(t *testing.T) {
+	content := &FileContent{
+		Filename: "data.csv",
+		Source: &ContentSource{
+			Type:      ContentSourceTypeFile,
+			MediaType: "text/csv",
+			FileID:    "cfile_123",
+		},
+		ContainerID: "cntr_456",
+	}
+	data, err := json.Marshal(content)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"file","filename":"data.csv","source":{"type":"file","media_type":"text/csv","file_id":"cfile_123"},"container_id":"cntr_456"}`, string(data))
+
+	decoded, err := UnmarshalContent(data)
+	assert.NoError(t, err)
+	assert.Equal(t, content, decoded.(*FileContent))
+}
+
+func TestResponseFiles(t *testing.T) {
+	response := &Response{
+		Content: []Content{
+			&TextContent{Text: "Done"},
+			&FileContent{Filename: "chart.png", Source: &ContentSource{Type: ContentSourceTypeURL, URL: "https://example.com/chart.png"}},
+			&BashCodeExecutionToolResultContent{
+				Content: BashCodeExecutionResult{
+					Type:    "bash_code_execution_result",
+					Content: []BashCodeExecutionFile{{FileID: "file_1"}},
+				},
+			},
+		},
+	}
+	files := response.Files()
+	assert.Len(t, files, 2)
+	assert.Equal(t, "chart.png", files[0].Filename)
+	assert.Equal(t, "file_1", files[1].Source.FileID)
+	assert.Len(t, (&Response{}).Files(), 0)
+}
+
 func TestCloneContent(t *testing.T) {
 	cc := &CacheControl{Type: CacheControlTypeEphemeral}
 
@@ -41,3 +41,22 @@ func PopulateCost(model string, fast bool, u *Usage) {
 	cost := pricing.CostOf(u)
 	u.Cost = &cost
 }
+
+// CacheSavings estimates how much less the usage cost because of prompt cache
+// reads: the cache-read tokens priced at the model's input rate minus their
+// actual cache-read cost. ok is false when no pricing is known for the model.
+func CacheSavings(model string, u *Usage) (float64, bool) {
+	if u == nil {
+		return 0, false
+	}
+	rp := costResolver.Load()
+	if rp == nil {
+		return 0, false
+	}
+	pricing, ok := (*rp)(model, u.Speed == string(SpeedFast))
+	if !ok {
+		return 0, false
+	}
+	const perMillion = 1_000_000.0
+	return float64(u.CacheReadInputTokens) * (pricing.InputPrice - pricing.CacheReadPrice) / perMillion, true
+}
@@ -115,6 +115,25 @@ func TestPopulateCost_NoResolver(t *testing.T) {
 	assert.Nil(t, u.Cost, "without a resolver, cost must remain unknown (nil)")
 }
 
+func TestCacheSavings(t *testing.T) {
+	t.Cleanup(func() { SetCostResolver(nil) })
+	SetCostResolver(func(model string, fast bool) (PricingInfo, bool) {
+		if model != "known" {
+			return PricingInfo{}, false
+		}
+		return PricingInfo{Model: model, InputPrice: 5.0, CacheReadPrice: 0.5, Currency: "USD"}, true
+	})
+
+	savings, ok := CacheSavings("known", &Usage{InputTokens: 100, CacheReadInputTokens: 1_000_000})
+	assert.True(t, ok)
+	assert.Equal(t, 4.5, savings)
+
+	_, ok = CacheSavings("unknown", &Usage{CacheReadInputTokens: 1_000_000})
+	assert.False(t, ok)
+	_, ok = CacheSavings("known", nil)
+	assert.False(t, ok)
+}
+
 func TestResponseAccumulatorPopulatesCost(t *testing.T) {
 	t.Cleanup(func() { SetCostResolver(nil) })
 	SetCostResolver(func(model string, fast bool) (PricingInfo, bool) {
@@ -9,6 +9,10 @@
 //     message components.
 //   - [Option] functions configure LLM requests (model, temperature, tools, etc.).
 //   - [Tool] describes a callable tool at the LLM level.
+//   - [CountTokens] counts a request's input tokens before it is sent.
+//   - [WithMiddleware] wraps an [LLM] to edit each [Request] before it is sent.
+//   - [WithRetry] wraps an [LLM] to retry failed requests with backoff.
+//   - [WithRateLimit] wraps an [LLM] to wait on a shared [RateLimiter].
 //
 // Most users interact with this package indirectly through [github.com/deepnoodle-ai/dive.Agent].
 // Direct usage is needed when building custom providers or working with the LLM
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RepairJSON makes a best-effort attempt to complete truncated JSON, such as
+// tool call arguments cut off by the output token limit. It closes an open
+// string, drops a dangling escape, comma, or partial number, completes a
+// partial true/false/null literal, fills a key that has no value with null,
+// and closes open objects and arrays. It returns the repaired JSON and true
+// if the result is valid, or nil and false if the input can't be salvaged.
+//
+// Valid input is returned unchanged. Repaired JSON is syntactically valid
+// but may be missing whatever the model meant to write after the cut, so
+// callers should treat it with suspicion.
+func RepairJSON(data []byte) ([]byte, bool) {
+	if json.Valid(data) {
+		return data, true
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, false
+	}
+
+	var stack []byte
+	inString, escaped := false, false
+	escapeStart := -1 // index of the backslash starting the current escape
+	for i, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+				if b != 'u' {
+					escapeStart = -1
+				}
+			case b == '\\':
+				escaped = true
+				escapeStart = i
+			case b == '"':
+				inString = false
+				escapeStart = -1
+			case escapeStart >= 0 && i-escapeStart > 5:
+				escapeStart = -1
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, b)
+		case '}', ']':
+			if len(stack) == 0 || (b == '}') != (stack[len(stack)-1] == '{') {
+				return nil, false
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	out := string(data)
+	if inString {
+		// Drop an escape sequence that was cut off, e.g. `\` or `\u00`.
+		if escapeStart >= 0 && (escaped || len(out)-escapeStart < 6) {
+			out = out[:escapeStart]
+		}
+		out += `"`
+	}
+	out = strings.TrimRight(out, " \t\r\n")
+	out = completeLiteral(out)
+	out = trimPartialNumber(strings.TrimRight(out, " \t\r\n"))
+	out = strings.TrimRight(out, ", \t\r\n")
+	if strings.HasSuffix(out, ":") {
+		out += "null"
+	}
+
+	var closers strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			closers.WriteByte('}')
+		} else {
+			closers.WriteByte(']')
+		}
+	}
+	for _, candidate := range []string{
+		out + closers.String(),
+		// An object key with no colon yet, e.g. `{"a": 1, "b"`.
+		out + ":null" + closers.String(),
+	} {
+		if json.Valid([]byte(candidate)) {
+			return []byte(candidate), true
+		}
+	}
+	return nil, false
+}
+
+// completeLiteral completes a trailing partial true, false, or null.
+func completeLiteral(s string) string {
+	end := len(s)
+	start := end
+	for start > 0 && s[start-1] >= 'a' && s[start-1] <= 'z' {
+		start--
+	}
+	if start == end {
+		return s
+	}
+	partial := s[start:]
+	for _, literal := range []string{"true", "false", "null"} {
+		if strings.HasPrefix(literal, partial) {
+			return s[:start] + literal
+		}
+	}
+	return s
+}
+
+// trimPartialNumber drops the incomplete tail of a trailing number, such as
+// the "." in "1." or the "e-" in "1e-".
+func trimPartialNumber(s string) string {
+	start := len(s)
+	for start > 0 && strings.IndexByte("0123456789.eE+-", s[start-1]) >= 0 {
+		start--
+	}
+	if start == len(s) || (start > 0 && s[start-1] >= 'a' && s[start-1] <= 'z') {
+		return s
+	}
+	return strings.TrimRight(s, ".eE+-")
+}
@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{`{"path": "main.go", "content": "package ma`, `{"path": "main.go", "content": "package ma"}`},
+		{`{"items": [1, 2, `, `{"items": [1, 2]}`},
+		{`{"a": {"b": [{"c": "d"`, `{"a": {"b": [{"c": "d"}]}}`},
+		{`{"a": `, `{"a":null}`},
+		{`{"a": 1, "b"`, `{"a": 1, "b":null}`},
+		{`{"a": tr`, `{"a": true}`},
+		{`{"a": fals`, `{"a": false}`},
+		{`{"a": true`, `{"a": true}`},
+		{`{"a": 1.`, `{"a": 1}`},
+		{`{"a": 1e-`, `{"a": 1}`},
+		{`{"a": -`, `{"a":null}`},
+		{`{"a": "x\`, `{"a": "x"}`},
+		{`{"a": "x\u00`, `{"a": "x"}`},
+		{`{"a": "xé`, `{"a": "xé"}`},
+		{`{"a": "brace } in string`, `{"a": "brace } in string"}`},
+	}
+	for _, tt := range tests {
+		got, ok := RepairJSON([]byte(tt.input))
+		assert.True(t, ok, tt.input)
+		assert.Equal(t, tt.want, string(got), tt.input)
+	}
+
+	for _, input := range []string{``, `   `, `{"a": 1]`, `{"a": xyz`} {
+		_, ok := RepairJSON([]byte(input))
+		assert.False(t, ok, input)
+	}
+}
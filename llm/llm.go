@@ -2,8 +2,16 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"slices"
 )
 
+// ErrNotSupported is returned by providers asked for an option their API
+// doesn't offer, such as disabling parallel tool calls, so callers know the
+// option wasn't applied rather than silently ignored. Match it with
+// errors.Is.
+var ErrNotSupported = errors.New("not supported by this provider")
+
 // LLM is the core interface for interacting with a language model provider.
 type LLM interface {
 	// Name of the LLM provider
@@ -40,3 +48,122 @@ type StreamIterator interface {
 	// Close closes the stream and releases any associated resources.
 	Close() error
 }
+
+// ToolLimits describes limits a provider places on the tool definitions sent
+// with a request. A zero field means the provider has no known limit.
+type ToolLimits struct {
+	// MaxTools is the maximum number of tool definitions per request.
+	MaxTools int
+
+	// MaxNameLength is the maximum length of a tool name.
+	MaxNameLength int
+
+	// MaxDescriptionLength is the maximum length of a tool description.
+	MaxDescriptionLength int
+}
+
+// ToolLimiter is an optional interface implemented by providers that enforce
+// limits on tool definitions. Agents use it to detect oversized tool sets
+// before sending a request that would be rejected.
+type ToolLimiter interface {
+	ToolLimits() ToolLimits
+}
+
+// Modality is a kind of content a model accepts or produces.
+type Modality string
+
+const (
+	ModalityText  Modality = "text"
+	ModalityImage Modality = "image"
+	ModalityAudio Modality = "audio"
+	ModalityVideo Modality = "video"
+	ModalityPDF   Modality = "pdf"
+)
+
+// ModelInfo describes a model's limits and what it can do. A zero limit
+// means it is unknown. Providers report limits through ModelInfoProvider;
+// providers.Capabilities reports the full entry for known models.
+type ModelInfo struct {
+	// Model is the model name the info describes.
+	Model string `json:"model"`
+
+	// Provider is the name of the provider that serves the model.
+	Provider string `json:"provider,omitempty"`
+
+	// ContextWindow is the maximum number of tokens of input and output
+	// combined in a single request.
+	ContextWindow int `json:"context_window,omitempty"`
+
+	// MaxOutputTokens is the maximum number of tokens in a response.
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+
+	// MaxRequestBytes is the largest request body the provider's API
+	// accepts.
+	MaxRequestBytes int `json:"max_request_bytes,omitempty"`
+
+	// MaxImages is the maximum number of images in a single request.
+	MaxImages int `json:"max_images,omitempty"`
+
+	// MaxImageBytes is the largest image the provider accepts.
+	MaxImageBytes int `json:"max_image_bytes,omitempty"`
+
+	// InputModalities and OutputModalities list the kinds of content the
+	// model accepts and produces.
+	InputModalities  []Modality `json:"input_modalities,omitempty"`
+	OutputModalities []Modality `json:"output_modalities,omitempty"`
+
+	// Tools reports whether the model can call function tools.
+	Tools bool `json:"tools,omitempty"`
+
+	// JSONSchema reports whether the provider can constrain the model's
+	// response to a JSON schema, natively or by emulation.
+	JSONSchema bool `json:"json_schema,omitempty"`
+
+	// Reasoning reports whether the model supports extended thinking or
+	// reasoning effort settings.
+	Reasoning bool `json:"reasoning,omitempty"`
+
+	// PromptCaching reports whether the model supports prompt caching.
+	PromptCaching bool `json:"prompt_caching,omitempty"`
+
+	// UpdatedAt is the date the information was last checked.
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// SupportsInput reports whether the model accepts the modality as input.
+func (m ModelInfo) SupportsInput(modality Modality) bool {
+	return slices.Contains(m.InputModalities, modality)
+}
+
+// SupportsOutput reports whether the model can produce the modality.
+func (m ModelInfo) SupportsOutput(modality Modality) bool {
+	return slices.Contains(m.OutputModalities, modality)
+}
+
+// ModelInfoProvider is an optional interface implemented by providers that
+// know their models' limits. Agents use it to budget the context window,
+// for example to reserve room for the model's reply.
+type ModelInfoProvider interface {
+	// ModelInfo returns the info for model, or for the provider's
+	// configured model when model is empty.
+	ModelInfo(model string) ModelInfo
+}
+
+// ResponseFormatter is an optional interface implemented by providers that
+// honor WithResponseFormat, natively or by emulation. Helpers that need
+// structured output use it to decide between a response format and a
+// forced tool call.
+type ResponseFormatter interface {
+	// SupportsResponseFormat reports whether the provider honors format.
+	SupportsResponseFormat(format *ResponseFormat) bool
+}
+
+// ResponseContinuer is an optional interface implemented by providers that
+// can store the responses they generate, like the OpenAI Responses API.
+// Agents use it to continue a stored response with WithPreviousResponseID
+// instead of resending the conversation.
+type ResponseContinuer interface {
+	// ContinuesResponses reports whether responses generated with config
+	// are stored, so a later request can reference them by ID.
+	ContinuesResponses(config *Config) bool
+}
@@ -60,6 +60,41 @@ func (m *Message) Text() string {
 	return sb.String()
 }
 
+// Citations returns the citations attached to the message's text content,
+// normalized with NormalizeCitation. Spans are mapped to byte offsets
+// within Text. A citation whose provider gave no offsets covers the whole
+// text block it is attached to.
+func (m *Message) Citations() []*CitationContent {
+	var citations []*CitationContent
+	var offset, textCount int
+	for _, content := range m.Content {
+		text, ok := content.(*TextContent)
+		if !ok {
+			continue
+		}
+		if textCount > 0 {
+			offset += len("\n\n")
+		}
+		textCount++
+		for _, c := range text.Citations {
+			citation := NormalizeCitation(c)
+			if citation == nil {
+				continue
+			}
+			if citation.HasSpan() && citation.EndIndex <= len(text.Text) {
+				citation.StartIndex += offset
+				citation.EndIndex += offset
+			} else {
+				citation.StartIndex = offset
+				citation.EndIndex = offset + len(text.Text)
+			}
+			citations = append(citations, citation)
+		}
+		offset += len(text.Text)
+	}
+	return citations
+}
+
 // WithText appends text content block(s) to the message.
 func (m *Message) WithText(text ...string) *Message {
 	for _, t := range text {
@@ -3,6 +3,8 @@ package llm
 import (
 	"encoding/base64"
 	"encoding/json"
+	"slices"
+	"strings"
 )
 
 // NewMessage creates a message with the given role and content blocks.
@@ -31,6 +33,17 @@ func NewSystemMessage(text string) *Message {
 	}
 }
 
+// NewDeveloperMessage creates a developer message with a single text
+// content block. Developer messages carry application instructions apart
+// from the system prompt; see FoldDeveloperMessages for providers without
+// the role.
+func NewDeveloperMessage(text string) *Message {
+	return &Message{
+		Role:    Developer,
+		Content: []Content{&TextContent{Text: text}},
+	}
+}
+
 // NewTextContent creates a text content block with the given text.
 func NewTextContent(text string) *TextContent {
 	return &TextContent{Text: text}
@@ -129,3 +142,50 @@ func FileID(id string) *ContentSource {
 		FileID: id,
 	}
 }
+
+// FoldDeveloperMessages prepares messages for a provider without a
+// developer role. Developer messages at the start of the conversation are
+// removed and their text returned as instructions, joined by blank lines,
+// for the provider to send after its system prompt. Later developer
+// messages, and leading ones with non-text content, keep their position as
+// user messages, so the conversation order and any cached prefix are
+// unchanged. The caller's messages are not modified.
+func FoldDeveloperMessages(messages []*Message) (string, []*Message) {
+	if !slices.ContainsFunc(messages, func(m *Message) bool { return m != nil && m.Role == Developer }) {
+		return "", messages
+	}
+	var instructions []string
+	leading := true
+	result := make([]*Message, 0, len(messages))
+	for _, message := range messages {
+		if message == nil || message.Role != Developer {
+			leading = false
+			result = append(result, message)
+			continue
+		}
+		if leading {
+			if text, ok := messageTextOnly(message); ok {
+				if text != "" {
+					instructions = append(instructions, text)
+				}
+				continue
+			}
+			leading = false
+		}
+		result = append(result, &Message{ID: message.ID, Role: User, Content: message.Content})
+	}
+	return strings.Join(instructions, "\n\n"), result
+}
+
+// messageTextOnly returns the text of a message whose content is all text.
+func messageTextOnly(message *Message) (string, bool) {
+	var texts []string
+	for _, content := range message.Content {
+		text, ok := content.(*TextContent)
+		if !ok {
+			return "", false
+		}
+		texts = append(texts, text.Text)
+	}
+	return strings.Join(texts, "\n\n"), true
+}
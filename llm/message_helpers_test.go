@@ -51,3 +51,35 @@ func TestNewUserDocumentFileIDMessage(t *testing.T) {
 	assert.Equal(t, ContentSourceTypeFile, docContent.Source.Type)
 	assert.Equal(t, fileID, docContent.Source.FileID)
 }
+
+func TestFoldDeveloperMessages(t *testing.T) {
+	user := NewUserTextMessage("Hello")
+	image := &ImageContent{Source: &ContentSource{Type: ContentSourceTypeURL, URL: "https://example.com/a.png"}}
+	messages := []*Message{
+		NewDeveloperMessage("Answer in French."),
+		{Role: Developer, Content: []Content{NewTextContent("Be brief."), NewTextContent("No emoji.")}},
+		user,
+		NewDeveloperMessage("Mention the weather."),
+	}
+
+	instructions, folded := FoldDeveloperMessages(messages)
+	assert.Equal(t, "Answer in French.\n\nBe brief.\n\nNo emoji.", instructions)
+	assert.Len(t, folded, 2)
+	assert.Equal(t, user, folded[0])
+	assert.Equal(t, User, folded[1].Role)
+	assert.Equal(t, "Mention the weather.", folded[1].Text())
+	// The caller's messages are unchanged
+	assert.Equal(t, Developer, messages[3].Role)
+
+	// A developer message with non-text content keeps its place
+	instructions, folded = FoldDeveloperMessages([]*Message{{Role: Developer, Content: []Content{image}}, user})
+	assert.Equal(t, "", instructions)
+	assert.Len(t, folded, 2)
+	assert.Equal(t, User, folded[0].Role)
+
+	// Without developer messages, the input is returned as is
+	plain := []*Message{user}
+	instructions, folded = FoldDeveloperMessages(plain)
+	assert.Equal(t, "", instructions)
+	assert.Equal(t, plain, folded)
+}
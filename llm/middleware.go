@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// Request is the fully assembled request seen by Middleware. It embeds the
+// Config built from the caller's options, so middleware can read and edit
+// the model, system prompt, messages, tools, and every other option in
+// place.
+//
+// The slices and maps in the Config are copies, so middleware may append,
+// remove, or replace messages, tools, and headers without affecting the
+// caller. The messages themselves are shared: replace a message rather than
+// modifying its content, since the caller, such as an agent session, may
+// still hold it. Edits must keep tool use and tool result pairs intact:
+// every tool result must follow the assistant message containing its tool
+// use, or the provider rejects the request.
+type Request struct {
+	Config
+
+	// Stream is true when the request is made with Stream rather than
+	// Generate.
+	Stream bool
+}
+
+// Middleware inspects and modifies a request before it is sent to the
+// provider, for example to try a different system prompt or to tag the
+// request with an experiment header. Returning an error fails the request
+// without sending it.
+//
+// Middleware runs below the agent, on every model call, while agent hooks
+// run at fixed points of an agent's generation loop.
+type Middleware func(ctx context.Context, req *Request) error
+
+// WithMiddleware returns an LLM that runs middleware, in order, on each
+// request before passing it to model. The returned LLM streams if model
+// does, and forwards ModelInfoProvider, ToolLimiter, and ResponseContinuer
+// to model, reporting no limits and no stored responses when model doesn't
+// implement them. It implements TokenCounter with CountTokens on model,
+// without running the middleware.
+func WithMiddleware(model LLM, middleware ...Middleware) LLM {
+	m := &middlewareLLM{llm: model, middleware: middleware}
+	if _, ok := model.(StreamingLLM); ok {
+		return &streamingMiddlewareLLM{m}
+	}
+	return m
+}
+
+type middlewareLLM struct {
+	llm        LLM
+	middleware []Middleware
+}
+
+func (m *middlewareLLM) Name() string {
+	return m.llm.Name()
+}
+
+func (m *middlewareLLM) Generate(ctx context.Context, opts ...Option) (*Response, error) {
+	config, err := m.apply(ctx, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	return m.llm.Generate(ctx, config)
+}
+
+// apply runs the middleware on the request built from opts and returns an
+// option that sets the resulting config.
+func (m *middlewareLLM) apply(ctx context.Context, opts []Option, stream bool) (Option, error) {
+	req := &Request{Stream: stream}
+	req.Apply(opts...)
+	req.Messages = slices.Clone(req.Messages)
+	req.Tools = slices.Clone(req.Tools)
+	req.Features = slices.Clone(req.Features)
+	req.MCPServers = slices.Clone(req.MCPServers)
+	req.Hooks = slices.Clone(req.Hooks)
+	req.RequestHeaders = req.RequestHeaders.Clone()
+	req.ProviderOptions = maps.Clone(req.ProviderOptions)
+	for _, mw := range m.middleware {
+		if err := mw(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	config := req.Config
+	return func(c *Config) { *c = config }, nil
+}
+
+func (m *middlewareLLM) ModelInfo(model string) ModelInfo {
+	if provider, ok := m.llm.(ModelInfoProvider); ok {
+		return provider.ModelInfo(model)
+	}
+	return ModelInfo{}
+}
+
+func (m *middlewareLLM) ToolLimits() ToolLimits {
+	if limiter, ok := m.llm.(ToolLimiter); ok {
+		return limiter.ToolLimits()
+	}
+	return ToolLimits{}
+}
+
+func (m *middlewareLLM) ContinuesResponses(config *Config) bool {
+	if continuer, ok := m.llm.(ResponseContinuer); ok {
+		return continuer.ContinuesResponses(config)
+	}
+	return false
+}
+
+func (m *middlewareLLM) CountTokens(ctx context.Context, model string, messages []*Message, tools []Tool) (int, error) {
+	return CountTokens(ctx, m.llm, model, messages, tools)
+}
+
+type streamingMiddlewareLLM struct {
+	*middlewareLLM
+}
+
+func (m *streamingMiddlewareLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	config, err := m.apply(ctx, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	return m.llm.(StreamingLLM).Stream(ctx, config)
+}
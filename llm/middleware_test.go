@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// recordingLLM records the config of each request it receives.
+type recordingLLM struct {
+	configs []*Config
+}
+
+func (r *recordingLLM) Name() string { return "recording" }
+
+func (r *recordingLLM) Generate(ctx context.Context, opts ...Option) (*Response, error) {
+	config := &Config{}
+	config.Apply(opts...)
+	r.configs = append(r.configs, config)
+	return &Response{Role: Assistant}, nil
+}
+
+type recordingStreamLLM struct {
+	recordingLLM
+}
+
+func (r *recordingStreamLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	_, err := r.Generate(ctx, opts...)
+	return nil, err
+}
+
+func (r *recordingStreamLLM) ModelInfo(model string) ModelInfo {
+	return ModelInfo{Model: "recorded", ContextWindow: 1000}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	inner := &recordingLLM{}
+	headers := http.Header{"X-Team": []string{"search"}}
+	history := []*Message{NewUserTextMessage("hello")}
+
+	var order []string
+	model := WithMiddleware(inner,
+		func(ctx context.Context, req *Request) error {
+			order = append(order, "first")
+			assert.False(t, req.Stream)
+			assert.Equal(t, "base-model", req.Model)
+			assert.Len(t, req.Tools, 1)
+			req.SystemPrompt = "variant B"
+			req.Tools = nil
+			req.RequestHeaders.Set("X-Experiment", "prompt-b")
+			return nil
+		},
+		func(ctx context.Context, req *Request) error {
+			order = append(order, "second")
+			assert.Equal(t, "variant B", req.SystemPrompt)
+			req.Model = "experiment-model"
+			req.Messages = append([]*Message{NewDeveloperMessage("be brief")}, req.Messages...)
+			return nil
+		},
+	)
+	_, isStreaming := model.(StreamingLLM)
+	assert.False(t, isStreaming)
+	assert.Equal(t, "recording", model.Name())
+
+	_, err := model.Generate(context.Background(),
+		WithModel("base-model"),
+		WithSystemPrompt("variant A"),
+		WithTools(NewToolDefinition().WithName("search")),
+		WithRequestHeaders(headers),
+		WithMessages(history...),
+		WithTemperature(0.2),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, order)
+
+	config := inner.configs[0]
+	assert.Equal(t, "experiment-model", config.Model)
+	assert.Equal(t, "variant B", config.SystemPrompt)
+	assert.Len(t, config.Tools, 0)
+	assert.Equal(t, "prompt-b", config.RequestHeaders.Get("X-Experiment"))
+	assert.Equal(t, 0.2, *config.Temperature)
+	assert.Len(t, config.Messages, 2)
+	assert.Equal(t, Developer, config.Messages[0].Role)
+
+	// The caller's slices and maps are unchanged
+	assert.Len(t, history, 1)
+	assert.Equal(t, "", headers.Get("X-Experiment"))
+}
+
+func TestWithMiddlewareError(t *testing.T) {
+	inner := &recordingStreamLLM{}
+	blocked := errors.New("blocked")
+	model := WithMiddleware(inner, func(ctx context.Context, req *Request) error {
+		assert.True(t, req.Stream)
+		return blocked
+	})
+	streaming, ok := model.(StreamingLLM)
+	assert.True(t, ok)
+
+	_, err := streaming.Stream(context.Background(), WithUserTextMessage("hi"))
+	assert.ErrorIs(t, err, blocked)
+	assert.Len(t, inner.configs, 0)
+
+	info := model.(ModelInfoProvider).ModelInfo("")
+	assert.Equal(t, 1000, info.ContextWindow)
+	assert.Equal(t, ToolLimits{}, model.(ToolLimiter).ToolLimits())
+}
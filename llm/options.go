@@ -11,39 +11,42 @@ type Option func(*Config)
 // Config is used to configure LLM calls. Not all providers support all options.
 // If a provider doesn't support a given option, it will be ignored.
 type Config struct {
-	Model              string                   `json:"model,omitempty"`
-	SystemPrompt       string                   `json:"system_prompt,omitempty"`
-	Endpoint           string                   `json:"endpoint,omitempty"`
-	APIKey             string                   `json:"api_key,omitempty"`
-	Prefill            string                   `json:"prefill,omitempty"`
-	PrefillClosingTag  string                   `json:"prefill_closing_tag,omitempty"`
-	MaxTokens          *int                     `json:"max_tokens,omitempty"`
-	Temperature        *float64                 `json:"temperature,omitempty"`
-	PresencePenalty    *float64                 `json:"presence_penalty,omitempty"`
-	FrequencyPenalty   *float64                 `json:"frequency_penalty,omitempty"`
-	ReasoningBudget    *int                     `json:"reasoning_budget,omitempty"`
-	ReasoningEffort    ReasoningEffort          `json:"reasoning_effort,omitempty"`
-	ReasoningSummary   ReasoningSummary         `json:"reasoning_summary,omitempty"`
-	Thinking           ThinkingType             `json:"thinking,omitempty"`
-	ThinkingDisplay    ThinkingDisplay          `json:"thinking_display,omitempty"`
-	Speed              Speed                    `json:"speed,omitempty"`
-	ContextManagement  *ContextManagementConfig `json:"context_management,omitempty"`
-	Tools              []Tool                   `json:"tools,omitempty"`
-	ToolChoice         *ToolChoice              `json:"tool_choice,omitempty"`
-	ParallelToolCalls  *bool                    `json:"parallel_tool_calls,omitempty"`
-	Features           []string                 `json:"features,omitempty"`
-	RequestHeaders     http.Header              `json:"request_headers,omitempty"`
-	MCPServers         []MCPServerConfig        `json:"mcp_servers,omitempty"`
-	Caching            *bool                    `json:"caching,omitempty"`
-	PreviousResponseID string                   `json:"previous_response_id,omitempty"`
-	ServiceTier        string                   `json:"service_tier,omitempty"`
-	ProviderOptions    map[string]interface{}   `json:"provider_options,omitempty"`
-	ResponseFormat     *ResponseFormat          `json:"response_format,omitempty"`
-	Messages           Messages                 `json:"messages"`
-	Hooks              Hooks                    `json:"-"`
-	Client             *http.Client             `json:"-"`
-	Logger             Logger                   `json:"-"`
-	SSECallback        ServerSentEventsCallback `json:"-"`
+	Model                 string                   `json:"model,omitempty"`
+	SystemPrompt          string                   `json:"system_prompt,omitempty"`
+	Endpoint              string                   `json:"endpoint,omitempty"`
+	APIKey                string                   `json:"api_key,omitempty"`
+	Prefill               string                   `json:"prefill,omitempty"`
+	PrefillClosingTag     string                   `json:"prefill_closing_tag,omitempty"`
+	MaxTokens             *int                     `json:"max_tokens,omitempty"`
+	Temperature           *float64                 `json:"temperature,omitempty"`
+	PresencePenalty       *float64                 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty      *float64                 `json:"frequency_penalty,omitempty"`
+	ReasoningBudget       *int                     `json:"reasoning_budget,omitempty"`
+	ReasoningEffort       ReasoningEffort          `json:"reasoning_effort,omitempty"`
+	ReasoningSummary      ReasoningSummary         `json:"reasoning_summary,omitempty"`
+	Thinking              ThinkingType             `json:"thinking,omitempty"`
+	ThinkingDisplay       ThinkingDisplay          `json:"thinking_display,omitempty"`
+	Speed                 Speed                    `json:"speed,omitempty"`
+	ContextManagement     *ContextManagementConfig `json:"context_management,omitempty"`
+	Tools                 []Tool                   `json:"tools,omitempty"`
+	ToolChoice            *ToolChoice              `json:"tool_choice,omitempty"`
+	ParallelToolCalls     *bool                    `json:"parallel_tool_calls,omitempty"`
+	Features              []string                 `json:"features,omitempty"`
+	RequestHeaders        http.Header              `json:"request_headers,omitempty"`
+	MCPServers            []MCPServerConfig        `json:"mcp_servers,omitempty"`
+	Caching               *bool                    `json:"caching,omitempty"`
+	CacheBreakpoints      []CacheBreakpoint        `json:"cache_breakpoints,omitempty"`
+	PreviousResponseID    string                   `json:"previous_response_id,omitempty"`
+	ServiceTier           string                   `json:"service_tier,omitempty"`
+	ProviderOptions       map[string]interface{}   `json:"provider_options,omitempty"`
+	StrictProviderOptions bool                     `json:"strict_provider_options,omitempty"`
+	ResponseFormat        *ResponseFormat          `json:"response_format,omitempty"`
+	Messages              Messages                 `json:"messages"`
+	BypassCache           bool                     `json:"-"`
+	Hooks                 Hooks                    `json:"-"`
+	Client                *http.Client             `json:"-"`
+	Logger                Logger                   `json:"-"`
+	SSECallback           ServerSentEventsCallback `json:"-"`
 }
 
 // Apply applies the given options to the config.
@@ -138,7 +141,12 @@ func WithToolChoice(toolChoice *ToolChoice) Option {
 	}
 }
 
-// WithParallelToolCalls sets whether to allow parallel tool calls.
+// WithParallelToolCalls sets whether the model may call several tools in
+// one response. Passing false asks the model to call tools one at a time,
+// for tools that aren't safe to run concurrently. It maps to
+// parallel_tool_calls on OpenAI-compatible APIs and disable_parallel_tool_use
+// on Anthropic. Providers without the setting fail the request with an error
+// matching ErrNotSupported when it is false.
 func WithParallelToolCalls(parallelToolCalls bool) Option {
 	return func(config *Config) {
 		config.ParallelToolCalls = &parallelToolCalls
@@ -198,6 +206,17 @@ func WithReasoningBudget(reasoningBudget int) Option {
 	}
 }
 
+// WithThinkingBudget caps the tokens the model may spend thinking before it
+// answers. It sets the same budget as WithReasoningBudget. Anthropic enables
+// extended thinking with the budget as budget_tokens, which must be at least
+// 1024 and less than the max tokens, and Gemini sets its thinking budget.
+// OpenAI reasoning models, which take an effort level instead, use
+// ReasoningEffortForBudget unless WithReasoningEffort is also set. Other
+// providers ignore it.
+func WithThinkingBudget(tokens int) Option {
+	return WithReasoningBudget(tokens)
+}
+
 // ReasoningEffort defines the effort level for reasoning aka extended thinking.
 // It controls how eagerly the model spends tokens on a response, including
 // thinking, tool calls, and text. Not all providers support all levels:
@@ -236,6 +255,22 @@ func (r ReasoningEffort) IsValid() bool {
 		r == ReasoningEffortMax
 }
 
+// ReasoningEffortForBudget maps a thinking token budget to the reasoning
+// effort with about the same depth, for providers that accept an effort
+// level but not a budget: under 4096 tokens is low, under 16384 is medium,
+// and more is high. These are the budgets Anthropic uses to emulate effort
+// on models without the effort parameter.
+func ReasoningEffortForBudget(tokens int) ReasoningEffort {
+	switch {
+	case tokens < 4096:
+		return ReasoningEffortLow
+	case tokens < 16384:
+		return ReasoningEffortMedium
+	default:
+		return ReasoningEffortHigh
+	}
+}
+
 // WithReasoningEffort sets the reasoning effort for the interaction.
 func WithReasoningEffort(reasoningEffort ReasoningEffort) Option {
 	return func(config *Config) {
@@ -376,6 +411,15 @@ func WithResponseFormat(responseFormat *ResponseFormat) Option {
 	}
 }
 
+// WithCacheBypass sends the request to the model even if an LLM returned by
+// WithCache holds a response for it, and leaves the cache unchanged. It
+// doesn't affect provider-side prompt caching; see WithCaching for that.
+func WithCacheBypass() Option {
+	return func(config *Config) {
+		config.BypassCache = true
+	}
+}
+
 // WithCaching controls provider-level caching. When true (default), providers
 // that support caching will automatically apply cache control to messages.
 // Set to false to disable automatic caching.
@@ -385,8 +429,20 @@ func WithCaching(enabled bool) Option {
 	}
 }
 
+// WithCacheBreakpoints places prompt cache breakpoints where the caller
+// chooses instead of where the provider would. Providers that support them
+// (Anthropic) mark only these boundaries as cacheable, up to the provider's
+// limit, and return an error for a breakpoint that doesn't fit the request.
+// Other providers ignore them. WithCaching(false) disables them too.
+func WithCacheBreakpoints(breakpoints ...CacheBreakpoint) Option {
+	return func(config *Config) {
+		config.CacheBreakpoints = append(config.CacheBreakpoints, breakpoints...)
+	}
+}
+
 // WithPreviousResponseID sets the previous response ID for the interaction.
-// OpenAI only.
+// OpenAI only. The previous response must have been stored. Messages up to
+// and including the assistant message with this ID are not resent.
 // https://platform.openai.com/docs/guides/conversation-state?api-mode=responses#openai-apis-for-conversation-state
 func WithPreviousResponseID(previousResponseID string) Option {
 	return func(config *Config) {
@@ -32,3 +32,19 @@ func TestWithReasoningEffort_Minimal(t *testing.T) {
 	cfg.Apply(WithReasoningEffort(ReasoningEffortMinimal))
 	assert.Equal(t, ReasoningEffortMinimal, cfg.ReasoningEffort)
 }
+
+func TestWithThinkingBudget(t *testing.T) {
+	cfg := &Config{}
+	cfg.Apply(WithThinkingBudget(8000))
+	assert.NotNil(t, cfg.ReasoningBudget)
+	assert.Equal(t, 8000, *cfg.ReasoningBudget)
+}
+
+func TestReasoningEffortForBudget(t *testing.T) {
+	assert.Equal(t, ReasoningEffortLow, ReasoningEffortForBudget(1024))
+	assert.Equal(t, ReasoningEffortLow, ReasoningEffortForBudget(4095))
+	assert.Equal(t, ReasoningEffortMedium, ReasoningEffortForBudget(4096))
+	assert.Equal(t, ReasoningEffortMedium, ReasoningEffortForBudget(16383))
+	assert.Equal(t, ReasoningEffortHigh, ReasoningEffortForBudget(16384))
+	assert.Equal(t, ReasoningEffortHigh, ReasoningEffortForBudget(64000))
+}
@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProviderOptionKey identifies a provider-specific request parameter passed
+// through WithProviderOption. Keys are namespaced by provider using the form
+// "<provider>:<param>", e.g. "anthropic:top_k". The param portion is written
+// verbatim into the provider's request body, which gives access to new API
+// parameters before Dive exposes a first-class option for them.
+//
+// Provider packages export constants for the keys they know about, such as
+// anthropic.OptionTopK.
+type ProviderOptionKey string
+
+// Provider returns the namespace portion of the key, or "" if the key is not
+// namespaced.
+func (k ProviderOptionKey) Provider() string {
+	provider, _, ok := strings.Cut(string(k), ":")
+	if !ok {
+		return ""
+	}
+	return provider
+}
+
+// Param returns the request parameter portion of the key.
+func (k ProviderOptionKey) Param() string {
+	_, param, ok := strings.Cut(string(k), ":")
+	if !ok {
+		return string(k)
+	}
+	return param
+}
+
+// UnknownProviderOptionError is returned when strict provider option
+// validation is enabled and an option addressed to the active provider is not
+// one it recognizes, or when an option key is not namespaced.
+type UnknownProviderOptionError struct {
+	Provider string
+	Key      ProviderOptionKey
+}
+
+func (e *UnknownProviderOptionError) Error() string {
+	if e.Key.Provider() == "" {
+		return fmt.Sprintf("provider option %q is not namespaced (expected \"<provider>:<param>\")", e.Key)
+	}
+	return fmt.Sprintf("unknown provider option %q for provider %q", e.Key, e.Provider)
+}
+
+// WithProviderOption sets a raw, provider-specific request parameter. The key
+// must be namespaced by provider (see ProviderOptionKey). Options addressed to
+// other providers are ignored, so the same option set can be shared across a
+// fallback chain of different providers.
+//
+// Keys the active provider does not recognize are still sent, but log a
+// warning. Use WithStrictProviderOptions to reject them instead.
+func WithProviderOption(key ProviderOptionKey, value any) Option {
+	return func(config *Config) {
+		if config.ProviderOptions == nil {
+			config.ProviderOptions = map[string]any{}
+		}
+		config.ProviderOptions[string(key)] = value
+	}
+}
+
+// WithStrictProviderOptions controls whether unrecognized provider options
+// produce an *UnknownProviderOptionError (true) or a logged warning (false,
+// the default).
+func WithStrictProviderOptions(strict bool) Option {
+	return func(config *Config) {
+		config.StrictProviderOptions = strict
+	}
+}
+
+// ResolveProviderOptions returns the provider options addressed to any of the
+// given namespaces, keyed by request parameter name. Providers typically pass
+// both their base provider name and their configured Name(), so that wrapping
+// providers (e.g. OpenRouter over Chat Completions) have their own namespace.
+//
+// Options whose parameter is not listed in known are handled according to
+// Config.StrictProviderOptions. Options for other namespaces are skipped.
+func (c *Config) ResolveProviderOptions(namespaces []string, known []ProviderOptionKey) (map[string]any, error) {
+	if len(c.ProviderOptions) == 0 {
+		return nil, nil
+	}
+	var active string
+	if len(namespaces) > 0 {
+		active = namespaces[len(namespaces)-1]
+	}
+	var params map[string]any
+	for rawKey, value := range c.ProviderOptions {
+		key := ProviderOptionKey(rawKey)
+		provider := key.Provider()
+		if provider == "" {
+			if err := c.unknownProviderOption(active, key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !slices.Contains(namespaces, provider) {
+			continue
+		}
+		if !isKnownProviderParam(known, key.Param()) {
+			if err := c.unknownProviderOption(active, key); err != nil {
+				return nil, err
+			}
+		}
+		if params == nil {
+			params = map[string]any{}
+		}
+		params[key.Param()] = value
+	}
+	return params, nil
+}
+
+func (c *Config) unknownProviderOption(provider string, key ProviderOptionKey) error {
+	err := &UnknownProviderOptionError{Provider: provider, Key: key}
+	if c.StrictProviderOptions {
+		return err
+	}
+	if c.Logger != nil {
+		c.Logger.Warn(err.Error())
+	}
+	return nil
+}
+
+func isKnownProviderParam(known []ProviderOptionKey, param string) bool {
+	for _, k := range known {
+		if k.Param() == param {
+			return true
+		}
+	}
+	return false
+}
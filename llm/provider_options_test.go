@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestProviderOptionKeyParts(t *testing.T) {
+	key := ProviderOptionKey("anthropic:top_k")
+	assert.Equal(t, "anthropic", key.Provider())
+	assert.Equal(t, "top_k", key.Param())
+
+	bare := ProviderOptionKey("seed")
+	assert.Equal(t, "", bare.Provider())
+	assert.Equal(t, "seed", bare.Param())
+}
+
+func TestResolveProviderOptions(t *testing.T) {
+	known := []ProviderOptionKey{"anthropic:top_k"}
+	cfg := &Config{}
+	cfg.Apply(
+		WithProviderOption("anthropic:top_k", 5),
+		WithProviderOption("anthropic:new_param", true),
+		WithProviderOption("openai:user", "u1"),
+	)
+
+	// Unknown keys for the active provider pass through by default; keys for
+	// other providers are skipped.
+	params, err := cfg.ResolveProviderOptions([]string{"anthropic"}, known)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"top_k": 5, "new_param": true}, params)
+
+	// Strict mode rejects them with a typed error
+	cfg.Apply(WithStrictProviderOptions(true))
+	_, err = cfg.ResolveProviderOptions([]string{"anthropic"}, known)
+	var unknownErr *UnknownProviderOptionError
+	assert.True(t, errors.As(err, &unknownErr))
+	assert.Equal(t, ProviderOptionKey("anthropic:new_param"), unknownErr.Key)
+	assert.Equal(t, "anthropic", unknownErr.Provider)
+}
+
+func TestResolveProviderOptionsRejectsUnnamespacedKeysInStrictMode(t *testing.T) {
+	cfg := &Config{}
+	cfg.Apply(WithProviderOption("seed", 42), WithStrictProviderOptions(true))
+	_, err := cfg.ResolveProviderOptions([]string{"openai"}, nil)
+	var unknownErr *UnknownProviderOptionError
+	assert.True(t, errors.As(err, &unknownErr))
+	assert.Contains(t, err.Error(), "not namespaced")
+}
+
+func TestResolveProviderOptionsEmpty(t *testing.T) {
+	params, err := (&Config{}).ResolveProviderOptions([]string{"anthropic"}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, params)
+}
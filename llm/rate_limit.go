@@ -0,0 +1,210 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitOptions configures a RateLimiter. Rates follow the token bucket
+// semantics of golang.org/x/time/rate: a bucket of Burst holds up to that
+// many requests and refills at the given rate per second.
+type RateLimitOptions struct {
+	// RequestsPerSecond is the sustained request rate. Zero means requests
+	// are not limited.
+	RequestsPerSecond float64
+
+	// Burst is how many requests may be made at once. Defaults to 1.
+	Burst int
+
+	// TokensPerSecond additionally limits the rate of output tokens, counted
+	// from each request's WithMaxTokens. Zero means tokens are not limited.
+	TokensPerSecond float64
+
+	// TokenBurst is how many tokens may be requested at once. A request
+	// for more is charged the whole bucket. Defaults to TokensPerSecond.
+	TokenBurst int
+
+	// DefaultMaxTokens is charged for requests that don't set
+	// WithMaxTokens. Zero means such requests aren't charged tokens.
+	DefaultMaxTokens int
+}
+
+// RateLimitStats reports a RateLimiter's activity.
+type RateLimitStats struct {
+	// Requests is the number of requests let through.
+	Requests int64
+
+	// Tokens is the number of tokens charged to those requests.
+	Tokens int64
+
+	// Waiting is the number of requests currently waiting.
+	Waiting int
+
+	// TotalWait is the time requests spent waiting, in total.
+	TotalWait time.Duration
+
+	// AvailableRequests and AvailableTokens are what the buckets hold now.
+	// Both are negative while waiting requests hold reservations.
+	AvailableRequests float64
+	AvailableTokens   float64
+}
+
+// RateLimiter is a token bucket limiter for LLM requests. A single
+// RateLimiter may be shared by every model that draws on the same provider
+// account, so that concurrent agents stay under the account's limits. Use
+// WithRateLimit to apply it to a model.
+type RateLimiter struct {
+	requests         *rate.Limiter
+	tokens           *rate.Limiter
+	defaultMaxTokens int
+
+	mu        sync.Mutex
+	stats     RateLimitStats
+	totalWait time.Duration
+}
+
+// NewRateLimiter returns a RateLimiter configured by opts.
+func NewRateLimiter(opts RateLimitOptions) *RateLimiter {
+	l := &RateLimiter{
+		requests:         rate.NewLimiter(rate.Inf, 0),
+		defaultMaxTokens: opts.DefaultMaxTokens,
+	}
+	if opts.RequestsPerSecond > 0 {
+		burst := opts.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		l.requests = rate.NewLimiter(rate.Limit(opts.RequestsPerSecond), burst)
+	}
+	if opts.TokensPerSecond > 0 {
+		burst := opts.TokenBurst
+		if burst <= 0 {
+			burst = max(int(opts.TokensPerSecond), 1)
+		}
+		l.tokens = rate.NewLimiter(rate.Limit(opts.TokensPerSecond), burst)
+	}
+	return l
+}
+
+// Wait blocks until a request that may generate maxTokens tokens is allowed,
+// or until ctx is done, in which case it returns ctx's error and the request
+// is not charged.
+func (l *RateLimiter) Wait(ctx context.Context, maxTokens int) error {
+	l.mu.Lock()
+	l.stats.Waiting++
+	l.mu.Unlock()
+	start := time.Now()
+
+	charged := 0
+	if l.tokens != nil && maxTokens > 0 {
+		charged = min(maxTokens, l.tokens.Burst())
+	}
+	err := l.reserve(ctx, start, charged)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Waiting--
+	l.totalWait += time.Since(start)
+	if err != nil {
+		return err
+	}
+	l.stats.Requests++
+	l.stats.Tokens += int64(charged)
+	return nil
+}
+
+// reserve takes a request and charged tokens from the buckets, waiting
+// until both are available. If ctx is done first, the reservations are
+// canceled so other requests can use them.
+func (l *RateLimiter) reserve(ctx context.Context, now time.Time, charged int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	reservations := []*rate.Reservation{l.requests.ReserveN(now, 1)}
+	if charged > 0 {
+		reservations = append(reservations, l.tokens.ReserveN(now, charged))
+	}
+	var delay time.Duration
+	for _, r := range reservations {
+		delay = max(delay, r.DelayFrom(now))
+	}
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		for _, r := range reservations {
+			r.Cancel()
+		}
+		return ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the limiter's activity.
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.mu.Lock()
+	stats := l.stats
+	stats.TotalWait = l.totalWait
+	l.mu.Unlock()
+	now := time.Now()
+	if l.requests.Limit() != rate.Inf {
+		stats.AvailableRequests = l.requests.TokensAt(now)
+	}
+	if l.tokens != nil {
+		stats.AvailableTokens = l.tokens.TokensAt(now)
+	}
+	return stats
+}
+
+// WithRateLimit returns an LLM that waits on limiter before each Generate
+// and Stream call to model. A request is charged its WithMaxTokens, or the
+// limiter's DefaultMaxTokens, against the token rate. Like WithMiddleware,
+// the returned LLM streams if model does and forwards ModelInfoProvider,
+// ToolLimiter, and ResponseContinuer.
+func WithRateLimit(model LLM, limiter *RateLimiter) LLM {
+	r := &rateLimitLLM{middlewareLLM: &middlewareLLM{llm: model}, limiter: limiter}
+	if _, ok := model.(StreamingLLM); ok {
+		return &streamingRateLimitLLM{r}
+	}
+	return r
+}
+
+type rateLimitLLM struct {
+	*middlewareLLM
+	limiter *RateLimiter
+}
+
+func (r *rateLimitLLM) Generate(ctx context.Context, opts ...Option) (*Response, error) {
+	if err := r.wait(ctx, opts); err != nil {
+		return nil, err
+	}
+	return r.llm.Generate(ctx, opts...)
+}
+
+func (r *rateLimitLLM) wait(ctx context.Context, opts []Option) error {
+	config := &Config{}
+	config.Apply(opts...)
+	maxTokens := r.limiter.defaultMaxTokens
+	if config.MaxTokens != nil {
+		maxTokens = *config.MaxTokens
+	}
+	return r.limiter.Wait(ctx, maxTokens)
+}
+
+type streamingRateLimitLLM struct {
+	*rateLimitLLM
+}
+
+func (r *streamingRateLimitLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	if err := r.wait(ctx, opts); err != nil {
+		return nil, err
+	}
+	return r.llm.(StreamingLLM).Stream(ctx, opts...)
+}
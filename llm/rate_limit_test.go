@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitOptions{RequestsPerSecond: 50, Burst: 2})
+	inner := &recordingStreamLLM{}
+	model := WithRateLimit(inner, limiter)
+	streaming, ok := model.(StreamingLLM)
+	assert.True(t, ok)
+	assert.Equal(t, 1000, model.(ModelInfoProvider).ModelInfo("").ContextWindow)
+
+	// The burst is free; the third request waits about 20ms for a refill
+	start := time.Now()
+	for range 2 {
+		_, err := model.Generate(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.True(t, time.Since(start) < 15*time.Millisecond)
+	_, err := streaming.Stream(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 15*time.Millisecond)
+	assert.Len(t, inner.configs, 3)
+
+	stats := limiter.Stats()
+	assert.Equal(t, int64(3), stats.Requests)
+	assert.Equal(t, 0, stats.Waiting)
+	assert.True(t, stats.TotalWait >= 15*time.Millisecond)
+	assert.True(t, stats.AvailableRequests < 1)
+}
+
+func TestWithRateLimitCanceled(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitOptions{RequestsPerSecond: 0.001})
+	inner := &recordingLLM{}
+	model := WithRateLimit(inner, limiter)
+	_, err := model.Generate(context.Background())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = model.Generate(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, inner.configs, 1)
+	assert.Equal(t, int64(1), limiter.Stats().Requests)
+}
+
+func TestWithRateLimitTokens(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitOptions{
+		TokensPerSecond:  10000,
+		TokenBurst:       1000,
+		DefaultMaxTokens: 100,
+	})
+	model := WithRateLimit(&recordingLLM{}, limiter)
+
+	_, err := model.Generate(context.Background(), WithMaxTokens(800))
+	assert.NoError(t, err)
+	_, err = model.Generate(context.Background())
+	assert.NoError(t, err)
+	stats := limiter.Stats()
+	assert.Equal(t, int64(900), stats.Tokens)
+	assert.True(t, stats.AvailableTokens < 500)
+
+	// The next 500 tokens must wait for the bucket to refill
+	start := time.Now()
+	_, err = model.Generate(context.Background(), WithMaxTokens(500))
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+
+	// Requests over the burst are charged the whole bucket
+	_, err = model.Generate(context.Background(), WithMaxTokens(5000))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2400), limiter.Stats().Tokens)
+}
@@ -0,0 +1,459 @@
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLogMaxBodyBytes is how much of each body and streamed line
+// WithRequestLogger logs when LogOptions.MaxBodyBytes is zero.
+const DefaultLogMaxBodyBytes = 64 * 1024
+
+// redactedValue replaces the values of redacted headers and query
+// parameters.
+const redactedValue = "REDACTED"
+
+// credentialHeaders are always redacted by WithRequestLogger.
+var credentialHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"Api-Key",
+	"X-Goog-Api-Key",
+	"X-Amz-Security-Token",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// LogOptions configures WithRequestLogger.
+type LogOptions struct {
+	// RedactHeaders names more headers to redact, beyond the credential
+	// headers that are always redacted: Authorization, X-Api-Key, Api-Key,
+	// X-Goog-Api-Key, and the like.
+	RedactHeaders []string
+
+	// RedactContent replaces message text, thinking, images, and tool inputs
+	// and results in logged bodies with a short SHA-256 hash, keeping the
+	// JSON structure, model, settings, and usage readable. Equal content
+	// hashes equally, so repeated messages can still be told apart. Bodies
+	// that aren't JSON are omitted.
+	RedactContent bool
+
+	// MaxBodyBytes caps how much of each body, and of each streamed line, is
+	// logged. Zero means DefaultLogMaxBodyBytes and a negative value means no
+	// limit.
+	MaxBodyBytes int
+
+	// Transport sends the requests. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// WithRequestLogger returns an http.RoundTripper that logs each request to
+// a provider and its response at debug level. Pass it to a provider's
+// WithTransport option; since it works on HTTP, it logs the same way for
+// every provider:
+//
+//	logger := llm.WithRequestLogger(myLogger, llm.LogOptions{RedactContent: true})
+//	model := anthropic.New(anthropic.WithTransport(logger))
+//
+// Credential headers are always redacted. Streamed responses are logged a
+// line at a time as the provider reads them, so a long stream is never held
+// in memory; other responses are logged when their body is read or closed.
+func WithRequestLogger(logger Logger, options LogOptions) http.RoundTripper {
+	if logger == nil {
+		logger = &NullLogger{}
+	}
+	transport := options.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	redact := make(map[string]bool, len(credentialHeaders)+len(options.RedactHeaders))
+	for _, names := range [][]string{credentialHeaders, options.RedactHeaders} {
+		for _, name := range names {
+			redact[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+	limit := options.MaxBodyBytes
+	if limit == 0 {
+		limit = DefaultLogMaxBodyBytes
+	}
+	return &requestLogger{
+		logger:        logger,
+		transport:     transport,
+		redactHeaders: redact,
+		redactContent: options.RedactContent,
+		limit:         limit,
+	}
+}
+
+type requestLogger struct {
+	logger        Logger
+	transport     http.RoundTripper
+	redactHeaders map[string]bool
+	redactContent bool
+	limit         int // negative for no limit
+}
+
+func (l *requestLogger) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := l.url(req)
+	args := []any{"method", req.Method, "url", url, "headers", l.headers(req.Header)}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		// Send a copy with the body restored, since a RoundTripper must not
+		// modify the caller's request
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		args = append(args, l.bodyArgs(body, false, req.Header.Get("Content-Type"))...)
+	}
+	l.logger.Debug("llm http request", args...)
+
+	started := time.Now()
+	resp, err := l.transport.RoundTrip(req)
+	if err != nil {
+		l.logger.Debug("llm http request failed", "method", req.Method, "url", url,
+			"duration", time.Since(started), "error", err)
+		return nil, err
+	}
+	l.logger.Debug("llm http response", "method", req.Method, "url", url,
+		"status", resp.StatusCode, "headers", l.headers(resp.Header),
+		"duration", time.Since(started))
+	if resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = &loggedBody{
+			ReadCloser:  resp.Body,
+			logger:      l,
+			url:         url,
+			contentType: resp.Header.Get("Content-Type"),
+			mode:        responseBodyMode(resp.Header.Get("Content-Type")),
+		}
+	}
+	return resp, nil
+}
+
+// url returns the request URL with API keys passed as query parameters
+// redacted.
+func (l *requestLogger) url(req *http.Request) string {
+	query := req.URL.Query()
+	redacted := false
+	for _, name := range []string{"key", "api_key", "apikey"} {
+		if query.Has(name) {
+			query.Set(name, redactedValue)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return req.URL.String()
+	}
+	u := *req.URL
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func (l *requestLogger) headers(header http.Header) http.Header {
+	logged := header.Clone()
+	for name := range logged {
+		if l.redactHeaders[http.CanonicalHeaderKey(name)] {
+			logged[name] = []string{redactedValue}
+		}
+	}
+	return logged
+}
+
+// bodyArgs returns the log arguments for a body. truncated reports whether
+// body is only the start of a longer body.
+func (l *requestLogger) bodyArgs(body []byte, truncated bool, contentType string) []any {
+	args := []any{"body_bytes", len(body)}
+	var logged string
+	switch {
+	case l.redactContent:
+		if truncated {
+			return args
+		}
+		value, ok := redactJSON(body)
+		if !ok {
+			return args
+		}
+		logged = value
+	case isTextContent(contentType) || json.Valid(body):
+		logged = string(body)
+	default:
+		return args
+	}
+	logged, cut := l.truncate(logged)
+	args = append(args, "body", logged)
+	if truncated || cut {
+		args = append(args, "truncated", true)
+	}
+	return args
+}
+
+func (l *requestLogger) truncate(text string) (string, bool) {
+	if l.limit < 0 || len(text) <= l.limit {
+		return text, false
+	}
+	return strings.ToValidUTF8(text[:l.limit], ""), true
+}
+
+// logLine logs one line of a streamed response.
+func (l *requestLogger) logLine(url string, line []byte, truncated bool) {
+	text := string(line)
+	if l.redactContent {
+		text = redactStreamLine(text, truncated)
+	}
+	text, cut := l.truncate(text)
+	args := []any{"url", url, "line", text}
+	if truncated || cut {
+		args = append(args, "truncated", true)
+	}
+	l.logger.Debug("llm http stream", args...)
+}
+
+// bodyMode is how a response body is logged.
+type bodyMode int
+
+const (
+	// bodyCaptured bodies are logged whole when read to the end or closed.
+	bodyCaptured bodyMode = iota
+	// bodyLines bodies are streams of lines, such as server-sent events or
+	// newline-delimited JSON, logged a line at a time.
+	bodyLines
+	// bodyBinary bodies, such as AWS event streams, are logged by size only.
+	bodyBinary
+)
+
+func responseBodyMode(contentType string) bodyMode {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "text/event-stream",
+		mediaType == "application/x-ndjson",
+		mediaType == "application/jsonl":
+		return bodyLines
+	case mediaType == "", isTextContent(contentType):
+		return bodyCaptured
+	}
+	return bodyBinary
+}
+
+func isTextContent(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/x-www-form-urlencoded"
+}
+
+// loggedBody logs a response body as the provider reads it. Captured
+// bodies are held up to the size limit; streamed bodies hold at most one
+// line.
+type loggedBody struct {
+	io.ReadCloser
+	logger      *requestLogger
+	url         string
+	contentType string
+	mode        bodyMode
+
+	mutex     sync.Mutex
+	buf       []byte
+	truncated bool
+	size      int
+	finished  bool
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.observe(p[:n])
+	if err != nil {
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.finish(nil)
+	return err
+}
+
+func (b *loggedBody) observe(data []byte) {
+	if b.finished {
+		return
+	}
+	b.size += len(data)
+	switch b.mode {
+	case bodyCaptured:
+		b.buf, b.truncated = b.keep(b.buf, data, b.truncated)
+	case bodyLines:
+		for len(data) > 0 {
+			line, rest, found := bytes.Cut(data, []byte("\n"))
+			b.buf, b.truncated = b.keep(b.buf, line, b.truncated)
+			if !found {
+				break
+			}
+			b.flushLine()
+			data = rest
+		}
+	}
+}
+
+// keep appends data to buf up to the size limit, reporting whether any of
+// it was dropped.
+func (b *loggedBody) keep(buf, data []byte, truncated bool) ([]byte, bool) {
+	limit := b.logger.limit
+	if limit >= 0 && len(buf)+len(data) > limit {
+		return append(buf, data[:max(limit-len(buf), 0)]...), true
+	}
+	return append(buf, data...), truncated
+}
+
+func (b *loggedBody) flushLine() {
+	line := bytes.TrimRight(b.buf, "\r")
+	if len(line) > 0 {
+		b.logger.logLine(b.url, line, b.truncated)
+	}
+	b.buf, b.truncated = b.buf[:0], false
+}
+
+// finish logs what remains of the body once it is read to the end, fails,
+// or is closed.
+func (b *loggedBody) finish(err error) {
+	if b.finished {
+		return
+	}
+	b.finished = true
+	var args []any
+	switch b.mode {
+	case bodyCaptured:
+		args = b.logger.bodyArgs(b.buf, b.truncated, b.contentType)
+		args[1] = b.size
+	case bodyLines:
+		b.flushLine()
+		args = []any{"body_bytes", b.size}
+	case bodyBinary:
+		args = []any{"body_bytes", b.size}
+	}
+	b.buf = nil
+	args = append([]any{"url", b.url}, args...)
+	if err != nil && !errors.Is(err, io.EOF) {
+		args = append(args, "error", err)
+	}
+	b.logger.logger.Debug("llm http response body", args...)
+}
+
+// redactStreamLine redacts the content of one line of a stream: the JSON
+// payload of a server-sent event's data field, or a line of
+// newline-delimited JSON. Data that isn't JSON is hashed whole.
+func redactStreamLine(line string, truncated bool) string {
+	prefix, payload := "", line
+	if field, value, ok := strings.Cut(line, ":"); ok && !strings.HasPrefix(line, "{") {
+		if field != "data" {
+			return line
+		}
+		prefix, payload = field+": ", strings.TrimPrefix(value, " ")
+	}
+	if payload == "" || payload == "[DONE]" {
+		return line
+	}
+	if !truncated {
+		if redacted, ok := redactJSON([]byte(payload)); ok {
+			return prefix + redacted
+		}
+	}
+	return prefix + hashContent(payload)
+}
+
+// contentKeys are the JSON fields whose string values carry conversation
+// content across provider APIs. Objects and arrays under them are searched
+// for more content rather than hashed whole, so block types stay visible.
+var contentKeys = map[string]bool{
+	"text":          true,
+	"content":       true,
+	"thinking":      true,
+	"data":          true,
+	"prompt":        true,
+	"system":        true,
+	"system_prompt": true,
+	"instructions":  true,
+	"delta":         true,
+	"partial_json":  true,
+	"output":        true,
+	"refusal":       true,
+	"image_url":     true,
+	"file_data":     true,
+	"url":           true,
+	"result":        true,
+}
+
+// argumentKeys are the JSON fields that hold tool inputs and results, which
+// are hashed whole when they are objects.
+var argumentKeys = map[string]bool{
+	"input":            true,
+	"arguments":        true,
+	"args":             true,
+	"functionResponse": true,
+}
+
+// redactJSON returns body with its content hashed, or false if body isn't
+// JSON.
+func redactJSON(body []byte) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if decoder.Decode(&value) != nil {
+		return "", false
+	}
+	redacted, err := json.Marshal(redactValue("", value))
+	if err != nil {
+		return "", false
+	}
+	return string(redacted), true
+}
+
+func redactValue(key string, value any) any {
+	switch v := value.(type) {
+	case string:
+		if contentKeys[key] || argumentKeys[key] {
+			return hashContent(v)
+		}
+		return v
+	case map[string]any:
+		if argumentKeys[key] {
+			encoded, _ := json.Marshal(v)
+			return hashContent(string(encoded))
+		}
+		for k, item := range v {
+			v[k] = redactValue(k, item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(key, item)
+		}
+		return v
+	}
+	return value
+}
+
+// hashContent returns a short hash of redacted content.
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// recordingLogger keeps the debug records it is given, with their
+// arguments as a map.
+type recordingLogger struct {
+	NullLogger
+	mutex   sync.Mutex
+	records []logRecord
+}
+
+type logRecord struct {
+	msg  string
+	args map[string]any
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) {
+	record := logRecord{msg: msg, args: map[string]any{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		record.args[args[i].(string)] = args[i+1]
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.records = append(l.records, record)
+}
+
+func (l *recordingLogger) find(msg string) []logRecord {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var found []logRecord
+	for _, record := range l.records {
+		if record.msg == msg {
+			found = append(found, record)
+		}
+	}
+	return found
+}
+
+func TestRequestLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`, string(body))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		io.WriteString(w, `{"id":"r1","content":[{"type":"text","text":"hello"}]}`)
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := &http.Client{Transport: WithRequestLogger(logger, LogOptions{
+		RedactHeaders: []string{"X-Org"},
+	})}
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/messages?key=k1",
+		strings.NewReader(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Org", "org-1")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, `{"id":"r1","content":[{"type":"text","text":"hello"}]}`, string(body))
+
+	requests := logger.find("llm http request")
+	assert.Len(t, requests, 1)
+	headers := requests[0].args["headers"].(http.Header)
+	assert.Equal(t, "REDACTED", headers.Get("Authorization"))
+	assert.Equal(t, "REDACTED", headers.Get("X-Org"))
+	assert.Equal(t, server.URL+"/v1/messages?key=REDACTED", requests[0].args["url"])
+	assert.Equal(t, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`, requests[0].args["body"])
+
+	responses := logger.find("llm http response")
+	assert.Len(t, responses, 1)
+	assert.Equal(t, http.StatusOK, responses[0].args["status"])
+	assert.Equal(t, "REDACTED", responses[0].args["headers"].(http.Header).Get("Set-Cookie"))
+
+	bodies := logger.find("llm http response body")
+	assert.Len(t, bodies, 1)
+	assert.Equal(t, string(body), bodies[0].args["body"])
+	assert.Equal(t, len(body), bodies[0].args["body_bytes"])
+}
+
+func TestRequestLoggerRedactsContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"content":[{"type":"tool_use","name":"search","input":{"q":"secret"}}],"usage":{"output_tokens":7}}`)
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := &http.Client{Transport: WithRequestLogger(logger, LogOptions{RedactContent: true})}
+	resp, err := client.Post(server.URL, "application/json",
+		strings.NewReader(`{"model":"m","system":"be nice","messages":[{"role":"user","content":[{"type":"text","text":"my ssn"}]}]}`))
+	assert.NoError(t, err)
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	requestBody := logger.find("llm http request")[0].args["body"].(string)
+	assert.Equal(t, fmt.Sprintf(`{"messages":[{"content":[{"text":%q,"type":"text"}],"role":"user"}],"model":"m","system":%q}`,
+		hashContent("my ssn"), hashContent("be nice")), requestBody)
+
+	responseBody := logger.find("llm http response body")[0].args["body"].(string)
+	assert.Equal(t, fmt.Sprintf(`{"content":[{"input":%q,"name":"search","type":"tool_use"}],"usage":{"output_tokens":7}}`,
+		hashContent(`{"q":"secret"}`)), responseBody)
+}
+
+func TestRequestLoggerStreams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		io.WriteString(w, "event: content_block_delta\ndata: {\"delta\":{\"type\":\"text_delta\",\"text\":\"Hel")
+		flusher.Flush()
+		io.WriteString(w, "lo\"}}\n\n")
+		io.WriteString(w, "data: "+strings.Repeat("x", 100)+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := &http.Client{Transport: WithRequestLogger(logger, LogOptions{RedactContent: true, MaxBodyBytes: 80})}
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	lines := logger.find("llm http stream")
+	assert.Len(t, lines, 4)
+	assert.Equal(t, "event: content_block_delta", lines[0].args["line"])
+	assert.Equal(t, fmt.Sprintf(`data: {"delta":{"text":%q,"type":"text_delta"}}`, hashContent("Hello")), lines[1].args["line"])
+	assert.Equal(t, "data: "+hashContent(strings.Repeat("x", 74)), lines[2].args["line"])
+	assert.Equal(t, true, lines[2].args["truncated"])
+	assert.Equal(t, "data: [DONE]", lines[3].args["line"])
+
+	bodies := logger.find("llm http response body")
+	assert.Len(t, bodies, 1)
+	assert.Equal(t, len(body), bodies[0].args["body_bytes"])
+	assert.Nil(t, bodies[0].args["body"])
+}
+
+func TestRequestLoggerTruncates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"text":"`+strings.Repeat("a", 100)+`"}`)
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := &http.Client{Transport: WithRequestLogger(logger, LogOptions{MaxBodyBytes: 10})}
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	body := logger.find("llm http response body")[0]
+	assert.Equal(t, `{"text":"a`, body.args["body"])
+	assert.Equal(t, true, body.args["truncated"])
+	assert.Equal(t, 111, body.args["body_bytes"])
+}
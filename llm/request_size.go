@@ -0,0 +1,258 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRequestTooLarge is matched, with errors.Is, by errors for requests that
+// exceed a model's size limits: the *RequestTooLargeError returned by
+// CheckRequestSize before anything is sent, and a provider's 413 response.
+var ErrRequestTooLarge = errors.New("request too large")
+
+// RequestTooLargeError describes a request that exceeds a model's limits.
+// Unit is "tokens" when a single message doesn't fit in the context window
+// and "bytes" when the encoded messages exceed the maximum request size.
+type RequestTooLargeError struct {
+	Model string
+
+	// MessageIndex is the index of the oversized message, or of the
+	// largest message when the request as a whole is too large.
+	MessageIndex int
+
+	Unit  string
+	Size  int
+	Limit int
+}
+
+func (e *RequestTooLargeError) Error() string {
+	if e.Unit == "bytes" {
+		return fmt.Sprintf("request too large: messages are %d bytes, over the %d-byte limit of model %q (message %d is the largest); remove attachments or send the content in separate requests",
+			e.Size, e.Limit, e.Model, e.MessageIndex)
+	}
+	return fmt.Sprintf("request too large: message %d is about %d tokens, over the %d-token limit of model %q; truncate it or split it with llm.SplitMessage",
+		e.MessageIndex, e.Size, e.Limit, e.Model)
+}
+
+// Is reports whether target is ErrRequestTooLarge.
+func (e *RequestTooLargeError) Is(target error) bool {
+	return target == ErrRequestTooLarge
+}
+
+// CheckRequestSize reports whether messages fit the limits in info, before
+// they are sent. Each message must fit in maxTokens, as estimated by
+// EstimateTokens; a zero maxTokens uses the context window. The JSON
+// encoding of all messages, which approximates the request body, must fit
+// in info.MaxRequestBytes. Unknown limits are not checked. It returns a
+// *RequestTooLargeError for the first limit exceeded. To check a
+// conversation after each turn, use a RequestSizer.
+func CheckRequestSize(info ModelInfo, messages []*Message, maxTokens int) error {
+	return NewRequestSizer(info).Check(messages, maxTokens)
+}
+
+// RequestSizer checks requests against a model's limits like
+// CheckRequestSize, remembering the size of each message it measures. A
+// conversation checked after every turn then only measures its new
+// messages. Messages must not be modified after they are checked. A
+// RequestSizer is not safe for concurrent use.
+type RequestSizer struct {
+	info  ModelInfo
+	sizes map[*Message]*messageSize
+}
+
+// messageSize is the measured size of a message; a negative size hasn't
+// been measured yet.
+type messageSize struct {
+	tokens int
+	bytes  int
+}
+
+// NewRequestSizer returns a RequestSizer for the limits in info.
+func NewRequestSizer(info ModelInfo) *RequestSizer {
+	return &RequestSizer{info: info, sizes: map[*Message]*messageSize{}}
+}
+
+// Check reports whether messages fit the limits, as CheckRequestSize does.
+func (s *RequestSizer) Check(messages []*Message, maxTokens int) error {
+	if maxTokens <= 0 {
+		maxTokens = s.info.ContextWindow
+	}
+	if maxTokens > 0 {
+		for i, msg := range messages {
+			tokens, err := s.tokens(msg)
+			if err != nil {
+				return fmt.Errorf("message %d: %w", i, err)
+			}
+			if tokens > maxTokens {
+				return &RequestTooLargeError{Model: s.info.Model, MessageIndex: i, Unit: "tokens", Size: tokens, Limit: maxTokens}
+			}
+		}
+	}
+	if s.info.MaxRequestBytes > 0 {
+		total, largest, largestSize := 0, 0, 0
+		for i, msg := range messages {
+			size, err := s.bytes(msg)
+			if err != nil {
+				return fmt.Errorf("message %d: %w", i, err)
+			}
+			total += size
+			if size > largestSize {
+				largest, largestSize = i, size
+			}
+		}
+		if total > s.info.MaxRequestBytes {
+			return &RequestTooLargeError{Model: s.info.Model, MessageIndex: largest, Unit: "bytes", Size: total, Limit: s.info.MaxRequestBytes}
+		}
+	}
+	return nil
+}
+
+func (s *RequestSizer) size(msg *Message) *messageSize {
+	size, ok := s.sizes[msg]
+	if !ok {
+		size = &messageSize{tokens: -1, bytes: -1}
+		s.sizes[msg] = size
+	}
+	return size
+}
+
+func (s *RequestSizer) tokens(msg *Message) (int, error) {
+	size := s.size(msg)
+	if size.tokens < 0 {
+		tokens, _, err := EstimateTokens(msg, s.info.Model)
+		if err != nil {
+			return 0, err
+		}
+		size.tokens = tokens
+	}
+	return size.tokens, nil
+}
+
+func (s *RequestSizer) bytes(msg *Message) (int, error) {
+	size := s.size(msg)
+	if size.bytes < 0 {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return 0, err
+		}
+		size.bytes = len(data)
+	}
+	return size.bytes, nil
+}
+
+// SplitMessage splits msg into consecutive messages with the same role that
+// each fit in maxTokens, as estimated by EstimateTokens for model. Text is
+// split at paragraph, line, or word boundaries, falling back to splitting
+// within a word only when a single word is too long. Other content blocks
+// are kept whole, in order, and a block that alone exceeds maxTokens
+// returns a *RequestTooLargeError. A message that already fits is returned
+// as the only part.
+func SplitMessage(msg *Message, maxTokens int, model string) ([]*Message, error) {
+	total, sizes, err := EstimateTokens(msg, model)
+	if err != nil {
+		return nil, err
+	}
+	if total <= maxTokens {
+		return []*Message{msg}, nil
+	}
+	overhead := total
+	for _, size := range sizes {
+		overhead -= size
+	}
+	budget := maxTokens - overhead
+	if budget <= 0 {
+		return nil, &RequestTooLargeError{Model: model, Unit: "tokens", Size: total, Limit: maxTokens}
+	}
+
+	var parts []*Message
+	var current []Content
+	used := 0
+	flush := func() {
+		if len(current) > 0 {
+			parts = append(parts, &Message{Role: msg.Role, Content: current})
+			current, used = nil, 0
+		}
+	}
+	add := func(c Content, size int) {
+		if used+size > budget {
+			flush()
+		}
+		current = append(current, c)
+		used += size
+	}
+	for i, c := range msg.Content {
+		if sizes[i] <= budget {
+			add(c, sizes[i])
+			continue
+		}
+		text, ok := c.(*TextContent)
+		if !ok {
+			return nil, &RequestTooLargeError{Model: model, Unit: "tokens", Size: sizes[i], Limit: budget}
+		}
+		var last *TextContent
+		for _, chunk := range splitText(text.Text, budget, model, textSeparators) {
+			size := EstimateTextTokens(chunk, model)
+			if last != nil && used+size <= budget {
+				// Keep pieces of the same text in one block
+				last.Text += chunk
+				used += size
+				continue
+			}
+			last = &TextContent{Text: chunk}
+			add(last, size)
+		}
+	}
+	flush()
+	return parts, nil
+}
+
+// textSeparators are the boundaries text is split at, coarsest first:
+// paragraphs, lines, then words.
+var textSeparators = []string{"\n\n", "\n", " "}
+
+// splitText splits text into chunks of at most maxTokens, preferring the
+// coarsest of seps that works. The chunks concatenate back to text.
+func splitText(text string, maxTokens int, model string, seps []string) []string {
+	if EstimateTextTokens(text, model) <= maxTokens {
+		return []string{text}
+	}
+	for i, sep := range seps {
+		if !strings.Contains(text, sep) {
+			continue
+		}
+		pieces := strings.SplitAfter(text, sep)
+		var chunks []string
+		var current strings.Builder
+		used := 0
+		for _, piece := range pieces {
+			size := EstimateTextTokens(piece, model)
+			if current.Len() > 0 && used+size > maxTokens {
+				chunks = append(chunks, current.String())
+				current.Reset()
+				used = 0
+			}
+			if size > maxTokens {
+				chunks = append(chunks, splitText(piece, maxTokens, model, seps[i+1:])...)
+				continue
+			}
+			current.WriteString(piece)
+			used += size
+		}
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+		}
+		return chunks
+	}
+	// A single word longer than the budget is cut by runes
+	runes := []rune(text)
+	n := max(1, len(runes)/(EstimateTextTokens(text, model)/maxTokens+1))
+	var chunks []string
+	for len(runes) > 0 {
+		size := min(n, len(runes))
+		chunks = append(chunks, string(runes[:size]))
+		runes = runes[size:]
+	}
+	return chunks
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestCheckRequestSize(t *testing.T) {
+	messages := []*Message{
+		NewUserTextMessage("short question"),
+		NewAssistantTextMessage(strings.Repeat("word ", 500)),
+	}
+	assert.NoError(t, CheckRequestSize(ModelInfo{Model: "gpt-5"}, messages, 0))
+	assert.NoError(t, CheckRequestSize(ModelInfo{Model: "gpt-5", ContextWindow: 10_000}, messages, 0))
+
+	err := CheckRequestSize(ModelInfo{Model: "gpt-5", ContextWindow: 10_000}, messages, 100)
+	assert.True(t, errors.Is(err, ErrRequestTooLarge))
+	var tooLarge *RequestTooLargeError
+	assert.True(t, errors.As(err, &tooLarge))
+	assert.Equal(t, 1, tooLarge.MessageIndex)
+	assert.Equal(t, "tokens", tooLarge.Unit)
+	assert.Equal(t, 100, tooLarge.Limit)
+	assert.True(t, tooLarge.Size > 100)
+	assert.Contains(t, err.Error(), "SplitMessage")
+
+	err = CheckRequestSize(ModelInfo{Model: "gpt-5", MaxRequestBytes: 1000}, messages, 0)
+	assert.True(t, errors.As(err, &tooLarge))
+	assert.Equal(t, "bytes", tooLarge.Unit)
+	assert.Equal(t, 1, tooLarge.MessageIndex)
+	assert.Equal(t, 1000, tooLarge.Limit)
+}
+
+func TestSplitMessage(t *testing.T) {
+	paragraph := strings.Repeat("word ", 40)
+	text := strings.Join([]string{paragraph, paragraph, paragraph, paragraph}, "\n\n")
+	msg := NewUserTextMessage(text)
+
+	parts, err := SplitMessage(msg, 100, "gpt-5")
+	assert.NoError(t, err)
+	assert.True(t, len(parts) > 1)
+	var joined strings.Builder
+	for _, part := range parts {
+		assert.Equal(t, User, part.Role)
+		tokens, _, err := EstimateTokens(part, "gpt-5")
+		assert.NoError(t, err)
+		assert.True(t, tokens <= 100)
+		joined.WriteString(part.Text())
+	}
+	assert.Equal(t, text, joined.String())
+
+	parts, err = SplitMessage(msg, 10_000, "gpt-5")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Message{msg}, parts)
+}
+
+func TestSplitMessageLongWord(t *testing.T) {
+	text := strings.Repeat("abcdefghij", 100)
+	parts, err := SplitMessage(NewUserTextMessage(text), 50, "gpt-5")
+	assert.NoError(t, err)
+	assert.True(t, len(parts) > 1)
+	var joined strings.Builder
+	for _, part := range parts {
+		joined.WriteString(part.Text())
+	}
+	assert.Equal(t, text, joined.String())
+}
+
+func TestSplitMessageOversizedBlock(t *testing.T) {
+	msg := &Message{Role: User, Content: []Content{
+		&ToolResultContent{ToolUseID: "call_1", Content: strings.Repeat("word ", 500)},
+	}}
+	_, err := SplitMessage(msg, 100, "gpt-5")
+	assert.True(t, errors.Is(err, ErrRequestTooLarge))
+}
+
+func TestRequestSizer(t *testing.T) {
+	info := ModelInfo{Model: "gpt-5", ContextWindow: 10_000, MaxRequestBytes: 3000}
+	sizer := NewRequestSizer(info)
+	messages := []*Message{NewUserTextMessage("short question")}
+	assert.NoError(t, sizer.Check(messages, 100))
+
+	// Later checks match CheckRequestSize as the conversation grows
+	messages = append(messages, NewAssistantTextMessage(strings.Repeat("word ", 500)))
+	assert.Equal(t, CheckRequestSize(info, messages, 100), sizer.Check(messages, 100))
+	messages = append(messages, NewUserTextMessage(strings.Repeat("more ", 500)))
+	assert.Equal(t, CheckRequestSize(info, messages, 0), sizer.Check(messages, 0))
+	assert.True(t, errors.Is(sizer.Check(messages, 0), ErrRequestTooLarge))
+	assert.Len(t, sizer.sizes, 3)
+}
@@ -69,6 +69,12 @@ func (r *Response) Message() *Message {
 	}
 }
 
+// Citations returns the citations in the response's text content, with
+// spans relative to the response text. See Message.Citations.
+func (r *Response) Citations() []*CitationContent {
+	return r.Message().Citations()
+}
+
 // ToolCalls extracts and returns all tool calls from the response.
 func (r *Response) ToolCalls() []*ToolUseContent {
 	var toolCalls []*ToolUseContent
@@ -79,12 +85,36 @@ func (r *Response) ToolCalls() []*ToolUseContent {
 				Name:     toolUse.Name,  // tool name e.g. "get_weather"
 				Input:    toolUse.Input, // tool call input JSON
 				Metadata: toolUse.Metadata.Clone(),
+				Repaired: toolUse.Repaired,
 			})
 		}
 	}
 	return toolCalls
 }
 
+// Files returns the files generated by the model in this response, such as
+// code interpreter outputs. It includes FileContent blocks and the files
+// referenced by Anthropic code execution results.
+func (r *Response) Files() []*FileContent {
+	var files []*FileContent
+	for _, content := range r.Content {
+		switch c := content.(type) {
+		case *FileContent:
+			files = append(files, c)
+		case *BashCodeExecutionToolResultContent:
+			for _, file := range c.Content.Content {
+				if file.FileID == "" {
+					continue
+				}
+				files = append(files, &FileContent{
+					Source: &ContentSource{Type: ContentSourceTypeFile, FileID: file.FileID},
+				})
+			}
+		}
+	}
+	return files
+}
+
 // UnmarshalJSON implements custom unmarshaling for Response to properly handle
 // the polymorphic Content field.
 func (r *Response) UnmarshalJSON(data []byte) error {
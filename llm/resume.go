@@ -0,0 +1,290 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrStreamNotResumable is returned by Resume when a stream can't continue
+// where it stopped. Match it with errors.Is.
+var ErrStreamNotResumable = errors.New("stream cannot be resumed")
+
+// ResumableStream is a StreamIterator that can continue after its
+// connection drops, instead of restarting the whole generation.
+type ResumableStream interface {
+	StreamIterator
+
+	// Resume reconnects a stream whose Next returned false with an error, so
+	// that Next continues with the event after the last one delivered. It
+	// returns an error matching ErrStreamNotResumable when the stream can't
+	// be continued, and the stream is left failed.
+	Resume(ctx context.Context) error
+}
+
+// NewResumableStream starts a stream from model that can be resumed after
+// its connection drops.
+//
+// When the provider's stream implements ResumableStream, such as the OpenAI
+// Responses API in background mode, Resume continues it from its cursor.
+// Otherwise Resume re-issues the request with the same options and skips the
+// text already delivered, so Next picks up mid-sentence. The regenerated
+// text must begin with the delivered text, which in practice requires
+// deterministic sampling such as a temperature of zero; when it doesn't,
+// Resume's stream fails with an error matching ErrStreamNotResumable.
+// Re-issued streams can only continue text: a response that had started a
+// tool call, thinking, or any other block can't be resumed this way.
+//
+// Usage reported by a re-issued stream's message_start is dropped, since the
+// event was already delivered, so input tokens billed again by the new
+// request aren't counted.
+func NewResumableStream(ctx context.Context, model StreamingLLM, opts ...Option) (ResumableStream, error) {
+	stream, err := model.Stream(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &resumableStream{
+		model:   model,
+		opts:    opts,
+		current: stream,
+		blocks:  map[int]*resumeBlock{},
+	}, nil
+}
+
+type resumableStream struct {
+	model   StreamingLLM
+	opts    []Option
+	current StreamIterator
+	event   *Event
+	err     error
+
+	// What has been delivered so far, replayed against a re-issued stream
+	started bool
+	blocks  map[int]*resumeBlock
+
+	// replaying is true while a re-issued stream repeats delivered output
+	replaying bool
+}
+
+// resumeBlock is a delivered content block.
+type resumeBlock struct {
+	contentType ContentType
+	text        strings.Builder
+	stopped     bool
+
+	// replayed is how much of text a re-issued stream has repeated.
+	replayed int
+}
+
+func (s *resumableStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.current.Next() {
+		event := s.current.Event()
+		if s.replaying {
+			var err error
+			event, err = s.replay(event)
+			if err != nil {
+				s.err = err
+				return false
+			}
+			if event == nil {
+				continue
+			}
+		}
+		s.record(event)
+		s.event = event
+		return true
+	}
+	s.err = s.current.Err()
+	return false
+}
+
+func (s *resumableStream) Event() *Event {
+	return s.event
+}
+
+func (s *resumableStream) Err() error {
+	return s.err
+}
+
+func (s *resumableStream) Close() error {
+	return s.current.Close()
+}
+
+func (s *resumableStream) Resume(ctx context.Context) error {
+	if s.err == nil {
+		return fmt.Errorf("%w: the stream has not failed", ErrStreamNotResumable)
+	}
+	if native, ok := s.current.(ResumableStream); ok {
+		err := native.Resume(ctx)
+		if err == nil {
+			s.err = nil
+			return nil
+		}
+		if !errors.Is(err, ErrStreamNotResumable) {
+			return err
+		}
+	}
+	for index, block := range s.blocks {
+		if block.contentType != ContentTypeText {
+			return fmt.Errorf("%w: block %d is %s, and only text can be regenerated", ErrStreamNotResumable, index, block.contentType)
+		}
+	}
+
+	stream, err := s.model.Stream(ctx, s.opts...)
+	if err != nil {
+		return err
+	}
+	s.current.Close()
+	s.current = stream
+	s.err = nil
+	s.replaying = true
+	for _, block := range s.blocks {
+		block.replayed = 0
+	}
+	return nil
+}
+
+// record notes the output of a delivered event.
+func (s *resumableStream) record(event *Event) {
+	switch event.Type {
+	case EventTypeMessageStart:
+		s.started = true
+	case EventTypeContentBlockStart:
+		if event.Index == nil || event.ContentBlock == nil {
+			return
+		}
+		block := &resumeBlock{contentType: event.ContentBlock.Type}
+		block.text.WriteString(event.ContentBlock.Text)
+		block.replayed = block.text.Len()
+		s.blocks[*event.Index] = block
+	case EventTypeContentBlockDelta:
+		if event.Index == nil || event.Delta == nil {
+			return
+		}
+		if block, ok := s.blocks[*event.Index]; ok && event.Delta.Type == EventDeltaTypeText {
+			block.text.WriteString(event.Delta.Text)
+			block.replayed = block.text.Len()
+		}
+	case EventTypeContentBlockStop:
+		if event.Index == nil {
+			return
+		}
+		if block, ok := s.blocks[*event.Index]; ok {
+			block.stopped = true
+		}
+	}
+}
+
+// replay filters an event of a re-issued stream, returning nil for events
+// that repeat delivered output and trimming delivered text from deltas. It
+// fails if the regenerated text differs from the delivered text.
+func (s *resumableStream) replay(event *Event) (*Event, error) {
+	switch event.Type {
+	case EventTypeMessageStart:
+		if s.started {
+			return nil, nil
+		}
+	case EventTypeContentBlockStart:
+		if event.Index == nil {
+			return event, nil
+		}
+		block, ok := s.blocks[*event.Index]
+		if !ok {
+			if index, pending := s.pending(); pending {
+				return nil, s.diverged(index)
+			}
+			return event, nil
+		}
+		if event.ContentBlock != nil {
+			if event.ContentBlock.Type != block.contentType {
+				return nil, s.diverged(*event.Index)
+			}
+			if _, err := block.consume(event.ContentBlock.Text); err != nil {
+				return nil, s.diverged(*event.Index)
+			}
+		}
+		return nil, nil
+	case EventTypeContentBlockDelta:
+		if event.Index == nil || event.Delta == nil || event.Delta.Type != EventDeltaTypeText {
+			return event, nil
+		}
+		block, ok := s.blocks[*event.Index]
+		if !ok {
+			return event, nil
+		}
+		rest, err := block.consume(event.Delta.Text)
+		if err != nil {
+			return nil, s.diverged(*event.Index)
+		}
+		if rest == "" {
+			return nil, nil
+		}
+		if block.stopped {
+			return nil, s.diverged(*event.Index)
+		}
+		delta := *event.Delta
+		delta.Text = rest
+		trimmed := *event
+		trimmed.Delta = &delta
+		return &trimmed, nil
+	case EventTypeContentBlockStop:
+		if event.Index == nil {
+			return event, nil
+		}
+		block, ok := s.blocks[*event.Index]
+		if !ok {
+			return event, nil
+		}
+		if block.replayed < block.text.Len() {
+			return nil, s.diverged(*event.Index)
+		}
+		if block.stopped {
+			return nil, nil
+		}
+	case EventTypePing:
+	default:
+		// The response ends only after the delivered text has been
+		// regenerated in full.
+		if index, pending := s.pending(); pending {
+			return nil, s.diverged(index)
+		}
+	}
+	return event, nil
+}
+
+// pending reports whether a delivered block has text a re-issued stream has
+// not repeated yet, and which.
+func (s *resumableStream) pending() (int, bool) {
+	for index, block := range s.blocks {
+		if block.replayed < block.text.Len() {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+func (s *resumableStream) diverged(index int) error {
+	return fmt.Errorf("%w: the regenerated text of block %d differs from the text already delivered", ErrStreamNotResumable, index)
+}
+
+// consume matches text from a re-issued stream against the delivered text
+// not yet replayed, returning the part of text beyond it.
+func (b *resumeBlock) consume(text string) (string, error) {
+	pending := b.text.String()[b.replayed:]
+	if len(text) <= len(pending) {
+		if !strings.HasPrefix(pending, text) {
+			return "", ErrStreamNotResumable
+		}
+		b.replayed += len(text)
+		return "", nil
+	}
+	if !strings.HasPrefix(text, pending) {
+		return "", ErrStreamNotResumable
+	}
+	b.replayed += len(pending)
+	return text[len(pending):], nil
+}
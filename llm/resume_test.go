@@ -0,0 +1,194 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// scriptedStreamLLM returns one stream per call, each sending its events
+// and then failing with the matching error, if any.
+type scriptedStreamLLM struct {
+	streams [][]*Event
+	errs    []error
+	calls   int
+}
+
+func (s *scriptedStreamLLM) Name() string { return "scripted" }
+
+func (s *scriptedStreamLLM) Generate(ctx context.Context, opts ...Option) (*Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *scriptedStreamLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	s.calls++
+	stream := &sliceStream{events: s.streams[s.calls-1]}
+	if s.calls <= len(s.errs) {
+		stream.err = s.errs[s.calls-1]
+	}
+	return stream, nil
+}
+
+func textStreamEvents(chunks ...string) []*Event {
+	index := 0
+	events := []*Event{
+		{Type: EventTypeMessageStart, Message: &Response{ID: "msg_1", Role: Assistant, Usage: Usage{InputTokens: 10}}},
+		{Type: EventTypeContentBlockStart, Index: &index, ContentBlock: &EventContentBlock{Type: ContentTypeText}},
+	}
+	for _, chunk := range chunks {
+		events = append(events, &Event{Type: EventTypeContentBlockDelta, Index: &index, Delta: &EventDelta{Type: EventDeltaTypeText, Text: chunk}})
+	}
+	return append(events,
+		&Event{Type: EventTypeContentBlockStop, Index: &index},
+		&Event{Type: EventTypeMessageDelta, Delta: &EventDelta{StopReason: "end_turn"}, Usage: &Usage{OutputTokens: 5}},
+		&Event{Type: EventTypeMessageStop},
+	)
+}
+
+// drain adds the stream's events to accumulator until Next returns false.
+func drain(t *testing.T, stream StreamIterator, accumulator *ResponseAccumulator) {
+	t.Helper()
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+}
+
+func TestResumableStreamRegeneratesText(t *testing.T) {
+	model := &scriptedStreamLLM{
+		streams: [][]*Event{
+			textStreamEvents("Hello, ", "wo")[:4],
+			textStreamEvents("Hel", "lo, world", "!"),
+		},
+		errs: []error{io.ErrUnexpectedEOF},
+	}
+	stream, err := NewResumableStream(context.Background(), model, WithUserTextMessage("hi"))
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	accumulator := NewResponseAccumulator()
+	drain(t, stream, accumulator)
+	assert.ErrorIs(t, stream.Err(), io.ErrUnexpectedEOF)
+
+	assert.NoError(t, stream.Resume(context.Background()))
+	drain(t, stream, accumulator)
+	assert.NoError(t, stream.Err())
+
+	assert.Equal(t, 2, model.calls)
+	assert.True(t, accumulator.IsComplete())
+	response := accumulator.Response()
+	assert.Equal(t, "Hello, world!", response.Message().Text())
+	assert.Equal(t, 10, response.Usage.InputTokens)
+	assert.Equal(t, "end_turn", response.StopReason)
+}
+
+func TestResumableStreamDiverged(t *testing.T) {
+	model := &scriptedStreamLLM{
+		streams: [][]*Event{
+			textStreamEvents("Hello, ", "wo")[:4],
+			textStreamEvents("Hi there!"),
+		},
+		errs: []error{io.ErrUnexpectedEOF},
+	}
+	stream, err := NewResumableStream(context.Background(), model)
+	assert.NoError(t, err)
+
+	accumulator := NewResponseAccumulator()
+	drain(t, stream, accumulator)
+	assert.NoError(t, stream.Resume(context.Background()))
+	drain(t, stream, accumulator)
+	assert.ErrorIs(t, stream.Err(), ErrStreamNotResumable)
+	assert.Equal(t, "Hello, wo", accumulator.Response().Message().Text())
+}
+
+func TestResumableStreamRegeneratedTextEndsEarly(t *testing.T) {
+	model := &scriptedStreamLLM{
+		streams: [][]*Event{
+			textStreamEvents("Hello, ", "wo")[:4],
+			textStreamEvents("Hello"),
+		},
+		errs: []error{io.ErrUnexpectedEOF},
+	}
+	stream, err := NewResumableStream(context.Background(), model)
+	assert.NoError(t, err)
+
+	drain(t, stream, NewResponseAccumulator())
+	assert.NoError(t, stream.Resume(context.Background()))
+	drain(t, stream, NewResponseAccumulator())
+	assert.ErrorIs(t, stream.Err(), ErrStreamNotResumable)
+}
+
+func TestResumableStreamToolCallNotResumable(t *testing.T) {
+	index := 0
+	model := &scriptedStreamLLM{
+		streams: [][]*Event{{
+			{Type: EventTypeMessageStart, Message: &Response{ID: "msg_1", Role: Assistant}},
+			{Type: EventTypeContentBlockStart, Index: &index, ContentBlock: &EventContentBlock{Type: ContentTypeToolUse, ID: "call_1", Name: "search"}},
+		}},
+		errs: []error{io.ErrUnexpectedEOF},
+	}
+	stream, err := NewResumableStream(context.Background(), model)
+	assert.NoError(t, err)
+
+	drain(t, stream, NewResponseAccumulator())
+	err = stream.Resume(context.Background())
+	assert.ErrorIs(t, err, ErrStreamNotResumable)
+	assert.Equal(t, 1, model.calls)
+	assert.False(t, stream.Next())
+	assert.ErrorIs(t, stream.Err(), io.ErrUnexpectedEOF)
+}
+
+func TestResumableStreamNotFailed(t *testing.T) {
+	model := &scriptedStreamLLM{streams: [][]*Event{textStreamEvents("ok")}}
+	stream, err := NewResumableStream(context.Background(), model)
+	assert.NoError(t, err)
+
+	drain(t, stream, NewResponseAccumulator())
+	assert.ErrorIs(t, stream.Resume(context.Background()), ErrStreamNotResumable)
+}
+
+// cursorStream is a provider stream that resumes natively, continuing
+// with the events after the failure.
+type cursorStream struct {
+	sliceStream
+	rest    []*Event
+	resumes int
+}
+
+func (c *cursorStream) Resume(ctx context.Context) error {
+	c.resumes++
+	c.events, c.rest, c.err = c.rest, nil, nil
+	return nil
+}
+
+type cursorStreamLLM struct {
+	scriptedStreamLLM
+	stream *cursorStream
+}
+
+func (c *cursorStreamLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	c.calls++
+	return c.stream, nil
+}
+
+func TestResumableStreamNativeResume(t *testing.T) {
+	events := textStreamEvents("Hello, ", "world!")
+	model := &cursorStreamLLM{stream: &cursorStream{
+		sliceStream: sliceStream{events: events[:3], err: io.ErrUnexpectedEOF},
+		rest:        events[3:],
+	}}
+	stream, err := NewResumableStream(context.Background(), model)
+	assert.NoError(t, err)
+
+	accumulator := NewResponseAccumulator()
+	drain(t, stream, accumulator)
+	assert.NoError(t, stream.Resume(context.Background()))
+	drain(t, stream, accumulator)
+	assert.NoError(t, stream.Err())
+
+	assert.Equal(t, 1, model.calls)
+	assert.Equal(t, 1, model.stream.resumes)
+	assert.Equal(t, "Hello, world!", accumulator.Response().Message().Text())
+}
@@ -0,0 +1,238 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/retry"
+)
+
+// Default values for RetryOptions.
+const (
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = time.Second
+	DefaultRetryMaxBackoff     = 30 * time.Second
+	DefaultRetryMultiplier     = 2.0
+)
+
+// RetryOptions configures WithRetry. Zero fields use the defaults above.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	MaxAttempts int
+
+	// InitialBackoff is the delay after the first failed attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. A provider's Retry-After
+	// is honored even when it is longer.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor by which the delay grows after each attempt.
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to this fraction of it, so 0.2
+	// means +/- 20%. Zero disables jitter.
+	Jitter float64
+}
+
+// WithRetry returns an LLM that retries failed requests to model with
+// exponential backoff. Rate limits, overloaded and unavailable servers,
+// timeouts, and network errors are retried; other errors, such as
+// authentication failures and invalid requests, are returned at once. A
+// provider error's Retry-After is honored when it asks for a longer wait
+// than the backoff, and the wait ends early if ctx is canceled.
+//
+// A stream is retried only if it fails before producing its first event;
+// after that the error is returned, since the caller has already seen part
+// of the response. Like WithMiddleware, the returned LLM streams if model
+// does and forwards ModelInfoProvider, ToolLimiter, and ResponseContinuer.
+//
+// Most providers retry internally as well; configure them with
+// WithMaxRetries(0) to let WithRetry own the retry budget.
+func WithRetry(model LLM, opts RetryOptions) LLM {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = DefaultRetryInitialBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if opts.Multiplier <= 0 {
+		opts.Multiplier = DefaultRetryMultiplier
+	}
+	r := &retryLLM{middlewareLLM: &middlewareLLM{llm: model}, opts: opts}
+	if _, ok := model.(StreamingLLM); ok {
+		return &streamingRetryLLM{r}
+	}
+	return r
+}
+
+type retryLLM struct {
+	*middlewareLLM
+	opts RetryOptions
+}
+
+func (r *retryLLM) Generate(ctx context.Context, opts ...Option) (*Response, error) {
+	for attempt := 1; ; attempt++ {
+		response, err := r.llm.Generate(ctx, opts...)
+		if err == nil || !r.again(ctx, attempt, err) {
+			return response, err
+		}
+	}
+}
+
+// again reports whether to make another attempt after attempt failed with
+// err, waiting out the backoff first.
+func (r *retryLLM) again(ctx context.Context, attempt int, err error) bool {
+	if attempt >= r.opts.MaxAttempts || ctx.Err() != nil || !isRetryable(err) {
+		return false
+	}
+	config := retry.Config{
+		InitialBackoff:    r.opts.InitialBackoff,
+		MaxBackoff:        r.opts.MaxBackoff,
+		BackoffMultiplier: r.opts.Multiplier,
+		Jitter:            r.opts.Jitter,
+	}
+	delay := retry.ExponentialBackoff(attempt, &config)
+	if after := retryAfter(err); after > delay {
+		delay = after
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// isRetryable reports whether err is worth retrying. Errors marked
+// permanent never are. Errors carrying an HTTP status, such as
+// providers.ProviderError, are classified by status; other errors are
+// retried only if they come from the network.
+func isRetryable(err error) bool {
+	err = lastAttemptError(err)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || retry.IsPermanent(err) {
+		return false
+	}
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode() {
+		case http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+			520, // Cloudflare
+			529: // Anthropic overloaded_error
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter returns the wait requested by err's Retry-After, if any.
+func retryAfter(err error) time.Duration {
+	var afterErr interface{ RetryAfter() time.Duration }
+	if errors.As(lastAttemptError(err), &afterErr) {
+		return afterErr.RetryAfter()
+	}
+	return 0
+}
+
+// lastAttemptError unwraps the error of a provider's own final retry
+// attempt, so that it is classified rather than an earlier attempt's.
+func lastAttemptError(err error) error {
+	var retryErr *retry.Error
+	if errors.As(err, &retryErr) && retryErr.Last != nil {
+		return retryErr.Last
+	}
+	return err
+}
+
+type streamingRetryLLM struct {
+	*retryLLM
+}
+
+func (r *streamingRetryLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	stream := &retryStream{ctx: ctx, llm: r, opts: opts}
+	if err := stream.start(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// retryStream restarts a failed stream until it produces its first event.
+type retryStream struct {
+	ctx     context.Context
+	llm     *streamingRetryLLM
+	opts    []Option
+	current StreamIterator
+	attempt int
+	started bool
+}
+
+// start opens a stream, retrying failed attempts.
+func (s *retryStream) start() error {
+	for {
+		s.attempt++
+		stream, err := s.llm.llm.(StreamingLLM).Stream(s.ctx, s.opts...)
+		if err == nil {
+			s.current = stream
+			return nil
+		}
+		if !s.llm.again(s.ctx, s.attempt, err) {
+			return err
+		}
+	}
+}
+
+func (s *retryStream) Next() bool {
+	for {
+		if s.current.Next() {
+			s.started = true
+			return true
+		}
+		err := s.current.Err()
+		if s.started || err == nil || !s.llm.again(s.ctx, s.attempt, err) {
+			return false
+		}
+		s.current.Close()
+		if err := s.start(); err != nil {
+			s.current = &failedStream{err: err}
+			return false
+		}
+	}
+}
+
+func (s *retryStream) Event() *Event {
+	return s.current.Event()
+}
+
+func (s *retryStream) Err() error {
+	return s.current.Err()
+}
+
+func (s *retryStream) Close() error {
+	return s.current.Close()
+}
+
+// failedStream is a stream whose final restart failed.
+type failedStream struct {
+	err error
+}
+
+func (s *failedStream) Next() bool    { return false }
+func (s *failedStream) Event() *Event { return nil }
+func (s *failedStream) Err() error    { return s.err }
+func (s *failedStream) Close() error  { return nil }
@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/retry"
+)
+
+// statusError mimics providers.ProviderError.
+type statusError struct {
+	status     int
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string             { return fmt.Sprintf("status %d", e.status) }
+func (e *statusError) StatusCode() int           { return e.status }
+func (e *statusError) RetryAfter() time.Duration { return e.retryAfter }
+
+// flakyLLM fails with errs, in order, before succeeding.
+type flakyLLM struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyLLM) Name() string { return "flaky" }
+
+func (f *flakyLLM) Generate(ctx context.Context, opts ...Option) (*Response, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return &Response{Role: Assistant, Content: []Content{&TextContent{Text: "ok"}}}, nil
+}
+
+// flakyStreamLLM streams events that fail with errs, in order, before
+// streaming successfully.
+type flakyStreamLLM struct {
+	flakyLLM
+	events []*Event // events sent before each failure
+}
+
+func (f *flakyStreamLLM) Stream(ctx context.Context, opts ...Option) (StreamIterator, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return &sliceStream{events: f.events, err: f.errs[f.calls-1]}, nil
+	}
+	return &sliceStream{events: []*Event{{Type: EventTypeMessageStop}}}, nil
+}
+
+type sliceStream struct {
+	events []*Event
+	event  *Event
+	err    error
+}
+
+func (s *sliceStream) Next() bool {
+	if len(s.events) == 0 {
+		return false
+	}
+	s.event, s.events = s.events[0], s.events[1:]
+	return true
+}
+
+func (s *sliceStream) Event() *Event { return s.event }
+
+func (s *sliceStream) Err() error {
+	if len(s.events) > 0 {
+		return nil
+	}
+	return s.err
+}
+
+func (s *sliceStream) Close() error { return nil }
+
+var fastRetry = RetryOptions{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestWithRetry(t *testing.T) {
+	inner := &flakyLLM{errs: []error{&statusError{status: 429}}}
+	model := WithRetry(inner, fastRetry)
+	_, isStreaming := model.(StreamingLLM)
+	assert.False(t, isStreaming)
+
+	response, err := model.Generate(context.Background(), WithUserTextMessage("hi"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", response.Message().Text())
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestWithRetryFatalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"auth", &statusError{status: 401}},
+		{"bad request", retry.MarkPermanent(&statusError{status: 400})},
+		{"out of quota", retry.MarkPermanent(&statusError{status: 429})},
+		{"unknown", errors.New("invalid tool schema")},
+		{"canceled", context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &flakyLLM{errs: []error{tt.err}}
+			_, err := WithRetry(inner, fastRetry).Generate(context.Background())
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, 1, inner.calls)
+		})
+	}
+}
+
+func TestWithRetryAttempts(t *testing.T) {
+	overloaded := &statusError{status: 529}
+	inner := &flakyLLM{errs: []error{overloaded, overloaded, overloaded}}
+	_, err := WithRetry(inner, fastRetry).Generate(context.Background())
+	assert.ErrorIs(t, err, overloaded)
+	assert.Equal(t, 3, inner.calls)
+
+	// A provider's exhausted internal retries are classified by their last
+	// error
+	inner = &flakyLLM{errs: []error{&retry.Error{Last: &statusError{status: 503}, Attempts: 2}}}
+	_, err = WithRetry(inner, fastRetry).Generate(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	inner := &flakyLLM{errs: []error{&statusError{status: 429, retryAfter: 30 * time.Millisecond}}}
+	start := time.Now()
+	_, err := WithRetry(inner, fastRetry).Generate(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+
+	// Cancellation ends the wait
+	inner = &flakyLLM{errs: []error{&statusError{status: 429, retryAfter: time.Hour}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = WithRetry(inner, fastRetry).Generate(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 1, inner.calls)
+}
+
+func TestWithRetryStream(t *testing.T) {
+	inner := &flakyStreamLLM{flakyLLM: flakyLLM{errs: []error{&statusError{status: 429}}}}
+	model := WithRetry(inner, fastRetry)
+	streaming, ok := model.(StreamingLLM)
+	assert.True(t, ok)
+
+	stream, err := streaming.Stream(context.Background())
+	assert.NoError(t, err)
+	var events []*Event
+	for stream.Next() {
+		events = append(events, stream.Event())
+	}
+	assert.NoError(t, stream.Err())
+	assert.NoError(t, stream.Close())
+	assert.Len(t, events, 1)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestWithRetryStreamAfterFirstEvent(t *testing.T) {
+	overloaded := &statusError{status: 529}
+	inner := &flakyStreamLLM{
+		flakyLLM: flakyLLM{errs: []error{overloaded}},
+		events:   []*Event{{Type: EventTypeMessageStart}},
+	}
+	stream, err := WithRetry(inner, fastRetry).(StreamingLLM).Stream(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, stream.Next())
+	assert.False(t, stream.Next())
+	assert.ErrorIs(t, stream.Err(), overloaded)
+	assert.Equal(t, 1, inner.calls)
+}
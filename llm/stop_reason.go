@@ -0,0 +1,53 @@
+package llm
+
+// Stop reasons reported in Response.StopReason. Every provider normalizes its
+// native finish reason to one of these values, so callers can handle
+// truncation, refusals, and tool use the same way regardless of provider.
+// Providers may report other values for conditions without a portable
+// equivalent (e.g. Anthropic's "pause_turn").
+const (
+	// StopReasonEndTurn means the model finished its response naturally.
+	StopReasonEndTurn = "end_turn"
+
+	// StopReasonMaxTokens means the response was cut off by the output token
+	// limit. See Response.Truncated.
+	StopReasonMaxTokens = "max_tokens"
+
+	// StopReasonStopSequence means generation hit a configured stop sequence.
+	StopReasonStopSequence = "stop_sequence"
+
+	// StopReasonToolUse means the model is requesting one or more tool calls.
+	StopReasonToolUse = "tool_use"
+
+	// StopReasonContentFilter means the provider's safety system blocked or
+	// truncated the output.
+	StopReasonContentFilter = "content_filter"
+
+	// StopReasonRefusal means the model declined to respond.
+	StopReasonRefusal = "refusal"
+)
+
+// NormalizeStopReason maps common provider-native finish reasons (such as
+// OpenAI's "stop", "length", and "tool_calls") to the StopReason constants.
+// Values that are already normalized, or that have no portable equivalent,
+// are returned unchanged.
+func NormalizeStopReason(reason string) string {
+	switch reason {
+	case "stop", "STOP":
+		return StopReasonEndTurn
+	case "length", "max_output_tokens", "MAX_TOKENS":
+		return StopReasonMaxTokens
+	case "tool_calls", "function_call":
+		return StopReasonToolUse
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return StopReasonContentFilter
+	default:
+		return reason
+	}
+}
+
+// Truncated returns true if the response was cut off by the output token
+// limit rather than finishing naturally.
+func (r *Response) Truncated() bool {
+	return r.StopReason == StopReasonMaxTokens
+}
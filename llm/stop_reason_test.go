@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestNormalizeStopReason(t *testing.T) {
+	tests := map[string]string{
+		"stop":              StopReasonEndTurn,
+		"length":            StopReasonMaxTokens,
+		"max_output_tokens": StopReasonMaxTokens,
+		"tool_calls":        StopReasonToolUse,
+		"function_call":     StopReasonToolUse,
+		"SAFETY":            StopReasonContentFilter,
+		"end_turn":          StopReasonEndTurn,
+		"content_filter":    StopReasonContentFilter,
+		"refusal":           StopReasonRefusal,
+		"pause_turn":        "pause_turn",
+		"":                  "",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, NormalizeStopReason(input), "input %q", input)
+	}
+}
+
+func TestResponseTruncated(t *testing.T) {
+	assert.True(t, (&Response{StopReason: StopReasonMaxTokens}).Truncated())
+	assert.False(t, (&Response{StopReason: StopReasonEndTurn}).Truncated())
+	assert.False(t, (&Response{}).Truncated())
+}
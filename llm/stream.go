@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"sort"
@@ -23,28 +24,58 @@ const (
 	EventTypeContentBlockStop  EventType = "content_block_stop"
 )
 
+// EventSchemaVersion is the version of Event's JSON encoding. The encoding
+// is stable: fields may be added, but a change that alters the meaning of an
+// existing field increments this version.
+const EventSchemaVersion = 1
+
 // Event represents a single streaming event from the LLM. A successfully
 // run stream will end with a final message containing the complete Response.
 type Event struct {
-	Type              EventType                  `json:"type"`
-	Index             *int                       `json:"index,omitempty"`
-	Message           *Response                  `json:"message,omitempty"`
-	ContentBlock      *EventContentBlock         `json:"content_block,omitempty"`
-	Delta             *EventDelta                `json:"delta,omitempty"`
-	Usage             *Usage                     `json:"usage,omitempty"`
+	Type         EventType          `json:"type"`
+	Index        *int               `json:"index,omitempty"`
+	Message      *Response          `json:"message,omitempty"`
+	ContentBlock *EventContentBlock `json:"content_block,omitempty"`
+	Delta        *EventDelta        `json:"delta,omitempty"`
+
+	// Usage is the token usage added since the previous event. Providers
+	// that report usage while streaming, such as Anthropic and OpenAI, set
+	// it on message_delta events, so a live total is the usage of the
+	// message_start event's Message plus each Usage so far. Providers
+	// without incremental usage leave it nil.
+	Usage *Usage `json:"usage,omitempty"`
+
 	ContextManagement *ContextManagementResponse `json:"context_management,omitempty"`
+
+	// SchemaVersion is the version of the JSON encoding an event was decoded
+	// from. Zero for events received from providers, which don't send it.
+	// Encoding an event with a zero SchemaVersion writes EventSchemaVersion.
+	SchemaVersion int `json:"schema_version,omitempty"`
+}
+
+// MarshalJSON encodes the event, stamping it with EventSchemaVersion unless
+// SchemaVersion is already set.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	if e.SchemaVersion == 0 {
+		e.SchemaVersion = EventSchemaVersion
+	}
+	return json.Marshal(event(e))
 }
 
 // EventContentBlock carries the start of a content block in an LLM event.
 type EventContentBlock struct {
-	Type      ContentType      `json:"type"`
-	Text      string           `json:"text,omitempty"`
-	ID        string           `json:"id,omitempty"`
-	Name      string           `json:"name,omitempty"`
-	Input     json.RawMessage  `json:"input,omitempty"`
-	Thinking  string           `json:"thinking,omitempty"`
-	Signature string           `json:"signature,omitempty"`
-	Metadata  ProviderMetadata `json:"metadata,omitempty"`
+	Type        ContentType      `json:"type"`
+	Text        string           `json:"text,omitempty"`
+	ID          string           `json:"id,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Input       json.RawMessage  `json:"input,omitempty"`
+	Thinking    string           `json:"thinking,omitempty"`
+	Signature   string           `json:"signature,omitempty"`
+	Metadata    ProviderMetadata `json:"metadata,omitempty"`
+	Filename    string           `json:"filename,omitempty"`
+	Source      *ContentSource   `json:"source,omitempty"`
+	ContainerID string           `json:"container_id,omitempty"`
 }
 
 // EventDeltaType indicates the type of delta in an LLM event.
@@ -72,6 +103,27 @@ type EventDelta struct {
 	PartialJSON  string         `json:"partial_json,omitempty"`
 	Thinking     string         `json:"thinking,omitempty"`
 	Signature    string         `json:"signature,omitempty"`
+	Citation     Citation       `json:"citation,omitempty"`
+}
+
+// UnmarshalJSON decodes the delta's citation by type. A citation of an
+// unknown type is dropped rather than failing the stream.
+func (d *EventDelta) UnmarshalJSON(data []byte) error {
+	type deltaAlias EventDelta
+	aux := struct {
+		*deltaAlias
+		Citation json.RawMessage `json:"citation,omitempty"`
+	}{deltaAlias: (*deltaAlias)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.Citation = nil
+	if len(aux.Citation) > 0 && string(aux.Citation) != "null" {
+		if citation, err := unmarshalCitation(aux.Citation); err == nil {
+			d.Citation = citation
+		}
+	}
+	return nil
 }
 
 // ResponseAccumulator builds up a complete response from a stream of events.
@@ -79,6 +131,8 @@ type ResponseAccumulator struct {
 	response      *Response
 	contentBlocks map[int]Content // Map of content blocks by index
 	skippedBlocks map[int]bool    // Indices of unrecognized content block types
+	completed     map[int]bool    // Indices of blocks already reported complete
+	onComplete    []func(Content)
 	complete      bool
 }
 
@@ -90,6 +144,24 @@ func NewResponseAccumulator() *ResponseAccumulator {
 	}
 }
 
+// OnContentBlockComplete registers a callback that is invoked each time a
+// content block finishes streaming, in the order blocks complete. The block
+// passed to the callback is fully assembled (e.g. a tool_use block carries its
+// complete input JSON). Blocks of unrecognized types are not reported. Any
+// blocks still open when the message stops are reported at that point.
+//
+// Callbacks run synchronously inside AddEvent and must not call back into the
+// accumulator.
+func (r *ResponseAccumulator) OnContentBlockComplete(fn func(Content)) {
+	if fn == nil {
+		return
+	}
+	r.onComplete = append(r.onComplete, fn)
+	if r.completed == nil {
+		r.completed = make(map[int]bool)
+	}
+}
+
 // AddEvent adds an event to the ResponseAccumulator.
 func (r *ResponseAccumulator) AddEvent(event *Event) error {
 	switch event.Type {
@@ -126,6 +198,12 @@ func (r *ResponseAccumulator) AddEvent(event *Event) error {
 			}
 		case ContentTypeRedactedThinking:
 			content = &RedactedThinkingContent{}
+		case ContentTypeFile:
+			content = &FileContent{
+				Filename:    event.ContentBlock.Filename,
+				Source:      event.ContentBlock.Source,
+				ContainerID: event.ContentBlock.ContainerID,
+			}
 		}
 		if content == nil {
 			// Unrecognized content block type (e.g. server-tool blocks like
@@ -181,6 +259,16 @@ func (r *ResponseAccumulator) AddEvent(event *Event) error {
 			} else {
 				return errors.New("in-progress block is not a thinking content")
 			}
+		case EventDeltaTypeCitations:
+			if textContent, ok := content.(*TextContent); ok && event.Delta.Citation != nil {
+				textContent.Citations = append(textContent.Citations, event.Delta.Citation)
+			}
+		}
+
+	case EventTypeContentBlockStop:
+		if event.Index != nil {
+			repairToolInput(r.contentBlocks[*event.Index])
+			r.notifyBlockComplete(*event.Index)
 		}
 
 	case EventTypeMessageDelta:
@@ -196,6 +284,11 @@ func (r *ResponseAccumulator) AddEvent(event *Event) error {
 
 	case EventTypeMessageStop:
 		r.complete = true
+		for _, content := range r.contentBlocks {
+			repairToolInput(content)
+		}
+		// Report any blocks the provider never explicitly stopped
+		r.notifyOpenBlocks()
 		// Convert map to sorted slice when complete
 		r.finalizeContent()
 	}
@@ -226,6 +319,62 @@ func (r *ResponseAccumulator) AddEvent(event *Event) error {
 	return nil
 }
 
+// repairToolInput completes the input of a tool use block whose JSON was cut
+// off mid-stream, marking it Repaired. Input that can't be repaired is left
+// as is for the caller to reject. A tool call with no arguments streams no
+// input (or only empty input_json_delta fragments), so empty input becomes
+// an empty object.
+func repairToolInput(content Content) {
+	toolUse, ok := content.(*ToolUseContent)
+	if !ok {
+		return
+	}
+	if len(bytes.TrimSpace(toolUse.Input)) == 0 {
+		toolUse.Input = json.RawMessage("{}")
+		return
+	}
+	if json.Valid(toolUse.Input) {
+		return
+	}
+	if repaired, ok := RepairJSON(toolUse.Input); ok {
+		toolUse.Input = repaired
+		toolUse.Repaired = true
+	}
+}
+
+// notifyBlockComplete reports the block at the given index to the registered
+// completion callbacks, at most once per block.
+func (r *ResponseAccumulator) notifyBlockComplete(index int) {
+	if len(r.onComplete) == 0 || r.completed[index] {
+		return
+	}
+	content, ok := r.contentBlocks[index]
+	if !ok {
+		return
+	}
+	r.completed[index] = true
+	for _, fn := range r.onComplete {
+		fn(content)
+	}
+}
+
+// notifyOpenBlocks reports all not-yet-reported blocks in index order.
+func (r *ResponseAccumulator) notifyOpenBlocks() {
+	if len(r.onComplete) == 0 {
+		return
+	}
+	indices := make([]int, 0, len(r.contentBlocks))
+	for index := range r.contentBlocks {
+		if !r.completed[index] {
+			indices = append(indices, index)
+		}
+	}
+	sort.Ints(indices)
+	for _, index := range indices {
+		r.notifyBlockComplete(index)
+	}
+}
+
 // finalizeContent converts the content blocks map to a sorted slice
 func (r *ResponseAccumulator) finalizeContent() {
 	if r.response == nil || len(r.contentBlocks) == 0 {
@@ -260,6 +409,9 @@ func (r *ResponseAccumulator) Response() *Response {
 	return r.response
 }
 
+// Usage returns the usage accumulated so far: the message_start usage plus
+// each event's Usage delta. It is current while the stream is running, and
+// its Speed is that of the latest delta that reports one.
 func (r *ResponseAccumulator) Usage() *Usage {
 	return &r.response.Usage
 }
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/deepnoodle-ai/wonton/assert"
@@ -150,3 +151,115 @@ func TestResponseAccumulatorPreservesOmittedThinkingSignature(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "done", text.Text)
 }
+
+func TestResponseAccumulatorOnContentBlockComplete(t *testing.T) {
+	acc := NewResponseAccumulator()
+	var completed []Content
+	acc.OnContentBlockComplete(func(c Content) {
+		completed = append(completed, c)
+	})
+	idx0, idx1, idx2 := 0, 1, 2
+
+	assert.NoError(t, acc.AddEvent(&Event{
+		Type:    EventTypeMessageStart,
+		Message: &Response{ID: "msg_1", Role: Assistant},
+	}))
+	assert.NoError(t, acc.AddEvent(&Event{
+		Type:  EventTypeContentBlockStart,
+		Index: &idx0,
+		ContentBlock: &EventContentBlock{
+			Type: ContentTypeToolUse,
+			ID:   "toolu_1",
+			Name: "search",
+		},
+	}))
+	assert.NoError(t, acc.AddEvent(&Event{
+		Type:  EventTypeContentBlockDelta,
+		Index: &idx0,
+		Delta: &EventDelta{Type: EventDeltaTypeInputJSON, PartialJSON: `{"q":`},
+	}))
+	assert.Len(t, completed, 0)
+	assert.NoError(t, acc.AddEvent(&Event{
+		Type:  EventTypeContentBlockDelta,
+		Index: &idx0,
+		Delta: &EventDelta{Type: EventDeltaTypeInputJSON, PartialJSON: `"go"}`},
+	}))
+	assert.NoError(t, acc.AddEvent(&Event{Type: EventTypeContentBlockStop, Index: &idx0}))
+
+	// The tool call is reported as soon as its block stops
+	assert.Len(t, completed, 1)
+	toolUse, ok := completed[0].(*ToolUseContent)
+	assert.True(t, ok)
+	assert.Equal(t, `{"q":"go"}`, string(toolUse.Input))
+
+	// Duplicate stops are reported once; skipped blocks are never reported
+	assert.NoError(t, acc.AddEvent(&Event{Type: EventTypeContentBlockStop, Index: &idx0}))
+	assert.NoError(t, acc.AddEvent(&Event{
+		Type:         EventTypeContentBlockStart,
+		Index:        &idx1,
+		ContentBlock: &EventContentBlock{Type: ContentType("server_tool_use")},
+	}))
+	assert.NoError(t, acc.AddEvent(&Event{Type: EventTypeContentBlockStop, Index: &idx1}))
+	assert.Len(t, completed, 1)
+
+	// A block left open is reported when the message stops
+	assert.NoError(t, acc.AddEvent(&Event{
+		Type:         EventTypeContentBlockStart,
+		Index:        &idx2,
+		ContentBlock: &EventContentBlock{Type: ContentTypeText, Text: "done"},
+	}))
+	assert.NoError(t, acc.AddEvent(&Event{Type: EventTypeMessageStop}))
+	assert.Len(t, completed, 2)
+	text, ok := completed[1].(*TextContent)
+	assert.True(t, ok)
+	assert.Equal(t, "done", text.Text)
+}
+
+func TestResponseAccumulatorRepairsTruncatedToolInput(t *testing.T) {
+	acc := NewResponseAccumulator()
+	idx := 0
+	events := []*Event{
+		{Type: EventTypeMessageStart, Message: &Response{ID: "msg_1", Role: Assistant}},
+		{Type: EventTypeContentBlockStart, Index: &idx, ContentBlock: &EventContentBlock{
+			Type: ContentTypeToolUse, ID: "toolu_1", Name: "write_file",
+		}},
+		{Type: EventTypeContentBlockDelta, Index: &idx, Delta: &EventDelta{
+			Type: EventDeltaTypeInputJSON, PartialJSON: `{"path": "a.txt", "content": "hel`,
+		}},
+		{Type: EventTypeContentBlockStop, Index: &idx},
+		{Type: EventTypeMessageDelta, Delta: &EventDelta{StopReason: StopReasonMaxTokens}},
+		{Type: EventTypeMessageStop},
+	}
+	for _, event := range events {
+		assert.NoError(t, acc.AddEvent(event))
+	}
+
+	calls := acc.Response().ToolCalls()
+	assert.Len(t, calls, 1)
+	assert.True(t, calls[0].Repaired)
+	assert.Equal(t, `{"path": "a.txt", "content": "hel"}`, string(calls[0].Input))
+}
+
+func TestResponseAccumulatorCitationsDelta(t *testing.T) {
+	// Anthropic-shaped stream events, decoded from JSON
+	lines := []string{
+		`{"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[]}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"citations_delta","citation":{"type":"web_search_result_location","url":"https://go.dev","title":"Go","cited_text":"Go is"}}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"citations_delta","citation":{"type":"unknown_location"}}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Go is fast."}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"message_stop"}`,
+	}
+	acc := NewResponseAccumulator()
+	for _, line := range lines {
+		var event Event
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.NoError(t, acc.AddEvent(&event))
+	}
+
+	text := acc.Response().Content[0].(*TextContent)
+	assert.Equal(t, "Go is fast.", text.Text)
+	assert.Len(t, text.Citations, 1)
+	assert.Equal(t, "https://go.dev", text.Citations[0].(*WebSearchResultLocation).URL)
+}
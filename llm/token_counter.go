@@ -0,0 +1,24 @@
+package llm
+
+import "context"
+
+// TokenCounter is an optional interface for LLMs that count the input tokens
+// of a request before it is sent, such as with a provider's token counting
+// endpoint. An empty model counts for the provider's default model.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, model string, messages []*Message, tools []Tool) (int, error)
+}
+
+// CountTokens counts the input tokens of a request with messages and tools
+// to model. It uses model's TokenCounter if it implements one and otherwise
+// estimates locally with EstimateRequestTokens, sizing for modelName, or for
+// the model it reports through ModelInfoProvider when modelName is empty.
+func CountTokens(ctx context.Context, model LLM, modelName string, messages []*Message, tools []Tool) (int, error) {
+	if counter, ok := model.(TokenCounter); ok {
+		return counter.CountTokens(ctx, modelName, messages, tools)
+	}
+	if provider, ok := model.(ModelInfoProvider); ok && modelName == "" {
+		modelName = provider.ModelInfo("").Model
+	}
+	return EstimateRequestTokens(modelName, messages, tools)
+}
@@ -0,0 +1,339 @@
+package llm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF for image sizes
+	_ "image/jpeg" // register JPEG for image sizes
+	_ "image/png"  // register PNG for image sizes
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	_ "golang.org/x/image/webp" // register WebP for image sizes
+)
+
+// tokenizerProfile approximates one model family's tokenizer. The estimates
+// follow how BPE tokenizers split text: common words are a single token,
+// longer words break into pieces of a few characters, digits are grouped,
+// and CJK and other non-Latin scripts cost about a token per character.
+type tokenizerProfile struct {
+	// wordChars is the longest word counted as a single token.
+	wordChars int
+	// charsPerToken sizes the pieces of longer words.
+	charsPerToken float64
+	// digitsPerToken is how many digits share a token.
+	digitsPerToken int
+	// imageTokens is the cost of an image whose dimensions are unknown. It
+	// is near the high end of what the provider charges.
+	imageTokens int
+	// imageCost is the provider's charge for an image of the given
+	// dimensions.
+	imageCost func(width, height int) int
+	// messageOverhead is the per-message cost of role and framing tokens.
+	messageOverhead int
+}
+
+var (
+	// OpenAI's o200k and cl100k vocabularies split digits into groups of
+	// three and hold most English words whole. A high-detail image is
+	// about 1100 tokens.
+	openAITokenizer = tokenizerProfile{
+		wordChars: 8, charsPerToken: 4, digitsPerToken: 3,
+		imageTokens: 1105, imageCost: openAIImageTokens, messageOverhead: 3,
+	}
+	// Claude's vocabulary is smaller, so words break up sooner. A full-size
+	// image is about 1600 tokens.
+	anthropicTokenizer = tokenizerProfile{
+		wordChars: 6, charsPerToken: 3.5, digitsPerToken: 3,
+		imageTokens: 1600, imageCost: anthropicImageTokens, messageOverhead: 4,
+	}
+	// Gemini tokenizes each digit separately and bills images at 258 tokens
+	// per tile.
+	geminiTokenizer = tokenizerProfile{
+		wordChars: 8, charsPerToken: 4, digitsPerToken: 1,
+		imageTokens: 1032, imageCost: geminiImageTokens, messageOverhead: 4,
+	}
+)
+
+// openAIImageTokens is the cost of a high-detail image: it is scaled to fit
+// in 2048x2048 and then down to 768 pixels on its short side, and costs 170
+// tokens per 512-pixel tile plus 85.
+func openAIImageTokens(width, height int) int {
+	w, h := float64(width), float64(height)
+	if scale := 2048 / max(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	if scale := 768 / min(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	return 85 + 170*int(math.Ceil(w/512)*math.Ceil(h/512))
+}
+
+// anthropicImageTokens is the cost of an image on Claude: it is scaled down
+// to 1568 pixels on its long side and costs a token per 750 pixels.
+func anthropicImageTokens(width, height int) int {
+	w, h := float64(width), float64(height)
+	if scale := 1568 / max(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	return max(1, int(w*h/750))
+}
+
+// geminiImageTokens is the cost of an image on Gemini: 258 tokens if both
+// sides are at most 384 pixels, otherwise 258 per 768-pixel tile.
+func geminiImageTokens(width, height int) int {
+	if width <= 384 && height <= 384 {
+		return 258
+	}
+	return 258 * int(math.Ceil(float64(width)/768)*math.Ceil(float64(height)/768))
+}
+
+// tokenizerFor returns the tokenizer profile for a model name. Unknown
+// models use the OpenAI profile, which most open-weight tokenizers resemble.
+func tokenizerFor(model string) tokenizerProfile {
+	model = strings.ToLower(model)
+	switch {
+	case strings.Contains(model, "claude"):
+		return anthropicTokenizer
+	case strings.Contains(model, "gemini"), strings.Contains(model, "gemma"):
+		return geminiTokenizer
+	default:
+		return openAITokenizer
+	}
+}
+
+// EstimateTokens estimates how many input tokens msg costs when sent to
+// model. It returns the total and the estimate for each content block, in
+// the order of msg.Content. The total also includes a few tokens of
+// per-message framing, so it is slightly more than the sum of the blocks.
+//
+// Estimates are computed locally with an approximation of the model
+// family's tokenizer (OpenAI, Claude, or Gemini, chosen by model name), not
+// the tokenizer itself, so they are estimates for every model. They are
+// closest for English prose; code, JSON, and non-Latin scripts vary more,
+// and Claude and Gemini vocabularies are not public, so their profiles are
+// coarser. Base64 images are sized from their dimensions with the
+// provider's published formula; other images are counted at a fixed cost
+// near the top of the provider's range. Use the provider's usage reporting when exact counts
+// matter, such as for billing.
+func EstimateTokens(msg *Message, model string) (int, []int, error) {
+	if msg == nil {
+		return 0, nil, errors.New("nil message")
+	}
+	profile := tokenizerFor(model)
+	perBlock := make([]int, len(msg.Content))
+	total := profile.messageOverhead
+	for i, content := range msg.Content {
+		tokens, err := profile.contentTokens(content)
+		if err != nil {
+			return 0, nil, fmt.Errorf("content block %d: %w", i, err)
+		}
+		perBlock[i] = tokens
+		total += tokens
+	}
+	return total, perBlock, nil
+}
+
+// EstimateTextTokens estimates how many tokens text costs for model. See
+// EstimateTokens.
+func EstimateTextTokens(text, model string) int {
+	return tokenizerFor(model).textTokens(text)
+}
+
+// EstimateRequestTokens estimates the input tokens of a request with messages
+// and tools for model: the messages as counted by EstimateTokens, plus each
+// tool's name, description, and schema. See EstimateTokens.
+func EstimateRequestTokens(model string, messages []*Message, tools []Tool) (int, error) {
+	profile := tokenizerFor(model)
+	total := 0
+	for i, msg := range messages {
+		tokens, _, err := EstimateTokens(msg, model)
+		if err != nil {
+			return 0, fmt.Errorf("message %d: %w", i, err)
+		}
+		total += tokens
+	}
+	for _, tool := range tools {
+		schema, err := json.Marshal(tool.Schema())
+		if err != nil {
+			return 0, fmt.Errorf("tool %q schema: %w", tool.Name(), err)
+		}
+		total += profile.textTokens(tool.Name() + " " + tool.Description() + " " + string(schema))
+	}
+	return total, nil
+}
+
+func (p tokenizerProfile) contentTokens(content Content) (int, error) {
+	switch c := content.(type) {
+	case *TextContent:
+		return p.textTokens(c.Text), nil
+	case *ThinkingContent:
+		return p.textTokens(c.Thinking), nil
+	case *RedactedThinkingContent:
+		// Encrypted reasoning is opaque; size it like base64 data.
+		return len(c.Data) / 4, nil
+	case *RefusalContent:
+		return p.textTokens(c.Text), nil
+	case *SummaryContent:
+		return p.textTokens(c.Summary), nil
+	case *ToolUseContent:
+		return p.textTokens(c.Name) + p.textTokens(string(c.Input)), nil
+	case *ToolResultContent:
+		return p.toolResultTokens(c.Content)
+	case *ImageContent:
+		return p.imageContentTokens(c), nil
+	case *DocumentContent:
+		return p.documentTokens(c), nil
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return 0, err
+	}
+	return p.textTokens(string(data)), nil
+}
+
+func (p tokenizerProfile) toolResultTokens(content any) (int, error) {
+	switch c := content.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return p.textTokens(c), nil
+	case []Content:
+		total := 0
+		for _, block := range c {
+			tokens, err := p.contentTokens(block)
+			if err != nil {
+				return 0, err
+			}
+			total += tokens
+		}
+		return total, nil
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return 0, err
+	}
+	return p.textTokens(string(data)), nil
+}
+
+// imageContentTokens sizes an image from its dimensions when they can be
+// read from its data.
+func (p tokenizerProfile) imageContentTokens(c *ImageContent) int {
+	if c.Source == nil || c.Source.Type != ContentSourceTypeBase64 {
+		return p.imageTokens
+	}
+	data := base64.NewDecoder(base64.StdEncoding, strings.NewReader(c.Source.Data))
+	config, _, err := image.DecodeConfig(data)
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return p.imageTokens
+	}
+	return p.imageCost(config.Width, config.Height)
+}
+
+// documentTokens sizes a document. Text documents are tokenized; PDFs are
+// billed per page as text plus a page image, which comes to roughly one
+// token per 25 bytes of PDF, and never less than one image.
+func (p tokenizerProfile) documentTokens(c *DocumentContent) int {
+	if c.Source == nil {
+		return 0
+	}
+	switch c.Source.Type {
+	case ContentSourceTypeText:
+		return p.textTokens(c.Source.Data)
+	case ContentSourceTypeBase64:
+		tokens := base64.StdEncoding.DecodedLen(len(c.Source.Data)) / 25
+		return max(tokens, p.imageTokens)
+	}
+	return p.imageTokens
+}
+
+// textTokens estimates the tokens in text by splitting it into the pieces a
+// BPE pre-tokenizer would: words with their leading space, digit runs,
+// punctuation runs, and whitespace.
+func (p tokenizerProfile) textTokens(text string) int {
+	tokens := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r == ' ' && startsWord(text[i+size:]):
+			// A single space before a word is part of the word's token
+			i += size
+		case isWordRune(r):
+			n := 0
+			for i < len(text) {
+				r, size = utf8.DecodeRuneInString(text[i:])
+				if !isWordRune(r) {
+					break
+				}
+				n++
+				i += size
+			}
+			tokens += p.wordTokens(n)
+		case unicode.IsDigit(r):
+			n := 0
+			for i < len(text) {
+				r, size = utf8.DecodeRuneInString(text[i:])
+				if !unicode.IsDigit(r) {
+					break
+				}
+				n++
+				i += size
+			}
+			tokens += (n + p.digitsPerToken - 1) / p.digitsPerToken
+		case unicode.IsSpace(r):
+			// Runs of whitespace, such as indentation, are usually one token
+			for i < len(text) {
+				r, size = utf8.DecodeRuneInString(text[i:])
+				if !unicode.IsSpace(r) {
+					break
+				}
+				i += size
+			}
+			tokens++
+		case r < utf8.RuneSelf:
+			// Punctuation tends to merge in pairs, like "()" or ".\n"
+			n := 0
+			for i < len(text) {
+				r, size = utf8.DecodeRuneInString(text[i:])
+				if r >= utf8.RuneSelf || isWordRune(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+					break
+				}
+				n++
+				i += size
+			}
+			tokens += (n + 1) / 2
+		default:
+			// CJK, emoji, and other scripts: about one token per character
+			i += size
+			tokens++
+		}
+	}
+	return tokens
+}
+
+func (p tokenizerProfile) wordTokens(n int) int {
+	if n <= p.wordChars {
+		return 1
+	}
+	tokens := int(float64(n)/p.charsPerToken + 0.5)
+	return max(tokens, 2)
+}
+
+func startsWord(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return isWordRune(r)
+}
+
+// isWordRune reports whether r belongs to a word that BPE vocabularies
+// commonly merge: Latin, Greek, and Cyrillic letters.
+func isWordRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+	}
+	return unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic)
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestEstimateTextTokens(t *testing.T) {
+	tests := []struct {
+		text  string
+		model string
+		want  int
+	}{
+		{"", "gpt-4o", 0},
+		{"hello world", "gpt-4o", 2},
+		{"The quick brown fox jumps over the lazy dog.", "gpt-4o", 10},
+		{"1234567", "gpt-4o", 3},
+		{"1234567", "gemini-2.5-pro", 7},
+		{"internationalization", "gpt-4o", 5},
+		{"internationalization", "claude-sonnet-4-5", 6},
+		{"你好世界", "gpt-4o", 4},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, EstimateTextTokens(tt.text, tt.model), tt.text)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	msg := &Message{Role: User, Content: []Content{
+		&TextContent{Text: "What is in this image?"},
+		&ImageContent{Source: &ContentSource{Type: ContentSourceTypeBase64, MediaType: "image/png", Data: "aGVsbG8="}},
+		&ToolResultContent{ToolUseID: "call_1", Content: "hello world"},
+	}}
+
+	total, perBlock, err := EstimateTokens(msg, "claude-sonnet-4-5")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{6, 1600, 2}, perBlock)
+	assert.Equal(t, 6+1600+2+anthropicTokenizer.messageOverhead, total)
+
+	// Images are sized per model family
+	_, perBlock, err = EstimateTokens(msg, "gpt-4o")
+	assert.NoError(t, err)
+	assert.Equal(t, 1105, perBlock[1])
+
+	_, _, err = EstimateTokens(nil, "gpt-4o")
+	assert.Error(t, err)
+}
+
+func TestEstimateTokensToolUse(t *testing.T) {
+	msg := &Message{Role: Assistant, Content: []Content{
+		&ToolUseContent{ID: "call_1", Name: "search", Input: []byte(`{"query":"go"}`)},
+	}}
+	_, perBlock, err := EstimateTokens(msg, "gpt-4o")
+	assert.NoError(t, err)
+	assert.Len(t, perBlock, 1)
+	assert.True(t, perBlock[0] > 1)
+}
+
+func TestEstimateTokensImageSize(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1024, 1024))))
+	msg := &Message{Role: User, Content: []Content{&ImageContent{Source: &ContentSource{
+		Type:      ContentSourceTypeBase64,
+		MediaType: "image/png",
+		Data:      base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}}}}
+
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"gpt-4o", 85 + 170*4},      // scaled to 768x768, four tiles
+		{"claude-sonnet-4-5", 1398}, // 1024*1024/750
+		{"gemini-2.5-pro", 258 * 4}, // four 768-pixel tiles
+	}
+	for _, tt := range tests {
+		_, perBlock, err := EstimateTokens(msg, tt.model)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, perBlock[0], tt.model)
+	}
+}
+
+func TestCountTokensEstimates(t *testing.T) {
+	tool := NewToolDefinition().WithName("search").WithDescription("Search the web")
+	messages := []*Message{NewUserTextMessage("hello world")}
+
+	withoutTools, err := EstimateRequestTokens("gpt-4o", messages, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2+openAITokenizer.messageOverhead, withoutTools)
+
+	// Models without a TokenCounter are estimated for the model they report
+	tokens, err := CountTokens(context.Background(), &recordingStreamLLM{}, "", messages, []Tool{tool})
+	assert.NoError(t, err)
+	assert.True(t, tokens > withoutTools)
+	want, err := EstimateRequestTokens("recorded", messages, []Tool{tool})
+	assert.NoError(t, err)
+	assert.Equal(t, want, tokens)
+}
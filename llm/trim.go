@@ -0,0 +1,225 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTrimBudgetExceeded is returned by TrimMessages when messages can't fit
+// the token budget without dropping the system prompt or one of the turns
+// it must keep. Match it with errors.Is.
+var ErrTrimBudgetExceeded = errors.New("messages do not fit the token budget")
+
+// TrimStrategy selects which turns TrimMessages drops first.
+type TrimStrategy string
+
+const (
+	// TrimDropOldest drops the oldest turns first. It is the default.
+	TrimDropOldest TrimStrategy = "drop_oldest"
+
+	// TrimMiddleOut drops the turns in the middle of the conversation
+	// first, working outward, so the opening turns, which often set up the
+	// task, are kept about as long as the latest ones.
+	TrimMiddleOut TrimStrategy = "middle_out"
+)
+
+// TrimOptions configures TrimMessages.
+type TrimOptions struct {
+	// Strategy selects which turns are dropped first. Defaults to
+	// TrimDropOldest.
+	Strategy TrimStrategy
+
+	// KeepLastTurns is the number of most recent turns that are never
+	// dropped. Values below 1 keep the latest turn.
+	KeepLastTurns int
+
+	// Model is passed to the counter, and sizes local estimates when the
+	// counter is nil.
+	Model string
+
+	// Tools are counted with the messages, since their definitions take up
+	// the same context window. They are never dropped.
+	Tools []Tool
+
+	// Summarize, if set, replaces the dropped turns with the message it
+	// returns, such as a user message summarizing them. The summary counts
+	// toward the budget, and more turns are dropped if it doesn't fit.
+	Summarize func(ctx context.Context, dropped []*Message) (*Message, error)
+}
+
+// TrimMessages returns the messages that fit in maxTokens, dropping whole
+// turns of the conversation until they do. A turn starts at a user message
+// that isn't a tool result and runs to the next one, so a tool call is never
+// separated from its result, and the trimmed conversation still alternates
+// roles the way providers expect. System messages are always kept, in
+// place. Messages are returned unchanged when they already fit.
+//
+// Requests are sized with counter, or estimated with EstimateRequestTokens
+// when counter is nil. The counter is called a few times, not once per
+// message, since provider counters make a request each time.
+//
+// When the system messages and the turns that must be kept don't fit on
+// their own, TrimMessages returns an error matching ErrTrimBudgetExceeded.
+// A trimmed slice is new, but shares the messages of the input.
+func TrimMessages(ctx context.Context, messages []*Message, maxTokens int, counter TokenCounter, opts TrimOptions) ([]*Message, error) {
+	count := func(candidate []*Message) (int, error) {
+		if counter == nil {
+			return EstimateRequestTokens(opts.Model, candidate, opts.Tools)
+		}
+		return counter.CountTokens(ctx, opts.Model, candidate, opts.Tools)
+	}
+	total, err := count(messages)
+	if err != nil {
+		return nil, err
+	}
+	if total <= maxTokens {
+		return messages, nil
+	}
+
+	turns := splitTurns(messages)
+	keep := max(opts.KeepLastTurns, 1)
+	droppable := len(turns) - keep
+	if droppable <= 0 {
+		return nil, trimBudgetError(total, maxTokens)
+	}
+	order := trimOrder(droppable, opts.Strategy)
+
+	// fits drops the first drops turns of the order and counts the result
+	fits := func(drops int) ([]*Message, int, error) {
+		trimmed, dropped := dropTurns(messages, turns, order[:drops])
+		if opts.Summarize != nil {
+			summary, err := opts.Summarize(ctx, dropped)
+			if err != nil {
+				return nil, 0, fmt.Errorf("summarizing dropped messages: %w", err)
+			}
+			if summary != nil {
+				trimmed = insertSummary(trimmed, summary, turns, order[:drops])
+			}
+		}
+		total, err := count(trimmed)
+		return trimmed, total, err
+	}
+	if opts.Summarize == nil {
+		// Without a summary, dropping more turns never adds tokens, so
+		// binary search for the fewest drops that fit
+		var best []*Message
+		low, high := 1, droppable
+		for low <= high {
+			drops := (low + high) / 2
+			trimmed, n, err := fits(drops)
+			if err != nil {
+				return nil, err
+			}
+			if n <= maxTokens {
+				best, high = trimmed, drops-1
+			} else {
+				total, low = n, drops+1
+			}
+		}
+		if best == nil {
+			return nil, trimBudgetError(total, maxTokens)
+		}
+		return best, nil
+	}
+	// A summary's size depends on what it summarizes, so drop one more turn
+	// at a time
+	for drops := 1; drops <= droppable; drops++ {
+		trimmed, n, err := fits(drops)
+		if err != nil {
+			return nil, err
+		}
+		if n <= maxTokens {
+			return trimmed, nil
+		}
+		total = n
+	}
+	return nil, trimBudgetError(total, maxTokens)
+}
+
+func trimBudgetError(total, maxTokens int) error {
+	return fmt.Errorf("%w: %d tokens after trimming, budget is %d", ErrTrimBudgetExceeded, total, maxTokens)
+}
+
+// splitTurns returns the indexes of the messages of each turn, leaving out
+// system messages. Messages before the first turn's user message form a
+// turn of their own.
+func splitTurns(messages []*Message) [][]int {
+	var turns [][]int
+	for i, msg := range messages {
+		if msg.Role == System {
+			continue
+		}
+		if len(turns) == 0 || (msg.Role == User && !containsToolResult(msg)) {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], i)
+	}
+	return turns
+}
+
+func containsToolResult(msg *Message) bool {
+	for _, content := range msg.Content {
+		if _, ok := content.(*ToolResultContent); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// trimOrder returns the indexes of the first n turns in the order they are
+// dropped. The turns dropped by any prefix of the order are contiguous.
+func trimOrder(n int, strategy TrimStrategy) []int {
+	order := make([]int, 0, n)
+	if strategy != TrimMiddleOut {
+		for i := range n {
+			order = append(order, i)
+		}
+		return order
+	}
+	// Start in the middle and alternate outward, earlier turns first
+	mid := n / 2
+	order = append(order, mid)
+	for d := 1; len(order) < n; d++ {
+		if mid-d >= 0 {
+			order = append(order, mid-d)
+		}
+		if mid+d < n {
+			order = append(order, mid+d)
+		}
+	}
+	return order
+}
+
+// dropTurns returns messages without the given turns, and the messages
+// that were dropped.
+func dropTurns(messages []*Message, turns [][]int, drop []int) (kept, dropped []*Message) {
+	skip := make(map[int]bool)
+	for _, turn := range drop {
+		for _, i := range turns[turn] {
+			skip[i] = true
+		}
+	}
+	for i, msg := range messages {
+		if skip[i] {
+			dropped = append(dropped, msg)
+		} else {
+			kept = append(kept, msg)
+		}
+	}
+	return kept, dropped
+}
+
+// insertSummary inserts summary into kept where the earliest dropped turn
+// was.
+func insertSummary(kept []*Message, summary *Message, turns [][]int, drop []int) []*Message {
+	first := turns[drop[0]][0]
+	for _, turn := range drop {
+		first = min(first, turns[turn][0])
+	}
+	// Each message before first was kept
+	result := make([]*Message, 0, len(kept)+1)
+	result = append(result, kept[:first]...)
+	result = append(result, summary)
+	return append(result, kept[first:]...)
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// messageCounter counts 10 tokens per message.
+type messageCounter struct {
+	calls int
+}
+
+func (c *messageCounter) CountTokens(ctx context.Context, model string, messages []*Message, tools []Tool) (int, error) {
+	c.calls++
+	return 10 * len(messages), nil
+}
+
+// turnMessages returns a conversation of n turns of a user message and an
+// assistant reply, with texts "u0", "a0", "u1", ...
+func turnMessages(n int) []*Message {
+	var messages []*Message
+	for i := range n {
+		messages = append(messages,
+			NewUserTextMessage("u"+string(rune('0'+i))),
+			NewAssistantTextMessage("a"+string(rune('0'+i))))
+	}
+	return messages
+}
+
+func messageTexts(messages []*Message) string {
+	texts := make([]string, len(messages))
+	for i, msg := range messages {
+		texts[i] = msg.Text()
+	}
+	return strings.Join(texts, " ")
+}
+
+func TestTrimMessagesFits(t *testing.T) {
+	messages := turnMessages(2)
+	counter := &messageCounter{}
+	trimmed, err := TrimMessages(context.Background(), messages, 40, counter, TrimOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, messages, trimmed)
+	assert.Equal(t, 1, counter.calls)
+}
+
+func TestTrimMessagesDropOldestKeepsToolPairs(t *testing.T) {
+	messages := []*Message{
+		NewSystemMessage("system"),
+		NewUserTextMessage("u0"),
+		NewAssistantMessage(NewToolUseContent("call_1", "search", []byte(`{}`))),
+		NewToolResultMessage(NewToolResultContent("call_1", "found", false)),
+		NewAssistantTextMessage("a0"),
+		NewUserTextMessage("u1"),
+		NewAssistantTextMessage("a1"),
+		NewUserTextMessage("u2"),
+	}
+
+	// Dropping the first two messages of the first turn would fit, but
+	// the whole turn goes, so the tool call stays with its result
+	trimmed, err := TrimMessages(context.Background(), messages, 60, &messageCounter{}, TrimOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "system u1 a1 u2", messageTexts(trimmed))
+
+	trimmed, err = TrimMessages(context.Background(), messages, 20, &messageCounter{}, TrimOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "system u2", messageTexts(trimmed))
+}
+
+func TestTrimMessagesBudgetExceeded(t *testing.T) {
+	messages := append([]*Message{NewSystemMessage("system")}, turnMessages(3)...)
+	_, err := TrimMessages(context.Background(), messages, 40, &messageCounter{}, TrimOptions{KeepLastTurns: 2})
+	assert.True(t, errors.Is(err, ErrTrimBudgetExceeded))
+
+	trimmed, err := TrimMessages(context.Background(), messages, 50, &messageCounter{}, TrimOptions{KeepLastTurns: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "system u1 a1 u2 a2", messageTexts(trimmed))
+}
+
+func TestTrimMessagesMiddleOut(t *testing.T) {
+	messages := turnMessages(5)
+	trimmed, err := TrimMessages(context.Background(), messages, 60, &messageCounter{}, TrimOptions{Strategy: TrimMiddleOut})
+	assert.NoError(t, err)
+	assert.Equal(t, "u0 a0 u3 a3 u4 a4", messageTexts(trimmed))
+
+	trimmed, err = TrimMessages(context.Background(), messages, 20, &messageCounter{}, TrimOptions{Strategy: TrimMiddleOut})
+	assert.NoError(t, err)
+	assert.Equal(t, "u4 a4", messageTexts(trimmed))
+}
+
+func TestTrimOrderContiguous(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		order := trimOrder(n, TrimMiddleOut)
+		assert.Len(t, order, n)
+		low, high := order[0], order[0]
+		for _, turn := range order[1:] {
+			assert.True(t, turn == low-1 || turn == high+1)
+			low, high = min(low, turn), max(high, turn)
+		}
+	}
+}
+
+func TestTrimMessagesSummarize(t *testing.T) {
+	messages := append([]*Message{NewSystemMessage("system")}, turnMessages(4)...)
+	var summarized []string
+	trimmed, err := TrimMessages(context.Background(), messages, 60, &messageCounter{}, TrimOptions{
+		Summarize: func(ctx context.Context, dropped []*Message) (*Message, error) {
+			summarized = append(summarized, messageTexts(dropped))
+			return NewUserTextMessage("summary"), nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "system summary u2 a2 u3 a3", messageTexts(trimmed))
+	assert.Equal(t, []string{"u0 a0", "u0 a0 u1 a1"}, summarized)
+
+	sentinel := errors.New("summarizer failed")
+	_, err = TrimMessages(context.Background(), messages, 50, &messageCounter{}, TrimOptions{
+		Summarize: func(ctx context.Context, dropped []*Message) (*Message, error) {
+			return nil, sentinel
+		},
+	})
+	assert.True(t, errors.Is(err, sentinel))
+}
+
+func TestTrimMessagesEstimatesWithoutCounter(t *testing.T) {
+	messages := []*Message{
+		NewUserTextMessage(strings.Repeat("old context ", 500)),
+		NewAssistantTextMessage("ok"),
+		NewUserTextMessage("latest question"),
+	}
+	trimmed, err := TrimMessages(context.Background(), messages, 100, nil, TrimOptions{Model: "gpt-5"})
+	assert.NoError(t, err)
+	assert.Equal(t, "latest question", messageTexts(trimmed))
+}
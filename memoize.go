@@ -0,0 +1,190 @@
+package dive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ResponseCache stores responses under caller-chosen keys. See Memoize.
+type ResponseCache interface {
+	// Get returns the response stored under key, or false if there is none.
+	Get(ctx context.Context, key string) (*Response, bool, error)
+
+	// Put stores response under key, replacing any earlier response.
+	Put(ctx context.Context, key string, response *Response) error
+
+	// Delete removes the response stored under key. Deleting a missing key
+	// is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Memoize wraps generate so its responses are stored in store and reused for
+// later calls with the same key. The caller controls the key, so inputs that
+// are large or only partly relevant can be keyed on what actually determines
+// the result, such as a hash of a document's content (see ContentKey). Use it
+// for expensive, deterministic sub-tasks like summarizing a fixed document.
+//
+// Only completed responses are stored; errors and suspended responses are
+// returned without caching. To invalidate a result, delete its key from the
+// store.
+func Memoize[T any](
+	key func(input T) string,
+	store ResponseCache,
+	generate func(ctx context.Context, input T) (*Response, error),
+) func(ctx context.Context, input T) (*Response, error) {
+	return func(ctx context.Context, input T) (*Response, error) {
+		k := key(input)
+		cached, ok, err := store.Get(ctx, k)
+		if err != nil {
+			return nil, fmt.Errorf("memoize: get %q: %w", k, err)
+		}
+		if ok {
+			return cached, nil
+		}
+		response, err := generate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if response.Status == ResponseStatusSuspended {
+			return response, nil
+		}
+		if err := store.Put(ctx, k, response); err != nil {
+			return nil, fmt.Errorf("memoize: put %q: %w", k, err)
+		}
+		return response, nil
+	}
+}
+
+// ContentKey returns a hex SHA-256 digest of parts, for use as a Memoize key
+// that changes whenever the content it is derived from changes.
+func ContentKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// Length-prefix each part so ("ab", "c") and ("a", "bc") differ.
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MemoryResponseCache is a ResponseCache held in memory. Responses are
+// shared, not copied, so callers should not modify them. It is safe for
+// concurrent use.
+type MemoryResponseCache struct {
+	mu        sync.RWMutex
+	responses map[string]*Response
+}
+
+// NewMemoryResponseCache creates an empty MemoryResponseCache.
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return &MemoryResponseCache{responses: make(map[string]*Response)}
+}
+
+// Get implements ResponseCache.
+func (c *MemoryResponseCache) Get(ctx context.Context, key string) (*Response, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	response, ok := c.responses[key]
+	return response, ok, nil
+}
+
+// Put implements ResponseCache.
+func (c *MemoryResponseCache) Put(ctx context.Context, key string, response *Response) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[key] = response
+	return nil
+}
+
+// Delete implements ResponseCache.
+func (c *MemoryResponseCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.responses, key)
+	return nil
+}
+
+// FileResponseCache is a ResponseCache that persists responses as JSON files
+// in a directory, so results are reused across runs. Each key is stored in a
+// file named by the SHA-256 of the key.
+//
+// Only message and tool call items are persisted. Tool call results are not,
+// since they may hold Go errors; the results sent to the model are kept in
+// OutputMessages.
+type FileResponseCache struct {
+	dir string
+}
+
+// NewFileResponseCache creates a FileResponseCache rooted at dir, creating
+// the directory if it does not exist.
+func NewFileResponseCache(dir string) (*FileResponseCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileResponseCache{dir: dir}, nil
+}
+
+func (c *FileResponseCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements ResponseCache.
+func (c *FileResponseCache) Get(ctx context.Context, key string) (*Response, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, false, err
+	}
+	return &response, true, nil
+}
+
+// Put implements ResponseCache. The file is written to a temporary file and
+// renamed into place, so readers never see a partial response.
+func (c *FileResponseCache) Put(ctx context.Context, key string, response *Response) error {
+	persisted := *response
+	persisted.Items = nil
+	for _, item := range response.Items {
+		if item.Type == ResponseItemTypeMessage || item.Type == ResponseItemTypeToolCall {
+			persisted.Items = append(persisted.Items, item)
+		}
+	}
+	data, err := json.Marshal(&persisted)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, ".response-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Delete implements ResponseCache.
+func (c *FileResponseCache) Delete(ctx context.Context, key string) error {
+	err := os.Remove(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
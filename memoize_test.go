@@ -0,0 +1,86 @@
+package dive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestMemoize(t *testing.T) {
+	for name, newStore := range map[string]func(t *testing.T) ResponseCache{
+		"memory": func(t *testing.T) ResponseCache { return NewMemoryResponseCache() },
+		"file": func(t *testing.T) ResponseCache {
+			store, err := NewFileResponseCache(t.TempDir())
+			assert.NoError(t, err)
+			return store
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			agent, err := NewAgent(AgentOptions{Model: &mockLLM{
+				generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+					calls++
+					return &llm.Response{
+						Role:       llm.Assistant,
+						Content:    []llm.Content{&llm.TextContent{Text: "summary"}},
+						StopReason: llm.StopReasonEndTurn,
+					}, nil
+				},
+			}})
+			assert.NoError(t, err)
+
+			store := newStore(t)
+			summarize := Memoize(
+				func(doc string) string { return ContentKey("summarize", doc) },
+				store,
+				func(ctx context.Context, doc string) (*Response, error) {
+					return agent.CreateResponse(ctx, WithInput("Summarize: "+doc))
+				},
+			)
+
+			ctx := context.Background()
+			first, err := summarize(ctx, "the document")
+			assert.NoError(t, err)
+			second, err := summarize(ctx, "the document")
+			assert.NoError(t, err)
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, "summary", first.OutputText())
+			assert.Equal(t, "summary", second.OutputText())
+
+			// A different input has a different key
+			_, err = summarize(ctx, "another document")
+			assert.NoError(t, err)
+			assert.Equal(t, 2, calls)
+
+			// Invalidation by key regenerates the result
+			assert.NoError(t, store.Delete(ctx, ContentKey("summarize", "the document")))
+			_, err = summarize(ctx, "the document")
+			assert.NoError(t, err)
+			assert.Equal(t, 3, calls)
+		})
+	}
+}
+
+func TestMemoizeSkipsSuspended(t *testing.T) {
+	store := NewMemoryResponseCache()
+	generate := Memoize(
+		func(input string) string { return input },
+		store,
+		func(ctx context.Context, input string) (*Response, error) {
+			return &Response{Status: ResponseStatusSuspended}, nil
+		},
+	)
+	_, err := generate(context.Background(), "key")
+	assert.NoError(t, err)
+	_, ok, err := store.Get(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestContentKey(t *testing.T) {
+	assert.Equal(t, ContentKey("a", "b"), ContentKey("a", "b"))
+	assert.NotEqual(t, ContentKey("ab", "c"), ContentKey("a", "bc"))
+	assert.Len(t, ContentKey("x"), 64)
+}
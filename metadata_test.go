@@ -0,0 +1,58 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestWithMetadata(t *testing.T) {
+	var requests []string
+	model := &mockLLM{generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		cfg := &llm.Config{}
+		cfg.Apply(opts...)
+		data, err := json.Marshal(cfg)
+		assert.NoError(t, err)
+		requests = append(requests, string(data))
+		return &llm.Response{
+			Role:       llm.Assistant,
+			Content:    []llm.Content{&llm.TextContent{Text: "ok"}},
+			StopReason: llm.StopReasonEndTurn,
+		}, nil
+	}}
+	agent, err := NewAgent(AgentOptions{Model: model})
+	assert.NoError(t, err)
+
+	var events []*ResponseItem
+	resp, err := agent.CreateResponse(context.Background(),
+		WithInput("hello"),
+		WithMetadata(map[string]any{"request_id": "req-123", "user": "u-1"}),
+		WithMetadata(map[string]any{"user": "u-2"}),
+		WithEventCallback(func(ctx context.Context, item *ResponseItem) error {
+			events = append(events, item)
+			return nil
+		}))
+	assert.NoError(t, err)
+
+	want := map[string]any{"request_id": "req-123", "user": "u-2"}
+	assert.Equal(t, want, resp.Metadata)
+	assert.True(t, len(events) > 0)
+	for _, event := range events {
+		assert.Equal(t, want, event.Metadata)
+	}
+	for _, item := range resp.Items {
+		assert.Nil(t, item.Metadata)
+	}
+
+	// Metadata never reaches the model
+	assert.Len(t, requests, 1)
+	assert.False(t, strings.Contains(requests[0], "req-123"))
+
+	data, err := json.Marshal(resp)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"metadata":{"request_id":"req-123","user":"u-2"}`)
+}
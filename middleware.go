@@ -0,0 +1,41 @@
+package dive
+
+import "context"
+
+// ResponseFunc generates a response. Agent.CreateResponse has this
+// signature, and it is the function a ResponseMiddleware wraps.
+type ResponseFunc func(ctx context.Context, opts ...CreateResponseOption) (*Response, error)
+
+// ResponseMiddleware wraps every CreateResponse call on an agent. It
+// receives the next function in the chain and returns a function that
+// calls it, doing work before and after:
+//
+//	func logging(next dive.ResponseFunc) dive.ResponseFunc {
+//	    return func(ctx context.Context, opts ...dive.CreateResponseOption) (*dive.Response, error) {
+//	        start := time.Now()
+//	        resp, err := next(ctx, opts...)
+//	        log.Printf("response took %s (err=%v)", time.Since(start), err)
+//	        return resp, err
+//	    }
+//	}
+//
+// Unlike hooks, which run at fixed points inside the generation loop, a
+// middleware wraps the whole call: session loading, every LLM request and
+// retry, every tool call, and session saving. A middleware may add options
+// before calling next (e.g. WithMetadata to tag the request), change the
+// context, replace the response or error, or return without calling next.
+//
+// Set middleware with AgentOptions.Middleware. The first middleware is the
+// outermost, so it sees the call first and the result last.
+type ResponseMiddleware func(next ResponseFunc) ResponseFunc
+
+// chainResponseMiddleware wraps base in middleware so that middleware[0]
+// runs first. Nil entries are skipped.
+func chainResponseMiddleware(base ResponseFunc, middleware []ResponseMiddleware) ResponseFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			base = middleware[i](base)
+		}
+	}
+	return base
+}
@@ -0,0 +1,89 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func newMiddlewareTestAgent(t *testing.T, calls *int, middleware ...ResponseMiddleware) *Agent {
+	t.Helper()
+	agent, err := NewAgent(AgentOptions{
+		Model: &mockLLM{
+			generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+				*calls++
+				return &llm.Response{
+					Role:    llm.Assistant,
+					Content: []llm.Content{&llm.TextContent{Text: "ok"}},
+					Usage:   llm.Usage{InputTokens: 10, OutputTokens: 5},
+				}, nil
+			},
+		},
+		Middleware: middleware,
+	})
+	assert.NoError(t, err)
+	return agent
+}
+
+func TestResponseMiddleware_Order(t *testing.T) {
+	var order []string
+	record := func(name string) ResponseMiddleware {
+		return func(next ResponseFunc) ResponseFunc {
+			return func(ctx context.Context, opts ...CreateResponseOption) (*Response, error) {
+				order = append(order, name+" before")
+				resp, err := next(ctx, opts...)
+				order = append(order, name+" after")
+				return resp, err
+			}
+		}
+	}
+
+	var calls int
+	agent := newMiddlewareTestAgent(t, &calls, record("outer"), nil, record("inner"))
+	resp, err := agent.CreateResponse(context.Background(), WithInput("hi"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp.OutputText())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"outer before", "inner before", "inner after", "outer after"}, order)
+}
+
+func TestResponseMiddleware_AddsOptionsAndSeesUsage(t *testing.T) {
+	var usage *llm.Usage
+	tag := func(next ResponseFunc) ResponseFunc {
+		return func(ctx context.Context, opts ...CreateResponseOption) (*Response, error) {
+			opts = append(opts, WithMetadata(map[string]any{"tenant": "acme"}))
+			resp, err := next(ctx, opts...)
+			if resp != nil {
+				usage = resp.Usage
+			}
+			return resp, err
+		}
+	}
+
+	var calls int
+	agent := newMiddlewareTestAgent(t, &calls, tag)
+	resp, err := agent.CreateResponse(context.Background(), WithInput("hi"))
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", resp.Metadata["tenant"])
+	assert.NotNil(t, usage)
+	assert.Equal(t, 10, usage.InputTokens)
+	assert.Equal(t, 5, usage.OutputTokens)
+}
+
+func TestResponseMiddleware_ShortCircuit(t *testing.T) {
+	errBlocked := errors.New("blocked")
+	block := func(next ResponseFunc) ResponseFunc {
+		return func(ctx context.Context, opts ...CreateResponseOption) (*Response, error) {
+			return nil, errBlocked
+		}
+	}
+
+	var calls int
+	agent := newMiddlewareTestAgent(t, &calls, block)
+	_, err := agent.CreateResponse(context.Background(), WithInput("hi"))
+	assert.ErrorIs(t, err, errBlocked)
+	assert.Equal(t, 0, calls)
+}
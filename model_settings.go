@@ -23,6 +23,11 @@ type ModelSettings struct {
 	Features          []string
 	RequestHeaders    http.Header
 	MCPServers        []llm.MCPServerConfig
+
+	// ProviderOptions passes raw provider-specific request parameters. See
+	// llm.WithProviderOption.
+	ProviderOptions       map[llm.ProviderOptionKey]any
+	StrictProviderOptions bool
 }
 
 // Options returns the LLM options corresponding to the model settings.
@@ -76,5 +81,11 @@ func (m *ModelSettings) Options() []llm.Option {
 	if m.Caching != nil {
 		opts = append(opts, llm.WithCaching(*m.Caching))
 	}
+	for key, value := range m.ProviderOptions {
+		opts = append(opts, llm.WithProviderOption(key, value))
+	}
+	if m.StrictProviderOptions {
+		opts = append(opts, llm.WithStrictProviderOptions(true))
+	}
 	return opts
 }
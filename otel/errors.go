@@ -32,9 +32,18 @@ func classifyChatError(err error) string {
 		return ""
 	}
 
-	// HTTP status code from the provider — most authoritative signal.
+	// The provider error's kind, then its HTTP status code — the most
+	// authoritative signals.
 	var perr *providers.ProviderError
 	if errors.As(err, &perr) {
+		switch {
+		case errors.Is(err, providers.ErrContextLengthExceeded):
+			return errTypeContextLength
+		case errors.Is(err, providers.ErrInvalidAPIKey), errors.Is(err, providers.ErrQuotaExceeded):
+			return errTypeAuth
+		case errors.Is(err, providers.ErrRateLimited):
+			return errTypeRateLimit
+		}
 		switch perr.StatusCode() {
 		case 401, 403:
 			return errTypeAuth
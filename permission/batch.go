@@ -0,0 +1,93 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// EvaluateToolBatch evaluates a turn's tool calls together. Calls that rules,
+// session grants, or the mode decide are settled immediately; all calls that
+// need confirmation are presented in a single batch dialog (see
+// dive.DialogInput.Items), so the user approves or denies them in one
+// interaction. A batch with one pending call uses a plain confirmation
+// dialog.
+//
+// Decisions are recorded per call ID and returned by the next
+// EvaluateToolUse for that call, so the per-call hook applies them without
+// prompting again. Calls with no recorded decision are evaluated normally.
+func (pm *Manager) EvaluateToolBatch(ctx context.Context, calls []dive.ToolBatchCall) error {
+	var pending []dive.ToolBatchCall
+	var items []*dive.DialogInput
+	for _, bc := range calls {
+		if bc.Tool == nil || bc.Call == nil || bc.Call.ID == "" {
+			continue
+		}
+		confirm, message, err := pm.evaluate(bc.Tool, bc.Call)
+		if err != nil || !confirm || pm.dialog == nil {
+			pm.recordBatchDecision(bc.Call, err)
+			continue
+		}
+		pending = append(pending, bc)
+		items = append(items, confirmInput(bc.Tool, bc.Call, message))
+	}
+
+	switch len(items) {
+	case 0:
+		return nil
+	case 1:
+		output, err := pm.dialog.Show(ctx, items[0])
+		if err != nil {
+			pm.recordBatchDecision(pending[0].Call, err)
+			return err
+		}
+		pm.recordBatchDecision(pending[0].Call, pm.applyConfirmation(pending[0].Tool, pending[0].Call, output))
+		return nil
+	}
+
+	output, err := pm.dialog.Show(ctx, &dive.DialogInput{
+		Title:   "Confirm tool calls",
+		Message: fmt.Sprintf("The agent wants to make %d tool calls.", len(items)),
+		Items:   items,
+	})
+	if err != nil {
+		for _, bc := range pending {
+			pm.recordBatchDecision(bc.Call, err)
+		}
+		return err
+	}
+	for i, bc := range pending {
+		var itemOutput *dive.DialogOutput
+		if i < len(output.Items) {
+			itemOutput = output.Items[i]
+		}
+		pm.recordBatchDecision(bc.Call, pm.applyConfirmation(bc.Tool, bc.Call, itemOutput))
+	}
+	return nil
+}
+
+func (pm *Manager) recordBatchDecision(call *llm.ToolUseContent, err error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.batchDecisions == nil {
+		pm.batchDecisions = make(map[string]error)
+	}
+	pm.batchDecisions[call.ID] = err
+}
+
+// takeBatchDecision returns and removes the decision recorded for call by
+// EvaluateToolBatch.
+func (pm *Manager) takeBatchDecision(call *llm.ToolUseContent) (error, bool) {
+	if call == nil || call.ID == "" {
+		return nil, false
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	err, ok := pm.batchDecisions[call.ID]
+	if ok {
+		delete(pm.batchDecisions, call.ID)
+	}
+	return err, ok
+}
@@ -0,0 +1,137 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func batchCall(id, toolName, input string) dive.ToolBatchCall {
+	return dive.ToolBatchCall{
+		Tool: &mockTool{name: toolName},
+		Call: &llm.ToolUseContent{ID: id, Name: toolName, Input: []byte(input)},
+	}
+}
+
+func TestEvaluateToolBatch(t *testing.T) {
+	t.Run("pending confirmations share one dialog", func(t *testing.T) {
+		var shown []*dive.DialogInput
+		dialog := &testDialog{showFunc: func(ctx context.Context, in *dive.DialogInput) (*dive.DialogOutput, error) {
+			shown = append(shown, in)
+			return &dive.DialogOutput{Items: []*dive.DialogOutput{
+				{Confirmed: true},
+				{Confirmed: false},
+				{Feedback: "use the read tool"},
+			}}, nil
+		}}
+		manager := NewManager(&Config{
+			Rules: Rules{
+				AllowRule("Read"),
+				DenyRule("Delete", "deletes are blocked"),
+				AskRule("Bash", "Run command?"),
+			},
+		}, dialog)
+
+		calls := []dive.ToolBatchCall{
+			batchCall("1", "Read", `{}`),
+			batchCall("2", "Bash", `{"command": "ls"}`),
+			batchCall("3", "Delete", `{}`),
+			batchCall("4", "Write", `{}`),
+			batchCall("5", "Bash", `{"command": "cat x"}`),
+		}
+		err := manager.EvaluateToolBatch(context.Background(), calls)
+		assert.NoError(t, err)
+
+		assert.Len(t, shown, 1)
+		assert.Len(t, shown[0].Items, 3)
+		assert.Equal(t, "2", shown[0].Items[0].Call.ID)
+		assert.Equal(t, "Run command?", shown[0].Items[0].Message)
+		assert.True(t, shown[0].Items[0].Confirm)
+		assert.Equal(t, "4", shown[0].Items[1].Call.ID)
+		assert.Equal(t, "5", shown[0].Items[2].Call.ID)
+
+		// Per-call evaluation applies the recorded decisions without
+		// prompting again
+		ctx := context.Background()
+		assert.NoError(t, manager.EvaluateToolUse(ctx, calls[0].Tool, calls[0].Call))
+		assert.NoError(t, manager.EvaluateToolUse(ctx, calls[1].Tool, calls[1].Call))
+		err = manager.EvaluateToolUse(ctx, calls[2].Tool, calls[2].Call)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "deletes are blocked")
+		err = manager.EvaluateToolUse(ctx, calls[3].Tool, calls[3].Call)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "user denied")
+		feedback, ok := dive.IsUserFeedback(manager.EvaluateToolUse(ctx, calls[4].Tool, calls[4].Call))
+		assert.True(t, ok)
+		assert.Equal(t, "use the read tool", feedback)
+		assert.Len(t, shown, 1)
+	})
+
+	t.Run("decisions are used once", func(t *testing.T) {
+		prompts := 0
+		dialog := &testDialog{showFunc: func(ctx context.Context, in *dive.DialogInput) (*dive.DialogOutput, error) {
+			prompts++
+			return &dive.DialogOutput{Confirmed: true}, nil
+		}}
+		manager := NewManager(nil, dialog)
+		call := batchCall("1", "Write", `{}`)
+
+		assert.NoError(t, manager.EvaluateToolBatch(context.Background(), []dive.ToolBatchCall{call}))
+		assert.Equal(t, 1, prompts)
+		assert.NoError(t, manager.EvaluateToolUse(context.Background(), call.Tool, call.Call))
+		assert.Equal(t, 1, prompts)
+		assert.NoError(t, manager.EvaluateToolUse(context.Background(), call.Tool, call.Call))
+		assert.Equal(t, 2, prompts)
+	})
+
+	t.Run("missing answers deny", func(t *testing.T) {
+		dialog := &testDialog{showFunc: func(ctx context.Context, in *dive.DialogInput) (*dive.DialogOutput, error) {
+			return &dive.DialogOutput{Items: []*dive.DialogOutput{{AllowSession: true}}}, nil
+		}}
+		manager := NewManager(nil, dialog)
+		calls := []dive.ToolBatchCall{
+			batchCall("1", "Bash", `{"command": "ls"}`),
+			batchCall("2", "Bash", `{"command": "pwd"}`),
+		}
+		assert.NoError(t, manager.EvaluateToolBatch(context.Background(), calls))
+
+		assert.NoError(t, manager.EvaluateToolUse(context.Background(), calls[0].Tool, calls[0].Call))
+		assert.Error(t, manager.EvaluateToolUse(context.Background(), calls[1].Tool, calls[1].Call))
+
+		// AllowSession on a batch item records a session grant
+		again := batchCall("3", "Bash", `{"command": "ls"}`)
+		assert.NoError(t, manager.EvaluateToolUse(context.Background(), again.Tool, again.Call))
+	})
+
+	t.Run("dialog error denies pending calls", func(t *testing.T) {
+		dialog := &testDialog{showFunc: func(ctx context.Context, in *dive.DialogInput) (*dive.DialogOutput, error) {
+			return nil, fmt.Errorf("ui closed")
+		}}
+		manager := NewManager(nil, dialog)
+		calls := []dive.ToolBatchCall{batchCall("1", "Write", `{}`), batchCall("2", "Edit", `{}`)}
+
+		err := manager.EvaluateToolBatch(context.Background(), calls)
+		assert.Error(t, err)
+		err = manager.EvaluateToolUse(context.Background(), calls[1].Tool, calls[1].Call)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ui closed")
+	})
+}
+
+func TestBatchHookWithAutoApproveDialog(t *testing.T) {
+	manager := NewManager(nil, &dive.AutoApproveDialog{})
+	hook := BatchHookFromManager(manager)
+	calls := []dive.ToolBatchCall{batchCall("1", "Write", `{}`), batchCall("2", "Edit", `{}`)}
+
+	err := hook(context.Background(), &dive.HookContext{ToolBatch: calls})
+	assert.NoError(t, err)
+	for _, bc := range calls {
+		decision, ok := manager.takeBatchDecision(bc.Call)
+		assert.True(t, ok)
+		assert.NoError(t, decision)
+	}
+}
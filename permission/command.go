@@ -5,16 +5,21 @@ import (
 )
 
 // SplitCommand splits a shell command into segments separated by unquoted
-// control operators: ;, &&, ||, |, &, and newlines. Quoting is respected:
-// operators inside single or double quotes (or escaped with a backslash) do
-// not split. The returned hasSubstitution is true when the command contains
-// command or process substitution outside single quotes ($(...), backticks,
-// <(...), >(...)), which means parts of the command cannot be validated by
-// pattern matching.
+// control operators: ;, &&, ||, |, |&, &, and newlines. Quoting is
+// respected: operators inside single or double quotes (or escaped with a
+// backslash) do not split, and the & of a redirection such as 2>&1, <&3,
+// or &>file doesn't either. The bodies of here-documents are data, not
+// commands, so they are left out of the segments. The returned
+// hasSubstitution is true when the command contains command or process
+// substitution outside single quotes ($(...), backticks, <(...), >(...)),
+// including in the body of a here-document with an unquoted delimiter,
+// which means parts of the command cannot be validated by pattern
+// matching.
 func SplitCommand(command string) (segments []string, hasSubstitution bool) {
 	var cur strings.Builder
 	inSingle := false
 	inDouble := false
+	var heredocs []heredoc
 	flush := func() {
 		s := strings.TrimSpace(cur.String())
 		if s != "" {
@@ -57,24 +62,58 @@ func SplitCommand(command string) (segments []string, hasSubstitution bool) {
 			}
 			cur.WriteByte(c)
 		case '<', '>':
-			if !inDouble && i+1 < len(command) && command[i+1] == '(' {
+			if inDouble {
+				cur.WriteByte(c)
+				continue
+			}
+			if i+1 < len(command) && command[i+1] == '(' {
 				hasSubstitution = true
 			}
+			if strings.HasPrefix(command[i:], "<<<") {
+				// A here-string's word is an argument, not a command
+				cur.WriteString("<<<")
+				i += 2
+				continue
+			}
+			if strings.HasPrefix(command[i:], "<<") {
+				doc, end := parseHeredoc(command, i+2)
+				heredocs = append(heredocs, doc)
+				cur.WriteString(command[i:end])
+				i = end - 1
+				continue
+			}
 			cur.WriteByte(c)
+			// >&2 and <&3 duplicate descriptors rather than run in the
+			// background
+			if i+1 < len(command) && command[i+1] == '&' {
+				i++
+				cur.WriteByte('&')
+			}
 		case ';', '\n':
 			if inDouble {
 				cur.WriteByte(c)
 				continue
 			}
 			flush()
+			if c == '\n' && len(heredocs) > 0 {
+				end, sub := skipHeredocBodies(command, i+1, heredocs)
+				hasSubstitution = hasSubstitution || sub
+				heredocs = nil
+				i = end - 1
+			}
 		case '&', '|':
 			if inDouble {
 				cur.WriteByte(c)
 				continue
 			}
+			if c == '&' && i+1 < len(command) && command[i+1] == '>' {
+				// &> and &>> redirect both output streams
+				cur.WriteByte(c)
+				continue
+			}
 			flush()
-			if i+1 < len(command) && command[i+1] == c {
-				i++ // skip the second char of && or ||
+			if i+1 < len(command) && (command[i+1] == c || c == '|' && command[i+1] == '&') {
+				i++ // skip the second char of &&, ||, or |&
 			}
 		default:
 			cur.WriteByte(c)
@@ -84,6 +123,87 @@ func SplitCommand(command string) (segments []string, hasSubstitution bool) {
 	return segments, hasSubstitution
 }
 
+// heredoc is a here-document whose body follows the next newline.
+type heredoc struct {
+	delimiter string
+	// stripTabs is set for <<-, which strips leading tabs from body lines
+	stripTabs bool
+	// quoted is set when any part of the delimiter is quoted, so the body
+	// isn't expanded
+	quoted bool
+}
+
+// parseHeredoc parses the delimiter of a here-document whose << operator
+// ends just before start. It returns the here-document and the index just
+// past its delimiter word.
+func parseHeredoc(command string, start int) (heredoc, int) {
+	var doc heredoc
+	i := start
+	if i < len(command) && command[i] == '-' {
+		doc.stripTabs = true
+		i++
+	}
+	for i < len(command) && (command[i] == ' ' || command[i] == '\t') {
+		i++
+	}
+	var delimiter strings.Builder
+	var quote byte
+	for ; i < len(command); i++ {
+		c := command[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			} else {
+				delimiter.WriteByte(c)
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"':
+			quote, doc.quoted = c, true
+		case c == '\\' && i+1 < len(command):
+			doc.quoted = true
+			i++
+			delimiter.WriteByte(command[i])
+		case strings.IndexByte(" \t\n;&|<>()", c) >= 0:
+			doc.delimiter = delimiter.String()
+			return doc, i
+		default:
+			delimiter.WriteByte(c)
+		}
+	}
+	doc.delimiter = delimiter.String()
+	return doc, i
+}
+
+// skipHeredocBodies skips the bodies of heredocs, in order, starting at
+// start, the beginning of the line after their operators. It returns the
+// index just past the last body's delimiter line, and whether an expanded
+// body contains command substitution.
+func skipHeredocBodies(command string, start int, heredocs []heredoc) (int, bool) {
+	hasSubstitution := false
+	i := start
+	for _, doc := range heredocs {
+		for i < len(command) {
+			line, _, found := strings.Cut(command[i:], "\n")
+			i += len(line)
+			if found {
+				i++
+			}
+			if doc.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == doc.delimiter {
+				break
+			}
+			if !doc.quoted && (strings.Contains(line, "$(") || strings.Contains(line, "`")) {
+				hasSubstitution = true
+			}
+		}
+	}
+	return i, hasSubstitution
+}
+
 // MatchCommandAllow reports whether a shell command is authorized by an
 // allow-side specifier pattern. The command is split on unquoted shell
 // control operators and EVERY segment must match the pattern; a compound
@@ -46,6 +46,25 @@ func HookFromManager(manager *Manager) dive.PreToolUseHook {
 	}
 }
 
+// BatchHookFromManager returns a PreToolBatchHook that collects the
+// confirmations for all of a turn's tool calls into one batch dialog (see
+// Manager.EvaluateToolBatch). Register it together with HookFromManager for
+// the same manager, which applies the recorded decisions per call:
+//
+//	manager := permission.NewManager(config, dialog)
+//	hooks := dive.Hooks{
+//	    PreToolBatch: []dive.PreToolBatchHook{permission.BatchHookFromManager(manager)},
+//	    PreToolUse:   []dive.PreToolUseHook{permission.HookFromManager(manager)},
+//	}
+//
+// The dialog receives a DialogInput with Items set, one confirmation per
+// pending call, and must answer with one DialogOutput item per input item.
+func BatchHookFromManager(manager *Manager) dive.PreToolBatchHook {
+	return func(ctx context.Context, hookCtx *dive.HookContext) error {
+		return manager.EvaluateToolBatch(ctx, hookCtx.ToolBatch)
+	}
+}
+
 // AuditHook returns a PreToolUseHook that logs all tool calls without making
 // permission decisions.
 //
@@ -138,6 +138,7 @@ type Manager struct {
 	dialog         dive.Dialog
 	sessionAllowed map[string]bool
 	sessionGrants  []sessionGrant
+	batchDecisions map[string]error
 }
 
 // NewManager creates a new permission manager.
@@ -149,6 +150,7 @@ func NewManager(config *Config, dialog dive.Dialog) *Manager {
 		config:         config,
 		dialog:         dialog,
 		sessionAllowed: make(map[string]bool),
+		batchDecisions: make(map[string]error),
 	}
 }
 
@@ -173,6 +175,20 @@ func (pm *Manager) EvaluateToolUse(
 	tool dive.Tool,
 	call *llm.ToolUseContent,
 ) error {
+	if err, ok := pm.takeBatchDecision(call); ok {
+		return err
+	}
+	confirm, message, err := pm.evaluate(tool, call)
+	if err != nil || !confirm {
+		return err
+	}
+	return pm.confirm(ctx, tool, call, message)
+}
+
+// evaluate applies rules, session grants, and the permission mode to a tool
+// call. It returns an error if the call is denied, or confirm=true with the
+// rule's message if the user must be asked.
+func (pm *Manager) evaluate(tool dive.Tool, call *llm.ToolUseContent) (confirm bool, message string, err error) {
 	denyRules, allowRules, askRules := pm.partitionRules()
 
 	var toolName string
@@ -188,26 +204,26 @@ func (pm *Manager) EvaluateToolUse(
 				if msg == "" {
 					msg = "denied by rule " + rule.String()
 				}
-				return fmt.Errorf("%s", msg)
+				return false, "", fmt.Errorf("%s", msg)
 			}
 		}
 	}
 
 	// Check session allowlist
 	if tool != nil && pm.isSessionAllowed(toolName, call) {
-		return nil
+		return false, "", nil
 	}
 
 	// Check allow and ask rules
 	if tool != nil && call != nil {
 		for _, rule := range allowRules {
 			if pm.matchRule(rule, toolName, call) {
-				return nil
+				return false, "", nil
 			}
 		}
 		for _, rule := range askRules {
 			if pm.matchRule(rule, toolName, call) {
-				return pm.confirm(ctx, tool, call, rule.Message)
+				return true, rule.Message, nil
 			}
 		}
 	}
@@ -216,13 +232,13 @@ func (pm *Manager) EvaluateToolUse(
 	d, msg := pm.evaluateMode(tool, call)
 	switch d {
 	case deny:
-		return fmt.Errorf("%s", msg)
+		return false, "", fmt.Errorf("%s", msg)
 	case allow:
-		return nil
+		return false, "", nil
 	}
 
 	// Default: ask for confirmation
-	return pm.confirm(ctx, tool, call, "")
+	return true, "", nil
 }
 
 func (pm *Manager) partitionRules() (denyRules, allowRules, askRules Rules) {
@@ -376,15 +392,29 @@ func (pm *Manager) confirm(
 	if pm.dialog == nil {
 		return nil // no dialog = auto-allow
 	}
-	output, err := pm.dialog.Show(ctx, &dive.DialogInput{
+	output, err := pm.dialog.Show(ctx, confirmInput(tool, call, message))
+	if err != nil {
+		return err
+	}
+	return pm.applyConfirmation(tool, call, output)
+}
+
+// confirmInput builds the confirmation dialog for a tool call.
+func confirmInput(tool dive.Tool, call *llm.ToolUseContent, message string) *dive.DialogInput {
+	return &dive.DialogInput{
 		Confirm: true,
 		Title:   tool.Name(),
 		Message: message,
 		Tool:    tool,
 		Call:    call,
-	})
-	if err != nil {
-		return err
+	}
+}
+
+// applyConfirmation turns the user's answer to a confirmation dialog into a
+// decision, recording a session grant if the user asked for one.
+func (pm *Manager) applyConfirmation(tool dive.Tool, call *llm.ToolUseContent, output *dive.DialogOutput) error {
+	if output == nil {
+		return fmt.Errorf("user denied tool call")
 	}
 	if output.AllowSession {
 		pm.grantSessionFromCall(tool, call)
@@ -584,6 +614,19 @@ func GetToolCategory(toolName string) Category {
 	return Category{Key: toolName, Label: toolName + " operations"}
 }
 
+// ToolCatalog returns agent.ToolCatalog with each tool's permission category
+// filled in.
+func ToolCatalog(ctx context.Context, agent *dive.Agent) ([]dive.ToolSpec, error) {
+	specs, err := agent.ToolCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range specs {
+		specs[i].Category = GetToolCategory(specs[i].Name).Key
+	}
+	return specs, nil
+}
+
 // DefaultSpecifierFields maps tool names to functions that extract the
 // specifier value from the tool call input. These are used when
 // Config.SpecifierFields does not have an entry for the tool.
@@ -600,6 +643,9 @@ var DefaultSpecifierFields = map[string]SpecifierFieldFunc{
 	"Edit": func(input json.RawMessage) string {
 		return jsonStringField(input, "file_path", "filePath", "path")
 	},
+	"MultiEdit": func(input json.RawMessage) string {
+		return jsonStringField(input, "file_path", "filePath", "path")
+	},
 	"WebFetch": func(input json.RawMessage) string {
 		return jsonStringField(input, "url")
 	},
@@ -612,18 +658,19 @@ var DefaultSpecifierFields = map[string]SpecifierFieldFunc{
 //   - Bash: command-aware matching. Allow rules require every shell segment
 //     to match and reject command substitution; deny rules match if the full
 //     command or any segment matches. See MatchCommandAllow/MatchCommandDeny.
-//   - Read/Write/Edit: paths are cleaned (so "/safe/../etc" cannot evade a
-//     rule) and matched with MatchPath, where * stays within one path segment
-//     and ** crosses segments. Deny rules also match the absolutized form of
-//     relative paths.
+//   - Read/Write/Edit/MultiEdit: paths are cleaned (so "/safe/../etc" cannot
+//     evade a rule) and matched with MatchPath, where * stays within one path
+//     segment and ** crosses segments. Deny rules also match the absolutized
+//     form of relative paths.
 //   - WebFetch: domain-aware matching via MatchURLSpecifier ("domain:x.com"
 //     or a bare domain matches the host; other patterns glob the full URL).
 var DefaultSpecifierMatchers = map[string]SpecifierMatcherFunc{
-	"Bash":     matchCommandSpecifier,
-	"Read":     matchPathSpecifier,
-	"Write":    matchPathSpecifier,
-	"Edit":     matchPathSpecifier,
-	"WebFetch": matchURLSpecifier,
+	"Bash":      matchCommandSpecifier,
+	"Read":      matchPathSpecifier,
+	"Write":     matchPathSpecifier,
+	"Edit":      matchPathSpecifier,
+	"MultiEdit": matchPathSpecifier,
+	"WebFetch":  matchURLSpecifier,
 }
 
 func matchCommandSpecifier(ruleType RuleType, pattern, command string) bool {
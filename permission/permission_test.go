@@ -1591,3 +1591,25 @@ func TestIntegrationFlow(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestToolCatalog(t *testing.T) {
+	agent, err := dive.NewAgent(dive.AgentOptions{
+		Model: catalogLLM{},
+		Tools: []dive.Tool{&mockTool{name: "Bash"}, &mockTool{name: "Read"}, &mockTool{name: "Deploy"}},
+	})
+	assert.NoError(t, err)
+
+	specs, err := ToolCatalog(context.Background(), agent)
+	assert.NoError(t, err)
+	assert.Len(t, specs, 3)
+	assert.Equal(t, "bash", specs[0].Category)
+	assert.Equal(t, "read", specs[1].Category)
+	assert.Equal(t, "Deploy", specs[2].Category)
+}
+
+type catalogLLM struct{}
+
+func (catalogLLM) Name() string { return "catalog" }
+func (catalogLLM) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	return nil, fmt.Errorf("not implemented")
+}
@@ -358,6 +358,16 @@ func TestSplitCommand(t *testing.T) {
 		{"echo '$(whoami)'", []string{"echo '$(whoami)'"}, false},
 		{"diff <(ls a) b", []string{"diff <(ls a) b"}, true},
 		{"echo $HOME", []string{"echo $HOME"}, false},
+		{"go test ./... 2>&1", []string{"go test ./... 2>&1"}, false},
+		{"go test ./... 2>&1 | head -50", []string{"go test ./... 2>&1", "head -50"}, false},
+		{"echo oops >&2 && cat <&3", []string{"echo oops >&2", "cat <&3"}, false},
+		{"make &> build.log &", []string{"make &> build.log"}, false},
+		{"make |& tee log", []string{"make", "tee log"}, false},
+		{"cat <<EOF\nhi; rm x\nEOF\nls", []string{"cat <<EOF", "ls"}, false},
+		{"cat <<-'END' > out\n\thi $(whoami)\n\tEND", []string{"cat <<-'END' > out"}, false},
+		{"cat <<EOF\nhi $(whoami)\nEOF", []string{"cat <<EOF"}, true},
+		{"cat <<A <<B\na\nA\nb\nB\necho done", []string{"cat <<A <<B", "echo done"}, false},
+		{"grep x <<< 'a b'", []string{"grep x <<< 'a b'"}, false},
 		{"", nil, false},
 		{"  ;  ; ", nil, false},
 	}
@@ -0,0 +1,245 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// projectInstructionFiles are the instruction files looked for in each
+// directory, highest precedence first.
+var projectInstructionFiles = []string{
+	filepath.Join(".dive", "instructions.md"),
+	"AGENTS.md",
+	"CLAUDE.md",
+}
+
+// ProjectInstructions are the instruction files that apply to a directory,
+// as found by LoadProjectInstructions.
+type ProjectInstructions struct {
+	// Root is the project root: the nearest ancestor of the directory,
+	// or the directory itself, that contains a .git entry. Without one,
+	// Root is the directory.
+	Root string
+
+	// Sources are the loaded files, from Root down to the directory.
+	Sources []*InstructionSource
+}
+
+// InstructionSource is one loaded instruction file.
+type InstructionSource struct {
+	// Path is the absolute path of the file.
+	Path string
+
+	// RelPath is the path relative to the project root, with forward
+	// slashes, such as "AGENTS.md" or "pkg/api/AGENTS.md".
+	RelPath string
+
+	Content string
+	ModTime time.Time
+}
+
+// Text renders the instructions for the system prompt, each file in a
+// <project_instructions> block tagged with its path. It returns "" when no
+// files were found.
+func (p *ProjectInstructions) Text() string {
+	if p == nil || len(p.Sources) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Follow these project instructions. Instructions in deeper directories are more specific and take precedence.\n")
+	for _, source := range p.Sources {
+		fmt.Fprintf(&sb, "\n<project_instructions path=%q>\n%s\n</project_instructions>\n",
+			source.RelPath, strings.TrimSpace(source.Content))
+	}
+	return sb.String()
+}
+
+// LoadProjectInstructions finds and reads the project instruction files
+// that apply to dir, for apps that inject them into the system prompt:
+//
+//	instructions, err := dive.LoadProjectInstructions(workspaceDir)
+//	systemPrompt := basePrompt + "\n\n" + instructions.Text()
+//
+// Each directory from the project root down to dir may contribute one file.
+// The first of .dive/instructions.md, AGENTS.md, and CLAUDE.md that exists
+// in a directory is loaded and the others there are ignored, so a CLAUDE.md
+// kept next to an AGENTS.md for other tools isn't included twice. Files
+// from nested directories are merged in order, root first, so the most
+// specific instructions come last. Empty files are skipped.
+//
+// Use a ProjectInstructionsLoader to cache the result or watch for changes.
+func LoadProjectInstructions(dir string) (*ProjectInstructions, error) {
+	return NewProjectInstructionsLoader().Load(dir)
+}
+
+// ProjectInstructionsLoader loads project instructions like
+// LoadProjectInstructions and caches them per directory. A cached result is
+// reused until one of the instruction files is added, removed, or modified,
+// which is detected without rereading the files. It is safe for concurrent
+// use. Callers must not modify the returned instructions.
+type ProjectInstructionsLoader struct {
+	mu    sync.Mutex
+	cache map[string]cachedProjectInstructions
+}
+
+type cachedProjectInstructions struct {
+	stamp        string
+	instructions *ProjectInstructions
+}
+
+// NewProjectInstructionsLoader returns an empty loader.
+func NewProjectInstructionsLoader() *ProjectInstructionsLoader {
+	return &ProjectInstructionsLoader{cache: map[string]cachedProjectInstructions{}}
+}
+
+// Load returns the instructions that apply to dir, from the cache when
+// none of the files changed.
+func (l *ProjectInstructionsLoader) Load(dir string) (*ProjectInstructions, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root, files, err := findProjectInstructionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	stamp := projectInstructionsStamp(files)
+
+	l.mu.Lock()
+	cached, ok := l.cache[dir]
+	l.mu.Unlock()
+	if ok && cached.stamp == stamp {
+		return cached.instructions, nil
+	}
+
+	instructions := &ProjectInstructions{Root: root}
+	for _, file := range files {
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			return nil, fmt.Errorf("read project instructions: %w", err)
+		}
+		if strings.TrimSpace(string(data)) == "" {
+			continue
+		}
+		rel, err := filepath.Rel(root, file.path)
+		if err != nil {
+			return nil, err
+		}
+		instructions.Sources = append(instructions.Sources, &InstructionSource{
+			Path:    file.path,
+			RelPath: filepath.ToSlash(rel),
+			Content: string(data),
+			ModTime: file.info.ModTime(),
+		})
+	}
+
+	l.mu.Lock()
+	l.cache[dir] = cachedProjectInstructions{stamp: stamp, instructions: instructions}
+	l.mu.Unlock()
+	return instructions, nil
+}
+
+// Watch checks the instructions for dir every interval until ctx is done,
+// calling onChange with the new instructions after any file is added,
+// removed, or modified, or with the error if loading fails. It loads the
+// instructions once before watching and returns that error, if any, without
+// watching. Otherwise it returns when ctx is done. The interval must be
+// positive.
+func (l *ProjectInstructionsLoader) Watch(ctx context.Context, dir string, interval time.Duration, onChange func(*ProjectInstructions, error)) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", interval)
+	}
+	current, err := l.Load(dir)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			next, err := l.Load(dir)
+			if err != nil {
+				onChange(nil, err)
+				continue
+			}
+			if next != current {
+				current = next
+				onChange(next, nil)
+			}
+		}
+	}
+}
+
+// projectInstructionFile is an instruction file found on disk.
+type projectInstructionFile struct {
+	path string
+	info fs.FileInfo
+}
+
+// findProjectInstructionFiles returns the project root for dir and the
+// highest-precedence instruction file in each directory from the root down
+// to dir.
+func findProjectInstructionFiles(dir string) (string, []projectInstructionFile, error) {
+	root := findProjectRoot(dir)
+	dirs := []string{root}
+	if rel, err := filepath.Rel(root, dir); err == nil && rel != "." {
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			dirs = append(dirs, filepath.Join(dirs[len(dirs)-1], part))
+		}
+	}
+
+	var files []projectInstructionFile
+	for _, d := range dirs {
+		for _, name := range projectInstructionFiles {
+			path := filepath.Join(d, name)
+			info, err := os.Stat(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return "", nil, fmt.Errorf("find project instructions: %w", err)
+			}
+			if !info.Mode().IsRegular() {
+				continue
+			}
+			files = append(files, projectInstructionFile{path: path, info: info})
+			break
+		}
+	}
+	return root, files, nil
+}
+
+// findProjectRoot returns the nearest ancestor of dir, or dir itself, that
+// contains a .git entry, or dir if there is none.
+func findProjectRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// projectInstructionsStamp identifies a set of instruction files by path,
+// size, and modification time, to detect changes without reading them.
+func projectInstructionsStamp(files []projectInstructionFile) string {
+	var sb strings.Builder
+	for _, file := range files {
+		fmt.Fprintf(&sb, "%s\x00%d\x00%d\n", file.path, file.info.Size(), file.info.ModTime().UnixNano())
+	}
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+package dive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestLoadProjectInstructions(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0o755))
+	writeTestFile(t, filepath.Join(root, "AGENTS.md"), "Use tabs.")
+	writeTestFile(t, filepath.Join(root, "CLAUDE.md"), "Ignored: AGENTS.md takes precedence.")
+	writeTestFile(t, filepath.Join(root, "pkg", "CLAUDE.md"), "Package notes.")
+	writeTestFile(t, filepath.Join(root, "pkg", "api", ".dive", "instructions.md"), "API rules.")
+	writeTestFile(t, filepath.Join(root, "pkg", "api", "AGENTS.md"), "Ignored: .dive/instructions.md takes precedence.")
+	writeTestFile(t, filepath.Join(root, "pkg", "other", "AGENTS.md"), "Not on the path.")
+
+	instructions, err := LoadProjectInstructions(filepath.Join(root, "pkg", "api"))
+	assert.NoError(t, err)
+	assert.Equal(t, root, instructions.Root)
+	var paths, contents []string
+	for _, source := range instructions.Sources {
+		paths = append(paths, source.RelPath)
+		contents = append(contents, source.Content)
+	}
+	assert.Equal(t, []string{"AGENTS.md", "pkg/CLAUDE.md", "pkg/api/.dive/instructions.md"}, paths)
+	assert.Equal(t, []string{"Use tabs.", "Package notes.", "API rules."}, contents)
+	assert.Equal(t, filepath.Join(root, "pkg", "CLAUDE.md"), instructions.Sources[1].Path)
+
+	text := instructions.Text()
+	assert.Contains(t, text, "<project_instructions path=\"pkg/api/.dive/instructions.md\">\nAPI rules.\n</project_instructions>")
+	assert.True(t, strings.Index(text, "Use tabs.") < strings.Index(text, "API rules."))
+}
+
+func TestLoadProjectInstructionsWithoutProjectRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "CLAUDE.md"), "claude")
+	writeTestFile(t, filepath.Join(dir, "sub", "AGENTS.md"), "not loaded from the parent")
+
+	instructions, err := LoadProjectInstructions(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, instructions.Root)
+	assert.Len(t, instructions.Sources, 1)
+	assert.Equal(t, "CLAUDE.md", instructions.Sources[0].RelPath)
+
+	// Only dir itself is searched when there is no project root
+	instructions, err = LoadProjectInstructions(filepath.Join(dir, "sub"))
+	assert.NoError(t, err)
+	assert.Len(t, instructions.Sources, 1)
+	assert.Equal(t, "AGENTS.md", instructions.Sources[0].RelPath)
+
+	empty, err := LoadProjectInstructions(t.TempDir())
+	assert.NoError(t, err)
+	assert.Len(t, empty.Sources, 0)
+	assert.Equal(t, "", empty.Text())
+}
+
+func TestProjectInstructionsLoaderCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "AGENTS.md")
+	writeTestFile(t, path, "v1")
+	loader := NewProjectInstructionsLoader()
+
+	first, err := loader.Load(dir)
+	assert.NoError(t, err)
+	second, err := loader.Load(dir)
+	assert.NoError(t, err)
+	assert.True(t, first == second)
+
+	writeTestFile(t, path, "version 2")
+	third, err := loader.Load(dir)
+	assert.NoError(t, err)
+	assert.True(t, third != first)
+	assert.Equal(t, "version 2", third.Sources[0].Content)
+
+	writeTestFile(t, filepath.Join(dir, ".dive", "instructions.md"), "dive")
+	fourth, err := loader.Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, ".dive/instructions.md", fourth.Sources[0].RelPath)
+}
+
+func TestProjectInstructionsLoaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "AGENTS.md"), "v1")
+	loader := NewProjectInstructionsLoader()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan *ProjectInstructions, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- loader.Watch(ctx, dir, 5*time.Millisecond, func(instructions *ProjectInstructions, err error) {
+			assert.NoError(t, err)
+			changes <- instructions
+		})
+	}()
+
+	// Wait for the initial load before changing the file
+	assert.NoError(t, waitFor(func() bool {
+		loader.mu.Lock()
+		defer loader.mu.Unlock()
+		return len(loader.cache) == 1
+	}))
+	writeTestFile(t, filepath.Join(dir, "AGENTS.md"), "version 2")
+	select {
+	case instructions := <-changes:
+		assert.Equal(t, "version 2", instructions.Sources[0].Content)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change reported")
+	}
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestProjectInstructionsLoaderWatchRejectsInterval(t *testing.T) {
+	loader := NewProjectInstructionsLoader()
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err := loader.Watch(context.Background(), t.TempDir(), interval, func(*ProjectInstructions, error) {
+			t.Fatal("onChange called")
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "interval must be positive")
+	}
+}
+
+// waitFor polls cond until it returns true or a second passes.
+func waitFor(cond func() bool) error {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return context.DeadlineExceeded
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Built-in model aliases. Each lists candidate models in preference order;
+// an alias resolves to the first candidate whose provider has an API key set.
+// The "default" alias is what ResolveDefaultModel returns.
+var builtinAliases = map[string][]string{
+	"default": {"claude-haiku-4-5", "gemini-3.6-flash", "gpt-5.6-sol", "grok-4.5", "mistral-small-latest"},
+	"fast":    {"claude-haiku-4-5", "gemini-3.6-flash", "gpt-5.4-mini", "grok-4-1-fast-non-reasoning", "mistral-small-latest"},
+	"smart":   {"claude-opus-4-8", "gemini-3.1-pro-preview", "gpt-5.6", "grok-4.5", "mistral-large-latest"},
+	"cheap":   {"claude-haiku-4-5", "gemini-3.5-flash-lite", "gpt-5.4-nano", "grok-4-1-fast-non-reasoning", "mistral-small-latest"},
+}
+
+func init() {
+	for alias, models := range builtinAliases {
+		defaultRegistry.RegisterAlias(alias, models...)
+	}
+}
+
+// AliasEnvPrefix is the prefix of environment variables that override an
+// alias. DIVE_MODEL_FAST=gpt-5.6 makes the "fast" alias resolve to gpt-5.6
+// regardless of its candidates.
+const AliasEnvPrefix = "DIVE_MODEL_"
+
+// RegisterAlias maps alias to one or more models, in preference order. The
+// alias resolves to the first model whose provider has an API key set (see
+// ProviderEntry.APIKeyEnv), or to the first model if none do. Registering an
+// existing alias replaces it. Aliases are case-insensitive.
+func (r *Registry) RegisterAlias(alias string, models ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.aliases == nil {
+		r.aliases = make(map[string][]string)
+	}
+	alias = strings.ToLower(alias)
+	if len(models) == 0 {
+		delete(r.aliases, alias)
+		return
+	}
+	r.aliases[alias] = append([]string(nil), models...)
+}
+
+// ResolveAlias returns the model that alias currently resolves to, and false
+// if alias is not a registered alias. An AliasEnvPrefix environment variable
+// for the alias takes precedence over its registered models.
+func (r *Registry) ResolveAlias(alias string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resolveAlias(alias)
+}
+
+// ResolveDefaultModel returns the model the "default" alias resolves to: the
+// recommended model of the first provider with an API key set. It returns ""
+// if the registry has no "default" alias.
+func (r *Registry) ResolveDefaultModel() string {
+	model, _ := r.ResolveAlias("default")
+	return model
+}
+
+// Aliases returns a copy of the registered aliases and their candidate models.
+func (r *Registry) Aliases() map[string][]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make(map[string][]string, len(r.aliases))
+	for alias, models := range r.aliases {
+		result[alias] = append([]string(nil), models...)
+	}
+	return result
+}
+
+// LoadAliases registers the aliases in a JSON file. The file holds an object
+// mapping each alias to a model or to a list of candidate models:
+//
+//	{
+//	  "fast": "claude-haiku-4-5",
+//	  "smart": ["claude-opus-4-8", "gpt-5.6"]
+//	}
+func (r *Registry) LoadAliases(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading aliases: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parsing aliases %s: %w", path, err)
+	}
+	aliases := make(map[string][]string, len(raw))
+	for alias, value := range raw {
+		var model string
+		if err := json.Unmarshal(value, &model); err == nil {
+			aliases[alias] = []string{model}
+			continue
+		}
+		var models []string
+		if err := json.Unmarshal(value, &models); err != nil {
+			return fmt.Errorf("parsing aliases %s: alias %q must be a string or a list of strings", path, alias)
+		}
+		aliases[alias] = models
+	}
+	for alias, models := range aliases {
+		r.RegisterAlias(alias, models...)
+	}
+	return nil
+}
+
+// resolveAlias resolves alias. The caller must hold r.mu.
+func (r *Registry) resolveAlias(alias string) (string, bool) {
+	alias = strings.ToLower(alias)
+	models, ok := r.aliases[alias]
+	if !ok {
+		return "", false
+	}
+	if model := os.Getenv(aliasEnvVar(alias)); model != "" {
+		return model, true
+	}
+	for _, model := range models {
+		if r.available(model) {
+			return model, true
+		}
+	}
+	return models[0], true
+}
+
+// available reports whether the provider for model has an API key set. The
+// caller must hold r.mu.
+func (r *Registry) available(model string) bool {
+	entry, _, ok := r.lookup(model)
+	if !ok {
+		return false
+	}
+	return len(entry.APIKeyEnv) == 0 || hasAPIKey(entry)
+}
+
+func aliasEnvVar(alias string) string {
+	return AliasEnvPrefix + strings.ToUpper(strings.ReplaceAll(alias, "-", "_"))
+}
+
+// RegisterAlias adds a model alias to the default registry.
+func RegisterAlias(alias string, models ...string) {
+	defaultRegistry.RegisterAlias(alias, models...)
+}
+
+// ResolveAlias resolves a model alias using the default registry.
+func ResolveAlias(alias string) (string, bool) {
+	return defaultRegistry.ResolveAlias(alias)
+}
+
+// ResolveDefaultModel returns the recommended default model from the default
+// registry, based on which provider API keys are set.
+func ResolveDefaultModel() string {
+	return defaultRegistry.ResolveDefaultModel()
+}
+
+// LoadAliases registers the aliases in a JSON file with the default registry.
+func LoadAliases(path string) error {
+	return defaultRegistry.LoadAliases(path)
+}
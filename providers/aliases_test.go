@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func aliasTestRegistry() *Registry {
+	r := &Registry{}
+	r.Register(ProviderEntry{
+		Name:      "cloud",
+		Match:     PrefixMatcher("cloud-"),
+		APIKeyEnv: []string{"TEST_CLOUD_API_KEY"},
+		Factory: func(model, endpoint string) llm.LLM {
+			return &stubLLM{name: "cloud:" + model}
+		},
+	})
+	r.Register(ProviderEntry{
+		Name:      "other",
+		Match:     PrefixMatcher("other-"),
+		APIKeyEnv: []string{"TEST_OTHER_API_KEY", "TEST_OTHER_ALT_KEY"},
+		Factory: func(model, endpoint string) llm.LLM {
+			return &stubLLM{name: "other:" + model}
+		},
+	})
+	r.Register(ProviderEntry{
+		Name:  "local",
+		Match: PrefixMatcher("local-"),
+		Factory: func(model, endpoint string) llm.LLM {
+			return &stubLLM{name: "local:" + model}
+		},
+	})
+	return r
+}
+
+func TestResolveAlias_PrefersAvailableProvider(t *testing.T) {
+	t.Setenv("TEST_CLOUD_API_KEY", "")
+	t.Setenv("TEST_OTHER_API_KEY", "")
+	t.Setenv("TEST_OTHER_ALT_KEY", "")
+	r := aliasTestRegistry()
+	r.RegisterAlias("fast", "cloud-small", "other-small")
+
+	// No keys: the first candidate is returned
+	model, ok := r.ResolveAlias("fast")
+	assert.True(t, ok)
+	assert.Equal(t, "cloud-small", model)
+
+	// Any of a provider's key variables makes it available
+	t.Setenv("TEST_OTHER_ALT_KEY", "key")
+	model, _ = r.ResolveAlias("FAST")
+	assert.Equal(t, "other-small", model)
+
+	// Earlier candidates win when both are available
+	t.Setenv("TEST_CLOUD_API_KEY", "key")
+	model, _ = r.ResolveAlias("fast")
+	assert.Equal(t, "cloud-small", model)
+
+	_, ok = r.ResolveAlias("cloud-small")
+	assert.False(t, ok)
+}
+
+func TestResolveAlias_KeylessProviderIsAvailable(t *testing.T) {
+	t.Setenv("TEST_CLOUD_API_KEY", "")
+	r := aliasTestRegistry()
+	r.RegisterAlias("cheap", "cloud-small", "unknown-model", "local-small")
+
+	model, _ := r.ResolveAlias("cheap")
+	assert.Equal(t, "local-small", model)
+}
+
+func TestResolveAlias_EnvOverride(t *testing.T) {
+	r := aliasTestRegistry()
+	r.RegisterAlias("code-fast", "cloud-small")
+
+	t.Setenv("DIVE_MODEL_CODE_FAST", "other-large")
+	model, ok := r.ResolveAlias("code-fast")
+	assert.True(t, ok)
+	assert.Equal(t, "other-large", model)
+}
+
+func TestRegisterAlias_ReplaceAndRemove(t *testing.T) {
+	r := aliasTestRegistry()
+	r.RegisterAlias("smart", "cloud-large")
+	r.RegisterAlias("smart", "other-large")
+	assert.Equal(t, map[string][]string{"smart": {"other-large"}}, r.Aliases())
+
+	r.RegisterAlias("smart")
+	_, ok := r.ResolveAlias("smart")
+	assert.False(t, ok)
+}
+
+func TestCreateModel_ResolvesAlias(t *testing.T) {
+	t.Setenv("TEST_CLOUD_API_KEY", "")
+	t.Setenv("TEST_OTHER_API_KEY", "key")
+	r := aliasTestRegistry()
+	r.RegisterAlias("smart", "cloud-large", "other-large")
+
+	result := r.CreateModel("smart", "")
+	assert.NotNil(t, result)
+	assert.Equal(t, "other:other-large", result.(*stubLLM).name)
+}
+
+func TestResolveDefaultModel(t *testing.T) {
+	t.Setenv("TEST_CLOUD_API_KEY", "key")
+	r := aliasTestRegistry()
+	assert.Equal(t, "", r.ResolveDefaultModel())
+
+	r.RegisterAlias("default", "cloud-small")
+	assert.Equal(t, "cloud-small", r.ResolveDefaultModel())
+}
+
+func TestLoadAliases(t *testing.T) {
+	t.Setenv("TEST_CLOUD_API_KEY", "")
+	t.Setenv("TEST_OTHER_API_KEY", "key")
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	err := os.WriteFile(path, []byte(`{"fast": "cloud-small", "smart": ["cloud-large", "other-large"]}`), 0o644)
+	assert.NoError(t, err)
+
+	r := aliasTestRegistry()
+	assert.NoError(t, r.LoadAliases(path))
+	model, _ := r.ResolveAlias("fast")
+	assert.Equal(t, "cloud-small", model)
+	model, _ = r.ResolveAlias("smart")
+	assert.Equal(t, "other-large", model)
+
+	err = os.WriteFile(path, []byte(`{"fast": 1}`), 0o644)
+	assert.NoError(t, err)
+	err = r.LoadAliases(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `alias "fast"`)
+}
+
+func TestBuiltinAliases(t *testing.T) {
+	aliases := DefaultRegistry().Aliases()
+	for _, alias := range []string{"default", "fast", "smart", "cheap"} {
+		assert.True(t, len(aliases[alias]) > 0, alias)
+	}
+}
@@ -26,10 +26,16 @@ var (
 	DefaultClient        = &http.Client{Timeout: 300 * time.Second}
 	DefaultMaxRetries    = 3
 	DefaultRetryBaseWait = 2 * time.Second
-	DefaultVersion       = "2023-06-01"
+
+	// DefaultVersion is the anthropic-version header sent unless pinned
+	// with WithAPIVersion.
+	DefaultVersion = "2023-06-01"
 )
 
 var _ llm.StreamingLLM = &Provider{}
+var _ llm.ToolLimiter = &Provider{}
+var _ llm.ModelInfoProvider = &Provider{}
+var _ llm.ResponseFormatter = &Provider{}
 
 // Provider implements the Anthropic LLM provider for Claude models.
 type Provider struct {
@@ -69,6 +75,34 @@ func (p *Provider) Name() string {
 	return ProviderName
 }
 
+// SupportsResponseFormat implements llm.ResponseFormatter. JSON and JSON
+// schema formats are emulated with a tool; see ResponseFormatToolName.
+func (p *Provider) SupportsResponseFormat(format *llm.ResponseFormat) bool {
+	return emulatesResponseFormat(&llm.Config{ResponseFormat: format})
+}
+
+// ToolLimits implements llm.ToolLimiter. Tool names are limited to 64
+// characters.
+func (p *Provider) ToolLimits() llm.ToolLimits {
+	return llm.ToolLimits{MaxNameLength: 64}
+}
+
+// ModelInfo implements llm.ModelInfoProvider. The context window is the
+// model's default; see FeatureContext1M for models with a larger beta window.
+// Requests to the Messages API are limited to 32 MB.
+func (p *Provider) ModelInfo(model string) llm.ModelInfo {
+	if model == "" {
+		model = p.model
+	}
+	return llm.ModelInfo{
+		Model:           model,
+		ContextWindow:   contextWindowFor(model),
+		MaxRequestBytes: 32 << 20,
+		MaxImages:       100,
+		MaxImageBytes:   5 << 20,
+	}
+}
+
 func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
 	config := &llm.Config{}
 	config.Apply(opts...)
@@ -81,7 +115,8 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 	if err != nil {
 		return nil, err
 	}
-	msgs, err := convertMessages(rendered)
+	folded := foldDeveloperMessages(&request, rendered)
+	msgs, err := convertMessages(folded)
 	if err != nil {
 		return nil, err
 	}
@@ -89,11 +124,13 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 		msgs = append(msgs, llm.NewAssistantTextMessage(config.Prefill))
 	}
 	request.Messages = msgs
-	p.applyCaching(&request, config)
+	if err := p.applyCaching(&request, config, rendered); err != nil {
+		return nil, err
+	}
 
-	body, err := json.Marshal(request)
+	body, err := p.marshalRequest(&request, config)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		return nil, err
 	}
 
 	if err := config.FireHooks(ctx, &llm.HookContext{
@@ -127,7 +164,7 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 						"status", resp.StatusCode, "body", string(body))
 				}
 			}
-			return providers.NewError(resp.StatusCode, string(body))
+			return providers.NewResponseError(resp, string(body))
 		}
 		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 			return fmt.Errorf("error decoding response: %w", err)
@@ -142,6 +179,9 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 		return nil, fmt.Errorf("empty response from anthropic api")
 	}
 	finalizeUsage(config, request.Model, &result.Usage)
+	if emulatesResponseFormat(config) {
+		convertResponseFormatResult(&result)
+	}
 	if config.Prefill != "" {
 		if err := addPrefill(result.Content, config.Prefill, config.PrefillClosingTag); err != nil {
 			return nil, err
@@ -164,6 +204,17 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 	return &result, nil
 }
 
+// marshalRequest encodes the request body, merging in any provider options
+// addressed to this provider.
+func (p *Provider) marshalRequest(request *Request, config *llm.Config) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+	return providers.ApplyProviderOptions(body, config,
+		[]string{ProviderName, p.Name()}, knownProviderOptions)
+}
+
 func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIterator, error) {
 	config := &llm.Config{}
 	config.Apply(opts...)
@@ -176,7 +227,8 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 	if err != nil {
 		return nil, err
 	}
-	msgs, err := convertMessages(rendered)
+	folded := foldDeveloperMessages(&request, rendered)
+	msgs, err := convertMessages(folded)
 	if err != nil {
 		return nil, fmt.Errorf("error converting messages: %w", err)
 	}
@@ -185,11 +237,13 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 	}
 	request.Messages = msgs
 	request.Stream = true
-	p.applyCaching(&request, config)
+	if err := p.applyCaching(&request, config, rendered); err != nil {
+		return nil, err
+	}
 
-	body, err := json.Marshal(request)
+	body, err := p.marshalRequest(&request, config)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		return nil, err
 	}
 
 	if err := config.FireHooks(ctx, &llm.HookContext{
@@ -203,12 +257,12 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 		return nil, err
 	}
 
-	stream := providers.NewRetryingStreamIterator(ctx, providers.StreamRetryConfig{
+	stream := providers.NewContextRetryingStreamIterator(ctx, providers.StreamRetryConfig{
 		Provider:      p.Name(),
 		MaxRetries:    p.maxRetries,
 		RetryBaseWait: p.retryBaseWait,
 		Logger:        config.Logger,
-	}, func() (llm.StreamIterator, error) {
+	}, func(ctx context.Context) (llm.StreamIterator, error) {
 		req, err := p.createRequest(ctx, body, config, true)
 		if err != nil {
 			return nil, err
@@ -220,15 +274,19 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			return nil, providers.NewError(resp.StatusCode, string(body))
+			return nil, providers.NewResponseError(resp, string(body))
 		}
-		return &StreamIterator{
+		var iterator llm.StreamIterator = &StreamIterator{
 			body: resp.Body,
 			reader: llm.NewServerSentEventsReader[llm.Event](resp.Body).
 				WithSSECallback(config.SSECallback),
 			prefill:           config.Prefill,
 			prefillClosingTag: config.PrefillClosingTag,
-		}, nil
+		}
+		if emulatesResponseFormat(config) {
+			iterator = newResponseFormatStream(iterator)
+		}
+		return iterator, nil
 	})
 	return stream, nil
 }
@@ -258,6 +316,12 @@ func convertMessages(messages []*llm.Message) ([]*llm.Message, error) {
 		var copiedContent []llm.Content
 		for _, content := range message.Content {
 			switch c := content.(type) {
+			case *llm.FileContent:
+				// Model-generated files are response-only; the code execution
+				// result block that produced them is replayed instead.
+				continue
+			case *llm.TextContent:
+				copiedContent = append(copiedContent, anthropicTextContent(c))
 			case *llm.ToolResultContent:
 				copiedContent = append(copiedContent, &llm.ToolResultContent{
 					Content:      convertToolResultBlocks(c),
@@ -366,6 +430,9 @@ const (
 	// chain of breakpoints stays reachable even when a single turn appends a
 	// large fan-out of tool-call / tool-result blocks.
 	cacheAnchorGap = 15
+	// maxCacheBreakpoints is the number of cache_control markers the API
+	// accepts in one request, counting the automatic-caching marker.
+	maxCacheBreakpoints = 4
 )
 
 // applyCaching places cache-control breakpoints across the request using the
@@ -386,14 +453,21 @@ const (
 // extended-cache feature is enabled; the tail stays at the default 5-minute
 // TTL. Breakpoints are capped at the 4 the API allows (automatic consumes one).
 //
+// Breakpoints the caller sets with llm.WithCacheBreakpoints replace this
+// strategy; see applyCacheBreakpoints. messages are the rendered messages the
+// request was converted from, which those breakpoints index.
+//
 // The request's messages/system should already be copies (system is built
 // fresh; messages come from convertMessages) so mutation here is safe.
-func (p *Provider) applyCaching(req *Request, config *llm.Config) {
+func (p *Provider) applyCaching(req *Request, config *llm.Config, messages []*llm.Message) error {
 	// Start from a clean slate so caller-provided cache markers never leak
 	// through — in particular on opt-out, where we strip them and bail.
 	clearRequestCacheControl(req)
 	if config.Caching != nil && !*config.Caching {
-		return
+		return nil
+	}
+	if len(config.CacheBreakpoints) > 0 {
+		return applyCacheBreakpoints(req, config, messages)
 	}
 
 	stableTTL := stablePrefixTTL(config)
@@ -401,11 +475,11 @@ func (p *Provider) applyCaching(req *Request, config *llm.Config) {
 	// Total explicit block-level breakpoints used so far. The API allows 4; when
 	// automatic caching is on it consumes one, leaving 3 for explicit blocks.
 	explicitUsed := 0
-	explicitBudget := 4
+	explicitBudget := maxCacheBreakpoints
 
 	automatic := p.supportsAutomaticCaching()
 	if automatic {
-		explicitBudget = 3
+		explicitBudget = maxCacheBreakpoints - 1
 		req.CacheControl = &llm.CacheControl{Type: llm.CacheControlTypeEphemeral}
 	}
 
@@ -415,7 +489,7 @@ func (p *Provider) applyCaching(req *Request, config *llm.Config) {
 	}
 
 	if len(req.Messages) == 0 {
-		return
+		return nil
 	}
 
 	// Tail handling. Automatic caching owns the tail when supported; otherwise
@@ -429,6 +503,7 @@ func (p *Provider) applyCaching(req *Request, config *llm.Config) {
 	// Anchors defend the 20-block lookback window for the recent conversation.
 	remaining := explicitBudget - explicitUsed
 	placeCacheAnchors(req.Messages, remaining, stableTTL)
+	return nil
 }
 
 // logCacheThrash surfaces prompt-cache thrash: when caching is enabled but a
@@ -669,8 +744,14 @@ func (p *Provider) applyRequestConfig(req *Request, config *llm.Config) error {
 		if config.ParallelToolCalls != nil && !*config.ParallelToolCalls {
 			req.ToolChoice.DisableParallelToolUse = true
 		}
+	} else if config.ParallelToolCalls != nil && !*config.ParallelToolCalls && len(config.Tools) > 0 {
+		// disable_parallel_tool_use is part of tool_choice, so spell out
+		// the default choice to carry it
+		req.ToolChoice = &ToolChoice{Type: ToolChoiceTypeAuto, DisableParallelToolUse: true}
 	}
 
+	applyResponseFormat(req, config, requestHasThinkingEnabled(req.Model, req.Thinking))
+
 	if len(config.MCPServers) > 0 {
 		req.MCPServers = config.MCPServers
 	}
@@ -934,6 +1015,9 @@ func modelRunsThinkingByDefault(model string) bool {
 
 // createRequest creates an HTTP request with appropriate headers for Anthropic API calls
 func (p *Provider) createRequest(ctx context.Context, body []byte, config *llm.Config, isStreaming bool) (*http.Request, error) {
+	if err := validateAPIVersion(p.version); err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
@@ -993,6 +1077,10 @@ func (p *Provider) createRequest(ctx context.Context, body []byte, config *llm.C
 		betaFeatures = append(betaFeatures, FeatureInterleavedThinking)
 	}
 
+	if config.IsFeatureEnabled(FeatureFineGrainedToolStreaming) {
+		betaFeatures = append(betaFeatures, FeatureFineGrainedToolStreaming)
+	}
+
 	if config.IsFeatureEnabled(FeatureComputerUse45_46) {
 		betaFeatures = append(betaFeatures, FeatureComputerUse45_46)
 	} else if config.IsFeatureEnabled(FeatureComputerUse) {
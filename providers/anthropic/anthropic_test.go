@@ -314,7 +314,7 @@ func applyTestCaching(t *testing.T, p *Provider, config *llm.Config, system stri
 	if system != "" {
 		req.System = []*SystemBlock{{Type: "text", Text: system}}
 	}
-	p.applyCaching(req, config)
+	assert.NoError(t, p.applyCaching(req, config, messages))
 	return req
 }
 
@@ -428,7 +428,7 @@ func TestApplyCachingOptOutClearsCallerMarkers(t *testing.T) {
 		Messages:     msgs,
 	}
 
-	New().applyCaching(req, &llm.Config{Caching: &off})
+	assert.NoError(t, New().applyCaching(req, &llm.Config{Caching: &off}, nil))
 
 	assert.Nil(t, req.CacheControl, "top-level cache_control should be cleared on opt-out")
 	assert.Nil(t, req.System[0].CacheControl, "system marker should be cleared on opt-out")
@@ -544,3 +544,32 @@ func blockCacheControl(c llm.Content) *llm.CacheControl {
 	}
 	return nil
 }
+
+func TestModelInfo(t *testing.T) {
+	p := New(WithModel(ModelClaudeOpus48))
+	assert.Equal(t, llm.ModelInfo{
+		Model:           ModelClaudeOpus48,
+		ContextWindow:   1_000_000,
+		MaxRequestBytes: 32 << 20,
+		MaxImages:       100,
+		MaxImageBytes:   5 << 20,
+	}, p.ModelInfo(""))
+	assert.Equal(t, 200_000, p.ModelInfo(ModelClaudeHaiku4520251001).ContextWindow)
+	assert.Equal(t, 200_000, p.ModelInfo(ModelClaudeSonnet46).ContextWindow)
+	assert.Equal(t, 0, p.ModelInfo("unknown-model").ContextWindow)
+}
+
+func TestAPIVersion(t *testing.T) {
+	ctx := context.Background()
+	req, err := New().createRequest(ctx, nil, &llm.Config{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultVersion, req.Header.Get("anthropic-version"))
+
+	req, err = New(WithAPIVersion("2024-01-01")).createRequest(ctx, nil, &llm.Config{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-01", req.Header.Get("anthropic-version"))
+
+	_, err = New(WithAPIVersion("v1")).Generate(ctx, llm.WithUserTextMessage("hi"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid anthropic api version "v1"`)
+}
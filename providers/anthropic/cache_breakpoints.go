@@ -0,0 +1,176 @@
+package anthropic
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// applyCacheBreakpoints places cache_control markers only at the boundaries
+// the caller chose with llm.WithCacheBreakpoints, instead of the automatic
+// strategy in applyCaching.
+//
+// A system breakpoint splits the system prompt block at its offset and marks
+// the block that ends there. A message breakpoint marks the last cacheable
+// block of the request message built from messages[index]; a leading
+// developer message folded into the system prompt marks the last system
+// block instead, and an empty message, which convertMessages drops, is
+// skipped. Breakpoints that land on the same block count once. More than
+// maxCacheBreakpoints distinct breakpoints, or one that doesn't fit the
+// request, is an error.
+func applyCacheBreakpoints(req *Request, config *llm.Config, messages []*llm.Message) error {
+	_, folded := llm.FoldDeveloperMessages(messages)
+	foldedCount := len(messages) - len(folded)
+
+	var systemBreakpoints []llm.CacheBreakpoint
+	var marks []cacheMark
+	for _, bp := range config.CacheBreakpoints {
+		if bp.System {
+			systemBreakpoints = append(systemBreakpoints, bp)
+			continue
+		}
+		index := bp.Message
+		if index < 0 {
+			index += len(messages)
+		}
+		if index < 0 || index >= len(messages) {
+			return fmt.Errorf("cache breakpoint message index %d is out of range for %d messages", bp.Message, len(messages))
+		}
+		if index < foldedCount {
+			marks = append(marks, cacheMark{system: true, block: -1, ttl: bp.TTL})
+			continue
+		}
+		target, ok := requestMessageIndex(folded, index-foldedCount)
+		if !ok {
+			continue
+		}
+		marks = append(marks, cacheMark{message: target, ttl: bp.TTL})
+	}
+
+	// Split the system prompt first so the folded-instructions mark below
+	// resolves to the final block index.
+	systemMarks, err := splitSystemPrompt(req, config.SystemPrompt, systemBreakpoints)
+	if err != nil {
+		return err
+	}
+	marks = append(systemMarks, marks...)
+
+	placed := 0
+	marked := map[llm.CacheControlSetter]bool{}
+	for _, mark := range marks {
+		cacheControl := &llm.CacheControl{Type: llm.CacheControlTypeEphemeral, TTL: mark.ttl}
+		if mark.system {
+			if len(req.System) == 0 {
+				continue
+			}
+			block := mark.block
+			if block < 0 {
+				block = len(req.System) - 1
+			}
+			if req.System[block].CacheControl != nil {
+				continue
+			}
+			req.System[block].CacheControl = cacheControl
+			placed++
+			continue
+		}
+		if mark.message >= len(req.Messages) {
+			continue
+		}
+		setter := lastCacheControlSetter(req.Messages[mark.message])
+		if setter == nil || marked[setter] {
+			continue
+		}
+		setter.SetCacheControl(cacheControl)
+		marked[setter] = true
+		placed++
+	}
+	if placed > maxCacheBreakpoints {
+		return fmt.Errorf("%d cache breakpoints exceed the limit of %d", placed, maxCacheBreakpoints)
+	}
+	return nil
+}
+
+// cacheMark is a resolved breakpoint: a system block, or a request message
+// whose last cacheable block is marked. A system block of -1 is the last.
+type cacheMark struct {
+	system  bool
+	block   int
+	message int
+	ttl     string
+}
+
+// splitSystemPrompt splits the system prompt block, req.System[0], at the
+// offsets of breakpoints and returns marks for the blocks that end at them.
+// An offset of zero, or the length of the prompt, marks the whole prompt.
+func splitSystemPrompt(req *Request, prompt string, breakpoints []llm.CacheBreakpoint) ([]cacheMark, error) {
+	if len(breakpoints) == 0 {
+		return nil, nil
+	}
+	if prompt == "" {
+		return nil, fmt.Errorf("cache breakpoint in the system prompt, but the request has no system prompt")
+	}
+
+	ttls := map[int]string{}
+	var offsets []int
+	for _, bp := range breakpoints {
+		offset := bp.SystemOffset
+		if offset == 0 {
+			offset = len(prompt)
+		}
+		if offset < 0 || offset > len(prompt) {
+			return nil, fmt.Errorf("cache breakpoint system offset %d is out of range for a %d-byte system prompt", bp.SystemOffset, len(prompt))
+		}
+		if offset < len(prompt) && !utf8.RuneStart(prompt[offset]) {
+			return nil, fmt.Errorf("cache breakpoint system offset %d is inside a UTF-8 character", offset)
+		}
+		if _, ok := ttls[offset]; !ok {
+			ttls[offset] = bp.TTL
+			offsets = append(offsets, offset)
+		}
+	}
+	slices.Sort(offsets)
+
+	var blocks []*SystemBlock
+	var marks []cacheMark
+	start := 0
+	for _, offset := range offsets {
+		marks = append(marks, cacheMark{system: true, block: len(blocks), ttl: ttls[offset]})
+		blocks = append(blocks, &SystemBlock{Type: "text", Text: prompt[start:offset]})
+		start = offset
+	}
+	if start < len(prompt) {
+		blocks = append(blocks, &SystemBlock{Type: "text", Text: prompt[start:]})
+	}
+	req.System = append(blocks, req.System[1:]...)
+	return marks, nil
+}
+
+// requestMessageIndex returns the index in the converted request of
+// folded[index], accounting for the empty messages convertMessages drops.
+// It returns false if folded[index] is itself empty.
+func requestMessageIndex(folded []*llm.Message, index int) (int, bool) {
+	if len(folded[index].Content) == 0 {
+		return 0, false
+	}
+	target := 0
+	for _, message := range folded[:index] {
+		if len(message.Content) > 0 {
+			target++
+		}
+	}
+	return target, true
+}
+
+// lastCacheControlSetter returns the last block of message that accepts
+// cache control, or nil if none does.
+func lastCacheControlSetter(message *llm.Message) llm.CacheControlSetter {
+	for i := len(message.Content) - 1; i >= 0; i-- {
+		if setter, ok := message.Content[i].(llm.CacheControlSetter); ok {
+			return setter
+		}
+	}
+	return nil
+}
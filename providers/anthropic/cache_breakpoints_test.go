@@ -0,0 +1,282 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// buildCachingRequest builds a request the way Generate does, folding
+// developer messages and converting, then places cache breakpoints.
+func buildCachingRequest(t *testing.T, config *llm.Config) (*Request, error) {
+	t.Helper()
+	req := &Request{}
+	if config.SystemPrompt != "" {
+		req.System = []*SystemBlock{{Type: "text", Text: config.SystemPrompt}}
+	}
+	folded := foldDeveloperMessages(req, config.Messages)
+	converted, err := convertMessages(folded)
+	assert.NoError(t, err)
+	req.Messages = converted
+	return req, New().applyCaching(req, config, config.Messages)
+}
+
+func TestCacheBreakpointsGenerate(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5",
+			"content":[{"type":"text","text":"ok"}],
+			"usage":{"input_tokens":20,"output_tokens":2,"cache_creation_input_tokens":1500,"cache_read_input_tokens":3000}}`))
+	}))
+	defer server.Close()
+
+	static := "You are a support agent. Follow the handbook."
+	response, err := New(WithEndpoint(server.URL), WithAPIKey("test"), WithMaxRetries(0)).Generate(context.Background(),
+		llm.WithSystemPrompt(static+" Today is Monday."),
+		llm.WithMessages(
+			llm.NewUserTextMessage("first question"),
+			llm.NewAssistantTextMessage("first answer"),
+			llm.NewUserTextMessage("second question"),
+		),
+		llm.WithCacheBreakpoints(
+			llm.SystemCacheBreakpoint(len(static)).WithTTL(llm.CacheTTL1h),
+			llm.MessageCacheBreakpoint(-2),
+		),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500, response.Usage.CacheCreationInputTokens)
+	assert.Equal(t, 3000, response.Usage.CacheReadInputTokens)
+
+	// Only the caller's breakpoints are sent, without automatic caching
+	_, automatic := body["cache_control"]
+	assert.False(t, automatic)
+
+	system := body["system"].([]any)
+	assert.Len(t, system, 2)
+	first := system[0].(map[string]any)
+	assert.Equal(t, static, first["text"])
+	assert.Equal(t, map[string]any{"type": "ephemeral", "ttl": "1h"}, first["cache_control"])
+	second := system[1].(map[string]any)
+	assert.Equal(t, " Today is Monday.", second["text"])
+	assert.Nil(t, second["cache_control"])
+
+	messages := body["messages"].([]any)
+	assert.Len(t, messages, 3)
+	for i, message := range messages {
+		content := message.(map[string]any)["content"].([]any)
+		_, marked := content[len(content)-1].(map[string]any)["cache_control"]
+		assert.Equal(t, i == 1, marked, "message %d", i)
+	}
+}
+
+func TestAutoPromptCachingRequest(t *testing.T) {
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		if len(bodies) == 1 {
+			_, _ = io.WriteString(w, autoCachingToolStream)
+			return
+		}
+		_, _ = io.WriteString(w, autoCachingTextStream)
+	}))
+	defer server.Close()
+
+	lookup := dive.FuncTool("lookup", "Look something up", func(ctx context.Context, input *struct{}) (*dive.ToolResult, error) {
+		return dive.NewToolResultText("found"), nil
+	})
+	agent, err := dive.NewAgent(dive.AgentOptions{
+		SystemPrompt: "You are a support agent.",
+		Model:        New(WithEndpoint(server.URL), WithAPIKey("test"), WithMaxRetries(0)),
+		Tools:        []dive.Tool{lookup},
+	})
+	assert.NoError(t, err)
+
+	_, err = agent.CreateResponse(context.Background(), dive.WithMessages(
+		llm.NewUserTextMessage("first question"),
+		llm.NewAssistantTextMessage("first answer"),
+		llm.NewUserTextMessage("second question"),
+	), dive.WithAutoPromptCaching(true))
+	assert.NoError(t, err)
+	assert.Len(t, bodies, 2)
+
+	// The agent's breakpoints replace automatic caching: the system prompt,
+	// the end of the previous turn, and the tail, which moves forward
+	for i, body := range bodies {
+		_, automatic := body["cache_control"]
+		assert.False(t, automatic)
+
+		system := body["system"].([]any)
+		_, marked := system[len(system)-1].(map[string]any)["cache_control"]
+		assert.True(t, marked, "request %d system", i)
+
+		messages := body["messages"].([]any)
+		assert.Len(t, messages, 3+2*i)
+		for j, message := range messages {
+			content := message.(map[string]any)["content"].([]any)
+			_, marked := content[len(content)-1].(map[string]any)["cache_control"]
+			assert.Equal(t, j == 1 || j == len(messages)-1, marked, "request %d message %d", i, j)
+		}
+	}
+}
+
+const autoCachingToolStream = `data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"test-model","content":[],"usage":{"input_tokens":20}}}
+
+data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"call_1","name":"lookup","input":{}}}
+
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{}"}}
+
+data: {"type":"content_block_stop","index":0}
+
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":2}}
+
+data: {"type":"message_stop"}
+
+`
+
+const autoCachingTextStream = `data: {"type":"message_start","message":{"id":"msg_2","type":"message","role":"assistant","model":"test-model","content":[],"usage":{"input_tokens":20,"cache_read_input_tokens":3000}}}
+
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"done"}}
+
+data: {"type":"content_block_stop","index":0}
+
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}
+
+data: {"type":"message_stop"}
+
+`
+
+func TestCacheBreakpointsFoldedDeveloperMessage(t *testing.T) {
+	req, err := buildCachingRequest(t, &llm.Config{
+		SystemPrompt: "sys",
+		Messages: []*llm.Message{
+			llm.NewDeveloperMessage("Answer in French."),
+			llm.NewUserTextMessage("hi"),
+		},
+		CacheBreakpoints: []llm.CacheBreakpoint{llm.MessageCacheBreakpoint(0)},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, req.System, 2)
+	assert.Nil(t, req.System[0].CacheControl)
+	assert.NotNil(t, req.System[1].CacheControl)
+	assert.Equal(t, 0, countMessageBreakpoints(req.Messages))
+}
+
+func TestCacheBreakpointsSkipEmptyMessages(t *testing.T) {
+	req, err := buildCachingRequest(t, &llm.Config{
+		Messages: []*llm.Message{
+			{Role: llm.User},
+			llm.NewUserTextMessage("hi"),
+			llm.NewAssistantTextMessage("hello"),
+		},
+		CacheBreakpoints: []llm.CacheBreakpoint{llm.MessageCacheBreakpoint(1), llm.MessageCacheBreakpoint(0)},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, req.Messages, 2)
+	assert.NotNil(t, lastBlockCacheControl(req.Messages[0]))
+	assert.Nil(t, lastBlockCacheControl(req.Messages[1]))
+}
+
+func TestCacheBreakpointsDeduplicate(t *testing.T) {
+	req, err := buildCachingRequest(t, &llm.Config{
+		SystemPrompt: "sys",
+		Messages:     []*llm.Message{llm.NewUserTextMessage("hi")},
+		CacheBreakpoints: []llm.CacheBreakpoint{
+			llm.SystemCacheBreakpoint(0),
+			llm.SystemCacheBreakpoint(3),
+			llm.MessageCacheBreakpoint(0),
+			llm.MessageCacheBreakpoint(-1),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, req.System, 1)
+	assert.NotNil(t, req.System[0].CacheControl)
+	assert.Equal(t, 1, countMessageBreakpoints(req.Messages))
+}
+
+func TestCacheBreakpointsErrors(t *testing.T) {
+	messages := []*llm.Message{
+		llm.NewUserTextMessage("a"),
+		llm.NewAssistantTextMessage("b"),
+		llm.NewUserTextMessage("c"),
+		llm.NewAssistantTextMessage("d"),
+		llm.NewUserTextMessage("e"),
+	}
+	tests := []struct {
+		name        string
+		system      string
+		breakpoints []llm.CacheBreakpoint
+		want        string
+	}{
+		{
+			name: "too many",
+			breakpoints: []llm.CacheBreakpoint{
+				llm.MessageCacheBreakpoint(0),
+				llm.MessageCacheBreakpoint(1),
+				llm.MessageCacheBreakpoint(2),
+				llm.MessageCacheBreakpoint(3),
+				llm.MessageCacheBreakpoint(4),
+			},
+			want: "5 cache breakpoints exceed the limit of 4",
+		},
+		{
+			name:        "message out of range",
+			breakpoints: []llm.CacheBreakpoint{llm.MessageCacheBreakpoint(-6)},
+			want:        "message index -6 is out of range",
+		},
+		{
+			name:        "no system prompt",
+			breakpoints: []llm.CacheBreakpoint{llm.SystemCacheBreakpoint(0)},
+			want:        "no system prompt",
+		},
+		{
+			name:        "system offset out of range",
+			system:      "short",
+			breakpoints: []llm.CacheBreakpoint{llm.SystemCacheBreakpoint(6)},
+			want:        "system offset 6 is out of range",
+		},
+		{
+			name:        "system offset inside a character",
+			system:      "café au lait",
+			breakpoints: []llm.CacheBreakpoint{llm.SystemCacheBreakpoint(4)},
+			want:        "inside a UTF-8 character",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildCachingRequest(t, &llm.Config{
+				SystemPrompt:     tt.system,
+				Messages:         messages,
+				CacheBreakpoints: tt.breakpoints,
+			})
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestCacheBreakpointsCachingDisabled(t *testing.T) {
+	off := false
+	req, err := buildCachingRequest(t, &llm.Config{
+		SystemPrompt:     "sys",
+		Messages:         []*llm.Message{llm.NewUserTextMessage("hi")},
+		Caching:          &off,
+		CacheBreakpoints: []llm.CacheBreakpoint{llm.SystemCacheBreakpoint(0), llm.MessageCacheBreakpoint(0)},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, req.System[0].CacheControl)
+	assert.Equal(t, 0, countMessageBreakpoints(req.Messages))
+}
@@ -0,0 +1,44 @@
+package anthropic
+
+import "github.com/deepnoodle-ai/dive/llm"
+
+// TextModelCapabilities describes what the text generation models can do.
+// Lookups through providers.Capabilities ignore snapshot dates, so the
+// undated entries also cover dated names such as ModelClaudeHaiku4520251001.
+var TextModelCapabilities = []llm.ModelInfo{
+	claudeCapabilities(ModelClaude35Haiku20241022, 8192, false),
+	claudeCapabilities(ModelClaude35Sonnet20241022, 8192, false),
+	claudeCapabilities(ModelClaude37Sonnet20250219, 64000, true),
+	claudeCapabilities(ModelClaudeSonnet420250514, 64000, true),
+	claudeCapabilities(ModelClaudeOpus420250514, 32000, true),
+	claudeCapabilities(ModelClaudeOpus4120250805, 32000, true),
+	claudeCapabilities(ModelClaudeHaiku45, 64000, true),
+	claudeCapabilities(ModelClaudeSonnet45, 64000, true),
+	claudeCapabilities(ModelClaudeOpus45, 64000, true),
+	claudeCapabilities(ModelClaudeSonnet46, 64000, true),
+	claudeCapabilities(ModelClaudeOpus46, 128000, true),
+	claudeCapabilities(ModelClaudeOpus47, 128000, true),
+	claudeCapabilities(ModelClaudeOpus48, 128000, true),
+	claudeCapabilities(ModelClaudeFable5, 128000, true),
+	claudeCapabilities(ModelClaudeMythos5, 128000, true),
+	claudeCapabilities(ModelClaudeSonnet5, 64000, true),
+}
+
+// claudeCapabilities returns the capabilities shared by Claude models: text,
+// image, and PDF input, tools, prompt caching, and JSON schema output
+// through the emulation described at ResponseFormatToolName.
+func claudeCapabilities(model string, maxOutputTokens int, reasoning bool) llm.ModelInfo {
+	return llm.ModelInfo{
+		Model:            model,
+		Provider:         ProviderName,
+		ContextWindow:    contextWindowFor(model),
+		MaxOutputTokens:  maxOutputTokens,
+		InputModalities:  []llm.Modality{llm.ModalityText, llm.ModalityImage, llm.ModalityPDF},
+		OutputModalities: []llm.Modality{llm.ModalityText},
+		Tools:            true,
+		JSONSchema:       true,
+		Reasoning:        reasoning,
+		PromptCaching:    true,
+		UpdatedAt:        "2026-10-17",
+	}
+}
@@ -0,0 +1,101 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/retry"
+)
+
+var _ llm.TokenCounter = &Provider{}
+
+// countTokensRequest is the body of a token counting request. It is a
+// Messages API request without the generation settings, which the
+// endpoint rejects.
+type countTokensRequest struct {
+	Model    string           `json:"model"`
+	Messages []*llm.Message   `json:"messages"`
+	System   []*SystemBlock   `json:"system,omitempty"`
+	Tools    []map[string]any `json:"tools,omitempty"`
+}
+
+type countTokensResponse struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+// CountTokens implements llm.TokenCounter with the Messages API's free token
+// counting endpoint, which counts images, documents, and tools exactly as
+// the model would. Endpoints that don't follow the Messages API's URL
+// layout, such as proxies, are estimated locally with
+// llm.EstimateRequestTokens instead.
+func (p *Provider) CountTokens(ctx context.Context, model string, messages []*llm.Message, tools []llm.Tool) (int, error) {
+	if model == "" {
+		model = p.model
+	}
+	if !strings.HasSuffix(p.endpoint, "/messages") {
+		return llm.EstimateRequestTokens(model, messages, tools)
+	}
+	if err := validateAPIVersion(p.version); err != nil {
+		return 0, err
+	}
+
+	var request Request
+	config := &llm.Config{Model: model, Tools: tools}
+	if err := p.applyRequestConfig(&request, config); err != nil {
+		return 0, err
+	}
+	rendered, err := p.renderReminders(messages, model)
+	if err != nil {
+		return 0, err
+	}
+	rendered = foldDeveloperMessages(&request, rendered)
+	msgs, err := convertMessages(rendered)
+	if err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(countTokensRequest{
+		Model:    model,
+		Messages: msgs,
+		System:   request.System,
+		Tools:    request.Tools,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error marshalling request: %w", err)
+	}
+
+	var result countTokensResponse
+	err = retry.DoSimple(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/count_tokens", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+		req.Header.Set("x-api-key", p.apiKey)
+		req.Header.Set("anthropic-version", p.version)
+		req.Header.Set("content-type", "application/json")
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("error making request: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return providers.NewResponseError(resp, string(body))
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+		return nil
+	}, retry.WithMaxAttempts(p.maxRetries+1), retry.WithBackoff(p.retryBaseWait, 5*time.Minute), retry.WithRetryIf(retry.SkipPermanent()))
+	if err != nil {
+		return 0, err
+	}
+	return result.InputTokens, nil
+}
@@ -0,0 +1,55 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+func TestCountTokens(t *testing.T) {
+	var path string
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(data, &body))
+		_, _ = io.WriteString(w, `{"input_tokens":42}`)
+	}))
+	defer server.Close()
+
+	p := New(WithAPIKey("key"), WithEndpoint(server.URL+"/v1/messages"), WithModel(ModelClaudeHaiku45))
+	tool := llm.NewToolDefinition().
+		WithName("search").
+		WithDescription("Search the web").
+		WithSchema(&schema.Schema{Type: "object"})
+	tokens, err := llm.CountTokens(context.Background(), p, "", []*llm.Message{
+		llm.NewDeveloperMessage("Be brief."),
+		llm.NewUserTextMessage("hello"),
+	}, []llm.Tool{tool})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, tokens)
+	assert.Equal(t, "/v1/messages/count_tokens", path)
+	assert.Equal(t, ModelClaudeHaiku45, body["model"])
+	assert.Len(t, body["messages"], 1)
+	assert.Len(t, body["system"], 1)
+	assert.Len(t, body["tools"], 1)
+	_, hasMaxTokens := body["max_tokens"]
+	assert.False(t, hasMaxTokens)
+}
+
+func TestCountTokensEstimatesOtherEndpoints(t *testing.T) {
+	p := New(WithEndpoint("https://proxy.example.com/anthropic"))
+	messages := []*llm.Message{llm.NewUserTextMessage("hello world")}
+	tokens, err := p.CountTokens(context.Background(), "", messages, nil)
+	assert.NoError(t, err)
+	want, err := llm.EstimateRequestTokens(DefaultModel, messages, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, want, tokens)
+}
@@ -48,4 +48,11 @@ const (
 	// Interleaved thinking beta for Opus 4.5 and earlier Claude 4 models.
 	// Adaptive-thinking models handle interleaved thinking automatically.
 	FeatureInterleavedThinking = "interleaved-thinking-2025-05-14"
+
+	// Fine-grained tool streaming: tool arguments stream as input_json_delta
+	// fragments as they are generated, instead of being buffered and
+	// validated server-side first. Arguments arrive sooner and in smaller
+	// pieces, but may be invalid JSON if the response hits max_tokens; the
+	// stream accumulator repairs or flags such input.
+	FeatureFineGrainedToolStreaming = "fine-grained-tool-streaming-2025-05-14"
 )
@@ -0,0 +1,46 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+var _ providers.HealthChecker = &Provider{}
+
+// HealthCheck verifies the API key and connectivity by retrieving the
+// configured model from the Models API, which is free and also confirms the
+// model exists. Endpoints that don't follow the Messages API's URL layout
+// are checked with a one-token request instead.
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	base, ok := strings.CutSuffix(p.endpoint, "/messages")
+	if !ok {
+		_, err := p.Generate(ctx, llm.WithUserTextMessage("ping"), llm.WithMaxTokens(1))
+		return err
+	}
+	if err := validateAPIVersion(p.version); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/models/"+url.PathEscape(p.model), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", p.version)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return providers.NewError(resp.StatusCode, string(body))
+	}
+	return nil
+}
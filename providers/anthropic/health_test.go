@@ -0,0 +1,34 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var path, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, apiKey = r.URL.Path, r.Header.Get("x-api-key")
+		if apiKey != "good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"type":"error","error":{"type":"authentication_error"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"type":"model","id":"claude-haiku-4-5"}`)
+	}))
+	defer server.Close()
+
+	p := New(WithAPIKey("good-key"), WithEndpoint(server.URL+"/v1/messages"), WithModel(ModelClaudeHaiku45))
+	assert.NoError(t, providers.HealthCheck(context.Background(), p))
+	assert.Equal(t, "/v1/models/claude-haiku-4-5", path)
+
+	p = New(WithAPIKey("bad-key"), WithEndpoint(server.URL+"/v1/messages"))
+	err := providers.HealthCheck(context.Background(), p)
+	assert.Equal(t, providers.HealthAuthFailure, providers.HealthStatusOf(err))
+}
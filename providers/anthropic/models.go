@@ -1,5 +1,7 @@
 package anthropic
 
+import "strings"
+
 const (
 	// Claude 3.5 models
 	ModelClaude35Haiku20241022  = "claude-3-5-haiku-20241022"
@@ -38,3 +40,21 @@ const (
 	ModelClaudeMythos5 = "claude-mythos-5"
 	ModelClaudeSonnet5 = "claude-sonnet-5"
 )
+
+// contextWindowFor returns a model's default context window. Models that
+// only reach 1M tokens with the FeatureContext1M beta report 200k, since the
+// beta is enabled per request.
+func contextWindowFor(model string) int {
+	for _, prefix := range []string{
+		ModelClaudeOpus47, ModelClaudeOpus48,
+		ModelClaudeFable5, ModelClaudeMythos5, ModelClaudeSonnet5,
+	} {
+		if strings.HasPrefix(model, prefix) {
+			return 1_000_000
+		}
+	}
+	if strings.HasPrefix(model, "claude-") {
+		return 200_000
+	}
+	return 0
+}
@@ -3,6 +3,9 @@ package anthropic
 import (
 	"net/http"
 	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
 )
 
 // Option configures the Anthropic provider.
@@ -37,6 +40,21 @@ func WithClient(client *http.Client) Option {
 	}
 }
 
+// WithTransport sets the HTTP transport used for all API requests. It
+// replaces the HTTP client with one that has no overall timeout, so bound
+// requests with context deadlines instead. See providers.NewTransportClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(p *Provider) {
+		p.client = providers.NewTransportClient(transport)
+	}
+}
+
+// WithStreamingTransport uses a transport tuned for long streaming responses,
+// such as extended reasoning. See providers.NewStreamingTransport.
+func WithStreamingTransport() Option {
+	return WithTransport(providers.NewStreamingTransport())
+}
+
 // WithMaxTokens sets the maximum number of tokens to generate.
 func WithMaxTokens(maxTokens int) Option {
 	return func(p *Provider) {
@@ -65,9 +83,38 @@ func WithBaseWait(baseWait time.Duration) Option {
 	}
 }
 
-// WithVersion sets the Anthropic API version string.
-func WithVersion(version string) Option {
+// WithAPIVersion pins the anthropic-version header sent with every request,
+// so upstream API changes are adopted only when the version is changed.
+// Versions are dates in YYYY-MM-DD form; requests fail with an error if the
+// version is malformed. Defaults to DefaultVersion.
+func WithAPIVersion(version string) Option {
 	return func(p *Provider) {
 		p.version = version
 	}
 }
+
+// WithVersion sets the Anthropic API version string.
+//
+// Deprecated: Use WithAPIVersion.
+func WithVersion(version string) Option {
+	return WithAPIVersion(version)
+}
+
+// Known provider option keys for use with llm.WithProviderOption. Other
+// "anthropic:" keys are still sent but log a warning (or fail when
+// llm.WithStrictProviderOptions is set).
+const (
+	OptionTopK          llm.ProviderOptionKey = "anthropic:top_k"
+	OptionTopP          llm.ProviderOptionKey = "anthropic:top_p"
+	OptionStopSequences llm.ProviderOptionKey = "anthropic:stop_sequences"
+	OptionMetadata      llm.ProviderOptionKey = "anthropic:metadata"
+	OptionServiceTier   llm.ProviderOptionKey = "anthropic:service_tier"
+)
+
+var knownProviderOptions = []llm.ProviderOptionKey{
+	OptionTopK,
+	OptionTopP,
+	OptionStopSequences,
+	OptionMetadata,
+	OptionServiceTier,
+}
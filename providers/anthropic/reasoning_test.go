@@ -2,6 +2,10 @@ package anthropic
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/deepnoodle-ai/dive/llm"
@@ -352,3 +356,85 @@ func TestSonnet5RejectsTemperature(t *testing.T) {
 	req := buildReq(t, ModelClaudeSonnet5, llm.WithTemperature(0.7))
 	assert.Nil(t, req.Temperature)
 }
+
+func TestDisableParallelToolUseWithoutToolChoice(t *testing.T) {
+	req := buildReq(t, ModelClaudeSonnet46,
+		llm.WithTools(reasoningTestTool()),
+		llm.WithParallelToolCalls(false))
+	assert.Equal(t, &ToolChoice{Type: ToolChoiceTypeAuto, DisableParallelToolUse: true}, req.ToolChoice)
+
+	req = buildReq(t, ModelClaudeSonnet46, llm.WithTools(reasoningTestTool()))
+	assert.Nil(t, req.ToolChoice)
+}
+
+func TestStreamThinkingBudget(t *testing.T) {
+	var body Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-opus-4-6","content":[],"usage":{"input_tokens":12,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":"","signature":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"2 plus 2"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":" is 4."}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig-1"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"4"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":30,"output_tokens_details":{"thinking_tokens":24}}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`)
+	}))
+	defer server.Close()
+
+	iterator, err := New(WithAPIKey("test-key"), WithEndpoint(server.URL), WithMaxRetries(0)).Stream(context.Background(),
+		llm.WithModel(ModelClaudeOpus46),
+		llm.WithMaxTokens(8192),
+		llm.WithThinkingBudget(2048),
+		llm.WithUserTextMessage("What is 2+2?"),
+	)
+	assert.NoError(t, err)
+	defer iterator.Close()
+
+	accumulator := llm.NewResponseAccumulator()
+	for iterator.Next() {
+		assert.NoError(t, accumulator.AddEvent(iterator.Event()))
+	}
+	assert.NoError(t, iterator.Err())
+
+	assert.NotNil(t, body.Thinking)
+	assert.Equal(t, "enabled", body.Thinking.Type)
+	assert.Equal(t, 2048, body.Thinking.BudgetTokens)
+
+	response := accumulator.Response()
+	assert.Len(t, response.Content, 2)
+	thinking, ok := response.Content[0].(*llm.ThinkingContent)
+	assert.True(t, ok)
+	assert.Equal(t, "2 plus 2 is 4.", thinking.Thinking)
+	assert.Equal(t, "sig-1", thinking.Signature)
+	assert.Equal(t, "4", response.Message().Text())
+	assert.Equal(t, 30, response.Usage.OutputTokens)
+	assert.Equal(t, 24, response.Usage.ReasoningTokens)
+}
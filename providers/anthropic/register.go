@@ -8,15 +8,18 @@ import (
 func init() {
 	// Register for claude-* models
 	providers.Register(providers.ProviderEntry{
-		Name:    "anthropic",
-		Match:   providers.PrefixMatcher("claude-"),
-		Factory: factory,
+		Name:             "anthropic",
+		Match:            providers.PrefixMatcher("claude-"),
+		Factory:          factory,
+		APIKeyEnv:        []string{"ANTHROPIC_API_KEY"},
+		HealthCheckModel: ModelClaudeHaiku45,
 	})
 
 	// Register as the fallback provider (for unknown models)
 	providers.SetFallback(factory)
 
 	registerPricing()
+	providers.RegisterCapabilities(TextModelCapabilities...)
 }
 
 // registerPricing publishes Anthropic model pricing — with derived prompt-cache
@@ -0,0 +1,144 @@
+package anthropic
+
+import (
+	"encoding/json"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+// ResponseFormatToolName is the name of the tool the provider adds to
+// emulate llm.WithResponseFormat. Avoid giving other tools this name.
+const ResponseFormatToolName = "structured_response"
+
+const defaultResponseFormatDescription = "Respond with your final answer by calling this tool. " +
+	"Its input is your entire response, so always use this tool to answer instead of replying with text."
+
+// applyResponseFormat emulates a JSON response format, which the Messages API
+// lacks, with a tool whose input schema is the format's schema. The model is
+// made to call it: tool_choice names the tool when there are no other tools,
+// and is "any" when there are, so the model can still use them before
+// answering. Extended thinking only allows tool_choice "auto", so there the
+// tool's description asks the model to call it. convertResponseFormatResult
+// turns the call back into text.
+func applyResponseFormat(req *Request, config *llm.Config, thinking bool) {
+	if !emulatesResponseFormat(config) {
+		return
+	}
+	format := config.ResponseFormat
+	inputSchema := format.Schema
+	if inputSchema == nil || format.Type == llm.ResponseFormatTypeJSON {
+		inputSchema = &schema.Schema{Type: schema.Object}
+	}
+	description := format.Description
+	if description == "" {
+		description = defaultResponseFormatDescription
+	}
+	tool := map[string]any{
+		"name":         ResponseFormatToolName,
+		"description":  description,
+		"input_schema": inputSchema,
+	}
+
+	choice := req.ToolChoice
+	if choice != nil && choice.Type == ToolChoiceTypeNone {
+		// No other tool may be called, so offer only this one
+		req.Tools = nil
+		choice = nil
+	}
+	hasOtherTools := len(req.Tools) > 0
+	req.Tools = append(req.Tools, tool)
+	switch {
+	case thinking:
+		req.ToolChoice = choice
+	case choice == nil || choice.Type == ToolChoiceTypeAuto:
+		forced := &ToolChoice{Type: ToolChoiceTypeTool, Name: ResponseFormatToolName}
+		if hasOtherTools {
+			forced = &ToolChoice{Type: ToolChoiceTypeAny}
+		}
+		forced.DisableParallelToolUse = config.ParallelToolCalls != nil && !*config.ParallelToolCalls
+		req.ToolChoice = forced
+	default:
+		// An explicit "any" or named tool choice is kept
+		req.ToolChoice = choice
+	}
+}
+
+// emulatesResponseFormat reports whether the request asks for JSON output,
+// which applyResponseFormat provides with the response tool.
+func emulatesResponseFormat(config *llm.Config) bool {
+	format := config.ResponseFormat
+	return format != nil &&
+		(format.Type == llm.ResponseFormatTypeJSONSchema || format.Type == llm.ResponseFormatTypeJSON)
+}
+
+// convertResponseFormatResult replaces calls to the response tool with text
+// holding their input, so callers receive the JSON as they would from a
+// provider with native support. Input cut off by the output token limit is
+// completed with llm.RepairJSON when possible. When no other tool was
+// called, the stop reason becomes end_turn.
+func convertResponseFormatResult(response *llm.Response) {
+	converted, otherTools := false, false
+	for i, content := range response.Content {
+		toolUse, ok := content.(*llm.ToolUseContent)
+		if !ok {
+			continue
+		}
+		if toolUse.Name != ResponseFormatToolName {
+			otherTools = true
+			continue
+		}
+		input := toolUse.Input
+		if !json.Valid(input) {
+			if repaired, ok := llm.RepairJSON(input); ok {
+				input = repaired
+			}
+		}
+		response.Content[i] = &llm.TextContent{Text: string(input)}
+		converted = true
+	}
+	if converted && !otherTools && response.StopReason == llm.StopReasonToolUse {
+		response.StopReason = llm.StopReasonEndTurn
+	}
+}
+
+// responseFormatStream converts streamed calls to the response tool into
+// text blocks, like convertResponseFormatResult does for Generate.
+type responseFormatStream struct {
+	llm.StreamIterator
+	blocks     map[int]bool
+	otherTools bool
+}
+
+func newResponseFormatStream(stream llm.StreamIterator) *responseFormatStream {
+	return &responseFormatStream{StreamIterator: stream, blocks: map[int]bool{}}
+}
+
+func (s *responseFormatStream) Next() bool {
+	if !s.StreamIterator.Next() {
+		return false
+	}
+	event := s.StreamIterator.Event()
+	switch event.Type {
+	case llm.EventTypeContentBlockStart:
+		block := event.ContentBlock
+		if block == nil || block.Type != llm.ContentTypeToolUse || event.Index == nil {
+			break
+		}
+		if block.Name != ResponseFormatToolName {
+			s.otherTools = true
+			break
+		}
+		s.blocks[*event.Index] = true
+		event.ContentBlock = &llm.EventContentBlock{Type: llm.ContentTypeText}
+	case llm.EventTypeContentBlockDelta:
+		if event.Index != nil && s.blocks[*event.Index] && event.Delta != nil {
+			event.Delta = &llm.EventDelta{Type: llm.EventDeltaTypeText, Text: event.Delta.PartialJSON}
+		}
+	case llm.EventTypeMessageDelta:
+		if event.Delta != nil && event.Delta.StopReason == llm.StopReasonToolUse && len(s.blocks) > 0 && !s.otherTools {
+			event.Delta.StopReason = llm.StopReasonEndTurn
+		}
+	}
+	return true
+}
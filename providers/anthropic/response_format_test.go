@@ -0,0 +1,146 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+var testResponseFormat = &llm.ResponseFormat{
+	Type: llm.ResponseFormatTypeJSONSchema,
+	Schema: &schema.Schema{
+		Type:       schema.Object,
+		Properties: map[string]*schema.Property{"city": {Type: schema.String}},
+		Required:   []string{"city"},
+	},
+}
+
+func TestResponseFormatForcesTool(t *testing.T) {
+	req := buildReq(t, ModelClaudeOpus48, llm.WithResponseFormat(testResponseFormat))
+	assert.Len(t, req.Tools, 1)
+	assert.Equal(t, ResponseFormatToolName, req.Tools[0]["name"])
+	assert.Equal(t, testResponseFormat.Schema, req.Tools[0]["input_schema"])
+	assert.Equal(t, &ToolChoice{Type: ToolChoiceTypeTool, Name: ResponseFormatToolName}, req.ToolChoice)
+}
+
+func TestResponseFormatWithOtherTools(t *testing.T) {
+	t.Run("requires some tool call", func(t *testing.T) {
+		req := buildReq(t, ModelClaudeOpus48,
+			llm.WithResponseFormat(testResponseFormat),
+			llm.WithTools(reasoningTestTool()),
+			llm.WithParallelToolCalls(false))
+		assert.Len(t, req.Tools, 2)
+		assert.Equal(t, &ToolChoice{Type: ToolChoiceTypeAny, DisableParallelToolUse: true}, req.ToolChoice)
+	})
+
+	t.Run("tool choice none offers only the response tool", func(t *testing.T) {
+		req := buildReq(t, ModelClaudeOpus48,
+			llm.WithResponseFormat(testResponseFormat),
+			llm.WithTools(reasoningTestTool()),
+			llm.WithToolChoice(llm.ToolChoiceNone))
+		assert.Len(t, req.Tools, 1)
+		assert.Equal(t, ResponseFormatToolName, req.Tools[0]["name"])
+		assert.Equal(t, ToolChoiceTypeTool, req.ToolChoice.Type)
+	})
+
+	t.Run("thinking leaves tool choice unforced", func(t *testing.T) {
+		req := buildReq(t, ModelClaudeOpus48,
+			llm.WithResponseFormat(testResponseFormat),
+			llm.WithAdaptiveThinking())
+		assert.Len(t, req.Tools, 1)
+		assert.Nil(t, req.ToolChoice)
+	})
+}
+
+func TestResponseFormatJSONObject(t *testing.T) {
+	req := buildReq(t, ModelClaudeOpus48,
+		llm.WithResponseFormat(&llm.ResponseFormat{Type: llm.ResponseFormatTypeJSON}))
+	assert.Equal(t, &schema.Schema{Type: schema.Object}, req.Tools[0]["input_schema"])
+
+	req = buildReq(t, ModelClaudeOpus48,
+		llm.WithResponseFormat(&llm.ResponseFormat{Type: llm.ResponseFormatTypeText}))
+	assert.Len(t, req.Tools, 0)
+	assert.Nil(t, req.ToolChoice)
+}
+
+func TestConvertResponseFormatResult(t *testing.T) {
+	t.Run("tool call becomes text", func(t *testing.T) {
+		response := &llm.Response{
+			Content: []llm.Content{
+				&llm.ToolUseContent{ID: "t1", Name: ResponseFormatToolName, Input: []byte(`{"city":"Paris"}`)},
+			},
+			StopReason: llm.StopReasonToolUse,
+		}
+		convertResponseFormatResult(response)
+		assert.Equal(t, []llm.Content{&llm.TextContent{Text: `{"city":"Paris"}`}}, response.Content)
+		assert.Equal(t, llm.StopReasonEndTurn, response.StopReason)
+	})
+
+	t.Run("truncated input is repaired", func(t *testing.T) {
+		response := &llm.Response{
+			Content: []llm.Content{
+				&llm.ToolUseContent{ID: "t1", Name: ResponseFormatToolName, Input: []byte(`{"city":"Par`)},
+			},
+			StopReason: llm.StopReasonMaxTokens,
+		}
+		convertResponseFormatResult(response)
+		assert.Equal(t, `{"city":"Par"}`, response.Message().Text())
+		assert.Equal(t, llm.StopReasonMaxTokens, response.StopReason)
+	})
+
+	t.Run("other tool calls keep tool_use", func(t *testing.T) {
+		response := &llm.Response{
+			Content: []llm.Content{
+				&llm.ToolUseContent{ID: "t1", Name: "lookup", Input: []byte(`{}`)},
+			},
+			StopReason: llm.StopReasonToolUse,
+		}
+		convertResponseFormatResult(response)
+		_, ok := response.Content[0].(*llm.ToolUseContent)
+		assert.True(t, ok)
+		assert.Equal(t, llm.StopReasonToolUse, response.StopReason)
+	})
+}
+
+const structuredResponseStream = `data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"test-model","content":[]}}
+
+data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"t1","name":"structured_response","input":{}}}
+
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}
+
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}
+
+data: {"type":"content_block_stop","index":0}
+
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"}}
+
+data: {"type":"message_stop"}
+
+`
+
+func TestResponseFormatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, structuredResponseStream)
+	}))
+	defer server.Close()
+
+	provider := New(WithAPIKey("test-key"), WithEndpoint(server.URL))
+	iterator, err := provider.Stream(context.Background(),
+		llm.WithMessages(llm.NewUserTextMessage("Where is the Louvre?")),
+		llm.WithResponseFormat(testResponseFormat),
+	)
+	assert.NoError(t, err)
+	defer iterator.Close()
+
+	response := consumeAnthropicStream(t, iterator).Response()
+	assert.Equal(t, `{"city":"Paris"}`, response.Message().Text())
+	assert.Len(t, response.ToolCalls(), 0)
+	assert.Equal(t, llm.StopReasonEndTurn, response.StopReason)
+}
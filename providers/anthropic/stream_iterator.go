@@ -17,6 +17,7 @@ type StreamIterator struct {
 	prefill           string
 	prefillClosingTag string
 	closeOnce         sync.Once
+	usage             llm.Usage // cumulative usage reported so far
 }
 
 // Next advances to the next event in the stream. Returns true if an event was
@@ -48,6 +49,17 @@ func (s *StreamIterator) processEvent(event *llm.Event) *llm.Event {
 		return nil
 	}
 
+	switch event.Type {
+	case llm.EventTypeMessageStart:
+		if event.Message != nil {
+			s.usage = event.Message.Usage
+		}
+	case llm.EventTypeMessageDelta:
+		if event.Usage != nil {
+			event.Usage = usageDelta(&s.usage, event.Usage)
+		}
+	}
+
 	// Apply prefill logic for the first text content block
 	if s.prefill != "" && event.Type == llm.EventTypeContentBlockStart {
 		if event.ContentBlock != nil && event.ContentBlock.Type == llm.ContentTypeText {
@@ -62,6 +74,26 @@ func (s *StreamIterator) processEvent(event *llm.Event) *llm.Event {
 	return event
 }
 
+// usageDelta returns the usage added since seen, given the cumulative usage
+// of a message_delta event, and advances seen. Anthropic reports cumulative
+// counts, while llm.Event.Usage carries increments. A count missing from the
+// event decodes as zero and so adds nothing.
+func usageDelta(seen *llm.Usage, cumulative *llm.Usage) *llm.Usage {
+	delta := &llm.Usage{Speed: cumulative.Speed}
+	advance := func(seen *int, cumulative int, delta *int) {
+		if cumulative > *seen {
+			*delta = cumulative - *seen
+			*seen = cumulative
+		}
+	}
+	advance(&seen.InputTokens, cumulative.InputTokens, &delta.InputTokens)
+	advance(&seen.OutputTokens, cumulative.OutputTokens, &delta.OutputTokens)
+	advance(&seen.CacheCreationInputTokens, cumulative.CacheCreationInputTokens, &delta.CacheCreationInputTokens)
+	advance(&seen.CacheReadInputTokens, cumulative.CacheReadInputTokens, &delta.CacheReadInputTokens)
+	advance(&seen.ReasoningTokens, cumulative.ReasoningTokens, &delta.ReasoningTokens)
+	return delta
+}
+
 func (s *StreamIterator) Close() error {
 	var err error
 	s.closeOnce.Do(func() { err = s.body.Close() })
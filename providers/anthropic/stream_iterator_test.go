@@ -0,0 +1,72 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// usageStreamSSE reports usage the way the Messages API does: the input
+// counts in message_start, then cumulative counts in each message_delta.
+const usageStreamSSE = `data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"test-model","content":[],"usage":{"input_tokens":25,"cache_read_input_tokens":10,"output_tokens":1}}}
+
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+data: {"type":"message_delta","delta":{},"usage":{"output_tokens":8}}
+
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" world"}}
+
+data: {"type":"content_block_stop","index":0}
+
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"input_tokens":25,"cache_read_input_tokens":10,"output_tokens":15}}
+
+data: {"type":"message_stop"}
+
+`
+
+func TestStreamUsageDeltas(t *testing.T) {
+	client := &http.Client{Transport: anthropicRoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return anthropicResponse(req, io.NopCloser(strings.NewReader(usageStreamSSE))), nil
+	})}
+	provider := New(WithAPIKey("test-key"), WithClient(client))
+
+	iterator, err := provider.Stream(context.Background(), llm.WithUserTextMessage("Say hello"))
+	assert.NoError(t, err)
+	defer iterator.Close()
+
+	accumulator := llm.NewResponseAccumulator()
+	var summed llm.Usage
+	var outputDeltas []int
+	for iterator.Next() {
+		event := iterator.Event()
+		assert.NoError(t, accumulator.AddEvent(event))
+		if event.Type == llm.EventTypeMessageStart {
+			summed = event.Message.Usage
+		}
+		if event.Usage != nil {
+			summed.InputTokens += event.Usage.InputTokens
+			summed.OutputTokens += event.Usage.OutputTokens
+			summed.CacheReadInputTokens += event.Usage.CacheReadInputTokens
+			outputDeltas = append(outputDeltas, event.Usage.OutputTokens)
+			// The accumulator's usage is live while streaming
+			assert.Equal(t, summed.OutputTokens, accumulator.Usage().OutputTokens)
+		}
+	}
+	assert.NoError(t, iterator.Err())
+
+	assert.Equal(t, []int{7, 7}, outputDeltas)
+	final := accumulator.Response().Usage
+	assert.Equal(t, 25, final.InputTokens)
+	assert.Equal(t, 15, final.OutputTokens)
+	assert.Equal(t, 10, final.CacheReadInputTokens)
+	assert.Equal(t, summed.InputTokens, final.InputTokens)
+	assert.Equal(t, summed.OutputTokens, final.OutputTokens)
+	assert.Equal(t, summed.CacheReadInputTokens, final.CacheReadInputTokens)
+}
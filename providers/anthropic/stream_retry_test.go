@@ -170,3 +170,71 @@ func TestStreamDoesNotRetryBodyFailureAfterFirstEvent(t *testing.T) {
 	assert.Equal(t, int64(1), requests.Load())
 	assert.True(t, partialBody.closed.Load())
 }
+
+func TestStreamWithTransport(t *testing.T) {
+	var requests atomic.Int64
+	transport := anthropicRoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		return anthropicResponse(req, io.NopCloser(strings.NewReader(successfulAnthropicStream))), nil
+	})
+	provider := New(
+		WithAPIKey("test-key"),
+		WithTransport(transport),
+	)
+	assert.Equal(t, 0, int(provider.client.Timeout))
+
+	iterator, err := provider.Stream(context.Background(),
+		llm.WithMessages(llm.NewUserTextMessage("hello")),
+	)
+	assert.NoError(t, err)
+	defer iterator.Close()
+
+	accumulator := consumeAnthropicStream(t, iterator)
+	assert.True(t, accumulator.IsComplete())
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestStreamStopReleasesBlockedRequest(t *testing.T) {
+	for _, stop := range []string{"close", "cancel"} {
+		t.Run(stop, func(t *testing.T) {
+			disconnected := make(chan struct{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, strings.SplitAfter(successfulAnthropicStream, "\n\n")[0])
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+				disconnected <- struct{}{}
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			provider := New(WithAPIKey("test-key"), WithEndpoint(server.URL), WithMaxRetries(0))
+			iterator, err := provider.Stream(ctx, llm.WithMessages(llm.NewUserTextMessage("hello")))
+			assert.NoError(t, err)
+			defer iterator.Close()
+			assert.True(t, iterator.Next())
+
+			next := make(chan bool, 1)
+			go func() { next <- iterator.Next() }()
+			time.Sleep(20 * time.Millisecond)
+			if stop == "close" {
+				go iterator.Close()
+			} else {
+				cancel()
+			}
+			select {
+			case gotEvent := <-next:
+				assert.False(t, gotEvent)
+			case <-time.After(2 * time.Second):
+				t.Fatal("Next did not return after the stream was stopped")
+			}
+			select {
+			case <-disconnected:
+			case <-time.After(2 * time.Second):
+				t.Fatal("the HTTP request was not aborted")
+			}
+			assert.True(t, errors.Is(iterator.Err(), context.Canceled))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// toolStreamSSE builds a stream with one tool call whose input is delivered
+// as input_json_delta fragments of the given size, followed by a tool call
+// with no arguments.
+func toolStreamSSE(t *testing.T, input string, fragmentSize int) string {
+	t.Helper()
+	var b strings.Builder
+	event := func(v map[string]any) {
+		data, err := json.Marshal(v)
+		assert.NoError(t, err)
+		fmt.Fprintf(&b, "data: %s\n\n", data)
+	}
+	delta := func(index int, partial string) {
+		event(map[string]any{"type": "content_block_delta", "index": index,
+			"delta": map[string]any{"type": "input_json_delta", "partial_json": partial}})
+	}
+	event(map[string]any{"type": "message_start", "message": map[string]any{
+		"id": "msg_1", "type": "message", "role": "assistant", "model": "test-model", "content": []any{}}})
+	event(map[string]any{"type": "content_block_start", "index": 0, "content_block": map[string]any{
+		"type": "tool_use", "id": "toolu_1", "name": "write_report", "input": map[string]any{}}})
+	delta(0, "")
+	// Fragments are JSON strings, so they split on character boundaries.
+	runes := []rune(input)
+	for i := 0; i < len(runes); i += fragmentSize {
+		delta(0, string(runes[i:min(i+fragmentSize, len(runes))]))
+	}
+	event(map[string]any{"type": "content_block_stop", "index": 0})
+	event(map[string]any{"type": "content_block_start", "index": 1, "content_block": map[string]any{
+		"type": "tool_use", "id": "toolu_2", "name": "list_files", "input": map[string]any{}}})
+	delta(1, "")
+	event(map[string]any{"type": "content_block_stop", "index": 1})
+	event(map[string]any{"type": "message_delta", "delta": map[string]any{"stop_reason": "tool_use"}})
+	event(map[string]any{"type": "message_stop"})
+	return b.String()
+}
+
+func TestStreamFineGrainedToolInput(t *testing.T) {
+	sections := make([]map[string]any, 200)
+	for i := range sections {
+		sections[i] = map[string]any{
+			"title": fmt.Sprintf("Section %d: \"quoted\" café ✓", i),
+			"body":  "line one\nline two\tindented \\ backslash",
+			"score": float64(i) * 1.5,
+			"draft": i%2 == 0,
+		}
+	}
+	expected, err := json.Marshal(map[string]any{"title": "Quarterly report", "sections": sections})
+	assert.NoError(t, err)
+
+	var betaHeader string
+	client := &http.Client{Transport: anthropicRoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		betaHeader = req.Header.Get("anthropic-beta")
+		// A fragment size of 7 splits escape sequences across deltas.
+		body := toolStreamSSE(t, string(expected), 7)
+		return anthropicResponse(req, io.NopCloser(strings.NewReader(body))), nil
+	})}
+	provider := New(WithAPIKey("test-key"), WithClient(client))
+
+	iterator, err := provider.Stream(context.Background(),
+		llm.WithMessages(llm.NewUserTextMessage("Write the report")),
+		llm.WithFeatures(FeatureFineGrainedToolStreaming),
+	)
+	assert.NoError(t, err)
+	defer iterator.Close()
+
+	accumulator := llm.NewResponseAccumulator()
+	var streamed strings.Builder
+	var deltas int
+	for iterator.Next() {
+		event := iterator.Event()
+		if event.Type == llm.EventTypeContentBlockDelta && *event.Index == 0 &&
+			event.Delta.Type == llm.EventDeltaTypeInputJSON {
+			streamed.WriteString(event.Delta.PartialJSON)
+			deltas++
+		}
+		assert.NoError(t, accumulator.AddEvent(event))
+	}
+	assert.NoError(t, iterator.Err())
+	assert.Equal(t, FeatureFineGrainedToolStreaming, betaHeader)
+
+	// Each fragment is surfaced as its own event, not buffered.
+	assert.Greater(t, deltas, 100)
+	assert.Equal(t, string(expected), streamed.String())
+
+	calls := accumulator.Response().ToolCalls()
+	assert.Len(t, calls, 2)
+	assert.Equal(t, "write_report", calls[0].Name)
+	assert.Equal(t, string(expected), string(calls[0].Input))
+	assert.False(t, calls[0].Repaired)
+	assert.Equal(t, "list_files", calls[1].Name)
+	assert.Equal(t, "{}", string(calls[1].Input))
+}
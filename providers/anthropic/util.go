@@ -3,6 +3,7 @@ package anthropic
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/deepnoodle-ai/dive/llm"
 )
@@ -50,3 +51,37 @@ func addPrefill(blocks []llm.Content, prefill, closingTag string) error {
 	}
 	return fmt.Errorf("no text content found in message")
 }
+
+// validateAPIVersion checks that version is an anthropic-version date.
+func validateAPIVersion(version string) error {
+	if _, err := time.Parse(time.DateOnly, version); err != nil {
+		return fmt.Errorf("invalid anthropic api version %q: expected a date like %q", version, DefaultVersion)
+	}
+	return nil
+}
+
+// anthropicTextContent clones a text block for a request, dropping
+// citations from other providers that the API would reject.
+func anthropicTextContent(c *llm.TextContent) *llm.TextContent {
+	clone := c.CloneContent().(*llm.TextContent)
+	var citations []llm.Citation
+	for _, citation := range clone.Citations {
+		switch citation.(type) {
+		case *llm.CharLocation, *llm.WebSearchResultLocation:
+			citations = append(citations, citation)
+		}
+	}
+	clone.Citations = citations
+	return clone
+}
+
+// foldDeveloperMessages moves leading developer messages into a system
+// block after the system prompt, which Anthropic has no separate role for.
+// Later developer messages are sent as user messages.
+func foldDeveloperMessages(req *Request, messages []*llm.Message) []*llm.Message {
+	instructions, messages := llm.FoldDeveloperMessages(messages)
+	if instructions != "" {
+		req.System = append(req.System, &SystemBlock{Type: "text", Text: instructions})
+	}
+	return messages
+}
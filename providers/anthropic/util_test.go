@@ -38,3 +38,35 @@ func TestConvertMessagesDoesNotMutateCaller(t *testing.T) {
 	assert.Equal(t, copied[1].Content[0].Type(), llm.ContentTypeText)
 	assert.Equal(t, copied[1].Content[1].Type(), llm.ContentTypeToolUse)
 }
+
+func TestConvertMessagesDropsForeignCitations(t *testing.T) {
+	web := &llm.WebSearchResultLocation{Type: "web_search_result_location", URL: "https://go.dev"}
+	text := &llm.TextContent{Text: "Go is fast.", Citations: []llm.Citation{
+		web,
+		&llm.CitationContent{URL: "https://example.com"},
+	}}
+	messages := []*llm.Message{
+		llm.NewUserTextMessage("Tell me about Go"),
+		{Role: llm.Assistant, Content: []llm.Content{text}},
+		llm.NewUserTextMessage("Thanks"),
+	}
+
+	copied, err := convertMessages(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, []llm.Citation{web}, copied[1].Content[0].(*llm.TextContent).Citations)
+	assert.Len(t, text.Citations, 2)
+}
+
+func TestFoldDeveloperMessages(t *testing.T) {
+	req := &Request{System: []*SystemBlock{{Type: "text", Text: "Be friendly."}}}
+	messages := foldDeveloperMessages(req, []*llm.Message{
+		llm.NewDeveloperMessage("Answer in French."),
+		llm.NewUserTextMessage("Hello"),
+	})
+	// The system prompt block is unchanged, keeping its cache prefix
+	assert.Len(t, req.System, 2)
+	assert.Equal(t, "Be friendly.", req.System[0].Text)
+	assert.Equal(t, "Answer in French.", req.System[1].Text)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, llm.User, messages[0].Role)
+}
@@ -0,0 +1,293 @@
+// Package bedrock provides an LLM provider for Amazon Bedrock. Claude models
+// (anthropic.*) use the Anthropic Messages API through Bedrock, with the
+// same features as the anthropic provider, and Amazon Titan text models
+// (amazon.titan-*) use their native API. Requests are signed with AWS
+// Signature Version 4 using the standard AWS credential chain:
+// environment variables, the shared config and credentials files, and IAM
+// roles for ECS tasks and EC2 instances.
+package bedrock
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers/anthropic"
+	"github.com/deepnoodle-ai/wonton/retry"
+)
+
+const ProviderName = "bedrock"
+
+var (
+	DefaultModel         = ModelClaudeSonnet4520250929
+	DefaultMaxTokens     = 32768
+	DefaultClient        = &http.Client{Timeout: 300 * time.Second}
+	DefaultMaxRetries    = 3
+	DefaultRetryBaseWait = 2 * time.Second
+
+	// DefaultAnthropicVersion is the anthropic_version sent with Claude
+	// requests unless pinned with WithAPIVersion.
+	DefaultAnthropicVersion = "bedrock-2023-05-31"
+)
+
+var _ llm.StreamingLLM = &Provider{}
+var _ llm.ToolLimiter = &Provider{}
+var _ llm.ModelInfoProvider = &Provider{}
+
+// Provider implements the Amazon Bedrock LLM provider.
+type Provider struct {
+	region           string
+	endpoint         string
+	credentials      aws.CredentialsProvider
+	client           *http.Client
+	model            string
+	maxTokens        int
+	maxRetries       int
+	retryBaseWait    time.Duration
+	anthropicVersion string
+	signer           *v4.Signer
+
+	// claude sends Claude requests, with a transport that signs them and
+	// routes them to Bedrock
+	claude *anthropic.Provider
+
+	// loadMu guards loading the AWS config; loaded is set once it succeeds,
+	// so a failed load is retried by the next request
+	loadMu sync.Mutex
+	loaded bool
+}
+
+// New creates a new Bedrock provider with the given options. The AWS
+// region and credentials are resolved on the first request, so New doesn't
+// fail when they are missing; the request does.
+func New(opts ...Option) *Provider {
+	p := &Provider{
+		client:           DefaultClient,
+		model:            DefaultModel,
+		maxTokens:        DefaultMaxTokens,
+		maxRetries:       DefaultMaxRetries,
+		retryBaseWait:    DefaultRetryBaseWait,
+		anthropicVersion: DefaultAnthropicVersion,
+		signer:           v4.NewSigner(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.claude = anthropic.New(
+		anthropic.WithName(ProviderName),
+		anthropic.WithClient(&http.Client{Transport: &claudeTransport{provider: p}}),
+		anthropic.WithEndpoint("https://bedrock.invalid/v1/messages"),
+		anthropic.WithMaxTokens(p.maxTokens),
+		anthropic.WithMaxRetries(p.maxRetries),
+		anthropic.WithBaseWait(p.retryBaseWait),
+		anthropic.WithModel(claudeModelName(p.model)),
+	)
+	return p
+}
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// ToolLimits implements llm.ToolLimiter with the limits of Claude.
+func (p *Provider) ToolLimits() llm.ToolLimits {
+	return p.claude.ToolLimits()
+}
+
+// ModelInfo implements llm.ModelInfoProvider. Claude models report the
+// limits of the Anthropic API, except for requests, which Bedrock limits
+// to 20 MB. Titan text models have an 8k or 32k context window.
+func (p *Provider) ModelInfo(model string) llm.ModelInfo {
+	if model == "" {
+		model = p.model
+	}
+	if isTitanModel(model) {
+		info := llm.ModelInfo{Model: model, ContextWindow: 8192}
+		if strings.Contains(model, "premier") {
+			info.ContextWindow = 32768
+		}
+		return info
+	}
+	info := p.claude.ModelInfo(claudeModelName(model))
+	info.Model = model
+	info.MaxRequestBytes = 20 << 20
+	return info
+}
+
+func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	config := &llm.Config{}
+	config.Apply(opts...)
+	model := cmp.Or(config.Model, p.model)
+	switch {
+	case isTitanModel(model):
+		return p.generateTitan(ctx, model, config)
+	case isClaudeModel(model):
+		return p.claude.Generate(ctx, claudeConfig(config, model))
+	}
+	return nil, unsupportedModelError(model)
+}
+
+func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIterator, error) {
+	config := &llm.Config{}
+	config.Apply(opts...)
+	model := cmp.Or(config.Model, p.model)
+	switch {
+	case isTitanModel(model):
+		return p.streamTitan(ctx, model, config)
+	case isClaudeModel(model):
+		return p.claude.Stream(ctx, claudeConfig(config, model))
+	}
+	return nil, unsupportedModelError(model)
+}
+
+func unsupportedModelError(model string) error {
+	return fmt.Errorf("bedrock: unsupported model %q; use an anthropic.* or amazon.titan-* model", model)
+}
+
+// isClaudeModel reports whether model is a Claude model ID, inference
+// profile ID, or inference profile ARN.
+func isClaudeModel(model string) bool {
+	return strings.Contains(model, "anthropic.")
+}
+
+func isTitanModel(model string) bool {
+	return strings.HasPrefix(model, "amazon.titan-")
+}
+
+// claudeModelName returns the Anthropic model name within a Bedrock model
+// ID, so "us.anthropic.claude-sonnet-4-5-20250929-v1:0" becomes
+// "claude-sonnet-4-5-20250929-v1:0". The anthropic provider uses the name
+// to decide which features the model supports.
+func claudeModelName(model string) string {
+	if _, name, ok := strings.Cut(model, "anthropic."); ok {
+		return name
+	}
+	return model
+}
+
+// modelIDHeader carries the Bedrock model ID of a Claude request from
+// claudeConfig to claudeTransport. It is removed before sending.
+const modelIDHeader = "X-Dive-Bedrock-Model-Id"
+
+// claudeConfig returns an option that sets config for the anthropic
+// provider, with the model's Anthropic name and its Bedrock ID in
+// modelIDHeader.
+func claudeConfig(config *llm.Config, model string) llm.Option {
+	resolved := *config
+	resolved.Model = claudeModelName(model)
+	resolved.RequestHeaders = config.RequestHeaders.Clone()
+	if resolved.RequestHeaders == nil {
+		resolved.RequestHeaders = http.Header{}
+	}
+	resolved.RequestHeaders.Set(modelIDHeader, model)
+	return func(c *llm.Config) { *c = resolved }
+}
+
+// load resolves the region and credentials from the AWS credential chain
+// unless they were set with options. A load that fails is tried again on
+// the next call, so a transient failure isn't cached.
+func (p *Provider) load(ctx context.Context) error {
+	p.loadMu.Lock()
+	defer p.loadMu.Unlock()
+	if !p.loaded && (p.credentials == nil || (p.region == "" && p.endpoint == "")) {
+		var opts []func(*config.LoadOptions) error
+		if p.region != "" {
+			opts = append(opts, config.WithRegion(p.region))
+		}
+		cfg, err := config.LoadDefaultConfig(context.WithoutCancel(ctx), opts...)
+		if err != nil {
+			return fmt.Errorf("bedrock: loading aws config: %w", err)
+		}
+		p.region = cmp.Or(p.region, cfg.Region)
+		if p.credentials == nil {
+			p.credentials = cfg.Credentials
+		}
+	}
+	p.loaded = true
+	if p.region == "" && p.endpoint == "" {
+		return fmt.Errorf("bedrock: no aws region; set AWS_REGION or use WithRegion")
+	}
+	return nil
+}
+
+// invoke sends body to the model with InvokeModel, or with
+// InvokeModelWithResponseStream if stream is set, adding the extra request
+// headers in header. It returns the response whatever its status.
+// Configuration errors are permanent, so the request isn't retried, though
+// a later request loads the AWS config again.
+func (p *Provider) invoke(ctx context.Context, model string, body []byte, stream bool, header http.Header) (*http.Response, error) {
+	if err := p.load(ctx); err != nil {
+		return nil, retry.MarkPermanent(err)
+	}
+	endpoint := p.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", p.region)
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, retry.MarkPermanent(fmt.Errorf("bedrock: invalid endpoint: %w", err))
+	}
+	action, accept := "invoke", "application/json"
+	if stream {
+		action, accept = "invoke-with-response-stream", "application/vnd.amazon.eventstream"
+	}
+	// Model IDs contain ":" and ARNs contain "/", which Bedrock expects
+	// escaped in the path
+	escaped := strings.ReplaceAll(url.PathEscape(model), ":", "%3A")
+	u.RawPath = u.EscapedPath() + "/model/" + escaped + "/" + action
+	u.Path = u.Path + "/model/" + model + "/" + action
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("accept", accept)
+	if err := p.sign(ctx, req, body); err != nil {
+		return nil, err
+	}
+	return p.client.Do(req)
+}
+
+// sign adds an AWS Signature Version 4 to req.
+func (p *Provider) sign(ctx context.Context, req *http.Request, body []byte) error {
+	credentials, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return retry.MarkPermanent(fmt.Errorf("bedrock: retrieving aws credentials: %w", err))
+	}
+	hash := sha256.Sum256(body)
+	region := p.region
+	if region == "" {
+		region = regionFromEndpoint(req.URL.Host)
+	}
+	return p.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), "bedrock", region, time.Now())
+}
+
+// regionFromEndpoint returns the region in a bedrock-runtime host name, or
+// us-east-1 for a custom endpoint without one.
+func regionFromEndpoint(host string) string {
+	parts := strings.Split(host, ".")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "bedrock-runtime") && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return "us-east-1"
+}
@@ -0,0 +1,246 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+var testCredentials = aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+	return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+})
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc, opts ...Option) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	opts = append([]Option{
+		WithEndpoint(server.URL),
+		WithRegion("us-west-2"),
+		WithCredentials(testCredentials),
+		WithMaxRetries(0),
+	}, opts...)
+	return New(opts...)
+}
+
+// writeChunks writes events as the chunks of an event stream response.
+func writeChunks(t *testing.T, w http.ResponseWriter, events ...string) {
+	t.Helper()
+	w.Header().Set("content-type", "application/vnd.amazon.eventstream")
+	encoder := eventstream.NewEncoder()
+	for _, event := range events {
+		payload, err := json.Marshal(map[string][]byte{"bytes": []byte(event)})
+		assert.NoError(t, err)
+		var msg eventstream.Message
+		msg.Headers.Set(":message-type", eventstream.StringValue("event"))
+		msg.Headers.Set(":event-type", eventstream.StringValue("chunk"))
+		msg.Payload = payload
+		assert.NoError(t, encoder.Encode(w, msg))
+	}
+}
+
+func TestClaudeGenerate(t *testing.T) {
+	var path, auth string
+	var body map[string]any
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		auth = r.Header.Get("Authorization")
+		assert.Equal(t, "", r.Header.Get("x-api-key"))
+		assert.Equal(t, "variant-b", r.Header.Get("X-Experiment"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5-20250929",
+			"content":[{"type":"text","text":"Hello from Bedrock"}],"stop_reason":"end_turn",
+			"usage":{"input_tokens":12,"output_tokens":4}}`)
+	})
+
+	response, err := p.Generate(context.Background(),
+		llm.WithUserTextMessage("hi"),
+		llm.WithFeatures("context-1m-2025-08-07"),
+		llm.WithRequestHeaders(http.Header{"X-Experiment": []string{"variant-b"}}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello from Bedrock", response.Message().Text())
+	assert.Equal(t, 12, response.Usage.InputTokens)
+
+	assert.Equal(t, "/model/anthropic.claude-sonnet-4-5-20250929-v1%3A0/invoke", path)
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+	assert.Contains(t, auth, "/us-west-2/bedrock/aws4_request")
+	assert.Equal(t, DefaultAnthropicVersion, body["anthropic_version"])
+	assert.Equal(t, []any{"context-1m-2025-08-07"}, body["anthropic_beta"])
+	_, hasModel := body["model"]
+	assert.False(t, hasModel)
+	assert.NotNil(t, body["messages"])
+}
+
+func TestClaudeStream(t *testing.T) {
+	var path string
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, hasStream := body["stream"]
+		assert.False(t, hasStream)
+		writeChunks(t, w,
+			`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-haiku-4-5","content":[],"usage":{"input_tokens":9,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" there"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":3}}`,
+			`{"type":"message_stop"}`,
+		)
+	}, WithModel("us."+ModelClaudeHaiku4520251001))
+
+	stream, err := p.Stream(context.Background(), llm.WithUserTextMessage("hi"))
+	assert.NoError(t, err)
+	defer stream.Close()
+	accumulator := llm.NewResponseAccumulator()
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Err())
+	response := accumulator.Response()
+	assert.Equal(t, "Hello there", response.Message().Text())
+	assert.Equal(t, llm.StopReasonEndTurn, response.StopReason)
+	assert.Equal(t, "/model/us.anthropic.claude-haiku-4-5-20251001-v1%3A0/invoke-with-response-stream", path)
+}
+
+func TestStreamException(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		var msg eventstream.Message
+		msg.Headers.Set(":message-type", eventstream.StringValue("exception"))
+		msg.Headers.Set(":exception-type", eventstream.StringValue("throttlingException"))
+		msg.Payload = []byte(`{"message":"Too many requests"}`)
+		assert.NoError(t, eventstream.NewEncoder().Encode(w, msg))
+	})
+
+	stream, err := p.Stream(context.Background(), llm.WithUserTextMessage("hi"))
+	assert.NoError(t, err)
+	defer stream.Close()
+	assert.False(t, stream.Next())
+	var providerErr *providers.ProviderError
+	assert.ErrorAs(t, stream.Err(), &providerErr)
+	assert.Equal(t, http.StatusTooManyRequests, providerErr.StatusCode())
+	assert.Contains(t, providerErr.Error(), "Too many requests")
+}
+
+func TestTitanGenerate(t *testing.T) {
+	var request titanRequest
+	var path string
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		io.WriteString(w, `{"inputTextTokenCount":11,"results":[{"tokenCount":3,"outputText":" Paris.","completionReason":"FINISH"}]}`)
+	}, WithModel(ModelTitanTextExpress))
+
+	response, err := p.Generate(context.Background(),
+		llm.WithSystemPrompt("Answer briefly."),
+		llm.WithMessages(
+			llm.NewUserTextMessage("Capital of Spain?"),
+			llm.NewAssistantTextMessage("Madrid."),
+			llm.NewUserTextMessage("And France?"),
+		),
+		llm.WithMaxTokens(50),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "/model/amazon.titan-text-express-v1/invoke", path)
+	assert.Equal(t, "Answer briefly.\n\nUser: Capital of Spain?\nBot: Madrid.\nUser: And France?\nBot:", request.InputText)
+	assert.Equal(t, 50, request.TextGenerationConfig.MaxTokenCount)
+	assert.Equal(t, " Paris.", response.Message().Text())
+	assert.Equal(t, llm.StopReasonEndTurn, response.StopReason)
+	assert.Equal(t, llm.Usage{InputTokens: 11, OutputTokens: 3}, response.Usage)
+
+	_, err = p.Generate(context.Background(),
+		llm.WithUserTextMessage("hi"),
+		llm.WithTools(llm.NewToolDefinition().WithName("search")),
+	)
+	assert.ErrorIs(t, err, llm.ErrNotSupported)
+}
+
+func TestTitanStream(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		writeChunks(t, w,
+			`{"outputText":"Once upon","index":0,"inputTextTokenCount":5,"totalOutputTextTokenCount":2,"completionReason":null}`,
+			`{"outputText":" a time","index":0,"totalOutputTextTokenCount":4,"completionReason":"LENGTH"}`,
+		)
+	}, WithModel(ModelTitanTextLite))
+
+	stream, err := p.Stream(context.Background(), llm.WithUserTextMessage("Tell a story"))
+	assert.NoError(t, err)
+	defer stream.Close()
+	var types []llm.EventType
+	accumulator := llm.NewResponseAccumulator()
+	for stream.Next() {
+		types = append(types, stream.Event().Type)
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, []llm.EventType{
+		llm.EventTypeMessageStart,
+		llm.EventTypeContentBlockStart,
+		llm.EventTypeContentBlockDelta,
+		llm.EventTypeContentBlockDelta,
+		llm.EventTypeContentBlockStop,
+		llm.EventTypeMessageDelta,
+		llm.EventTypeMessageStop,
+	}, types)
+	response := accumulator.Response()
+	assert.Equal(t, "Once upon a time", response.Message().Text())
+	assert.Equal(t, llm.StopReasonMaxTokens, response.StopReason)
+	assert.Equal(t, 5, response.Usage.InputTokens)
+	assert.Equal(t, 4, response.Usage.OutputTokens)
+}
+
+func TestUnsupportedModel(t *testing.T) {
+	p := New(WithModel("meta.llama3-70b-instruct-v1:0"))
+	_, err := p.Generate(context.Background(), llm.WithUserTextMessage("hi"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported model")
+}
+
+func TestModelInfo(t *testing.T) {
+	p := New()
+	info := p.ModelInfo("us." + ModelClaudeSonnet4520250929)
+	assert.Equal(t, "us."+ModelClaudeSonnet4520250929, info.Model)
+	assert.Equal(t, 200_000, info.ContextWindow)
+	assert.Equal(t, 20<<20, info.MaxRequestBytes)
+	assert.Equal(t, 32768, p.ModelInfo(ModelTitanTextPremier).ContextWindow)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Setenv("AWS_REGION", "us-east-1")
+	for _, model := range []string{ModelClaudeSonnet4520250929, "us." + ModelClaudeOpus4520251101, ModelTitanTextExpress} {
+		assert.Equal(t, ProviderName, providers.CreateModel(model, "").Name(), model)
+	}
+	t.Setenv("AWS_REGION", "")
+	if model := providers.CreateModel(ModelTitanTextExpress, ""); model != nil {
+		assert.NotEqual(t, ProviderName, model.Name())
+	}
+}
+
+func TestLoadRetriesAfterError(t *testing.T) {
+	// A missing profile fails the load; once it's fixed, the next call works
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", dir+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", dir+"/credentials")
+	t.Setenv("AWS_PROFILE", "dive-missing")
+	p := New(WithRegion("us-west-2"))
+	assert.Error(t, p.load(context.Background()))
+
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	assert.NoError(t, p.load(context.Background()))
+	assert.NotNil(t, p.credentials)
+}
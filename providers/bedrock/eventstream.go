@@ -0,0 +1,126 @@
+package bedrock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+// chunkReader reads the chunks of an InvokeModelWithResponseStream
+// response. Each chunk holds one event of the model's native streaming
+// format as JSON.
+type chunkReader struct {
+	body    io.Reader
+	decoder *eventstream.Decoder
+}
+
+func newChunkReader(body io.Reader) *chunkReader {
+	return &chunkReader{body: body, decoder: eventstream.NewDecoder()}
+}
+
+// Next returns the next chunk, or io.EOF at the end of the stream. An
+// exception sent in the stream is returned as a providers error with the
+// matching HTTP status, so throttling is retried like a 429 response.
+func (r *chunkReader) Next() ([]byte, error) {
+	for {
+		msg, err := r.decoder.Decode(r.body, nil)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("bedrock: decoding event stream: %w", err)
+		}
+		switch headerString(msg.Headers, ":message-type") {
+		case "exception":
+			return nil, streamException(headerString(msg.Headers, ":exception-type"), msg.Payload)
+		case "error":
+			return nil, providers.NewError(http.StatusInternalServerError, fmt.Sprintf("%s: %s",
+				headerString(msg.Headers, ":error-code"), headerString(msg.Headers, ":error-message")))
+		}
+		if headerString(msg.Headers, ":event-type") != "chunk" {
+			continue
+		}
+		var chunk struct {
+			Bytes []byte `json:"bytes"`
+		}
+		if err := json.Unmarshal(msg.Payload, &chunk); err != nil {
+			return nil, fmt.Errorf("bedrock: decoding chunk: %w", err)
+		}
+		return chunk.Bytes, nil
+	}
+}
+
+func headerString(headers eventstream.Headers, name string) string {
+	if value := headers.Get(name); value != nil {
+		return value.String()
+	}
+	return ""
+}
+
+// streamException converts an exception sent in a response stream to a
+// providers error.
+func streamException(exceptionType string, payload []byte) error {
+	status := http.StatusInternalServerError
+	switch exceptionType {
+	case "throttlingException":
+		status = http.StatusTooManyRequests
+	case "serviceUnavailableException":
+		status = http.StatusServiceUnavailable
+	case "validationException":
+		status = http.StatusBadRequest
+	case "modelTimeoutException":
+		status = http.StatusRequestTimeout
+	case "modelStreamErrorException":
+		status = http.StatusFailedDependency
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	message := string(payload)
+	if json.Unmarshal(payload, &body) == nil && body.Message != "" {
+		message = body.Message
+	}
+	return providers.NewDetailedError(status, fmt.Sprintf("%s: %s", exceptionType, message), providers.ErrorDetails{
+		Code:    exceptionType,
+		Message: message,
+	})
+}
+
+// eventStreamSSEBody presents the chunks of a Claude response stream as
+// server-sent events, the encoding of the Anthropic API, so the anthropic
+// provider's stream iterator can read them.
+type eventStreamSSEBody struct {
+	body   io.ReadCloser
+	chunks *chunkReader
+	buf    bytes.Buffer
+}
+
+func newEventStreamSSEBody(body io.ReadCloser) *eventStreamSSEBody {
+	return &eventStreamSSEBody{body: body, chunks: newChunkReader(body)}
+}
+
+func (b *eventStreamSSEBody) Read(p []byte) (int, error) {
+	for b.buf.Len() == 0 {
+		chunk, err := b.chunks.Next()
+		if err != nil {
+			return 0, err
+		}
+		// Events are sent one per line
+		b.buf.WriteString("data: ")
+		if err := json.Compact(&b.buf, chunk); err != nil {
+			return 0, fmt.Errorf("bedrock: invalid chunk: %w", err)
+		}
+		b.buf.WriteString("\n\n")
+	}
+	return b.buf.Read(p)
+}
+
+func (b *eventStreamSSEBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,19 @@
+package bedrock
+
+const (
+	// Claude models. Newer models are only available on demand through a
+	// cross-region inference profile, named by prefixing the model ID with
+	// a geography such as "us." or "eu.".
+	ModelClaude35Haiku20241022  = "anthropic.claude-3-5-haiku-20241022-v1:0"
+	ModelClaude37Sonnet20250219 = "anthropic.claude-3-7-sonnet-20250219-v1:0"
+	ModelClaudeSonnet420250514  = "anthropic.claude-sonnet-4-20250514-v1:0"
+	ModelClaudeOpus4120250805   = "anthropic.claude-opus-4-1-20250805-v1:0"
+	ModelClaudeHaiku4520251001  = "anthropic.claude-haiku-4-5-20251001-v1:0"
+	ModelClaudeSonnet4520250929 = "anthropic.claude-sonnet-4-5-20250929-v1:0"
+	ModelClaudeOpus4520251101   = "anthropic.claude-opus-4-5-20251101-v1:0"
+
+	// Amazon Titan text models
+	ModelTitanTextPremier = "amazon.titan-text-premier-v1:0"
+	ModelTitanTextExpress = "amazon.titan-text-express-v1"
+	ModelTitanTextLite    = "amazon.titan-text-lite-v1"
+)
@@ -0,0 +1,90 @@
+package bedrock
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+// Option configures the Bedrock provider.
+type Option func(*Provider)
+
+// WithRegion sets the AWS region, overriding AWS_REGION and the shared
+// config file.
+func WithRegion(region string) Option {
+	return func(p *Provider) {
+		p.region = region
+	}
+}
+
+// WithCredentials sets the AWS credentials provider, replacing the default
+// credential chain. Use credentials.NewStaticCredentialsProvider for fixed
+// keys.
+func WithCredentials(credentials aws.CredentialsProvider) Option {
+	return func(p *Provider) {
+		p.credentials = credentials
+	}
+}
+
+// WithEndpoint sets the Bedrock Runtime endpoint URL, such as a VPC
+// endpoint. Defaults to the public endpoint of the region.
+func WithEndpoint(endpoint string) Option {
+	return func(p *Provider) {
+		p.endpoint = endpoint
+	}
+}
+
+// WithClient sets the HTTP client.
+func WithClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// WithTransport sets the HTTP transport used for all API requests. It
+// replaces the HTTP client with one that has no overall timeout, so bound
+// requests with context deadlines instead. See providers.NewTransportClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(p *Provider) {
+		p.client = providers.NewTransportClient(transport)
+	}
+}
+
+// WithModel sets the Bedrock model ID, such as ModelClaudeSonnet4520250929
+// or an inference profile ID like "us.anthropic.claude-sonnet-4-5-20250929-v1:0".
+func WithModel(model string) Option {
+	return func(p *Provider) {
+		p.model = model
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens to generate.
+func WithMaxTokens(maxTokens int) Option {
+	return func(p *Provider) {
+		p.maxTokens = maxTokens
+	}
+}
+
+// WithMaxRetries sets the maximum number of retries for failed requests.
+func WithMaxRetries(maxRetries int) Option {
+	return func(p *Provider) {
+		p.maxRetries = maxRetries
+	}
+}
+
+// WithBaseWait sets the base wait time between retries.
+func WithBaseWait(baseWait time.Duration) Option {
+	return func(p *Provider) {
+		p.retryBaseWait = baseWait
+	}
+}
+
+// WithAPIVersion pins the anthropic_version sent with Claude requests.
+// Defaults to DefaultAnthropicVersion.
+func WithAPIVersion(version string) Option {
+	return func(p *Provider) {
+		p.anthropicVersion = version
+	}
+}
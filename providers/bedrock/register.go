@@ -0,0 +1,31 @@
+package bedrock
+
+import (
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+func init() {
+	claude := providers.ContainsMatcher("anthropic.")
+	titan := providers.PrefixMatcher("amazon.titan-")
+
+	// Bedrock authenticates with the AWS credential chain rather than an
+	// API key, so a configured region is what marks it as available.
+	providers.Register(providers.ProviderEntry{
+		Name: ProviderName,
+		Match: providers.EnvMatcher("AWS_REGION", func(model string) bool {
+			return claude(model) || titan(model)
+		}),
+		Factory:          factory,
+		APIKeyEnv:        []string{"AWS_REGION"},
+		HealthCheckModel: ModelClaudeHaiku4520251001,
+	})
+}
+
+func factory(model, endpoint string) llm.LLM {
+	opts := []Option{WithModel(model)}
+	if endpoint != "" {
+		opts = append(opts, WithEndpoint(endpoint))
+	}
+	return New(opts...)
+}
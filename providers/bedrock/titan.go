@@ -0,0 +1,324 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/retry"
+)
+
+// titanRequest is the body of a Titan text request.
+type titanRequest struct {
+	InputText            string      `json:"inputText"`
+	TextGenerationConfig titanConfig `json:"textGenerationConfig"`
+}
+
+type titanConfig struct {
+	MaxTokenCount int      `json:"maxTokenCount,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+type titanResponse struct {
+	InputTextTokenCount int `json:"inputTextTokenCount"`
+	Results             []struct {
+		TokenCount       int    `json:"tokenCount"`
+		OutputText       string `json:"outputText"`
+		CompletionReason string `json:"completionReason"`
+	} `json:"results"`
+}
+
+// titanChunk is one chunk of a streamed Titan response.
+type titanChunk struct {
+	OutputText                string `json:"outputText"`
+	InputTextTokenCount       int    `json:"inputTextTokenCount"`
+	TotalOutputTextTokenCount int    `json:"totalOutputTextTokenCount"`
+	CompletionReason          string `json:"completionReason"`
+}
+
+// titanBody encodes the request for a Titan text model. Titan takes a
+// single prompt, so the conversation is written as a transcript of "User:"
+// and "Bot:" turns after the system prompt. Tools and content other than
+// text aren't supported.
+func (p *Provider) titanBody(config *llm.Config) ([]byte, error) {
+	if len(config.Tools) > 0 {
+		return nil, fmt.Errorf("bedrock: titan models don't support tools: %w", llm.ErrNotSupported)
+	}
+	instructions, messages := llm.FoldDeveloperMessages(config.Messages)
+	var prompt strings.Builder
+	for _, text := range []string{config.SystemPrompt, instructions} {
+		if text != "" {
+			prompt.WriteString(text)
+			prompt.WriteString("\n\n")
+		}
+	}
+	for _, message := range messages {
+		for _, content := range message.Content {
+			if _, ok := content.(*llm.TextContent); !ok {
+				return nil, fmt.Errorf("bedrock: titan models only support text content, got %s: %w",
+					content.Type(), llm.ErrNotSupported)
+			}
+		}
+		speaker := "User"
+		if message.Role == llm.Assistant {
+			speaker = "Bot"
+		}
+		fmt.Fprintf(&prompt, "%s: %s\n", speaker, message.Text())
+	}
+	prompt.WriteString("Bot:")
+
+	maxTokens := p.maxTokens
+	if config.MaxTokens != nil {
+		maxTokens = *config.MaxTokens
+	}
+	return json.Marshal(titanRequest{
+		InputText: prompt.String(),
+		TextGenerationConfig: titanConfig{
+			MaxTokenCount: maxTokens,
+			Temperature:   config.Temperature,
+		},
+	})
+}
+
+// titanStopReason maps a Titan completion reason to an llm stop reason.
+func titanStopReason(reason string) string {
+	switch reason {
+	case "LENGTH":
+		return llm.StopReasonMaxTokens
+	case "STOP_CRITERIA_MET":
+		return llm.StopReasonStopSequence
+	case "CONTENT_FILTERED":
+		return llm.StopReasonContentFilter
+	}
+	return llm.StopReasonEndTurn
+}
+
+func (p *Provider) generateTitan(ctx context.Context, model string, config *llm.Config) (*llm.Response, error) {
+	body, err := p.titanBody(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.BeforeGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	var result titanResponse
+	var requestID string
+	err = retry.DoSimple(ctx, func() error {
+		resp, err := p.invoke(ctx, model, body, false, config.RequestHeaders)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return providers.NewResponseError(resp, string(body))
+		}
+		requestID = resp.Header.Get("x-amzn-requestid")
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+		return nil
+	}, retry.WithMaxAttempts(p.maxRetries+1), retry.WithBackoff(p.retryBaseWait, 5*time.Minute), retry.WithRetryIf(retry.SkipPermanent()))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Results) == 0 {
+		return nil, fmt.Errorf("empty response from bedrock api")
+	}
+	output := result.Results[0]
+	response := &llm.Response{
+		ID:         requestID,
+		Model:      model,
+		Role:       llm.Assistant,
+		Type:       "message",
+		Content:    []llm.Content{&llm.TextContent{Text: output.OutputText}},
+		StopReason: titanStopReason(output.CompletionReason),
+		Usage: llm.Usage{
+			InputTokens:  result.InputTextTokenCount,
+			OutputTokens: output.TokenCount,
+		},
+	}
+
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.AfterGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+		Response: &llm.HookResponseContext{
+			Response: response,
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (p *Provider) streamTitan(ctx context.Context, model string, config *llm.Config) (llm.StreamIterator, error) {
+	body, err := p.titanBody(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.BeforeGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	stream := providers.NewContextRetryingStreamIterator(ctx, providers.StreamRetryConfig{
+		Provider:      p.Name(),
+		MaxRetries:    p.maxRetries,
+		RetryBaseWait: p.retryBaseWait,
+		Logger:        config.Logger,
+	}, func(ctx context.Context) (llm.StreamIterator, error) {
+		resp, err := p.invoke(ctx, model, body, true, config.RequestHeaders)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, providers.NewResponseError(resp, string(body))
+		}
+		return &titanStreamIterator{
+			body:   resp.Body,
+			chunks: newChunkReader(resp.Body),
+			id:     resp.Header.Get("x-amzn-requestid"),
+			model:  model,
+		}, nil
+	})
+	return stream, nil
+}
+
+// titanStreamIterator translates the chunks of a streamed Titan response to
+// the llm event sequence: message_start, then one text block of
+// content_block_start, content_block_delta, and content_block_stop, then
+// message_delta with the stop reason and usage, and message_stop.
+type titanStreamIterator struct {
+	body      io.ReadCloser
+	chunks    *chunkReader
+	id        string
+	model     string
+	events    []*llm.Event
+	current   *llm.Event
+	started   bool
+	done      bool
+	err       error
+	closeOnce sync.Once
+}
+
+func (s *titanStreamIterator) Next() bool {
+	for len(s.events) == 0 {
+		if s.done || s.err != nil {
+			s.Close()
+			return false
+		}
+		data, err := s.chunks.Next()
+		if err == io.EOF {
+			// The stream ended without a completion reason
+			s.finish(&titanChunk{})
+			continue
+		}
+		if err != nil {
+			s.err = err
+			continue
+		}
+		var chunk titanChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			s.err = fmt.Errorf("bedrock: decoding titan chunk: %w", err)
+			continue
+		}
+		s.handle(&chunk)
+	}
+	s.current = s.events[0]
+	s.events = s.events[1:]
+	return true
+}
+
+func (s *titanStreamIterator) handle(chunk *titanChunk) {
+	index := 0
+	if !s.started {
+		s.started = true
+		s.events = append(s.events,
+			&llm.Event{
+				Type: llm.EventTypeMessageStart,
+				Message: &llm.Response{
+					ID:    s.id,
+					Type:  "message",
+					Role:  llm.Assistant,
+					Model: s.model,
+					Usage: llm.Usage{InputTokens: chunk.InputTextTokenCount},
+				},
+			},
+			&llm.Event{
+				Type:         llm.EventTypeContentBlockStart,
+				Index:        &index,
+				ContentBlock: &llm.EventContentBlock{Type: llm.ContentTypeText},
+			},
+		)
+	}
+	if chunk.OutputText != "" {
+		s.events = append(s.events, &llm.Event{
+			Type:  llm.EventTypeContentBlockDelta,
+			Index: &index,
+			Delta: &llm.EventDelta{Type: llm.EventDeltaTypeText, Text: chunk.OutputText},
+		})
+	}
+	if chunk.CompletionReason != "" {
+		s.finish(chunk)
+	}
+}
+
+// finish queues the events that end the stream.
+func (s *titanStreamIterator) finish(chunk *titanChunk) {
+	s.done = true
+	if !s.started {
+		return
+	}
+	index := 0
+	s.events = append(s.events,
+		&llm.Event{Type: llm.EventTypeContentBlockStop, Index: &index},
+		&llm.Event{
+			Type:  llm.EventTypeMessageDelta,
+			Delta: &llm.EventDelta{StopReason: titanStopReason(chunk.CompletionReason)},
+			Usage: &llm.Usage{OutputTokens: chunk.TotalOutputTextTokenCount},
+		},
+		&llm.Event{Type: llm.EventTypeMessageStop},
+	)
+}
+
+func (s *titanStreamIterator) Event() *llm.Event {
+	return s.current
+}
+
+func (s *titanStreamIterator) Err() error {
+	return s.err
+}
+
+func (s *titanStreamIterator) Close() error {
+	var err error
+	s.closeOnce.Do(func() { err = s.body.Close() })
+	return err
+}
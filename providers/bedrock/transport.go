@@ -0,0 +1,78 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// claudeTransport sends the Messages API requests of the anthropic
+// provider to Bedrock. It moves the model and stream fields out of the body
+// and into the URL, sends beta features in the body instead of the
+// anthropic-beta header, and translates streamed responses from the AWS
+// event stream encoding to server-sent events.
+type claudeTransport struct {
+	provider *Provider
+}
+
+func (t *claudeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer req.Body.Close()
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	model := req.Header.Get(modelIDHeader)
+	body, stream, err := claudeRequestBody(data, t.provider.anthropicVersion, req.Header.Get("anthropic-beta"))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.provider.invoke(req.Context(), model, body, stream, extraHeaders(req.Header))
+	if err != nil {
+		return nil, err
+	}
+	if stream && resp.StatusCode == http.StatusOK {
+		resp.Body = newEventStreamSSEBody(resp.Body)
+		resp.Header.Set("content-type", "text/event-stream")
+	}
+	return resp, nil
+}
+
+// claudeRequestBody converts a Messages API request body for Bedrock and
+// reports whether it requests streaming.
+func claudeRequestBody(data []byte, version, betas string) ([]byte, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, false, fmt.Errorf("bedrock: invalid request body: %w", err)
+	}
+	var stream bool
+	if raw, ok := fields["stream"]; ok {
+		if err := json.Unmarshal(raw, &stream); err != nil {
+			return nil, false, fmt.Errorf("bedrock: invalid stream field: %w", err)
+		}
+	}
+	delete(fields, "stream")
+	delete(fields, "model")
+	fields["anthropic_version"], _ = json.Marshal(version)
+	if betas != "" {
+		fields["anthropic_beta"], _ = json.Marshal(strings.Split(betas, ","))
+	}
+	body, err := json.Marshal(fields)
+	return body, stream, err
+}
+
+// extraHeaders returns the request headers set by the caller with
+// llm.WithRequestHeaders, leaving out those of the Anthropic API.
+func extraHeaders(header http.Header) http.Header {
+	extra := http.Header{}
+	for key, values := range header {
+		switch http.CanonicalHeaderKey(key) {
+		case "X-Api-Key", "Anthropic-Version", "Anthropic-Beta",
+			"Content-Type", "Accept", http.CanonicalHeaderKey(modelIDHeader):
+			continue
+		}
+		extra[key] = values
+	}
+	return extra
+}
@@ -0,0 +1,167 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// Capability registry. Providers register what their models can do from
+// init(), like pricing. Overrides take precedence over registered entries,
+// so corrected or new model data can be loaded at runtime without waiting
+// for a release.
+var (
+	capabilitiesMu         sync.RWMutex
+	registeredCapabilities = map[string]llm.ModelInfo{}
+	capabilityOverrides    = map[string]llm.ModelInfo{}
+)
+
+// RegisterCapabilities records what models can do. Typically called from a
+// provider's init().
+func RegisterCapabilities(infos ...llm.ModelInfo) {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	for _, info := range infos {
+		registeredCapabilities[info.Model] = info
+	}
+}
+
+// OverrideCapabilities replaces the registered entries of models, or adds
+// entries for models no provider registered. Each entry replaces the whole
+// registered entry rather than merging with it.
+func OverrideCapabilities(infos ...llm.ModelInfo) {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	for _, info := range infos {
+		capabilityOverrides[info.Model] = info
+	}
+}
+
+// LoadCapabilityOverrides reads a JSON array of llm.ModelInfo entries from
+// path and applies them with OverrideCapabilities.
+func LoadCapabilityOverrides(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading capability overrides: %w", err)
+	}
+	defer f.Close()
+	var infos []llm.ModelInfo
+	if err := json.NewDecoder(f).Decode(&infos); err != nil {
+		return fmt.Errorf("decoding capability overrides: %w", err)
+	}
+	for i, info := range infos {
+		if info.Model == "" {
+			return fmt.Errorf("capability override %d has no model", i)
+		}
+	}
+	OverrideCapabilities(infos...)
+	return nil
+}
+
+// dateSuffix matches the snapshot date at the end of a model name, as in
+// "claude-sonnet-4-5-20250929" or "gpt-4o-2024-08-06".
+var dateSuffix = regexp.MustCompile(`-(\d{8}|\d{4}-\d{2}-\d{2})$`)
+
+// Capabilities returns what a model can do. Aliases are resolved first, and
+// a "provider/" prefix and a trailing snapshot date are ignored when the
+// full name has no entry, so "anthropic/claude-haiku-4-5-20251001" finds
+// the entry for "claude-haiku-4-5". ok is false for unknown models.
+func Capabilities(model string) (llm.ModelInfo, bool) {
+	if resolved, ok := ResolveAlias(model); ok {
+		model = resolved
+	}
+	capabilitiesMu.RLock()
+	defer capabilitiesMu.RUnlock()
+	candidates := []string{model}
+	if _, name, ok := strings.Cut(model, "/"); ok {
+		candidates = append(candidates, name)
+	}
+	for _, name := range candidates {
+		if undated := dateSuffix.ReplaceAllString(name, ""); undated != name {
+			candidates = append(candidates, undated)
+		}
+	}
+	for _, name := range candidates {
+		if info, ok := capabilityOverrides[name]; ok {
+			return info, true
+		}
+		if info, ok := registeredCapabilities[name]; ok {
+			return info, true
+		}
+	}
+	return llm.ModelInfo{}, false
+}
+
+// ErrUnsupportedCapability is returned by CheckCapabilities when a request
+// needs something the model can't do. Match it with errors.Is.
+var ErrUnsupportedCapability = errors.New("model does not support the request")
+
+// CheckCapabilities reports whether a model can serve a request: that it
+// accepts every image, audio, and document input in the messages, and that
+// it can call tools when tools are given. Unknown models pass, since
+// nothing is known about them.
+func CheckCapabilities(model string, messages []*llm.Message, tools []llm.Tool) error {
+	info, ok := Capabilities(model)
+	if !ok {
+		return nil
+	}
+	if len(tools) > 0 && !info.Tools {
+		return fmt.Errorf("%w: %s can't call tools", ErrUnsupportedCapability, model)
+	}
+	for i, msg := range messages {
+		for _, content := range msg.Content {
+			modality, ok := inputModality(content)
+			if ok && !info.SupportsInput(modality) {
+				return fmt.Errorf("%w: %s doesn't accept %s input (message %d)",
+					ErrUnsupportedCapability, model, modality, i)
+			}
+		}
+	}
+	return nil
+}
+
+// inputModality returns the modality of media content. Text, including text
+// documents, and other content report false.
+func inputModality(content llm.Content) (llm.Modality, bool) {
+	switch c := content.(type) {
+	case *llm.ImageContent:
+		return llm.ModalityImage, true
+	case *llm.DocumentContent:
+		if c.Source == nil || c.Source.Type == llm.ContentSourceTypeText {
+			return "", false
+		}
+		switch media := c.Source.MediaType; {
+		case media == "" || media == "application/pdf":
+			return llm.ModalityPDF, true
+		case strings.HasPrefix(media, "image/"):
+			return llm.ModalityImage, true
+		case strings.HasPrefix(media, "audio/"):
+			return llm.ModalityAudio, true
+		case strings.HasPrefix(media, "video/"):
+			return llm.ModalityVideo, true
+		}
+	}
+	return "", false
+}
+
+// CapabilityCheck returns middleware that fails requests the model can't
+// serve with CheckCapabilities, before they are sent, instead of with a
+// provider error. model names the model when requests don't set one.
+//
+//	model = llm.WithMiddleware(model, providers.CapabilityCheck("gpt-4o"))
+func CapabilityCheck(model string) llm.Middleware {
+	return func(ctx context.Context, req *llm.Request) error {
+		name := req.Model
+		if name == "" {
+			name = model
+		}
+		return CheckCapabilities(name, req.Messages, req.Tools)
+	}
+}
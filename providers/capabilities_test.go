@@ -0,0 +1,88 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/dive/providers/anthropic"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestCapabilitiesRegisteredByProvider(t *testing.T) {
+	info, ok := providers.Capabilities(anthropic.ModelClaudeOpus48)
+	assert.True(t, ok)
+	assert.Equal(t, "anthropic", info.Provider)
+	assert.Equal(t, 1_000_000, info.ContextWindow)
+	assert.True(t, info.SupportsInput(llm.ModalityImage))
+	assert.True(t, info.Tools)
+
+	// Provider prefixes and snapshot dates fall back to the base entry
+	info, ok = providers.Capabilities("anthropic/" + anthropic.ModelClaudeHaiku4520251001)
+	assert.True(t, ok)
+	assert.Equal(t, anthropic.ModelClaudeHaiku45, info.Model)
+
+	_, ok = providers.Capabilities("no-such-model")
+	assert.False(t, ok)
+}
+
+func TestCapabilityOverrides(t *testing.T) {
+	providers.RegisterCapabilities(llm.ModelInfo{
+		Model:           "test-capabilities-model",
+		ContextWindow:   8192,
+		InputModalities: []llm.Modality{llm.ModalityText},
+	})
+	path := filepath.Join(t.TempDir(), "capabilities.json")
+	err := os.WriteFile(path, []byte(`[
+		{"model": "test-capabilities-model", "context_window": 32768, "input_modalities": ["text", "image"]},
+		{"model": "test-capabilities-new", "tools": true}
+	]`), 0o644)
+	assert.NoError(t, err)
+	assert.NoError(t, providers.LoadCapabilityOverrides(path))
+
+	info, ok := providers.Capabilities("test-capabilities-model")
+	assert.True(t, ok)
+	assert.Equal(t, 32768, info.ContextWindow)
+	assert.True(t, info.SupportsInput(llm.ModalityImage))
+
+	info, ok = providers.Capabilities("test-capabilities-new")
+	assert.True(t, ok)
+	assert.True(t, info.Tools)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`[{"context_window": 1}]`), 0o644))
+	assert.Error(t, providers.LoadCapabilityOverrides(path))
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	providers.RegisterCapabilities(llm.ModelInfo{
+		Model:           "test-text-only",
+		InputModalities: []llm.Modality{llm.ModalityText},
+	})
+	image := llm.NewUserMessage(
+		llm.NewTextContent("what is this?"),
+		llm.NewImageContent(llm.EncodedData("image/png", "cG5n")),
+	)
+	text := llm.NewUserTextMessage("hello")
+
+	err := providers.CheckCapabilities("test-text-only", []*llm.Message{text, image}, nil)
+	assert.True(t, errors.Is(err, providers.ErrUnsupportedCapability))
+	assert.Contains(t, err.Error(), "image input (message 1)")
+
+	err = providers.CheckCapabilities("test-text-only", []*llm.Message{text}, []llm.Tool{llm.NewToolDefinition()})
+	assert.True(t, errors.Is(err, providers.ErrUnsupportedCapability))
+
+	assert.NoError(t, providers.CheckCapabilities("test-text-only", []*llm.Message{text}, nil))
+	assert.NoError(t, providers.CheckCapabilities(anthropic.ModelClaudeSonnet5, []*llm.Message{image}, nil))
+	assert.NoError(t, providers.CheckCapabilities("no-such-model", []*llm.Message{image}, nil))
+
+	check := providers.CapabilityCheck("test-text-only")
+	req := &llm.Request{}
+	req.Messages = []*llm.Message{image}
+	assert.True(t, errors.Is(check(context.Background(), req), providers.ErrUnsupportedCapability))
+	req.Model = anthropic.ModelClaudeSonnet5
+	assert.NoError(t, check(context.Background(), req))
+}
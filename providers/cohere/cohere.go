@@ -0,0 +1,478 @@
+// Package cohere provides an LLM provider for Cohere's Command models,
+// using the v1 Chat API with native tool use.
+package cohere
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/retry"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+const ProviderName = "cohere"
+
+var (
+	DefaultModel         = ModelCommandRPlus082024
+	DefaultEndpoint      = "https://api.cohere.com/v1/chat"
+	DefaultMaxTokens     = 4000
+	DefaultClient        = &http.Client{Timeout: 300 * time.Second}
+	DefaultMaxRetries    = 3
+	DefaultRetryBaseWait = 2 * time.Second
+)
+
+var _ llm.StreamingLLM = &Provider{}
+
+// Provider implements the Cohere LLM provider.
+type Provider struct {
+	client        *http.Client
+	apiKey        string
+	endpoint      string
+	model         string
+	maxTokens     int
+	maxRetries    int
+	retryBaseWait time.Duration
+}
+
+// New creates a new Cohere provider with the given options. The API key is
+// read from CO_API_KEY or COHERE_API_KEY by default.
+func New(opts ...Option) *Provider {
+	p := &Provider{
+		apiKey:        cmp.Or(os.Getenv("CO_API_KEY"), os.Getenv("COHERE_API_KEY")),
+		endpoint:      DefaultEndpoint,
+		client:        DefaultClient,
+		model:         DefaultModel,
+		maxTokens:     DefaultMaxTokens,
+		maxRetries:    DefaultMaxRetries,
+		retryBaseWait: DefaultRetryBaseWait,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	config := &llm.Config{}
+	config.Apply(opts...)
+
+	request, err := p.buildRequest(config)
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.marshalRequest(request, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.BeforeGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	var result Response
+	err = retry.DoSimple(ctx, func() error {
+		req, err := p.createRequest(ctx, body, config)
+		if err != nil {
+			return err
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("error making request: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return providers.NewResponseError(resp, string(body))
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+		return nil
+	}, retry.WithMaxAttempts(p.maxRetries+1), retry.WithBackoff(p.retryBaseWait, 5*time.Minute), retry.WithRetryIf(retry.SkipPermanent()))
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertResponse(&result, request.Model)
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.AfterGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+		Response: &llm.HookResponseContext{
+			Response: response,
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIterator, error) {
+	config := &llm.Config{}
+	config.Apply(opts...)
+
+	request, err := p.buildRequest(config)
+	if err != nil {
+		return nil, err
+	}
+	request.Stream = true
+	body, err := p.marshalRequest(request, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.BeforeGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	stream := providers.NewContextRetryingStreamIterator(ctx, providers.StreamRetryConfig{
+		Provider:      p.Name(),
+		MaxRetries:    p.maxRetries,
+		RetryBaseWait: p.retryBaseWait,
+		Logger:        config.Logger,
+	}, func(ctx context.Context) (llm.StreamIterator, error) {
+		req, err := p.createRequest(ctx, body, config)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error making request: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, providers.NewResponseError(resp, string(body))
+		}
+		return newStreamIterator(resp.Body, request.Model, config.SSECallback), nil
+	})
+	return stream, nil
+}
+
+// marshalRequest encodes the request body, merging in any provider options
+// addressed to this provider.
+func (p *Provider) marshalRequest(request *Request, config *llm.Config) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+	return providers.ApplyProviderOptions(body, config, []string{ProviderName}, nil)
+}
+
+func (p *Provider) createRequest(ctx context.Context, body []byte, config *llm.Config) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("authorization", "Bearer "+p.apiKey)
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("accept", "application/json")
+	for key, values := range config.RequestHeaders {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return req, nil
+}
+
+// buildRequest converts the config to a Chat API request. The system prompt
+// and leading developer messages become the preamble.
+func (p *Provider) buildRequest(config *llm.Config) (*Request, error) {
+	if config.ParallelToolCalls != nil && !*config.ParallelToolCalls {
+		return nil, fmt.Errorf("cohere: disabling parallel tool calls: %w", llm.ErrNotSupported)
+	}
+	request := &Request{
+		Model:       cmp.Or(config.Model, p.model),
+		MaxTokens:   p.maxTokens,
+		Temperature: config.Temperature,
+	}
+	if config.MaxTokens != nil {
+		request.MaxTokens = *config.MaxTokens
+	}
+	instructions, messages := llm.FoldDeveloperMessages(config.Messages)
+	request.Preamble = strings.Join(nonEmpty(config.SystemPrompt, instructions), "\n\n")
+	if err := convertMessages(request, messages); err != nil {
+		return nil, err
+	}
+	if config.ToolChoice == nil || config.ToolChoice.Type != llm.ToolChoiceTypeNone {
+		tools, err := convertTools(config.Tools)
+		if err != nil {
+			return nil, err
+		}
+		request.Tools = tools
+	}
+	if config.ToolChoice != nil && (config.ToolChoice.Type == llm.ToolChoiceTypeAny || config.ToolChoice.Type == llm.ToolChoiceTypeTool) {
+		return nil, fmt.Errorf("cohere: tool choice %q: %w", config.ToolChoice.Type, llm.ErrNotSupported)
+	}
+	return request, nil
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// convertMessages sets the chat history, message, and tool results of
+// request. The last user message is sent as the message, and the results
+// it carries as the tool results; earlier messages form the chat history.
+func convertMessages(request *Request, messages []*llm.Message) error {
+	if len(messages) == 0 {
+		return fmt.Errorf("cohere: no messages")
+	}
+	last := messages[len(messages)-1]
+	if last.Role != llm.User {
+		return fmt.Errorf("cohere: the last message must be a user message, got %s", last.Role)
+	}
+
+	// The v1 API matches results to calls by content, so remember each call
+	calls := map[string]*ToolCall{}
+	for i, message := range messages {
+		switch message.Role {
+		case llm.Assistant:
+			turn := &ChatMessage{Role: RoleChatbot}
+			for _, content := range message.Content {
+				switch c := content.(type) {
+				case *llm.TextContent:
+					turn.Message += c.Text
+				case *llm.ToolUseContent:
+					call := &ToolCall{Name: c.Name, Parameters: toolInput(c.Input)}
+					calls[c.ID] = call
+					turn.ToolCalls = append(turn.ToolCalls, call)
+				}
+			}
+			request.ChatHistory = append(request.ChatHistory, turn)
+		case llm.User:
+			var text strings.Builder
+			var results []*ToolResult
+			for _, content := range message.Content {
+				switch c := content.(type) {
+				case *llm.TextContent:
+					text.WriteString(c.Text)
+				case *llm.ToolResultContent:
+					call, ok := calls[c.ToolUseID]
+					if !ok {
+						return fmt.Errorf("cohere: tool result %q has no matching tool call", c.ToolUseID)
+					}
+					results = append(results, &ToolResult{Call: call, Outputs: toolResultOutputs(c)})
+				default:
+					return fmt.Errorf("cohere: unsupported content type %s in message %d: %w",
+						content.Type(), i, llm.ErrNotSupported)
+				}
+			}
+			if message == last {
+				request.Message = text.String()
+				request.ToolResults = results
+				continue
+			}
+			if len(results) > 0 {
+				request.ChatHistory = append(request.ChatHistory, &ChatMessage{Role: RoleTool, ToolResults: results})
+			}
+			if text.Len() > 0 {
+				request.ChatHistory = append(request.ChatHistory, &ChatMessage{Role: RoleUser, Message: text.String()})
+			}
+		default:
+			return fmt.Errorf("cohere: unsupported message role %q", message.Role)
+		}
+	}
+	return nil
+}
+
+// toolInput returns the parameters of a tool call, which must be a JSON
+// object.
+func toolInput(input json.RawMessage) json.RawMessage {
+	if len(bytes.TrimSpace(input)) == 0 {
+		return json.RawMessage("{}")
+	}
+	return input
+}
+
+// toolResultOutputs returns the outputs of a tool result. The API expects
+// a list of objects, so the result text is sent as {"result": text}, or
+// {"error": text} for a failed call.
+func toolResultOutputs(c *llm.ToolResultContent) []map[string]any {
+	key := "result"
+	if c.IsError {
+		key = "error"
+	}
+	return []map[string]any{{key: toolResultText(c)}}
+}
+
+func toolResultText(c *llm.ToolResultContent) string {
+	if providers.IsEmptyToolResultContent(c.Content) {
+		return providers.EmptyToolResultText
+	}
+	if text, ok := c.Content.(string); ok {
+		return text
+	}
+	if blocks := providers.ToolResultBlocks(c); blocks != nil {
+		var texts []string
+		for _, block := range blocks {
+			if block.Text != "" {
+				texts = append(texts, block.Text)
+			} else {
+				texts = append(texts, fmt.Sprintf("[%s content omitted]", block.Type))
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	data, _ := json.Marshal(c.Content)
+	return string(data)
+}
+
+// convertTools converts tool definitions to the v1 format, which lists
+// each top-level parameter with a Python type name.
+func convertTools(tools []llm.Tool) ([]*Tool, error) {
+	var result []*Tool
+	for _, tool := range tools {
+		converted := &Tool{Name: tool.Name(), Description: tool.Description()}
+		if s := tool.Schema(); s != nil && len(s.Properties) > 0 {
+			converted.ParameterDefinitions = map[string]*ParameterDefinition{}
+			for name, property := range s.Properties {
+				if property == nil {
+					return nil, fmt.Errorf("cohere: tool %q: parameter %q has no schema", tool.Name(), name)
+				}
+				description := property.Description
+				if len(property.Enum) > 0 {
+					description = strings.TrimSpace(fmt.Sprintf("%s One of: %v.", description, property.Enum))
+				}
+				converted.ParameterDefinitions[name] = &ParameterDefinition{
+					Description: description,
+					Type:        pythonType(property),
+					Required:    contains(s.Required, name),
+				}
+			}
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+// pythonType returns the Python type name of a JSON schema property.
+func pythonType(property *schema.Property) string {
+	switch property.Type {
+	case schema.Integer:
+		return "int"
+	case schema.Number:
+		return "float"
+	case schema.Boolean:
+		return "bool"
+	case schema.Object:
+		return "Dict"
+	case schema.Array:
+		if property.Items != nil {
+			return "List[" + pythonType(property.Items) + "]"
+		}
+		return "List"
+	}
+	return "str"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// toolCallCounter numbers tool calls, since the v1 API doesn't identify
+// them.
+var toolCallCounter atomic.Int64
+
+func newToolCallID(name string) string {
+	return fmt.Sprintf("call_%s_%d", name, toolCallCounter.Add(1))
+}
+
+// convertResponse converts a Chat API response. With tool calls, the text
+// is the model's plan for using the tools.
+func convertResponse(result *Response, model string) *llm.Response {
+	response := &llm.Response{
+		ID:         result.ResponseID,
+		Model:      model,
+		Role:       llm.Assistant,
+		Type:       "message",
+		StopReason: stopReason(result.FinishReason, len(result.ToolCalls) > 0),
+		Usage:      usage(result.Meta),
+	}
+	if result.Text != "" {
+		response.Content = append(response.Content, &llm.TextContent{Text: result.Text})
+	}
+	for _, call := range result.ToolCalls {
+		response.Content = append(response.Content, &llm.ToolUseContent{
+			ID:    newToolCallID(call.Name),
+			Name:  call.Name,
+			Input: toolInput(call.Parameters),
+		})
+	}
+	return response
+}
+
+// stopReason maps a finish reason to an llm stop reason.
+func stopReason(finishReason string, toolCalls bool) string {
+	if toolCalls {
+		return llm.StopReasonToolUse
+	}
+	switch finishReason {
+	case "MAX_TOKENS":
+		return llm.StopReasonMaxTokens
+	case "STOP_SEQUENCE":
+		return llm.StopReasonStopSequence
+	case "ERROR_TOXIC":
+		return llm.StopReasonContentFilter
+	}
+	return llm.StopReasonEndTurn
+}
+
+// usage returns the token counts in meta. The billed units are used when
+// the total tokens, which include the prompt template, aren't reported.
+func usage(meta *Meta) llm.Usage {
+	if meta == nil {
+		return llm.Usage{}
+	}
+	tokens := meta.Tokens
+	if tokens == nil {
+		tokens = meta.BilledUnits
+	}
+	if tokens == nil {
+		return llm.Usage{}
+	}
+	return llm.Usage{InputTokens: tokens.InputTokens, OutputTokens: tokens.OutputTokens}
+}
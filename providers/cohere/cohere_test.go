@@ -0,0 +1,188 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(WithEndpoint(server.URL), WithAPIKey("test-key"), WithMaxRetries(0))
+}
+
+var weatherTool = llm.NewToolDefinition().
+	WithName("get_weather").
+	WithDescription("Get the weather for a city").
+	WithSchema(&schema.Schema{
+		Type: schema.Object,
+		Properties: map[string]*schema.Property{
+			"city":  {Type: schema.String, Description: "City name"},
+			"units": {Type: schema.String, Enum: []any{"c", "f"}},
+			"days":  {Type: schema.Array, Items: &schema.Property{Type: schema.Integer}},
+		},
+		Required: []string{"city"},
+	})
+
+func TestGenerateToolCalls(t *testing.T) {
+	var request Request
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		io.WriteString(w, `{"response_id":"resp_1","generation_id":"gen_1",
+			"text":"I will check the weather in Paris.",
+			"tool_calls":[{"name":"get_weather","parameters":{"city":"Paris"}}],
+			"finish_reason":"COMPLETE",
+			"meta":{"billed_units":{"input_tokens":30,"output_tokens":12},"tokens":{"input_tokens":250,"output_tokens":12}}}`)
+	})
+
+	response, err := p.Generate(context.Background(),
+		llm.WithModel(ModelCommandRPlus),
+		llm.WithSystemPrompt("You are a weather bot."),
+		llm.WithMaxTokens(300),
+		llm.WithTemperature(0.3),
+		llm.WithTools(weatherTool),
+		llm.WithMessages(
+			llm.NewDeveloperMessage("Use metric units."),
+			llm.NewUserTextMessage("Weather in Paris?"),
+		),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ModelCommandRPlus, request.Model)
+	assert.Equal(t, "You are a weather bot.\n\nUse metric units.", request.Preamble)
+	assert.Equal(t, "Weather in Paris?", request.Message)
+	assert.Len(t, request.ChatHistory, 0)
+	assert.Equal(t, 300, request.MaxTokens)
+	assert.Equal(t, 0.3, *request.Temperature)
+	assert.Len(t, request.Tools, 1)
+	params := request.Tools[0].ParameterDefinitions
+	assert.Equal(t, &ParameterDefinition{Description: "City name", Type: "str", Required: true}, params["city"])
+	assert.Equal(t, &ParameterDefinition{Description: "One of: [c f].", Type: "str"}, params["units"])
+	assert.Equal(t, "List[int]", params["days"].Type)
+
+	assert.Equal(t, llm.StopReasonToolUse, response.StopReason)
+	assert.Equal(t, llm.Usage{InputTokens: 250, OutputTokens: 12}, response.Usage)
+	assert.Len(t, response.Content, 2)
+	assert.Equal(t, "I will check the weather in Paris.", response.Content[0].(*llm.TextContent).Text)
+	call := response.Content[1].(*llm.ToolUseContent)
+	assert.Equal(t, "get_weather", call.Name)
+	assert.Equal(t, `{"city":"Paris"}`, string(call.Input))
+	assert.NotEqual(t, "", call.ID)
+}
+
+func TestGenerateToolResults(t *testing.T) {
+	var request Request
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		io.WriteString(w, `{"text":"It is 18C in Paris.","finish_reason":"COMPLETE"}`)
+	})
+
+	response, err := p.Generate(context.Background(),
+		llm.WithTools(weatherTool),
+		llm.WithMessages(
+			llm.NewUserTextMessage("Weather in Paris?"),
+			&llm.Message{Role: llm.Assistant, Content: []llm.Content{
+				&llm.TextContent{Text: "Checking."},
+				&llm.ToolUseContent{ID: "call_1", Name: "get_weather", Input: json.RawMessage(`{"city":"Paris"}`)},
+			}},
+			llm.NewToolResultMessage(llm.NewToolResultContent("call_1", "18C and sunny", false)),
+		),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "It is 18C in Paris.", response.Message().Text())
+	assert.Equal(t, llm.StopReasonEndTurn, response.StopReason)
+
+	assert.Equal(t, "", request.Message)
+	assert.Equal(t, []*ChatMessage{
+		{Role: RoleUser, Message: "Weather in Paris?"},
+		{Role: RoleChatbot, Message: "Checking.", ToolCalls: []*ToolCall{
+			{Name: "get_weather", Parameters: json.RawMessage(`{"city":"Paris"}`)},
+		}},
+	}, request.ChatHistory)
+	assert.Len(t, request.ToolResults, 1)
+	assert.Equal(t, "get_weather", request.ToolResults[0].Call.Name)
+	assert.Equal(t, []map[string]any{{"result": "18C and sunny"}}, request.ToolResults[0].Outputs)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	p := New(WithAPIKey("test-key"))
+	_, err := p.Generate(context.Background(), llm.WithMessages(
+		llm.NewToolResultMessage(llm.NewToolResultContent("missing", "result", false)),
+	))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no matching tool call")
+
+	_, err = p.Generate(context.Background(),
+		llm.WithUserTextMessage("hi"),
+		llm.WithParallelToolCalls(false),
+	)
+	assert.ErrorIs(t, err, llm.ErrNotSupported)
+}
+
+func TestStream(t *testing.T) {
+	var request Request
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("content-type", "application/stream+json")
+		io.WriteString(w, strings.Join([]string{
+			`{"is_finished":false,"event_type":"stream-start","generation_id":"gen_1"}`,
+			`{"is_finished":false,"event_type":"tool-calls-chunk","tool_call_delta":{"text":"I will"}}`,
+			`{"is_finished":false,"event_type":"tool-calls-generation","text":"I will look it up.","tool_calls":[{"name":"get_weather","parameters":{"city":"Oslo"}},{"name":"get_weather","parameters":{"city":"Rome"}}]}`,
+			`{"is_finished":true,"event_type":"stream-end","finish_reason":"COMPLETE","response":{"response_id":"resp_1","text":"","meta":{"tokens":{"input_tokens":40,"output_tokens":20}}}}`,
+		}, "\n")+"\n")
+	})
+
+	stream, err := p.Stream(context.Background(), llm.WithUserTextMessage("Oslo and Rome?"), llm.WithTools(weatherTool))
+	assert.NoError(t, err)
+	defer stream.Close()
+	accumulator := llm.NewResponseAccumulator()
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Err())
+	assert.True(t, request.Stream)
+
+	response := accumulator.Response()
+	assert.Equal(t, "gen_1", response.ID)
+	assert.Equal(t, llm.StopReasonToolUse, response.StopReason)
+	assert.Equal(t, llm.Usage{InputTokens: 40, OutputTokens: 20}, response.Usage)
+	assert.Len(t, response.Content, 3)
+	assert.Equal(t, "I will look it up.", response.Content[0].(*llm.TextContent).Text)
+	assert.Equal(t, `{"city":"Oslo"}`, string(response.Content[1].(*llm.ToolUseContent).Input))
+	assert.Equal(t, `{"city":"Rome"}`, string(response.Content[2].(*llm.ToolUseContent).Input))
+}
+
+func TestStreamText(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Join([]string{
+			`{"event_type":"stream-start","generation_id":"gen_2"}`,
+			`{"event_type":"text-generation","text":"Hello"}`,
+			`{"event_type":"text-generation","text":" world"}`,
+			`{"event_type":"stream-end","finish_reason":"MAX_TOKENS","response":{"meta":{"billed_units":{"input_tokens":3,"output_tokens":2}}}}`,
+		}, "\n")+"\n")
+	})
+
+	stream, err := p.Stream(context.Background(), llm.WithUserTextMessage("hi"))
+	assert.NoError(t, err)
+	defer stream.Close()
+	accumulator := llm.NewResponseAccumulator()
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Err())
+	response := accumulator.Response()
+	assert.Equal(t, "Hello world", response.Message().Text())
+	assert.Equal(t, llm.StopReasonMaxTokens, response.StopReason)
+	assert.Equal(t, 3, response.Usage.InputTokens)
+}
@@ -0,0 +1,15 @@
+package cohere
+
+const (
+	// Command A models
+	ModelCommandA032025 = "command-a-03-2025"
+
+	// Command R models
+	ModelCommandRPlus082024 = "command-r-plus-08-2024"
+	ModelCommandR082024     = "command-r-08-2024"
+	ModelCommandR7B122024   = "command-r7b-12-2024"
+
+	// Aliases for the latest Command R models
+	ModelCommandRPlus = "command-r-plus"
+	ModelCommandR     = "command-r"
+)
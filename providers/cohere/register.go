@@ -0,0 +1,24 @@
+package cohere
+
+import (
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+func init() {
+	providers.Register(providers.ProviderEntry{
+		Name:             ProviderName,
+		Match:            providers.PrefixMatcher("command-"),
+		Factory:          factory,
+		APIKeyEnv:        []string{"CO_API_KEY", "COHERE_API_KEY"},
+		HealthCheckModel: ModelCommandR7B122024,
+	})
+}
+
+func factory(model, endpoint string) llm.LLM {
+	opts := []Option{WithModel(model)}
+	if endpoint != "" {
+		opts = append(opts, WithEndpoint(endpoint))
+	}
+	return New(opts...)
+}
@@ -0,0 +1,174 @@
+package cohere
+
+import (
+	"io"
+	"sync"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// StreamIterator translates the events of a streamed Chat API response,
+// sent as one JSON object per line, to llm events. Generated text becomes a
+// text block and each tool call a tool use block, between message_start
+// and the message_delta and message_stop sent at stream-end.
+type StreamIterator struct {
+	reader    *llm.ServerSentEventsReader[StreamEvent]
+	body      io.ReadCloser
+	model     string
+	events    []*llm.Event
+	current   *llm.Event
+	index     int
+	textOpen  bool
+	toolCalls bool
+	done      bool
+	err       error
+	closeOnce sync.Once
+}
+
+func newStreamIterator(body io.ReadCloser, model string, callback llm.ServerSentEventsCallback) *StreamIterator {
+	return &StreamIterator{
+		reader: llm.NewServerSentEventsReader[StreamEvent](body).WithSSECallback(callback),
+		body:   body,
+		model:  model,
+	}
+}
+
+// Next advances to the next event. It returns false when the stream is
+// complete or an error occurs.
+func (s *StreamIterator) Next() bool {
+	for len(s.events) == 0 {
+		if s.done {
+			s.Close()
+			return false
+		}
+		event, ok := s.reader.Next()
+		if !ok {
+			s.err = s.reader.Err()
+			s.Close()
+			return false
+		}
+		s.handle(&event)
+	}
+	s.current = s.events[0]
+	s.events = s.events[1:]
+	return true
+}
+
+func (s *StreamIterator) handle(event *StreamEvent) {
+	switch event.EventType {
+	case EventStreamStart:
+		s.queue(&llm.Event{
+			Type: llm.EventTypeMessageStart,
+			Message: &llm.Response{
+				ID:    event.GenerationID,
+				Type:  "message",
+				Role:  llm.Assistant,
+				Model: s.model,
+			},
+		})
+	case EventTextGeneration:
+		if !s.textOpen {
+			s.textOpen = true
+			s.queue(&llm.Event{
+				Type:         llm.EventTypeContentBlockStart,
+				Index:        s.blockIndex(),
+				ContentBlock: &llm.EventContentBlock{Type: llm.ContentTypeText},
+			})
+		}
+		s.queue(&llm.Event{
+			Type:  llm.EventTypeContentBlockDelta,
+			Index: s.blockIndex(),
+			Delta: &llm.EventDelta{Type: llm.EventDeltaTypeText, Text: event.Text},
+		})
+	case EventToolCallsGeneration:
+		// The text is the model's plan for the calls, unless it was
+		// already streamed as text
+		if !s.textOpen && event.Text != "" {
+			s.queue(
+				&llm.Event{
+					Type:         llm.EventTypeContentBlockStart,
+					Index:        s.blockIndex(),
+					ContentBlock: &llm.EventContentBlock{Type: llm.ContentTypeText, Text: event.Text},
+				},
+				&llm.Event{Type: llm.EventTypeContentBlockStop, Index: s.blockIndex()},
+			)
+			s.index++
+		}
+		s.closeText()
+		for _, call := range event.ToolCalls {
+			s.toolCalls = true
+			s.queue(
+				&llm.Event{
+					Type:  llm.EventTypeContentBlockStart,
+					Index: s.blockIndex(),
+					ContentBlock: &llm.EventContentBlock{
+						Type: llm.ContentTypeToolUse,
+						ID:   newToolCallID(call.Name),
+						Name: call.Name,
+					},
+				},
+				&llm.Event{
+					Type:  llm.EventTypeContentBlockDelta,
+					Index: s.blockIndex(),
+					Delta: &llm.EventDelta{
+						Type:        llm.EventDeltaTypeInputJSON,
+						PartialJSON: string(toolInput(call.Parameters)),
+					},
+				},
+				&llm.Event{Type: llm.EventTypeContentBlockStop, Index: s.blockIndex()},
+			)
+			s.index++
+		}
+	case EventStreamEnd:
+		s.closeText()
+		var meta *Meta
+		if event.Response != nil {
+			meta = event.Response.Meta
+		}
+		usage := usage(meta)
+		s.queue(
+			&llm.Event{
+				Type:  llm.EventTypeMessageDelta,
+				Delta: &llm.EventDelta{StopReason: stopReason(event.FinishReason, s.toolCalls)},
+				Usage: &usage,
+			},
+			&llm.Event{Type: llm.EventTypeMessageStop},
+		)
+		s.done = true
+	}
+}
+
+func (s *StreamIterator) queue(events ...*llm.Event) {
+	s.events = append(s.events, events...)
+}
+
+func (s *StreamIterator) blockIndex() *int {
+	index := s.index
+	return &index
+}
+
+// closeText ends the open text block, if any.
+func (s *StreamIterator) closeText() {
+	if !s.textOpen {
+		return
+	}
+	s.textOpen = false
+	s.queue(&llm.Event{Type: llm.EventTypeContentBlockStop, Index: s.blockIndex()})
+	s.index++
+}
+
+// Event returns the current event. Should only be called after a
+// successful Next().
+func (s *StreamIterator) Event() *llm.Event {
+	return s.current
+}
+
+func (s *StreamIterator) Close() error {
+	var err error
+	s.closeOnce.Do(func() { err = s.body.Close() })
+	return err
+}
+
+func (s *StreamIterator) Err() error {
+	return s.err
+}
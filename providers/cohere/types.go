@@ -0,0 +1,102 @@
+package cohere
+
+import "encoding/json"
+
+// Request is the body of a v1 Chat API request.
+type Request struct {
+	Model         string         `json:"model,omitempty"`
+	Message       string         `json:"message"`
+	ChatHistory   []*ChatMessage `json:"chat_history,omitempty"`
+	Preamble      string         `json:"preamble,omitempty"`
+	Tools         []*Tool        `json:"tools,omitempty"`
+	ToolResults   []*ToolResult  `json:"tool_results,omitempty"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+	StopSequences []string       `json:"stop_sequences,omitempty"`
+}
+
+// Chat history roles.
+const (
+	RoleUser    = "USER"
+	RoleChatbot = "CHATBOT"
+	RoleSystem  = "SYSTEM"
+	RoleTool    = "TOOL"
+)
+
+// ChatMessage is one turn of the chat history.
+type ChatMessage struct {
+	Role        string        `json:"role"`
+	Message     string        `json:"message,omitempty"`
+	ToolCalls   []*ToolCall   `json:"tool_calls,omitempty"`
+	ToolResults []*ToolResult `json:"tool_results,omitempty"`
+}
+
+// Tool describes a tool the model may call.
+type Tool struct {
+	Name                 string                          `json:"name"`
+	Description          string                          `json:"description"`
+	ParameterDefinitions map[string]*ParameterDefinition `json:"parameter_definitions,omitempty"`
+}
+
+// ParameterDefinition describes one parameter of a tool. Types are written
+// as Python type names, such as "str" or "List[int]".
+type ParameterDefinition struct {
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ToolCall is a call the model made. The v1 API doesn't assign call IDs, so
+// a result is matched to its call by repeating the call.
+type ToolCall struct {
+	Name       string          `json:"name"`
+	Parameters json.RawMessage `json:"parameters"`
+}
+
+// ToolResult is the outcome of a tool call.
+type ToolResult struct {
+	Call    *ToolCall        `json:"call"`
+	Outputs []map[string]any `json:"outputs"`
+}
+
+// Response is the body of a v1 Chat API response.
+type Response struct {
+	ResponseID   string      `json:"response_id"`
+	GenerationID string      `json:"generation_id"`
+	Text         string      `json:"text"`
+	ToolCalls    []*ToolCall `json:"tool_calls,omitempty"`
+	FinishReason string      `json:"finish_reason"`
+	Meta         *Meta       `json:"meta,omitempty"`
+}
+
+// Meta carries the usage of a response.
+type Meta struct {
+	BilledUnits *Tokens `json:"billed_units,omitempty"`
+	Tokens      *Tokens `json:"tokens,omitempty"`
+}
+
+// Tokens counts the input and output tokens of a response.
+type Tokens struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Stream event types.
+const (
+	EventStreamStart         = "stream-start"
+	EventTextGeneration      = "text-generation"
+	EventToolCallsChunk      = "tool-calls-chunk"
+	EventToolCallsGeneration = "tool-calls-generation"
+	EventStreamEnd           = "stream-end"
+)
+
+// StreamEvent is one line of a streamed response.
+type StreamEvent struct {
+	EventType    string      `json:"event_type"`
+	GenerationID string      `json:"generation_id,omitempty"`
+	Text         string      `json:"text,omitempty"`
+	ToolCalls    []*ToolCall `json:"tool_calls,omitempty"`
+	FinishReason string      `json:"finish_reason,omitempty"`
+	Response     *Response   `json:"response,omitempty"`
+}
@@ -0,0 +1,196 @@
+package deepseek
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	openaic "github.com/deepnoodle-ai/dive/providers/openaicompletions"
+)
+
+var (
+	DefaultModel         = ModelDeepSeekChat
+	DefaultEndpoint      = "https://api.deepseek.com/chat/completions"
+	DefaultMaxTokens     = 8192
+	DefaultMaxRetries    = openaic.DefaultMaxRetries
+	DefaultRetryBaseWait = openaic.DefaultRetryBaseWait
+	DefaultClient        = &http.Client{Timeout: 300 * time.Second}
+)
+
+var _ llm.StreamingLLM = &Provider{}
+
+// Provider implements the DeepSeek API, which is compatible with the OpenAI
+// Chat Completions API. The reasoning that deepseek-reasoner returns in its
+// reasoning_content field is surfaced as llm.ThinkingContent.
+type Provider struct {
+	apiKey        string
+	endpoint      string
+	model         string
+	maxTokens     int
+	maxRetries    int
+	retryBaseWait time.Duration
+	client        *http.Client
+
+	// Embedded OpenAI completions provider
+	*openaic.Provider
+}
+
+// New creates a new DeepSeek provider with the given options.
+func New(opts ...Option) *Provider {
+	p := &Provider{
+		apiKey:        os.Getenv("DEEPSEEK_API_KEY"),
+		endpoint:      DefaultEndpoint,
+		client:        DefaultClient,
+		model:         DefaultModel,
+		maxTokens:     DefaultMaxTokens,
+		maxRetries:    DefaultMaxRetries,
+		retryBaseWait: DefaultRetryBaseWait,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	// Create a custom client that maps DeepSeek's reasoning field
+	customClient := &http.Client{
+		Timeout:   p.client.Timeout,
+		Transport: &reasoningTransport{underlying: p.client.Transport},
+	}
+
+	// Pass the options through to the wrapped OpenAI provider
+	p.Provider = openaic.New(
+		openaic.WithName("deepseek"),
+		openaic.WithAPIKey(p.apiKey),
+		openaic.WithClient(customClient),
+		openaic.WithEndpoint(p.endpoint),
+		openaic.WithMaxTokens(p.maxTokens),
+		openaic.WithMaxRetries(p.maxRetries),
+		openaic.WithBaseWait(p.retryBaseWait),
+		openaic.WithModel(p.model),
+		openaic.WithSystemRole("system"),
+	)
+	return p
+}
+
+func (p *Provider) Name() string {
+	return "deepseek"
+}
+
+// reasoningTransport is a custom http.RoundTripper that renames DeepSeek's
+// reasoning_content field to the reasoning field the Chat Completions
+// provider maps to thinking content. Without it the reasoning is dropped.
+type reasoningTransport struct {
+	underlying http.RoundTripper
+}
+
+func (t *reasoningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Use the underlying transport or default if none provided
+	transport := t.underlying
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		resp.Body = &eventStreamBody{
+			reader: bufio.NewReader(resp.Body),
+			closer: resp.Body,
+		}
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = renameReasoning(body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
+
+// eventStreamBody rewrites each server-sent event line of a streamed
+// response as it is read.
+type eventStreamBody struct {
+	reader  *bufio.Reader
+	closer  io.Closer
+	pending []byte
+	err     error
+}
+
+func (b *eventStreamBody) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+		var line []byte
+		line, b.err = b.reader.ReadBytes('\n')
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			trimmed := bytes.TrimSpace(data)
+			if rewritten := renameReasoning(trimmed); !bytes.Equal(rewritten, trimmed) {
+				line = append(append([]byte("data: "), rewritten...), '\n')
+			}
+		}
+		b.pending = line
+	}
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+func (b *eventStreamBody) Close() error {
+	return b.closer.Close()
+}
+
+// renameReasoning moves reasoning_content to reasoning in the message or
+// delta of each choice in a completion or chunk. Data that has no
+// reasoning_content, or is not a JSON object, is returned unchanged.
+func renameReasoning(data []byte) []byte {
+	if !bytes.Contains(data, []byte(`"reasoning_content"`)) {
+		return data
+	}
+	var completion map[string]json.RawMessage
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return data
+	}
+	var choices []map[string]json.RawMessage
+	if err := json.Unmarshal(completion["choices"], &choices); err != nil {
+		return data
+	}
+	for _, choice := range choices {
+		for _, key := range []string{"message", "delta"} {
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(choice[key], &fields); err != nil {
+				continue
+			}
+			reasoning, ok := fields["reasoning_content"]
+			if !ok {
+				continue
+			}
+			delete(fields, "reasoning_content")
+			fields["reasoning"] = reasoning
+			encoded, err := json.Marshal(fields)
+			if err != nil {
+				return data
+			}
+			choice[key] = encoded
+		}
+	}
+	encoded, err := json.Marshal(choices)
+	if err != nil {
+		return data
+	}
+	completion["choices"] = encoded
+	if rewritten, err := json.Marshal(completion); err == nil {
+		return rewritten
+	}
+	return data
+}
@@ -0,0 +1,104 @@
+package deepseek
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(WithEndpoint(server.URL), WithAPIKey("test-key"), WithMaxRetries(0))
+}
+
+func TestGenerateReasoningContent(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"resp_1","object":"chat.completion","model":"deepseek-reasoner",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"4","reasoning_content":"2 plus 2 is 4."},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":12,"completion_tokens":9,"total_tokens":21}}`)
+	})
+
+	response, err := p.Generate(context.Background(),
+		llm.WithModel(ModelDeepSeekReasoner),
+		llm.WithUserTextMessage("What is 2+2?"),
+	)
+	assert.NoError(t, err)
+	assert.Len(t, response.Content, 2)
+	thinking, ok := response.Content[0].(*llm.ThinkingContent)
+	assert.True(t, ok)
+	assert.Equal(t, "2 plus 2 is 4.", thinking.Thinking)
+	assert.Equal(t, "4", response.Message().Text())
+	assert.Equal(t, 9, response.Usage.OutputTokens)
+}
+
+func TestStreamReasoningContent(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data: {"id":"resp_1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"role":"assistant","content":null,"reasoning_content":"2 plus 2"},"finish_reason":null}]}
+
+data: {"id":"resp_1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":null,"reasoning_content":" is 4."},"finish_reason":null}]}
+
+data: {"id":"resp_1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":"4","reasoning_content":null},"finish_reason":null}]}
+
+data: {"id":"resp_1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":9,"total_tokens":21}}
+
+data: [DONE]
+
+`)
+	})
+
+	iterator, err := p.Stream(context.Background(),
+		llm.WithModel(ModelDeepSeekReasoner),
+		llm.WithUserTextMessage("What is 2+2?"),
+	)
+	assert.NoError(t, err)
+	defer iterator.Close()
+
+	accumulator := llm.NewResponseAccumulator()
+	for iterator.Next() {
+		assert.NoError(t, accumulator.AddEvent(iterator.Event()))
+	}
+	assert.NoError(t, iterator.Err())
+
+	response := accumulator.Response()
+	assert.Len(t, response.Content, 2)
+	thinking, ok := response.Content[0].(*llm.ThinkingContent)
+	assert.True(t, ok)
+	assert.Equal(t, "2 plus 2 is 4.", thinking.Thinking)
+	assert.Equal(t, "4", response.Message().Text())
+}
+
+func TestRenameReasoning(t *testing.T) {
+	unchanged := []byte(`{"choices":[{"delta":{"content":"hi"}}]}`)
+	assert.Equal(t, string(unchanged), string(renameReasoning(unchanged)))
+	assert.Equal(t, "[DONE]", string(renameReasoning([]byte("[DONE]"))))
+
+	renamed := renameReasoning([]byte(`{"choices":[{"delta":{"reasoning_content":"hmm"}}]}`))
+	assert.Equal(t, `{"choices":[{"delta":{"reasoning":"hmm"}}]}`, string(renamed))
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "deepseek", New().Name())
+}
+
+func TestRegistryRequiresAPIKey(t *testing.T) {
+	t.Setenv("DEEPSEEK_API_KEY", "")
+	_, ok := providers.CreateModel(ModelDeepSeekReasoner, "").(*Provider)
+	assert.False(t, ok)
+
+	t.Setenv("DEEPSEEK_API_KEY", "test-key")
+	model, ok := providers.CreateModel(ModelDeepSeekReasoner, "").(*Provider)
+	assert.True(t, ok)
+	assert.Equal(t, ModelDeepSeekReasoner, model.model)
+	assert.Equal(t, "test-key", model.apiKey)
+}
@@ -0,0 +1,10 @@
+package deepseek
+
+const (
+	// ModelDeepSeekChat is DeepSeek's general chat model (non-thinking mode).
+	ModelDeepSeekChat = "deepseek-chat"
+
+	// ModelDeepSeekReasoner is DeepSeek's reasoning model (thinking mode). Its
+	// reasoning is returned as llm.ThinkingContent.
+	ModelDeepSeekReasoner = "deepseek-reasoner"
+)
@@ -0,0 +1,23 @@
+package deepseek
+
+import "github.com/deepnoodle-ai/dive/llm"
+
+// TextModelPricing contains pricing for DeepSeek text generation models
+var TextModelPricing = map[string]llm.PricingInfo{
+	ModelDeepSeekChat: {
+		Model:          ModelDeepSeekChat,
+		InputPrice:     0.28,
+		OutputPrice:    0.42,
+		CacheReadPrice: 0.028,
+		Currency:       "USD",
+		UpdatedAt:      "2026-10-01",
+	},
+	ModelDeepSeekReasoner: {
+		Model:          ModelDeepSeekReasoner,
+		InputPrice:     0.28,
+		OutputPrice:    0.42,
+		CacheReadPrice: 0.028,
+		Currency:       "USD",
+		UpdatedAt:      "2026-10-01",
+	},
+}
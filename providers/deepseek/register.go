@@ -0,0 +1,24 @@
+package deepseek
+
+import (
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+func init() {
+	providers.Register(providers.ProviderEntry{
+		Name:             "deepseek",
+		Match:            providers.EnvMatcher("DEEPSEEK_API_KEY", providers.PrefixMatcher("deepseek-")),
+		Factory:          factory,
+		APIKeyEnv:        []string{"DEEPSEEK_API_KEY"},
+		HealthCheckModel: ModelDeepSeekChat,
+	})
+}
+
+func factory(model, endpoint string) llm.LLM {
+	opts := []Option{WithModel(model)}
+	if endpoint != "" {
+		opts = append(opts, WithEndpoint(endpoint))
+	}
+	return New(opts...)
+}
@@ -14,4 +14,7 @@
 //   - [github.com/deepnoodle-ai/dive/providers/mistral] - Mistral models
 //   - [github.com/deepnoodle-ai/dive/providers/ollama] - Local model serving
 //   - [github.com/deepnoodle-ai/dive/providers/openrouter] - Multi-provider proxy
+//   - [github.com/deepnoodle-ai/dive/providers/deepseek] - DeepSeek models
+//   - [github.com/deepnoodle-ai/dive/providers/perplexity] - Perplexity Sonar models
+//   - [github.com/deepnoodle-ai/dive/providers/fireworks] - Fireworks AI models
 package providers
@@ -0,0 +1,244 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Errors that a *ProviderError matches with errors.Is when the provider's
+// response says what went wrong. Use errors.As with *ProviderError for the
+// status code, the provider's own error code and message, and the request
+// ID.
+var (
+	// ErrContextLengthExceeded matches errors for requests whose prompt
+	// doesn't fit in the model's context window.
+	ErrContextLengthExceeded = errors.New("context length exceeded")
+
+	// ErrContentFiltered matches errors for requests a provider's content
+	// policy rejected.
+	ErrContentFiltered = errors.New("content filtered")
+
+	// ErrInvalidAPIKey matches errors for requests with a missing, invalid,
+	// or revoked API key.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+
+	// ErrModelNotFound matches errors for requests naming a model that
+	// doesn't exist or that the account can't use.
+	ErrModelNotFound = errors.New("model not found")
+
+	// ErrRateLimited matches errors for requests rejected by a rate limit or
+	// quota. See ProviderError.RetryAfter for when to try again.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrQuotaExceeded matches errors for requests rejected because the
+	// account is out of credit or past its billing limit. Unlike
+	// ErrRateLimited, waiting doesn't help, so these errors aren't retried.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// ErrorDetails is what a provider understood of an error response. Pass it
+// to NewDetailedError; zero fields are filled in from the status code and
+// body where possible.
+type ErrorDetails struct {
+	// Kind is one of the Err* errors of this package, or nil to classify the
+	// error from the status code, Code, and Message.
+	Kind error
+
+	// Code is the provider's error code or type, such as
+	// "context_length_exceeded" or "RESOURCE_EXHAUSTED".
+	Code string
+
+	// Message is the provider's description of the error.
+	Message string
+
+	// RequestID identifies the request in the provider's logs, for support
+	// tickets.
+	RequestID string
+
+	// RetryAfter is how long the provider asked callers to wait before
+	// retrying.
+	RetryAfter time.Duration
+}
+
+// ResponseErrorDetails returns the details of an error response found in its
+// headers: the request ID, the Retry-After delay, and the error type Amazon
+// APIs send in X-Amzn-Errortype. It returns empty details for a nil
+// response.
+func ResponseErrorDetails(resp *http.Response) ErrorDetails {
+	if resp == nil {
+		return ErrorDetails{}
+	}
+	errorType, _, _ := strings.Cut(resp.Header.Get("X-Amzn-Errortype"), ":")
+	return ErrorDetails{
+		Code:       errorType,
+		RequestID:  responseRequestID(resp.Header),
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// requestIDHeaders are the response headers providers return request IDs
+// in, in the order they are checked.
+var requestIDHeaders = []string{
+	"Request-Id",       // Anthropic
+	"X-Request-Id",     // OpenAI and OpenAI-compatible APIs
+	"X-Amzn-Requestid", // Amazon Bedrock
+	"Apim-Request-Id",  // Azure OpenAI
+	"X-Goog-Request-Id",
+}
+
+// responseRequestID returns the request ID of a response, or "" if it has
+// none.
+func responseRequestID(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// parseErrorBody returns the error code and message of a JSON error body.
+// It understands the shapes providers use:
+//
+//	{"type": "error", "error": {"type": "...", "message": "..."}}  // Anthropic
+//	{"error": {"code": "...", "type": "...", "message": "..."}}     // OpenAI
+//	{"error": {"code": 400, "status": "...", "message": "..."}}     // Google
+//	{"error": "...", "code": "..."}
+//	{"message": "...", "code": "..."}                               // Cohere, Bedrock
+//
+// Bodies it doesn't understand return empty strings.
+func parseErrorBody(body string) (code, message string) {
+	var envelope struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Code    json.RawMessage `json:"code"`
+		Type    string          `json:"type"`
+	}
+	if json.Unmarshal([]byte(body), &envelope) != nil {
+		return "", ""
+	}
+	code, message = jsonString(envelope.Code), envelope.Message
+	if envelope.Type != "error" && code == "" {
+		code = envelope.Type
+	}
+	var inner struct {
+		Code    json.RawMessage `json:"code"`
+		Type    string          `json:"type"`
+		Status  json.RawMessage `json:"status"`
+		Message string          `json:"message"`
+	}
+	if json.Unmarshal(envelope.Error, &inner) == nil {
+		// A numeric code or status is the HTTP status, so prefer the named
+		// fields
+		code = firstNonEmpty(textCode(inner.Code), textCode(inner.Status), inner.Type, code)
+		message = firstNonEmpty(inner.Message, message)
+	} else if text := jsonString(envelope.Error); text != "" {
+		message = firstNonEmpty(message, text)
+	}
+	return code, message
+}
+
+// jsonString returns a JSON string's value, or a number's text.
+func jsonString(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var number json.Number
+	if json.Unmarshal(raw, &number) == nil {
+		return number.String()
+	}
+	return ""
+}
+
+// textCode returns a JSON string's value, ignoring numbers.
+func textCode(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Error codes and message fragments, lower-cased, that identify each kind
+// of error across providers.
+var (
+	contextLengthCodes = []string{"context_length_exceeded", "string_above_max_length"}
+	contextLengthText  = []string{
+		"prompt is too long",     // Anthropic
+		"maximum context length", // OpenAI
+		"context length",         // OpenAI-compatible servers
+		"context window",         // OpenAI Responses API
+		"input token count",      // Google: "The input token count (N) exceeds ..."
+		"input is too long",      // Bedrock
+		"too many input tokens",
+		"exceeds the maximum number of tokens",
+	}
+
+	contentFilterCodes = []string{"content_filter", "content_policy_violation", "responsibleaipolicyviolation"}
+	contentFilterText  = []string{"content management policy", "content policy", "safety system"}
+
+	invalidAPIKeyCodes = []string{
+		"invalid_api_key", "authentication_error", "api_key_invalid",
+		"unauthenticated", "unrecognizedclientexception",
+	}
+	invalidAPIKeyText = []string{"api key not valid", "invalid api key", "incorrect api key", "invalid x-api-key"}
+
+	modelNotFoundCodes = []string{"model_not_found", "deploymentnotfound"}
+	modelNotFoundText  = []string{"model identifier is invalid", "does not exist or you do not have access"}
+
+	rateLimitedCodes = []string{
+		"rate_limit_error", "rate_limit_exceeded", "resource_exhausted",
+		"throttlingexception", "too_many_requests",
+	}
+
+	quotaExceededCodes = []string{"insufficient_quota", "billing_hard_limit_reached"}
+	quotaExceededText  = []string{"credit balance is too low"} // Anthropic
+)
+
+// classifyError returns the Err* error of this package that a response
+// matches, or nil.
+func classifyError(statusCode int, code, message string) error {
+	code, message = strings.ToLower(code), strings.ToLower(message)
+	matches := func(codes, texts []string) bool {
+		for _, c := range codes {
+			if code == c {
+				return true
+			}
+		}
+		for _, text := range texts {
+			if strings.Contains(message, text) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case matches(quotaExceededCodes, quotaExceededText):
+		return ErrQuotaExceeded
+	case statusCode == http.StatusTooManyRequests || matches(rateLimitedCodes, nil):
+		return ErrRateLimited
+	case statusCode == http.StatusUnauthorized || matches(invalidAPIKeyCodes, invalidAPIKeyText):
+		return ErrInvalidAPIKey
+	case matches(contextLengthCodes, contextLengthText):
+		return ErrContextLengthExceeded
+	case matches(contentFilterCodes, contentFilterText):
+		return ErrContentFiltered
+	case matches(modelNotFoundCodes, modelNotFoundText),
+		statusCode == http.StatusNotFound && (code == "not_found_error" || code == "not_found" || strings.Contains(message, "model")):
+		return ErrModelNotFound
+	}
+	return nil
+}
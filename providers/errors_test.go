@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/retry"
+)
+
+func TestProviderErrorKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+		code   string
+	}{
+		{"anthropic context", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`,
+			ErrContextLengthExceeded, "invalid_request_error"},
+		{"openai context", 400, `{"error":{"message":"This model's maximum context length is 128000 tokens.","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`,
+			ErrContextLengthExceeded, "context_length_exceeded"},
+		{"azure content filter", 400, `{"error":{"message":"The response was filtered due to the prompt triggering Azure OpenAI's content management policy.","code":"content_filter","status":400}}`,
+			ErrContentFiltered, "content_filter"},
+		{"anthropic auth", 401, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			ErrInvalidAPIKey, "authentication_error"},
+		{"google bad key", 400, `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`,
+			ErrInvalidAPIKey, "INVALID_ARGUMENT"},
+		{"openai model", 404, `{"error":{"message":"The model 'gpt-9' does not exist or you do not have access to it.","type":"invalid_request_error","code":"model_not_found"}}`,
+			ErrModelNotFound, "model_not_found"},
+		{"anthropic model", 404, `{"type":"error","error":{"type":"not_found_error","message":"model: claude-9"}}`,
+			ErrModelNotFound, "not_found_error"},
+		{"rate limit", 429, `{"message":"You are being rate limited"}`, ErrRateLimited, ""},
+		{"quota", 403, `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`,
+			ErrRateLimited, "RESOURCE_EXHAUSTED"},
+		{"openai quota", 429, `{"error":{"message":"You exceeded your current quota, please check your plan and billing details.","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			ErrQuotaExceeded, "insufficient_quota"},
+		{"anthropic credit", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"Your credit balance is too low to access the Anthropic API."}}`,
+			ErrQuotaExceeded, "invalid_request_error"},
+		{"other", 400, `{"error":{"message":"temperature must be at most 2","type":"invalid_request_error"}}`,
+			nil, "invalid_request_error"},
+		{"not json", 500, `upstream connect error`, nil, ""},
+	}
+	kinds := []error{ErrContextLengthExceeded, ErrContentFiltered, ErrInvalidAPIKey, ErrModelNotFound, ErrRateLimited, ErrQuotaExceeded}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewError(tt.status, tt.body)
+			for _, kind := range kinds {
+				assert.Equal(t, kind == tt.want, errors.Is(err, kind), kind.Error())
+			}
+			var providerErr *ProviderError
+			assert.True(t, errors.As(err, &providerErr))
+			assert.Equal(t, tt.code, providerErr.Code())
+			assert.Equal(t, tt.status, providerErr.StatusCode())
+			if tt.want == ErrQuotaExceeded {
+				assert.True(t, retry.IsPermanent(err))
+			}
+		})
+	}
+}
+
+func TestProviderErrorDetails(t *testing.T) {
+	resp := &http.Response{StatusCode: 400, Header: http.Header{"Request-Id": []string{"req_123"}}}
+	err := NewResponseError(resp, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long"}}`)
+	var providerErr *ProviderError
+	assert.True(t, errors.As(err, &providerErr))
+	assert.Equal(t, "req_123", providerErr.RequestID())
+	assert.Equal(t, "prompt is too long", providerErr.Message())
+	assert.Contains(t, err.Error(), "request req_123")
+	assert.False(t, errors.Is(err, llm.ErrRequestTooLarge))
+
+	// Details a provider passes take precedence over the body
+	err = NewDetailedError(400, "blocked", ErrorDetails{Kind: ErrContentFiltered, Code: "SAFETY"})
+	assert.True(t, errors.Is(err, ErrContentFiltered))
+	assert.True(t, errors.As(err, &providerErr))
+	assert.Equal(t, "SAFETY", providerErr.Code())
+	assert.Equal(t, "blocked", providerErr.Message())
+
+	resp = &http.Response{StatusCode: 400, Header: http.Header{
+		"X-Amzn-Requestid": []string{"amzn-1"},
+		"X-Amzn-Errortype": []string{"ValidationException:http://internal.amazon.com/coral/com.amazon.bedrock/"},
+	}}
+	err = NewResponseError(resp, `{"message":"Input is too long for requested model."}`)
+	assert.True(t, errors.Is(err, ErrContextLengthExceeded))
+	assert.True(t, errors.As(err, &providerErr))
+	assert.Equal(t, "ValidationException", providerErr.Code())
+	assert.Equal(t, "amzn-1", providerErr.RequestID())
+}
@@ -0,0 +1,115 @@
+package fireworks
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	openaic "github.com/deepnoodle-ai/dive/providers/openaicompletions"
+)
+
+var (
+	DefaultModel         = ModelDeepSeekV3p1
+	DefaultEndpoint      = "https://api.fireworks.ai/inference/v1/chat/completions"
+	DefaultMaxTokens     = 8192
+	DefaultMaxRetries    = openaic.DefaultMaxRetries
+	DefaultRetryBaseWait = openaic.DefaultRetryBaseWait
+	DefaultClient        = &http.Client{Timeout: 300 * time.Second}
+)
+
+var (
+	_ llm.StreamingLLM      = &Provider{}
+	_ llm.ResponseFormatter = &Provider{}
+)
+
+// Provider implements the Fireworks AI API, which is compatible with the
+// OpenAI Chat Completions API. Function calling, usage, and streaming are
+// handled by the embedded Chat Completions provider.
+//
+// llm.WithResponseFormat uses Fireworks' JSON mode, which constrains
+// generation with a grammar built from the schema. Other response_format
+// values, such as {"type": "grammar", "grammar": "..."} for a custom GBNF
+// grammar, can be sent with llm.WithProviderOption("fireworks:response_format", ...).
+type Provider struct {
+	apiKey        string
+	endpoint      string
+	model         string
+	maxTokens     int
+	maxRetries    int
+	retryBaseWait time.Duration
+	client        *http.Client
+
+	// Embedded OpenAI completions provider
+	*openaic.Provider
+}
+
+// New creates a new Fireworks provider with the given options.
+func New(opts ...Option) *Provider {
+	p := &Provider{
+		apiKey:        os.Getenv("FIREWORKS_API_KEY"),
+		endpoint:      DefaultEndpoint,
+		client:        DefaultClient,
+		model:         DefaultModel,
+		maxTokens:     DefaultMaxTokens,
+		maxRetries:    DefaultMaxRetries,
+		retryBaseWait: DefaultRetryBaseWait,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	// Pass the options through to the wrapped OpenAI provider
+	p.Provider = openaic.New(
+		openaic.WithName("fireworks"),
+		openaic.WithAPIKey(p.apiKey),
+		openaic.WithClient(p.client),
+		openaic.WithEndpoint(p.endpoint),
+		openaic.WithMaxTokens(p.maxTokens),
+		openaic.WithMaxRetries(p.maxRetries),
+		openaic.WithBaseWait(p.retryBaseWait),
+		openaic.WithModel(p.model),
+		openaic.WithSystemRole("system"),
+		openaic.WithResponseFormatEncoder(encodeResponseFormat),
+	)
+	return p
+}
+
+func (p *Provider) Name() string {
+	return "fireworks"
+}
+
+// SupportsResponseFormat implements llm.ResponseFormatter. JSON and JSON
+// schema formats use Fireworks' JSON mode.
+func (p *Provider) SupportsResponseFormat(format *llm.ResponseFormat) bool {
+	if format == nil {
+		return false
+	}
+	switch format.Type {
+	case llm.ResponseFormatTypeText, llm.ResponseFormatTypeJSON, llm.ResponseFormatTypeJSONSchema:
+		return true
+	}
+	return false
+}
+
+// encodeResponseFormat converts format to Fireworks' response_format:
+// {"type": "json_object"}, with a "schema" when one is given.
+func encodeResponseFormat(format *llm.ResponseFormat) (any, error) {
+	switch format.Type {
+	case "", llm.ResponseFormatTypeText:
+		return nil, nil
+	case llm.ResponseFormatTypeJSON, llm.ResponseFormatTypeJSONSchema:
+		if format.Type == llm.ResponseFormatTypeJSONSchema && format.Schema == nil {
+			return nil, errors.New("fireworks: json_schema response format requires a schema")
+		}
+		encoded := map[string]any{"type": "json_object"}
+		if format.Schema != nil {
+			encoded["schema"] = format.Schema
+		}
+		return encoded, nil
+	default:
+		return nil, fmt.Errorf("fireworks: unsupported response format type %q", format.Type)
+	}
+}
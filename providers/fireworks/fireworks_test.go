@@ -0,0 +1,173 @@
+package fireworks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(WithEndpoint(server.URL), WithAPIKey("test-key"), WithMaxRetries(0))
+}
+
+// decodeBody decodes a request body into a generic map.
+func decodeBody(t *testing.T, r *http.Request) map[string]any {
+	t.Helper()
+	var body map[string]any
+	assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	return body
+}
+
+func TestGenerateResponseFormatSchema(t *testing.T) {
+	var body map[string]any
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		body = decodeBody(t, r)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"resp_1","object":"chat.completion","model":"accounts/fireworks/models/deepseek-v3p1",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"{\"city\":\"Paris\"}"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":14,"completion_tokens":6,"total_tokens":20}}`)
+	})
+
+	response, err := p.Generate(context.Background(),
+		llm.WithUserTextMessage("What is the capital of France?"),
+		llm.WithResponseFormat(&llm.ResponseFormat{
+			Type: llm.ResponseFormatTypeJSONSchema,
+			Name: "answer",
+			Schema: &schema.Schema{
+				Type:       "object",
+				Properties: map[string]*schema.Property{"city": {Type: "string"}},
+				Required:   []string{"city"},
+			},
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"city":"Paris"}`, response.Message().Text())
+	assert.Equal(t, 14, response.Usage.InputTokens)
+	assert.Equal(t, 6, response.Usage.OutputTokens)
+
+	assert.Equal(t, ModelDeepSeekV3p1, body["model"])
+	format, ok := body["response_format"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, "json_object", format["type"])
+	responseSchema, ok := format["schema"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, "object", responseSchema["type"])
+	assert.Equal(t, []any{"city"}, responseSchema["required"])
+}
+
+func TestGenerateResponseFormatOmitted(t *testing.T) {
+	var body map[string]any
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		body = decodeBody(t, r)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"resp_1","object":"chat.completion","model":"m",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	})
+
+	_, err := p.Generate(context.Background(),
+		llm.WithUserTextMessage("hi"),
+		llm.WithResponseFormat(&llm.ResponseFormat{Type: llm.ResponseFormatTypeText}),
+	)
+	assert.NoError(t, err)
+	_, ok := body["response_format"]
+	assert.False(t, ok)
+}
+
+func TestGenerateGrammarProviderOption(t *testing.T) {
+	var body map[string]any
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		body = decodeBody(t, r)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"resp_1","object":"chat.completion","model":"m",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"yes"},"finish_reason":"stop"}]}`)
+	})
+
+	_, err := p.Generate(context.Background(),
+		llm.WithUserTextMessage("Is the sky blue?"),
+		llm.WithStrictProviderOptions(true),
+		llm.WithProviderOption("fireworks:response_format", map[string]any{
+			"type":    "grammar",
+			"grammar": `root ::= "yes" | "no"`,
+		}),
+	)
+	assert.NoError(t, err)
+	format, ok := body["response_format"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, "grammar", format["type"])
+}
+
+func TestResponseFormatSchemaRequired(t *testing.T) {
+	p := New(WithAPIKey("test-key"))
+	_, err := p.Generate(context.Background(),
+		llm.WithUserTextMessage("hi"),
+		llm.WithResponseFormat(&llm.ResponseFormat{Type: llm.ResponseFormatTypeJSONSchema}),
+	)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a schema")
+}
+
+func TestStreamToolCall(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		body := decodeBody(t, r)
+		assert.Equal(t, true, body["stream"])
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data: {"id":"resp_1","object":"chat.completion.chunk","model":"accounts/fireworks/models/kimi-k2-instruct","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]},"finish_reason":null}]}
+
+data: {"id":"resp_1","object":"chat.completion.chunk","model":"accounts/fireworks/models/kimi-k2-instruct","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"resp_1","object":"chat.completion.chunk","model":"accounts/fireworks/models/kimi-k2-instruct","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":30,"completion_tokens":11,"total_tokens":41}}
+
+data: [DONE]
+
+`)
+	})
+
+	iterator, err := p.Stream(context.Background(),
+		llm.WithModel(ModelKimiK2Instruct),
+		llm.WithUserTextMessage("What's the weather in Paris?"),
+	)
+	assert.NoError(t, err)
+	defer iterator.Close()
+
+	accumulator := llm.NewResponseAccumulator()
+	for iterator.Next() {
+		assert.NoError(t, accumulator.AddEvent(iterator.Event()))
+	}
+	assert.NoError(t, iterator.Err())
+
+	response := accumulator.Response()
+	calls := response.ToolCalls()
+	assert.Len(t, calls, 1)
+	assert.Equal(t, "get_weather", calls[0].Name)
+	assert.Equal(t, `{"city":"Paris"}`, string(calls[0].Input))
+	assert.Equal(t, 30, response.Usage.InputTokens)
+	assert.Equal(t, 11, response.Usage.OutputTokens)
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "fireworks", New().Name())
+}
+
+func TestRegistryRequiresAPIKey(t *testing.T) {
+	t.Setenv("FIREWORKS_API_KEY", "")
+	_, ok := providers.CreateModel(ModelKimiK2Instruct, "").(*Provider)
+	assert.False(t, ok)
+
+	t.Setenv("FIREWORKS_API_KEY", "test-key")
+	model, ok := providers.CreateModel(ModelKimiK2Instruct, "").(*Provider)
+	assert.True(t, ok)
+	assert.Equal(t, ModelKimiK2Instruct, model.model)
+	assert.Equal(t, "test-key", model.apiKey)
+}
@@ -0,0 +1,22 @@
+package fireworks
+
+// Fireworks names its serverless models by account and model ID.
+const (
+	// ModelDeepSeekV3p1 is DeepSeek V3.1, a hybrid reasoning model.
+	ModelDeepSeekV3p1 = "accounts/fireworks/models/deepseek-v3p1"
+
+	// ModelKimiK2Instruct is Moonshot's Kimi K2, tuned for agentic tool use.
+	ModelKimiK2Instruct = "accounts/fireworks/models/kimi-k2-instruct"
+
+	// ModelQwen3_235B is Qwen3 235B A22B, a mixture-of-experts model.
+	ModelQwen3_235B = "accounts/fireworks/models/qwen3-235b-a22b"
+
+	// ModelLlama3p3_70BInstruct is Meta's Llama 3.3 70B Instruct.
+	ModelLlama3p3_70BInstruct = "accounts/fireworks/models/llama-v3p3-70b-instruct"
+
+	// ModelGPTOSS120B is OpenAI's open-weight gpt-oss-120b.
+	ModelGPTOSS120B = "accounts/fireworks/models/gpt-oss-120b"
+
+	// ModelGPTOSS20B is OpenAI's smaller open-weight gpt-oss-20b.
+	ModelGPTOSS20B = "accounts/fireworks/models/gpt-oss-20b"
+)
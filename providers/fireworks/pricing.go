@@ -0,0 +1,49 @@
+package fireworks
+
+import "github.com/deepnoodle-ai/dive/llm"
+
+// TextModelPricing contains token pricing for Fireworks serverless models.
+var TextModelPricing = map[string]llm.PricingInfo{
+	ModelDeepSeekV3p1: {
+		Model:       ModelDeepSeekV3p1,
+		InputPrice:  0.56,
+		OutputPrice: 1.68,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelKimiK2Instruct: {
+		Model:       ModelKimiK2Instruct,
+		InputPrice:  0.60,
+		OutputPrice: 2.50,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelQwen3_235B: {
+		Model:       ModelQwen3_235B,
+		InputPrice:  0.22,
+		OutputPrice: 0.88,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelLlama3p3_70BInstruct: {
+		Model:       ModelLlama3p3_70BInstruct,
+		InputPrice:  0.90,
+		OutputPrice: 0.90,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelGPTOSS120B: {
+		Model:       ModelGPTOSS120B,
+		InputPrice:  0.15,
+		OutputPrice: 0.60,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelGPTOSS20B: {
+		Model:       ModelGPTOSS20B,
+		InputPrice:  0.07,
+		OutputPrice: 0.30,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+}
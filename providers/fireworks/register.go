@@ -0,0 +1,27 @@
+package fireworks
+
+import (
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+func init() {
+	// Fireworks model IDs contain "/", so this entry must be registered
+	// before OpenRouter's ContainsMatcher("/"). Package initialization
+	// order guarantees that when both are imported.
+	providers.Register(providers.ProviderEntry{
+		Name:             "fireworks",
+		Match:            providers.EnvMatcher("FIREWORKS_API_KEY", providers.ContainsMatcher("accounts/fireworks/")),
+		Factory:          factory,
+		APIKeyEnv:        []string{"FIREWORKS_API_KEY"},
+		HealthCheckModel: ModelGPTOSS20B,
+	})
+}
+
+func factory(model, endpoint string) llm.LLM {
+	opts := []Option{WithModel(model)}
+	if endpoint != "" {
+		opts = append(opts, WithEndpoint(endpoint))
+	}
+	return New(opts...)
+}
@@ -0,0 +1,66 @@
+package google
+
+import "github.com/deepnoodle-ai/dive/llm"
+
+// TextModelCapabilities describes what the text generation models can do.
+var TextModelCapabilities = []llm.ModelInfo{
+	geminiCapabilities(ModelGemini36Flash, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini35Flash, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini35FlashLite, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini31ProPreview, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini31ProPreviewCustomTools, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini31FlashLite, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini31FlashLitePreview, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini3FlashPreview, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini25Pro, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini25Flash, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini25FlashLite, 1_048_576, 65_536, true),
+	geminiCapabilities(ModelGemini20Flash, 1_048_576, 8192, false),
+	geminiCapabilities(ModelGemini15Pro, 2_097_152, 8192, false),
+	geminiCapabilities(ModelGemini15Flash, 1_048_576, 8192, false),
+}
+
+// ImageModelCapabilities describes what the Nano Banana image generation
+// models can do. They take text and images and return both, without tools.
+var ImageModelCapabilities = []llm.ModelInfo{
+	geminiImageCapabilities(ModelGemini25FlashImage, 32_768, 32_768),
+	geminiImageCapabilities(ModelGemini31FlashLiteImage, 131_072, 32_768),
+	geminiImageCapabilities(ModelGemini31FlashImage, 131_072, 32_768),
+	geminiImageCapabilities(ModelGemini31FlashImagePrev, 131_072, 32_768),
+	geminiImageCapabilities(ModelGemini3ProImage, 65_536, 32_768),
+	geminiImageCapabilities(ModelGemini3ProImagePreview, 65_536, 32_768),
+}
+
+// geminiCapabilities returns the capabilities shared by Gemini text models:
+// text, image, audio, video, and PDF input, tools, structured outputs, and
+// implicit prompt caching.
+func geminiCapabilities(model string, contextWindow, maxOutputTokens int, reasoning bool) llm.ModelInfo {
+	return llm.ModelInfo{
+		Model:           model,
+		Provider:        ProviderName,
+		ContextWindow:   contextWindow,
+		MaxOutputTokens: maxOutputTokens,
+		InputModalities: []llm.Modality{
+			llm.ModalityText, llm.ModalityImage, llm.ModalityAudio,
+			llm.ModalityVideo, llm.ModalityPDF,
+		},
+		OutputModalities: []llm.Modality{llm.ModalityText},
+		Tools:            true,
+		JSONSchema:       true,
+		Reasoning:        reasoning,
+		PromptCaching:    true,
+		UpdatedAt:        "2026-10-17",
+	}
+}
+
+func geminiImageCapabilities(model string, contextWindow, maxOutputTokens int) llm.ModelInfo {
+	return llm.ModelInfo{
+		Model:            model,
+		Provider:         ProviderName,
+		ContextWindow:    contextWindow,
+		MaxOutputTokens:  maxOutputTokens,
+		InputModalities:  []llm.Modality{llm.ModalityText, llm.ModalityImage},
+		OutputModalities: []llm.Modality{llm.ModalityText, llm.ModalityImage},
+		UpdatedAt:        "2026-10-17",
+	}
+}
@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
 	"sync"
 	"time"
 
+	"cloud.google.com/go/auth"
+	"cloud.google.com/go/auth/credentials"
+	"cloud.google.com/go/auth/httptransport"
 	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/dive/providers"
 	"github.com/deepnoodle-ai/wonton/retry"
@@ -18,24 +22,36 @@ import (
 
 const ProviderName = "google"
 
+// vertexScope is the OAuth scope requested for Vertex AI credentials.
+const vertexScope = "https://www.googleapis.com/auth/cloud-platform"
+
 var (
 	DefaultModel         = ModelGemini25Pro
 	DefaultMaxTokens     = 32768
 	DefaultClient        *http.Client
 	DefaultMaxRetries    = 3
 	DefaultRetryBaseWait = 1 * time.Second
-	DefaultVersion       = "v1"
+
+	// DefaultVersion is the API version used unless pinned with
+	// WithAPIVersion. Empty uses the SDK default.
+	DefaultVersion = ""
 )
 
+// apiVersionPattern matches Google API versions such as "v1" and "v1beta".
+var apiVersionPattern = regexp.MustCompile(`^v\d+((alpha|beta)\d*)?$`)
+
 var _ llm.StreamingLLM = &Provider{}
+var _ llm.ToolLimiter = &Provider{}
 
 // Provider implements the Google Gemini LLM provider.
 type Provider struct {
 	client        *genai.Client
+	httpClient    *http.Client
 	projectID     string
 	location      string
 	apiKey        string
 	vertexAI      bool
+	credentials   *auth.Credentials
 	model         string
 	maxTokens     int
 	maxRetries    int
@@ -73,23 +89,37 @@ func (p *Provider) initClient(ctx context.Context) (*genai.Client, error) {
 	if p.client != nil {
 		return p.client, nil
 	}
+	if p.version != "" && !apiVersionPattern.MatchString(p.version) {
+		return nil, fmt.Errorf("invalid google api version %q: expected a version like \"v1\" or \"v1beta\"", p.version)
+	}
+	httpOptions := genai.HTTPOptions{APIVersion: p.version}
 	var cfg *genai.ClientConfig
 	if p.vertexAI {
-		// Vertex AI authenticates with Application Default Credentials. An API
-		// key is mutually exclusive with project/location in the genai client
-		// config, and this backend resolves auth via ADC, so we pass only the
-		// project and location. An empty location is resolved by the SDK from
-		// GOOGLE_CLOUD_LOCATION/GOOGLE_CLOUD_REGION, defaulting to "global".
+		// Vertex AI authenticates with Application Default Credentials, or
+		// the credentials from WithCredentials. An API key is mutually
+		// exclusive with project/location in the genai client config, so we
+		// pass only the project and location. An empty location is resolved
+		// by the SDK from GOOGLE_CLOUD_LOCATION/GOOGLE_CLOUD_REGION, defaulting
+		// to "global".
+		httpClient, err := p.vertexHTTPClient()
+		if err != nil {
+			return nil, err
+		}
 		cfg = &genai.ClientConfig{
-			Backend:  genai.BackendVertexAI,
-			Project:  p.projectID,
-			Location: p.location,
+			Backend:     genai.BackendVertexAI,
+			Project:     p.projectID,
+			Location:    p.location,
+			Credentials: p.credentials,
+			HTTPClient:  httpClient,
+			HTTPOptions: httpOptions,
 		}
 	} else {
 		// The Gemini API backend authenticates with an API key, which is
 		// mutually exclusive with project/location, so we pass only the key.
 		cfg = &genai.ClientConfig{
-			APIKey: p.apiKey,
+			APIKey:      p.apiKey,
+			HTTPClient:  p.httpClient,
+			HTTPOptions: httpOptions,
 		}
 	}
 	client, err := genai.NewClient(ctx, cfg)
@@ -100,10 +130,41 @@ func (p *Provider) initClient(ctx context.Context) (*genai.Client, error) {
 	return p.client, nil
 }
 
+// vertexHTTPClient returns the HTTP client for the Vertex AI backend. The SDK
+// only adds authorization to the client it creates itself, so a client from
+// WithClient is copied and given an authorizing transport here. It returns
+// nil when no client was set.
+func (p *Provider) vertexHTTPClient() (*http.Client, error) {
+	if p.httpClient == nil {
+		return nil, nil
+	}
+	creds := p.credentials
+	if creds == nil {
+		var err error
+		creds, err = credentials.DetectDefault(&credentials.DetectOptions{
+			Scopes: []string{vertexScope},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find google default credentials: %w", err)
+		}
+	}
+	client := *p.httpClient
+	if err := httptransport.AddAuthorizationMiddleware(&client, creds); err != nil {
+		return nil, fmt.Errorf("failed to authorize google http client: %w", err)
+	}
+	return &client, nil
+}
+
 func (p *Provider) Name() string {
 	return ProviderName
 }
 
+// ToolLimits implements llm.ToolLimiter. Function names are limited to 64
+// characters.
+func (p *Provider) ToolLimits() llm.ToolLimits {
+	return llm.ToolLimits{MaxNameLength: 64}
+}
+
 func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
 	config := &llm.Config{}
 	config.Apply(opts...)
@@ -121,6 +182,7 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 	}
 
 	// Convert messages to genai.Content format
+	rendered = foldDeveloperMessages(&request, rendered)
 	contents, err := messagesToContents(rendered)
 	if err != nil {
 		return nil, err
@@ -157,7 +219,7 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 		}
 
 		var convErr error
-		result, convErr = convertGoogleResponse(resp, request.Model)
+		result, convErr = convertGoogleResponse(resp, modelID(request.Model))
 		if convErr != nil {
 			return fmt.Errorf("error converting response: %w", convErr)
 		}
@@ -204,6 +266,7 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 	}
 
 	// Convert messages to genai.Content format
+	rendered = foldDeveloperMessages(&request, rendered)
 	contents, err := messagesToContents(rendered)
 	if err != nil {
 		return nil, fmt.Errorf("error converting messages: %w", err)
@@ -225,17 +288,17 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 		return nil, err
 	}
 
-	stream := providers.NewRetryingStreamIterator(ctx, providers.StreamRetryConfig{
+	stream := providers.NewContextRetryingStreamIterator(ctx, providers.StreamRetryConfig{
 		Provider:      p.Name(),
 		MaxRetries:    p.maxRetries,
 		RetryBaseWait: p.retryBaseWait,
 		Logger:        config.Logger,
-	}, func() (llm.StreamIterator, error) {
+	}, func(ctx context.Context) (llm.StreamIterator, error) {
 		// GenerateContentStream reports request failures through its lazy
 		// sequence. The shared iterator consumes the first result as part of
 		// the provider's pre-event retry boundary.
 		streamSeq := p.client.Models.GenerateContentStream(ctx, request.Model, contents, genConfig)
-		return NewStreamIteratorFromSeq(ctx, streamSeq, request.Model), nil
+		return NewStreamIteratorFromSeq(ctx, streamSeq, modelID(request.Model)), nil
 	})
 
 	return stream, nil
@@ -247,23 +310,38 @@ func renderReminderMessages(messages []*llm.Message) ([]*llm.Message, error) {
 	return llm.RenderReminders(messages, nil)
 }
 
-// wrapGoogleError converts a Google API error to a providers.NewError so that
-// retry.SkipPermanent can detect non-retryable status codes. Falls back to the
-// original error if it's not a genai.APIError. The SDK returns APIError as a
-// value today, while callers and wrappers may still expose a pointer, so both
-// forms must be handled.
+// wrapGoogleError converts a Google API error to a providers.ProviderError so
+// that retry.SkipPermanent can detect non-retryable status codes, classified
+// by the error's reason, such as API_KEY_INVALID, or else its status, such as
+// RESOURCE_EXHAUSTED. Falls back to the original error if it's not a
+// genai.APIError. The SDK returns APIError as a value today, while callers
+// and wrappers may still expose a pointer, so both forms must be handled.
 func wrapGoogleError(err error) error {
 	var apiErr genai.APIError
 	if errors.As(err, &apiErr) {
-		return providers.NewError(apiErr.Code, apiErr.Message)
+		return newAPIError(&apiErr)
 	}
 	var apiErrPtr *genai.APIError
 	if errors.As(err, &apiErrPtr) {
-		return providers.NewError(apiErrPtr.Code, apiErrPtr.Message)
+		return newAPIError(apiErrPtr)
 	}
 	return err
 }
 
+func newAPIError(apiErr *genai.APIError) error {
+	code := apiErr.Status
+	for _, detail := range apiErr.Details {
+		if reason, ok := detail["reason"].(string); ok && reason != "" {
+			code = reason
+			break
+		}
+	}
+	return providers.NewDetailedError(apiErr.Code, apiErr.Message, providers.ErrorDetails{
+		Code:    code,
+		Message: apiErr.Message,
+	})
+}
+
 func (p *Provider) applyRequestConfig(req *Request, config *llm.Config) error {
 	req.Model = config.Model
 	if req.Model == "" {
@@ -276,6 +354,10 @@ func (p *Provider) applyRequestConfig(req *Request, config *llm.Config) error {
 		req.MaxTokens = p.maxTokens
 	}
 
+	if config.ParallelToolCalls != nil && !*config.ParallelToolCalls {
+		return fmt.Errorf("google: disabling parallel tool calls: %w", llm.ErrNotSupported)
+	}
+
 	if len(config.Tools) > 0 {
 		var tools []map[string]any
 		for _, tool := range config.Tools {
@@ -298,6 +380,7 @@ func (p *Provider) applyRequestConfig(req *Request, config *llm.Config) error {
 		config.Logger.Warn("temperature is not supported by this Google model and will be ignored",
 			"model", req.Model)
 	}
+	req.ThinkingBudget = config.ReasoningBudget
 	// Dive does not currently expose top_p or top_k. If those controls are
 	// added, apply the same Gemini request-generation cutoff used above.
 	req.System = config.SystemPrompt
@@ -2,11 +2,15 @@ package google
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/http"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"cloud.google.com/go/auth"
 	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/wonton/assert"
 	"github.com/deepnoodle-ai/wonton/schema"
@@ -149,6 +153,72 @@ func TestProviderVertexAIOption(t *testing.T) {
 	assert.Equal(t, "test-project", provider.projectID)
 }
 
+func TestProviderVertexOption(t *testing.T) {
+	provider := New(WithVertex("test-project", "europe-west4"))
+	assert.True(t, provider.vertexAI)
+	assert.Equal(t, "test-project", provider.projectID)
+	assert.Equal(t, "europe-west4", provider.location)
+}
+
+type staticTokenProvider string
+
+func (s staticTokenProvider) Token(context.Context) (*auth.Token, error) {
+	return &auth.Token{Value: string(s), Type: "Bearer"}, nil
+}
+
+// roundTripFunc is an http.RoundTripper that records requests instead of
+// sending them.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestVertexGenerateContent(t *testing.T) {
+	var requests []*http.Request
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests = append(requests, r)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body: io.NopCloser(strings.NewReader(`{"candidates":[{"content":{"role":"model","parts":[{"text":"hello"}]},"finishReason":"STOP"}],
+				"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":1}}`)),
+			Request: r,
+		}, nil
+	})
+
+	provider := New(
+		WithVertex("test-project", "us-central1"),
+		WithCredentials(auth.NewCredentials(&auth.CredentialsOptions{TokenProvider: staticTokenProvider("test-token")})),
+		WithTransport(transport),
+		WithAPIKey("unused-key"),
+		WithMaxRetries(0),
+	)
+	response, err := provider.Generate(context.Background(),
+		llm.WithModel("publishers/google/models/"+ModelGemini25Flash),
+		llm.WithUserTextMessage("hi"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", response.Message().Text())
+	assert.Equal(t, ModelGemini25Flash, response.Model)
+	assert.Equal(t, 4, response.Usage.InputTokens)
+
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "us-central1-aiplatform.googleapis.com", requests[0].URL.Host)
+	assert.Equal(t, "/v1beta1/projects/test-project/locations/us-central1/publishers/google/models/gemini-2.5-flash:generateContent", requests[0].URL.Path)
+	assert.Equal(t, "Bearer test-token", requests[0].Header.Get("Authorization"))
+	assert.Equal(t, "", requests[0].Header.Get("x-goog-api-key"))
+}
+
+func TestModelID(t *testing.T) {
+	for _, model := range []string{
+		"gemini-2.5-pro",
+		"models/gemini-2.5-pro",
+		"publishers/google/models/gemini-2.5-pro",
+		"projects/p/locations/us-central1/publishers/google/models/gemini-2.5-pro",
+	} {
+		assert.Equal(t, "gemini-2.5-pro", modelID(model), model)
+	}
+}
+
 // func TestConvertMessages(t *testing.T) {
 // 	messages := []*llm.Message{
 // 		llm.NewUserTextMessage("Hello"),
@@ -518,3 +588,26 @@ func TestStreamingIntegration(t *testing.T) {
 	}
 	assert.True(t, hasKeyword, "Response should contain one of the keywords")
 }
+
+// pathRecorder records request paths and fails every request.
+type pathRecorder struct{ paths []string }
+
+func (r *pathRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.paths = append(r.paths, req.URL.Path)
+	return nil, errors.New("offline")
+}
+
+func TestAPIVersion(t *testing.T) {
+	ctx := context.Background()
+	recorder := &pathRecorder{}
+	p := New(WithAPIKey("test-key"), WithAPIVersion("v1"), WithMaxRetries(0),
+		WithClient(&http.Client{Transport: recorder}))
+	_, err := p.Generate(ctx, llm.WithUserTextMessage("hi"))
+	assert.Error(t, err)
+	assert.True(t, len(recorder.paths) > 0)
+	assert.True(t, strings.HasPrefix(recorder.paths[0], "/v1/models/"))
+
+	_, err = New(WithAPIKey("test-key"), WithAPIVersion("beta")).Generate(ctx, llm.WithUserTextMessage("hi"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid google api version "beta"`)
+}
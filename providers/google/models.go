@@ -52,11 +52,23 @@ const (
 	ModelGemini15Flash = "gemini-1.5-flash"
 )
 
+// modelID returns the model ID from a model resource name, so that names in
+// the forms the Gemini API and Vertex AI accept, such as
+// "models/gemini-2.5-pro" and
+// "projects/p/locations/us-central1/publishers/google/models/gemini-2.5-pro",
+// are treated like "gemini-2.5-pro".
+func modelID(model string) string {
+	if i := strings.LastIndex(model, "models/"); i >= 0 {
+		return model[i+len("models/"):]
+	}
+	return model
+}
+
 // shouldOmitTemperature reports whether a model belongs to the Gemini request
 // generation that deprecated temperature. The cutover starts with Gemini 3.5
 // Flash-Lite and all Gemini 3.6+ models.
 func shouldOmitTemperature(model string) bool {
-	model = strings.TrimPrefix(model, "models/")
+	model = modelID(model)
 	if model == ModelGemini35FlashLite || strings.HasPrefix(model, ModelGemini35FlashLite+"-") {
 		return true
 	}
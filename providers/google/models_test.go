@@ -1,6 +1,7 @@
 package google
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/deepnoodle-ai/dive/llm"
@@ -64,6 +65,7 @@ func TestShouldOmitTemperatureCoversFutureGeminiModels(t *testing.T) {
 		{"gemini-3.7-pro", true},
 		{"gemini-4-pro", true},
 		{"models/gemini-4.1-flash", true},
+		{"publishers/google/models/gemini-4.1-flash", true},
 		{ModelGemini35Flash, false},
 		{ModelGemini31ProPreview, false},
 		{"not-a-gemini-model", false},
@@ -89,6 +91,17 @@ func TestGemini35FlashKeepsTemperature(t *testing.T) {
 	assert.Equal(t, temperature, *request.Temperature)
 }
 
+func TestDisablingParallelToolCallsIsNotSupported(t *testing.T) {
+	provider := New()
+	var request Request
+	disabled := false
+	err := provider.applyRequestConfig(&request, &llm.Config{ParallelToolCalls: &disabled})
+	assert.True(t, errors.Is(err, llm.ErrNotSupported))
+
+	enabled := true
+	assert.NoError(t, provider.applyRequestConfig(&request, &llm.Config{ParallelToolCalls: &enabled}))
+}
+
 type recordingWarningLogger struct {
 	warnings []string
 }
@@ -1,7 +1,11 @@
 package google
 
 import (
+	"net/http"
 	"time"
+
+	"cloud.google.com/go/auth"
+	"github.com/deepnoodle-ai/dive/providers"
 )
 
 // Option is a function that configures the Google provider.
@@ -49,13 +53,25 @@ func WithRetryBaseWait(retryBaseWait time.Duration) Option {
 	}
 }
 
-// WithVersion sets the API version.
-func WithVersion(version string) Option {
+// WithAPIVersion pins the API version in request paths, such as "v1" or
+// "v1beta", so upstream API changes are adopted only when the version is
+// changed. Requests fail with an error if the version is malformed. When
+// unset, the SDK default is used: "v1beta" for the Gemini API and "v1beta1"
+// for Vertex AI. Some features, such as preview models and tools, are only
+// available in beta versions.
+func WithAPIVersion(version string) Option {
 	return func(p *Provider) {
 		p.version = version
 	}
 }
 
+// WithVersion sets the API version.
+//
+// Deprecated: Use WithAPIVersion.
+func WithVersion(version string) Option {
+	return WithAPIVersion(version)
+}
+
 // WithAPIKey sets the API key for the provider.
 func WithAPIKey(apiKey string) Option {
 	return func(p *Provider) {
@@ -76,3 +92,47 @@ func WithVertexAI(location string) Option {
 		p.location = location
 	}
 }
+
+// WithVertex routes requests through the Vertex AI generateContent endpoint of
+// the given GCP project and region, authenticating with Application Default
+// Credentials, such as a service account named by
+// GOOGLE_APPLICATION_CREDENTIALS. It is WithVertexAI and WithProjectID in one
+// option. Model names, request conversion, and streaming are the same as on
+// the Gemini API.
+func WithVertex(projectID, location string) Option {
+	return func(p *Provider) {
+		p.vertexAI = true
+		p.projectID = projectID
+		p.location = location
+	}
+}
+
+// WithCredentials sets the Google Cloud credentials used by the Vertex AI
+// backend in place of Application Default Credentials, for example a service
+// account key loaded with cloud.google.com/go/auth/credentials.DetectDefault.
+// It has no effect on the Gemini API backend, which uses an API key.
+func WithCredentials(creds *auth.Credentials) Option {
+	return func(p *Provider) {
+		p.credentials = creds
+	}
+}
+
+// WithClient sets the HTTP client used by the genai SDK. By default the SDK
+// uses its own client.
+func WithClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.httpClient = client
+	}
+}
+
+// WithTransport sets the HTTP transport used for all API requests, via a
+// client with no overall timeout. See providers.NewTransportClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return WithClient(providers.NewTransportClient(transport))
+}
+
+// WithStreamingTransport uses a transport tuned for long streaming responses,
+// such as extended reasoning. See providers.NewStreamingTransport.
+func WithStreamingTransport() Option {
+	return WithTransport(providers.NewStreamingTransport())
+}
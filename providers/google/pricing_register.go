@@ -3,9 +3,12 @@ package google
 import "github.com/deepnoodle-ai/dive/providers"
 
 // init publishes this provider's model pricing to the central registry so usage
-// cost can be attached automatically (see providers.PricingFor / llm.PopulateCost).
+// cost can be attached automatically (see providers.PricingFor / llm.PopulateCost),
+// and its model capabilities for providers.Capabilities.
 func init() {
 	for _, p := range TextModelPricing {
 		providers.RegisterPricing(p, false)
 	}
+	providers.RegisterCapabilities(TextModelCapabilities...)
+	providers.RegisterCapabilities(ImageModelCapabilities...)
 }
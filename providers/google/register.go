@@ -7,9 +7,11 @@ import (
 
 func init() {
 	providers.Register(providers.ProviderEntry{
-		Name:    "google",
-		Match:   providers.PrefixMatcher("gemini-"),
-		Factory: factory,
+		Name:             "google",
+		Match:            providers.PrefixMatcher("gemini-"),
+		Factory:          factory,
+		APIKeyEnv:        []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"},
+		HealthCheckModel: ModelGemini31FlashLite,
 	})
 }
 
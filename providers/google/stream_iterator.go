@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"iter"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -36,8 +38,11 @@ type StreamIterator struct {
 	messageStartSent bool
 	nextBlockIndex   int
 	textBlockIndex   int // index of the open text block, or -1 if none
+	lastTextIndex    int // index of the last text block, or -1 if none
+	grounding        *genai.GroundingMetadata
 	usage            *genai.GenerateContentResponseUsageMetadata
 	finishReason     genai.FinishReason
+	sawToolCall      bool
 
 	mu sync.Mutex
 }
@@ -50,6 +55,7 @@ func NewStreamIteratorFromSeq(ctx context.Context, streamSeq iter.Seq2[*genai.Ge
 		model:          model,
 		responseID:     fmt.Sprintf("google_%s_%d", model, time.Now().UnixNano()),
 		textBlockIndex: -1,
+		lastTextIndex:  -1,
 	}
 }
 
@@ -95,6 +101,13 @@ func (s *StreamIterator) Next() bool {
 			return false
 		}
 		if !hasMore {
+			// The SDK ends the sequence without an error when the context is
+			// canceled mid-response, so that must not read as a full response.
+			if err := s.ctx.Err(); err != nil {
+				s.err = err
+				s.done = true
+				return false
+			}
 			// Stream ended: close any open block and emit the final
 			// message_delta (usage + stop reason) and message_stop events.
 			s.queueFinalEvents()
@@ -160,6 +173,9 @@ func (s *StreamIterator) processChunk(response *genai.GenerateContentResponse) e
 	if candidate.FinishReason != "" {
 		s.finishReason = candidate.FinishReason
 	}
+	if candidate.GroundingMetadata != nil {
+		s.grounding = candidate.GroundingMetadata
+	}
 	if candidate.Content == nil {
 		return nil
 	}
@@ -170,6 +186,8 @@ func (s *StreamIterator) processChunk(response *genai.GenerateContentResponse) e
 			if err := s.queueFunctionCall(part); err != nil {
 				return err
 			}
+		case part.InlineData != nil && len(part.InlineData.Data) > 0 && !part.Thought:
+			s.queueFile(part.InlineData)
 		case part.Text != "":
 			if part.Thought {
 				// Thought-summary parts are not surfaced (matching the
@@ -189,6 +207,7 @@ func (s *StreamIterator) queueText(text string) {
 		index := s.nextBlockIndex
 		s.nextBlockIndex++
 		s.textBlockIndex = index
+		s.lastTextIndex = index
 		s.eventQueue = append(s.eventQueue, &llm.Event{
 			Type:  llm.EventTypeContentBlockStart,
 			Index: &index,
@@ -222,6 +241,7 @@ func (s *StreamIterator) queueFunctionCall(part *genai.Part) error {
 
 	// Close any open text block first
 	s.closeTextBlock()
+	s.sawToolCall = true
 
 	// Gemini does not always populate FunctionCall.ID; synthesize a unique ID
 	// when missing (matching convertGoogleResponse).
@@ -259,6 +279,29 @@ func (s *StreamIterator) queueFunctionCall(part *genai.Part) error {
 	return nil
 }
 
+// queueFile emits a complete file content block for inline data generated by
+// the model, such as a chart produced by code execution.
+func (s *StreamIterator) queueFile(blob *genai.Blob) {
+	s.closeTextBlock()
+	index := s.nextBlockIndex
+	s.nextBlockIndex++
+	s.eventQueue = append(s.eventQueue,
+		&llm.Event{
+			Type:  llm.EventTypeContentBlockStart,
+			Index: &index,
+			ContentBlock: &llm.EventContentBlock{
+				Type:     llm.ContentTypeFile,
+				Filename: blob.DisplayName,
+				Source:   convertBlobToSource(blob),
+			},
+		},
+		&llm.Event{
+			Type:  llm.EventTypeContentBlockStop,
+			Index: &index,
+		},
+	)
+}
+
 // closeTextBlock emits a content_block_stop for the open text block, if any.
 func (s *StreamIterator) closeTextBlock() {
 	if s.textBlockIndex < 0 {
@@ -272,6 +315,34 @@ func (s *StreamIterator) closeTextBlock() {
 	})
 }
 
+// queueCitations emits the grounding sources of the response as citation
+// deltas on the last text block. Streamed text parts are merged into one
+// block, so every citation goes on that block.
+func (s *StreamIterator) queueCitations() {
+	if s.lastTextIndex < 0 {
+		return
+	}
+	citations := groundingCitations(s.grounding)
+	ordered := slices.Sorted(maps.Keys(citations))
+	if len(ordered) > 0 && ordered[0] < 0 {
+		// Sources that support no segment go last
+		ordered = append(ordered[1:], ordered[0])
+	}
+	for _, partIndex := range ordered {
+		for _, citation := range citations[partIndex] {
+			index := s.lastTextIndex
+			s.eventQueue = append(s.eventQueue, &llm.Event{
+				Type:  llm.EventTypeContentBlockDelta,
+				Index: &index,
+				Delta: &llm.EventDelta{
+					Type:     llm.EventDeltaTypeCitations,
+					Citation: citation,
+				},
+			})
+		}
+	}
+}
+
 // queueFinalEvents closes any open content block and emits message_delta
 // (carrying usage and the stop reason) followed by message_stop.
 func (s *StreamIterator) queueFinalEvents() {
@@ -279,11 +350,12 @@ func (s *StreamIterator) queueFinalEvents() {
 		// Stream ended without any chunks; nothing to finalize.
 		return
 	}
+	s.queueCitations()
 	s.closeTextBlock()
 
 	delta := &llm.EventDelta{}
 	if s.finishReason != "" {
-		delta.StopReason = convertFinishReason(s.finishReason)
+		delta.StopReason = convertFinishReason(s.finishReason, s.sawToolCall)
 	}
 	event := &llm.Event{
 		Type:  llm.EventTypeMessageDelta,
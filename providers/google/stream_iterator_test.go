@@ -76,13 +76,13 @@ func TestStreamIteratorUsageAndStopReason(t *testing.T) {
 		}
 	}
 	assert.NotNil(t, messageDelta)
-	assert.Equal(t, "stop", messageDelta.Delta.StopReason)
+	assert.Equal(t, "end_turn", messageDelta.Delta.StopReason)
 	assert.NotNil(t, messageDelta.Usage)
 	assert.Equal(t, 100, messageDelta.Usage.InputTokens)
 
 	response := accumulator.Response()
 	assert.Equal(t, "Hello world", response.Message().Text())
-	assert.Equal(t, "stop", response.StopReason)
+	assert.Equal(t, "end_turn", response.StopReason)
 	assert.Equal(t, 100, response.Usage.InputTokens)
 	assert.Equal(t, 25, response.Usage.OutputTokens)
 	assert.Equal(t, 60, response.Usage.CacheReadInputTokens)
@@ -158,7 +158,7 @@ func TestStreamIteratorParallelFunctionCalls(t *testing.T) {
 	// Usage and stop reason still arrive
 	assert.Equal(t, 50, response.Usage.InputTokens)
 	assert.Equal(t, 20, response.Usage.OutputTokens)
-	assert.Equal(t, "stop", response.StopReason)
+	assert.Equal(t, "tool_use", response.StopReason)
 }
 
 func TestStreamIteratorFunctionCallsAcrossChunks(t *testing.T) {
@@ -201,7 +201,7 @@ func TestStreamIteratorFunctionCallsAcrossChunks(t *testing.T) {
 	second, ok := response.Content[1].(*llm.ToolUseContent)
 	assert.True(t, ok)
 	assert.NotEqual(t, first.ID, second.ID)
-	assert.Equal(t, "stop", response.StopReason)
+	assert.Equal(t, "tool_use", response.StopReason)
 	assert.Equal(t, 30, response.Usage.InputTokens)
 	assert.Equal(t, 12, response.Usage.OutputTokens)
 }
@@ -237,3 +237,62 @@ func TestStreamIteratorStreamError(t *testing.T) {
 	}
 	assert.Error(t, iterator.Err())
 }
+
+func TestStreamIteratorInlineDataBecomesFile(t *testing.T) {
+	chunk := textChunk("Here is the chart:")
+	chunk.Candidates[0].Content.Parts = append(chunk.Candidates[0].Content.Parts, &genai.Part{
+		InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("png-bytes")},
+	})
+	chunk.Candidates[0].FinishReason = genai.FinishReasonStop
+
+	iterator := NewStreamIteratorFromSeq(context.Background(),
+		chunkSeq(chunk), "gemini-2.5-pro")
+	defer iterator.Close()
+
+	_, accumulator := collectStreamEvents(t, iterator)
+	response := accumulator.Response()
+	assert.Equal(t, "Here is the chart:", response.Message().Text())
+
+	files := response.Files()
+	assert.Len(t, files, 1)
+	assert.Equal(t, "image/png", files[0].Source.MediaType)
+	data, err := files[0].Source.DecodedData()
+	assert.NoError(t, err)
+	assert.Equal(t, "png-bytes", string(data))
+}
+
+// groundingFixture grounds "Go 1.22" in the text "Go 1.22 shipped in
+// February." on one search result, and lists a second result that no
+// segment cites.
+func groundingFixture() *genai.GroundingMetadata {
+	return &genai.GroundingMetadata{
+		GroundingChunks: []*genai.GroundingChunk{
+			{Web: &genai.GroundingChunkWeb{URI: "https://go.dev/doc/go1.22", Title: "go.dev"}},
+			{Web: &genai.GroundingChunkWeb{URI: "https://example.com/news", Title: "example.com"}},
+		},
+		GroundingSupports: []*genai.GroundingSupport{{
+			Segment:               &genai.Segment{StartIndex: 0, EndIndex: 7, Text: "Go 1.22"},
+			GroundingChunkIndices: []int32{0},
+		}},
+	}
+}
+
+func TestStreamIteratorGroundingCitations(t *testing.T) {
+	final := textChunk(" in February.")
+	final.Candidates[0].FinishReason = genai.FinishReasonStop
+	final.Candidates[0].GroundingMetadata = groundingFixture()
+
+	iterator := NewStreamIteratorFromSeq(context.Background(),
+		chunkSeq(textChunk("Go 1.22 shipped"), final), "gemini-2.5-pro")
+	defer iterator.Close()
+
+	_, accumulator := collectStreamEvents(t, iterator)
+	response := accumulator.Response()
+	citations := response.Citations()
+	assert.Len(t, citations, 2)
+	assert.Equal(t, "https://go.dev/doc/go1.22", citations[0].URL)
+	assert.Equal(t, "Go 1.22", response.Message().Text()[citations[0].StartIndex:citations[0].EndIndex])
+	assert.Equal(t, "https://example.com/news", citations[1].URL)
+	assert.Equal(t, 0, citations[1].StartIndex)
+	assert.Equal(t, len(response.Message().Text()), citations[1].EndIndex)
+}
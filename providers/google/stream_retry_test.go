@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
 	"github.com/deepnoodle-ai/wonton/assert"
 	"github.com/deepnoodle-ai/wonton/retry"
 	"google.golang.org/genai"
@@ -72,7 +73,7 @@ func TestStreamRetriesBeforeFirstEvent(t *testing.T) {
 	accumulator := consumeGoogleProviderStream(t, iterator)
 	assert.True(t, accumulator.IsComplete())
 	assert.Equal(t, "ok", accumulator.Response().Message().Text())
-	assert.Equal(t, "stop", accumulator.Response().StopReason)
+	assert.Equal(t, "end_turn", accumulator.Response().StopReason)
 	assert.Equal(t, int64(2), requests.Load())
 	assert.Equal(t, int64(1), hooks.Load())
 }
@@ -127,3 +128,72 @@ func TestWrapGoogleErrorHandlesValueAndPointerForms(t *testing.T) {
 	assert.True(t, retry.IsPermanent(wrapGoogleError(valueErr)))
 	assert.False(t, retry.IsPermanent(wrapGoogleError(pointerErr)))
 }
+
+func TestWrapGoogleErrorClassifies(t *testing.T) {
+	err := wrapGoogleError(genai.APIError{
+		Code:    http.StatusBadRequest,
+		Message: "API key expired. Please renew the API key.",
+		Status:  "INVALID_ARGUMENT",
+		Details: []map[string]any{{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "API_KEY_INVALID"}},
+	})
+	assert.True(t, errors.Is(err, providers.ErrInvalidAPIKey))
+
+	err = wrapGoogleError(genai.APIError{
+		Code:    http.StatusBadRequest,
+		Message: "The input token count (1200000) exceeds the maximum number of tokens allowed (1048576).",
+		Status:  "INVALID_ARGUMENT",
+	})
+	assert.True(t, errors.Is(err, providers.ErrContextLengthExceeded))
+	var providerErr *providers.ProviderError
+	assert.True(t, errors.As(err, &providerErr))
+	assert.Equal(t, "INVALID_ARGUMENT", providerErr.Code())
+}
+
+func TestStreamStopReleasesBlockedRequest(t *testing.T) {
+	for _, stop := range []string{"close", "cancel"} {
+		t.Run(stop, func(t *testing.T) {
+			disconnected := make(chan struct{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, "data: {\"responseId\":\"resp_1\",\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"o\"}]}}]}\n\n")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+				disconnected <- struct{}{}
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			provider := newRetryTestProvider(t, server.URL, 0)
+			iterator, err := provider.Stream(ctx, llm.WithMessages(llm.NewUserTextMessage("hello")))
+			assert.NoError(t, err)
+			defer iterator.Close()
+			assert.True(t, iterator.Next())
+
+			// Read past the events of the first chunk, until Next blocks
+			next := make(chan bool, 1)
+			go func() {
+				for iterator.Next() {
+				}
+				next <- false
+			}()
+			time.Sleep(20 * time.Millisecond)
+			if stop == "close" {
+				go iterator.Close()
+			} else {
+				cancel()
+			}
+			select {
+			case <-next:
+			case <-time.After(2 * time.Second):
+				t.Fatal("Next did not return after the stream was stopped")
+			}
+			select {
+			case <-disconnected:
+			case <-time.After(2 * time.Second):
+				t.Fatal("the HTTP request was not aborted")
+			}
+			assert.True(t, errors.Is(iterator.Err(), context.Canceled))
+		})
+	}
+}
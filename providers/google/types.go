@@ -12,6 +12,9 @@ type Request struct {
 	Temperature *float64         `json:"temperature,omitempty"`
 	System      string           `json:"system,omitempty"`
 	Tools       []map[string]any `json:"tools,omitempty"`
+	// ThinkingBudget caps the model's thinking tokens. See
+	// llm.WithThinkingBudget.
+	ThinkingBudget *int `json:"thinking_budget,omitempty"`
 }
 
 type Tool struct {
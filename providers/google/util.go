@@ -4,6 +4,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"sync/atomic"
 
@@ -32,6 +34,12 @@ func convertGoogleResponse(resp *genai.GenerateContentResponse, model string) (*
 	for _, part := range candidate.Content.Parts {
 		if part.Text != "" {
 			content = append(content, &llm.TextContent{Text: part.Text})
+		} else if part.InlineData != nil && len(part.InlineData.Data) > 0 && !part.Thought {
+			// Files generated by the model, e.g. charts from code execution
+			content = append(content, &llm.FileContent{
+				Filename: part.InlineData.DisplayName,
+				Source:   convertBlobToSource(part.InlineData),
+			})
 		} else if part.FunctionCall != nil {
 			// Handle function calls - convert args to JSON
 			args, err := json.Marshal(part.FunctionCall.Args)
@@ -57,6 +65,25 @@ func convertGoogleResponse(resp *genai.GenerateContentResponse, model string) (*
 		}
 	}
 
+	// Attach grounding sources to the text parts they support. Sources
+	// that support no known text part go on the last text block.
+	citations := groundingCitations(candidate.GroundingMetadata)
+	last := lastTextContent(content)
+	for _, partIndex := range slices.Sorted(maps.Keys(citations)) {
+		text := last
+		if partIndex >= 0 && partIndex < len(content) {
+			if partText, ok := content[partIndex].(*llm.TextContent); ok {
+				text = partText
+			}
+		}
+		if text != nil && partIndex >= 0 {
+			text.Citations = append(text.Citations, citations[partIndex]...)
+		}
+	}
+	if last != nil {
+		last.Citations = append(last.Citations, citations[-1]...)
+	}
+
 	// Convert usage information
 	var usage llm.Usage
 	if resp.UsageMetadata != nil {
@@ -73,11 +100,94 @@ func convertGoogleResponse(resp *genai.GenerateContentResponse, model string) (*
 	}
 
 	// Set stop reason
-	diveResponse.StopReason = convertFinishReason(candidate.FinishReason)
+	diveResponse.StopReason = convertFinishReason(candidate.FinishReason, len(diveResponse.ToolCalls()) > 0)
 
 	return diveResponse, nil
 }
 
+// groundingCitations converts the sources in grounding metadata, such as
+// Google Search results, to citations keyed by the index of the part they
+// support. Each supported segment yields one citation per source, with the
+// segment's byte offsets within the part. Sources that no segment cites are
+// keyed by -1.
+func groundingCitations(metadata *genai.GroundingMetadata) map[int][]llm.Citation {
+	if metadata == nil || len(metadata.GroundingChunks) == 0 {
+		return nil
+	}
+	sources := make([]*llm.CitationContent, len(metadata.GroundingChunks))
+	for i, chunk := range metadata.GroundingChunks {
+		switch {
+		case chunk == nil:
+		case chunk.Web != nil:
+			sources[i] = &llm.CitationContent{
+				Type:  string(llm.CitationTypeContent),
+				Title: chunk.Web.Title,
+				URL:   chunk.Web.URI,
+			}
+		case chunk.RetrievedContext != nil:
+			sources[i] = &llm.CitationContent{
+				Type:     string(llm.CitationTypeContent),
+				Title:    chunk.RetrievedContext.Title,
+				URL:      chunk.RetrievedContext.URI,
+				Snippet:  chunk.RetrievedContext.Text,
+				SourceID: chunk.RetrievedContext.DocumentName,
+			}
+		}
+	}
+
+	citations := map[int][]llm.Citation{}
+	cited := make([]bool, len(sources))
+	for _, support := range metadata.GroundingSupports {
+		if support == nil || support.Segment == nil {
+			continue
+		}
+		segment := support.Segment
+		for _, chunkIndex := range support.GroundingChunkIndices {
+			if int(chunkIndex) >= len(sources) || sources[chunkIndex] == nil {
+				continue
+			}
+			cited[chunkIndex] = true
+			citation := *sources[chunkIndex]
+			citation.Snippet = segment.Text
+			citation.StartIndex = int(segment.StartIndex)
+			citation.EndIndex = int(segment.EndIndex)
+			partIndex := int(segment.PartIndex)
+			citations[partIndex] = append(citations[partIndex], &citation)
+		}
+	}
+	for i, source := range sources {
+		if source != nil && !cited[i] {
+			citations[-1] = append(citations[-1], source)
+		}
+	}
+	return citations
+}
+
+// foldDeveloperMessages appends leading developer messages to the system
+// instruction, since Gemini contents allow only user and model roles. Later
+// developer messages are sent as user messages.
+func foldDeveloperMessages(req *Request, messages []*llm.Message) []*llm.Message {
+	instructions, messages := llm.FoldDeveloperMessages(messages)
+	switch {
+	case instructions == "":
+	case req.System == "":
+		req.System = instructions
+	default:
+		req.System += "\n\n" + instructions
+	}
+	return messages
+}
+
+// lastTextContent returns the last text block in content, or nil.
+func lastTextContent(content []llm.Content) *llm.TextContent {
+	for i := len(content) - 1; i >= 0; i-- {
+		if text, ok := content[i].(*llm.TextContent); ok {
+			return text
+		}
+	}
+	return nil
+}
+
 // convertUsageMetadata converts genai usage metadata to llm.Usage, carrying
 // cached-content and thoughts token counts where the API reports them.
 func convertUsageMetadata(metadata *genai.GenerateContentResponseUsageMetadata) llm.Usage {
@@ -89,16 +199,38 @@ func convertUsageMetadata(metadata *genai.GenerateContentResponseUsageMetadata)
 	}
 }
 
-// convertFinishReason maps a genai finish reason to Dive's stop reason
-// vocabulary for this provider (matching convertGoogleResponse).
-func convertFinishReason(reason genai.FinishReason) string {
+// convertFinishReason maps a genai finish reason to one of the llm.StopReason
+// values. Gemini reports STOP even when the model requested function calls,
+// so hasToolCalls reports tool_use in that case to match other providers.
+func convertFinishReason(reason genai.FinishReason, hasToolCalls bool) string {
 	switch reason {
-	case genai.FinishReasonStop:
-		return "stop"
+	case genai.FinishReasonStop, genai.FinishReasonUnspecified:
+		if hasToolCalls {
+			return llm.StopReasonToolUse
+		}
+		return llm.StopReasonEndTurn
 	case genai.FinishReasonMaxTokens:
-		return "max_tokens"
+		return llm.StopReasonMaxTokens
+	case genai.FinishReasonSafety,
+		genai.FinishReasonRecitation,
+		genai.FinishReasonBlocklist,
+		genai.FinishReasonProhibitedContent,
+		genai.FinishReasonSPII,
+		genai.FinishReasonImageSafety,
+		genai.FinishReasonImageProhibitedContent:
+		return llm.StopReasonContentFilter
 	default:
-		return "other"
+		return string(reason)
+	}
+}
+
+// convertBlobToSource converts inline data returned by Gemini to a base64
+// content source.
+func convertBlobToSource(blob *genai.Blob) *llm.ContentSource {
+	return &llm.ContentSource{
+		Type:      llm.ContentSourceTypeBase64,
+		MediaType: blob.MIMEType,
+		Data:      base64.StdEncoding.EncodeToString(blob.Data),
 	}
 }
 
@@ -338,6 +470,9 @@ func messagesToContents(messages []*llm.Message) ([]*genai.Content, error) {
 				// Gemini has no field for replaying another model's reasoning,
 				// so thinking blocks (e.g. from a session started on Anthropic)
 				// are skipped on encode rather than erroring.
+			case *llm.FileContent:
+				// Model-generated files are response-only and are not
+				// replayed.
 			default:
 				return nil, fmt.Errorf("unsupported content type for google provider: %s", c.Type())
 			}
@@ -421,6 +556,10 @@ func buildGenAIGenerateConfig(request *Request) (*genai.GenerateContentConfig, e
 	if request.MaxTokens > 0 {
 		genConfig.MaxOutputTokens = int32(request.MaxTokens)
 	}
+	if request.ThinkingBudget != nil {
+		budget := int32(*request.ThinkingBudget)
+		genConfig.ThinkingConfig = &genai.ThinkingConfig{ThinkingBudget: &budget}
+	}
 	if request.System != "" {
 		genConfig.SystemInstruction = &genai.Content{
 			Parts: []*genai.Part{genai.NewPartFromText(request.System)},
@@ -139,3 +139,58 @@ func TestGoogleThoughtSignatureSurvivesMessageRoundTrip(t *testing.T) {
 	assert.Len(t, contents, 2)
 	assert.Equal(t, signature, contents[0].Parts[0].ThoughtSignature)
 }
+
+func TestConvertGoogleResponseGroundingCitations(t *testing.T) {
+	response, err := convertGoogleResponse(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: &genai.Content{
+				Role:  "model",
+				Parts: []*genai.Part{{Text: "Go 1.22 shipped in February."}},
+			},
+			GroundingMetadata: groundingFixture(),
+		}},
+	}, "gemini-2.5-pro")
+	assert.NoError(t, err)
+
+	text := response.Content[0].(*llm.TextContent)
+	assert.Len(t, text.Citations, 2)
+	cited := text.Citations[0].(*llm.CitationContent)
+	assert.Equal(t, "go.dev", cited.Title)
+	assert.Equal(t, "Go 1.22", cited.Snippet)
+	assert.Equal(t, "Go 1.22", text.Text[cited.StartIndex:cited.EndIndex])
+	assert.False(t, text.Citations[1].(*llm.CitationContent).HasSpan())
+}
+
+func TestFoldDeveloperMessages(t *testing.T) {
+	req := &Request{System: "Be friendly."}
+	messages := foldDeveloperMessages(req, []*llm.Message{
+		llm.NewDeveloperMessage("Answer in French."),
+		llm.NewUserTextMessage("Hello"),
+		llm.NewDeveloperMessage("Keep it short."),
+	})
+	assert.Equal(t, "Be friendly.\n\nAnswer in French.", req.System)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, llm.User, messages[1].Role)
+
+	contents, err := messagesToContents(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", contents[1].Role)
+}
+
+func TestBuildGenAIGenerateConfigThinkingBudget(t *testing.T) {
+	var request Request
+	assert.NoError(t, New().applyRequestConfig(&request, &llm.Config{
+		Model:           ModelGemini35Flash,
+		ReasoningBudget: dive.Ptr(2048),
+	}))
+	generateConfig, err := buildGenAIGenerateConfig(&request)
+	assert.NoError(t, err)
+	assert.NotNil(t, generateConfig.ThinkingConfig)
+	assert.Equal(t, int32(2048), *generateConfig.ThinkingConfig.ThinkingBudget)
+
+	request = Request{}
+	assert.NoError(t, New().applyRequestConfig(&request, &llm.Config{Model: ModelGemini35Flash}))
+	generateConfig, err = buildGenAIGenerateConfig(&request)
+	assert.NoError(t, err)
+	assert.Nil(t, generateConfig.ThinkingConfig)
+}
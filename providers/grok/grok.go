@@ -45,6 +45,9 @@ func New(opts ...Option) *Provider {
 		openaiProvider.WithMaxRetries(cfg.maxRetries),
 		openaiProvider.WithRetryBaseWait(cfg.retryBaseWait),
 	}
+	if cfg.httpClient != nil {
+		openaiOpts = append(openaiOpts, openaiProvider.WithClient(cfg.httpClient))
+	}
 	if len(cfg.extraRequestOptions) > 0 {
 		openaiOpts = append(openaiOpts,
 			openaiProvider.WithExtraRequestOptions(cfg.extraRequestOptions...))
@@ -1,8 +1,10 @@
 package grok
 
 import (
+	"net/http"
 	"time"
 
+	"github.com/deepnoodle-ai/dive/providers"
 	"github.com/openai/openai-go/v3/option"
 )
 
@@ -15,6 +17,7 @@ type config struct {
 	maxTokens           int
 	maxRetries          int
 	retryBaseWait       time.Duration
+	httpClient          *http.Client
 	extraRequestOptions []option.RequestOption
 }
 
@@ -73,3 +76,22 @@ func WithPromptCacheKey(key string) Option {
 			option.WithJSONSet("prompt_cache_key", key))
 	}
 }
+
+// WithClient sets the HTTP client used for all API requests.
+func WithClient(client *http.Client) Option {
+	return func(c *config) {
+		c.httpClient = client
+	}
+}
+
+// WithTransport sets the HTTP transport used for all API requests, via a
+// client with no overall timeout. See providers.NewTransportClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return WithClient(providers.NewTransportClient(transport))
+}
+
+// WithStreamingTransport uses a transport tuned for long streaming responses,
+// such as extended reasoning. See providers.NewStreamingTransport.
+func WithStreamingTransport() Option {
+	return WithTransport(providers.NewStreamingTransport())
+}
@@ -7,9 +7,11 @@ import (
 
 func init() {
 	providers.Register(providers.ProviderEntry{
-		Name:    "grok",
-		Match:   providers.PrefixMatcher("grok-"),
-		Factory: factory,
+		Name:             "grok",
+		Match:            providers.PrefixMatcher("grok-"),
+		Factory:          factory,
+		APIKeyEnv:        []string{"XAI_API_KEY", "GROK_API_KEY"},
+		HealthCheckModel: ModelGrok3Mini,
 	})
 }
 
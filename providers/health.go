@@ -0,0 +1,181 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// HealthStatus classifies the outcome of a provider health check.
+type HealthStatus string
+
+const (
+	// HealthOK means the provider accepted the request.
+	HealthOK HealthStatus = "ok"
+
+	// HealthAuthFailure means the credentials were missing, invalid, or not
+	// allowed to use the model, or the account is out of quota.
+	HealthAuthFailure HealthStatus = "auth_failure"
+
+	// HealthRateLimited means the provider is reachable and the credentials
+	// were not rejected, but the request was rate limited.
+	HealthRateLimited HealthStatus = "rate_limited"
+
+	// HealthUnavailable means the provider returned a server error or
+	// reported being overloaded.
+	HealthUnavailable HealthStatus = "unavailable"
+
+	// HealthNetwork means the provider could not be reached.
+	HealthNetwork HealthStatus = "network"
+
+	// HealthError covers other failures, such as a request the provider
+	// rejected for an unknown model.
+	HealthError HealthStatus = "error"
+)
+
+// HealthChecker is implemented by providers that can verify their
+// configuration more cheaply than by generating a response, for example by
+// looking up the model in the provider's model list. HealthCheck uses it
+// when available.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheckError is returned by HealthCheck when a provider fails its
+// check.
+type HealthCheckError struct {
+	Provider string
+	Status   HealthStatus
+	Err      error
+}
+
+func (e *HealthCheckError) Error() string {
+	return fmt.Sprintf("%s health check failed (%s): %v", e.Provider, e.Status, e.Err)
+}
+
+func (e *HealthCheckError) Unwrap() error {
+	return e.Err
+}
+
+// HealthCheck verifies that a provider's credentials work and that it can be
+// reached. It uses the provider's HealthChecker implementation if it has
+// one, and otherwise asks for a reply of at most 16 tokens (the smallest
+// limit some providers accept) to a one-word prompt, so the probe costs a
+// handful of tokens. It returns nil when the provider is healthy and a
+// *HealthCheckError otherwise; use HealthStatusOf to classify it. Bound
+// the check with a context deadline, since providers retry rate limits
+// and server errors before giving up.
+func HealthCheck(ctx context.Context, model llm.LLM) error {
+	var err error
+	if checker, ok := model.(HealthChecker); ok {
+		err = checker.HealthCheck(ctx)
+	} else {
+		_, err = model.Generate(ctx,
+			llm.WithUserTextMessage("ping"),
+			llm.WithMaxTokens(16),
+		)
+	}
+	if err == nil {
+		return nil
+	}
+	return &HealthCheckError{
+		Provider: model.Name(),
+		Status:   classifyHealthError(err),
+		Err:      err,
+	}
+}
+
+// HealthStatusOf returns the status of an error returned by HealthCheck.
+// A nil error is HealthOK.
+func HealthStatusOf(err error) HealthStatus {
+	if err == nil {
+		return HealthOK
+	}
+	var healthErr *HealthCheckError
+	if errors.As(err, &healthErr) {
+		return healthErr.Status
+	}
+	return classifyHealthError(err)
+}
+
+func classifyHealthError(err error) HealthStatus {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		switch code := providerErr.StatusCode(); {
+		case code == http.StatusUnauthorized || code == http.StatusForbidden ||
+			errors.Is(err, ErrInvalidAPIKey) || errors.Is(err, ErrQuotaExceeded):
+			return HealthAuthFailure
+		case code == http.StatusTooManyRequests || errors.Is(err, ErrRateLimited):
+			return HealthRateLimited
+		case code >= 500:
+			return HealthUnavailable
+		}
+		return HealthError
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return HealthNetwork
+	}
+	return HealthError
+}
+
+// HealthResult is the outcome of checking one provider in HealthCheckAll.
+type HealthResult struct {
+	Provider string
+	Model    string
+	Status   HealthStatus
+	Err      error
+}
+
+// HealthCheckAll checks every registered provider whose API key is set,
+// concurrently, using each entry's HealthCheckModel. Providers without an
+// API key environment variable, such as local servers, and entries without
+// a HealthCheckModel are skipped. Results are in registration order.
+func (r *Registry) HealthCheckAll(ctx context.Context) []HealthResult {
+	r.mu.RLock()
+	var entries []ProviderEntry
+	for _, entry := range r.entries {
+		if entry.HealthCheckModel != "" && hasAPIKey(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]HealthResult, len(entries))
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := HealthCheck(ctx, entry.Factory(entry.HealthCheckModel, ""))
+			results[i] = HealthResult{
+				Provider: entry.Name,
+				Model:    entry.HealthCheckModel,
+				Status:   HealthStatusOf(err),
+				Err:      err,
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func hasAPIKey(entry ProviderEntry) bool {
+	for _, envVar := range entry.APIKeyEnv {
+		if os.Getenv(envVar) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HealthCheckAll checks every configured provider in the default registry.
+func HealthCheckAll(ctx context.Context) []HealthResult {
+	return defaultRegistry.HealthCheckAll(ctx)
+}
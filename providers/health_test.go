@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// healthLLM fails Generate with err and records the max tokens it was given.
+type healthLLM struct {
+	err       error
+	maxTokens int
+}
+
+func (h *healthLLM) Name() string {
+	return "health"
+}
+
+func (h *healthLLM) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	config := &llm.Config{}
+	config.Apply(opts...)
+	if config.MaxTokens != nil {
+		h.maxTokens = *config.MaxTokens
+	}
+	return &llm.Response{}, h.err
+}
+
+type checkerLLM struct {
+	healthLLM
+	checked bool
+}
+
+func (c *checkerLLM) HealthCheck(ctx context.Context) error {
+	c.checked = true
+	return nil
+}
+
+func TestHealthCheck(t *testing.T) {
+	model := &healthLLM{}
+	assert.NoError(t, HealthCheck(context.Background(), model))
+	assert.Equal(t, 16, model.maxTokens)
+
+	tests := []struct {
+		err    error
+		status HealthStatus
+	}{
+		{NewError(401, "invalid x-api-key"), HealthAuthFailure},
+		{NewError(403, "forbidden"), HealthAuthFailure},
+		{fmt.Errorf("wrapped: %w", NewError(429, "slow down")), HealthRateLimited},
+		{NewError(429, `{"error":{"type":"insufficient_quota","code":"insufficient_quota"}}`), HealthAuthFailure},
+		{NewError(529, "overloaded"), HealthUnavailable},
+		{NewError(404, "model not found"), HealthError},
+		{fmt.Errorf("error making request: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), HealthNetwork},
+		{context.DeadlineExceeded, HealthNetwork},
+		{errors.New("boom"), HealthError},
+	}
+	for _, tt := range tests {
+		err := HealthCheck(context.Background(), &healthLLM{err: tt.err})
+		var healthErr *HealthCheckError
+		assert.True(t, errors.As(err, &healthErr))
+		assert.Equal(t, "health", healthErr.Provider)
+		assert.Equal(t, tt.status, HealthStatusOf(err), tt.err.Error())
+		assert.True(t, errors.Is(err, tt.err))
+	}
+}
+
+func TestHealthCheckUsesHealthChecker(t *testing.T) {
+	model := &checkerLLM{}
+	assert.NoError(t, HealthCheck(context.Background(), model))
+	assert.True(t, model.checked)
+	assert.Equal(t, 0, model.maxTokens)
+}
+
+func TestRegistryHealthCheckAll(t *testing.T) {
+	t.Setenv("HEALTH_GOOD_KEY", "k")
+	t.Setenv("HEALTH_BAD_KEY", "k")
+	t.Setenv("HEALTH_UNSET_KEY", "")
+
+	r := &Registry{}
+	entry := func(name string, err error) ProviderEntry {
+		return ProviderEntry{
+			Name:             name,
+			Match:            PrefixMatcher(name),
+			Factory:          func(model, endpoint string) llm.LLM { return &healthLLM{err: err} },
+			APIKeyEnv:        []string{"HEALTH_" + name + "_KEY"},
+			HealthCheckModel: name + "-small",
+		}
+	}
+	r.Register(entry("GOOD", nil))
+	r.Register(entry("BAD", NewError(401, "invalid key")))
+	r.Register(entry("UNSET", nil))
+	r.Register(ProviderEntry{Name: "local", Match: PrefixMatcher("local"),
+		Factory: func(model, endpoint string) llm.LLM { return &healthLLM{} }})
+
+	results := r.HealthCheckAll(context.Background())
+	assert.Len(t, results, 2)
+	assert.Equal(t, "GOOD", results[0].Provider)
+	assert.Equal(t, "GOOD-small", results[0].Model)
+	assert.Equal(t, HealthOK, results[0].Status)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "BAD", results[1].Provider)
+	assert.Equal(t, HealthAuthFailure, results[1].Status)
+	assert.Error(t, results[1].Err)
+}
@@ -0,0 +1,342 @@
+// Package metrics records Prometheus metrics for LLM requests.
+//
+// Wrap any llm.LLM with WithMetrics to count its requests, time them, and
+// count the tokens they use and the errors they return:
+//
+//	model, err := metrics.WithMetrics(anthropic.New(), prometheus.DefaultRegisterer)
+//
+// Every metric is labeled with the provider (the model's Name) and the model
+// name from the request or response:
+//
+//   - dive_llm_requests_total counts requests, labeled status "success" or
+//     "error".
+//   - dive_llm_request_duration_seconds is a histogram of request latency.
+//     For streams it runs until the stream ends.
+//   - dive_llm_tokens_total counts tokens, labeled type "input", "output",
+//     "cache_read", or "cache_write".
+//   - dive_llm_errors_total counts failed requests, labeled with an
+//     error_type derived from the shared provider error types: one of the
+//     ErrorType constants, such as "rate_limit" or "auth".
+//
+// The package is a separate module so that the root module does not depend
+// on the Prometheus client.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Error types used as the error_type label of dive_llm_errors_total. The set
+// is small so the label stays low-cardinality.
+const (
+	ErrorTypeRateLimit       = "rate_limit"
+	ErrorTypeAuth            = "auth"
+	ErrorTypeInvalidRequest  = "invalid_request"
+	ErrorTypeNotFound        = "not_found"
+	ErrorTypeRequestTooLarge = "request_too_large"
+	ErrorTypeOverloaded      = "overloaded"
+	ErrorTypeServer          = "server"
+	ErrorTypeTimeout         = "timeout"
+	ErrorTypeCanceled        = "canceled"
+	ErrorTypeNetwork         = "network"
+	ErrorTypeOther           = "other"
+)
+
+// Collector holds the Prometheus metrics that wrapped models record. One
+// Collector can wrap any number of models.
+type Collector struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	tokens   *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+}
+
+// NewCollector creates the metrics and registers them with registerer, or
+// with prometheus.DefaultRegisterer if it is nil. Metrics that are already
+// registered, for example by an earlier NewCollector call with the same
+// registerer, are reused.
+func NewCollector(registerer prometheus.Registerer) (*Collector, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	c := &Collector{}
+	var err error
+	c.requests, err = register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dive_llm_requests_total",
+		Help: "Number of LLM requests, by provider, model, and status.",
+	}, []string{"provider", "model", "status"}))
+	if err != nil {
+		return nil, err
+	}
+	c.duration, err = register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dive_llm_request_duration_seconds",
+		Help:    "Latency of LLM requests in seconds, by provider and model.",
+		Buckets: []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 80, 160, 320},
+	}, []string{"provider", "model"}))
+	if err != nil {
+		return nil, err
+	}
+	c.tokens, err = register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dive_llm_tokens_total",
+		Help: "Number of tokens used by LLM requests, by provider, model, and token type.",
+	}, []string{"provider", "model", "type"}))
+	if err != nil {
+		return nil, err
+	}
+	c.errors, err = register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dive_llm_errors_total",
+		Help: "Number of failed LLM requests, by provider, model, and error type.",
+	}, []string{"provider", "model", "error_type"}))
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// register registers collector, returning the existing collector instead
+// if an identical one is already registered.
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	if err := registerer.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return collector, err
+	}
+	return collector, nil
+}
+
+// WithMetrics returns model wrapped to record metrics registered with
+// registerer, or with prometheus.DefaultRegisterer if it is nil. It may be
+// called for several models with the same registerer. It is shorthand for
+// NewCollector followed by Collector.Wrap.
+func WithMetrics(model llm.LLM, registerer prometheus.Registerer) (llm.LLM, error) {
+	c, err := NewCollector(registerer)
+	if err != nil {
+		return nil, err
+	}
+	return c.Wrap(model), nil
+}
+
+// Wrap returns an LLM that records metrics for each request to model. Like
+// llm.WithMiddleware, the returned LLM streams if model does and forwards
+// ModelInfoProvider, ToolLimiter, ResponseContinuer, and TokenCounter.
+func (c *Collector) Wrap(model llm.LLM) llm.LLM {
+	m := &metricsLLM{llm: model, collector: c}
+	if _, ok := model.(llm.StreamingLLM); ok {
+		return &streamingMetricsLLM{m}
+	}
+	return m
+}
+
+// observe records one finished request.
+func (c *Collector) observe(provider, model string, elapsed time.Duration, usage *llm.Usage, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+		c.errors.WithLabelValues(provider, model, ErrorType(err)).Inc()
+	}
+	c.requests.WithLabelValues(provider, model, status).Inc()
+	c.duration.WithLabelValues(provider, model).Observe(elapsed.Seconds())
+	if usage == nil {
+		return
+	}
+	for tokenType, n := range map[string]int{
+		"input":       usage.InputTokens,
+		"output":      usage.OutputTokens,
+		"cache_read":  usage.CacheReadInputTokens,
+		"cache_write": usage.CacheCreationInputTokens,
+	} {
+		if n > 0 {
+			c.tokens.WithLabelValues(provider, model, tokenType).Add(float64(n))
+		}
+	}
+}
+
+// ErrorType classifies err as one of the ErrorType constants. The kind and
+// then the status code of a *providers.ProviderError decide first; then
+// context errors, llm.ErrRequestTooLarge, and network errors. It returns ""
+// for nil.
+func ErrorType(err error) string {
+	if err == nil {
+		return ""
+	}
+	var providerErr *providers.ProviderError
+	if errors.As(err, &providerErr) {
+		switch code := providerErr.StatusCode(); {
+		case errors.Is(err, providers.ErrRateLimited):
+			return ErrorTypeRateLimit
+		case errors.Is(err, providers.ErrInvalidAPIKey), errors.Is(err, providers.ErrQuotaExceeded):
+			return ErrorTypeAuth
+		case errors.Is(err, providers.ErrModelNotFound):
+			return ErrorTypeNotFound
+		case errors.Is(err, providers.ErrContextLengthExceeded):
+			return ErrorTypeRequestTooLarge
+		case code == http.StatusTooManyRequests:
+			return ErrorTypeRateLimit
+		case code == http.StatusUnauthorized || code == http.StatusForbidden:
+			return ErrorTypeAuth
+		case code == http.StatusNotFound:
+			return ErrorTypeNotFound
+		case code == http.StatusRequestEntityTooLarge:
+			return ErrorTypeRequestTooLarge
+		case code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout:
+			return ErrorTypeTimeout
+		case code == 529 || code == http.StatusServiceUnavailable:
+			return ErrorTypeOverloaded
+		case code >= 500:
+			return ErrorTypeServer
+		case code >= 400:
+			return ErrorTypeInvalidRequest
+		}
+	}
+	switch {
+	case errors.Is(err, llm.ErrRequestTooLarge):
+		return ErrorTypeRequestTooLarge
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTypeTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorTypeCanceled
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorTypeTimeout
+		}
+		return ErrorTypeNetwork
+	}
+	return ErrorTypeOther
+}
+
+type metricsLLM struct {
+	llm       llm.LLM
+	collector *Collector
+}
+
+func (m *metricsLLM) Name() string {
+	return m.llm.Name()
+}
+
+func (m *metricsLLM) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	model := m.modelName(opts)
+	start := time.Now()
+	resp, err := m.llm.Generate(ctx, opts...)
+	var usage *llm.Usage
+	if resp != nil {
+		usage = &resp.Usage
+		if resp.Model != "" {
+			model = resp.Model
+		}
+	}
+	m.collector.observe(m.llm.Name(), model, time.Since(start), usage, err)
+	return resp, err
+}
+
+// modelName returns the model the request names, or the provider's
+// configured model.
+func (m *metricsLLM) modelName(opts []llm.Option) string {
+	var config llm.Config
+	config.Apply(opts...)
+	if config.Model != "" {
+		return config.Model
+	}
+	if provider, ok := m.llm.(llm.ModelInfoProvider); ok {
+		return provider.ModelInfo("").Model
+	}
+	return ""
+}
+
+func (m *metricsLLM) ModelInfo(model string) llm.ModelInfo {
+	if provider, ok := m.llm.(llm.ModelInfoProvider); ok {
+		return provider.ModelInfo(model)
+	}
+	return llm.ModelInfo{}
+}
+
+func (m *metricsLLM) ToolLimits() llm.ToolLimits {
+	if limiter, ok := m.llm.(llm.ToolLimiter); ok {
+		return limiter.ToolLimits()
+	}
+	return llm.ToolLimits{}
+}
+
+func (m *metricsLLM) ContinuesResponses(config *llm.Config) bool {
+	if continuer, ok := m.llm.(llm.ResponseContinuer); ok {
+		return continuer.ContinuesResponses(config)
+	}
+	return false
+}
+
+func (m *metricsLLM) CountTokens(ctx context.Context, model string, messages []*llm.Message, tools []llm.Tool) (int, error) {
+	return llm.CountTokens(ctx, m.llm, model, messages, tools)
+}
+
+type streamingMetricsLLM struct {
+	*metricsLLM
+}
+
+func (m *streamingMetricsLLM) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIterator, error) {
+	model := m.modelName(opts)
+	start := time.Now()
+	stream, err := m.llm.(llm.StreamingLLM).Stream(ctx, opts...)
+	if err != nil {
+		m.collector.observe(m.llm.Name(), model, time.Since(start), nil, err)
+		return nil, err
+	}
+	return &metricsStream{StreamIterator: stream, llm: m.metricsLLM, model: model, start: start}, nil
+}
+
+// metricsStream records a request's metrics when its stream ends, either
+// by running out of events or by being closed early.
+type metricsStream struct {
+	llm.StreamIterator
+	llm   *metricsLLM
+	model string
+	start time.Time
+	usage *llm.Usage
+	once  sync.Once
+}
+
+func (s *metricsStream) Next() bool {
+	if !s.StreamIterator.Next() {
+		s.finish(s.StreamIterator.Err())
+		return false
+	}
+	event := s.StreamIterator.Event()
+	if event == nil {
+		return true
+	}
+	if event.Type == llm.EventTypeMessageStart && event.Message != nil {
+		// Copy before the caller's accumulator adds to it
+		s.usage = event.Message.Usage.Copy()
+		if event.Message.Model != "" {
+			s.model = event.Message.Model
+		}
+	} else if event.Usage != nil && s.usage != nil {
+		s.usage.Add(event.Usage)
+	}
+	return true
+}
+
+func (s *metricsStream) Close() error {
+	err := s.StreamIterator.Close()
+	s.finish(s.StreamIterator.Err())
+	return err
+}
+
+func (s *metricsStream) finish(err error) {
+	s.once.Do(func() {
+		s.llm.collector.observe(s.llm.llm.Name(), s.model, time.Since(s.start), s.usage, err)
+	})
+}
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeLLM struct {
+	resp   *llm.Response
+	err    error
+	events []*llm.Event
+}
+
+func (f *fakeLLM) Name() string { return "fake" }
+
+func (f *fakeLLM) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	return f.resp, f.err
+}
+
+func (f *fakeLLM) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIterator, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sliceStream{events: f.events, index: -1}, nil
+}
+
+type sliceStream struct {
+	events []*llm.Event
+	index  int
+}
+
+func (s *sliceStream) Next() bool {
+	s.index++
+	return s.index < len(s.events)
+}
+
+func (s *sliceStream) Event() *llm.Event { return s.events[s.index] }
+func (s *sliceStream) Err() error        { return nil }
+func (s *sliceStream) Close() error      { return nil }
+
+func TestWithMetrics_Generate(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	model, err := WithMetrics(&fakeLLM{resp: &llm.Response{
+		Model: "fake-large",
+		Usage: llm.Usage{InputTokens: 100, OutputTokens: 20, CacheReadInputTokens: 30},
+	}}, registry)
+	assert.NoError(t, err)
+
+	_, err = model.Generate(context.Background(), llm.WithModel("fake-large"))
+	assert.NoError(t, err)
+	_, err = model.Generate(context.Background(), llm.WithModel("fake-large"))
+	assert.NoError(t, err)
+
+	c, err := NewCollector(registry)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.requests.WithLabelValues("fake", "fake-large", "success")))
+	assert.Equal(t, 200.0, testutil.ToFloat64(c.tokens.WithLabelValues("fake", "fake-large", "input")))
+	assert.Equal(t, 40.0, testutil.ToFloat64(c.tokens.WithLabelValues("fake", "fake-large", "output")))
+	assert.Equal(t, 60.0, testutil.ToFloat64(c.tokens.WithLabelValues("fake", "fake-large", "cache_read")))
+	assert.Equal(t, 1, testutil.CollectAndCount(c.duration))
+}
+
+func TestWithMetrics_Errors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	c, err := NewCollector(registry)
+	assert.NoError(t, err)
+
+	rateLimited := c.Wrap(&fakeLLM{err: fmt.Errorf("generate: %w", providers.NewError(429, "slow down"))})
+	_, err = rateLimited.Generate(context.Background(), llm.WithModel("m"))
+	assert.Error(t, err)
+
+	unauthorized := c.Wrap(&fakeLLM{err: providers.NewError(401, "bad key")})
+	_, err = unauthorized.Generate(context.Background(), llm.WithModel("m"))
+	assert.Error(t, err)
+	_, err = unauthorized.(llm.StreamingLLM).Stream(context.Background(), llm.WithModel("m"))
+	assert.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.errors.WithLabelValues("fake", "m", ErrorTypeRateLimit)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.errors.WithLabelValues("fake", "m", ErrorTypeAuth)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(c.requests.WithLabelValues("fake", "m", "error")))
+}
+
+func TestWithMetrics_Stream(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	c, err := NewCollector(registry)
+	assert.NoError(t, err)
+
+	index := 0
+	model := c.Wrap(&fakeLLM{events: []*llm.Event{
+		{Type: llm.EventTypeMessageStart, Message: &llm.Response{Model: "fake-small", Usage: llm.Usage{InputTokens: 50}}},
+		{Type: llm.EventTypeContentBlockStart, Index: &index, ContentBlock: &llm.EventContentBlock{Type: llm.ContentTypeText}},
+		{Type: llm.EventTypeContentBlockDelta, Index: &index, Delta: &llm.EventDelta{Type: llm.EventDeltaTypeText, Text: "hi"}},
+		{Type: llm.EventTypeContentBlockStop, Index: &index},
+		{Type: llm.EventTypeMessageDelta, Delta: &llm.EventDelta{}, Usage: &llm.Usage{OutputTokens: 7}},
+		{Type: llm.EventTypeMessageStop},
+	}})
+
+	stream, err := model.(llm.StreamingLLM).Stream(context.Background())
+	assert.NoError(t, err)
+	accumulator := llm.NewResponseAccumulator()
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Close())
+
+	assert.Equal(t, "hi", accumulator.Response().Message().Text())
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.requests.WithLabelValues("fake", "fake-small", "success")))
+	assert.Equal(t, 50.0, testutil.ToFloat64(c.tokens.WithLabelValues("fake", "fake-small", "input")))
+	assert.Equal(t, 7.0, testutil.ToFloat64(c.tokens.WithLabelValues("fake", "fake-small", "output")))
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{providers.NewError(429, ""), ErrorTypeRateLimit},
+		{providers.NewError(403, ""), ErrorTypeAuth},
+		{providers.NewError(400, ""), ErrorTypeInvalidRequest},
+		{providers.NewError(404, ""), ErrorTypeNotFound},
+		{providers.NewError(413, ""), ErrorTypeRequestTooLarge},
+		{providers.NewError(529, ""), ErrorTypeOverloaded},
+		{providers.NewError(500, ""), ErrorTypeServer},
+		{providers.NewError(504, ""), ErrorTypeTimeout},
+		{&llm.RequestTooLargeError{}, ErrorTypeRequestTooLarge},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), ErrorTypeTimeout},
+		{context.Canceled, ErrorTypeCanceled},
+		{errors.New("boom"), ErrorTypeOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ErrorType(tt.err), "%v", tt.err)
+	}
+}
@@ -7,9 +7,11 @@ import (
 
 func init() {
 	providers.Register(providers.ProviderEntry{
-		Name:    "mistral",
-		Match:   providers.PrefixesMatcher("mistral-", "ministral-", "codestral-", "devstral-"),
-		Factory: factory,
+		Name:             "mistral",
+		Match:            providers.PrefixesMatcher("mistral-", "ministral-", "codestral-", "devstral-"),
+		Factory:          factory,
+		APIKeyEnv:        []string{"MISTRAL_API_KEY"},
+		HealthCheckModel: ModelMistralSmall,
 	})
 }
 
@@ -31,6 +31,18 @@ const (
 	ModelGemma2_2B  = "gemma2:2b"
 	ModelGemma2_9B  = "gemma2:9b"
 	ModelGemma2_27B = "gemma2:27b"
+	ModelGemma3_1B  = "gemma3:1b"
+	ModelGemma3_4B  = "gemma3:4b"
+	ModelGemma3_12B = "gemma3:12b"
+	ModelGemma3_27B = "gemma3:27b"
+
+	// Vision models, which accept images as well as text. Gemma 3 models
+	// from 4B up accept images too.
+	ModelLlava_7B          = "llava:7b"
+	ModelLlava_13B         = "llava:13b"
+	ModelLlama32Vision_11B = "llama3.2-vision:11b"
+	ModelMoondream         = "moondream"
+	ModelQwen25VL_7B       = "qwen2.5vl:7b"
 
 	// Qwen models
 	ModelQwen_0_5B = "qwen:0.5b"
@@ -1,6 +1,7 @@
 package ollama
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -31,6 +32,7 @@ type Provider struct {
 	maxRetries    int
 	retryBaseWait time.Duration
 	client        *http.Client
+	vision        *bool
 
 	// Embedded Anthropic provider
 	*anthropic.Provider
@@ -76,3 +78,21 @@ func getAPIKey() string {
 func (p *Provider) Name() string {
 	return fmt.Sprintf("ollama-%s", p.model)
 }
+
+// Generate sends the request to Ollama, first checking that any images are
+// base64 data sent to a model that accepts them.
+func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	if err := p.checkImages(opts); err != nil {
+		return nil, err
+	}
+	return p.Provider.Generate(ctx, opts...)
+}
+
+// Stream streams the response from Ollama, first checking images as
+// Generate does.
+func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIterator, error) {
+	if err := p.checkImages(opts); err != nil {
+		return nil, err
+	}
+	return p.Provider.Stream(ctx, opts...)
+}
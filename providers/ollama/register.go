@@ -6,7 +6,7 @@ import (
 )
 
 func init() {
-	// Register for llama/mixtral/gemma models
+	// Register for llama/mixtral/gemma models and local vision models
 	providers.Register(providers.ProviderEntry{
 		Name: "ollama",
 		Match: providers.PrefixesMatcher(
@@ -17,6 +17,9 @@ func init() {
 			"qwen",
 			"phi",
 			"deepseek",
+			"llava", "bakllava",
+			"moondream",
+			"minicpm-v",
 		),
 		Factory: factory,
 	})
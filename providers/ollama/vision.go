@@ -0,0 +1,86 @@
+package ollama
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+// visionModelPrefixes are the Ollama library models that accept images.
+var visionModelPrefixes = []string{
+	"llava", // llava, llava-llama3, llava-phi3
+	"bakllava",
+	"moondream",
+	"minicpm-v",
+	"llama3.2-vision",
+	"llama4",
+	"gemma3",
+	"qwen2.5vl",
+	"qwen3-vl",
+	"granite3.2-vision",
+	"mistral-small3.1",
+	"mistral-small3.2",
+}
+
+// textOnlyVisionTags are sizes of vision model families that only accept
+// text.
+var textOnlyVisionTags = []string{"gemma3:1b", "gemma3:270m"}
+
+// SupportsImages reports whether an Ollama library model accepts image
+// input. Models pulled under another name, such as from Hugging Face, are
+// unknown and report false; enable images for them with WithVision.
+func SupportsImages(model string) bool {
+	model = strings.ToLower(model)
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	for _, tag := range textOnlyVisionTags {
+		if strings.HasPrefix(model, tag) {
+			return false
+		}
+	}
+	for _, prefix := range visionModelPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkImages returns an error if a request sends images the model can't
+// see. Without this check, Ollama silently drops images sent to text-only
+// models, and the model answers as if it had seen one.
+func (p *Provider) checkImages(opts []llm.Option) error {
+	var config llm.Config
+	config.Apply(opts...)
+	model := config.Model
+	if model == "" {
+		model = p.model
+	}
+	for _, message := range config.Messages {
+		for _, content := range message.Content {
+			image, ok := content.(*llm.ImageContent)
+			if !ok {
+				continue
+			}
+			if image.Source != nil && image.Source.Type != llm.ContentSourceTypeBase64 {
+				return fmt.Errorf("ollama: %s image sources are not supported, send images as base64 data: %w",
+					image.Source.Type, llm.ErrNotSupported)
+			}
+			if !p.supportsImages(model) {
+				return fmt.Errorf("%w: ollama model %s doesn't accept images; use a vision model such as %s or %s, or WithVision(true) if it does",
+					providers.ErrUnsupportedCapability, model, ModelLlava_7B, ModelGemma3_4B)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Provider) supportsImages(model string) bool {
+	if p.vision != nil {
+		return *p.vision
+	}
+	return SupportsImages(model)
+}
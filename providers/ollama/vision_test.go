@@ -0,0 +1,87 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestSupportsImages(t *testing.T) {
+	for model, want := range map[string]bool{
+		ModelLlava_7B:          true,
+		"llava-llama3:8b":      true,
+		ModelLlama32Vision_11B: true,
+		ModelGemma3_4B:         true,
+		ModelGemma3_1B:         false,
+		ModelMoondream:         true,
+		"library/qwen2.5vl":    true,
+		ModelLlama32_3B:        false,
+		ModelMistral_7B:        false,
+	} {
+		assert.Equal(t, want, SupportsImages(model), model)
+	}
+}
+
+func TestGenerateWithImage(t *testing.T) {
+	var request struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Content []map[string]any `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"msg_1","type":"message","role":"assistant","model":"llava:7b",
+			"content":[{"type":"text","text":"A cat."}],"stop_reason":"end_turn",
+			"usage":{"input_tokens":10,"output_tokens":3}}`)
+	}))
+	defer server.Close()
+
+	provider := New(WithEndpoint(server.URL), WithModel(ModelLlava_7B), WithMaxRetries(0))
+	response, err := provider.Generate(context.Background(), llm.WithMessages(llm.NewUserMessage(
+		llm.NewTextContent("What is in this picture?"),
+		llm.NewImageContent(llm.EncodedData("image/png", "cG5n")),
+	)))
+	assert.NoError(t, err)
+	assert.Equal(t, "A cat.", response.Message().Text())
+
+	assert.Equal(t, ModelLlava_7B, request.Model)
+	assert.Len(t, request.Messages, 1)
+	content := request.Messages[0].Content
+	assert.Len(t, content, 2)
+	assert.Equal(t, "text", content[0]["type"])
+	assert.Equal(t, "image", content[1]["type"])
+	assert.Equal(t, map[string]any{"type": "base64", "media_type": "image/png", "data": "cG5n"}, content[1]["source"])
+}
+
+func TestImagesRequireVisionModel(t *testing.T) {
+	image := llm.WithMessages(llm.NewUserMessage(
+		llm.NewTextContent("Describe this."),
+		llm.NewImageContent(llm.EncodedData("image/png", "cG5n")),
+	))
+
+	provider := New(WithEndpoint("http://127.0.0.1:1"), WithModel(ModelLlama32_3B))
+	_, err := provider.Generate(context.Background(), image)
+	assert.True(t, errors.Is(err, providers.ErrUnsupportedCapability))
+	assert.Contains(t, err.Error(), ModelLlama32_3B)
+
+	_, err = provider.Stream(context.Background(), image, llm.WithModel(ModelGemma3_1B))
+	assert.True(t, errors.Is(err, providers.ErrUnsupportedCapability))
+
+	_, err = provider.Generate(context.Background(), llm.WithMessages(llm.NewUserMessage(
+		llm.NewImageContent(&llm.ContentSource{Type: llm.ContentSourceTypeURL, URL: "https://example.com/cat.png"}),
+	)), llm.WithModel(ModelLlava_7B))
+	assert.True(t, errors.Is(err, llm.ErrNotSupported))
+
+	assert.NoError(t, New(WithModel("hf.co/acme/my-vlm"), WithVision(true)).checkImages([]llm.Option{image}))
+	assert.Error(t, New(WithModel(ModelGemma3_4B), WithVision(false)).checkImages([]llm.Option{image}))
+}
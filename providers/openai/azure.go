@@ -0,0 +1,159 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when
+// AzureConfig.APIVersion and AZURE_OPENAI_API_VERSION are unset. The
+// Responses API requires 2025-03-01-preview or later.
+var DefaultAzureAPIVersion = "2025-04-01-preview"
+
+// AzureAPIVersionV1 selects the Azure OpenAI v1 API, served under
+// /openai/v1/ without an api-version query parameter.
+const AzureAPIVersionV1 = "v1"
+
+// AzureTokenScope is the scope of the Microsoft Entra ID (Azure AD) access
+// tokens accepted by Azure OpenAI.
+const AzureTokenScope = "https://cognitiveservices.azure.com/.default"
+
+// AzureTokenSource supplies Microsoft Entra ID (Azure AD) access tokens for
+// Azure OpenAI. Token is called before every request, so implementations
+// should cache tokens until they near expiry, as azidentity credentials do.
+type AzureTokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// AzureTokenSourceFunc adapts a function to an AzureTokenSource. With an
+// azidentity credential:
+//
+//	openai.AzureTokenSourceFunc(func(ctx context.Context) (string, error) {
+//		token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
+//			Scopes: []string{openai.AzureTokenScope},
+//		})
+//		return token.Token, err
+//	})
+type AzureTokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token implements AzureTokenSource.
+func (f AzureTokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// AzureConfig configures a Provider for Azure OpenAI.
+//
+// Azure serves models through deployments: named instances of a model that
+// you create in an Azure OpenAI resource. Requests select a deployment, not
+// a model, so Deployment is the name you gave the deployment, such as
+// "prod-gpt5", which need not match the name of the model it serves, such
+// as "gpt-5". The deployment name is sent wherever the OpenAI API expects a
+// model name, and deployment-scoped routes such as chat completions carry
+// it in the URL, as /openai/deployments/{deployment}/...
+type AzureConfig struct {
+	// Endpoint is the resource endpoint, such as
+	// "https://my-resource.openai.azure.com". Defaults to
+	// AZURE_OPENAI_ENDPOINT.
+	Endpoint string
+
+	// Deployment is the deployment name, used as the model of every
+	// request. Defaults to the model set with WithModel.
+	Deployment string
+
+	// APIVersion is sent as the api-version query parameter. Defaults to
+	// AZURE_OPENAI_API_VERSION, then DefaultAzureAPIVersion. Set it to
+	// AzureAPIVersionV1 to use the v1 API instead.
+	APIVersion string
+
+	// APIKey is sent in the api-key header. Defaults to AZURE_OPENAI_API_KEY
+	// unless TokenSource is set.
+	APIKey string
+
+	// TokenSource authenticates with Microsoft Entra ID (Azure AD) bearer
+	// tokens instead of an API key.
+	TokenSource AzureTokenSource
+}
+
+// WithAzure configures the provider for Azure OpenAI. It replaces the
+// endpoint and authentication set by WithEndpoint and WithAPIKey.
+func WithAzure(config AzureConfig) Option {
+	return func(p *Provider) {
+		if config.Endpoint == "" {
+			config.Endpoint = os.Getenv("AZURE_OPENAI_ENDPOINT")
+		}
+		if config.APIVersion == "" {
+			config.APIVersion = os.Getenv("AZURE_OPENAI_API_VERSION")
+		}
+		if config.APIVersion == "" {
+			config.APIVersion = DefaultAzureAPIVersion
+		}
+		if config.APIKey == "" && config.TokenSource == nil {
+			config.APIKey = os.Getenv("AZURE_OPENAI_API_KEY")
+		}
+		p.azure = &config
+		p.endpoint = config.Endpoint
+	}
+}
+
+// azureDeploymentRoutes are the routes Azure scopes to a deployment in the
+// URL. Others, such as /responses, take the deployment as the model.
+var azureDeploymentRoutes = []string{
+	"/chat/completions", "/completions", "/embeddings", "/audio/", "/images/",
+}
+
+// requestOptions returns the SDK options that point requests at the Azure
+// resource and authenticate them. They're applied after the others, so they
+// take precedence.
+func (c *AzureConfig) requestOptions() []option.RequestOption {
+	base := strings.TrimRight(c.Endpoint, "/") + "/openai/"
+	opts := []option.RequestOption{
+		// Azure doesn't accept OpenAI's bearer API keys; deleting the header
+		// also stops the SDK from adding one from OPENAI_API_KEY.
+		option.WithHeaderDel("Authorization"),
+		option.WithMiddleware(c.middleware),
+	}
+	if c.APIVersion == AzureAPIVersionV1 {
+		opts = append(opts, option.WithBaseURL(base+"v1/"))
+	} else {
+		opts = append(opts,
+			option.WithBaseURL(base),
+			option.WithQueryAdd("api-version", c.APIVersion))
+	}
+	if c.TokenSource == nil {
+		opts = append(opts, option.WithHeader("api-key", c.APIKey))
+	}
+	return opts
+}
+
+// middleware adds a bearer token from the token source and moves the
+// deployment into the URL of deployment-scoped routes.
+func (c *AzureConfig) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	if c.TokenSource != nil {
+		token, err := c.TokenSource.Token(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("azure openai token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.APIVersion != AzureAPIVersionV1 && c.Deployment != "" {
+		if route, ok := strings.CutPrefix(req.URL.Path, "/openai"); ok && isAzureDeploymentRoute(route) {
+			req.URL.Path = "/openai/deployments/" + c.Deployment + route
+			req.URL.RawPath = ""
+		}
+	}
+	return next(req)
+}
+
+func isAzureDeploymentRoute(route string) bool {
+	for _, prefix := range azureDeploymentRoutes {
+		if route == prefix || (strings.HasSuffix(prefix, "/") && strings.HasPrefix(route, prefix)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// azureRequest is what a test server saw of a request.
+type azureRequest struct {
+	path, query, apiKey, authorization, model string
+}
+
+func newAzureServer(t *testing.T) (*httptest.Server, *azureRequest) {
+	t.Helper()
+	var seen azureRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var params struct {
+			Model string `json:"model"`
+		}
+		json.Unmarshal(body, &params)
+		seen = azureRequest{
+			path:          r.URL.Path,
+			query:         r.URL.RawQuery,
+			apiKey:        r.Header.Get("api-key"),
+			authorization: r.Header.Get("Authorization"),
+			model:         params.Model,
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+	return server, &seen
+}
+
+func TestAzureAPIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "openai-key")
+	server, seen := newAzureServer(t)
+
+	provider := New(WithMaxRetries(0), WithAzure(AzureConfig{
+		Endpoint:   server.URL + "/",
+		Deployment: "prod-gpt5",
+		APIVersion: "2025-04-01-preview",
+		APIKey:     "azure-key",
+	}))
+	_, err := provider.Generate(context.Background(), llm.WithUserTextMessage("hi"))
+	assert.Error(t, err)
+	assert.Equal(t, "/openai/responses", seen.path)
+	assert.Equal(t, "api-version=2025-04-01-preview", seen.query)
+	assert.Equal(t, "azure-key", seen.apiKey)
+	assert.Equal(t, "", seen.authorization)
+	assert.Equal(t, "prod-gpt5", seen.model)
+}
+
+func TestAzureTokenSource(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+	server, seen := newAzureServer(t)
+
+	provider := New(WithMaxRetries(0), WithModel("prod-gpt5"), WithAzure(AzureConfig{
+		Endpoint:   server.URL,
+		APIVersion: AzureAPIVersionV1,
+		TokenSource: AzureTokenSourceFunc(func(ctx context.Context) (string, error) {
+			return "entra-token", nil
+		}),
+	}))
+	_, err := provider.Generate(context.Background(), llm.WithUserTextMessage("hi"))
+	assert.Error(t, err)
+	assert.Equal(t, "/openai/v1/responses", seen.path)
+	assert.Equal(t, "", seen.query)
+	assert.Equal(t, "", seen.apiKey)
+	assert.Equal(t, "Bearer entra-token", seen.authorization)
+	assert.Equal(t, "prod-gpt5", seen.model)
+}
+
+func TestAzureDeploymentRoutes(t *testing.T) {
+	config := &AzureConfig{Deployment: "prod-gpt5", APIVersion: DefaultAzureAPIVersion}
+	for path, want := range map[string]string{
+		"/openai/chat/completions":     "/openai/deployments/prod-gpt5/chat/completions",
+		"/openai/embeddings":           "/openai/deployments/prod-gpt5/embeddings",
+		"/openai/images/generations":   "/openai/deployments/prod-gpt5/images/generations",
+		"/openai/audio/transcriptions": "/openai/deployments/prod-gpt5/audio/transcriptions",
+		"/openai/responses":            "/openai/responses",
+		"/openai/files":                "/openai/files",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "https://example.openai.azure.com"+path, nil)
+		var got string
+		_, err := config.middleware(req, func(req *http.Request) (*http.Response, error) {
+			got = req.URL.Path
+			return nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, path)
+	}
+}
+
+func TestAzureRegistered(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+	server, seen := newAzureServer(t)
+
+	model := providers.CreateModel("azure/prod-gpt5", server.URL)
+	assert.NotNil(t, model)
+	_, err := model.Generate(context.Background(), llm.WithUserTextMessage("hi"))
+	assert.Error(t, err)
+	assert.Equal(t, "/openai/responses", seen.path)
+	assert.Equal(t, "api-version="+DefaultAzureAPIVersion, seen.query)
+	assert.Equal(t, "azure-key", seen.apiKey)
+	assert.Equal(t, "prod-gpt5", seen.model)
+
+	// Deployment names never match implicitly
+	assert.Equal(t, "openai", providers.CreateModel("gpt-5", "").Name())
+}
@@ -0,0 +1,42 @@
+package openai
+
+import "github.com/deepnoodle-ai/dive/llm"
+
+// TextModelCapabilities describes what the text generation models can do.
+var TextModelCapabilities = []llm.ModelInfo{
+	gptCapabilities(ModelGPT56, 1_050_000, 128_000, true),
+	gptCapabilities(ModelGPT56Sol, 1_050_000, 128_000, true),
+	gptCapabilities(ModelGPT56Terra, 1_050_000, 128_000, true),
+	gptCapabilities(ModelGPT56Luna, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT55, 1_050_000, 128_000, true),
+	gptCapabilities(ModelGPT54, 1_050_000, 128_000, true),
+	gptCapabilities(ModelGPT54Mini, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT54Nano, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT52, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT52Pro, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT51, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT5, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT5Mini, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT5Nano, 400_000, 128_000, true),
+	gptCapabilities(ModelGPT41, 1_047_576, 32_768, false),
+	gptCapabilities(ModelGPT4o, 128_000, 16_384, false),
+}
+
+// gptCapabilities returns the capabilities shared by GPT models: text,
+// image, and PDF input, tools, structured outputs, and automatic prompt
+// caching.
+func gptCapabilities(model string, contextWindow, maxOutputTokens int, reasoning bool) llm.ModelInfo {
+	return llm.ModelInfo{
+		Model:            model,
+		Provider:         ProviderName,
+		ContextWindow:    contextWindow,
+		MaxOutputTokens:  maxOutputTokens,
+		InputModalities:  []llm.Modality{llm.ModalityText, llm.ModalityImage, llm.ModalityPDF},
+		OutputModalities: []llm.Modality{llm.ModalityText},
+		Tools:            true,
+		JSONSchema:       true,
+		Reasoning:        reasoning,
+		PromptCaching:    true,
+		UpdatedAt:        "2026-10-17",
+	}
+}
@@ -83,25 +83,23 @@ func decodeMessageContent(outputMsg responses.ResponseOutputMessage) ([]llm.Cont
 			textContent := &llm.TextContent{
 				Text: outputText.Text,
 			}
-			// Convert OpenAI annotations to web_search_result_location citations
+			// Convert OpenAI annotations to citations. Container file
+			// citations reference files written by the code interpreter
+			// and become FileContent blocks.
+			var files []llm.Content
 			if len(outputText.Annotations) > 0 {
 				citations := make([]llm.Citation, 0, len(outputText.Annotations))
 				for _, annotation := range outputText.Annotations {
-					switch annotation.Type {
-					case "url_citation":
-						urlCitation := annotation.AsURLCitation()
-						citations = append(citations, &llm.WebSearchResultLocation{
-							Type:  "web_search_result_location",
-							URL:   urlCitation.URL,
-							Title: urlCitation.Title,
-							// StartIndex: int(urlCitation.StartIndex),
-							// EndIndex:   int(urlCitation.EndIndex),
-						})
+					if file := decodeContainerFile(annotation); file != nil {
+						files = append(files, file)
+					} else if citation := decodeCitation(outputText.Text, annotation); citation != nil {
+						citations = append(citations, citation)
 					}
 				}
 				textContent.Citations = citations
 			}
 			contentBlocks = append(contentBlocks, textContent)
+			contentBlocks = append(contentBlocks, files...)
 		case "refusal":
 			contentBlocks = append(contentBlocks, &llm.RefusalContent{
 				Text: content.AsRefusal().Refusal,
@@ -111,6 +109,60 @@ func decodeMessageContent(outputMsg responses.ResponseOutputMessage) ([]llm.Cont
 	return contentBlocks, nil
 }
 
+// decodeContainerFile converts a container file citation, which references
+// a file written by the code interpreter, to a file, or returns nil for
+// other annotations.
+func decodeContainerFile(annotation responses.ResponseOutputTextAnnotationUnion) *llm.FileContent {
+	if annotation.Type != "container_file_citation" {
+		return nil
+	}
+	fileCitation := annotation.AsContainerFileCitation()
+	return &llm.FileContent{
+		Filename: fileCitation.Filename,
+		Source: &llm.ContentSource{
+			Type:   llm.ContentSourceTypeFile,
+			FileID: fileCitation.FileID,
+		},
+		ContainerID: fileCitation.ContainerID,
+	}
+}
+
+// decodeCitation converts a URL or file citation annotation on text to a
+// citation, or returns nil for other annotations. OpenAI gives spans in
+// characters, which are converted to byte offsets.
+func decodeCitation(text string, annotation responses.ResponseOutputTextAnnotationUnion) *llm.CitationContent {
+	switch annotation.Type {
+	case "url_citation":
+		urlCitation := annotation.AsURLCitation()
+		return &llm.CitationContent{
+			Type:       string(llm.CitationTypeContent),
+			Title:      urlCitation.Title,
+			URL:        urlCitation.URL,
+			StartIndex: byteOffset(text, int(urlCitation.StartIndex)),
+			EndIndex:   byteOffset(text, int(urlCitation.EndIndex)),
+		}
+	case "file_citation":
+		fileCitation := annotation.AsFileCitation()
+		return &llm.CitationContent{
+			Type:     string(llm.CitationTypeContent),
+			Title:    fileCitation.Filename,
+			SourceID: fileCitation.FileID,
+		}
+	}
+	return nil
+}
+
+// byteOffset converts a character offset in text to a byte offset.
+func byteOffset(text string, chars int) int {
+	for offset := range text {
+		if chars == 0 {
+			return offset
+		}
+		chars--
+	}
+	return len(text)
+}
+
 func decodeFunctionCallContent(functionCall responses.ResponseFunctionToolCall) ([]llm.Content, error) {
 	return []llm.Content{
 		&llm.ToolUseContent{
@@ -264,7 +316,7 @@ func decodeCodeInterpreterCallContent(codeCall responses.ResponseCodeInterpreter
 		}
 		results = append(results, callResult)
 	}
-	return []llm.Content{
+	contentBlocks := []llm.Content{
 		&CodeInterpreterCallContent{
 			ID:          codeCall.ID,
 			Code:        codeCall.Code,
@@ -272,7 +324,29 @@ func decodeCodeInterpreterCallContent(codeCall responses.ResponseCodeInterpreter
 			Status:      string(codeCall.Status),
 			ContainerID: codeCall.ContainerID,
 		},
-	}, nil
+	}
+	for _, file := range decodeCodeInterpreterImages(codeCall) {
+		contentBlocks = append(contentBlocks, file)
+	}
+	return contentBlocks, nil
+}
+
+// decodeCodeInterpreterImages returns the images rendered by the
+// interpreter (e.g. matplotlib charts) as files.
+func decodeCodeInterpreterImages(codeCall responses.ResponseCodeInterpreterToolCall) []*llm.FileContent {
+	var files []*llm.FileContent
+	for _, output := range codeCall.Outputs {
+		if output.Type == "image" && output.URL != "" {
+			files = append(files, &llm.FileContent{
+				Source: &llm.ContentSource{
+					Type: llm.ContentSourceTypeURL,
+					URL:  output.URL,
+				},
+				ContainerID: codeCall.ContainerID,
+			})
+		}
+	}
+	return files
 }
 
 func decodeFileSearchCallContent(fileSearchCall responses.ResponseFileSearchToolCall) ([]llm.Content, error) {
@@ -163,6 +163,11 @@ func encodeAssistantMessage(message *llm.Message) ([]responses.ResponseInputItem
 			if pairedResultIndex != -1 {
 				processed[pairedResultIndex] = true // Mark the corresponding MCPToolResultContent as processed
 			}
+		} else if _, ok := c.(*llm.FileContent); ok {
+			// Model-generated files are response-only; the code interpreter
+			// call that produced them is replayed instead.
+			processed[i] = true
+			continue
 		} else if _, ok := c.(*llm.MCPToolResultContent); ok {
 			// If we encounter an MCPToolResultContent that hasn't been processed
 			// as part of a pair, it means it's an orphaned result. The current
@@ -275,12 +280,14 @@ func encodeAssistantToolResultContent(c *llm.ToolResultContent) (responses.Respo
 }
 
 // encodeFunctionCallOutput renders a tool result as a Responses API
-// function_call_output item. Typed tool result blocks are flattened to plain
-// text rather than JSON-marshaled; results carrying images are emitted as a
-// content-part list so the model can actually see them.
+// function_call_output item whose call_id matches the function_call it
+// answers. Typed tool result blocks are flattened to plain text rather than
+// JSON-marshaled; results carrying images or files are emitted as a
+// content-part list (input_text, input_image, input_file) so the model can
+// actually see them.
 func encodeFunctionCallOutput(c *llm.ToolResultContent) (responses.ResponseInputItemUnionParam, error) {
 	blocks := providers.ToolResultBlocks(c)
-	if blocks == nil || c.IsError || !blocksContainImage(blocks) {
+	if blocks == nil || c.IsError || !blocksContainMedia(blocks) {
 		output, err := toolResultOutputText(c)
 		if err != nil {
 			return responses.ResponseInputItemUnionParam{}, err
@@ -309,6 +316,24 @@ func encodeFunctionCallOutput(c *llm.ToolResultContent) (responses.ResponseInput
 					ImageURL: openai.String(dataURL),
 				},
 			})
+		case dive.ToolResultContentTypeFile:
+			if b.MimeType == "" || b.Data == "" {
+				items = append(items, responses.ResponseFunctionCallOutputItemUnionParam{
+					OfInputText: &responses.ResponseInputTextContentParam{Text: "[file content omitted]"},
+				})
+				continue
+			}
+			// OpenAI requires a filename, so generate a default if one is not provided
+			filename := b.Filename
+			if filename == "" {
+				filename = "document"
+			}
+			items = append(items, responses.ResponseFunctionCallOutputItemUnionParam{
+				OfInputFile: &responses.ResponseInputFileContentParam{
+					Filename: openai.String(filename),
+					FileData: openai.String(fmt.Sprintf("data:%s;base64,%s", b.MimeType, b.Data)),
+				},
+			})
 		case dive.ToolResultContentTypeText, "":
 			if b.Text != "" {
 				items = append(items, responses.ResponseFunctionCallOutputItemUnionParam{
@@ -324,9 +349,11 @@ func encodeFunctionCallOutput(c *llm.ToolResultContent) (responses.ResponseInput
 	return responses.ResponseInputItemParamOfFunctionCallOutput(c.ToolUseID, items), nil
 }
 
-func blocksContainImage(blocks []*dive.ToolResultContent) bool {
+// blocksContainMedia reports whether any block is an image or file, which
+// the plain-text output form can't carry.
+func blocksContainMedia(blocks []*dive.ToolResultContent) bool {
 	for _, b := range blocks {
-		if b.Type == dive.ToolResultContentTypeImage {
+		if b.Type == dive.ToolResultContentTypeImage || b.Type == dive.ToolResultContentTypeFile {
 			return true
 		}
 	}
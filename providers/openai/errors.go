@@ -1,16 +1,23 @@
 package openai
 
 import (
+	"cmp"
 	"errors"
 
 	"github.com/deepnoodle-ai/dive/providers"
 	openaisdk "github.com/openai/openai-go/v3"
 )
 
+// normalizeOpenAIError converts an SDK error to a providers.ProviderError,
+// classified by the API's error code, such as "context_length_exceeded", or
+// its type when there is no code.
 func normalizeOpenAIError(err error) error {
 	var apiErr *openaisdk.Error
 	if errors.As(err, &apiErr) {
-		return providers.NewError(apiErr.StatusCode, apiErr.Message)
+		details := providers.ResponseErrorDetails(apiErr.Response)
+		details.Code = cmp.Or(apiErr.Code, apiErr.Type, details.Code)
+		details.Message = apiErr.Message
+		return providers.NewDetailedError(apiErr.StatusCode, apiErr.Message, details)
 	}
 	return err
 }
@@ -319,11 +319,19 @@ func (p *MediaProvider) Transcribe(ctx context.Context, audio []byte, config *me
 	return result, nil
 }
 
-// decodeImageResults extracts image data from OpenAI response items.
+// decodeImageResults extracts image data from OpenAI response items. Items
+// returned as a URL (DALL-E models with the default response format) have no
+// Data; the URL is in Metadata["url"] and SaveImage downloads it.
 func decodeImageResults(data []openai.Image, model string) ([]*media.ImageResult, error) {
 	var results []*media.ImageResult
 	for _, item := range data {
 		if item.B64JSON == "" {
+			if item.URL != "" {
+				results = append(results, &media.ImageResult{
+					Model:    model,
+					Metadata: map[string]any{"provider": "openai", "url": item.URL},
+				})
+			}
 			continue
 		}
 		imageData, err := base64.StdEncoding.DecodeString(item.B64JSON)
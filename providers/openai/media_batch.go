@@ -0,0 +1,192 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/media"
+	"github.com/deepnoodle-ai/wonton/retry"
+)
+
+// Defaults for ImageBatchOptions.
+const (
+	DefaultImageBatchConcurrency = 4
+	DefaultImageBatchMaxRetries  = 2
+	DefaultImageBatchRetryWait   = 2 * time.Second
+)
+
+// ImageGenerationRequest is one item in a GenerateImages batch.
+type ImageGenerationRequest struct {
+	// Prompt describes the image to generate.
+	Prompt string
+
+	// Options configure the request, e.g. media.WithModel,
+	// media.WithAspectRatio, or media.WithCount. The model defaults to
+	// ModelGPTImage2.
+	Options []media.Option
+}
+
+// ImageResult is the outcome of one ImageGenerationRequest.
+type ImageResult struct {
+	// Index is the position of the request in the batch.
+	Index int
+
+	// Prompt is the request prompt.
+	Prompt string
+
+	// Images holds the generated images. Empty if Err is set.
+	Images []*media.ImageResult
+
+	// Err is the error from the final attempt, if the request failed.
+	Err error
+}
+
+// ImageBatchOptions configures GenerateImages.
+type ImageBatchOptions struct {
+	// Provider generates the images. Defaults to NewMediaProvider().
+	Provider *MediaProvider
+
+	// Concurrency is the maximum number of requests in flight. Defaults to
+	// DefaultImageBatchConcurrency.
+	Concurrency int
+
+	// MaxRetries is the number of times a request is retried after a
+	// rate limit or server error. Defaults to DefaultImageBatchMaxRetries;
+	// use a negative value to disable retries.
+	MaxRetries int
+
+	// RetryBaseWait is the initial backoff between retries. Defaults to
+	// DefaultImageBatchRetryWait.
+	RetryBaseWait time.Duration
+}
+
+// GenerateImages runs a batch of image generation requests with bounded
+// concurrency, retrying each request on rate limits and server errors. It
+// returns one result per request, in request order. A failed request sets
+// its result's Err without affecting the rest of the batch; the returned
+// error is non-nil only if the context is done before every request starts.
+//
+//	results, _ := openai.GenerateImages(ctx, []openai.ImageGenerationRequest{
+//	    {Prompt: "A lighthouse at dawn"},
+//	    {Prompt: "A lighthouse at dusk", Options: []media.Option{media.WithAspectRatio(media.Aspect16x9)}},
+//	})
+//	for _, result := range results {
+//	    if result.Err != nil {
+//	        log.Printf("request %d: %v", result.Index, result.Err)
+//	        continue
+//	    }
+//	    openai.SaveImage(ctx, result.Images[0], fmt.Sprintf("image-%d", result.Index))
+//	}
+func GenerateImages(ctx context.Context, reqs []ImageGenerationRequest, opts ...ImageBatchOptions) ([]*ImageResult, error) {
+	var options ImageBatchOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.Provider == nil {
+		options.Provider = NewMediaProvider()
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = DefaultImageBatchConcurrency
+	}
+	if options.MaxRetries == 0 {
+		options.MaxRetries = DefaultImageBatchMaxRetries
+	} else if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	}
+	if options.RetryBaseWait <= 0 {
+		options.RetryBaseWait = DefaultImageBatchRetryWait
+	}
+
+	results := make([]*ImageResult, len(reqs))
+	sem := make(chan struct{}, options.Concurrency)
+	var wg sync.WaitGroup
+	var startErr error
+	for i, req := range reqs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			startErr = ctx.Err()
+		}
+		if startErr != nil {
+			for j := i; j < len(reqs); j++ {
+				results[j] = &ImageResult{Index: j, Prompt: reqs[j].Prompt, Err: startErr}
+			}
+			break
+		}
+		wg.Add(1)
+		go func(idx int, req ImageGenerationRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[idx] = generateImageWithRetry(ctx, idx, req, &options)
+		}(i, req)
+	}
+	wg.Wait()
+	return results, startErr
+}
+
+func generateImageWithRetry(ctx context.Context, idx int, req ImageGenerationRequest, options *ImageBatchOptions) *ImageResult {
+	config := &media.Config{}
+	config.Apply(req.Options...)
+
+	result := &ImageResult{Index: idx, Prompt: req.Prompt}
+	err := retry.DoSimple(ctx, func() error {
+		images, err := options.Provider.GenerateImage(ctx, req.Prompt, config)
+		if err != nil {
+			return normalizeOpenAIError(err)
+		}
+		result.Images = images
+		return nil
+	}, retry.WithMaxAttempts(options.MaxRetries+1),
+		retry.WithBackoff(options.RetryBaseWait, time.Minute),
+		retry.WithRetryIf(retry.SkipPermanent()))
+	if err != nil {
+		result.Err = fmt.Errorf("image request %d: %w", idx, err)
+	}
+	return result
+}
+
+// SaveImage writes an image to path and returns the path written. Images
+// returned as a URL rather than inline data (see decodeImageResults) are
+// downloaded first. If path has no extension, the image format's extension
+// is appended, and an existing file is never overwritten; see
+// media.ImageResult.WriteTo.
+func SaveImage(ctx context.Context, image *media.ImageResult, path string) (string, error) {
+	if len(image.Data) == 0 {
+		url, _ := image.Metadata["url"].(string)
+		if url == "" {
+			return "", fmt.Errorf("image has no data or url")
+		}
+		data, err := downloadImage(ctx, url)
+		if err != nil {
+			return "", err
+		}
+		image.Data = data
+		image.Format = media.DetectFormat(data)
+		image.MimeType = image.Format.MIMEType()
+	}
+	return image.WriteTo(path)
+}
+
+func downloadImage(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("downloading image: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading image: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading image: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,111 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/media"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))))
+	return buf.Bytes()
+}
+
+func TestGenerateImages(t *testing.T) {
+	pngData := testPNG(t)
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Prompt string `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		attempts[body.Prompt]++
+		n := attempts[body.Prompt]
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case body.Prompt == "flaky" && n == 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+		case body.Prompt == "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"rejected"}}`))
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": []map[string]any{{"b64_json": base64.StdEncoding.EncodeToString(pngData)}},
+			})
+		}
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(
+		option.WithBaseURL(server.URL),
+		option.WithAPIKey("test-key"),
+		option.WithMaxRetries(0),
+	)
+	results, err := GenerateImages(context.Background(), []ImageGenerationRequest{
+		{Prompt: "ok"},
+		{Prompt: "flaky"},
+		{Prompt: "bad"},
+	}, ImageBatchOptions{
+		Provider:      &MediaProvider{client: &client},
+		Concurrency:   2,
+		RetryBaseWait: time.Millisecond,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+
+	assert.NoError(t, results[0].Err)
+	assert.Len(t, results[0].Images, 1)
+	assert.Equal(t, media.FormatPNG, results[0].Images[0].Format)
+
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, 2, attempts["flaky"])
+
+	// Client errors fail the item without retrying or aborting the batch.
+	assert.Error(t, results[2].Err)
+	assert.Equal(t, 2, results[2].Index)
+	assert.Equal(t, 1, attempts["bad"])
+}
+
+func TestSaveImage(t *testing.T) {
+	pngData := testPNG(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(pngData)
+	}))
+	defer server.Close()
+
+	results, err := decodeImageResults([]openai.Image{{URL: server.URL + "/image.png"}}, "dall-e-3")
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	dir := t.TempDir()
+	path, err := SaveImage(context.Background(), results[0], filepath.Join(dir, "image"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "image.png"), path)
+	saved, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, pngData, saved)
+
+	_, err = SaveImage(context.Background(), &media.ImageResult{}, filepath.Join(dir, "empty"))
+	assert.Error(t, err)
+}
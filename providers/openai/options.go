@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
 	"github.com/openai/openai-go/v3/option"
 )
 
@@ -34,6 +36,21 @@ func WithClient(client *http.Client) Option {
 	}
 }
 
+// WithTransport sets the HTTP transport used for all API requests. It
+// replaces the HTTP client with one that has no overall timeout, so bound
+// requests with context deadlines instead. See providers.NewTransportClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(p *Provider) {
+		p.httpClient = providers.NewTransportClient(transport)
+	}
+}
+
+// WithStreamingTransport uses a transport tuned for long streaming responses,
+// such as extended reasoning. See providers.NewStreamingTransport.
+func WithStreamingTransport() Option {
+	return WithTransport(providers.NewStreamingTransport())
+}
+
 // WithModel sets the model name.
 func WithModel(model string) Option {
 	return func(p *Provider) {
@@ -66,6 +83,17 @@ func WithRetryBaseWait(retryBaseWait time.Duration) Option {
 	}
 }
 
+// WithAPIVersion pins the OpenAI-Beta header sent with every request, which
+// selects the version of beta APIs, such as "assistants=v2". Multiple
+// versions are comma-separated. Requests fail with an error if the value
+// isn't in name=vN form. The header is not sent by default; the Responses
+// API itself is unversioned.
+func WithAPIVersion(version string) Option {
+	return func(p *Provider) {
+		p.apiVersion = version
+	}
+}
+
 // WithName overrides the provider name returned by Name(). This is used by
 // providers that embed the OpenAI provider (e.g., Grok) to ensure the correct
 // name is reported in contexts like ToolConfiguration.
@@ -83,3 +111,31 @@ func WithExtraRequestOptions(opts ...option.RequestOption) Option {
 		p.extraRequestOptions = append(p.extraRequestOptions, opts...)
 	}
 }
+
+// Known provider option keys for use with llm.WithProviderOption. Providers
+// that embed this adapter also accept the same parameters under their own
+// namespace, e.g. "grok:top_p".
+const (
+	OptionTopP             llm.ProviderOptionKey = "openai:top_p"
+	OptionUser             llm.ProviderOptionKey = "openai:user"
+	OptionMetadata         llm.ProviderOptionKey = "openai:metadata"
+	OptionStore            llm.ProviderOptionKey = "openai:store"
+	OptionTruncation       llm.ProviderOptionKey = "openai:truncation"
+	OptionSafetyIdentifier llm.ProviderOptionKey = "openai:safety_identifier"
+	OptionPromptCacheKey   llm.ProviderOptionKey = "openai:prompt_cache_key"
+
+	// OptionBackground runs the response in background mode. A background
+	// stream can be resumed from its last event with llm.ResumableStream.
+	OptionBackground llm.ProviderOptionKey = "openai:background"
+)
+
+var knownProviderOptions = []llm.ProviderOptionKey{
+	OptionTopP,
+	OptionUser,
+	OptionMetadata,
+	OptionStore,
+	OptionTruncation,
+	OptionSafetyIdentifier,
+	OptionPromptCacheKey,
+	OptionBackground,
+}
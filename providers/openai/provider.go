@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -29,6 +30,10 @@ var (
 
 var _ llm.LLM = &Provider{}
 var _ llm.StreamingLLM = &Provider{}
+var _ llm.ToolLimiter = &Provider{}
+var _ llm.TokenCounter = &Provider{}
+var _ llm.ResponseFormatter = &Provider{}
+var _ llm.ResumableStream = &responseStream{}
 
 // Provider implements the OpenAI LLM provider using the Responses API.
 type Provider struct {
@@ -42,6 +47,8 @@ type Provider struct {
 	httpClient          *http.Client
 	options             []option.RequestOption
 	extraRequestOptions []option.RequestOption
+	apiVersion          string
+	azure               *AzureConfig
 }
 
 // New creates a new OpenAI provider with the given options.
@@ -61,6 +68,13 @@ func New(opts ...Option) *Provider {
 	for _, opt := range opts {
 		opt(p)
 	}
+	if p.azure != nil {
+		if p.azure.Deployment == "" {
+			p.azure.Deployment = string(p.model)
+		}
+		p.model = openai.ChatModel(p.azure.Deployment)
+		p.options = append(p.options, p.azure.requestOptions()...)
+	}
 	// Bake the HTTP client into the SDK client at construction time so
 	// per-request options don't have to re-specify it. WithClient only
 	// updates p.httpClient; the actual plumbing happens here.
@@ -84,6 +98,23 @@ func (p *Provider) Name() string {
 	return ProviderName
 }
 
+// ToolLimits implements llm.ToolLimiter. The Responses API accepts at most 128
+// tools, with names up to 64 characters and descriptions up to 1024.
+func (p *Provider) ToolLimits() llm.ToolLimits {
+	return llm.ToolLimits{MaxTools: 128, MaxNameLength: 64, MaxDescriptionLength: 1024}
+}
+
+// CountTokens implements llm.TokenCounter. OpenAI has no token counting
+// endpoint, so the count is estimated locally, like tiktoken, with
+// llm.EstimateRequestTokens. Images are sized from their dimensions at high
+// detail.
+func (p *Provider) CountTokens(ctx context.Context, model string, messages []*llm.Message, tools []llm.Tool) (int, error) {
+	if model == "" {
+		model = string(p.model)
+	}
+	return llm.EstimateRequestTokens(model, messages, tools)
+}
+
 func (p *Provider) buildConfig(opts ...llm.Option) *llm.Config {
 	config := &llm.Config{}
 	config.Apply(opts...)
@@ -97,6 +128,10 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 	if err != nil {
 		return nil, err
 	}
+	extraOpts, err := p.providerOptionRequestOptions(config)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := config.FireHooks(ctx, &llm.HookContext{
 		Type: llm.BeforeGenerate,
@@ -113,6 +148,7 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 		reqOpts := append([]option.RequestOption{
 			option.WithRequestTimeout(5 * time.Minute),
 		}, p.extraRequestOptions...)
+		reqOpts = append(reqOpts, extraOpts...)
 		resp, err = p.client.Responses.New(
 			ctx,
 			params,
@@ -158,6 +194,10 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 	if err != nil {
 		return nil, err
 	}
+	extraOpts, err := p.providerOptionRequestOptions(config)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := config.FireHooks(ctx, &llm.HookContext{
 		Type: llm.BeforeGenerate,
@@ -172,24 +212,139 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 	streamOpts := append([]option.RequestOption{
 		option.WithRequestTimeout(5 * time.Minute),
 	}, p.extraRequestOptions...)
-	stream := providers.NewRetryingStreamIterator(
-		ctx,
-		providers.StreamRetryConfig{
+	streamOpts = append(streamOpts, extraOpts...)
+	stream := &responseStream{
+		provider:   p,
+		background: p.runsInBackground(config),
+		retry: providers.StreamRetryConfig{
 			Provider:       p.Name(),
 			MaxRetries:     p.maxRetries,
 			RetryBaseWait:  p.retryBaseWait,
 			Logger:         config.Logger,
 			NormalizeError: normalizeOpenAIError,
 		},
-		func() (llm.StreamIterator, error) {
+	}
+	stream.StreamIterator = providers.NewContextRetryingStreamIterator(ctx, stream.retry,
+		func(ctx context.Context) (llm.StreamIterator, error) {
 			streamSDK := p.client.Responses.NewStreaming(ctx, params, streamOpts...)
-			return newOpenAIStreamIterator(streamSDK, config), nil
+			stream.iterator = newOpenAIStreamIterator(streamSDK, config)
+			return stream.iterator, nil
 		},
 	)
-
 	return stream, nil
 }
 
+// runsInBackground reports whether responses generated with config run in
+// background mode, which the openai:background provider option enables.
+func (p *Provider) runsInBackground(config *llm.Config) bool {
+	params, err := config.ResolveProviderOptions([]string{ProviderName, p.Name()}, knownProviderOptions)
+	background, _ := params["background"].(bool)
+	return err == nil && background
+}
+
+// responseStream is a Responses API stream. When the response runs in
+// background mode, it implements llm.ResumableStream by streaming the
+// stored response's events after the last one received.
+type responseStream struct {
+	llm.StreamIterator
+	provider   *Provider
+	background bool
+	retry      providers.StreamRetryConfig
+
+	// iterator decodes the current attempt's events
+	iterator *openaiStreamIterator
+}
+
+// Resume implements llm.ResumableStream.
+func (s *responseStream) Resume(ctx context.Context) error {
+	if !s.background {
+		return fmt.Errorf("%w: openai streams resume only in background mode, set with %s", llm.ErrStreamNotResumable, OptionBackground)
+	}
+	if s.Err() == nil {
+		return fmt.Errorf("%w: the stream has not failed", llm.ErrStreamNotResumable)
+	}
+	iterator := s.iterator
+	if iterator == nil || iterator.responseID == "" {
+		return fmt.Errorf("%w: the response was not created", llm.ErrStreamNotResumable)
+	}
+	iterator.Close()
+
+	p := s.provider
+	// The SDK sends stream in the body, which a GET request doesn't have, so
+	// it is set as the query parameter the API reads.
+	reqOpts := append([]option.RequestOption{
+		option.WithRequestTimeout(5 * time.Minute),
+		option.WithQuery("stream", "true"),
+	}, p.extraRequestOptions...)
+	s.StreamIterator = providers.NewContextRetryingStreamIterator(ctx, s.retry,
+		func(ctx context.Context) (llm.StreamIterator, error) {
+			params := responses.ResponseGetParams{StartingAfter: openai.Int(iterator.sequence)}
+			iterator.resume(p.client.Responses.GetStreaming(ctx, iterator.responseID, params, reqOpts...))
+			return iterator, nil
+		},
+	)
+	return nil
+}
+
+// providerOptionRequestOptions converts provider options addressed to this
+// provider (see llm.WithProviderOption) into JSON body overrides.
+func (p *Provider) providerOptionRequestOptions(config *llm.Config) ([]option.RequestOption, error) {
+	params, err := config.ResolveProviderOptions([]string{ProviderName, p.Name()}, knownProviderOptions)
+	if err != nil {
+		return nil, err
+	}
+	opts := make([]option.RequestOption, 0, len(params)+1)
+	for name, value := range params {
+		opts = append(opts, option.WithJSONSet(name, value))
+	}
+	if p.apiVersion != "" {
+		if !betaVersionPattern.MatchString(p.apiVersion) {
+			return nil, fmt.Errorf("invalid openai api version %q: expected a value like \"assistants=v2\"", p.apiVersion)
+		}
+		opts = append(opts, option.WithHeader("OpenAI-Beta", p.apiVersion))
+	}
+	return opts, nil
+}
+
+// betaVersionPattern matches OpenAI-Beta header values such as
+// "assistants=v2" or "assistants=v2,realtime=v1".
+var betaVersionPattern = regexp.MustCompile(`^[a-z0-9_.-]+=v\d+(,\s*[a-z0-9_.-]+=v\d+)*$`)
+
+// ContinuesResponses reports whether responses generated with config are
+// stored, which the openai:store provider option enables. It implements
+// llm.ResponseContinuer.
+func (p *Provider) ContinuesResponses(config *llm.Config) bool {
+	params, err := config.ResolveProviderOptions([]string{ProviderName, p.Name()}, knownProviderOptions)
+	store, _ := params["store"].(bool)
+	return err == nil && store
+}
+
+// SupportsResponseFormat implements llm.ResponseFormatter. The Responses API
+// supports text, JSON, and strict JSON schema formats natively.
+func (p *Provider) SupportsResponseFormat(format *llm.ResponseFormat) bool {
+	switch format.Type {
+	case llm.ResponseFormatTypeText, llm.ResponseFormatTypeJSON, llm.ResponseFormatTypeJSONSchema:
+		return true
+	}
+	return false
+}
+
+// messagesAfterResponse drops the messages the API already holds when a
+// request continues a stored response: everything up to and including the
+// assistant message with that response's ID. Callers can then keep their
+// full history and pass llm.WithPreviousResponseID(resp.ID), and only the
+// new input, such as the function_call_output items answering the previous
+// response's calls, is sent. Messages are returned unchanged when none
+// carries the ID.
+func messagesAfterResponse(messages []*llm.Message, responseID string) []*llm.Message {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == llm.Assistant && messages[i].ID == responseID {
+			return messages[i+1:]
+		}
+	}
+	return messages
+}
+
 // buildRequestParams converts llm.Config to responses.ResponseNewParams
 func (p *Provider) buildRequestParams(config *llm.Config) (responses.ResponseNewParams, error) {
 	if len(config.Messages) == 0 {
@@ -198,7 +353,7 @@ func (p *Provider) buildRequestParams(config *llm.Config) (responses.ResponseNew
 
 	// Convert input messages to the OpenAI SDK input type
 	rendered, err := llm.RenderReminders(config.Messages, func(_ int, _ []*llm.Message) (llm.Role, bool) {
-		if p.Name() == ProviderName && strings.TrimRight(p.endpoint, "/") == DefaultEndpoint {
+		if p.Name() == ProviderName && (p.azure != nil || strings.TrimRight(p.endpoint, "/") == DefaultEndpoint) {
 			return llm.Developer, true
 		}
 		return llm.User, false
@@ -206,6 +361,9 @@ func (p *Provider) buildRequestParams(config *llm.Config) (responses.ResponseNew
 	if err != nil {
 		return responses.ResponseNewParams{}, err
 	}
+	if config.PreviousResponseID != "" {
+		rendered = messagesAfterResponse(rendered, config.PreviousResponseID)
+	}
 	input, err := encodeMessages(rendered)
 	if err != nil {
 		return responses.ResponseNewParams{}, err
@@ -239,9 +397,13 @@ func (p *Provider) buildRequestParams(config *llm.Config) (responses.ResponseNew
 
 	includes := map[Include]bool{}
 
-	// Handle reasoning effort
-	if config.ReasoningEffort != "" {
-		effort, err := normalizeResponsesReasoningEffort(p.Name(), string(params.Model), config.ReasoningEffort)
+	// Handle reasoning effort, or a thinking budget mapped to one
+	requestedEffort := config.ReasoningEffort
+	if requestedEffort == "" {
+		requestedEffort = budgetReasoningEffort(p.Name(), string(params.Model), config)
+	}
+	if requestedEffort != "" {
+		effort, err := normalizeResponsesReasoningEffort(p.Name(), string(params.Model), requestedEffort)
 		if err != nil {
 			return responses.ResponseNewParams{}, err
 		}
@@ -481,27 +643,34 @@ func applyResponseFormat(params *responses.ResponseNewParams, config *llm.Config
 	return nil
 }
 
-// determineStopReason maps SDK response data to standard stop reasons
+// determineStopReason maps SDK response data to the llm.StopReason values
 func determineStopReason(response *responses.Response) string {
-	// Check if the response contains any tool calls
+	// Check if the response contains any tool calls or refusals
 	for _, item := range response.Output {
 		if strings.HasSuffix(item.Type, "_call") {
-			return "tool_use"
+			return llm.StopReasonToolUse
+		}
+		if item.Type == "message" {
+			for _, content := range item.Content {
+				if content.Type == "refusal" {
+					return llm.StopReasonRefusal
+				}
+			}
 		}
 	}
 
 	// Handle different response statuses
 	switch response.Status {
 	case "completed":
-		return "end_turn"
+		return llm.StopReasonEndTurn
 	case "incomplete":
 		// Map specific incomplete reasons if available
 		if response.IncompleteDetails.Reason != "" {
 			switch response.IncompleteDetails.Reason {
 			case "max_output_tokens":
-				return "max_tokens"
+				return llm.StopReasonMaxTokens
 			case "content_filter":
-				return "content_filter"
+				return llm.StopReasonContentFilter
 			case "run_cancelled":
 				return "cancelled"
 			case "run_expired":
@@ -518,6 +687,6 @@ func determineStopReason(response *responses.Response) string {
 	case "in_progress":
 		return "incomplete"
 	default:
-		return "end_turn"
+		return llm.StopReasonEndTurn
 	}
 }
@@ -1,7 +1,10 @@
 package openai
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/deepnoodle-ai/dive/llm"
@@ -600,3 +603,23 @@ func TestConvertResponseBasicFields(t *testing.T) {
 	assert.Equal(t, 12, result.Usage.OutputTokens)
 	assert.Equal(t, 3, result.Usage.CacheReadInputTokens)
 }
+
+func TestAPIVersion(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("OpenAI-Beta")
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	input := llm.WithUserTextMessage("hi")
+
+	_, err := New(WithAPIKey("test-key"), WithEndpoint(server.URL), WithMaxRetries(0),
+		WithAPIVersion("assistants=v2,realtime=v1")).Generate(ctx, input)
+	assert.Error(t, err)
+	assert.Equal(t, "assistants=v2,realtime=v1", header)
+
+	_, err = New(WithAPIKey("test-key"), WithEndpoint(server.URL), WithAPIVersion("v2")).Generate(ctx, input)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid openai api version "v2"`)
+}
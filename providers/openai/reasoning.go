@@ -14,6 +14,28 @@ func normalizeResponsesReasoningEffort(providerName, model string, effort llm.Re
 	return normalizeOpenAIReasoningEffort(model, effort)
 }
 
+// budgetReasoningEffort maps a thinking budget set with llm.WithThinkingBudget
+// to an effort level for OpenAI reasoning models, which take no budget. It
+// returns "" when an effort is set explicitly, when there is no budget, and
+// for other models, which ignore the budget.
+func budgetReasoningEffort(providerName, model string, config *llm.Config) llm.ReasoningEffort {
+	if config.ReasoningEffort != "" || config.ReasoningBudget == nil ||
+		strings.EqualFold(providerName, "grok") || !isOpenAIReasoningModel(model) {
+		return ""
+	}
+	return llm.ReasoningEffortForBudget(*config.ReasoningBudget)
+}
+
+// isOpenAIReasoningModel reports whether model is a GPT-5, o-series, or Codex
+// model, which accept a reasoning effort.
+func isOpenAIReasoningModel(model string) bool {
+	model = strings.TrimPrefix(strings.ToLower(model), "openai/")
+	if strings.HasPrefix(model, "gpt-5") || strings.Contains(model, "codex") {
+		return true
+	}
+	return len(model) > 1 && model[0] == 'o' && model[1] >= '0' && model[1] <= '9'
+}
+
 func normalizeOpenAIReasoningEffort(model string, effort llm.ReasoningEffort) (llm.ReasoningEffort, error) {
 	model = strings.ToLower(model)
 	model = strings.TrimPrefix(model, "openai/")
@@ -8,9 +8,21 @@ import (
 func init() {
 	// OpenAI Responses API models
 	providers.Register(providers.ProviderEntry{
-		Name:    "openai",
-		Match:   providers.PrefixesMatcher("gpt-", "o3", "o4", "codex"),
-		Factory: factory,
+		Name:             "openai",
+		Match:            providers.PrefixesMatcher("gpt-", "o3", "o4", "codex"),
+		Factory:          factory,
+		APIKeyEnv:        []string{"OPENAI_API_KEY"},
+		HealthCheckModel: ModelGPT54Mini,
+	})
+
+	// Azure OpenAI deployments. Azure model names are deployment names,
+	// which can be anything, so the provider is only selected explicitly,
+	// as "azure/<deployment>".
+	providers.Register(providers.ProviderEntry{
+		Name:      "azure",
+		Match:     func(string) bool { return false },
+		Factory:   azureFactory,
+		APIKeyEnv: []string{"AZURE_OPENAI_API_KEY"},
 	})
 }
 
@@ -21,3 +33,7 @@ func factory(model, endpoint string) llm.LLM {
 	}
 	return New(opts...)
 }
+
+func azureFactory(deployment, endpoint string) llm.LLM {
+	return New(WithAzure(AzureConfig{Endpoint: endpoint, Deployment: deployment}))
+}
@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/deepnoodle-ai/dive/llm"
@@ -33,6 +34,104 @@ func TestDecodeFileSearchCallContent(t *testing.T) {
 	assert.Equal(t, "produced 1,773,443 vehicles", fsc.Results[0].Text)
 }
 
+func TestDecodeGeneratedFiles(t *testing.T) {
+	var message responses.ResponseOutputMessage
+	err := json.Unmarshal([]byte(`{
+		"id": "msg_1",
+		"type": "message",
+		"role": "assistant",
+		"status": "completed",
+		"content": [{
+			"type": "output_text",
+			"text": "Saved results.csv",
+			"annotations": [{
+				"type": "container_file_citation",
+				"container_id": "cntr_1",
+				"file_id": "cfile_1",
+				"filename": "results.csv",
+				"start_index": 6,
+				"end_index": 17
+			}]
+		}]
+	}`), &message)
+	assert.NoError(t, err)
+	contents, err := decodeMessageContent(message)
+	assert.NoError(t, err)
+	assert.Len(t, contents, 2)
+	file, ok := contents[1].(*llm.FileContent)
+	assert.True(t, ok)
+	assert.Equal(t, "results.csv", file.Filename)
+	assert.Equal(t, "cfile_1", file.Source.FileID)
+	assert.Equal(t, "cntr_1", file.ContainerID)
+
+	var call responses.ResponseCodeInterpreterToolCall
+	err = json.Unmarshal([]byte(`{
+		"id": "ci_1",
+		"type": "code_interpreter_call",
+		"status": "completed",
+		"container_id": "cntr_1",
+		"code": "plot()",
+		"outputs": [{"type": "logs", "logs": "ok"}, {"type": "image", "url": "https://example.com/chart.png"}]
+	}`), &call)
+	assert.NoError(t, err)
+	contents, err = decodeCodeInterpreterCallContent(call)
+	assert.NoError(t, err)
+	assert.Len(t, contents, 2)
+	file, ok = contents[1].(*llm.FileContent)
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com/chart.png", file.Source.URL)
+
+	// Files are not replayed to the API.
+	items, err := encodeAssistantMessage(&llm.Message{Role: llm.Assistant, Content: []llm.Content{
+		&llm.TextContent{Text: "Saved results.csv"}, file,
+	}})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestDecodeCitations(t *testing.T) {
+	var message responses.ResponseOutputMessage
+	err := json.Unmarshal([]byte(`{
+		"id": "msg_1",
+		"type": "message",
+		"role": "assistant",
+		"status": "completed",
+		"content": [{
+			"type": "output_text",
+			"text": "Café prices rose (source).",
+			"annotations": [{
+				"type": "url_citation",
+				"url": "https://example.com/prices",
+				"title": "Prices",
+				"start_index": 5,
+				"end_index": 16
+			}, {
+				"type": "file_citation",
+				"file_id": "file_1",
+				"filename": "report.pdf",
+				"index": 0
+			}]
+		}]
+	}`), &message)
+	assert.NoError(t, err)
+	contents, err := decodeMessageContent(message)
+	assert.NoError(t, err)
+	assert.Len(t, contents, 1)
+	text := contents[0].(*llm.TextContent)
+	assert.Len(t, text.Citations, 2)
+
+	// Character offsets become byte offsets; "é" is two bytes.
+	web := text.Citations[0].(*llm.CitationContent)
+	assert.Equal(t, "https://example.com/prices", web.URL)
+	assert.Equal(t, "Prices", web.Title)
+	assert.Equal(t, "prices rose", text.Text[web.StartIndex:web.EndIndex])
+
+	file := text.Citations[1].(*llm.CitationContent)
+	assert.Equal(t, "report.pdf", file.Title)
+	assert.Equal(t, "file_1", file.Source())
+	assert.False(t, file.HasSpan())
+}
+
 func TestDecodeAssistantResponse_ReasoningTokens(t *testing.T) {
 	resp := &responses.Response{
 		ID: "resp_1",
@@ -275,3 +374,31 @@ func TestBuildRequestParams_UnknownReasoningEffortPassesThroughKnownModel(t *tes
 	assert.NoError(t, err)
 	assert.Equal(t, responses.ReasoningEffort("superdeep"), params.Reasoning.Effort)
 }
+
+func TestBuildRequestParams_ThinkingBudgetMapsToEffort(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  string
+		effort llm.ReasoningEffort
+		want   responses.ReasoningEffort
+	}{
+		{name: "budget maps to effort", model: ModelGPT55, want: "medium"},
+		{name: "explicit effort wins", model: ModelGPT55, effort: llm.ReasoningEffortHigh, want: "high"},
+		{name: "non-reasoning model ignores budget", model: ModelGPT41, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := New(WithAPIKey("test"), WithModel(tt.model))
+			config := &llm.Config{}
+			config.Apply(
+				llm.WithMessages(llm.NewUserTextMessage("hi")),
+				llm.WithThinkingBudget(8000),
+				llm.WithReasoningEffort(tt.effort),
+			)
+
+			params, err := provider.buildRequestParams(config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, params.Reasoning.Effort)
+		})
+	}
+}
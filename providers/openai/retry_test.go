@@ -1,26 +1,32 @@
 package openai
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
 	"github.com/deepnoodle-ai/wonton/assert"
 )
 
-// countingTransport counts every HTTP request and always returns a 429.
+// countingTransport counts every HTTP request and always returns a 429,
+// with body if set or a rate limit error otherwise.
 type countingTransport struct {
 	requests atomic.Int64
+	body     string
 }
 
 func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	t.requests.Add(1)
-	body := `{"error": {"message": "rate limited", "type": "rate_limit_error"}}`
+	body := cmp.Or(t.body, `{"error": {"message": "rate limited", "type": "rate_limit_error"}}`)
 	return &http.Response{
 		StatusCode: http.StatusTooManyRequests,
 		Status:     "429 Too Many Requests",
@@ -66,3 +72,39 @@ func TestGenerateRetryCountDefault(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, int64(DefaultMaxRetries+1), transport.requests.Load())
 }
+
+func TestGenerateQuotaNotRetried(t *testing.T) {
+	transport := &countingTransport{body: `{"error": {"message": "You exceeded your current quota.",
+		"type": "insufficient_quota", "code": "insufficient_quota"}}`}
+	provider := New(
+		WithAPIKey("test-key"),
+		WithClient(&http.Client{Transport: transport}),
+		WithMaxRetries(2),
+	)
+	provider.retryBaseWait = time.Millisecond
+
+	_, err := provider.Generate(context.Background(), llm.WithUserTextMessage("hello"))
+	assert.True(t, errors.Is(err, providers.ErrQuotaExceeded))
+	assert.False(t, errors.Is(err, providers.ErrRateLimited))
+	assert.Equal(t, int64(1), transport.requests.Load())
+}
+
+func TestGenerateClassifiesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req_abc")
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error": {"message": "Your input exceeds the context window of this model.",
+			"type": "invalid_request_error", "code": "context_length_exceeded"}}`)
+	}))
+	defer server.Close()
+
+	provider := New(WithAPIKey("test-key"), WithEndpoint(server.URL), WithMaxRetries(0))
+	_, err := provider.Generate(context.Background(), llm.WithUserTextMessage("hello"))
+	assert.True(t, errors.Is(err, providers.ErrContextLengthExceeded))
+	var providerErr *providers.ProviderError
+	assert.True(t, errors.As(err, &providerErr))
+	assert.Equal(t, "context_length_exceeded", providerErr.Code())
+	assert.Equal(t, "req_abc", providerErr.RequestID())
+	assert.Equal(t, http.StatusBadRequest, providerErr.StatusCode())
+}
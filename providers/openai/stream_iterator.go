@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"sync"
@@ -32,6 +33,14 @@ type openaiStreamIterator struct {
 	// Keyed by OutputIndex (from OpenAI events)
 	outputItemsState map[int]*outputItemState
 
+	// sequence is the sequence number of the last event processed, the
+	// cursor a resumed stream starts after
+	sequence int64
+
+	// fileCount is the number of file blocks emitted. Files have no output
+	// index of their own, so they are indexed after every output item.
+	fileCount int
+
 	eventCount int
 	closeOnce  sync.Once
 	isClosed   bool
@@ -64,6 +73,10 @@ type contentPartState struct {
 	PartType     string // E.g., "output_text", "reasoning"
 	Text         string // Accumulated text for output_text or reasoning
 	IsComplete   bool
+
+	// Annotations added to output_text, emitted as citations once the
+	// text is complete and their offsets can be converted
+	Annotations []responses.ResponseOutputTextAnnotationUnion
 }
 
 func newOpenAIStreamIterator(sdkStream StreamSource, config *llm.Config) *openaiStreamIterator {
@@ -110,6 +123,7 @@ func (s *openaiStreamIterator) Next() bool {
 
 	// Process the OpenAI event
 	rawEvent := s.sdkStream.Current()
+	s.sequence = rawEvent.SequenceNumber
 	events, err := s.processOpenAIEvent(rawEvent)
 	if err != nil {
 		s.err = err
@@ -153,6 +167,16 @@ func (s *openaiStreamIterator) Close() error {
 	return err
 }
 
+// resume continues the iterator on sdkStream, which carries the events of
+// the same response after s.sequence, keeping the state of the output items
+// streamed so far.
+func (s *openaiStreamIterator) resume(sdkStream StreamSource) {
+	s.sdkStream = sdkStream
+	s.err = nil
+	s.isClosed = false
+	s.closeOnce = sync.Once{}
+}
+
 // processOpenAIEvent converts OpenAI stream events to Dive events
 func (s *openaiStreamIterator) processOpenAIEvent(event responses.ResponseStreamEventUnion) ([]*llm.Event, error) {
 	var diveEvents []*llm.Event
@@ -376,6 +400,23 @@ func (s *openaiStreamIterator) processOpenAIEvent(event responses.ResponseStream
 			})
 		}
 
+	case responses.ResponseOutputTextAnnotationAddedEvent:
+		outputIdx := int(data.OutputIndex)
+		contentIdx := int(data.ContentIndex)
+		if item, ok := s.outputItemsState[outputIdx]; ok {
+			if part, ok2 := item.ContentParts[contentIdx]; ok2 {
+				raw, err := json.Marshal(data.Annotation)
+				if err != nil {
+					return nil, fmt.Errorf("error encoding annotation: %w", err)
+				}
+				var annotation responses.ResponseOutputTextAnnotationUnion
+				if err := json.Unmarshal(raw, &annotation); err != nil {
+					return nil, fmt.Errorf("error decoding annotation: %w", err)
+				}
+				part.Annotations = append(part.Annotations, annotation)
+			}
+		}
+
 	case responses.ResponseTextDoneEvent:
 		outputIdx := int(data.OutputIndex)
 		contentIdx := int(data.ContentIndex)
@@ -383,10 +424,27 @@ func (s *openaiStreamIterator) processOpenAIEvent(event responses.ResponseStream
 			if part, ok2 := item.ContentParts[contentIdx]; ok2 {
 				part.Text = data.Text
 				part.IsComplete = true
+				var files []*llm.FileContent
+				for _, annotation := range part.Annotations {
+					if file := decodeContainerFile(annotation); file != nil {
+						files = append(files, file)
+					} else if citation := decodeCitation(part.Text, annotation); citation != nil {
+						idx := outputIdx
+						diveEvents = append(diveEvents, &llm.Event{
+							Type:  llm.EventTypeContentBlockDelta,
+							Index: &idx,
+							Delta: &llm.EventDelta{
+								Type:     llm.EventDeltaTypeCitations,
+								Citation: citation,
+							},
+						})
+					}
+				}
 				diveEvents = append(diveEvents, &llm.Event{
 					Type:  llm.EventTypeContentBlockStop,
 					Index: &outputIdx,
 				})
+				diveEvents = append(diveEvents, s.fileEvents(files)...)
 			}
 		}
 
@@ -434,6 +492,10 @@ func (s *openaiStreamIterator) processOpenAIEvent(event responses.ResponseStream
 			}
 		}
 
+		if data.Item.Type == "code_interpreter_call" {
+			diveEvents = append(diveEvents, s.fileEvents(decodeCodeInterpreterImages(data.Item.AsCodeInterpreterCall()))...)
+		}
+
 		if item, ok := s.outputItemsState[outputIdx]; ok && !item.IsComplete {
 			item.IsComplete = true
 			if item.ItemType == "function_call" {
@@ -498,3 +560,31 @@ func (s *openaiStreamIterator) processOpenAIEvent(event responses.ResponseStream
 
 	return diveEvents, nil
 }
+
+// fileIndexBase is the index of the first file block, after the indexes of
+// any output items.
+const fileIndexBase = 1 << 20
+
+// fileEvents returns the events of complete file blocks for files, such as
+// those the code interpreter wrote or rendered.
+func (s *openaiStreamIterator) fileEvents(files []*llm.FileContent) []*llm.Event {
+	var events []*llm.Event
+	for _, file := range files {
+		index := fileIndexBase + s.fileCount
+		s.fileCount++
+		events = append(events,
+			&llm.Event{
+				Type:  llm.EventTypeContentBlockStart,
+				Index: &index,
+				ContentBlock: &llm.EventContentBlock{
+					Type:        llm.ContentTypeFile,
+					Filename:    file.Filename,
+					Source:      file.Source,
+					ContainerID: file.ContainerID,
+				},
+			},
+			&llm.Event{Type: llm.EventTypeContentBlockStop, Index: &index},
+		)
+	}
+	return events
+}
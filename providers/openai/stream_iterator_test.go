@@ -95,3 +95,66 @@ func TestStreamIteratorZeroBasedIndices(t *testing.T) {
 	assert.Equal(t, 140, response.Usage.InputTokens)
 	assert.Equal(t, 11, response.Usage.OutputTokens)
 }
+
+func TestStreamIteratorCitations(t *testing.T) {
+	lines := []string{
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress","output":[]}}`,
+		`{"type":"response.output_item.added","sequence_number":1,"output_index":0,"item":{"id":"msg_1","type":"message","status":"in_progress","content":[],"role":"assistant"}}`,
+		`{"type":"response.content_part.added","sequence_number":2,"item_id":"msg_1","output_index":0,"content_index":0,"part":{"type":"output_text","annotations":[],"text":""}}`,
+		`{"type":"response.output_text.delta","sequence_number":3,"item_id":"msg_1","output_index":0,"content_index":0,"delta":"Go 1.22 added range over ints."}`,
+		`{"type":"response.output_text.annotation.added","sequence_number":4,"item_id":"msg_1","output_index":0,"content_index":0,"annotation_index":0,"annotation":{"type":"url_citation","url":"https://go.dev/doc/go1.22","title":"Go 1.22 Release Notes","start_index":0,"end_index":7}}`,
+		`{"type":"response.output_text.done","sequence_number":5,"item_id":"msg_1","output_index":0,"content_index":0,"text":"Go 1.22 added range over ints."}`,
+		`{"type":"response.completed","sequence_number":6,"response":{"id":"resp_1","status":"completed","output":[],"usage":{"input_tokens":2,"output_tokens":3}}}`,
+	}
+	response := accumulateStream(t, lines)
+	citations := response.Citations()
+	assert.Len(t, citations, 1)
+	assert.Equal(t, "https://go.dev/doc/go1.22", citations[0].URL)
+	assert.Equal(t, "Go 1.22", response.Message().Text()[citations[0].StartIndex:citations[0].EndIndex])
+}
+
+func TestStreamIteratorGeneratedFiles(t *testing.T) {
+	lines := []string{
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress","output":[]}}`,
+		`{"type":"response.output_item.added","sequence_number":1,"output_index":0,"item":{"id":"ci_1","type":"code_interpreter_call","status":"in_progress","container_id":"cntr_1","code":""}}`,
+		`{"type":"response.output_item.done","sequence_number":2,"output_index":0,"item":{"id":"ci_1","type":"code_interpreter_call","status":"completed","container_id":"cntr_1","code":"plot()","outputs":[{"type":"logs","logs":"ok"},{"type":"image","url":"https://example.com/chart.png"}]}}`,
+		`{"type":"response.output_item.added","sequence_number":3,"output_index":1,"item":{"id":"msg_1","type":"message","status":"in_progress","content":[],"role":"assistant"}}`,
+		`{"type":"response.content_part.added","sequence_number":4,"item_id":"msg_1","output_index":1,"content_index":0,"part":{"type":"output_text","annotations":[],"text":""}}`,
+		`{"type":"response.output_text.delta","sequence_number":5,"item_id":"msg_1","output_index":1,"content_index":0,"delta":"Saved results.csv"}`,
+		`{"type":"response.output_text.annotation.added","sequence_number":6,"item_id":"msg_1","output_index":1,"content_index":0,"annotation_index":0,"annotation":{"type":"container_file_citation","container_id":"cntr_1","file_id":"cfile_1","filename":"results.csv","start_index":6,"end_index":17}}`,
+		`{"type":"response.output_text.done","sequence_number":7,"item_id":"msg_1","output_index":1,"content_index":0,"text":"Saved results.csv"}`,
+		`{"type":"response.completed","sequence_number":8,"response":{"id":"resp_1","status":"completed","output":[],"usage":{"input_tokens":2,"output_tokens":3}}}`,
+	}
+	response := accumulateStream(t, lines)
+	assert.Equal(t, "Saved results.csv", response.Message().Text())
+	assert.Len(t, response.Citations(), 0)
+
+	files := response.Files()
+	assert.Len(t, files, 2)
+	assert.Equal(t, "https://example.com/chart.png", files[0].Source.URL)
+	assert.Equal(t, "cntr_1", files[0].ContainerID)
+	assert.Equal(t, "results.csv", files[1].Filename)
+	assert.Equal(t, "cfile_1", files[1].Source.FileID)
+	assert.Equal(t, "cntr_1", files[1].ContainerID)
+}
+
+// accumulateStream runs the stream events in lines through the iterator
+// and returns the accumulated response.
+func accumulateStream(t *testing.T, lines []string) *llm.Response {
+	t.Helper()
+	var events []responses.ResponseStreamEventUnion
+	for _, line := range lines {
+		var event responses.ResponseStreamEventUnion
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	iterator := newOpenAIStreamIterator(&mockStreamSource{events: events}, &llm.Config{})
+	defer iterator.Close()
+
+	accumulator := llm.NewResponseAccumulator()
+	for iterator.Next() {
+		assert.NoError(t, accumulator.AddEvent(iterator.Event()))
+	}
+	assert.NoError(t, iterator.Err())
+	return accumulator.Response()
+}
@@ -2,9 +2,11 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"sync"
@@ -291,3 +293,143 @@ func TestStreamRetryDefaultBudget(t *testing.T) {
 	assert.Error(t, iterator.Err())
 	assert.Equal(t, int64(DefaultMaxRetries+1), transport.requests.Load())
 }
+
+func TestStreamResumeBackgroundResponse(t *testing.T) {
+	sse := func(lines ...string) string {
+		var b strings.Builder
+		for _, line := range lines {
+			b.WriteString("data: " + line + "\n\n")
+		}
+		return b.String()
+	}
+	first := sse(
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_bg","status":"in_progress","model":"test-model","output":[]}}`,
+		`{"type":"response.output_item.added","sequence_number":1,"output_index":0,"item":{"id":"msg_1","type":"message","status":"in_progress","content":[],"role":"assistant"}}`,
+		`{"type":"response.content_part.added","sequence_number":2,"item_id":"msg_1","output_index":0,"content_index":0,"part":{"type":"output_text","annotations":[],"text":""}}`,
+		`{"type":"response.output_text.delta","sequence_number":3,"item_id":"msg_1","output_index":0,"content_index":0,"delta":"Hello, "}`,
+	)
+	rest := sse(
+		`{"type":"response.output_text.delta","sequence_number":4,"item_id":"msg_1","output_index":0,"content_index":0,"delta":"world!"}`,
+		`{"type":"response.output_text.done","sequence_number":5,"item_id":"msg_1","output_index":0,"content_index":0,"text":"Hello, world!"}`,
+		`{"type":"response.completed","sequence_number":6,"response":{"id":"resp_bg","status":"completed","model":"test-model","output":[],"usage":{"input_tokens":4,"output_tokens":3}}}`,
+	)
+
+	var requests []*http.Request
+	var requestBody map[string]any
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req)
+		if req.Method == http.MethodPost {
+			assert.NoError(t, json.NewDecoder(req.Body).Decode(&requestBody))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+				Body:       &failingOpenAIStreamBody{data: strings.NewReader(first), err: io.ErrUnexpectedEOF},
+				Request:    req,
+			}, nil
+		}
+		return streamHTTPResponse(req, http.StatusOK, "text/event-stream", rest), nil
+	})
+	provider := New(
+		WithAPIKey("test-key"),
+		WithClient(&http.Client{Transport: transport}),
+		WithMaxRetries(0),
+	)
+
+	stream, err := llm.NewResumableStream(context.Background(), provider,
+		llm.WithMessages(llm.NewUserTextMessage("hello")),
+		llm.WithProviderOption(OptionBackground, true),
+	)
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	accumulator := llm.NewResponseAccumulator()
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.Error(t, stream.Err())
+	assert.Equal(t, "Hello, ", accumulator.Response().Message().Text())
+
+	assert.NoError(t, stream.Resume(context.Background()))
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Err())
+
+	assert.True(t, accumulator.IsComplete())
+	assert.Equal(t, "Hello, world!", accumulator.Response().Message().Text())
+	assert.Equal(t, 3, accumulator.Response().Usage.OutputTokens)
+
+	assert.Equal(t, true, requestBody["background"])
+	assert.Len(t, requests, 2)
+	assert.Equal(t, http.MethodGet, requests[1].Method)
+	assert.Equal(t, "/v1/responses/resp_bg", requests[1].URL.Path)
+	assert.Equal(t, "true", requests[1].URL.Query().Get("stream"))
+	assert.Equal(t, "3", requests[1].URL.Query().Get("starting_after"))
+}
+
+func TestStreamResumeRequiresBackground(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+			Body: &failingOpenAIStreamBody{
+				data: strings.NewReader(`data: {"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress","output":[]}}` + "\n\n"),
+				err:  io.ErrUnexpectedEOF,
+			},
+			Request: req,
+		}, nil
+	})
+	provider := New(WithAPIKey("test-key"), WithClient(&http.Client{Transport: transport}), WithMaxRetries(0))
+
+	iterator, _ := consumeStream(t, provider, llm.WithMessages(llm.NewUserTextMessage("hello")))
+	defer iterator.Close()
+	assert.Error(t, iterator.Err())
+
+	err := iterator.(llm.ResumableStream).Resume(context.Background())
+	assert.ErrorIs(t, err, llm.ErrStreamNotResumable)
+}
+
+func TestStreamStopReleasesBlockedRequest(t *testing.T) {
+	for _, stop := range []string{"close", "cancel"} {
+		t.Run(stop, func(t *testing.T) {
+			disconnected := make(chan struct{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, `data: {"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress","model":"test-model","output":[]}}`+"\n\n")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+				disconnected <- struct{}{}
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			provider := New(WithAPIKey("test-key"), WithEndpoint(server.URL), WithMaxRetries(0))
+			iterator, err := provider.Stream(ctx, llm.WithUserTextMessage("hello"))
+			assert.NoError(t, err)
+			defer iterator.Close()
+			assert.True(t, iterator.Next())
+
+			next := make(chan bool, 1)
+			go func() { next <- iterator.Next() }()
+			time.Sleep(20 * time.Millisecond)
+			if stop == "close" {
+				go iterator.Close()
+			} else {
+				cancel()
+			}
+			select {
+			case gotEvent := <-next:
+				assert.False(t, gotEvent)
+			case <-time.After(2 * time.Second):
+				t.Fatal("Next did not return after the stream was stopped")
+			}
+			select {
+			case <-disconnected:
+			case <-time.After(2 * time.Second):
+				t.Fatal("the HTTP request was not aborted")
+			}
+			assert.True(t, errors.Is(iterator.Err(), context.Canceled))
+		})
+	}
+}
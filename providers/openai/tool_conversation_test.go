@@ -0,0 +1,130 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	dive "github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+// replayServer answers each request with the next recorded response and
+// keeps the decoded request bodies.
+func replayServer(t *testing.T, fixtures ...string) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(data, &body))
+		bodies = append(bodies, body)
+		if len(bodies) > len(fixtures) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		recorded, err := os.ReadFile(fixtures[len(bodies)-1])
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(recorded)
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func TestTwoTurnToolConversationWithPreviousResponseID(t *testing.T) {
+	server, bodies := replayServer(t,
+		"fixtures/responses-tool-turn1.json",
+		"fixtures/responses-tool-turn2.json",
+	)
+	provider := New(WithAPIKey("test-key"), WithEndpoint(server.URL), WithModel(ModelGPT54Mini))
+	forecast := llm.NewToolDefinition().
+		WithName("get_forecast").
+		WithDescription("Get the forecast for a city").
+		WithSchema(&schema.Schema{
+			Type:       schema.Object,
+			Properties: map[string]*schema.Property{"city": {Type: schema.String}},
+		})
+	store := llm.WithProviderOption(OptionStore, true)
+	ctx := context.Background()
+
+	history := []*llm.Message{llm.NewUserTextMessage("What's the weather in Paris?")}
+	first, err := provider.Generate(ctx, llm.WithMessages(history...), llm.WithTools(forecast), store)
+	assert.NoError(t, err)
+	assert.Equal(t, "resp_turn1", first.ID)
+	calls := first.ToolCalls()
+	assert.Len(t, calls, 1)
+	assert.Equal(t, "call_weather_1", calls[0].ID)
+
+	radar := base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\nradar"))
+	report := base64.StdEncoding.EncodeToString([]byte("%PDF-1.7 report"))
+	history = append(history, first.Message(), &llm.Message{
+		Role: llm.User,
+		Content: []llm.Content{&llm.ToolResultContent{
+			ToolUseID: calls[0].ID,
+			Content: dive.NewToolResult(
+				&dive.ToolResultContent{Type: dive.ToolResultContentTypeText, Text: "Sunny, 24°C"},
+				&dive.ToolResultContent{Type: dive.ToolResultContentTypeImage, Data: radar, MimeType: "image/png"},
+				&dive.ToolResultContent{Type: dive.ToolResultContentTypeFile, Data: report, MimeType: "application/pdf", Filename: "report.pdf"},
+			).Content,
+		}},
+	})
+	second, err := provider.Generate(ctx,
+		llm.WithMessages(history...),
+		llm.WithTools(forecast),
+		llm.WithPreviousResponseID(first.ID),
+		store,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sunny in Paris, 24°C. The radar image and report agree.", second.Message().Text())
+
+	assert.Len(t, *bodies, 2)
+	assert.Equal(t, true, (*bodies)[0]["store"])
+	turn2 := (*bodies)[1]
+	assert.Equal(t, "resp_turn1", turn2["previous_response_id"])
+	assert.Equal(t, true, turn2["store"])
+
+	// Only the tool output is sent; the API already holds the question and
+	// the function call.
+	input := turn2["input"].([]any)
+	assert.Len(t, input, 1)
+	output := input[0].(map[string]any)
+	assert.Equal(t, "function_call_output", output["type"])
+	assert.Equal(t, "call_weather_1", output["call_id"])
+
+	parts := output["output"].([]any)
+	assert.Len(t, parts, 3)
+	assert.Equal(t, map[string]any{"type": "input_text", "text": "Sunny, 24°C"}, parts[0])
+	assert.Equal(t, map[string]any{"type": "input_image", "image_url": "data:image/png;base64," + radar}, parts[1])
+	assert.Equal(t, map[string]any{
+		"type":      "input_file",
+		"filename":  "report.pdf",
+		"file_data": "data:application/pdf;base64," + report,
+	}, parts[2])
+}
+
+func TestPreviousResponseIDWithoutMatchingMessageSendsFullHistory(t *testing.T) {
+	messages := []*llm.Message{
+		llm.NewUserTextMessage("hi"),
+		{ID: "resp_other", Role: llm.Assistant, Content: []llm.Content{&llm.TextContent{Text: "hello"}}},
+		llm.NewUserTextMessage("again"),
+	}
+	assert.Equal(t, messages, messagesAfterResponse(messages, "resp_missing"))
+	assert.Equal(t, messages[2:], messagesAfterResponse(messages, "resp_other"))
+}
+
+func TestContinuesResponsesWhenStored(t *testing.T) {
+	provider := New(WithAPIKey("test-key"))
+	config := &llm.Config{}
+	assert.False(t, provider.ContinuesResponses(config))
+	config.Apply(llm.WithProviderOption(OptionStore, true))
+	assert.True(t, provider.ContinuesResponses(config))
+}
@@ -0,0 +1,36 @@
+package openaicompletions
+
+import "github.com/deepnoodle-ai/dive/llm"
+
+// convertCitations converts the citations and search results that online
+// models such as Perplexity's Sonar return alongside an answer. The answer
+// refers to them by 1-based markers like [1], so the result keeps their
+// order: citation N is the source of marker [N+1]. The citations have no
+// span in the answer text. When the server sends only search results, they
+// are used in place of citations.
+func convertCitations(urls []string, results []SearchResult) []llm.Citation {
+	if len(urls) == 0 {
+		for _, result := range results {
+			urls = append(urls, result.URL)
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+	byURL := make(map[string]SearchResult, len(results))
+	for _, result := range results {
+		byURL[result.URL] = result
+	}
+	citations := make([]llm.Citation, 0, len(urls))
+	for _, url := range urls {
+		result := byURL[url]
+		citations = append(citations, &llm.CitationContent{
+			Type:     string(llm.CitationTypeContent),
+			Title:    result.Title,
+			URL:      url,
+			Snippet:  result.Snippet,
+			SourceID: url,
+		})
+	}
+	return citations
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -57,6 +58,7 @@ var (
 )
 
 var _ llm.StreamingLLM = &Provider{}
+var _ llm.ToolLimiter = &Provider{}
 
 // Provider implements an LLM provider using the OpenAI Chat Completions API.
 // This is used as the base for several providers including Grok, Groq, Mistral,
@@ -71,6 +73,9 @@ type Provider struct {
 	maxRetries    int
 	retryBaseWait time.Duration
 	systemRole    string
+	apiVersion    string
+
+	responseFormatEncoder ResponseFormatEncoder
 }
 
 // New creates a new OpenAI Completions provider with the given options.
@@ -98,6 +103,25 @@ func (p *Provider) Name() string {
 	return "openai-completions"
 }
 
+// ToolLimits implements llm.ToolLimiter using the OpenAI Chat Completions
+// limits: at most 128 tools, with names up to 64 characters and descriptions
+// up to 1024. Compatible endpoints generally enforce the same or looser
+// limits.
+func (p *Provider) ToolLimits() llm.ToolLimits {
+	return llm.ToolLimits{MaxTools: 128, MaxNameLength: 64, MaxDescriptionLength: 1024}
+}
+
+// marshalRequest encodes the request body, merging in any provider options
+// addressed to this provider.
+func (p *Provider) marshalRequest(request *Request, config *llm.Config) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+	return providers.ApplyProviderOptions(body, config,
+		[]string{"openai-completions", p.Name()}, knownProviderOptions)
+}
+
 func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
 	config := &llm.Config{}
 	config.Apply(opts...)
@@ -110,7 +134,8 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 	if err := validateMessages(config.Messages); err != nil {
 		return nil, err
 	}
-	msgs, err := convertMessages(config.Messages)
+	instructions, messages := llm.FoldDeveloperMessages(config.Messages)
+	msgs, err := convertMessages(messages)
 	if err != nil {
 		return nil, fmt.Errorf("error converting messages: %w", err)
 	}
@@ -119,11 +144,11 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 	}
 
 	request.Messages = msgs
-	addSystemPrompt(&request, config.SystemPrompt, p.systemRole)
+	addSystemPrompt(&request, joinInstructions(config.SystemPrompt, instructions), p.systemRole)
 
-	body, err := json.Marshal(request)
+	body, err := p.marshalRequest(&request, config)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		return nil, err
 	}
 
 	if err := config.FireHooks(ctx, &llm.HookContext{
@@ -157,7 +182,7 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 						"status", resp.StatusCode, "body", string(body))
 				}
 			}
-			return providers.NewError(resp.StatusCode, string(body))
+			return providers.NewResponseError(resp, string(body))
 		}
 		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 			return fmt.Errorf("error decoding response: %w", err)
@@ -174,8 +199,14 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 	choice := result.Choices[0]
 
 	var contentBlocks []llm.Content
+	if choice.Message.Reasoning != "" {
+		contentBlocks = append(contentBlocks, &llm.ThinkingContent{Thinking: choice.Message.Reasoning})
+	}
 	if choice.Message.Content != "" {
-		contentBlocks = append(contentBlocks, &llm.TextContent{Text: choice.Message.Content})
+		contentBlocks = append(contentBlocks, &llm.TextContent{
+			Text:      choice.Message.Content,
+			Citations: convertCitations(result.Citations, result.SearchResults),
+		})
 	}
 
 	// Transform tool calls into content blocks (like Anthropic)
@@ -202,11 +233,12 @@ func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Respo
 	}
 
 	response := &llm.Response{
-		ID:      result.ID,
-		Model:   p.model,
-		Role:    llm.Assistant,
-		Content: contentBlocks,
-		Usage:   result.Usage.toLLMUsage(),
+		ID:         result.ID,
+		Model:      p.model,
+		Role:       llm.Assistant,
+		Content:    contentBlocks,
+		StopReason: llm.NormalizeStopReason(choice.FinishReason),
+		Usage:      result.Usage.toLLMUsage(),
 	}
 
 	llm.PopulateCost(response.Model, response.Usage.Speed == string(llm.SpeedFast), &response.Usage)
@@ -242,7 +274,8 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 	if err := validateMessages(config.Messages); err != nil {
 		return nil, err
 	}
-	msgs, err := convertMessages(config.Messages)
+	instructions, messages := llm.FoldDeveloperMessages(config.Messages)
+	msgs, err := convertMessages(messages)
 	if err != nil {
 		return nil, fmt.Errorf("error converting messages: %w", err)
 	}
@@ -253,11 +286,11 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 	request.Messages = msgs
 	request.Stream = true
 	request.StreamOptions = &StreamOptions{IncludeUsage: true}
-	addSystemPrompt(&request, config.SystemPrompt, p.systemRole)
+	addSystemPrompt(&request, joinInstructions(config.SystemPrompt, instructions), p.systemRole)
 
-	body, err := json.Marshal(request)
+	body, err := p.marshalRequest(&request, config)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		return nil, err
 	}
 
 	if err := config.FireHooks(ctx, &llm.HookContext{
@@ -271,12 +304,12 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 		return nil, err
 	}
 
-	stream := providers.NewRetryingStreamIterator(ctx, providers.StreamRetryConfig{
+	stream := providers.NewContextRetryingStreamIterator(ctx, providers.StreamRetryConfig{
 		Provider:      p.Name(),
 		MaxRetries:    p.maxRetries,
 		RetryBaseWait: p.retryBaseWait,
 		Logger:        config.Logger,
-	}, func() (llm.StreamIterator, error) {
+	}, func(ctx context.Context) (llm.StreamIterator, error) {
 		req, err := p.createRequest(ctx, body, config, true)
 		if err != nil {
 			return nil, err
@@ -289,7 +322,7 @@ func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIt
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			return nil, providers.NewError(resp.StatusCode, string(body))
+			return nil, providers.NewResponseError(resp, string(body))
 		}
 		return &StreamIterator{
 			body:              resp.Body,
@@ -371,6 +404,9 @@ func convertMessages(messages []*llm.Message) ([]Message, error) {
 				// thinking content (which this provider's own stream
 				// iterator can produce from "reasoning" deltas) is
 				// skipped on encode rather than erroring.
+			case *llm.FileContent:
+				// Model-generated files are response-only and are not
+				// replayed.
 			default:
 				return nil, fmt.Errorf("unsupported content type: %s", c.Type())
 			}
@@ -601,6 +637,15 @@ func (p *Provider) applyRequestConfig(req *Request, config *llm.Config) error {
 			}
 		}
 		req.ToolChoice = toolChoice
+		req.ParallelToolCalls = config.ParallelToolCalls
+	}
+
+	if config.ResponseFormat != nil && p.responseFormatEncoder != nil {
+		format, err := p.responseFormatEncoder(config.ResponseFormat)
+		if err != nil {
+			return err
+		}
+		req.ResponseFormat = format
 	}
 
 	req.Tools = tools
@@ -626,6 +671,16 @@ func (p *Provider) applyRequestConfig(req *Request, config *llm.Config) error {
 	return nil
 }
 
+// joinInstructions appends developer instructions folded out of the
+// messages to the system prompt, so they are sent with the provider's
+// system role, which is "system" for servers without a developer role.
+func joinInstructions(systemPrompt, instructions string) string {
+	if systemPrompt == "" || instructions == "" {
+		return systemPrompt + instructions
+	}
+	return systemPrompt + "\n\n" + instructions
+}
+
 func addSystemPrompt(request *Request, systemPrompt, defaultSystemRole string) {
 	if systemPrompt == "" {
 		return
@@ -649,14 +704,24 @@ func addSystemPrompt(request *Request, systemPrompt, defaultSystemRole string) {
 	}}, request.Messages...)
 }
 
+// betaVersionPattern matches OpenAI-Beta header values such as
+// "assistants=v2" or "assistants=v2,realtime=v1".
+var betaVersionPattern = regexp.MustCompile(`^[a-z0-9_.-]+=v\d+(,\s*[a-z0-9_.-]+=v\d+)*$`)
+
 // createRequest creates an HTTP request with appropriate headers for OpenAI API calls
 func (p *Provider) createRequest(ctx context.Context, body []byte, config *llm.Config, isStreaming bool) (*http.Request, error) {
+	if p.apiVersion != "" && !betaVersionPattern.MatchString(p.apiVersion) {
+		return nil, fmt.Errorf("invalid openai api version %q: expected a value like \"assistants=v2\"", p.apiVersion)
+	}
 	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+p.apiKey)
 	req.Header.Set("Content-Type", "application/json")
+	if p.apiVersion != "" {
+		req.Header.Set("OpenAI-Beta", p.apiVersion)
+	}
 
 	if isStreaming {
 		req.Header.Set("Accept", "text/event-stream")
@@ -100,6 +100,27 @@ func TestApplyRequestConfig_MistralOmitsReasoningEffort(t *testing.T) {
 	assert.Equal(t, ReasoningEffort(""), req.ReasoningEffort)
 }
 
+func TestApplyRequestConfig_ParallelToolCalls(t *testing.T) {
+	tool := llm.NewToolDefinition().
+		WithName("lookup").
+		WithDescription("Looks up a value").
+		WithSchema(&schema.Schema{Type: "object"})
+	provider := New(WithModel(ModelGPT5))
+	disabled := false
+
+	var req Request
+	assert.NoError(t, provider.applyRequestConfig(&req, &llm.Config{
+		Tools:             []llm.Tool{tool},
+		ParallelToolCalls: &disabled,
+	}))
+	assert.Equal(t, &disabled, req.ParallelToolCalls)
+
+	// Without tools there is nothing to call in parallel
+	req = Request{}
+	assert.NoError(t, provider.applyRequestConfig(&req, &llm.Config{ParallelToolCalls: &disabled}))
+	assert.Nil(t, req.ParallelToolCalls)
+}
+
 func TestApplyRequestConfig_NormalizesReasoningEffortForTools(t *testing.T) {
 	tool := llm.NewToolDefinition().
 		WithName("lookup").
@@ -696,3 +717,32 @@ func TestGenerateUsageDetails(t *testing.T) {
 	assert.Equal(t, 150, usage.CacheReadInputTokens)
 	assert.Equal(t, 10, usage.ReasoningTokens)
 }
+
+func TestAPIVersion(t *testing.T) {
+	ctx := context.Background()
+	req, err := New().createRequest(ctx, nil, &llm.Config{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "", req.Header.Get("OpenAI-Beta"))
+
+	req, err = New(WithAPIVersion("assistants=v2")).createRequest(ctx, nil, &llm.Config{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "assistants=v2", req.Header.Get("OpenAI-Beta"))
+
+	_, err = New(WithAPIVersion("2024-01-01")).createRequest(ctx, nil, &llm.Config{}, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid openai api version")
+}
+
+func TestApplyRequestConfig_ThinkingBudgetMapsToEffort(t *testing.T) {
+	budget := 20000
+	provider := New(WithModel(ModelGPT55))
+	var req Request
+	assert.NoError(t, provider.applyRequestConfig(&req, &llm.Config{ReasoningBudget: &budget}))
+	assert.Equal(t, ReasoningEffortHigh, req.ReasoningEffort)
+
+	// Compatible servers that take no effort level ignore the budget
+	provider = New(WithModel("deepseek-chat"))
+	req = Request{}
+	assert.NoError(t, provider.applyRequestConfig(&req, &llm.Config{ReasoningBudget: &budget}))
+	assert.Equal(t, ReasoningEffort(""), req.ReasoningEffort)
+}
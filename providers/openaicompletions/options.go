@@ -3,6 +3,9 @@ package openaicompletions
 import (
 	"net/http"
 	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
 )
 
 // Option is a function that configures the Provider
@@ -37,6 +40,21 @@ func WithClient(client *http.Client) Option {
 	}
 }
 
+// WithTransport sets the HTTP transport used for all API requests. It
+// replaces the HTTP client with one that has no overall timeout, so bound
+// requests with context deadlines instead. See providers.NewTransportClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(p *Provider) {
+		p.client = providers.NewTransportClient(transport)
+	}
+}
+
+// WithStreamingTransport uses a transport tuned for long streaming responses,
+// such as extended reasoning. See providers.NewStreamingTransport.
+func WithStreamingTransport() Option {
+	return WithTransport(providers.NewStreamingTransport())
+}
+
 // WithMaxTokens sets the maximum number of tokens to generate
 func WithMaxTokens(maxTokens int) Option {
 	return func(p *Provider) {
@@ -65,9 +83,64 @@ func WithBaseWait(baseWait time.Duration) Option {
 	}
 }
 
+// WithAPIVersion pins the OpenAI-Beta header sent with every request, such
+// as "assistants=v2". Multiple versions are comma-separated. Requests fail
+// with an error if the value isn't in name=vN form. The header is not sent
+// by default; the Chat Completions API itself is unversioned.
+func WithAPIVersion(version string) Option {
+	return func(p *Provider) {
+		p.apiVersion = version
+	}
+}
+
 // WithSystemRole sets the name of the system role for the provider
 func WithSystemRole(systemRole string) Option {
 	return func(p *Provider) {
 		p.systemRole = systemRole
 	}
 }
+
+// ResponseFormatEncoder converts an llm.ResponseFormat into the
+// response_format request parameter of a compatible server. It returns nil
+// to omit the parameter.
+type ResponseFormatEncoder func(format *llm.ResponseFormat) (any, error)
+
+// WithResponseFormatEncoder sends llm.WithResponseFormat requests as the
+// response_format parameter, encoded by encode. Without an encoder the
+// response format is not sent, since compatible servers disagree on its
+// shape.
+func WithResponseFormatEncoder(encode ResponseFormatEncoder) Option {
+	return func(p *Provider) {
+		p.responseFormatEncoder = encode
+	}
+}
+
+// Known provider option keys for use with llm.WithProviderOption. Providers
+// that embed this adapter also accept the same parameters under their own
+// namespace, e.g. "openrouter:seed".
+const (
+	OptionTopP        llm.ProviderOptionKey = "openai-completions:top_p"
+	OptionSeed        llm.ProviderOptionKey = "openai-completions:seed"
+	OptionStop        llm.ProviderOptionKey = "openai-completions:stop"
+	OptionUser        llm.ProviderOptionKey = "openai-completions:user"
+	OptionLogitBias   llm.ProviderOptionKey = "openai-completions:logit_bias"
+	OptionLogprobs    llm.ProviderOptionKey = "openai-completions:logprobs"
+	OptionTopLogprobs llm.ProviderOptionKey = "openai-completions:top_logprobs"
+	OptionMetadata    llm.ProviderOptionKey = "openai-completions:metadata"
+
+	// OptionResponseFormat sets the raw response_format parameter, replacing
+	// any value encoded from llm.WithResponseFormat.
+	OptionResponseFormat llm.ProviderOptionKey = "openai-completions:response_format"
+)
+
+var knownProviderOptions = []llm.ProviderOptionKey{
+	OptionTopP,
+	OptionSeed,
+	OptionStop,
+	OptionUser,
+	OptionLogitBias,
+	OptionLogprobs,
+	OptionTopLogprobs,
+	OptionMetadata,
+	OptionResponseFormat,
+}
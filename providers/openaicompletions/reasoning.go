@@ -8,12 +8,16 @@ import (
 )
 
 func (p *Provider) resolveReasoningEffort(model string, config *llm.Config) (ReasoningEffort, bool, error) {
+	modelLower := strings.ToLower(model)
 	effort := config.ReasoningEffort
+	if effort == "" && config.ReasoningBudget != nil && isOpenAIReasoningModel(modelLower) {
+		// OpenAI reasoning models take an effort level, not a budget
+		effort = llm.ReasoningEffortForBudget(*config.ReasoningBudget)
+	}
 	if effort == "" {
 		return "", false, nil
 	}
 
-	modelLower := strings.ToLower(model)
 	switch {
 	case strings.HasPrefix(modelLower, "openai/"):
 		normalized, err := normalizeOpenAIReasoningEffort(strings.TrimPrefix(modelLower, "openai/"), effort)
@@ -35,6 +39,16 @@ func (p *Provider) resolveReasoningEffort(model string, config *llm.Config) (Rea
 	}
 }
 
+// isOpenAIReasoningModel reports whether model is a GPT-5, o-series, or Codex
+// model, which accept a reasoning effort.
+func isOpenAIReasoningModel(model string) bool {
+	model = strings.TrimPrefix(strings.ToLower(model), "openai/")
+	if strings.HasPrefix(model, "gpt-5") || strings.Contains(model, "codex") {
+		return true
+	}
+	return len(model) > 1 && model[0] == 'o' && model[1] >= '0' && model[1] <= '9'
+}
+
 // normalizeToolReasoningEffort handles Chat Completions constraints that only
 // apply when function tools and reasoning are requested together. GPT-5.4 mini
 // rejects that combination unless reasoning_effort is "none".
@@ -10,9 +10,11 @@ import (
 func init() {
 	// Explicit OpenAI Completions API (prefix: openai-completions:)
 	providers.Register(providers.ProviderEntry{
-		Name:    "openai-completions",
-		Match:   providers.PrefixMatcher("openai-completions:"),
-		Factory: factory,
+		Name:             "openai-completions",
+		Match:            providers.PrefixMatcher("openai-completions:"),
+		Factory:          factory,
+		APIKeyEnv:        []string{"OPENAI_API_KEY"},
+		HealthCheckModel: ModelGPT54Mini,
 	})
 }
 
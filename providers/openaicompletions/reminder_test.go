@@ -1,6 +1,10 @@
 package openaicompletions
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/deepnoodle-ai/dive/llm"
@@ -17,3 +21,30 @@ func TestReminderRenderingFallsBackForChatCompletions(t *testing.T) {
 	assert.Equal(t, "user", converted[0].Role)
 	assert.Contains(t, converted[0].Content, `name="mode"`)
 }
+
+func TestDeveloperMessagesFoldIntoSystemPrompt(t *testing.T) {
+	var body Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := New(WithEndpoint(server.URL), WithAPIKey("test"), WithModel("gpt-4o"))
+	_, err := provider.Generate(context.Background(),
+		llm.WithSystemPrompt("Be friendly."),
+		llm.WithMessages(
+			llm.NewDeveloperMessage("Answer in French."),
+			llm.NewUserTextMessage("Hello"),
+			llm.NewDeveloperMessage("Keep it short."),
+		))
+	assert.NoError(t, err)
+
+	assert.Len(t, body.Messages, 3)
+	assert.Equal(t, DefaultSystemRole, body.Messages[0].Role)
+	assert.Equal(t, "Be friendly.\n\nAnswer in French.", body.Messages[0].Content)
+	assert.Equal(t, "user", body.Messages[1].Role)
+	assert.Equal(t, "user", body.Messages[2].Role)
+	assert.Equal(t, "Keep it short.", body.Messages[2].Content)
+}
@@ -21,6 +21,8 @@ type StreamIterator struct {
 	responseID        string
 	responseModel     string
 	usage             Usage
+	citations         []string
+	searchResults     []SearchResult
 	prefill           string
 	prefillClosingTag string
 	eventCount        int
@@ -140,6 +142,13 @@ func (s *StreamIterator) next() ([]*llm.Event, error) {
 	if event.Usage.TotalTokens > 0 {
 		s.usage = event.Usage
 	}
+	// Online models repeat their citations on each chunk
+	if len(event.Citations) > 0 {
+		s.citations = event.Citations
+	}
+	if len(event.SearchResults) > 0 {
+		s.searchResults = event.SearchResults
+	}
 	if len(event.Choices) == 0 {
 		// With stream_options.include_usage set, the API sends a final chunk
 		// with empty choices that carries the usage, after the finish_reason
@@ -324,6 +333,19 @@ func (s *StreamIterator) next() ([]*llm.Event, error) {
 	}
 
 	if choice.FinishReason != "" {
+		// Attach the answer's citations to its text block
+		if s.textIndex >= 0 {
+			for _, citation := range convertCitations(s.citations, s.searchResults) {
+				events = append(events, &llm.Event{
+					Type:  llm.EventTypeContentBlockDelta,
+					Index: &s.textIndex,
+					Delta: &llm.EventDelta{
+						Type:     llm.EventDeltaTypeCitations,
+						Citation: citation,
+					},
+				})
+			}
+		}
 		// Stop any open content blocks
 		for index, block := range s.contentBlocks {
 			blockIndex := index
@@ -349,10 +371,7 @@ func (s *StreamIterator) next() ([]*llm.Event, error) {
 		// Build the message_delta event with the stop reason, but defer it
 		// (along with message_stop) until the trailing usage chunk, [DONE]
 		// marker, or EOF, so the message_delta carries the real token usage.
-		stopReason := choice.FinishReason
-		if stopReason == "tool_calls" {
-			stopReason = "tool_use" // Match Anthropic
-		}
+		stopReason := llm.NormalizeStopReason(choice.FinishReason)
 		s.finalEvents = []*llm.Event{
 			{
 				Type:  llm.EventTypeMessageDelta,
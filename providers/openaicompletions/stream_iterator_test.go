@@ -39,6 +39,27 @@ func collectEvents(t *testing.T, iterator *StreamIterator) ([]*llm.Event, *llm.R
 	return events, accumulator
 }
 
+// TestStreamIteratorLengthIsTruncated verifies that the "length" finish
+// reason is normalized to max_tokens.
+func TestStreamIteratorLengthIsTruncated(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-5","choices":[{"index":0,"delta":{"role":"assistant","content":"Once upon"}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-5","choices":[{"index":0,"delta":{},"finish_reason":"length"}]}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	iterator := newTestStreamIterator(body)
+	defer iterator.Close()
+	_, accumulator := collectEvents(t, iterator)
+
+	response := accumulator.Response()
+	assert.Equal(t, llm.StopReasonMaxTokens, response.StopReason)
+	assert.True(t, response.Truncated())
+}
+
 // TestStreamIteratorUsageFromTrailingChunk verifies that the token usage
 // delivered in the final empty-choices chunk (stream_options.include_usage)
 // is reflected in the accumulated response, and that message_stop is still
@@ -67,7 +88,7 @@ func TestStreamIteratorUsageFromTrailingChunk(t *testing.T) {
 
 	response := accumulator.Response()
 	assert.Equal(t, "Hello world", response.Message().Text())
-	assert.Equal(t, "stop", response.StopReason)
+	assert.Equal(t, "end_turn", response.StopReason)
 	assert.Equal(t, 12, response.Usage.InputTokens)
 	assert.Equal(t, 7, response.Usage.OutputTokens)
 }
@@ -103,7 +124,7 @@ func TestStreamIteratorSkipsSSEComments(t *testing.T) {
 
 	response := accumulator.Response()
 	assert.Equal(t, "Hi there", response.Message().Text())
-	assert.Equal(t, "stop", response.StopReason)
+	assert.Equal(t, "end_turn", response.StopReason)
 }
 
 // TestStreamIteratorToolUseWithTrailingUsage verifies that tool-use streams
@@ -164,7 +185,7 @@ func TestStreamIteratorTerminatesWithoutTrailingUsage(t *testing.T) {
 
 	response := accumulator.Response()
 	assert.Equal(t, "Hi", response.Message().Text())
-	assert.Equal(t, "stop", response.StopReason)
+	assert.Equal(t, "end_turn", response.StopReason)
 }
 
 // TestStreamIteratorUsageOnFinishChunk verifies that providers which include
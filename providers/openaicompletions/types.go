@@ -35,10 +35,12 @@ type Request struct {
 	StreamOptions       *StreamOptions  `json:"stream_options,omitempty"`
 	Tools               []Tool          `json:"tools,omitempty"`
 	ToolChoice          any             `json:"tool_choice,omitempty"`
+	ParallelToolCalls   *bool           `json:"parallel_tool_calls,omitempty"`
 	PresencePenalty     *float64        `json:"presence_penalty,omitempty"`  // -2 to 2, default 0
 	FrequencyPenalty    *float64        `json:"frequency_penalty,omitempty"` // -2 to 2, default 0
 	ReasoningEffort     ReasoningEffort `json:"reasoning_effort,omitempty"`  // supported reasoning models only
 	ReasoningFormat     string          `json:"reasoning_format,omitempty"`  // groq only?
+	ResponseFormat      any             `json:"response_format,omitempty"`
 }
 
 type Message struct {
@@ -54,6 +56,9 @@ type Message struct {
 	Name         string        `json:"name,omitempty"`
 	ToolCallID   string        `json:"tool_call_id,omitempty"`
 	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
+	// Reasoning is the model's reasoning text, returned by some compatible
+	// servers alongside the response content. It is never sent in requests.
+	Reasoning string `json:"-"`
 }
 
 func (m Message) MarshalJSON() ([]byte, error) {
@@ -79,11 +84,13 @@ func (m *Message) UnmarshalJSON(data []byte) error {
 		Name       string          `json:"name"`
 		ToolCallID string          `json:"tool_call_id"`
 		ToolCalls  []ToolCall      `json:"tool_calls"`
+		Reasoning  string          `json:"reasoning"`
 	}
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
 	m.Role = aux.Role
+	m.Reasoning = aux.Reasoning
 	m.Name = aux.Name
 	m.ToolCallID = aux.ToolCallID
 	m.ToolCalls = aux.ToolCalls
@@ -157,12 +164,24 @@ type ToolCall struct {
 }
 
 type Response struct {
-	ID      string   `json:"id"`
-	Object  string   `json:"object"`
-	Created int64    `json:"created"`
-	Model   string   `json:"model"`
-	Choices []Choice `json:"choices"`
-	Usage   Usage    `json:"usage"`
+	ID            string         `json:"id"`
+	Object        string         `json:"object"`
+	Created       int64          `json:"created"`
+	Model         string         `json:"model"`
+	Choices       []Choice       `json:"choices"`
+	Usage         Usage          `json:"usage"`
+	Citations     []string       `json:"citations,omitempty"`
+	SearchResults []SearchResult `json:"search_results,omitempty"`
+}
+
+// SearchResult is a web page an online model such as Perplexity's Sonar
+// consulted. Servers that send them list one per entry of citations, in the
+// same order.
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Date    string `json:"date,omitempty"`
+	Snippet string `json:"snippet,omitempty"`
 }
 
 type Choice struct {
@@ -226,6 +245,8 @@ type StreamResponse struct {
 	SystemFingerprint string         `json:"system_fingerprint"` // fp_eb9dce56a8
 	Choices           []StreamChoice `json:"choices"`
 	Usage             Usage          `json:"usage,omitempty"`
+	Citations         []string       `json:"citations,omitempty"`
+	SearchResults     []SearchResult `json:"search_results,omitempty"`
 }
 
 type StreamChoice struct {
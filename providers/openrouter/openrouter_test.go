@@ -76,3 +76,11 @@ func TestGetAPIKey(t *testing.T) {
 		assert.Equal(t, "", key)
 	})
 }
+
+func TestMatchModel(t *testing.T) {
+	assert.True(t, matchModel("meta-llama/llama-3-70b"))
+	assert.True(t, matchModel("meta-llama/llama-3-8b-instruct:free"))
+	assert.False(t, matchModel("gpt-5"))
+	// Versioned Replicate models are left to the Replicate provider
+	assert.False(t, matchModel("meta/llama-2-70b:02e509c789964a7ea8736978a43525956ef40397be9033abf9fd2badfe68c9e3"))
+}
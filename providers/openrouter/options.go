@@ -3,6 +3,8 @@ package openrouter
 import (
 	"net/http"
 	"time"
+
+	"github.com/deepnoodle-ai/dive/providers"
 )
 
 // Option is a function that configures the Provider
@@ -29,6 +31,21 @@ func WithClient(client *http.Client) Option {
 	}
 }
 
+// WithTransport sets the HTTP transport used for all API requests. It
+// replaces the HTTP client with one that has no overall timeout, so bound
+// requests with context deadlines instead. See providers.NewTransportClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(p *Provider) {
+		p.client = providers.NewTransportClient(transport)
+	}
+}
+
+// WithStreamingTransport uses a transport tuned for long streaming responses,
+// such as extended reasoning. See providers.NewStreamingTransport.
+func WithStreamingTransport() Option {
+	return WithTransport(providers.NewStreamingTransport())
+}
+
 // WithMaxTokens sets the maximum number of tokens to generate
 func WithMaxTokens(maxTokens int) Option {
 	return func(p *Provider) {
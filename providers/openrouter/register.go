@@ -1,6 +1,9 @@
 package openrouter
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/dive/providers"
 )
@@ -8,12 +11,25 @@ import (
 func init() {
 	// Models with "/" are OpenRouter format (e.g., "openai/gpt-4", "google/gemini-pro")
 	providers.Register(providers.ProviderEntry{
-		Name:    "openrouter",
-		Match:   providers.ContainsMatcher("/"),
-		Factory: factory,
+		Name:             "openrouter",
+		Match:            matchModel,
+		Factory:          factory,
+		APIKeyEnv:        []string{"OPENROUTER_API_KEY"},
+		HealthCheckModel: ModelGPT54Mini,
 	})
 }
 
+// versionHash matches the version suffix of a Replicate model,
+// "owner/model:version", which is a 64 character hex hash. OpenRouter
+// variants such as ":free" never look like one.
+var versionHash = regexp.MustCompile(`:[0-9a-f]{64}$`)
+
+// matchModel matches model IDs with a "/", leaving versioned Replicate
+// models to the Replicate provider, which registers after this one.
+func matchModel(model string) bool {
+	return strings.Contains(model, "/") && !versionHash.MatchString(model)
+}
+
 func factory(model, endpoint string) llm.LLM {
 	opts := []Option{WithModel(model)}
 	if endpoint != "" {
@@ -0,0 +1,20 @@
+package perplexity
+
+const (
+	// ModelSonar is Perplexity's lightweight search-grounded model.
+	ModelSonar = "sonar"
+
+	// ModelSonarPro is Perplexity's advanced search model for complex queries.
+	ModelSonarPro = "sonar-pro"
+
+	// ModelSonarReasoning is Perplexity's search model with step-by-step
+	// reasoning.
+	ModelSonarReasoning = "sonar-reasoning"
+
+	// ModelSonarReasoningPro is the higher-capacity version of
+	// ModelSonarReasoning.
+	ModelSonarReasoningPro = "sonar-reasoning-pro"
+
+	// ModelSonarDeepResearch runs multi-step research and writes a report.
+	ModelSonarDeepResearch = "sonar-deep-research"
+)
@@ -0,0 +1,73 @@
+package perplexity
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	openaic "github.com/deepnoodle-ai/dive/providers/openaicompletions"
+)
+
+var (
+	DefaultModel         = ModelSonar
+	DefaultEndpoint      = "https://api.perplexity.ai/chat/completions"
+	DefaultMaxTokens     = 8192
+	DefaultMaxRetries    = openaic.DefaultMaxRetries
+	DefaultRetryBaseWait = openaic.DefaultRetryBaseWait
+	DefaultClient        = &http.Client{Timeout: 300 * time.Second}
+)
+
+var _ llm.StreamingLLM = &Provider{}
+
+// Provider implements the Perplexity API, which is compatible with the
+// OpenAI Chat Completions API. The sources that Sonar models search are
+// attached to the answer's llm.TextContent as llm.CitationContent, in the
+// order of the [1], [2], ... markers in the answer text. Use
+// Response.Citations to list them.
+type Provider struct {
+	apiKey        string
+	endpoint      string
+	model         string
+	maxTokens     int
+	maxRetries    int
+	retryBaseWait time.Duration
+	client        *http.Client
+
+	// Embedded OpenAI completions provider
+	*openaic.Provider
+}
+
+// New creates a new Perplexity provider with the given options.
+func New(opts ...Option) *Provider {
+	p := &Provider{
+		apiKey:        os.Getenv("PERPLEXITY_API_KEY"),
+		endpoint:      DefaultEndpoint,
+		client:        DefaultClient,
+		model:         DefaultModel,
+		maxTokens:     DefaultMaxTokens,
+		maxRetries:    DefaultMaxRetries,
+		retryBaseWait: DefaultRetryBaseWait,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	// Pass the options through to the wrapped OpenAI provider
+	p.Provider = openaic.New(
+		openaic.WithName("perplexity"),
+		openaic.WithAPIKey(p.apiKey),
+		openaic.WithClient(p.client),
+		openaic.WithEndpoint(p.endpoint),
+		openaic.WithMaxTokens(p.maxTokens),
+		openaic.WithMaxRetries(p.maxRetries),
+		openaic.WithBaseWait(p.retryBaseWait),
+		openaic.WithModel(p.model),
+		openaic.WithSystemRole("system"),
+	)
+	return p
+}
+
+func (p *Provider) Name() string {
+	return "perplexity"
+}
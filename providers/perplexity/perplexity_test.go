@@ -0,0 +1,100 @@
+package perplexity
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(WithEndpoint(server.URL), WithAPIKey("test-key"), WithMaxRetries(0))
+}
+
+func TestGenerateCitations(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"resp_1","object":"chat.completion","model":"sonar",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"Go 1.25 shipped in August [1][2]."},"finish_reason":"stop"}],
+			"citations":["https://go.dev/blog/go1.25","https://go.dev/doc/go1.25"],
+			"search_results":[{"title":"Go 1.25 is released","url":"https://go.dev/blog/go1.25","date":"2025-08-12","snippet":"Today the Go team released Go 1.25."}],
+			"usage":{"prompt_tokens":8,"completion_tokens":12,"total_tokens":20}}`)
+	})
+
+	response, err := p.Generate(context.Background(),
+		llm.WithModel(ModelSonar),
+		llm.WithUserTextMessage("When did Go 1.25 ship?"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "Go 1.25 shipped in August [1][2].", response.Message().Text())
+
+	citations := response.Citations()
+	assert.Len(t, citations, 2)
+	assert.Equal(t, "https://go.dev/blog/go1.25", citations[0].URL)
+	assert.Equal(t, "Go 1.25 is released", citations[0].Title)
+	assert.Equal(t, "Today the Go team released Go 1.25.", citations[0].Snippet)
+	assert.Equal(t, "https://go.dev/doc/go1.25", citations[1].URL)
+	assert.Equal(t, "", citations[1].Title)
+}
+
+func TestStreamCitations(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data: {"id":"resp_1","object":"chat.completion.chunk","model":"sonar","citations":["https://go.dev/blog/go1.25"],"choices":[{"index":0,"delta":{"role":"assistant","content":"In August"},"finish_reason":null}]}
+
+data: {"id":"resp_1","object":"chat.completion.chunk","model":"sonar","citations":["https://go.dev/blog/go1.25"],"choices":[{"index":0,"delta":{"content":" [1]."},"finish_reason":null}]}
+
+data: {"id":"resp_1","object":"chat.completion.chunk","model":"sonar","citations":["https://go.dev/blog/go1.25"],"choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":8,"completion_tokens":4,"total_tokens":12}}
+
+data: [DONE]
+
+`)
+	})
+
+	iterator, err := p.Stream(context.Background(),
+		llm.WithModel(ModelSonar),
+		llm.WithUserTextMessage("When did Go 1.25 ship?"),
+	)
+	assert.NoError(t, err)
+	defer iterator.Close()
+
+	accumulator := llm.NewResponseAccumulator()
+	for iterator.Next() {
+		assert.NoError(t, accumulator.AddEvent(iterator.Event()))
+	}
+	assert.NoError(t, iterator.Err())
+
+	response := accumulator.Response()
+	assert.Equal(t, "In August [1].", response.Message().Text())
+	citations := response.Citations()
+	assert.Len(t, citations, 1)
+	assert.Equal(t, "https://go.dev/blog/go1.25", citations[0].URL)
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "perplexity", New().Name())
+}
+
+func TestRegistryRequiresAPIKey(t *testing.T) {
+	t.Setenv("PERPLEXITY_API_KEY", "")
+	_, ok := providers.CreateModel(ModelSonarPro, "").(*Provider)
+	assert.False(t, ok)
+
+	t.Setenv("PERPLEXITY_API_KEY", "test-key")
+	model, ok := providers.CreateModel(ModelSonarPro, "").(*Provider)
+	assert.True(t, ok)
+	assert.Equal(t, ModelSonarPro, model.model)
+	assert.Equal(t, "test-key", model.apiKey)
+
+	_, ok = providers.CreateModel("llama-3.1-sonar-large-128k-online", "").(*Provider)
+	assert.True(t, ok)
+}
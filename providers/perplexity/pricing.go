@@ -0,0 +1,43 @@
+package perplexity
+
+import "github.com/deepnoodle-ai/dive/llm"
+
+// TextModelPricing contains token pricing for Perplexity Sonar models. It
+// doesn't include Perplexity's per-request search fees.
+var TextModelPricing = map[string]llm.PricingInfo{
+	ModelSonar: {
+		Model:       ModelSonar,
+		InputPrice:  1.00,
+		OutputPrice: 1.00,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelSonarPro: {
+		Model:       ModelSonarPro,
+		InputPrice:  3.00,
+		OutputPrice: 15.00,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelSonarReasoning: {
+		Model:       ModelSonarReasoning,
+		InputPrice:  1.00,
+		OutputPrice: 5.00,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelSonarReasoningPro: {
+		Model:       ModelSonarReasoningPro,
+		InputPrice:  2.00,
+		OutputPrice: 8.00,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+	ModelSonarDeepResearch: {
+		Model:       ModelSonarDeepResearch,
+		InputPrice:  2.00,
+		OutputPrice: 8.00,
+		Currency:    "USD",
+		UpdatedAt:   "2026-10-01",
+	},
+}
@@ -0,0 +1,24 @@
+package perplexity
+
+import (
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+func init() {
+	providers.Register(providers.ProviderEntry{
+		Name:             "perplexity",
+		Match:            providers.EnvMatcher("PERPLEXITY_API_KEY", providers.PrefixesMatcher("sonar", "llama-3.1-sonar-")),
+		Factory:          factory,
+		APIKeyEnv:        []string{"PERPLEXITY_API_KEY"},
+		HealthCheckModel: ModelSonar,
+	})
+}
+
+func factory(model, endpoint string) llm.LLM {
+	opts := []Option{WithModel(model)}
+	if endpoint != "" {
+		opts = append(opts, WithEndpoint(endpoint))
+	}
+	return New(opts...)
+}
@@ -6,10 +6,13 @@ import (
 	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/dive/providers"
 	"github.com/deepnoodle-ai/dive/providers/anthropic"
+	"github.com/deepnoodle-ai/dive/providers/deepseek"
+	"github.com/deepnoodle-ai/dive/providers/fireworks"
 	"github.com/deepnoodle-ai/dive/providers/mistral"
 	"github.com/deepnoodle-ai/dive/providers/ollama"
 	"github.com/deepnoodle-ai/dive/providers/openaicompletions"
 	"github.com/deepnoodle-ai/dive/providers/openrouter"
+	"github.com/deepnoodle-ai/dive/providers/perplexity"
 	"github.com/deepnoodle-ai/wonton/assert"
 )
 
@@ -34,6 +37,9 @@ func TestProvidersRegisterPricing(t *testing.T) {
 	assertRegistered(t, "openaicompletions", openaicompletions.TextModelPricing)
 	assertRegistered(t, "mistral", mistral.TextModelPricing)
 	assertRegistered(t, "openrouter", openrouter.TextModelPricing)
+	assertRegistered(t, "deepseek", deepseek.TextModelPricing)
+	assertRegistered(t, "perplexity", perplexity.TextModelPricing)
+	assertRegistered(t, "fireworks", fireworks.TextModelPricing)
 	// Ollama runs locally; entries (if any) are free.
 	assertRegistered(t, "ollama", ollama.TextModelPricing)
 }
@@ -54,3 +60,13 @@ func TestPopulateCostForMistralModel(t *testing.T) {
 	assert.NotNil(t, u.Cost, "cost should populate via the registry resolver")
 	assert.True(t, u.Cost.Total > 0, "priced model should yield a positive cost")
 }
+
+func TestFireworksRegisteredBeforeOpenRouter(t *testing.T) {
+	t.Setenv("FIREWORKS_API_KEY", "test-key")
+	model := providers.CreateModel(fireworks.ModelKimiK2Instruct, "")
+	assert.Equal(t, "fireworks", model.Name())
+
+	t.Setenv("FIREWORKS_API_KEY", "")
+	model = providers.CreateModel(fireworks.ModelKimiK2Instruct, "")
+	assert.Equal(t, "openrouter", model.Name())
+}
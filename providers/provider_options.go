@@ -0,0 +1,34 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// ApplyProviderOptions merges the provider options addressed to the given
+// namespaces (see llm.WithProviderOption) into a marshaled JSON request body.
+// Each option is written as a top-level field, overriding any value Dive set
+// for the same field. The body is returned unchanged when no options apply.
+func ApplyProviderOptions(body []byte, config *llm.Config, namespaces []string, known []llm.ProviderOptionKey) ([]byte, error) {
+	params, err := config.ResolveProviderOptions(namespaces, known)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) == 0 {
+		return body, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("error applying provider options: %w", err)
+	}
+	for name, value := range params {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling provider option %q: %w", name, err)
+		}
+		fields[name] = data
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,36 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestApplyProviderOptions(t *testing.T) {
+	config := &llm.Config{}
+	config.Apply(
+		llm.WithProviderOption("test:top_k", 3),
+		llm.WithProviderOption("test:model", "override"),
+		llm.WithProviderOption("other:seed", 1),
+	)
+	body, err := ApplyProviderOptions([]byte(`{"model":"m1","max_tokens":10}`),
+		config, []string{"test"}, []llm.ProviderOptionKey{"test:top_k"})
+	assert.NoError(t, err)
+
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(body, &fields))
+	assert.Equal(t, float64(3), fields["top_k"])
+	assert.Equal(t, "override", fields["model"])
+	assert.Equal(t, float64(10), fields["max_tokens"])
+	_, hasSeed := fields["seed"]
+	assert.False(t, hasSeed)
+}
+
+func TestApplyProviderOptionsNoOptions(t *testing.T) {
+	body := []byte(`{"model":"m1"}`)
+	out, err := ApplyProviderOptions(body, &llm.Config{}, []string{"test"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, string(body), string(out))
+}
@@ -3,17 +3,30 @@ package providers
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/wonton/retry"
 )
 
-// ProviderError represents an error returned by an LLM provider API.
+// ProviderError represents an error returned by an LLM provider API. It
+// matches one of the Err* errors of this package with errors.Is when the
+// response says what went wrong.
 type ProviderError struct {
 	statusCode int
 	body       string
+	retryAfter time.Duration
+	kind       error
+	code       string
+	message    string
+	requestID  string
 }
 
 func (e *ProviderError) Error() string {
+	if e.requestID != "" {
+		return fmt.Sprintf("provider api error (status %d, request %s): %s", e.statusCode, e.requestID, e.body)
+	}
 	return fmt.Sprintf("provider api error (status %d): %s", e.statusCode, e.body)
 }
 
@@ -21,16 +34,100 @@ func (e *ProviderError) StatusCode() int {
 	return e.statusCode
 }
 
-// NewError creates a new ProviderError. Non-retryable status codes are wrapped
-// with retry.MarkPermanent.
+// RetryAfter returns how long the provider asked callers to wait before
+// retrying, from the response's Retry-After header, or zero if it didn't say.
+func (e *ProviderError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Code returns the provider's error code or type, such as
+// "context_length_exceeded", or "" if the response had none.
+func (e *ProviderError) Code() string {
+	return e.code
+}
+
+// Message returns the provider's description of the error, or the raw
+// response body when it couldn't be parsed.
+func (e *ProviderError) Message() string {
+	if e.message == "" {
+		return e.body
+	}
+	return e.message
+}
+
+// RequestID returns the ID the provider assigned the request, or "" if the
+// response didn't include one.
+func (e *ProviderError) RequestID() string {
+	return e.requestID
+}
+
+// Is reports whether the error matches target: the error's kind, such as
+// ErrContextLengthExceeded, or for a 413 response, llm.ErrRequestTooLarge.
+func (e *ProviderError) Is(target error) bool {
+	if e.kind != nil && target == e.kind {
+		return true
+	}
+	return target == llm.ErrRequestTooLarge && e.statusCode == http.StatusRequestEntityTooLarge
+}
+
+// NewError creates a new ProviderError, classifying it from the status code
+// and any JSON error in body. Non-retryable status codes and quota errors
+// are wrapped with retry.MarkPermanent.
 func NewError(statusCode int, body string) error {
-	err := &ProviderError{statusCode: statusCode, body: body}
-	if !shouldRetry(statusCode) {
+	return NewDetailedError(statusCode, body, ErrorDetails{})
+}
+
+// NewResponseError creates a ProviderError for a non-success HTTP response,
+// like NewError, and records the details in the response's headers, such as
+// the Retry-After delay and request ID. See ResponseErrorDetails.
+func NewResponseError(resp *http.Response, body string) error {
+	return NewDetailedError(resp.StatusCode, body, ResponseErrorDetails(resp))
+}
+
+// NewDetailedError creates a ProviderError from what a provider understood
+// of an error response, such as the fields of an SDK's error type. Empty
+// details are filled in from body, and the error is classified from them
+// unless details.Kind is set. Non-retryable status codes and
+// ErrQuotaExceeded errors are wrapped with retry.MarkPermanent.
+func NewDetailedError(statusCode int, body string, details ErrorDetails) error {
+	code, message := parseErrorBody(body)
+	err := &ProviderError{
+		statusCode: statusCode,
+		body:       body,
+		retryAfter: details.RetryAfter,
+		kind:       details.Kind,
+		code:       firstNonEmpty(details.Code, code),
+		message:    firstNonEmpty(details.Message, message),
+		requestID:  details.RequestID,
+	}
+	if err.kind == nil {
+		err.kind = classifyError(statusCode, err.code, err.Message())
+	}
+	if !shouldRetry(statusCode) || err.kind == ErrQuotaExceeded {
 		return retry.MarkPermanent(err)
 	}
 	return err
 }
 
+// ParseRetryAfter parses a Retry-After header value, given either as a number
+// of seconds or as an HTTP date relative to now. It returns zero for an empty
+// or invalid value or a date in the past.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
 // shouldRetry determines if the given status code should trigger a retry
 func shouldRetry(statusCode int) bool {
 	return statusCode == http.StatusTooManyRequests || // 429
@@ -1,8 +1,12 @@
 package providers
 
 import (
+	"errors"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/wonton/assert"
 	"github.com/deepnoodle-ai/wonton/retry"
 )
@@ -36,3 +40,31 @@ func TestNewErrorMarksPermanent(t *testing.T) {
 	assert.True(t, retry.IsPermanent(NewError(400, "bad request")))
 	assert.False(t, retry.IsPermanent(NewError(529, "overloaded")))
 }
+
+func TestProviderErrorRequestTooLarge(t *testing.T) {
+	assert.True(t, errors.Is(NewError(413, "request_too_large"), llm.ErrRequestTooLarge))
+	assert.False(t, errors.Is(NewError(400, "invalid_request_error"), llm.ErrRequestTooLarge))
+}
+
+func TestNewResponseErrorRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: 429, Header: http.Header{"Retry-After": []string{"7"}}}
+	err := NewResponseError(resp, "rate limited")
+	assert.False(t, retry.IsPermanent(err))
+	var providerErr *ProviderError
+	assert.True(t, errors.As(err, &providerErr))
+	assert.Equal(t, 429, providerErr.StatusCode())
+	assert.Equal(t, 7*time.Second, providerErr.RetryAfter())
+
+	resp = &http.Response{StatusCode: 401, Header: http.Header{}}
+	assert.True(t, retry.IsPermanent(NewResponseError(resp, "unauthorized")))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, 30*time.Second, ParseRetryAfter("30", now))
+	assert.Equal(t, 90*time.Second, ParseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("-1", now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("soon", now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("", now))
+}
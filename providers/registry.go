@@ -19,6 +19,16 @@ type ProviderEntry struct {
 	Name    string
 	Match   ModelMatcher
 	Factory ProviderFactory
+
+	// APIKeyEnv lists the environment variables that can hold the
+	// provider's API key. Alias resolution treats the provider as available
+	// when any of them is set. Leave empty for providers that need no key,
+	// such as local servers; they are always considered available.
+	APIKeyEnv []string
+
+	// HealthCheckModel is a small, cheap model that HealthCheckAll uses to
+	// probe the provider. Entries without one are not checked.
+	HealthCheckModel string
 }
 
 // Registry manages model-to-provider mappings.
@@ -28,6 +38,7 @@ type Registry struct {
 	mu       sync.RWMutex
 	entries  []ProviderEntry
 	fallback ProviderFactory
+	aliases  map[string][]string
 }
 
 // Register adds a provider entry to the registry.
@@ -47,7 +58,7 @@ func (r *Registry) SetFallback(factory ProviderFactory) {
 }
 
 // CreateModel returns an LLM provider for the given model name and endpoint.
-// The model string supports an optional "provider/model" syntax to explicitly
+// Model aliases (see RegisterAlias) are resolved first. The model string supports an optional "provider/model" syntax to explicitly
 // select a provider (e.g. "ollama/mistral:7b"). Without a provider prefix, it
 // iterates through registered entries in order and returns the first match.
 // If no entry matches and a fallback is set, the fallback is used.
@@ -64,6 +75,21 @@ func (r *Registry) CreateModel(model, endpoint string) llm.LLM {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if resolved, ok := r.resolveAlias(model); ok {
+		model = resolved
+	}
+	if entry, modelName, ok := r.lookup(model); ok {
+		return entry.Factory(modelName, endpoint)
+	}
+	if r.fallback != nil {
+		return r.fallback(model, endpoint)
+	}
+	return nil
+}
+
+// lookup returns the entry that handles model and the model name to pass to
+// its factory. The caller must hold r.mu.
+func (r *Registry) lookup(model string) (ProviderEntry, string, bool) {
 	// Check for explicit "provider/model" syntax. Only treat the text before
 	// the "/" as a provider selector when it matches a registered provider
 	// name; otherwise fall through to the matcher loop below.
@@ -72,20 +98,17 @@ func (r *Registry) CreateModel(model, endpoint string) llm.LLM {
 		modelName := model[idx+1:]
 		for _, entry := range r.entries {
 			if strings.ToLower(entry.Name) == providerName {
-				return entry.Factory(modelName, endpoint)
+				return entry, modelName, true
 			}
 		}
 	}
 
 	for _, entry := range r.entries {
 		if entry.Match(model) {
-			return entry.Factory(model, endpoint)
+			return entry, model, true
 		}
 	}
-	if r.fallback != nil {
-		return r.fallback(model, endpoint)
-	}
-	return nil
+	return ProviderEntry{}, "", false
 }
 
 // Entries returns a copy of all registered provider entries.
@@ -0,0 +1,13 @@
+package replicate
+
+const (
+	// Meta Llama models
+	ModelLlama4Maverick    = "meta/llama-4-maverick-instruct"
+	ModelLlama4Scout       = "meta/llama-4-scout-instruct"
+	ModelLlama370BInstruct = "meta/meta-llama-3-70b-instruct"
+	ModelLlama38BInstruct  = "meta/meta-llama-3-8b-instruct"
+
+	// DeepSeek models
+	ModelDeepSeekR1 = "deepseek-ai/deepseek-r1"
+	ModelDeepSeekV3 = "deepseek-ai/deepseek-v3"
+)
@@ -0,0 +1,78 @@
+package replicate
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+// Option configures the Replicate provider.
+type Option func(*Provider)
+
+// WithAPIToken sets the Replicate API token.
+func WithAPIToken(apiToken string) Option {
+	return func(p *Provider) {
+		p.apiToken = apiToken
+	}
+}
+
+// WithEndpoint sets the base URL of the API.
+func WithEndpoint(endpoint string) Option {
+	return func(p *Provider) {
+		p.endpoint = endpoint
+	}
+}
+
+// WithClient sets the HTTP client.
+func WithClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// WithTransport sets the HTTP transport used for all API requests. It
+// replaces the HTTP client with one that has no overall timeout, so bound
+// requests with context deadlines instead. See providers.NewTransportClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(p *Provider) {
+		p.client = providers.NewTransportClient(transport)
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens to generate.
+func WithMaxTokens(maxTokens int) Option {
+	return func(p *Provider) {
+		p.maxTokens = maxTokens
+	}
+}
+
+// WithModel sets the model, as "owner/model" for official models or
+// "owner/model:version" to pin a version.
+func WithModel(model string) Option {
+	return func(p *Provider) {
+		p.model = model
+	}
+}
+
+// WithPollInterval sets how often Generate checks on a prediction that
+// hasn't finished.
+func WithPollInterval(interval time.Duration) Option {
+	return func(p *Provider) {
+		p.pollInterval = interval
+	}
+}
+
+// WithMaxRetries sets the maximum number of retry attempts.
+func WithMaxRetries(maxRetries int) Option {
+	return func(p *Provider) {
+		p.maxRetries = maxRetries
+	}
+}
+
+// WithBaseWait sets the base wait duration between retries.
+func WithBaseWait(baseWait time.Duration) Option {
+	return func(p *Provider) {
+		p.retryBaseWait = baseWait
+	}
+}
@@ -0,0 +1,27 @@
+package replicate
+
+import (
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+)
+
+func init() {
+	// Versioned models ("owner/model:version") are Replicate's. Select
+	// official models explicitly, as "replicate/owner/model", since the
+	// bare form is shared with OpenRouter.
+	providers.Register(providers.ProviderEntry{
+		Name:             ProviderName,
+		Match:            IsVersionedModel,
+		Factory:          factory,
+		APIKeyEnv:        []string{"REPLICATE_API_TOKEN"},
+		HealthCheckModel: ModelLlama38BInstruct,
+	})
+}
+
+func factory(model, endpoint string) llm.LLM {
+	opts := []Option{WithModel(model)}
+	if endpoint != "" {
+		opts = append(opts, WithEndpoint(endpoint))
+	}
+	return New(opts...)
+}
@@ -0,0 +1,468 @@
+// Package replicate provides an LLM provider for language models hosted on
+// Replicate, using its asynchronous predictions API.
+//
+// Models are named "owner/model" for official models, which always run the
+// latest version, or "owner/model:version" to pin a version by its hash.
+// Replicate models take a prompt rather than messages, so the conversation
+// is rendered as a transcript. Tools and non-text content aren't supported.
+package replicate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/retry"
+)
+
+const ProviderName = "replicate"
+
+var (
+	DefaultModel         = ModelLlama370BInstruct
+	DefaultEndpoint      = "https://api.replicate.com/v1"
+	DefaultMaxTokens     = 4000
+	DefaultPollInterval  = time.Second
+	DefaultClient        = &http.Client{Timeout: 300 * time.Second}
+	DefaultMaxRetries    = 3
+	DefaultRetryBaseWait = 2 * time.Second
+)
+
+var (
+	_ llm.StreamingLLM        = &Provider{}
+	_ providers.HealthChecker = &Provider{}
+)
+
+// Provider implements the Replicate LLM provider.
+type Provider struct {
+	client        *http.Client
+	apiToken      string
+	endpoint      string
+	model         string
+	maxTokens     int
+	pollInterval  time.Duration
+	maxRetries    int
+	retryBaseWait time.Duration
+}
+
+// New creates a new Replicate provider with the given options. The API
+// token is read from REPLICATE_API_TOKEN by default.
+func New(opts ...Option) *Provider {
+	p := &Provider{
+		apiToken:      os.Getenv("REPLICATE_API_TOKEN"),
+		endpoint:      DefaultEndpoint,
+		client:        DefaultClient,
+		model:         DefaultModel,
+		maxTokens:     DefaultMaxTokens,
+		pollInterval:  DefaultPollInterval,
+		maxRetries:    DefaultMaxRetries,
+		retryBaseWait: DefaultRetryBaseWait,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// versionedModel matches a model pinned to a version, "owner/model:version",
+// where the version is the 64 character hex hash Replicate assigns.
+var versionedModel = regexp.MustCompile(`^[\w.-]+/[\w.-]+:[0-9a-f]{64}$`)
+
+// IsVersionedModel reports whether model names a model pinned to a version,
+// as in "owner/model:version".
+func IsVersionedModel(model string) bool {
+	return versionedModel.MatchString(model)
+}
+
+// Generate creates a prediction and waits for it to finish. The API is
+// asked to hold the create request until the prediction is done, for up to
+// a minute, and the prediction is polled after that. If ctx ends first, the
+// prediction is canceled.
+func (p *Provider) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	config := &llm.Config{}
+	config.Apply(opts...)
+
+	model, url, body, err := p.buildRequest(config, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.BeforeGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	var prediction Prediction
+	err = retry.DoSimple(ctx, func() error {
+		return p.send(ctx, http.MethodPost, url, body, config, true, &prediction)
+	}, p.retryOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.wait(ctx, &prediction, config); err != nil {
+		return nil, err
+	}
+
+	response := convertResponse(&prediction, model)
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.AfterGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+		Response: &llm.HookResponseContext{
+			Response: response,
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Stream creates a prediction and streams its output. Models that don't
+// stream are waited on like Generate, and their output is sent as one
+// text delta.
+func (p *Provider) Stream(ctx context.Context, opts ...llm.Option) (llm.StreamIterator, error) {
+	config := &llm.Config{}
+	config.Apply(opts...)
+
+	model, url, body, err := p.buildRequest(config, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.FireHooks(ctx, &llm.HookContext{
+		Type: llm.BeforeGenerate,
+		Request: &llm.HookRequestContext{
+			Messages: config.Messages,
+			Config:   config,
+			Body:     body,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	stream := providers.NewContextRetryingStreamIterator(ctx, providers.StreamRetryConfig{
+		Provider:      p.Name(),
+		MaxRetries:    p.maxRetries,
+		RetryBaseWait: p.retryBaseWait,
+		Logger:        config.Logger,
+	}, func(ctx context.Context) (llm.StreamIterator, error) {
+		var prediction Prediction
+		if err := p.send(ctx, http.MethodPost, url, body, config, false, &prediction); err != nil {
+			return nil, err
+		}
+		if prediction.URLs == nil || prediction.URLs.Stream == "" {
+			if err := p.wait(ctx, &prediction, config); err != nil {
+				return nil, err
+			}
+			return newCompletedStreamIterator(&prediction, model), nil
+		}
+		req, err := p.newRequest(ctx, http.MethodGet, prediction.URLs.Stream, nil, config)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("accept", "text/event-stream")
+		req.Header.Set("cache-control", "no-store")
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error making request: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, providers.NewResponseError(resp, string(body))
+		}
+		// Streams don't report usage, so fetch the finished prediction
+		finish := func() (*Prediction, error) {
+			var final Prediction
+			err := p.send(ctx, http.MethodGet, p.predictionURL(&prediction), nil, config, false, &final)
+			return &final, err
+		}
+		return newStreamIterator(resp.Body, prediction.ID, model, config.SSECallback, finish), nil
+	})
+	return stream, nil
+}
+
+// HealthCheck implements providers.HealthChecker by looking up the account
+// the API token belongs to, which runs no model.
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	var account struct {
+		Username string `json:"username"`
+	}
+	return p.send(ctx, http.MethodGet, p.endpoint+"/account", nil, &llm.Config{}, false, &account)
+}
+
+func (p *Provider) retryOptions() []retry.Option {
+	return []retry.Option{
+		retry.WithMaxAttempts(p.maxRetries + 1),
+		retry.WithBackoff(p.retryBaseWait, 5*time.Minute),
+		retry.WithRetryIf(retry.SkipPermanent()),
+	}
+}
+
+// buildRequest returns the model, the create prediction URL, and the body
+// of a request for config.
+func (p *Provider) buildRequest(config *llm.Config, stream bool) (string, string, []byte, error) {
+	model := p.model
+	if config.Model != "" {
+		model = config.Model
+	}
+	url, version, err := p.createURL(model)
+	if err != nil {
+		return "", "", nil, err
+	}
+	input, err := buildInput(config, p.maxTokens)
+	if err != nil {
+		return "", "", nil, err
+	}
+	body, err := json.Marshal(&Request{Version: version, Input: input, Stream: stream})
+	if err != nil {
+		return "", "", nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+	return model, url, body, nil
+}
+
+// createURL returns the URL that creates predictions of model, and the
+// version to send in the body when model is pinned to one.
+func (p *Provider) createURL(model string) (string, string, error) {
+	if IsVersionedModel(model) {
+		_, version, _ := strings.Cut(model, ":")
+		return p.endpoint + "/predictions", version, nil
+	}
+	owner, name, ok := strings.Cut(model, "/")
+	if !ok || owner == "" || name == "" || strings.ContainsAny(name, "/:") {
+		return "", "", fmt.Errorf("replicate: invalid model %q: want owner/model or owner/model:version", model)
+	}
+	return p.endpoint + "/models/" + owner + "/" + name + "/predictions", "", nil
+}
+
+// buildInput returns the model input for config, with any provider options
+// addressed to this provider merged in, so model-specific inputs such as
+// top_p can be set with llm.WithProviderOption.
+func buildInput(config *llm.Config, maxTokens int) (json.RawMessage, error) {
+	if len(config.Tools) > 0 && (config.ToolChoice == nil || config.ToolChoice.Type != llm.ToolChoiceTypeNone) {
+		return nil, fmt.Errorf("replicate: tools: %w", llm.ErrNotSupported)
+	}
+	instructions, messages := llm.FoldDeveloperMessages(config.Messages)
+	prompt, err := renderPrompt(messages)
+	if err != nil {
+		return nil, err
+	}
+	input := map[string]any{"prompt": prompt, "max_tokens": maxTokens}
+	if config.MaxTokens != nil {
+		input["max_tokens"] = *config.MaxTokens
+	}
+	if system := strings.Join(nonEmpty(config.SystemPrompt, instructions), "\n\n"); system != "" {
+		input["system_prompt"] = system
+	}
+	if config.Temperature != nil {
+		input["temperature"] = *config.Temperature
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling input: %w", err)
+	}
+	return providers.ApplyProviderOptions(body, config, []string{ProviderName}, nil)
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// renderPrompt renders messages as a prompt. A single user message is sent
+// as is. Longer conversations become a transcript of "User:" and
+// "Assistant:" turns that ends with an "Assistant:" cue for the reply.
+func renderPrompt(messages []*llm.Message) (string, error) {
+	if len(messages) == 0 {
+		return "", fmt.Errorf("replicate: no messages")
+	}
+	turns := make([]string, len(messages))
+	for i, message := range messages {
+		var text strings.Builder
+		for _, content := range message.Content {
+			c, ok := content.(*llm.TextContent)
+			if !ok {
+				return "", fmt.Errorf("replicate: unsupported content type %s in message %d: %w",
+					content.Type(), i, llm.ErrNotSupported)
+			}
+			text.WriteString(c.Text)
+		}
+		switch message.Role {
+		case llm.User:
+			turns[i] = "User: " + text.String()
+		case llm.Assistant:
+			turns[i] = "Assistant: " + text.String()
+		default:
+			return "", fmt.Errorf("replicate: unsupported message role %q", message.Role)
+		}
+	}
+	if len(messages) == 1 && messages[0].Role == llm.User {
+		return strings.TrimPrefix(turns[0], "User: "), nil
+	}
+	if messages[len(messages)-1].Role == llm.User {
+		turns = append(turns, "Assistant:")
+	}
+	return strings.Join(turns, "\n\n"), nil
+}
+
+// wait polls prediction until it finishes, and returns an error if it
+// failed or was canceled. If ctx ends first, the prediction is canceled.
+func (p *Provider) wait(ctx context.Context, prediction *Prediction, config *llm.Config) error {
+	for !prediction.Done() {
+		select {
+		case <-ctx.Done():
+			p.cancel(ctx, prediction, config)
+			return ctx.Err()
+		case <-time.After(p.pollInterval):
+		}
+		err := retry.DoSimple(ctx, func() error {
+			return p.send(ctx, http.MethodGet, p.predictionURL(prediction), nil, config, false, prediction)
+		}, p.retryOptions()...)
+		if err != nil {
+			if ctx.Err() != nil {
+				p.cancel(ctx, prediction, config)
+			}
+			return err
+		}
+	}
+	switch prediction.Status {
+	case StatusFailed:
+		return fmt.Errorf("replicate: prediction %s failed: %v", prediction.ID, prediction.Error)
+	case StatusCanceled:
+		return fmt.Errorf("replicate: prediction %s was canceled", prediction.ID)
+	}
+	return nil
+}
+
+// cancel asks Replicate to stop a prediction whose caller went away, so it
+// stops running and billing. It is best effort.
+func (p *Provider) cancel(ctx context.Context, prediction *Prediction, config *llm.Config) {
+	url := p.predictionURL(prediction) + "/cancel"
+	if prediction.URLs != nil && prediction.URLs.Cancel != "" {
+		url = prediction.URLs.Cancel
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	var canceled Prediction
+	p.send(ctx, http.MethodPost, url, nil, config, false, &canceled)
+}
+
+func (p *Provider) predictionURL(prediction *Prediction) string {
+	if prediction.URLs != nil && prediction.URLs.Get != "" {
+		return prediction.URLs.Get
+	}
+	return p.endpoint + "/predictions/" + prediction.ID
+}
+
+// send makes one API request and decodes the response into out. wait asks
+// the API to hold a create request until the prediction finishes.
+func (p *Provider) send(ctx context.Context, method, url string, body []byte, config *llm.Config, wait bool, out any) error {
+	req, err := p.newRequest(ctx, method, url, body, config)
+	if err != nil {
+		return err
+	}
+	if wait {
+		req.Header.Set("prefer", "wait")
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return providers.NewResponseError(resp, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
+
+func (p *Provider) newRequest(ctx context.Context, method, url string, body []byte, config *llm.Config) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("authorization", "Bearer "+p.apiToken)
+	req.Header.Set("accept", "application/json")
+	if body != nil {
+		req.Header.Set("content-type", "application/json")
+	}
+	for key, values := range config.RequestHeaders {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return req, nil
+}
+
+// convertResponse converts a finished prediction. Replicate doesn't say
+// why generation stopped, so the stop reason is always end_turn.
+func convertResponse(prediction *Prediction, model string) *llm.Response {
+	response := &llm.Response{
+		ID:         prediction.ID,
+		Model:      model,
+		Role:       llm.Assistant,
+		Type:       "message",
+		StopReason: llm.StopReasonEndTurn,
+		Usage:      usage(prediction.Metrics),
+	}
+	if text := outputText(prediction.Output); text != "" {
+		response.Content = append(response.Content, &llm.TextContent{Text: text})
+	}
+	return response
+}
+
+// outputText returns the text of a prediction's output. Language models
+// output a list of tokens, which are joined; a string is used as is, and
+// any other output as JSON.
+func outputText(output json.RawMessage) string {
+	if len(output) == 0 || string(output) == "null" {
+		return ""
+	}
+	var tokens []string
+	if err := json.Unmarshal(output, &tokens); err == nil {
+		return strings.Join(tokens, "")
+	}
+	var text string
+	if err := json.Unmarshal(output, &text); err == nil {
+		return text
+	}
+	return string(output)
+}
+
+func usage(metrics *Metrics) llm.Usage {
+	if metrics == nil {
+		return llm.Usage{}
+	}
+	return llm.Usage{InputTokens: metrics.InputTokenCount, OutputTokens: metrics.OutputTokenCount}
+}
@@ -0,0 +1,258 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/providers"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+const testVersion = "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(
+		WithEndpoint(server.URL),
+		WithAPIToken("test-token"),
+		WithMaxRetries(0),
+		WithPollInterval(time.Millisecond),
+	)
+}
+
+func TestGeneratePollsPrediction(t *testing.T) {
+	var request Request
+	var input map[string]any
+	var polls atomic.Int32
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		switch r.Method + " " + r.URL.Path {
+		case "POST /models/meta/meta-llama-3-8b-instruct/predictions":
+			assert.Equal(t, "wait", r.Header.Get("Prefer"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.NoError(t, json.Unmarshal(request.Input, &input))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"pred_1","status":"starting","urls":{"get":%q}}`,
+				"http://"+r.Host+"/predictions/pred_1")
+		case "GET /predictions/pred_1":
+			if polls.Add(1) < 2 {
+				io.WriteString(w, `{"id":"pred_1","status":"processing"}`)
+				return
+			}
+			io.WriteString(w, `{"id":"pred_1","status":"succeeded",
+				"output":["The"," sky"," is"," blue."],
+				"metrics":{"input_token_count":25,"output_token_count":5}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	response, err := p.Generate(context.Background(),
+		llm.WithModel(ModelLlama38BInstruct),
+		llm.WithSystemPrompt("Be brief."),
+		llm.WithMaxTokens(100),
+		llm.WithTemperature(0.5),
+		llm.WithProviderOption("replicate:top_p", 0.9),
+		llm.WithMessages(
+			llm.NewUserTextMessage("Hi"),
+			llm.NewAssistantTextMessage("Hello!"),
+			llm.NewUserTextMessage("What color is the sky?"),
+		),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", request.Version)
+	assert.False(t, request.Stream)
+	assert.Equal(t, "User: Hi\n\nAssistant: Hello!\n\nUser: What color is the sky?\n\nAssistant:", input["prompt"])
+	assert.Equal(t, "Be brief.", input["system_prompt"])
+	assert.Equal(t, float64(100), input["max_tokens"])
+	assert.Equal(t, 0.5, input["temperature"])
+	assert.Equal(t, 0.9, input["top_p"])
+
+	assert.Equal(t, int32(2), polls.Load())
+	assert.Equal(t, "pred_1", response.ID)
+	assert.Equal(t, ModelLlama38BInstruct, response.Model)
+	assert.Equal(t, "The sky is blue.", response.Message().Text())
+	assert.Equal(t, llm.StopReasonEndTurn, response.StopReason)
+	assert.Equal(t, llm.Usage{InputTokens: 25, OutputTokens: 5}, response.Usage)
+}
+
+func TestGenerateVersionedModel(t *testing.T) {
+	var request Request
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/predictions", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		io.WriteString(w, `{"id":"pred_2","status":"succeeded","output":"done"}`)
+	})
+
+	response, err := p.Generate(context.Background(),
+		llm.WithModel("acme/custom-llm:"+testVersion),
+		llm.WithUserTextMessage("Go"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, testVersion, request.Version)
+	assert.Equal(t, "done", response.Message().Text())
+}
+
+func TestGenerateFailedPrediction(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"pred_3","status":"failed","error":"CUDA out of memory"}`)
+	})
+	_, err := p.Generate(context.Background(), llm.WithUserTextMessage("Go"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "CUDA out of memory")
+}
+
+func TestGenerateCancelsPrediction(t *testing.T) {
+	canceled := make(chan struct{})
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /predictions/pred_4/cancel":
+			close(canceled)
+			io.WriteString(w, `{"id":"pred_4","status":"canceled"}`)
+		default:
+			io.WriteString(w, `{"id":"pred_4","status":"processing"}`)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := p.Generate(ctx, llm.WithUserTextMessage("Go"))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("prediction was not canceled")
+	}
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	p := New(WithAPIToken("test-token"))
+	_, err := p.Generate(context.Background(),
+		llm.WithUserTextMessage("Go"),
+		llm.WithTools(llm.NewToolDefinition().WithName("search")),
+	)
+	assert.True(t, errors.Is(err, llm.ErrNotSupported))
+
+	_, err = p.Generate(context.Background(), llm.WithMessages(llm.NewUserMessage(
+		llm.NewImageContent(llm.EncodedData("image/png", "cG5n")),
+	)))
+	assert.True(t, errors.Is(err, llm.ErrNotSupported))
+
+	_, err = p.Generate(context.Background(), llm.WithModel("llama"), llm.WithUserTextMessage("Go"))
+	assert.Error(t, err)
+}
+
+func TestStream(t *testing.T) {
+	var request Request
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /models/meta/meta-llama-3-8b-instruct/predictions":
+			assert.Equal(t, "", r.Header.Get("Prefer"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"pred_5","status":"starting","urls":{"get":%q,"stream":%q}}`,
+				"http://"+r.Host+"/predictions/pred_5", "http://"+r.Host+"/stream/pred_5")
+		case "GET /stream/pred_5":
+			assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "event: output\nid: 1\ndata: Hello\n\n"+
+				"event: output\nid: 2\ndata: , world\ndata: !\n\n"+
+				"event: done\ndata: {}\n\n")
+		case "GET /predictions/pred_5":
+			io.WriteString(w, `{"id":"pred_5","status":"succeeded","metrics":{"input_token_count":3,"output_token_count":4}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	stream, err := p.Stream(context.Background(),
+		llm.WithModel(ModelLlama38BInstruct),
+		llm.WithUserTextMessage("Greet me"),
+	)
+	assert.NoError(t, err)
+	accumulator := llm.NewResponseAccumulator()
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Err())
+	assert.True(t, request.Stream)
+
+	response := accumulator.Response()
+	assert.Equal(t, "pred_5", response.ID)
+	assert.Equal(t, "Hello, world\n!", response.Message().Text())
+	assert.Equal(t, llm.StopReasonEndTurn, response.StopReason)
+	assert.Equal(t, 3, response.Usage.InputTokens)
+	assert.Equal(t, 4, response.Usage.OutputTokens)
+}
+
+func TestStreamWithoutStreamURL(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"pred_6","status":"succeeded","output":["Hi","!"]}`)
+	})
+	stream, err := p.Stream(context.Background(), llm.WithUserTextMessage("Greet me"))
+	assert.NoError(t, err)
+	accumulator := llm.NewResponseAccumulator()
+	for stream.Next() {
+		assert.NoError(t, accumulator.AddEvent(stream.Event()))
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, "Hi!", accumulator.Response().Message().Text())
+}
+
+func TestStreamError(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprintf(w, `{"id":"pred_7","status":"starting","urls":{"stream":%q}}`, "http://"+r.Host+"/stream")
+			return
+		}
+		io.WriteString(w, "event: output\ndata: Hel\n\nevent: error\ndata: {\"detail\":\"model crashed\"}\n\n")
+	})
+	stream, err := p.Stream(context.Background(), llm.WithUserTextMessage("Greet me"))
+	assert.NoError(t, err)
+	for stream.Next() {
+	}
+	assert.Error(t, stream.Err())
+	assert.Contains(t, stream.Err().Error(), "model crashed")
+}
+
+func TestHealthCheck(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/account", r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, `{"type":"user","username":"test"}`)
+	})
+	assert.NoError(t, providers.HealthCheck(context.Background(), p))
+
+	bad := New(WithEndpoint(p.endpoint), WithAPIToken("wrong"), WithMaxRetries(0))
+	err := providers.HealthCheck(context.Background(), bad)
+	assert.Equal(t, providers.HealthAuthFailure, providers.HealthStatusOf(err))
+}
+
+func TestRegistered(t *testing.T) {
+	model := providers.CreateModel("acme/custom-llm:"+testVersion, "")
+	assert.Equal(t, ProviderName, model.Name())
+	assert.Equal(t, "acme/custom-llm:"+testVersion, model.(*Provider).model)
+
+	model = providers.CreateModel("replicate/"+ModelLlama370BInstruct, "")
+	assert.Equal(t, ProviderName, model.Name())
+	assert.Equal(t, ModelLlama370BInstruct, model.(*Provider).model)
+
+	assert.True(t, IsVersionedModel("acme/custom-llm:"+testVersion))
+	assert.False(t, IsVersionedModel("acme/custom-llm"))
+	assert.False(t, IsVersionedModel("meta-llama/llama-3-8b-instruct:free"))
+}
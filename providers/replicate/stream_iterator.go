@@ -0,0 +1,227 @@
+package replicate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// StreamIterator translates a prediction's server-sent events to llm
+// events. Output events carry raw text rather than JSON, and become the
+// deltas of a single text block. The done event ends the message, with the
+// usage of the finished prediction.
+type StreamIterator struct {
+	reader    *bufio.Reader
+	body      io.ReadCloser
+	callback  llm.ServerSentEventsCallback
+	finish    func() (*Prediction, error)
+	id        string
+	model     string
+	events    []*llm.Event
+	current   *llm.Event
+	started   bool
+	done      bool
+	err       error
+	closeOnce sync.Once
+}
+
+func newStreamIterator(body io.ReadCloser, id, model string, callback llm.ServerSentEventsCallback, finish func() (*Prediction, error)) *StreamIterator {
+	return &StreamIterator{
+		reader:   bufio.NewReader(body),
+		body:     body,
+		callback: callback,
+		finish:   finish,
+		id:       id,
+		model:    model,
+	}
+}
+
+// newCompletedStreamIterator returns an iterator over the output of a
+// finished prediction, for models that don't stream.
+func newCompletedStreamIterator(prediction *Prediction, model string) *StreamIterator {
+	s := &StreamIterator{id: prediction.ID, model: model}
+	s.start()
+	if text := outputText(prediction.Output); text != "" {
+		s.queue(textDelta(text))
+	}
+	s.stop(prediction)
+	return s
+}
+
+// Next advances to the next event. It returns false when the stream is
+// complete or an error occurs.
+func (s *StreamIterator) Next() bool {
+	for len(s.events) == 0 {
+		if s.done || s.err != nil {
+			s.Close()
+			return false
+		}
+		event, data, err := s.readEvent()
+		if err != nil {
+			s.err = err
+			continue
+		}
+		s.handle(event, data)
+	}
+	s.current = s.events[0]
+	s.events = s.events[1:]
+	return true
+}
+
+// readEvent reads the next event's type and data. Multi-line data is
+// joined with newlines, as the SSE format specifies.
+func (s *StreamIterator) readEvent() (string, string, error) {
+	var event string
+	var data []string
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF {
+				err = fmt.Errorf("replicate: stream of prediction %s ended before it finished: %w", s.id, io.ErrUnexpectedEOF)
+			}
+			return "", "", err
+		}
+		if s.callback != nil {
+			if err := s.callback(line); err != nil {
+				return "", "", err
+			}
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if event != "" || len(data) > 0 {
+				return event, strings.Join(data, "\n"), nil
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
+
+func (s *StreamIterator) handle(event, data string) {
+	switch event {
+	case EventOutput:
+		s.start()
+		if data != "" {
+			s.queue(textDelta(data))
+		}
+	case EventError:
+		var detail struct {
+			Detail string `json:"detail"`
+		}
+		if json.Unmarshal([]byte(data), &detail) == nil && detail.Detail != "" {
+			data = detail.Detail
+		}
+		s.err = fmt.Errorf("replicate: prediction %s failed: %s", s.id, data)
+	case EventDone:
+		var reason struct {
+			Reason string `json:"reason"`
+		}
+		json.Unmarshal([]byte(data), &reason)
+		switch reason.Reason {
+		case "canceled":
+			s.err = fmt.Errorf("replicate: prediction %s was canceled", s.id)
+			return
+		case "error":
+			s.err = fmt.Errorf("replicate: prediction %s failed", s.id)
+			return
+		}
+		s.start()
+		// Usage is informational, so a failed lookup leaves it empty
+		// rather than failing a complete response
+		final, err := s.finish()
+		if err != nil {
+			final = &Prediction{}
+		}
+		s.stop(final)
+	}
+}
+
+// start queues the message start and opens the text block, once.
+func (s *StreamIterator) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.queue(
+		&llm.Event{
+			Type: llm.EventTypeMessageStart,
+			Message: &llm.Response{
+				ID:    s.id,
+				Type:  "message",
+				Role:  llm.Assistant,
+				Model: s.model,
+			},
+		},
+		&llm.Event{
+			Type:         llm.EventTypeContentBlockStart,
+			Index:        blockIndex(),
+			ContentBlock: &llm.EventContentBlock{Type: llm.ContentTypeText},
+		},
+	)
+}
+
+// stop closes the text block and ends the message with the usage of the
+// finished prediction.
+func (s *StreamIterator) stop(prediction *Prediction) {
+	usage := usage(prediction.Metrics)
+	s.queue(
+		&llm.Event{Type: llm.EventTypeContentBlockStop, Index: blockIndex()},
+		&llm.Event{
+			Type:  llm.EventTypeMessageDelta,
+			Delta: &llm.EventDelta{StopReason: llm.StopReasonEndTurn},
+			Usage: &usage,
+		},
+		&llm.Event{Type: llm.EventTypeMessageStop},
+	)
+	s.done = true
+}
+
+func (s *StreamIterator) queue(events ...*llm.Event) {
+	s.events = append(s.events, events...)
+}
+
+func textDelta(text string) *llm.Event {
+	return &llm.Event{
+		Type:  llm.EventTypeContentBlockDelta,
+		Index: blockIndex(),
+		Delta: &llm.EventDelta{Type: llm.EventDeltaTypeText, Text: text},
+	}
+}
+
+// blockIndex returns the index of the text block, the only block.
+func blockIndex() *int {
+	index := 0
+	return &index
+}
+
+// Event returns the current event. Should only be called after a
+// successful Next().
+func (s *StreamIterator) Event() *llm.Event {
+	return s.current
+}
+
+func (s *StreamIterator) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		if s.body != nil {
+			err = s.body.Close()
+		}
+	})
+	return err
+}
+
+func (s *StreamIterator) Err() error {
+	return s.err
+}
@@ -0,0 +1,64 @@
+package replicate
+
+import "encoding/json"
+
+// Request is the body of a create prediction request. Version is set for
+// models pinned to a version; official models are addressed in the URL.
+type Request struct {
+	Version string          `json:"version,omitempty"`
+	Input   json.RawMessage `json:"input"`
+	Stream  bool            `json:"stream,omitempty"`
+}
+
+// Prediction statuses. Succeeded, failed, and canceled are terminal.
+const (
+	StatusStarting   = "starting"
+	StatusProcessing = "processing"
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusCanceled   = "canceled"
+)
+
+// Prediction is a run of a model.
+type Prediction struct {
+	ID      string          `json:"id"`
+	Model   string          `json:"model,omitempty"`
+	Version string          `json:"version,omitempty"`
+	Status  string          `json:"status"`
+	Output  json.RawMessage `json:"output,omitempty"`
+	Error   any             `json:"error,omitempty"`
+	Metrics *Metrics        `json:"metrics,omitempty"`
+	URLs    *URLs           `json:"urls,omitempty"`
+}
+
+// Done reports whether the prediction has reached a terminal status.
+func (p *Prediction) Done() bool {
+	switch p.Status {
+	case StatusSucceeded, StatusFailed, StatusCanceled:
+		return true
+	}
+	return false
+}
+
+// Metrics carries the usage of a prediction. Token counts are reported by
+// language models only.
+type Metrics struct {
+	InputTokenCount  int     `json:"input_token_count,omitempty"`
+	OutputTokenCount int     `json:"output_token_count,omitempty"`
+	PredictTime      float64 `json:"predict_time,omitempty"`
+}
+
+// URLs are the API URLs of a prediction. Stream is set only for models that
+// stream their output.
+type URLs struct {
+	Get    string `json:"get,omitempty"`
+	Cancel string `json:"cancel,omitempty"`
+	Stream string `json:"stream,omitempty"`
+}
+
+// Stream event types.
+const (
+	EventOutput = "output"
+	EventError  = "error"
+	EventDone   = "done"
+)
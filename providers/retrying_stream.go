@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/deepnoodle-ai/dive/llm"
@@ -19,6 +20,12 @@ const maxStreamRetryBackoff = 5 * time.Minute
 // caller-owned messages; retries may invoke it more than once.
 type StreamFactory func() (llm.StreamIterator, error)
 
+// ContextStreamFactory is a StreamFactory that receives the attempt context.
+// ctx is canceled when the stream is closed. Attempts should bind their HTTP
+// requests to it, so that closing the stream aborts a request still waiting
+// for its response and unblocks reads of the response body.
+type ContextStreamFactory func(ctx context.Context) (llm.StreamIterator, error)
+
 // StreamRetryConfig configures retries before the first event is exposed to a
 // stream consumer.
 type StreamRetryConfig struct {
@@ -36,16 +43,42 @@ type StreamRetryConfig struct {
 // transport attempts from factory. Factory errors and iterator errors share a
 // single retry budget. The first exposed llm.Event commits the current attempt;
 // after that point, errors are returned without retry to avoid duplicate output.
+// Use NewContextRetryingStreamIterator so that closing the stream can abort
+// an attempt's HTTP request.
 func NewRetryingStreamIterator(
 	ctx context.Context,
 	config StreamRetryConfig,
 	factory StreamFactory,
+) llm.StreamIterator {
+	var contextFactory ContextStreamFactory
+	if factory != nil {
+		contextFactory = func(context.Context) (llm.StreamIterator, error) {
+			return factory()
+		}
+	}
+	return NewContextRetryingStreamIterator(ctx, config, contextFactory)
+}
+
+// NewContextRetryingStreamIterator is like NewRetryingStreamIterator, but
+// passes each attempt a context that is canceled when the stream is closed.
+//
+// Canceling ctx or calling Close aborts the current attempt's HTTP request,
+// so a Next blocked on the provider returns promptly. Close may be called
+// from another goroutine while Next is blocked, such as when a user stops
+// generation. It doesn't wait for that Next: the attempt is closed when Next
+// returns, even if the factory ignores its context.
+func NewContextRetryingStreamIterator(
+	ctx context.Context,
+	config StreamRetryConfig,
+	factory ContextStreamFactory,
 ) llm.StreamIterator {
 	if config.MaxRetries < 0 {
 		config.MaxRetries = 0
 	}
+	ctx, cancel := context.WithCancel(ctx)
 	return &retryingStreamIterator{
 		ctx:     ctx,
+		cancel:  cancel,
 		config:  config,
 		factory: factory,
 	}
@@ -53,20 +86,43 @@ func NewRetryingStreamIterator(
 
 type retryingStreamIterator struct {
 	ctx     context.Context
+	cancel  context.CancelFunc
 	config  StreamRetryConfig
-	factory StreamFactory
+	factory ContextStreamFactory
 
-	current   llm.StreamIterator
-	committed bool
-	ended     bool
-	err       error
-	closeOnce sync.Once
+	// mu is held by Next for its whole call. Close doesn't wait for it: it
+	// sets closed, and whichever of Close and Next holds mu last closes the
+	// current attempt.
+	mu            sync.Mutex
+	closed        atomic.Bool
+	current       llm.StreamIterator
+	currentClosed bool
+	committed     bool
+	ended         bool
+	err           error
+	closeOnce     sync.Once
 }
 
 func (s *retryingStreamIterator) Next() bool {
-	if s.ended || s.err != nil {
+	s.mu.Lock()
+	defer func() {
+		s.mu.Unlock()
+		// Close the attempt for a Close that ran while Next held mu
+		if s.closed.Load() && s.mu.TryLock() {
+			s.closeCurrent()
+			s.mu.Unlock()
+		}
+	}()
+	if s.ended || s.err != nil || s.closed.Load() {
 		return false
 	}
+	defer func() {
+		if s.ended {
+			// Release the attempt context once the stream is over, even if
+			// the caller never closes it
+			s.cancel()
+		}
+	}()
 
 	if s.committed {
 		if s.current.Next() {
@@ -83,7 +139,7 @@ func (s *retryingStreamIterator) Next() bool {
 			return retry.MarkPermanent(fmt.Errorf("providers: stream factory is nil"))
 		}
 
-		stream, err := s.factory()
+		stream, err := s.factory(s.ctx)
 		if err != nil {
 			if stream != nil {
 				_ = stream.Close()
@@ -93,7 +149,13 @@ func (s *retryingStreamIterator) Next() bool {
 		if stream == nil {
 			return retry.MarkPermanent(fmt.Errorf("providers: stream factory returned nil"))
 		}
+		if err := s.ctx.Err(); err != nil {
+			// Stopped while the factory ignored its context
+			_ = stream.Close()
+			return err
+		}
 		s.current = stream
+		s.currentClosed = false
 
 		if s.current.Next() {
 			// The first exposed event is the commit point. A committed stream is
@@ -106,6 +168,7 @@ func (s *retryingStreamIterator) Next() bool {
 		streamErr := s.current.Err()
 		closeErr := s.current.Close()
 		s.current = nil // Close every failed attempt before creating a replacement.
+		s.currentClosed = false
 
 		normalizedStreamErr := s.normalizeError(streamErr)
 		if normalizedStreamErr == nil {
@@ -151,17 +214,33 @@ func (s *retryingStreamIterator) Err() error {
 	return s.err
 }
 
+// Close cancels the stream and closes the current attempt. If Next is
+// running, Close returns without waiting for it, and Next closes the
+// attempt when it returns; its close error is then not reported.
 func (s *retryingStreamIterator) Close() error {
 	var closeErr error
 	s.closeOnce.Do(func() {
-		s.ended = true
-		if s.current != nil {
-			closeErr = s.current.Close()
+		s.cancel()
+		s.closed.Store(true)
+		if !s.mu.TryLock() {
+			return
 		}
+		defer s.mu.Unlock()
+		closeErr = s.closeCurrent()
 	})
 	return closeErr
 }
 
+// closeCurrent closes the current attempt once. The caller holds mu.
+func (s *retryingStreamIterator) closeCurrent() error {
+	s.ended = true
+	if s.current == nil || s.currentClosed {
+		return nil
+	}
+	s.currentClosed = true
+	return s.current.Close()
+}
+
 func (s *retryingStreamIterator) normalizeError(err error) error {
 	if err == nil || errors.Is(err, io.EOF) {
 		return nil
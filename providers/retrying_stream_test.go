@@ -1,10 +1,13 @@
 package providers
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -483,3 +486,154 @@ func TestRetryingStreamLogsTransportErrorWithoutStatus(t *testing.T) {
 		assert.False(t, logger.args[i] == "status")
 	}
 }
+
+// blockingStreamServer starts a server that, unless beforeHeaders is set,
+// sends a first event and then holds the response open until the client
+// disconnects, which it reports on the returned channel.
+func blockingStreamServer(t *testing.T, beforeHeaders bool) (*httptest.Server, <-chan struct{}) {
+	t.Helper()
+	disconnected := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !beforeHeaders {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "message_start\n")
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+		disconnected <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return server, disconnected
+}
+
+// lineStreamIterator yields one event per line of an HTTP response body.
+type lineStreamIterator struct {
+	body   io.ReadCloser
+	lines  *bufio.Scanner
+	event  *llm.Event
+	closed atomic.Bool
+}
+
+func (s *lineStreamIterator) Next() bool {
+	if !s.lines.Scan() {
+		return false
+	}
+	s.event = &llm.Event{Type: llm.EventType(s.lines.Text())}
+	return true
+}
+
+func (s *lineStreamIterator) Event() *llm.Event { return s.event }
+func (s *lineStreamIterator) Err() error        { return s.lines.Err() }
+func (s *lineStreamIterator) Close() error {
+	s.closed.Store(true)
+	return s.body.Close()
+}
+
+func TestRetryingStreamAbortsBlockedRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		beforeHeaders bool
+		close         bool
+	}{
+		{name: "close while reading body", close: true},
+		{name: "cancel while reading body"},
+		{name: "close while awaiting headers", beforeHeaders: true, close: true},
+		{name: "cancel while awaiting headers", beforeHeaders: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server, disconnected := blockingStreamServer(t, tc.beforeHeaders)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var attempt *lineStreamIterator
+			iterator := NewContextRetryingStreamIterator(ctx, StreamRetryConfig{Provider: "test"},
+				func(ctx context.Context) (llm.StreamIterator, error) {
+					req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+					if err != nil {
+						return nil, err
+					}
+					resp, err := http.DefaultClient.Do(req)
+					if err != nil {
+						return nil, err
+					}
+					attempt = &lineStreamIterator{body: resp.Body, lines: bufio.NewScanner(resp.Body)}
+					return attempt, nil
+				})
+			defer iterator.Close()
+
+			if !tc.beforeHeaders {
+				assert.True(t, iterator.Next())
+				assert.Equal(t, llm.EventTypeMessageStart, iterator.Event().Type)
+			}
+			next := make(chan bool, 1)
+			go func() { next <- iterator.Next() }()
+			time.Sleep(20 * time.Millisecond)
+
+			if tc.close {
+				closed := make(chan struct{})
+				go func() {
+					iterator.Close()
+					close(closed)
+				}()
+				select {
+				case <-closed:
+				case <-time.After(2 * time.Second):
+					t.Fatal("Close did not return while Next was blocked")
+				}
+			} else {
+				cancel()
+			}
+			select {
+			case gotEvent := <-next:
+				assert.False(t, gotEvent)
+			case <-time.After(2 * time.Second):
+				t.Fatal("Next did not return after the stream was stopped")
+			}
+			select {
+			case <-disconnected:
+			case <-time.After(2 * time.Second):
+				t.Fatal("the HTTP request was not aborted")
+			}
+			assert.True(t, errors.Is(iterator.Err(), context.Canceled))
+			if attempt != nil {
+				assert.NoError(t, iterator.Close())
+				assert.True(t, attempt.closed.Load())
+			}
+		})
+	}
+}
+
+func TestRetryingStreamCloseDoesNotWaitForFactory(t *testing.T) {
+	release := make(chan struct{})
+	attempt := &testStreamIterator{events: []*llm.Event{{Type: llm.EventTypeMessageStart}}}
+	iterator := NewRetryingStreamIterator(context.Background(), StreamRetryConfig{Provider: "test"},
+		func() (llm.StreamIterator, error) {
+			// Ignores cancellation, like a factory without a context
+			<-release
+			return attempt, nil
+		})
+
+	next := make(chan bool, 1)
+	go func() { next <- iterator.Next() }()
+	time.Sleep(20 * time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() { closed <- iterator.Close() }()
+	select {
+	case err := <-closed:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close waited for the factory")
+	}
+
+	// The attempt the factory returns after Close is closed by Next
+	close(release)
+	select {
+	case gotEvent := <-next:
+		assert.False(t, gotEvent)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Next did not return")
+	}
+	assert.True(t, errors.Is(iterator.Err(), context.Canceled))
+	assert.Equal(t, 1, attempt.closeCount)
+}
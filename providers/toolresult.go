@@ -59,7 +59,8 @@ func ToolResultBlocks(c *llm.ToolResultContent) []*dive.ToolResultContent {
 		switch b.Type {
 		case dive.ToolResultContentTypeText,
 			dive.ToolResultContentTypeImage,
-			dive.ToolResultContentTypeAudio:
+			dive.ToolResultContentTypeAudio,
+			dive.ToolResultContentTypeFile:
 		case "":
 			if b.Text == "" && b.Data == "" {
 				return nil
@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Streaming transport defaults. See NewStreamingTransport.
+const (
+	StreamingDialTimeout         = 30 * time.Second
+	StreamingKeepAlive           = 15 * time.Second
+	StreamingTLSHandshakeTimeout = 10 * time.Second
+	StreamingIdleConnTimeout     = 90 * time.Second
+)
+
+// NewStreamingTransport returns an *http.Transport tuned for long-lived
+// streaming responses, such as extended reasoning streams:
+//
+//   - HTTP/2 is attempted, so streams share a connection and get
+//     protocol-level pings.
+//   - No response header timeout: a model can think for minutes before the
+//     first byte arrives.
+//   - TCP keep-alives every 15 seconds, well inside the idle timeout of
+//     common load balancers and NAT gateways, so quiet streams aren't
+//     dropped mid-response.
+//   - Proxy settings come from the environment, as with the default
+//     transport.
+//
+// Providers accept it through their WithStreamingTransport option, or
+// through WithTransport after further tuning.
+func NewStreamingTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   StreamingDialTimeout,
+		KeepAlive: StreamingKeepAlive,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   StreamingTLSHandshakeTimeout,
+		IdleConnTimeout:       StreamingIdleConnTimeout,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		ExpectContinueTimeout: 1 * time.Second,
+		ResponseHeaderTimeout: 0,
+	}
+}
+
+// NewTransportClient returns an *http.Client that uses transport and has no
+// overall timeout. A client timeout covers reading the whole response body,
+// so it cuts off long streams; bound requests with context deadlines (for
+// example AgentOptions.ResponseTimeout) instead.
+func NewTransportClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,24 @@
+package providers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestNewStreamingTransport(t *testing.T) {
+	transport := NewStreamingTransport()
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.Equal(t, 0, int(transport.ResponseHeaderTimeout))
+	assert.Equal(t, StreamingIdleConnTimeout, transport.IdleConnTimeout)
+	assert.NotNil(t, transport.DialContext)
+	assert.NotNil(t, transport.Proxy)
+}
+
+func TestNewTransportClient(t *testing.T) {
+	transport := NewStreamingTransport()
+	client := NewTransportClient(transport)
+	assert.Equal(t, http.RoundTripper(transport), client.Transport)
+	assert.Equal(t, 0, int(client.Timeout))
+}
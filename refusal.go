@@ -0,0 +1,111 @@
+package dive
+
+import (
+	"slices"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// RefusalKind classifies why a model response was refused.
+type RefusalKind string
+
+const (
+	// RefusalKindModel means the model itself declined and explained why
+	// (llm.RefusalContent). Models sometimes refuse benign requests that
+	// are phrased ambiguously, so these may be retried with a rephrased
+	// prompt. See WithRefusalRetry.
+	RefusalKindModel RefusalKind = "model"
+
+	// RefusalKindPolicy means the provider stopped the response for a usage
+	// policy violation (stop reason "refusal" with no refusal text, as
+	// Anthropic's safety classifiers report). It is never retried.
+	RefusalKindPolicy RefusalKind = "policy"
+
+	// RefusalKindContentFilter means the provider's safety system blocked
+	// or truncated the output (stop reason "content_filter"). It is never
+	// retried.
+	RefusalKindContentFilter RefusalKind = "content_filter"
+)
+
+// Refusal describes a response the model or provider refused.
+type Refusal struct {
+	// Kind classifies the refusal.
+	Kind RefusalKind `json:"kind"`
+
+	// StopReason is the response's normalized stop reason.
+	StopReason string `json:"stop_reason,omitempty"`
+
+	// Text is the model's refusal message, if it gave one.
+	Text string `json:"text,omitempty"`
+
+	// Retries is the number of rephrased retries made before giving up.
+	Retries int `json:"retries,omitempty"`
+}
+
+// Retryable reports whether the refusal may be retried with a rephrased
+// prompt. Only RefusalKindModel refusals are; policy and content filter
+// refusals concern genuinely disallowed content.
+func (r *Refusal) Retryable() bool {
+	return r.Kind == RefusalKindModel
+}
+
+// DetectRefusal returns the refusal in response, or nil if the response
+// wasn't refused.
+func DetectRefusal(response *llm.Response) *Refusal {
+	for _, c := range response.Content {
+		if refusal, ok := c.(*llm.RefusalContent); ok {
+			return &Refusal{Kind: RefusalKindModel, StopReason: response.StopReason, Text: refusal.Text}
+		}
+	}
+	switch response.StopReason {
+	case llm.StopReasonRefusal:
+		return &Refusal{Kind: RefusalKindPolicy, StopReason: response.StopReason}
+	case llm.StopReasonContentFilter:
+		return &Refusal{Kind: RefusalKindContentFilter, StopReason: response.StopReason}
+	}
+	return nil
+}
+
+// RefusalRetry configures retrying refused responses. See WithRefusalRetry.
+type RefusalRetry struct {
+	// Rephrase returns a safer framing of the refused prompt.
+	Rephrase func(prompt string) string
+
+	// MaxAttempts is the maximum number of retries per CreateResponse call.
+	MaxAttempts int
+}
+
+// rephrasePrompt returns messages with the trailing user prompt rewritten by
+// rephrase. It returns false unless the last message is a user prompt (not
+// tool results) with text, so only a direct refusal of the prompt is
+// retried. The rewritten prompt replaces the text blocks of the message and
+// keeps its other content, such as images.
+func rephrasePrompt(messages []*llm.Message, rephrase func(string) string) ([]*llm.Message, bool) {
+	if len(messages) == 0 || rephrase == nil {
+		return nil, false
+	}
+	last := messages[len(messages)-1]
+	if last.Role != llm.User || hasToolResult(last) {
+		return nil, false
+	}
+	prompt := last.Text()
+	if prompt == "" {
+		return nil, false
+	}
+	rephrased := rephrase(prompt)
+	if rephrased == "" || rephrased == prompt {
+		return nil, false
+	}
+	content := make([]llm.Content, 0, len(last.Content))
+	for _, c := range last.Content {
+		if _, ok := c.(*llm.TextContent); !ok {
+			content = append(content, c)
+		}
+	}
+	content = append(content, &llm.TextContent{Text: rephrased})
+	msg := *last
+	msg.Content = content
+	result := slices.Clone(messages)
+	result[len(result)-1] = &msg
+	return result, true
+}
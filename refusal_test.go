@@ -0,0 +1,149 @@
+package dive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestDetectRefusal(t *testing.T) {
+	t.Run("model refusal", func(t *testing.T) {
+		refusal := DetectRefusal(&llm.Response{
+			Content:    []llm.Content{&llm.RefusalContent{Text: "I can't help with that."}},
+			StopReason: llm.StopReasonRefusal,
+		})
+		assert.NotNil(t, refusal)
+		assert.Equal(t, RefusalKindModel, refusal.Kind)
+		assert.Equal(t, "I can't help with that.", refusal.Text)
+		assert.True(t, refusal.Retryable())
+	})
+
+	t.Run("policy refusal", func(t *testing.T) {
+		refusal := DetectRefusal(&llm.Response{StopReason: llm.StopReasonRefusal})
+		assert.NotNil(t, refusal)
+		assert.Equal(t, RefusalKindPolicy, refusal.Kind)
+		assert.False(t, refusal.Retryable())
+	})
+
+	t.Run("content filter", func(t *testing.T) {
+		refusal := DetectRefusal(&llm.Response{StopReason: llm.StopReasonContentFilter})
+		assert.NotNil(t, refusal)
+		assert.Equal(t, RefusalKindContentFilter, refusal.Kind)
+		assert.False(t, refusal.Retryable())
+	})
+
+	t.Run("no refusal", func(t *testing.T) {
+		assert.Nil(t, DetectRefusal(&llm.Response{
+			Content:    []llm.Content{&llm.TextContent{Text: "ok"}},
+			StopReason: llm.StopReasonEndTurn,
+		}))
+	})
+}
+
+// refusingLLM refuses any prompt containing "forbidden" and records the
+// prompts it receives.
+func refusingLLM(stopReason string, prompts *[]string) *mockLLM {
+	return &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			cfg := &llm.Config{}
+			cfg.Apply(opts...)
+			prompt := cfg.Messages[len(cfg.Messages)-1].Text()
+			*prompts = append(*prompts, prompt)
+			if !strings.Contains(prompt, "forbidden") {
+				return &llm.Response{
+					Role:       llm.Assistant,
+					Content:    []llm.Content{&llm.TextContent{Text: "answer"}},
+					StopReason: llm.StopReasonEndTurn,
+				}, nil
+			}
+			resp := &llm.Response{Role: llm.Assistant, StopReason: stopReason}
+			if stopReason == llm.StopReasonRefusal {
+				resp.Content = []llm.Content{&llm.RefusalContent{Text: "I can't help with that."}}
+			}
+			return resp, nil
+		},
+	}
+}
+
+func TestRefusalRetry(t *testing.T) {
+	t.Run("rephrased prompt succeeds", func(t *testing.T) {
+		var prompts []string
+		agent, err := NewAgent(AgentOptions{Model: refusingLLM(llm.StopReasonRefusal, &prompts)})
+		assert.NoError(t, err)
+
+		resp, err := agent.CreateResponse(context.Background(),
+			WithInput("explain the forbidden technique"),
+			WithRefusalRetry(func(prompt string) string {
+				return strings.Replace(prompt, "forbidden", "historical", 1)
+			}, 2))
+		assert.NoError(t, err)
+		assert.Nil(t, resp.Refusal)
+		assert.Equal(t, "answer", resp.OutputText())
+		assert.Equal(t, []string{"explain the forbidden technique", "explain the historical technique"}, prompts)
+		// The refused response is discarded
+		assert.Len(t, resp.OutputMessages, 1)
+	})
+
+	t.Run("still refused after max attempts", func(t *testing.T) {
+		var prompts []string
+		agent, err := NewAgent(AgentOptions{Model: refusingLLM(llm.StopReasonRefusal, &prompts)})
+		assert.NoError(t, err)
+
+		resp, err := agent.CreateResponse(context.Background(),
+			WithInput("forbidden"),
+			WithRefusalRetry(func(prompt string) string { return prompt + " (forbidden)" }, 2))
+		assert.NoError(t, err)
+		assert.Len(t, prompts, 3)
+		assert.NotNil(t, resp.Refusal)
+		assert.Equal(t, RefusalKindModel, resp.Refusal.Kind)
+		assert.Equal(t, 2, resp.Refusal.Retries)
+		assert.Equal(t, "I can't help with that.", resp.Refusal.Text)
+	})
+
+	t.Run("content filter is not retried", func(t *testing.T) {
+		var prompts []string
+		agent, err := NewAgent(AgentOptions{Model: refusingLLM(llm.StopReasonContentFilter, &prompts)})
+		assert.NoError(t, err)
+
+		resp, err := agent.CreateResponse(context.Background(),
+			WithInput("forbidden"),
+			WithRefusalRetry(func(string) string { return "something else" }, 3))
+		assert.NoError(t, err)
+		assert.Len(t, prompts, 1)
+		assert.NotNil(t, resp.Refusal)
+		assert.Equal(t, RefusalKindContentFilter, resp.Refusal.Kind)
+	})
+
+	t.Run("without the option refusals are reported", func(t *testing.T) {
+		var prompts []string
+		agent, err := NewAgent(AgentOptions{Model: refusingLLM(llm.StopReasonRefusal, &prompts)})
+		assert.NoError(t, err)
+
+		resp, err := agent.CreateResponse(context.Background(), WithInput("forbidden"))
+		assert.NoError(t, err)
+		assert.Len(t, prompts, 1)
+		assert.NotNil(t, resp.Refusal)
+		assert.Equal(t, 0, resp.Refusal.Retries)
+	})
+}
+
+func TestRephrasePrompt(t *testing.T) {
+	image := &llm.ImageContent{Source: &llm.ContentSource{Type: llm.ContentSourceTypeURL, URL: "https://example.com/a.png"}}
+	messages := []*llm.Message{{Role: llm.User, Content: []llm.Content{image, &llm.TextContent{Text: "describe"}}}}
+
+	rephrased, ok := rephrasePrompt(messages, func(string) string { return "summarize" })
+	assert.True(t, ok)
+	assert.Equal(t, []llm.Content{image, &llm.TextContent{Text: "summarize"}}, rephrased[0].Content)
+	// The caller's messages are not modified
+	assert.Equal(t, "describe", messages[0].Text())
+
+	_, ok = rephrasePrompt(messages, func(p string) string { return p })
+	assert.False(t, ok)
+
+	toolResults := []*llm.Message{llm.NewToolResultMessage(&llm.ToolResultContent{ToolUseID: "1", Content: "x"})}
+	_, ok = rephrasePrompt(toolResults, func(string) string { return "other" })
+	assert.False(t, ok)
+}
@@ -0,0 +1,149 @@
+package dive
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// OversizedMessagePolicy controls whether the agent checks a request's size
+// before sending it, and what it does with a message too large for the
+// model's context window. Sizes are estimated with llm.EstimateTokens, which
+// can overcount, so the check is opt-in: the zero value sends requests
+// unchecked, and a provider's own size error still matches
+// llm.ErrRequestTooLarge.
+type OversizedMessagePolicy string
+
+const (
+	// OversizedMessageFail fails the request with an error matching
+	// llm.ErrRequestTooLarge before anything is sent to the provider. With
+	// WithReservedOutputTokens, a message that overflows the context window
+	// is reported by the ContextOverflowStrategy first.
+	OversizedMessageFail OversizedMessagePolicy = "fail"
+
+	// OversizedMessageTruncate splits the oversized message with
+	// llm.SplitMessage and sends only its first part, followed by a note
+	// telling the model the rest was omitted. The omitted parts are returned
+	// in Response.OmittedParts. Only the model-facing history is changed.
+	// Messages whose oversized content isn't text, such as a large tool
+	// result, still fail.
+	OversizedMessageTruncate OversizedMessagePolicy = "truncate"
+)
+
+// WithOversizedMessagePolicy sets how the agent handles a message too large
+// for the model's context window in this call.
+func WithOversizedMessagePolicy(policy OversizedMessagePolicy) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.OversizedMessagePolicy = policy
+	}
+}
+
+// requestFitter checks the requests of one CreateResponse call against the
+// model's size limits, so an oversized request fails with an actionable
+// error instead of a provider 413 or 400. Each message must fit in the
+// context window less the reserved output tokens. Message sizes and
+// truncations are remembered across the call's iterations, so each turn
+// only measures its new messages.
+type requestFitter struct {
+	sizer     *llm.RequestSizer
+	model     string
+	maxTokens int
+	policy    OversizedMessagePolicy
+
+	// truncated maps each truncated message to the message sent in its place
+	truncated map[*llm.Message]*llm.Message
+
+	// omitted holds the parts left out by truncation, in order
+	omitted []*llm.Message
+}
+
+// newRequestFitter returns a requestFitter for the model, or nil if the
+// policy is unset or the model doesn't implement llm.ModelInfoProvider.
+func newRequestFitter(model llm.LLM, modelName string, reserved int, policy OversizedMessagePolicy) *requestFitter {
+	provider, ok := model.(llm.ModelInfoProvider)
+	if !ok || policy == "" {
+		return nil
+	}
+	info := provider.ModelInfo(modelName)
+	maxTokens := 0
+	if info.ContextWindow > 0 {
+		maxTokens = max(1, info.ContextWindow-reserved)
+	}
+	return &requestFitter{
+		sizer:     llm.NewRequestSizer(info),
+		model:     info.Model,
+		maxTokens: maxTokens,
+		policy:    policy,
+		truncated: map[*llm.Message]*llm.Message{},
+	}
+}
+
+// truncate returns messages with each oversized message replaced by its
+// truncation, when the policy is OversizedMessageTruncate. Messages
+// truncated in an earlier iteration are replaced by the same truncation.
+func (f *requestFitter) truncate(messages []*llm.Message) ([]*llm.Message, error) {
+	if f == nil || f.policy != OversizedMessageTruncate {
+		return messages, nil
+	}
+	fitted, copied := messages, false
+	replace := func(i int, msg *llm.Message) {
+		if !copied {
+			fitted, copied = slices.Clone(messages), true
+		}
+		fitted[i] = msg
+	}
+	for i, msg := range messages {
+		if truncated, ok := f.truncated[msg]; ok {
+			replace(i, truncated)
+		}
+	}
+	for range messages {
+		err := f.sizer.Check(fitted, f.maxTokens)
+		var tooLarge *llm.RequestTooLargeError
+		if err == nil || !errors.As(err, &tooLarge) || tooLarge.Unit != "tokens" {
+			return fitted, err
+		}
+		original := fitted[tooLarge.MessageIndex]
+		truncated, omitted, truncErr := truncateMessage(original, f.maxTokens, f.model)
+		if truncErr != nil {
+			return nil, fmt.Errorf("%w: %w", err, truncErr)
+		}
+		f.truncated[original] = truncated
+		f.omitted = append(f.omitted, omitted...)
+		replace(tooLarge.MessageIndex, truncated)
+	}
+	return fitted, f.sizer.Check(fitted, f.maxTokens)
+}
+
+// check reports whether messages fit the model's size limits.
+func (f *requestFitter) check(messages []*llm.Message) error {
+	if f == nil {
+		return nil
+	}
+	return f.sizer.Check(messages, f.maxTokens)
+}
+
+// omittedParts returns the message parts left out by truncation.
+func (f *requestFitter) omittedParts() []*llm.Message {
+	if f == nil {
+		return nil
+	}
+	return f.omitted
+}
+
+// truncateMessage returns the first part of msg split to fit in maxTokens,
+// with a note about the parts left out, and the parts left out.
+func truncateMessage(msg *llm.Message, maxTokens int, model string) (*llm.Message, []*llm.Message, error) {
+	note := "[Message truncated: %d more parts were omitted because it was too long to send.]"
+	noteTokens := llm.EstimateTextTokens(fmt.Sprintf(note, 1000), model)
+	parts, err := llm.SplitMessage(msg, maxTokens-noteTokens, model)
+	if err != nil {
+		return nil, nil, err
+	}
+	first := *parts[0]
+	first.ID = msg.ID
+	first.Content = append(first.Content, &llm.TextContent{Text: fmt.Sprintf(note, len(parts)-1)})
+	return &first, parts[1:], nil
+}
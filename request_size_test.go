@@ -0,0 +1,85 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestOversizedMessage(t *testing.T) {
+	t.Run("fails before sending", func(t *testing.T) {
+		model := newWindowedLLM(300)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(),
+			WithInput(strings.Repeat("word ", 500)),
+			WithOversizedMessagePolicy(OversizedMessageFail))
+		assert.True(t, errors.Is(err, llm.ErrRequestTooLarge))
+		var tooLarge *llm.RequestTooLargeError
+		assert.True(t, errors.As(err, &tooLarge))
+		assert.Equal(t, 0, tooLarge.MessageIndex)
+		assert.Equal(t, 300, tooLarge.Limit)
+		assert.Len(t, model.requests, 0)
+	})
+
+	t.Run("truncate policy", func(t *testing.T) {
+		model := newWindowedLLM(300)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		input := strings.Repeat("word ", 500)
+		resp, err := agent.CreateResponse(context.Background(),
+			WithInput(input),
+			WithReservedOutputTokens(100),
+			WithOversizedMessagePolicy(OversizedMessageTruncate))
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp.OutputText())
+
+		sent := model.requests[0]
+		assert.Len(t, sent, 1)
+		tokens, _, err := llm.EstimateTokens(sent[0], "test-model")
+		assert.NoError(t, err)
+		assert.True(t, tokens <= 200)
+		assert.Contains(t, sent[0].Text(), "more parts were omitted")
+		first := sent[0].Content[0].(*llm.TextContent).Text
+		assert.True(t, strings.HasPrefix(input, first))
+
+		// Every omitted part is returned
+		assert.True(t, len(resp.OmittedParts) > 0)
+		var joined strings.Builder
+		joined.WriteString(first)
+		for _, part := range resp.OmittedParts {
+			assert.Equal(t, llm.User, part.Role)
+			joined.WriteString(part.Text())
+		}
+		assert.Equal(t, input, joined.String())
+	})
+
+	t.Run("unchecked by default", func(t *testing.T) {
+		model := newWindowedLLM(300)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(),
+			WithInput(strings.Repeat("word ", 500)))
+		assert.NoError(t, err)
+		assert.Len(t, model.requests, 1)
+	})
+
+	t.Run("unknown limits are ignored", func(t *testing.T) {
+		model := newWindowedLLM(0)
+		agent, err := NewAgent(AgentOptions{Model: model})
+		assert.NoError(t, err)
+
+		_, err = agent.CreateResponse(context.Background(),
+			WithInput(strings.Repeat("word ", 500)),
+			WithOversizedMessagePolicy(OversizedMessageFail))
+		assert.NoError(t, err)
+		assert.Len(t, model.requests, 1)
+	})
+}
@@ -42,6 +42,22 @@ const (
 	// widget with structured fields like exit_code or files_scanned.
 	ResponseItemTypeToolProgress ResponseItemType = "tool_progress"
 
+	// ResponseItemTypeStatus indicates a user-facing status line about what
+	// the agent is doing, reported with ReportStatus (typically by the
+	// toolkit Status tool). The Status field contains the text. Statuses are
+	// ephemeral and should be rendered as transient progress, not as part of
+	// the answer.
+	ResponseItemTypeStatus ResponseItemType = "status"
+
+	// ResponseItemTypeToolCircuit indicates a tool's circuit breaker tripped
+	// or reset. The ToolCircuit field contains the tool name and new state.
+	ResponseItemTypeToolCircuit ResponseItemType = "tool_circuit"
+
+	// ResponseItemTypeToolCheckpoint indicates the model was consulted at a
+	// checkpoint of a long-running tool. The ToolCheckpoint field contains
+	// its decision. See ToolCheckpointer.
+	ResponseItemTypeToolCheckpoint ResponseItemType = "tool_checkpoint"
+
 	// ResponseItemTypeSuspended is a terminal item emitted when the agent
 	// transitions into a suspended state. The Suspension field carries the
 	// same SuspensionState as Response.Suspension. Stream consumers should
@@ -215,6 +231,16 @@ type ResponseItem struct {
 	// ToolProgress is set if the response item is a structured progress snapshot.
 	ToolProgress *ToolProgressEvent `json:"tool_progress,omitempty"`
 
+	// Status is set if the response item is a user-facing status line.
+	Status *StatusEvent `json:"status,omitempty"`
+
+	// ToolCircuit is set if the response item is a circuit breaker change.
+	ToolCircuit *ToolCircuitEvent `json:"tool_circuit,omitempty"`
+
+	// ToolCheckpoint is set if the response item is a tool checkpoint
+	// decision.
+	ToolCheckpoint *ToolCheckpointEvent `json:"tool_checkpoint,omitempty"`
+
 	// Suspension is set on a ResponseItemTypeSuspended item. It mirrors
 	// Response.Suspension.
 	Suspension *SuspensionState `json:"suspension,omitempty"`
@@ -225,6 +251,16 @@ type ResponseItem struct {
 
 	// Usage contains token usage information, if applicable
 	Usage *llm.Usage `json:"usage,omitempty"`
+
+	// Metadata is the call's WithMetadata metadata. It is set on items
+	// passed to the event callback, not on those in Response.Items.
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// SchemaVersion is the version of the item's JSON encoding (see
+	// ResponseItemSchemaVersion). Zero on items created by the agent;
+	// encoding writes the current version and decoding migrates older
+	// items to it.
+	SchemaVersion int `json:"schema_version,omitempty"`
 }
 
 // ToolStreamEvent contains a chunk of streaming output from a tool.
@@ -241,6 +277,12 @@ type ToolProgressEvent struct {
 	Progress   *ToolProgress `json:"progress"`
 }
 
+// StatusEvent contains a user-facing status line reported via ReportStatus.
+type StatusEvent struct {
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	Text       string `json:"text"`
+}
+
 // Response represents the output from an Agent's response generation.
 type Response struct {
 	// Model represents the model that generated the response
@@ -300,6 +342,34 @@ type Response struct {
 	// goroutine sends to its buffered channel (cap 1) and exits regardless of
 	// whether any caller reads the result.
 	BackgroundTasks []*BackgroundTaskHandle `json:"-"`
+
+	// Refusal is non-nil when the final model response was refused by the
+	// model or the provider. Refusals that WithRefusalRetry retried
+	// successfully are not reported.
+	Refusal *Refusal `json:"refusal,omitempty"`
+
+	// StopReason is the reason given by the StopCondition that ended the
+	// response early, or StopReasonDeadline when the WithDeadline time ran
+	// out. It is empty when the agent stopped on its own.
+	StopReason string `json:"stop_reason,omitempty"`
+
+	// Metadata is the metadata passed with WithMetadata, for correlating
+	// the response with the request that produced it.
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// Truncated is true when the final model response was cut off by the
+	// output token limit, even after any continuations WithAutoContinue
+	// allowed. Use Continue to generate the rest.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// OmittedParts holds, in order, the parts of oversized messages that
+	// OversizedMessageTruncate left out of the request. Each has the role of
+	// the message it was split from, so it can be sent in a later call.
+	OmittedParts []*llm.Message `json:"omitted_parts,omitempty"`
+
+	// continuation holds what Continue needs to resume a truncated
+	// response. It is not serialized.
+	continuation *continuation
 }
 
 // OutputText returns the text content from the last message in the response.
@@ -328,6 +398,43 @@ func (r *Response) OutputText() string {
 	return ""
 }
 
+// Citations returns the sources behind the response. It lists the
+// citations in the final assistant message, with spans relative to that
+// message's text (see llm.Message.Citations), followed by the Sources of
+// tool results that the message doesn't already cite. Sources are matched
+// by SourceID, or URL when SourceID is empty.
+func (r *Response) Citations() []*llm.CitationContent {
+	var lastMessage *llm.Message
+	for _, item := range r.Items {
+		if item.Type == ResponseItemTypeMessage && item.Message != nil {
+			lastMessage = item.Message
+		}
+	}
+	var citations []*llm.CitationContent
+	seen := map[string]bool{}
+	if lastMessage != nil {
+		for _, citation := range lastMessage.Citations() {
+			citations = append(citations, citation)
+			seen[citation.Source()] = true
+		}
+	}
+	for _, result := range r.ToolCallResults() {
+		if result == nil || result.Result == nil {
+			continue
+		}
+		for _, source := range result.Result.Sources {
+			if source == nil || (source.Source() != "" && seen[source.Source()]) {
+				continue
+			}
+			seen[source.Source()] = true
+			citation := llm.NormalizeCitation(source)
+			citation.StartIndex, citation.EndIndex = 0, 0
+			citations = append(citations, citation)
+		}
+	}
+	return citations
+}
+
 // ToolCallResults returns all tool call results from the response.
 func (r *Response) ToolCallResults() []*ToolCallResult {
 	var results []*ToolCallResult
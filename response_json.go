@@ -0,0 +1,177 @@
+package dive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ResponseItemSchemaVersion is the version of ResponseItem's JSON encoding.
+//
+// The encoding is stable, so recorded items can be stored and decoded by
+// later releases: fields may be added, but a change that renames a field or
+// alters its meaning increments this version, and MigrateResponseItem learns
+// to upgrade the older form. Items decoded by UnmarshalJSON are migrated
+// automatically. Items from a newer version are decoded as far as possible;
+// fields this version doesn't know are ignored.
+//
+// Version history:
+//
+//   - 0: items written before versioning. ToolCallResult had no JSON tags, so
+//     its fields used Go names ("ID", "Input", ...) and its error was lost.
+//   - 1: tool call results use snake_case fields and carry the error message.
+const ResponseItemSchemaVersion = 1
+
+// responseItemJSON is ResponseItem without its methods, for encoding.
+type responseItemJSON ResponseItem
+
+// MarshalJSON encodes the item, stamping it with ResponseItemSchemaVersion
+// unless SchemaVersion is already set.
+func (r ResponseItem) MarshalJSON() ([]byte, error) {
+	if r.SchemaVersion == 0 {
+		r.SchemaVersion = ResponseItemSchemaVersion
+	}
+	return json.Marshal(responseItemJSON(r))
+}
+
+// UnmarshalJSON decodes an item written by any schema version, migrating
+// older items with MigrateResponseItem first.
+func (r *ResponseItem) UnmarshalJSON(data []byte) error {
+	migrated, err := MigrateResponseItem(data)
+	if err != nil {
+		return err
+	}
+	var item responseItemJSON
+	if err := json.Unmarshal(migrated, &item); err != nil {
+		return err
+	}
+	*r = ResponseItem(item)
+	return nil
+}
+
+// MigrateResponseItem upgrades a JSON-encoded ResponseItem written by an
+// older schema version to the current one, for example to rewrite a store of
+// recorded items in place. Items already at the current version, or a newer
+// one, are returned unchanged.
+func MigrateResponseItem(data []byte) ([]byte, error) {
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("invalid response item: %w", err)
+	}
+	version := 0
+	if raw, ok := item["schema_version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, fmt.Errorf("invalid response item schema version: %w", err)
+		}
+	}
+	if version >= ResponseItemSchemaVersion {
+		return data, nil
+	}
+	if raw, ok := item["tool_call_result"]; ok {
+		result, err := migrateToolCallResultV0(raw)
+		if err != nil {
+			return nil, err
+		}
+		item["tool_call_result"] = result
+	}
+	item["schema_version"] = json.RawMessage(fmt.Sprint(ResponseItemSchemaVersion))
+	return json.Marshal(item)
+}
+
+// migrateToolCallResultV0 renames a version 0 tool call result's Go field
+// names to their snake_case form. The error, which version 0 encoded as an
+// empty object, and the background task handle are dropped.
+func migrateToolCallResultV0(data json.RawMessage) (json.RawMessage, error) {
+	var old map[string]json.RawMessage
+	if err := json.Unmarshal(data, &old); err != nil {
+		return nil, fmt.Errorf("invalid tool call result: %w", err)
+	}
+	renames := map[string]string{
+		"ID":                "id",
+		"Name":              "name",
+		"Input":             "input",
+		"Preview":           "preview",
+		"Result":            "result",
+		"AdditionalContext": "additional_context",
+		"Cached":            "cached",
+	}
+	result := make(map[string]json.RawMessage, len(old))
+	for key, value := range old {
+		if renamed, ok := renames[key]; ok {
+			result[renamed] = value
+		}
+	}
+	return json.Marshal(result)
+}
+
+// toolCallResultJSON is the JSON encoding of ToolCallResult.
+type toolCallResultJSON struct {
+	ID                string           `json:"id"`
+	Name              string           `json:"name"`
+	Input             json.RawMessage  `json:"input,omitempty"`
+	Preview           *ToolCallPreview `json:"preview,omitempty"`
+	Result            *ToolResult      `json:"result,omitempty"`
+	Error             string           `json:"error,omitempty"`
+	AdditionalContext string           `json:"additional_context,omitempty"`
+	Cached            bool             `json:"cached,omitempty"`
+}
+
+// MarshalJSON encodes the result with its error as a message. The
+// background task handle is not encoded.
+func (r ToolCallResult) MarshalJSON() ([]byte, error) {
+	out := toolCallResultJSON{
+		ID:                r.ID,
+		Name:              r.Name,
+		Preview:           r.Preview,
+		Result:            r.Result,
+		AdditionalContext: r.AdditionalContext,
+		Cached:            r.Cached,
+	}
+	switch input := r.Input.(type) {
+	case nil:
+	case json.RawMessage:
+		out.Input = input
+	case []byte:
+		out.Input = input
+	default:
+		data, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tool call input: %w", err)
+		}
+		out.Input = data
+	}
+	if len(out.Input) > 0 && !json.Valid(out.Input) {
+		// Raw input that isn't JSON is kept as a string
+		data, _ := json.Marshal(string(out.Input))
+		out.Input = data
+	}
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a result encoded by MarshalJSON. Input is decoded as
+// a json.RawMessage and Error, if present, as an error with the recorded
+// message.
+func (r *ToolCallResult) UnmarshalJSON(data []byte) error {
+	var in toolCallResultJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*r = ToolCallResult{
+		ID:                in.ID,
+		Name:              in.Name,
+		Preview:           in.Preview,
+		Result:            in.Result,
+		AdditionalContext: in.AdditionalContext,
+		Cached:            in.Cached,
+	}
+	if len(in.Input) > 0 {
+		r.Input = in.Input
+	}
+	if in.Error != "" {
+		r.Error = errors.New(in.Error)
+	}
+	return nil
+}
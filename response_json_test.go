@@ -0,0 +1,93 @@
+package dive
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func readGoldenItems(t *testing.T, path string) []json.RawMessage {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var items []json.RawMessage
+	assert.NoError(t, json.Unmarshal(data, &items))
+	return items
+}
+
+func compactJSON(t *testing.T, data []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	assert.NoError(t, json.Compact(&buf, data))
+	return buf.String()
+}
+
+func TestResponseItemGoldenV1(t *testing.T) {
+	raw := readGoldenItems(t, "testdata/response_items_v1.json")
+	items := make([]*ResponseItem, len(raw))
+	for i, data := range raw {
+		assert.NoError(t, json.Unmarshal(data, &items[i]))
+		assert.Equal(t, ResponseItemSchemaVersion, items[i].SchemaVersion)
+
+		// Re-encoding reproduces the golden payload exactly
+		encoded, err := json.Marshal(items[i])
+		assert.NoError(t, err)
+		assert.Equal(t, compactJSON(t, data), string(encoded))
+	}
+
+	assert.Equal(t, "Checking the weather", items[0].Event.Delta.Text)
+	assert.Equal(t, llm.EventSchemaVersion, items[0].Event.SchemaVersion)
+	assert.Equal(t, "weather", items[1].Message.Content[1].(*llm.ToolUseContent).Name)
+	assert.Equal(t, 120, items[1].Usage.InputTokens)
+	assert.Equal(t, `{"city":"Paris"}`, compactJSON(t, items[2].ToolCall.Input))
+	assert.Equal(t, "Fetching forecast", items[3].Status.Text)
+
+	result := items[4].ToolCallResult
+	assert.Equal(t, "call_1", result.ID)
+	assert.Equal(t, `{"city":"Paris"}`, compactJSON(t, result.Input.(json.RawMessage)))
+	assert.True(t, result.Result.IsError)
+	assert.Equal(t, "service unavailable", result.Error.Error())
+
+	assert.Equal(t, ToolCircuitOpen, items[5].ToolCircuit.State)
+	assert.Equal(t, 2026, items[5].ToolCircuit.RetryAt.Year())
+}
+
+func TestResponseItemMigratesV0(t *testing.T) {
+	raw := readGoldenItems(t, "testdata/response_items_v0.json")
+
+	var event ResponseItem
+	assert.NoError(t, json.Unmarshal(raw[0], &event))
+	assert.Equal(t, ResponseItemSchemaVersion, event.SchemaVersion)
+	assert.Equal(t, "Checking the weather", event.Event.Delta.Text)
+
+	var result ResponseItem
+	assert.NoError(t, json.Unmarshal(raw[1], &result))
+	assert.Equal(t, "call_1", result.ToolCallResult.ID)
+	assert.Equal(t, "weather", result.ToolCallResult.Name)
+	assert.Equal(t, `{"city":"Paris"}`, compactJSON(t, result.ToolCallResult.Input.(json.RawMessage)))
+	assert.Equal(t, "service unavailable", result.ToolCallResult.Result.Content[0].Text)
+	assert.Nil(t, result.ToolCallResult.Error)
+
+	migrated, err := MigrateResponseItem(raw[1])
+	assert.NoError(t, err)
+	assert.Contains(t, string(migrated), `"schema_version":1`)
+	assert.Contains(t, string(migrated), `"id":"call_1"`)
+	assert.NotContains(t, string(migrated), `"ID"`)
+
+	// Current items pass through unchanged
+	again, err := MigrateResponseItem(migrated)
+	assert.NoError(t, err)
+	assert.Equal(t, string(migrated), string(again))
+}
+
+func TestResponseItemFromNewerVersion(t *testing.T) {
+	var item ResponseItem
+	err := json.Unmarshal([]byte(`{"type":"status","status":{"text":"hi"},"future_field":true,"schema_version":99}`), &item)
+	assert.NoError(t, err)
+	assert.Equal(t, 99, item.SchemaVersion)
+	assert.Equal(t, "hi", item.Status.Text)
+}
@@ -112,6 +112,36 @@ func TestResponse_ToolCallResults(t *testing.T) {
 	})
 }
 
+func TestResponse_Citations(t *testing.T) {
+	search := NewToolResultText("results").WithSources(
+		&llm.CitationContent{URL: "https://go.dev", Title: "Go"},
+		&llm.CitationContent{URL: "https://example.com", Title: "Example"},
+	)
+	resp := &Response{
+		Items: []*ResponseItem{
+			{
+				Type:           ResponseItemTypeToolCallResult,
+				ToolCallResult: &ToolCallResult{Name: "WebSearch", Result: search},
+			},
+			{
+				Type: ResponseItemTypeMessage,
+				Message: &llm.Message{Role: llm.Assistant, Content: []llm.Content{
+					&llm.TextContent{Text: "Go is fast.", Citations: []llm.Citation{
+						&llm.CitationContent{URL: "https://go.dev", StartIndex: 0, EndIndex: 5},
+					}},
+				}},
+			},
+		},
+	}
+	citations := resp.Citations()
+	assert.Len(t, citations, 2)
+	assert.Equal(t, "https://go.dev", citations[0].URL)
+	assert.Equal(t, 5, citations[0].EndIndex)
+	// Tool sources the answer doesn't cite follow, without spans
+	assert.Equal(t, "https://example.com", citations[1].URL)
+	assert.False(t, citations[1].HasSpan())
+}
+
 func TestResponse_NoIDField(t *testing.T) {
 	resp := &Response{
 		Model: "test-model",
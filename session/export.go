@@ -0,0 +1,374 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// DefaultExportToolResultLimit is the number of characters of each tool
+// result shown in an export.
+const DefaultExportToolResultLimit = 4000
+
+// exportToolSummaryLimit bounds the tool input shown next to a tool's name.
+const exportToolSummaryLimit = 80
+
+// ExportOption configures ExportMarkdown and ExportHTML.
+type ExportOption func(*exportOptions)
+
+type exportOptions struct {
+	toolResultLimit int
+	thinking        bool
+}
+
+// WithToolResultLimit shows at most n characters of each tool result. Zero
+// or less shows results in full. Defaults to DefaultExportToolResultLimit.
+func WithToolResultLimit(n int) ExportOption {
+	return func(o *exportOptions) {
+		o.toolResultLimit = n
+	}
+}
+
+// WithThinking includes the model's thinking blocks, collapsed. They are
+// left out by default.
+func WithThinking() ExportOption {
+	return func(o *exportOptions) {
+		o.thinking = true
+	}
+}
+
+// ExportMarkdown writes the session's full transcript to w as a Markdown
+// document, for sharing a conversation in an issue or pull request or
+// keeping it for audit. Each tool call is a collapsible <details> block
+// showing the tool's name and truncated input, with the full input and the
+// tool's result inside. Images and documents given by URL or inline data
+// are embedded or linked; files uploaded to a provider are named.
+//
+// The transcript includes messages replaced by compaction, as returned by
+// AllMessages.
+func ExportMarkdown(sess *Session, w io.Writer, opts ...ExportOption) error {
+	return export(sess, w, &markdownExporter{}, opts)
+}
+
+// ExportHTML writes the session's full transcript to w as a standalone HTML
+// page. It renders the same content as ExportMarkdown. Message text is shown
+// as preformatted text rather than rendered as Markdown.
+func ExportHTML(sess *Session, w io.Writer, opts ...ExportOption) error {
+	return export(sess, w, &htmlExporter{}, opts)
+}
+
+// exporter renders the parts of a transcript in one output format.
+type exporter interface {
+	begin(title string)
+	role(role llm.Role)
+	text(text string)
+	thinking(text string)
+	image(src string)
+	link(label, href string)
+	toolCall(call *exportedToolCall)
+	end() string
+}
+
+type exportedToolCall struct {
+	name      string
+	summary   string
+	input     string
+	result    string
+	hasResult bool
+	isError   bool
+}
+
+func export(sess *Session, w io.Writer, out exporter, opts []ExportOption) error {
+	options := exportOptions{toolResultLimit: DefaultExportToolResultLimit}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	messages, err := sess.AllMessages(context.Background())
+	if err != nil {
+		return err
+	}
+	results := map[string]*llm.ToolResultContent{}
+	for _, msg := range messages {
+		for _, c := range msg.Content {
+			if result, ok := c.(*llm.ToolResultContent); ok {
+				results[result.ToolUseID] = result
+			}
+		}
+	}
+
+	title := sess.Title()
+	if title == "" {
+		title = "Session " + sess.ID()
+	}
+	out.begin(title)
+	var lastRole llm.Role
+	for _, msg := range messages {
+		if hasToolResults(msg) {
+			// Results are shown with their calls
+			continue
+		}
+		if msg.Role != lastRole {
+			out.role(msg.Role)
+			lastRole = msg.Role
+		}
+		for _, c := range msg.Content {
+			switch c := c.(type) {
+			case *llm.TextContent:
+				if strings.TrimSpace(c.Text) != "" {
+					out.text(c.Text)
+				}
+			case *llm.RefusalContent:
+				out.text(c.Text)
+			case *llm.ThinkingContent:
+				if options.thinking && strings.TrimSpace(c.Thinking) != "" {
+					out.thinking(c.Thinking)
+				}
+			case *llm.ImageContent:
+				exportSource(out, "Image", c.Source, true)
+			case *llm.DocumentContent:
+				label := c.Title
+				if label == "" {
+					label = "Document"
+				}
+				exportSource(out, label, c.Source, false)
+			case *llm.ToolUseContent:
+				out.toolCall(exportToolCall(c, results[c.ID], options.toolResultLimit))
+			}
+		}
+	}
+	_, err = io.WriteString(w, out.end())
+	return err
+}
+
+// exportSource shows an image inline, or links to a document, when its data
+// is available, and otherwise names it.
+func exportSource(out exporter, label string, source *llm.ContentSource, inline bool) {
+	if source == nil {
+		return
+	}
+	var href string
+	switch source.Type {
+	case llm.ContentSourceTypeURL:
+		href = source.URL
+	case llm.ContentSourceTypeBase64:
+		href = "data:" + source.MediaType + ";base64," + source.Data
+	case llm.ContentSourceTypeText:
+		out.text(label + ":\n\n" + source.Data)
+		return
+	case llm.ContentSourceTypeFile:
+		out.text(fmt.Sprintf("[%s: file %s]", label, source.FileID))
+		return
+	}
+	if href == "" {
+		return
+	}
+	if inline {
+		out.image(href)
+	} else {
+		out.link(label, href)
+	}
+}
+
+func exportToolCall(call *llm.ToolUseContent, result *llm.ToolResultContent, limit int) *exportedToolCall {
+	exported := &exportedToolCall{
+		name:    call.Name,
+		summary: truncateExport(strings.Join(strings.Fields(string(call.Input)), " "), exportToolSummaryLimit),
+		input:   string(call.Input),
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, call.Input, "", "  "); err == nil {
+		exported.input = indented.String()
+	}
+	if result != nil {
+		exported.hasResult = true
+		exported.isError = result.IsError
+		exported.result = truncateExport(toolResultText(result.Content), limit)
+	}
+	return exported
+}
+
+// toolResultText renders a tool result's content as text.
+func toolResultText(content any) string {
+	switch c := content.(type) {
+	case nil:
+		return ""
+	case string:
+		return c
+	case []llm.Content:
+		var parts []string
+		for _, block := range c {
+			switch block := block.(type) {
+			case *llm.TextContent:
+				parts = append(parts, block.Text)
+			case *llm.ImageContent:
+				parts = append(parts, "[image]")
+			default:
+				parts = append(parts, fmt.Sprintf("[%s]", block.Type()))
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return fmt.Sprint(content)
+	}
+	return string(data)
+}
+
+func hasToolResults(msg *llm.Message) bool {
+	for _, c := range msg.Content {
+		if _, ok := c.(*llm.ToolResultContent); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateExport shortens text to limit characters. A limit of zero or less
+// means no limit.
+func truncateExport(text string, limit int) string {
+	runes := []rune(text)
+	if limit <= 0 || len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
+
+func roleLabel(role llm.Role) string {
+	if role == "" {
+		return "Message"
+	}
+	return strings.ToUpper(string(role[:1])) + string(role[1:])
+}
+
+//// Markdown //////////////////////////////////////////////////////////////////
+
+type markdownExporter struct {
+	sb strings.Builder
+}
+
+func (m *markdownExporter) begin(title string) {
+	fmt.Fprintf(&m.sb, "# %s\n", title)
+}
+
+func (m *markdownExporter) role(role llm.Role) {
+	fmt.Fprintf(&m.sb, "\n## %s\n", roleLabel(role))
+}
+
+func (m *markdownExporter) text(text string) {
+	fmt.Fprintf(&m.sb, "\n%s\n", strings.TrimSpace(text))
+}
+
+func (m *markdownExporter) thinking(text string) {
+	fmt.Fprintf(&m.sb, "\n<details>\n<summary>Thinking</summary>\n\n%s\n\n</details>\n", strings.TrimSpace(text))
+}
+
+func (m *markdownExporter) image(src string) {
+	fmt.Fprintf(&m.sb, "\n![image](%s)\n", src)
+}
+
+func (m *markdownExporter) link(label, href string) {
+	fmt.Fprintf(&m.sb, "\n[%s](%s)\n", label, href)
+}
+
+func (m *markdownExporter) toolCall(call *exportedToolCall) {
+	fmt.Fprintf(&m.sb, "\n<details>\n<summary>Tool: <code>%s</code> <code>%s</code></summary>\n\n",
+		html.EscapeString(call.name), html.EscapeString(call.summary))
+	fmt.Fprintf(&m.sb, "**Input**\n\n%s\n", markdownFence(call.input, "json"))
+	if call.hasResult {
+		heading := "Result"
+		if call.isError {
+			heading = "Error"
+		}
+		fmt.Fprintf(&m.sb, "\n**%s**\n\n%s\n", heading, markdownFence(call.result, ""))
+	}
+	m.sb.WriteString("\n</details>\n")
+}
+
+func (m *markdownExporter) end() string {
+	return m.sb.String()
+}
+
+// markdownFence wraps text in a code fence longer than any backtick run it
+// contains.
+func markdownFence(text, lang string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence
+}
+
+//// HTML //////////////////////////////////////////////////////////////////////
+
+const exportHTMLStyle = `body{font-family:system-ui,sans-serif;max-width:52rem;margin:2rem auto;padding:0 1rem;line-height:1.5;color:#1f2328}
+h2{font-size:1rem;text-transform:uppercase;letter-spacing:.05em;color:#59636e;border-top:1px solid #d1d9e0;padding-top:1rem;margin-top:2rem}
+.text{white-space:pre-wrap}
+details{border:1px solid #d1d9e0;border-radius:6px;padding:.5rem .75rem;margin:.75rem 0}
+summary{cursor:pointer}
+pre{background:#f6f8fa;padding:.75rem;border-radius:6px;overflow-x:auto;white-space:pre-wrap}
+.error{color:#d1242f}
+img{max-width:100%}`
+
+type htmlExporter struct {
+	sb strings.Builder
+}
+
+func (h *htmlExporter) begin(title string) {
+	title = html.EscapeString(title)
+	fmt.Fprintf(&h.sb, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n<h1>%s</h1>\n",
+		title, exportHTMLStyle, title)
+}
+
+func (h *htmlExporter) role(role llm.Role) {
+	fmt.Fprintf(&h.sb, "<h2>%s</h2>\n", html.EscapeString(roleLabel(role)))
+}
+
+func (h *htmlExporter) text(text string) {
+	fmt.Fprintf(&h.sb, "<div class=\"text\">%s</div>\n", html.EscapeString(strings.TrimSpace(text)))
+}
+
+func (h *htmlExporter) thinking(text string) {
+	fmt.Fprintf(&h.sb, "<details>\n<summary>Thinking</summary>\n<div class=\"text\">%s</div>\n</details>\n",
+		html.EscapeString(strings.TrimSpace(text)))
+}
+
+func (h *htmlExporter) image(src string) {
+	fmt.Fprintf(&h.sb, "<p><img src=\"%s\" alt=\"image\"></p>\n", html.EscapeString(src))
+}
+
+func (h *htmlExporter) link(label, href string) {
+	fmt.Fprintf(&h.sb, "<p><a href=\"%s\">%s</a></p>\n", html.EscapeString(href), html.EscapeString(label))
+}
+
+func (h *htmlExporter) toolCall(call *exportedToolCall) {
+	fmt.Fprintf(&h.sb, "<details>\n<summary>Tool: <code>%s</code> <code>%s</code></summary>\n",
+		html.EscapeString(call.name), html.EscapeString(call.summary))
+	fmt.Fprintf(&h.sb, "<p><strong>Input</strong></p>\n<pre>%s</pre>\n", html.EscapeString(call.input))
+	if call.hasResult {
+		if call.isError {
+			fmt.Fprintf(&h.sb, "<p><strong class=\"error\">Error</strong></p>\n")
+		} else {
+			fmt.Fprintf(&h.sb, "<p><strong>Result</strong></p>\n")
+		}
+		fmt.Fprintf(&h.sb, "<pre>%s</pre>\n", html.EscapeString(call.result))
+	}
+	h.sb.WriteString("</details>\n")
+}
+
+func (h *htmlExporter) end() string {
+	h.sb.WriteString("</body>\n</html>\n")
+	return h.sb.String()
+}
@@ -0,0 +1,76 @@
+package session_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/dive/session"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func exportSession(t *testing.T) *session.Session {
+	t.Helper()
+	sess := session.New("s1")
+	sess.SetTitle("Fix the build")
+	err := sess.SaveTurn(context.Background(), []*llm.Message{
+		{Role: llm.User, Content: []llm.Content{
+			&llm.TextContent{Text: "Why does the build fail?"},
+			&llm.ImageContent{Source: &llm.ContentSource{Type: llm.ContentSourceTypeURL, URL: "https://example.com/error.png"}},
+		}},
+		{Role: llm.Assistant, Content: []llm.Content{
+			&llm.ThinkingContent{Thinking: "Check the log first."},
+			&llm.ToolUseContent{ID: "call_1", Name: "Read", Input: []byte(`{"path":"build.log"}`)},
+		}},
+		{Role: llm.User, Content: []llm.Content{
+			&llm.ToolResultContent{ToolUseID: "call_1", Content: "error: " + strings.Repeat("x", 100)},
+		}},
+		llm.NewAssistantTextMessage("A dependency is missing. Use `go mod tidy`."),
+	}, nil)
+	assert.NoError(t, err)
+	return sess
+}
+
+func TestExportMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	err := session.ExportMarkdown(exportSession(t), &buf, session.WithToolResultLimit(20))
+	assert.NoError(t, err)
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "# Fix the build\n"))
+	assert.Contains(t, out, "## User\n\nWhy does the build fail?")
+	assert.Contains(t, out, "![image](https://example.com/error.png)")
+	assert.Contains(t, out, "<summary>Tool: <code>Read</code> <code>{&#34;path&#34;:&#34;build.log&#34;}</code></summary>")
+	assert.Contains(t, out, "```json\n{\n  \"path\": \"build.log\"\n}\n```")
+	assert.Contains(t, out, "**Result**\n\n```\nerror: xxxxxxxxxxxxx…\n```")
+	assert.Contains(t, out, "A dependency is missing. Use `go mod tidy`.")
+	// One heading per speaker change; tool results don't start a turn
+	assert.Equal(t, 1, strings.Count(out, "## Assistant"))
+	assert.Equal(t, 1, strings.Count(out, "## User"))
+	// Thinking is left out by default
+	assert.NotContains(t, out, "Check the log first.")
+}
+
+func TestExportMarkdownWithThinking(t *testing.T) {
+	var buf bytes.Buffer
+	err := session.ExportMarkdown(exportSession(t), &buf, session.WithThinking())
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "<summary>Thinking</summary>\n\nCheck the log first.")
+}
+
+func TestExportHTML(t *testing.T) {
+	var buf bytes.Buffer
+	err := session.ExportHTML(exportSession(t), &buf)
+	assert.NoError(t, err)
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.Contains(t, out, "<title>Fix the build</title>")
+	assert.Contains(t, out, "<img src=\"https://example.com/error.png\" alt=\"image\">")
+	assert.Contains(t, out, "<summary>Tool: <code>Read</code>")
+	assert.Contains(t, out, "<pre>error: "+strings.Repeat("x", 100)+"</pre>")
+	assert.Contains(t, out, "Use `go mod tidy`.")
+	assert.True(t, strings.HasSuffix(out, "</html>\n"))
+}
@@ -87,14 +87,16 @@ type FileStore struct {
 	// Guarded by mu. Never take a session's lock while holding mu — the
 	// established lock order is session first, store second.
 	sessions map[string]*Session
+	clock    dive.Clock
+	ids      dive.IDSource
 }
 
 // NewFileStore creates a FileStore rooted at dir. The directory is created
 // if it does not exist. The returned store uses pagecache durability for
 // the hot-path event append — see FileStore's documentation for the
 // trade-off and NewFileStoreWithSync for power-loss durability.
-func NewFileStore(dir string) (*FileStore, error) {
-	return NewFileStoreWithSync(dir, false)
+func NewFileStore(dir string, opts ...Option) (*FileStore, error) {
+	return NewFileStoreWithSync(dir, false, opts...)
 }
 
 // NewFileStoreWithSync creates a FileStore rooted at dir with explicit
@@ -103,7 +105,7 @@ func NewFileStore(dir string) (*FileStore, error) {
 // successfully returned SaveTurn call survives an immediate power loss
 // at the cost of a disk round-trip per append. When sync is false the
 // store behaves like NewFileStore.
-func NewFileStoreWithSync(dir string, sync bool) (*FileStore, error) {
+func NewFileStoreWithSync(dir string, sync bool, opts ...Option) (*FileStore, error) {
 	if strings.HasPrefix(dir, "~/") {
 		home, err := os.UserHomeDir()
 		if err != nil {
@@ -115,7 +117,8 @@ func NewFileStoreWithSync(dir string, sync bool) (*FileStore, error) {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, err
 	}
-	return &FileStore{dir: dir, sync: sync, sessions: make(map[string]*Session)}, nil
+	o := applyOptions(opts)
+	return &FileStore{dir: dir, sync: sync, sessions: make(map[string]*Session), clock: o.clock, ids: o.ids}, nil
 }
 
 // validateID rejects session IDs that could escape the store directory.
@@ -187,7 +190,7 @@ func (s *FileStore) Open(ctx context.Context, id string) (*Session, error) {
 			return nil, err
 		}
 		// Create new session
-		now := time.Now()
+		now := s.clock.Now()
 		data = &sessionData{
 			ID:        id,
 			CreatedAt: now,
@@ -207,6 +210,8 @@ func (s *FileStore) Open(ctx context.Context, id string) (*Session, error) {
 	sess := &Session{
 		data:     data,
 		appender: s,
+		clock:    s.clock,
+		ids:      s.ids,
 	}
 	s.sessions[id] = sess
 	return sess, nil
@@ -5,7 +5,8 @@ import (
 	"maps"
 	"sort"
 	"sync"
-	"time"
+
+	"github.com/deepnoodle-ai/dive"
 )
 
 // MemoryStore is an in-memory Store implementation.
@@ -15,12 +16,17 @@ import (
 type MemoryStore struct {
 	mu       sync.RWMutex
 	sessions map[string]*Session
+	clock    dive.Clock
+	ids      dive.IDSource
 }
 
 // NewMemoryStore creates an empty in-memory store.
-func NewMemoryStore() *MemoryStore {
+func NewMemoryStore(opts ...Option) *MemoryStore {
+	o := applyOptions(opts)
 	return &MemoryStore{
 		sessions: make(map[string]*Session),
+		clock:    o.clock,
+		ids:      o.ids,
 	}
 }
 
@@ -29,7 +35,7 @@ func (s *MemoryStore) Open(ctx context.Context, id string) (*Session, error) {
 	defer s.mu.Unlock()
 	sess, ok := s.sessions[id]
 	if !ok {
-		now := time.Now()
+		now := s.clock.Now()
 		sess = &Session{
 			data: &sessionData{
 				ID:        id,
@@ -37,6 +43,8 @@ func (s *MemoryStore) Open(ctx context.Context, id string) (*Session, error) {
 				UpdatedAt: now,
 			},
 			appender: s,
+			clock:    s.clock,
+			ids:      s.ids,
 		}
 		s.sessions[id] = sess
 	}
@@ -35,19 +35,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"maps"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/deepnoodle-ai/dive"
 	"github.com/deepnoodle-ai/dive/llm"
 )
 
-// eventCounter ensures unique event IDs under concurrent generation.
-var eventCounter uint64
-
 // ErrNotFound is returned when a session does not exist.
 var ErrNotFound = errors.New("session not found")
 
@@ -295,21 +290,73 @@ type Session struct {
 	mu       sync.RWMutex
 	data     *sessionData
 	appender eventAppender // nil for in-memory sessions
+	clock    dive.Clock
+	ids      dive.IDSource
+}
+
+// Option configures a Session or Store.
+type Option func(*options)
+
+type options struct {
+	clock dive.Clock
+	ids   dive.IDSource
+}
+
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.clock = dive.ClockOrDefault(o.clock)
+	o.ids = dive.IDSourceOrDefault(o.ids)
+	return o
+}
+
+// WithClock sets the clock used for session and event timestamps. Defaults
+// to dive.SystemClock; pass a dive.FakeClock in tests for deterministic
+// timestamps. Sessions opened from a store use the store's clock.
+func WithClock(clock dive.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithIDSource sets the source of event IDs. Defaults to
+// dive.SystemIDSource; pass a dive.SeededIDSource, together with a
+// dive.FakeClock, for sessions whose events replay identically. Sessions
+// opened from a store use the store's source.
+func WithIDSource(ids dive.IDSource) Option {
+	return func(o *options) {
+		o.ids = ids
+	}
 }
 
 // New creates an in-memory session with the given ID.
 // Messages are stored in memory only and lost when the process exits.
-func New(id string) *Session {
-	now := time.Now()
+func New(id string, opts ...Option) *Session {
+	o := applyOptions(opts)
+	now := o.clock.Now()
 	return &Session{
 		data: &sessionData{
 			ID:        id,
 			CreatedAt: now,
 			UpdatedAt: now,
 		},
+		clock: o.clock,
+		ids:   o.ids,
 	}
 }
 
+// newEventID returns a new event identifier from the session's ID source.
+func (s *Session) newEventID() string {
+	return "evt-" + dive.IDSourceOrDefault(s.ids).NewID()
+}
+
+// now returns the current time from the session's clock.
+func (s *Session) now() time.Time {
+	return dive.ClockOrDefault(s.clock).Now()
+}
+
 // ID returns the session's unique identifier.
 func (s *Session) ID() string {
 	return s.data.ID
@@ -384,10 +431,11 @@ func (s *Session) SaveTurn(ctx context.Context, messages []*llm.Message, usage *
 	if s.data.Suspended {
 		return ErrSuspendedSession
 	}
+	now := s.now()
 	evt := &event{
-		ID:        newEventID(),
+		ID:        s.newEventID(),
 		Type:      eventTypeTurn,
-		Timestamp: time.Now(),
+		Timestamp: now,
 		// Deep-copy on ingestion: reads already deep-copy, so without this
 		// a caller mutating e.g. response.OutputMessages after SaveTurn
 		// would silently rewrite stored history.
@@ -499,7 +547,7 @@ func (s *Session) SaveSuspendedTurn(ctx context.Context, messages []*llm.Message
 	defer s.mu.Unlock()
 
 	return s.withRollback(ctx, func() {
-		now := time.Now()
+		now := s.now()
 		replaceLast := s.data.Suspended &&
 			len(s.data.Events) > 0 &&
 			s.data.Events[len(s.data.Events)-1].Type == eventTypeTurn
@@ -514,7 +562,7 @@ func (s *Session) SaveSuspendedTurn(ctx context.Context, messages []*llm.Message
 			// TotalUsage does not undercount.
 			priorUsage = prev.Usage
 		} else {
-			evtID = newEventID()
+			evtID = s.newEventID()
 		}
 		evt := &event{
 			ID:        evtID,
@@ -562,7 +610,7 @@ func (s *Session) SaveResumedTurn(ctx context.Context, messages []*llm.Message,
 	}
 
 	return s.withRollback(ctx, func() {
-		now := time.Now()
+		now := s.now()
 		prev := s.data.Events[len(s.data.Events)-1]
 		evt := &event{
 			ID:        prev.ID,
@@ -600,7 +648,7 @@ func (s *Session) CancelSuspension(ctx context.Context) error {
 		s.data.Suspended = false
 		s.data.PendingToolCalls = nil
 		s.data.CompletedToolCalls = nil
-		s.data.UpdatedAt = time.Now()
+		s.data.UpdatedAt = s.now()
 	})
 }
 
@@ -686,7 +734,7 @@ func (s *Session) Fork(newID string) *Session {
 	for i, e := range s.data.Events {
 		events[i] = e.copy()
 	}
-	now := time.Now()
+	now := s.now()
 	forked := &Session{
 		data: &sessionData{
 			ID:         newID,
@@ -700,6 +748,8 @@ func (s *Session) Fork(newID string) *Session {
 			// launched the original suspend and cannot be resumed against a
 			// divergent branch.
 		},
+		clock: s.clock,
+		ids:   s.ids,
 	}
 	if s.data.Metadata != nil {
 		forked.data.Metadata = make(map[string]any, len(s.data.Metadata))
@@ -742,13 +792,13 @@ func (s *Session) Compact(ctx context.Context, summarize CompactFunc) error {
 	if err != nil {
 		return err
 	}
-	now := time.Now()
+	now := s.now()
 	// withRollback restores Events/UpdatedAt if putSession fails, so a
 	// persistence error never leaves the in-memory checkpoint diverged from
 	// the store (matching SaveTurn and the suspend paths).
 	return s.withRollback(ctx, func() {
 		s.data.Events = append(s.data.Events, &event{
-			ID:        newEventID(),
+			ID:        s.newEventID(),
 			Type:      eventTypeCompaction,
 			Timestamp: now,
 			Messages:  compacted,
@@ -867,9 +917,3 @@ func ForkSession(ctx context.Context, store Store, fromID, newID string) (*Sessi
 	}
 	return forked, nil
 }
-
-// newEventID generates a unique event identifier.
-func newEventID() string {
-	n := atomic.AddUint64(&eventCounter, 1)
-	return fmt.Sprintf("evt-%d-%d", time.Now().UnixNano(), n)
-}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/deepnoodle-ai/dive"
 	"github.com/deepnoodle-ai/dive/llm"
@@ -1376,3 +1377,110 @@ func TestFileStoreConcurrentOpenSingleInstance(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, n, len(msgs))
 }
+
+func TestStoreWithClock(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := dive.NewFakeClock(start)
+
+	newStores := map[string]func() session.Store{
+		"memory": func() session.Store { return session.NewMemoryStore(session.WithClock(clock)) },
+		"file": func() session.Store {
+			store, err := session.NewFileStore(t.TempDir(), session.WithClock(clock))
+			assert.NoError(t, err)
+			return store
+		},
+	}
+	for name, newStore := range newStores {
+		t.Run(name, func(t *testing.T) {
+			clock.Set(start)
+			store := newStore()
+			sess, err := store.Open(ctx, "s1")
+			assert.NoError(t, err)
+
+			clock.Advance(time.Minute)
+			err = sess.SaveTurn(ctx, []*llm.Message{llm.NewUserTextMessage("hi")}, nil)
+			assert.NoError(t, err)
+
+			result, err := store.List(ctx, nil)
+			assert.NoError(t, err)
+			assert.Len(t, result.Sessions, 1)
+			assert.Equal(t, start, result.Sessions[0].CreatedAt.UTC())
+			assert.Equal(t, start.Add(time.Minute), result.Sessions[0].UpdatedAt.UTC())
+		})
+	}
+}
+
+// scriptedLLM calls the "ticket" tool once, then answers with the tool's
+// output.
+type scriptedLLM struct{}
+
+func (scriptedLLM) Name() string { return "scripted" }
+
+func (scriptedLLM) Generate(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+	cfg := &llm.Config{}
+	cfg.Apply(opts...)
+	last := cfg.Messages[len(cfg.Messages)-1]
+	for _, c := range last.Content {
+		if result, ok := c.(*llm.ToolResultContent); ok {
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: result.Content.([]*dive.ToolResultContent)[0].Text}},
+				StopReason: llm.StopReasonEndTurn,
+			}, nil
+		}
+	}
+	return &llm.Response{
+		Role:       llm.Assistant,
+		Content:    []llm.Content{&llm.ToolUseContent{ID: "call_1", Name: "ticket", Input: []byte(`{}`)}},
+		StopReason: llm.StopReasonToolUse,
+	}, nil
+}
+
+func TestReplayProducesIdenticalIDs(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ticket := dive.FuncTool("ticket", "Opens a ticket",
+		func(ctx context.Context, input struct{}) (*dive.ToolResult, error) {
+			return dive.NewToolResultText("ticket " + dive.NewID(ctx)), nil
+		})
+
+	// run executes one turn with a fake clock and an ID source seeded with
+	// seed, and returns the reply and the session file.
+	run := func(seed uint64) (string, []byte) {
+		clock := dive.NewFakeClock(start)
+		clock.SetAutoAdvance(time.Second)
+		ids := dive.NewSeededIDSource(seed)
+		dir := t.TempDir()
+		store, err := session.NewFileStore(dir, session.WithClock(clock), session.WithIDSource(ids))
+		assert.NoError(t, err)
+		sess, err := store.Open(ctx, "s1")
+		assert.NoError(t, err)
+		agent, err := dive.NewAgent(dive.AgentOptions{
+			Model:    scriptedLLM{},
+			Tools:    []dive.Tool{ticket},
+			Session:  sess,
+			Clock:    clock,
+			IDSource: ids,
+		})
+		assert.NoError(t, err)
+		resp, err := agent.CreateResponse(ctx, dive.WithInput("open a ticket"))
+		assert.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(dir, "s1.jsonl"))
+		assert.NoError(t, err)
+		return resp.OutputText(), data
+	}
+
+	// Recording keeps the seed; replaying reuses it
+	const seed = 7
+	reply, file := run(seed)
+	assert.True(t, strings.HasPrefix(reply, "ticket "))
+	assert.Contains(t, string(file), `"id":"evt-`)
+
+	replayReply, replayFile := run(seed)
+	assert.Equal(t, reply, replayReply)
+	assert.Equal(t, string(file), string(replayFile))
+
+	otherReply, _ := run(seed + 1)
+	assert.NotEqual(t, reply, otherReply)
+}
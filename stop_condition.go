@@ -0,0 +1,39 @@
+package dive
+
+import (
+	"context"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// StopCondition decides whether the agent should stop before its next model
+// request, for goal-directed termination such as stopping once a file
+// exists or the output contains a marker. It receives the response so far
+// and the full conversation, including the latest tool results. Returning
+// true halts the agent with reason in Response.StopReason.
+type StopCondition func(ctx context.Context, response *Response, messages []*llm.Message) (stop bool, reason string)
+
+// WithStopCondition adds a condition that can end this call early. After
+// each model response that calls tools, once the tools have run and their
+// results are recorded, the conditions are evaluated in order before the
+// next model request. The first that returns true stops the agent: the
+// response ends with the tool results message, StopReason holds the
+// condition's reason, and Stop hooks don't run. Conditions aren't
+// evaluated after a final response without tool calls, since the agent
+// stops there anyway.
+func WithStopCondition(condition StopCondition) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.StopConditions = append(opts.StopConditions, condition)
+	}
+}
+
+// checkStopConditions returns the reason of the first condition that asks
+// to stop, and whether any did.
+func checkStopConditions(ctx context.Context, conditions []StopCondition, response *Response, messages []*llm.Message) (string, bool) {
+	for _, condition := range conditions {
+		if stop, reason := condition(ctx, response, messages); stop {
+			return reason, true
+		}
+	}
+	return "", false
+}
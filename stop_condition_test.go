@@ -0,0 +1,84 @@
+package dive
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestStopCondition(t *testing.T) {
+	// The model calls the step tool on every turn and never finishes on
+	// its own
+	generations := 0
+	model := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			generations++
+			return &llm.Response{
+				Role: llm.Assistant,
+				Content: []llm.Content{&llm.ToolUseContent{
+					ID: fmt.Sprintf("call_%d", generations), Name: "step", Input: []byte(`{}`),
+				}},
+				StopReason: llm.StopReasonToolUse,
+			}, nil
+		},
+	}
+	steps := 0
+	step := &mockTool{name: "step", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		steps++
+		return NewToolResultText(fmt.Sprintf("step %d", steps)), nil
+	}}
+	stopHookCalled := false
+	agent, err := NewAgent(AgentOptions{
+		Model: model,
+		Tools: []Tool{step},
+		Hooks: Hooks{Stop: []StopHook{func(ctx context.Context, hctx *HookContext) (*StopDecision, error) {
+			stopHookCalled = true
+			return nil, nil
+		}}},
+	})
+	assert.NoError(t, err)
+
+	var seen int
+	resp, err := agent.CreateResponse(context.Background(),
+		WithInput("go"),
+		WithStopCondition(func(ctx context.Context, response *Response, messages []*llm.Message) (bool, string) {
+			seen = len(messages)
+			texts := toolResultTexts(response)
+			if texts[len(texts)-1] == "step 3" {
+				return true, "reached step 3"
+			}
+			return false, ""
+		}))
+	assert.NoError(t, err)
+	assert.Equal(t, "reached step 3", resp.StopReason)
+	assert.Equal(t, 3, generations)
+	assert.Equal(t, 3, steps)
+	assert.False(t, stopHookCalled)
+	// Input plus three tool calls and their results
+	assert.Equal(t, 7, seen)
+	assert.Len(t, resp.OutputMessages, 6)
+}
+
+func TestStopConditionNotMet(t *testing.T) {
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(&llm.ToolUseContent{ID: "call_1", Name: "noop", Input: []byte(`{}`)}),
+		Tools: []Tool{&mockTool{name: "noop"}},
+	})
+	assert.NoError(t, err)
+
+	calls := 0
+	resp, err := agent.CreateResponse(context.Background(),
+		WithInput("go"),
+		WithStopCondition(func(ctx context.Context, response *Response, messages []*llm.Message) (bool, string) {
+			calls++
+			assert.Len(t, response.OutputMessages, 2)
+			return false, ""
+		}))
+	assert.NoError(t, err)
+	assert.Equal(t, "", resp.StopReason)
+	assert.Equal(t, "done", resp.OutputText())
+	assert.Equal(t, 1, calls)
+}
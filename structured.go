@@ -0,0 +1,266 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// DefaultStructuredRetries is how many times GenerateStructured re-prompts
+// the model after an invalid response, unless WithStructuredRetries is set.
+const DefaultStructuredRetries = 2
+
+// structuredToolName is the name of the tool GenerateStructured forces the
+// model to call when its provider has no response format support.
+const structuredToolName = "submit_response"
+
+// StructuredOption configures GenerateStructured.
+type StructuredOption func(*structuredOptions)
+
+type structuredOptions struct {
+	retries int
+}
+
+// WithStructuredRetries sets how many times GenerateStructured re-prompts
+// the model after a response that fails validation. Zero disables retries,
+// and negative values are treated as zero.
+func WithStructuredRetries(retries int) StructuredOption {
+	return func(opts *structuredOptions) {
+		opts.retries = max(retries, 0)
+	}
+}
+
+// StructuredOutputError is returned by GenerateStructured when the model's
+// last response still didn't match the schema.
+type StructuredOutputError struct {
+	// Attempts is the number of responses the model generated.
+	Attempts int
+
+	// Errors describes how the last response failed validation.
+	Errors []string
+}
+
+func (e *StructuredOutputError) Error() string {
+	return fmt.Sprintf("structured output invalid after %d attempts: %s",
+		e.Attempts, strings.Join(e.Errors, "; "))
+}
+
+// GenerateStructured asks the agent's model to answer input with JSON
+// matching schema and decodes it into a T. It uses llm.WithResponseFormat
+// when the model implements llm.ResponseFormatter and supports a JSON schema
+// format, and otherwise forces a call to a tool whose input schema is
+// schema. The response is validated against schema, checking types,
+// required properties, and enum values; an invalid response is sent back to
+// the model with the validation errors, up to DefaultStructuredRetries
+// times. If every attempt fails, the error is a *StructuredOutputError.
+//
+// The call uses the agent's model and system prompt only. It doesn't run
+// tools, hooks, or sessions.
+func GenerateStructured[T any](ctx context.Context, agent *Agent, input string, schema *Schema, opts ...StructuredOption) (T, error) {
+	var result T
+	options := structuredOptions{retries: DefaultStructuredRetries}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	model := agent.Model()
+	format := &llm.ResponseFormat{
+		Type:   llm.ResponseFormatTypeJSONSchema,
+		Schema: schema,
+		Name:   "structured_response",
+	}
+	formatter, useFormat := model.(llm.ResponseFormatter)
+	useFormat = useFormat && formatter.SupportsResponseFormat(format)
+
+	genOpts := []llm.Option{}
+	if prompt := agent.SystemPrompt(); prompt != "" {
+		genOpts = append(genOpts, llm.WithSystemPrompt(prompt))
+	}
+	if useFormat {
+		genOpts = append(genOpts, llm.WithResponseFormat(format))
+	} else {
+		tool := llm.NewToolDefinition().
+			WithName(structuredToolName).
+			WithDescription("Submit your response. Its input is your entire answer.").
+			WithSchema(schema)
+		genOpts = append(genOpts,
+			llm.WithTools(tool),
+			llm.WithToolChoice(&llm.ToolChoice{Type: llm.ToolChoiceTypeTool, Name: structuredToolName}),
+		)
+	}
+
+	messages := []*llm.Message{llm.NewUserTextMessage(input)}
+	var errs []string
+	for attempt := 1; attempt <= options.retries+1; attempt++ {
+		resp, err := model.Generate(ctx, append(slices.Clone(genOpts), llm.WithMessages(messages...))...)
+		if err != nil {
+			return result, err
+		}
+
+		var data []byte
+		var call *llm.ToolUseContent
+		if useFormat {
+			data = []byte(resp.Message().Text())
+		} else {
+			for _, c := range resp.ToolCalls() {
+				if c.Name == structuredToolName {
+					call = c
+					data = c.Input
+					break
+				}
+			}
+		}
+
+		errs = validateStructured(data, schema)
+		if len(errs) == 0 {
+			var decoded T
+			err := json.Unmarshal(data, &decoded)
+			if err == nil {
+				return decoded, nil
+			}
+			errs = []string{fmt.Sprintf("response does not decode: %v", err)}
+		}
+
+		// Show the model its response and what was wrong with it
+		feedback := "Your response did not match the required schema:\n- " +
+			strings.Join(errs, "\n- ") + "\nRespond again with corrected JSON."
+		messages = append(messages, resp.Message())
+		if call != nil {
+			messages = append(messages, llm.NewToolResultMessage(&llm.ToolResultContent{
+				ToolUseID: call.ID,
+				Content:   feedback,
+				IsError:   true,
+			}))
+		} else if calls := resp.ToolCalls(); len(calls) > 0 {
+			// The model called something else; every call needs a result
+			results := make([]*llm.ToolResultContent, len(calls))
+			for i, c := range calls {
+				results[i] = &llm.ToolResultContent{ToolUseID: c.ID, Content: feedback, IsError: true}
+			}
+			messages = append(messages, llm.NewToolResultMessage(results...))
+		} else {
+			messages = append(messages, llm.NewUserTextMessage(feedback))
+		}
+	}
+	return result, &StructuredOutputError{Attempts: options.retries + 1, Errors: errs}
+}
+
+// validateStructured checks that data is JSON matching schema and returns a
+// description of each mismatch.
+func validateStructured(data []byte, schema *Schema) []string {
+	if len(data) == 0 {
+		return []string{"no JSON response was given"}
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []string{fmt.Sprintf("response is not valid JSON: %v", err)}
+	}
+	if schema == nil {
+		return nil
+	}
+	root := &SchemaProperty{
+		Type:                 schema.Type,
+		Properties:           schema.Properties,
+		Required:             schema.Required,
+		AdditionalProperties: schema.AdditionalProperties,
+		Items:                schema.Items,
+		Nullable:             schema.Nullable,
+	}
+	var errs []string
+	validateValue("response", value, root, &errs)
+	return errs
+}
+
+// validateValue appends to errs each way value at path fails to match prop.
+func validateValue(path string, value any, prop *SchemaProperty, errs *[]string) {
+	if prop == nil {
+		return
+	}
+	if value == nil {
+		if prop.Type != "" && prop.Type != Null && (prop.Nullable == nil || !*prop.Nullable) {
+			*errs = append(*errs, fmt.Sprintf("%s must not be null", path))
+		}
+		return
+	}
+	if len(prop.Enum) > 0 && !slices.ContainsFunc(prop.Enum, func(allowed any) bool {
+		return jsonEqual(allowed, value)
+	}) {
+		*errs = append(*errs, fmt.Sprintf("%s must be one of %s", path, formatEnum(prop.Enum)))
+		return
+	}
+	switch prop.Type {
+	case Object:
+		object, ok := value.(map[string]any)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s must be an object", path))
+			return
+		}
+		for _, name := range prop.Required {
+			if _, ok := object[name]; !ok {
+				*errs = append(*errs, fmt.Sprintf("%s is missing required property %q", path, name))
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child, ok := prop.Properties[name]
+			if !ok {
+				if prop.AdditionalProperties != nil && !*prop.AdditionalProperties {
+					*errs = append(*errs, fmt.Sprintf("%s has unexpected property %q", path, name))
+				}
+				continue
+			}
+			validateValue(path+"."+name, object[name], child, errs)
+		}
+	case Array:
+		array, ok := value.([]any)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s must be an array", path))
+			return
+		}
+		for i, item := range array {
+			validateValue(fmt.Sprintf("%s[%d]", path, i), item, prop.Items, errs)
+		}
+	case String:
+		if _, ok := value.(string); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s must be a string", path))
+		}
+	case Number:
+		if _, ok := value.(float64); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s must be a number", path))
+		}
+	case Integer:
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			*errs = append(*errs, fmt.Sprintf("%s must be an integer", path))
+		}
+	case Boolean:
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s must be a boolean", path))
+		}
+	}
+}
+
+// jsonEqual reports whether a and b encode to the same JSON, so an enum
+// value of 1 matches a decoded float64(1).
+func jsonEqual(a, b any) bool {
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aj) == string(bj)
+}
+
+func formatEnum(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		encoded, _ := json.Marshal(v)
+		parts[i] = string(encoded)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,132 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+type ticket struct {
+	Title    string   `json:"title"`
+	Priority string   `json:"priority"`
+	Labels   []string `json:"labels"`
+}
+
+var ticketSchema = schema.NewSchema(map[string]*schema.Property{
+	"title":    schema.StringProp("Short summary"),
+	"priority": schema.EnumProp("Urgency", "low", "high"),
+	"labels":   schema.ArrayProp(&schema.Property{Type: schema.String}, "Labels"),
+}, "title", "priority")
+
+// formatLLM is a mock that supports response formats.
+type formatLLM struct{ mockLLM }
+
+func (m *formatLLM) SupportsResponseFormat(format *llm.ResponseFormat) bool { return true }
+
+func toolCallResponse(id, input string) *llm.Response {
+	return &llm.Response{
+		Role:       llm.Assistant,
+		Content:    []llm.Content{&llm.ToolUseContent{ID: id, Name: structuredToolName, Input: []byte(input)}},
+		StopReason: llm.StopReasonToolUse,
+	}
+}
+
+func TestGenerateStructuredRepromptsWithToolCall(t *testing.T) {
+	var configs []*llm.Config
+	model := &mockLLM{generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		config := &llm.Config{}
+		config.Apply(opts...)
+		configs = append(configs, config)
+		if len(configs) == 1 {
+			return toolCallResponse("call_1", `{"priority":"urgent"}`), nil
+		}
+		return toolCallResponse("call_2", `{"title":"Login fails","priority":"high","labels":["auth"]}`), nil
+	}}
+	agent, err := NewAgent(AgentOptions{Model: model, SystemPrompt: "You triage bugs."})
+	assert.NoError(t, err)
+
+	got, err := GenerateStructured[ticket](context.Background(), agent, "Users can't log in", ticketSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, ticket{Title: "Login fails", Priority: "high", Labels: []string{"auth"}}, got)
+
+	assert.Len(t, configs, 2)
+	first := configs[0]
+	assert.Nil(t, first.ResponseFormat)
+	assert.Equal(t, structuredToolName, first.ToolChoice.Name)
+	assert.Len(t, first.Tools, 1)
+	assert.True(t, strings.Contains(first.SystemPrompt, "You triage bugs."))
+
+	// The retry answers the first call with its validation errors
+	retry := configs[1].Messages
+	assert.Len(t, retry, 3)
+	result, ok := retry[2].Content[0].(*llm.ToolResultContent)
+	assert.True(t, ok)
+	assert.Equal(t, "call_1", result.ToolUseID)
+	assert.True(t, result.IsError)
+	feedback := result.Content.(string)
+	assert.True(t, strings.Contains(feedback, `missing required property "title"`))
+	assert.True(t, strings.Contains(feedback, `response.priority must be one of "low", "high"`))
+}
+
+func TestGenerateStructuredUsesResponseFormat(t *testing.T) {
+	var config llm.Config
+	model := &formatLLM{mockLLM{generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		config.Apply(opts...)
+		return &llm.Response{
+			Role:    llm.Assistant,
+			Content: []llm.Content{&llm.TextContent{Text: `{"title":"Crash on save","priority":"low"}`}},
+		}, nil
+	}}}
+	agent, err := NewAgent(AgentOptions{Model: model})
+	assert.NoError(t, err)
+
+	got, err := GenerateStructured[ticket](context.Background(), agent, "The editor crashes", ticketSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, "Crash on save", got.Title)
+	assert.Equal(t, llm.ResponseFormatTypeJSONSchema, config.ResponseFormat.Type)
+	assert.Equal(t, ticketSchema, config.ResponseFormat.Schema)
+	assert.Len(t, config.Tools, 0)
+}
+
+func TestGenerateStructuredReturnsValidationErrors(t *testing.T) {
+	calls := 0
+	model := &mockLLM{generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		calls++
+		return toolCallResponse("call", `{"title":7,"priority":"low","labels":"auth"}`), nil
+	}}
+	agent, err := NewAgent(AgentOptions{Model: model})
+	assert.NoError(t, err)
+
+	_, err = GenerateStructured[ticket](context.Background(), agent, "Anything", ticketSchema,
+		WithStructuredRetries(1))
+	var structuredErr *StructuredOutputError
+	assert.True(t, errors.As(err, &structuredErr))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 2, structuredErr.Attempts)
+	assert.Equal(t, []string{
+		"response.labels must be an array",
+		"response.title must be a string",
+	}, structuredErr.Errors)
+}
+
+func TestGenerateStructuredNegativeRetries(t *testing.T) {
+	calls := 0
+	model := &mockLLM{generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		calls++
+		return toolCallResponse("call", `{"title":7}`), nil
+	}}
+	agent, err := NewAgent(AgentOptions{Model: model})
+	assert.NoError(t, err)
+
+	_, err = GenerateStructured[ticket](context.Background(), agent, "Anything", ticketSchema,
+		WithStructuredRetries(-3))
+	var structuredErr *StructuredOutputError
+	assert.True(t, errors.As(err, &structuredErr))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, structuredErr.Attempts)
+}
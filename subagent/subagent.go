@@ -92,7 +92,7 @@ var GeneralPurpose = &Definition{
 //	myExplore.Model = "haiku"
 var Explore = &Definition{
 	Description:     "Fast read-only search agent for locating code. Use to find files, grep for symbols, or answer where-is-X questions.",
-	DisallowedTools: []string{"Edit", "Write", "Bash"},
+	DisallowedTools: []string{"Edit", "MultiEdit", "ApplyPatch", "Write", "Bash"},
 	Prompt:          explorePrompt,
 }
 
@@ -100,7 +100,7 @@ var Explore = &Definition{
 // Clone and modify to override the model or adjust the tool set.
 var Plan = &Definition{
 	Description:     "Software architect agent for designing implementation plans. Use when you need to plan an implementation strategy.",
-	DisallowedTools: []string{"Edit", "Write", "Bash"},
+	DisallowedTools: []string{"Edit", "MultiEdit", "ApplyPatch", "Write", "Bash"},
 	Prompt:          planPrompt,
 }
 
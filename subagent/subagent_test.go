@@ -68,7 +68,7 @@ func TestBuiltinDefinitions(t *testing.T) {
 		clone.Tools = append([]string{}, Explore.Tools...)
 		clone.Tools = append(clone.Tools, "Glob")
 
-		assert.Equal(t, []string{"Edit", "Write", "Bash"}, Explore.DisallowedTools)
+		assert.Equal(t, []string{"Edit", "MultiEdit", "ApplyPatch", "Write", "Bash"}, Explore.DisallowedTools)
 		assert.Equal(t, 0, len(Explore.Tools))
 	})
 }
@@ -0,0 +1,138 @@
+package dive
+
+import (
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// ThinkingHistory controls whether thinking blocks (llm.ThinkingContent and
+// llm.RedactedThinkingContent) from earlier turns are sent back to the model.
+//
+// Resending thinking lets a model build on its earlier reasoning, and some
+// providers require it: Anthropic rejects a tool result whose preceding
+// assistant message lost its thinking blocks. But prior reasoning can be
+// large, and where the model ignores it, resending only costs bandwidth and
+// input tokens. Thinking blocks in the turn in progress, back to the last
+// user message that isn't a tool result, are always sent so tool-use
+// continuations stay valid.
+type ThinkingHistory string
+
+const (
+	// ThinkingHistoryProviderDefault sends thinking blocks to the provider
+	// unchanged and lets it apply its API's rules. Anthropic and OpenAI
+	// Responses send them back; Gemini and Chat Completions drop them,
+	// since those APIs have no way to receive prior reasoning. This is the
+	// default.
+	ThinkingHistoryProviderDefault ThinkingHistory = "provider_default"
+
+	// ThinkingHistoryInclude sends thinking blocks from every earlier turn.
+	// Providers whose APIs can't receive prior reasoning still drop it, so
+	// with the current providers this matches the default; it keeps the
+	// behavior fixed if a provider's default changes.
+	ThinkingHistoryInclude ThinkingHistory = "include"
+
+	// ThinkingHistoryExclude removes thinking blocks from completed turns,
+	// keeping only those of the turn in progress. Use it to save tokens on
+	// long reasoning conversations.
+	ThinkingHistoryExclude ThinkingHistory = "exclude"
+
+	// ThinkingHistorySummarize replaces thinking blocks from completed
+	// turns with text written by the agent's ThinkingSummarizer, and drops
+	// redacted thinking, which can't be read. Use it when earlier turns
+	// came from a different model, such as after a handoff from a reasoning
+	// model: the model sees the earlier reasoning as ordinary text, which
+	// every provider accepts, instead of losing it or receiving blocks
+	// signed by another provider.
+	ThinkingHistorySummarize ThinkingHistory = "summarize"
+)
+
+// filterThinkingHistory returns messages with thinking blocks removed
+// according to mode. The input messages are never modified.
+func filterThinkingHistory(messages []*llm.Message, mode ThinkingHistory) []*llm.Message {
+	if mode != ThinkingHistoryExclude {
+		return messages
+	}
+	filtered, _ := rewriteThinking(messages, currentTurnStart(messages),
+		func(*llm.ThinkingContent) (llm.Content, error) { return nil, nil })
+	return filtered
+}
+
+// rewriteThinking returns messages with the thinking blocks of assistant
+// messages before end replaced by convert's result. Redacted thinking, and
+// thinking that convert returns nil for, is removed, along with messages
+// left empty. The input messages are never modified; when nothing changes,
+// messages itself is returned.
+func rewriteThinking(messages []*llm.Message, end int, convert func(*llm.ThinkingContent) (llm.Content, error)) ([]*llm.Message, error) {
+	var rewritten []*llm.Message
+	for i, msg := range messages {
+		if i >= end || msg.Role != llm.Assistant || !hasThinking(msg) {
+			if rewritten != nil {
+				rewritten = append(rewritten, msg)
+			}
+			continue
+		}
+		if rewritten == nil {
+			rewritten = make([]*llm.Message, i, len(messages))
+			copy(rewritten, messages[:i])
+		}
+		content := make([]llm.Content, 0, len(msg.Content))
+		for _, c := range msg.Content {
+			switch c := c.(type) {
+			case *llm.ThinkingContent:
+				converted, err := convert(c)
+				if err != nil {
+					return nil, err
+				}
+				if converted != nil {
+					content = append(content, converted)
+				}
+				continue
+			case *llm.RedactedThinkingContent:
+				continue
+			}
+			content = append(content, c)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		stripped := *msg
+		stripped.Content = content
+		rewritten = append(rewritten, &stripped)
+	}
+	if rewritten == nil {
+		return messages, nil
+	}
+	return rewritten, nil
+}
+
+// currentTurnStart returns the index of the last user message with no tool
+// results, i.e. the start of the turn in progress. Tool result messages may
+// also carry text, such as hook context or reminders, so they don't start a
+// turn.
+func currentTurnStart(messages []*llm.Message) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.Role == llm.User && !hasToolResult(msg) {
+			return i
+		}
+	}
+	return 0
+}
+
+func hasToolResult(msg *llm.Message) bool {
+	for _, c := range msg.Content {
+		if _, ok := c.(*llm.ToolResultContent); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hasThinking(msg *llm.Message) bool {
+	for _, c := range msg.Content {
+		switch c.(type) {
+		case *llm.ThinkingContent, *llm.RedactedThinkingContent:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package dive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func thinkingConversation() []*llm.Message {
+	return []*llm.Message{
+		llm.NewUserTextMessage("first question"),
+		{Role: llm.Assistant, Content: []llm.Content{
+			&llm.ThinkingContent{Thinking: "old reasoning", Signature: "sig1"},
+			&llm.TextContent{Text: "first answer"},
+		}},
+		llm.NewUserTextMessage("second question"),
+		{Role: llm.Assistant, Content: []llm.Content{
+			&llm.ThinkingContent{Thinking: "current reasoning", Signature: "sig2"},
+			&llm.ToolUseContent{ID: "call_1", Name: "search", Input: []byte(`{}`)},
+		}},
+		{Role: llm.User, Content: []llm.Content{
+			&llm.ToolResultContent{ToolUseID: "call_1", Content: "result"},
+			&llm.TextContent{Text: "hook context"},
+		}},
+	}
+}
+
+func TestFilterThinkingHistory(t *testing.T) {
+	t.Run("default and include keep everything", func(t *testing.T) {
+		messages := thinkingConversation()
+		assert.Equal(t, messages, filterThinkingHistory(messages, ThinkingHistoryProviderDefault))
+		assert.Equal(t, messages, filterThinkingHistory(messages, ThinkingHistoryInclude))
+		assert.Equal(t, messages, filterThinkingHistory(messages, ""))
+	})
+
+	t.Run("exclude strips completed turns only", func(t *testing.T) {
+		messages := thinkingConversation()
+		filtered := filterThinkingHistory(messages, ThinkingHistoryExclude)
+
+		assert.Len(t, filtered, 5)
+		assert.Len(t, filtered[1].Content, 1)
+		assert.Equal(t, "first answer", filtered[1].Content[0].(*llm.TextContent).Text)
+		// The turn in progress keeps its thinking for the tool continuation
+		assert.Equal(t, messages[3], filtered[3])
+
+		// The caller's messages are not modified
+		assert.Len(t, messages[1].Content, 2)
+	})
+
+	t.Run("exclude drops thinking-only messages", func(t *testing.T) {
+		messages := []*llm.Message{
+			llm.NewUserTextMessage("q1"),
+			{Role: llm.Assistant, Content: []llm.Content{&llm.RedactedThinkingContent{Data: "x"}}},
+			llm.NewUserTextMessage("q2"),
+		}
+		filtered := filterThinkingHistory(messages, ThinkingHistoryExclude)
+		assert.Len(t, filtered, 2)
+		assert.Equal(t, "q2", filtered[1].Text())
+	})
+
+	t.Run("exclude without thinking returns the input", func(t *testing.T) {
+		messages := []*llm.Message{llm.NewUserTextMessage("q1"), llm.NewAssistantTextMessage("a1")}
+		filtered := filterThinkingHistory(messages, ThinkingHistoryExclude)
+		assert.Equal(t, messages, filtered)
+	})
+}
+
+func TestAgentThinkingHistory(t *testing.T) {
+	var received []*llm.Message
+	mock := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			cfg := &llm.Config{}
+			cfg.Apply(opts...)
+			received = cfg.Messages
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "done"}},
+				StopReason: llm.StopReasonEndTurn,
+			}, nil
+		},
+	}
+	history := thinkingConversation()[:2]
+
+	agent, err := NewAgent(AgentOptions{Model: mock, ThinkingHistory: ThinkingHistoryExclude})
+	assert.NoError(t, err)
+
+	_, err = agent.CreateResponse(context.Background(),
+		WithMessages(append(history, llm.NewUserTextMessage("next"))...))
+	assert.NoError(t, err)
+	assert.Len(t, received[1].Content, 1)
+
+	// The per-call option overrides the agent default
+	_, err = agent.CreateResponse(context.Background(),
+		WithMessages(append(history, llm.NewUserTextMessage("next"))...),
+		WithThinkingHistory(ThinkingHistoryInclude))
+	assert.NoError(t, err)
+	assert.Len(t, received[1].Content, 2)
+}
@@ -0,0 +1,77 @@
+package dive
+
+import (
+	"context"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// ThinkingSummarizer converts a thinking block into text that a model can
+// read as part of the conversation. It may call another model to condense
+// the reasoning. See ThinkingHistorySummarize and SummarizeThinking.
+type ThinkingSummarizer func(ctx context.Context, thinking string) (string, error)
+
+// maxThinkingSummaryRunes bounds the reasoning kept by
+// DefaultThinkingSummarizer.
+const maxThinkingSummaryRunes = 2000
+
+// DefaultThinkingSummarizer keeps the reasoning text, shortened to its first
+// 2000 characters, inside <prior_reasoning> tags so the model can tell it
+// apart from what was said to the user.
+func DefaultThinkingSummarizer(ctx context.Context, thinking string) (string, error) {
+	thinking = strings.TrimSpace(thinking)
+	if thinking == "" {
+		return "", nil
+	}
+	if runes := []rune(thinking); len(runes) > maxThinkingSummaryRunes {
+		thinking = string(runes[:maxThinkingSummaryRunes]) + "..."
+	}
+	return "<prior_reasoning>\n" + thinking + "\n</prior_reasoning>", nil
+}
+
+// SummarizeThinking returns messages with every thinking block replaced by
+// text written by summarizer, and redacted thinking removed. Use it when
+// handing a conversation from one model to another, for example passing a
+// reasoning model's OutputMessages to an agent backed by a different
+// provider: that provider would otherwise drop the reasoning or reject
+// blocks signed by another provider. A nil summarizer uses
+// DefaultThinkingSummarizer. The input messages are never modified.
+func SummarizeThinking(ctx context.Context, messages []*llm.Message, summarizer ThinkingSummarizer) ([]*llm.Message, error) {
+	return summarizeThinking(ctx, messages, len(messages), summarizer)
+}
+
+// summarizeThinking converts the thinking blocks of messages before end.
+func summarizeThinking(ctx context.Context, messages []*llm.Message, end int, summarizer ThinkingSummarizer) ([]*llm.Message, error) {
+	if summarizer == nil {
+		summarizer = DefaultThinkingSummarizer
+	}
+	return rewriteThinking(messages, end, func(c *llm.ThinkingContent) (llm.Content, error) {
+		summary, err := summarizer(ctx, c.Thinking)
+		if err != nil || summary == "" {
+			return nil, err
+		}
+		return &llm.TextContent{Text: summary}, nil
+	})
+}
+
+// cachedThinkingSummarizer wraps summarizer so each distinct thinking text
+// is summarized once. The agent uses it for the length of a CreateResponse
+// call, since earlier turns are resent on every generation.
+func cachedThinkingSummarizer(summarizer ThinkingSummarizer) ThinkingSummarizer {
+	if summarizer == nil {
+		summarizer = DefaultThinkingSummarizer
+	}
+	summaries := map[string]string{}
+	return func(ctx context.Context, thinking string) (string, error) {
+		if summary, ok := summaries[thinking]; ok {
+			return summary, nil
+		}
+		summary, err := summarizer(ctx, thinking)
+		if err != nil {
+			return "", err
+		}
+		summaries[thinking] = summary
+		return summary, nil
+	}
+}
@@ -0,0 +1,105 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestSummarizeThinking(t *testing.T) {
+	t.Run("converts every thinking block", func(t *testing.T) {
+		messages := thinkingConversation()
+		summarized, err := SummarizeThinking(context.Background(), messages, nil)
+		assert.NoError(t, err)
+
+		assert.Len(t, summarized, 5)
+		assert.Equal(t, []llm.Content{
+			&llm.TextContent{Text: "<prior_reasoning>\nold reasoning\n</prior_reasoning>"},
+			&llm.TextContent{Text: "first answer"},
+		}, summarized[1].Content)
+		text, ok := summarized[3].Content[0].(*llm.TextContent)
+		assert.True(t, ok)
+		assert.Contains(t, text.Text, "current reasoning")
+		_, ok = summarized[3].Content[1].(*llm.ToolUseContent)
+		assert.True(t, ok)
+
+		// The caller's messages are not modified
+		_, ok = messages[1].Content[0].(*llm.ThinkingContent)
+		assert.True(t, ok)
+	})
+
+	t.Run("custom summarizer", func(t *testing.T) {
+		summarized, err := SummarizeThinking(context.Background(), thinkingConversation(),
+			func(ctx context.Context, thinking string) (string, error) {
+				return "Reasoned: " + strings.ToUpper(thinking), nil
+			})
+		assert.NoError(t, err)
+		assert.Equal(t, "Reasoned: OLD REASONING", summarized[1].Content[0].(*llm.TextContent).Text)
+	})
+
+	t.Run("summarizer error", func(t *testing.T) {
+		_, err := SummarizeThinking(context.Background(), thinkingConversation(),
+			func(ctx context.Context, thinking string) (string, error) {
+				return "", errors.New("boom")
+			})
+		assert.Error(t, err)
+	})
+
+	t.Run("redacted thinking is dropped", func(t *testing.T) {
+		messages := []*llm.Message{
+			llm.NewUserTextMessage("q1"),
+			{Role: llm.Assistant, Content: []llm.Content{&llm.RedactedThinkingContent{Data: "x"}}},
+			llm.NewUserTextMessage("q2"),
+		}
+		summarized, err := SummarizeThinking(context.Background(), messages, nil)
+		assert.NoError(t, err)
+		assert.Len(t, summarized, 2)
+	})
+}
+
+func TestDefaultThinkingSummarizerTruncates(t *testing.T) {
+	summary, err := DefaultThinkingSummarizer(context.Background(), strings.Repeat("a", 5000))
+	assert.NoError(t, err)
+	assert.True(t, len(summary) < 2100)
+	assert.Contains(t, summary, "...\n</prior_reasoning>")
+}
+
+func TestAgentThinkingHistorySummarize(t *testing.T) {
+	var received []*llm.Message
+	calls := 0
+	mock := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			cfg := &llm.Config{}
+			cfg.Apply(opts...)
+			received = cfg.Messages
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "done"}},
+				StopReason: llm.StopReasonEndTurn,
+			}, nil
+		},
+	}
+	agent, err := NewAgent(AgentOptions{
+		Model:           mock,
+		ThinkingHistory: ThinkingHistorySummarize,
+		ThinkingSummarizer: func(ctx context.Context, thinking string) (string, error) {
+			calls++
+			return "summary of " + thinking, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	history := thinkingConversation()[:2]
+	_, err = agent.CreateResponse(context.Background(),
+		WithMessages(append(history, llm.NewUserTextMessage("next"))...))
+	assert.NoError(t, err)
+	assert.Equal(t, []llm.Content{
+		&llm.TextContent{Text: "summary of old reasoning"},
+		&llm.TextContent{Text: "first answer"},
+	}, received[1].Content)
+	assert.Equal(t, 1, calls)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/wonton/schema"
@@ -24,8 +25,54 @@ type ToolAnnotations struct {
 	// to sequential execution. Use for tools that mutate shared state (a
 	// working-directory chdir, a non-thread-safe SDK, a singleton resource).
 	// Default false = parallel-safe, matching the existing behavior.
-	SequentialOnlyHint bool           `json:"sequentialOnlyHint,omitempty"`
-	Extra              map[string]any `json:"extra,omitempty"`
+	SequentialOnlyHint bool `json:"sequentialOnlyHint,omitempty"`
+	// UsageHint is a one-line hint on when or how to use the tool. Along
+	// with Examples, it is appended to the description sent to the model
+	// only when AgentOptions.IncludeToolExamples is set.
+	UsageHint string `json:"usageHint,omitempty"`
+	// Examples are sample invocations appended to the description sent to
+	// the model when AgentOptions.IncludeToolExamples is set.
+	Examples []ToolExample  `json:"examples,omitempty"`
+	Extra    map[string]any `json:"extra,omitempty"`
+}
+
+// ToolExample is a sample invocation of a tool. See ToolAnnotations.Examples.
+type ToolExample struct {
+	// Description says what the example does.
+	Description string `json:"description,omitempty"`
+	// Input is the example tool input, encoded as JSON for the model.
+	Input any `json:"input"`
+}
+
+// DescribeToolUsage returns description with the annotations' UsageHint and
+// Examples appended, formatted for the model. It returns description
+// unchanged if the annotations have neither.
+func DescribeToolUsage(description string, annotations *ToolAnnotations) string {
+	if annotations == nil || (annotations.UsageHint == "" && len(annotations.Examples) == 0) {
+		return description
+	}
+	var sb strings.Builder
+	sb.WriteString(description)
+	if annotations.UsageHint != "" {
+		sb.WriteString("\n\nUsage: ")
+		sb.WriteString(annotations.UsageHint)
+	}
+	if len(annotations.Examples) > 0 {
+		sb.WriteString("\n\nExamples:")
+		for _, example := range annotations.Examples {
+			input, err := json.Marshal(example.Input)
+			if err != nil {
+				continue
+			}
+			sb.WriteString("\n- ")
+			if example.Description != "" {
+				sb.WriteString(example.Description)
+				sb.WriteString(": ")
+			}
+			sb.Write(input)
+		}
+	}
+	return sb.String()
 }
 
 func (a *ToolAnnotations) MarshalJSON() ([]byte, error) {
@@ -40,6 +87,12 @@ func (a *ToolAnnotations) MarshalJSON() ([]byte, error) {
 	if a.SequentialOnlyHint {
 		data["sequentialOnlyHint"] = a.SequentialOnlyHint
 	}
+	if a.UsageHint != "" {
+		data["usageHint"] = a.UsageHint
+	}
+	if len(a.Examples) > 0 {
+		data["examples"] = a.Examples
+	}
 	if a.Extra != nil {
 		for k, v := range a.Extra {
 			data[k] = v
@@ -58,6 +111,16 @@ func (a *ToolAnnotations) UnmarshalJSON(data []byte) error {
 		json.Unmarshal(title, &a.Title)
 		delete(rawMap, "title")
 	}
+	if hint, ok := rawMap["usageHint"]; ok {
+		json.Unmarshal(hint, &a.UsageHint)
+		delete(rawMap, "usageHint")
+	}
+	if examples, ok := rawMap["examples"]; ok {
+		if err := json.Unmarshal(examples, &a.Examples); err != nil {
+			return fmt.Errorf("tool annotations: invalid value for %q: %w", "examples", err)
+		}
+		delete(rawMap, "examples")
+	}
 	// Handle boolean hints
 	boolFields := map[string]*bool{
 		"readOnlyHint":       &a.ReadOnlyHint,
@@ -92,6 +155,12 @@ const (
 	ToolResultContentTypeText  ToolResultContentType = "text"
 	ToolResultContentTypeImage ToolResultContentType = "image"
 	ToolResultContentTypeAudio ToolResultContentType = "audio"
+
+	// ToolResultContentTypeFile is a file such as a PDF. Data holds its
+	// base64-encoded contents, MimeType its media type, and Filename, if
+	// set, its file name. Providers that can't accept files in tool results
+	// replace it with a placeholder.
+	ToolResultContentTypeFile ToolResultContentType = "file"
 )
 
 func (t ToolResultContentType) String() string {
@@ -105,6 +174,7 @@ type ToolResultContent struct {
 	Text        string                `json:"text,omitempty"`
 	Data        string                `json:"data,omitempty"`
 	MimeType    string                `json:"mimeType,omitempty"`
+	Filename    string                `json:"filename,omitempty"`
 	Annotations map[string]any        `json:"annotations,omitempty"`
 }
 
@@ -125,6 +195,10 @@ type ToolResult struct {
 	Display string `json:"display,omitempty"`
 	// IsError indicates whether the tool call resulted in an error.
 	IsError bool `json:"isError,omitempty"`
+	// Sources lists the documents or pages the result was drawn from, such
+	// as search results or a fetched page. They are not sent to the LLM;
+	// Response.Citations reports them alongside the model's citations.
+	Sources []*llm.CitationContent `json:"sources,omitempty"`
 	// Suspend, when non-nil, tells the agent to suspend its turn rather than
 	// send this tool result to the LLM. Must be the only field set on the
 	// ToolResult (no Content, no Display, no IsError). Use NewSuspendResult
@@ -206,6 +280,13 @@ func (r *ToolResult) WithDisplay(display string) *ToolResult {
 	return r
 }
 
+// WithSources appends sources to the result and returns the receiver for
+// chaining.
+func (r *ToolResult) WithSources(sources ...*llm.CitationContent) *ToolResult {
+	r.Sources = append(r.Sources, sources...)
+	return r
+}
+
 // NewToolResultError creates a new ToolResult containing an error message.
 func NewToolResultError(text string) *ToolResult {
 	return &ToolResult{
@@ -386,6 +467,30 @@ func (t *TypedToolAdapter[T]) PreviewCall(ctx context.Context, input any) *ToolC
 	return previewer.PreviewCall(ctx, typedInput)
 }
 
+// CachePolicy implements ToolCacheable by delegating to the underlying
+// TypedTool if it implements TypedToolCacheable[T]. Input that can't be
+// converted is not cached.
+func (t *TypedToolAdapter[T]) CachePolicy(input any) ToolCachePolicy {
+	cacheable, ok := t.tool.(TypedToolCacheable[T])
+	if !ok {
+		return ToolCachePolicy{}
+	}
+	typedInput, err := t.convertInput(input)
+	if err != nil {
+		return ToolCachePolicy{Disabled: true}
+	}
+	return cacheable.CachePolicy(typedInput)
+}
+
+// CheckpointPolicy implements ToolCheckpointer by delegating to the
+// underlying TypedTool if it implements ToolCheckpointer.
+func (t *TypedToolAdapter[T]) CheckpointPolicy() *ToolCheckpointPolicy {
+	if checkpointer, ok := t.tool.(ToolCheckpointer); ok {
+		return checkpointer.CheckpointPolicy()
+	}
+	return nil
+}
+
 // convertInput converts any input to the typed T, handling json.RawMessage and other types.
 func (t *TypedToolAdapter[T]) convertInput(input any) (T, error) {
 	var zero T
@@ -498,6 +603,46 @@ func FuncTool[T any](name, description string, fn func(ctx context.Context, inpu
 	return ToolAdapter(ft)
 }
 
+// NewTypedTool creates a Tool from a function that takes a typed input and
+// returns a typed output, with the schema generated from In as in FuncTool.
+//
+// The output is the tool result: a string is sent as is, and any other value
+// is marshaled to JSON. An error returned by fn is sent to the LLM as an
+// error result, so it can correct its input and try again.
+//
+// Example:
+//
+//	type AddInput struct {
+//	    A int `json:"a" description:"First addend"`
+//	    B int `json:"b" description:"Second addend"`
+//	}
+//
+//	type AddOutput struct {
+//	    Sum int `json:"sum"`
+//	}
+//
+//	addTool := dive.NewTypedTool("add", "Add two numbers",
+//	    func(ctx context.Context, input AddInput) (AddOutput, error) {
+//	        return AddOutput{Sum: input.A + input.B}, nil
+//	    },
+//	)
+func NewTypedTool[In, Out any](name, description string, fn func(ctx context.Context, input In) (Out, error), opts ...FuncToolOption) Tool {
+	return FuncTool(name, description, func(ctx context.Context, input In) (*ToolResult, error) {
+		output, err := fn(ctx, input)
+		if err != nil {
+			return NewToolResultError(err.Error()), nil
+		}
+		if text, ok := any(output).(string); ok {
+			return NewToolResultText(text), nil
+		}
+		data, err := json.Marshal(output)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: cannot marshal output %T: %w", name, output, err)
+		}
+		return NewToolResultText(string(data)), nil
+	}, opts...)
+}
+
 // FuncToolOption configures a FuncTool.
 type FuncToolOption interface {
 	applyFuncTool(ft any)
@@ -578,5 +723,6 @@ type ToolCallResult struct {
 	Error              error                 // Go error if tool.Call() itself failed
 	AdditionalContext  string                // Context injected by hooks, appended to the tool result message
 	BackgroundHandle   *BackgroundTaskHandle // Non-nil when the tool returned BackgroundResult
+	Cached             bool                  // Result was served from a ToolCache without running the tool
 	reminderDeliveries []reminderDelivery
 }
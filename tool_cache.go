@@ -0,0 +1,162 @@
+package dive
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// ToolCachePolicy describes whether a tool call's result may be cached. See
+// ToolCacheable.
+type ToolCachePolicy struct {
+	// Disabled prevents the call from being served from or stored in the
+	// cache.
+	Disabled bool
+
+	// Files lists the files the result depends on. A cached result is
+	// discarded when any of them is created, deleted, or changes size or
+	// modification time.
+	Files []string
+}
+
+// ToolCacheable is an optional interface that lets a tool control how its
+// results are cached by a ToolCache. Tools that don't implement it are
+// cached on name and input alone when they are eligible.
+type ToolCacheable interface {
+	CachePolicy(input any) ToolCachePolicy
+}
+
+// TypedToolCacheable is the TypedTool counterpart of ToolCacheable.
+type TypedToolCacheable[T any] interface {
+	CachePolicy(input T) ToolCachePolicy
+}
+
+// ToolCache stores the results of read-only tool calls so that repeated
+// identical calls within a run return the earlier result instead of running
+// the tool again. Pass it to CreateResponse with WithToolCache.
+//
+// Only tools annotated with both ReadOnlyHint and IdempotentHint are cached.
+// Entries are keyed on the tool name and the call input, with JSON object
+// keys normalized. Error results are never cached. Tools can opt out, or
+// declare the files a result depends on, by implementing ToolCacheable.
+//
+// A ToolCache may be reused across CreateResponse calls and agents. It is
+// safe for concurrent use.
+type ToolCache struct {
+	mu      sync.Mutex
+	entries map[string]*toolCacheEntry
+}
+
+type toolCacheEntry struct {
+	result *ToolResult
+	files  map[string]fileStamp
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func (s fileStamp) equal(other fileStamp) bool {
+	return s.exists == other.exists && s.size == other.size && s.modTime.Equal(other.modTime)
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// NewToolCache creates an empty ToolCache.
+func NewToolCache() *ToolCache {
+	return &ToolCache{entries: make(map[string]*toolCacheEntry)}
+}
+
+// Len returns the number of cached results.
+func (c *ToolCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Clear removes all cached results.
+func (c *ToolCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// toolCachePolicy returns the cache policy for a call of tool, and whether
+// the tool is eligible for caching at all.
+func toolCachePolicy(tool Tool, input []byte) (ToolCachePolicy, bool) {
+	annotations := tool.Annotations()
+	if annotations == nil || !annotations.ReadOnlyHint || !annotations.IdempotentHint {
+		return ToolCachePolicy{}, false
+	}
+	var policy ToolCachePolicy
+	if cacheable, ok := tool.(ToolCacheable); ok {
+		policy = cacheable.CachePolicy(input)
+	}
+	return policy, !policy.Disabled
+}
+
+func toolCacheKey(name string, input []byte) string {
+	var v any
+	if err := json.Unmarshal(input, &v); err == nil {
+		// Re-encoding sorts object keys, so equivalent inputs share a key.
+		if normalized, err := json.Marshal(v); err == nil {
+			input = normalized
+		}
+	}
+	return name + "\x00" + string(input)
+}
+
+// get returns the cached result for key, discarding it if a file it depends
+// on has changed.
+func (c *ToolCache) get(key string) (*ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	for path, stamp := range entry.files {
+		if !statFile(path).equal(stamp) {
+			delete(c.entries, key)
+			return nil, false
+		}
+	}
+	return entry.result, true
+}
+
+// put caches result under key, along with the current state of files.
+// Stamps should be taken before the tool runs, so a file changed during the
+// call invalidates the entry.
+func (c *ToolCache) put(key string, result *ToolResult, files map[string]fileStamp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &toolCacheEntry{result: result, files: files}
+}
+
+// stampFiles records the current state of paths.
+func stampFiles(paths []string) map[string]fileStamp {
+	if len(paths) == 0 {
+		return nil
+	}
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		stamps[path] = statFile(path)
+	}
+	return stamps
+}
+
+// cacheableResult reports whether a tool call result may be stored.
+func cacheableResult(result *ToolCallResult) bool {
+	return result.Error == nil && result.Result != nil && !result.Result.IsError &&
+		result.Result.Suspend == nil && result.Result.Background == nil
+}
@@ -0,0 +1,130 @@
+package dive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+var readOnlyAnnotations = &ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true}
+
+func TestToolCacheKey(t *testing.T) {
+	assert.Equal(t,
+		toolCacheKey("read", []byte(`{"path":"a.go","limit":10}`)),
+		toolCacheKey("read", []byte(`{ "limit": 10, "path": "a.go" }`)))
+	assert.NotEqual(t,
+		toolCacheKey("read", []byte(`{"path":"a.go"}`)),
+		toolCacheKey("read", []byte(`{"path":"b.go"}`)))
+	assert.NotEqual(t,
+		toolCacheKey("read", []byte(`{}`)),
+		toolCacheKey("list", []byte(`{}`)))
+}
+
+func TestToolCacheFileInvalidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("v1"), 0644))
+
+	cache := NewToolCache()
+	cache.put("key", NewToolResultText("v1"), stampFiles([]string{path}))
+	result, ok := cache.get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "v1", result.Content[0].Text)
+
+	assert.NoError(t, os.WriteFile(path, []byte("version 2"), 0644))
+	_, ok = cache.get("key")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+type cachePolicyTool struct {
+	mockTool
+	policy ToolCachePolicy
+}
+
+func (t *cachePolicyTool) CachePolicy(input any) ToolCachePolicy { return t.policy }
+
+func TestAgentToolCache(t *testing.T) {
+	run := func(t *testing.T, tool Tool, cache *ToolCache) *Response {
+		agent, err := NewAgent(AgentOptions{
+			Model: toolCallingLLM(tool.Name(), 3),
+			Tools: []Tool{tool},
+		})
+		assert.NoError(t, err)
+		resp, err := agent.CreateResponse(context.Background(), WithInput("Go"), WithToolCache(cache))
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("read-only tool is cached", func(t *testing.T) {
+		calls := 0
+		tool := &mockTool{
+			name:        "lookup",
+			annotations: readOnlyAnnotations,
+			callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+				calls++
+				return NewToolResultText("result"), nil
+			},
+		}
+		cache := NewToolCache()
+		resp := run(t, tool, cache)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, []string{"result", "result", "result"}, toolResultTexts(resp))
+
+		var cached []bool
+		for _, item := range resp.Items {
+			if item.Type == ResponseItemTypeToolCallResult {
+				cached = append(cached, item.ToolCallResult.Cached)
+			}
+		}
+		assert.Equal(t, []bool{false, true, true}, cached)
+
+		// The cache can be shared with later calls.
+		run(t, tool, cache)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("other tools, errors, and opted-out tools are not cached", func(t *testing.T) {
+		calls := 0
+		count := func(ctx context.Context, input any) (*ToolResult, error) {
+			calls++
+			return NewToolResultText("result"), nil
+		}
+		run(t, &mockTool{name: "write", callFunc: count}, NewToolCache())
+		assert.Equal(t, 3, calls)
+
+		calls = 0
+		run(t, &mockTool{
+			name:        "flaky",
+			annotations: readOnlyAnnotations,
+			callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+				calls++
+				return NewToolResultError("try again"), nil
+			},
+		}, NewToolCache())
+		assert.Equal(t, 3, calls)
+
+		calls = 0
+		run(t, &cachePolicyTool{
+			mockTool: mockTool{name: "live", annotations: readOnlyAnnotations, callFunc: count},
+			policy:   ToolCachePolicy{Disabled: true},
+		}, NewToolCache())
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("no cache by default", func(t *testing.T) {
+		calls := 0
+		tool := &mockTool{
+			name:        "lookup",
+			annotations: readOnlyAnnotations,
+			callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+				calls++
+				return NewToolResultText("result"), nil
+			},
+		}
+		run(t, tool, nil)
+		assert.Equal(t, 3, calls)
+	})
+}
@@ -0,0 +1,76 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ToolSpec is a read-only description of one tool, for rendering help pages
+// and tool browsers or for asserting an agent's tool surface in tests.
+type ToolSpec struct {
+	// Name is the tool name the model calls.
+	Name string `json:"name"`
+
+	// Description is the tool description.
+	Description string `json:"description"`
+
+	// InputSchema is the tool's input JSON Schema, as returned by
+	// ToolJSONSchema.
+	InputSchema json.RawMessage `json:"input_schema"`
+
+	// Annotations are the tool's behavior hints. May be nil.
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+
+	// Category is the tool's permission category key, such as "bash" or
+	// "read". The dive package leaves it empty; permission.ToolCatalog fills
+	// it in.
+	Category string `json:"category,omitempty"`
+}
+
+// ToolJSONSchema returns the JSON Schema of a tool's input. A tool with no
+// schema is reported as accepting an empty object.
+func ToolJSONSchema(tool Tool) (json.RawMessage, error) {
+	schema := tool.Schema()
+	if schema == nil {
+		return json.RawMessage(`{"type":"object","properties":{}}`), nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("tool %s schema: %w", tool.Name(), err)
+	}
+	return data, nil
+}
+
+// NewToolSpec describes tool as a ToolSpec.
+func NewToolSpec(tool Tool) (ToolSpec, error) {
+	schema, err := ToolJSONSchema(tool)
+	if err != nil {
+		return ToolSpec{}, err
+	}
+	return ToolSpec{
+		Name:        tool.Name(),
+		Description: tool.Description(),
+		InputSchema: schema,
+		Annotations: tool.Annotations(),
+	}, nil
+}
+
+// ToolCatalog describes every tool the agent would offer the model right
+// now: its static tools followed by the current tools of its toolsets, in
+// the order they are sent. It does not call any tool.
+func (a *Agent) ToolCatalog(ctx context.Context) ([]ToolSpec, error) {
+	tools, _, err := a.resolveTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	specs := make([]ToolSpec, 0, len(tools))
+	for _, tool := range tools {
+		spec, err := NewToolSpec(tool)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
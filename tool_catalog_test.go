@@ -0,0 +1,78 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestToolJSONSchema(t *testing.T) {
+	type weatherInput struct {
+		City string `json:"city" description:"City name"`
+	}
+	tool := FuncTool("get_weather", "Get current weather",
+		func(ctx context.Context, input *weatherInput) (*ToolResult, error) {
+			return NewToolResultText("72°F"), nil
+		})
+
+	schema, err := ToolJSONSchema(tool)
+	assert.NoError(t, err)
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(schema, &decoded))
+	assert.Equal(t, "object", decoded["type"])
+	assert.Contains(t, decoded["properties"], "city")
+
+	schema, err = ToolJSONSchema(&mockTool{name: "no_schema"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"object","properties":{}}`, string(schema))
+}
+
+func TestAgentToolCatalog(t *testing.T) {
+	static := &mockTool{name: "Read", annotations: &ToolAnnotations{ReadOnlyHint: true}}
+	dynamic := &mockTool{name: "Deploy"}
+	agent, err := NewAgent(AgentOptions{
+		Model: &mockLLM{},
+		Tools: []Tool{static},
+		Toolsets: []Toolset{&ToolsetFunc{
+			ToolsetName: "deploy",
+			Resolve: func(ctx context.Context) ([]Tool, error) {
+				return []Tool{dynamic}, nil
+			},
+		}},
+	})
+	assert.NoError(t, err)
+
+	specs, err := agent.ToolCatalog(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, specs, 2)
+	assert.Equal(t, "Read", specs[0].Name)
+	assert.Equal(t, "mock tool", specs[0].Description)
+	assert.True(t, specs[0].Annotations.ReadOnlyHint)
+	assert.Equal(t, `{"type":"object","properties":{}}`, string(specs[0].InputSchema))
+	assert.Equal(t, "Deploy", specs[1].Name)
+	assert.Nil(t, specs[1].Annotations)
+
+	data, err := json.Marshal(specs[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"input_schema":{"type":"object","properties":{}}`)
+}
+
+func TestAgentToolCatalogToolsetError(t *testing.T) {
+	agent, err := NewAgent(AgentOptions{
+		Model: &mockLLM{},
+		Toolsets: []Toolset{&ToolsetFunc{
+			ToolsetName: "broken",
+			Resolve: func(ctx context.Context) ([]Tool, error) {
+				return nil, fmt.Errorf("server offline")
+			},
+		}},
+	})
+	assert.NoError(t, err)
+
+	_, err = agent.ToolCatalog(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "server offline")
+}
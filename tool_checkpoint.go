@@ -0,0 +1,259 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// Default values for ToolCheckpointPolicy.
+const (
+	DefaultToolCheckpointInterval     = 30 * time.Second
+	DefaultToolCheckpointMax          = 3
+	DefaultToolCheckpointOutputLength = 4000
+)
+
+// ToolCheckpointPolicy configures checkpoints for a long-running tool that
+// streams output with StreamOutput. At each checkpoint the agent shows a
+// model the output streamed so far and asks whether the call is still worth
+// finishing. If the model says no, the tool's context is canceled and the
+// call returns the output so far with the model's reason, so an agent can
+// abandon a test run or build that is clearly failing. See
+// ToolCheckpointer.
+type ToolCheckpointPolicy struct {
+	// Interval is the time between checkpoints. A checkpoint is skipped if
+	// the tool streamed nothing since the previous one. Defaults to
+	// DefaultToolCheckpointInterval.
+	Interval time.Duration
+
+	// MaxCheckpoints bounds how many times the model is consulted during
+	// one call. Defaults to DefaultToolCheckpointMax.
+	MaxCheckpoints int
+
+	// MaxOutputLength caps the output shown at each checkpoint, in bytes.
+	// The most recent output is kept. Defaults to
+	// DefaultToolCheckpointOutputLength.
+	MaxOutputLength int
+
+	// Model answers checkpoints. Defaults to the agent's model; a smaller
+	// model keeps checkpoints cheap.
+	Model llm.LLM
+}
+
+// ToolCheckpointer is an optional interface for tools that opt in to
+// checkpoints. Return nil to run the tool without them. Checkpoints are
+// consulted only while the tool runs, so a tool that finishes before the
+// first interval costs nothing extra. Streamed output is emitted as
+// ResponseItemTypeToolStream events whether or not checkpoints are enabled.
+type ToolCheckpointer interface {
+	CheckpointPolicy() *ToolCheckpointPolicy
+}
+
+// ToolCheckpointEvent reports the model's decision at a checkpoint.
+type ToolCheckpointEvent struct {
+	ToolCallID string `json:"tool_call_id"`
+	ToolName   string `json:"tool_name"`
+
+	// Checkpoint is the 1-based number of the checkpoint within the call.
+	Checkpoint int `json:"checkpoint"`
+
+	// Continue is false if the model stopped the tool.
+	Continue bool   `json:"continue"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+const toolCheckpointPrompt = `You are monitoring a long-running tool call made by an AI agent. You will see the tool, its input, and the output it has streamed so far. Decide whether the call is still worth finishing.
+
+Answer ok=true to let it keep running. Answer ok=false only when the output already shows the call will not succeed or is no longer useful, such as repeated failures, a fatal error, or a stuck loop, and explain why in reason. When in doubt, let it run.`
+
+// errToolCheckpointStop is the cancellation cause of a tool stopped at a
+// checkpoint.
+var errToolCheckpointStop = errors.New("tool stopped at checkpoint")
+
+// toolCheckpoints monitors one tool call, consulting the model at each
+// checkpoint and canceling the call if the model says to stop.
+type toolCheckpoints struct {
+	agent    *Agent
+	policy   ToolCheckpointPolicy
+	model    llm.LLM
+	tool     Tool
+	call     *llm.ToolUseContent
+	callback EventCallback
+	cancel   context.CancelCauseFunc
+	ctx      context.Context // parent context, for events
+	monitor  context.Context // canceled when the tool returns
+	finish   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu         sync.Mutex
+	output     []byte // tail of the streamed output
+	total      int    // bytes streamed in total
+	checked    int    // total at the last checkpoint
+	stopReason string
+	stopped    bool
+}
+
+// toolCheckpointPolicy returns the checkpoint policy of tool with defaults
+// applied, or nil if the tool doesn't use checkpoints.
+func toolCheckpointPolicy(tool Tool) *ToolCheckpointPolicy {
+	checkpointer, ok := tool.(ToolCheckpointer)
+	if !ok {
+		return nil
+	}
+	policy := checkpointer.CheckpointPolicy()
+	if policy == nil {
+		return nil
+	}
+	resolved := *policy
+	if resolved.Interval <= 0 {
+		resolved.Interval = DefaultToolCheckpointInterval
+	}
+	if resolved.MaxCheckpoints <= 0 {
+		resolved.MaxCheckpoints = DefaultToolCheckpointMax
+	}
+	if resolved.MaxOutputLength <= 0 {
+		resolved.MaxOutputLength = DefaultToolCheckpointOutputLength
+	}
+	return &resolved
+}
+
+// startToolCheckpoints begins monitoring a call of tool. It returns the
+// context to run the tool with, which records streamed output and is
+// canceled if the model stops the call. ctx is used for the model calls.
+func (a *Agent) startToolCheckpoints(
+	ctx context.Context,
+	toolCtx context.Context,
+	policy *ToolCheckpointPolicy,
+	tool Tool,
+	call *llm.ToolUseContent,
+	callback EventCallback,
+) (context.Context, *toolCheckpoints) {
+	model := policy.Model
+	if model == nil {
+		model = a.Model()
+	}
+	c := &toolCheckpoints{
+		agent:    a,
+		policy:   *policy,
+		model:    model,
+		tool:     tool,
+		call:     call,
+		callback: callback,
+		ctx:      ctx,
+	}
+	c.monitor, c.finish = context.WithCancel(ctx)
+	toolCtx, c.cancel = context.WithCancelCause(toolCtx)
+	next, _ := toolCtx.Value(toolStreamFnKey).(func(string, string))
+	toolCtx = WithToolStreamFunc(toolCtx, func(toolCallID, text string) {
+		c.record(text)
+		if next != nil {
+			next(toolCallID, text)
+		}
+	})
+	c.wg.Add(1)
+	go c.run()
+	return toolCtx, c
+}
+
+func (c *toolCheckpoints) record(text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total += len(text)
+	c.output = append(c.output, text...)
+	if excess := len(c.output) - c.policy.MaxOutputLength; excess > 0 {
+		c.output = c.output[excess:]
+	}
+}
+
+func (c *toolCheckpoints) run() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.policy.Interval)
+	defer ticker.Stop()
+	for checkpoint := 1; checkpoint <= c.policy.MaxCheckpoints; {
+		select {
+		case <-c.monitor.Done():
+			return
+		case <-ticker.C:
+		}
+		c.mu.Lock()
+		fresh := c.total > c.checked
+		c.checked = c.total
+		output := string(c.output)
+		c.mu.Unlock()
+		if !fresh {
+			continue
+		}
+		if !c.consult(checkpoint, output) {
+			return
+		}
+		checkpoint++
+	}
+}
+
+// consult asks the model whether the call should continue and reports
+// whether it does. Model errors let the call continue.
+func (c *toolCheckpoints) consult(checkpoint int, output string) bool {
+	evidence := fmt.Sprintf("Tool: %s\nInput: %s\n\nOutput so far (most recent %d bytes):\n%s",
+		c.call.Name, c.call.Input, c.policy.MaxOutputLength, output)
+	decision, err := askJudgment(c.monitor, c.model, toolCheckpointPrompt, evidence)
+	if c.monitor.Err() != nil {
+		// The tool finished while the model was deciding
+		return false
+	}
+	if err != nil {
+		c.agent.logger.Warn("tool checkpoint failed", "tool_name", c.call.Name, "tool_id", c.call.ID, "error", err)
+		return true
+	}
+	if c.callback != nil {
+		_ = c.callback(c.ctx, &ResponseItem{
+			Type: ResponseItemTypeToolCheckpoint,
+			ToolCheckpoint: &ToolCheckpointEvent{
+				ToolCallID: c.call.ID,
+				ToolName:   c.call.Name,
+				Checkpoint: checkpoint,
+				Continue:   decision.OK,
+				Reason:     decision.Reason,
+			},
+		})
+	}
+	if decision.OK {
+		return true
+	}
+	c.agent.logger.Info("tool stopped at checkpoint", "tool_name", c.call.Name, "tool_id", c.call.ID, "reason", decision.Reason)
+	c.mu.Lock()
+	c.stopped = true
+	c.stopReason = decision.Reason
+	c.mu.Unlock()
+	c.cancel(errToolCheckpointStop)
+	return false
+}
+
+// stop ends monitoring and waits for a checkpoint in progress. It returns
+// the result to use in place of the tool's if the model stopped the call.
+func (c *toolCheckpoints) stop() *ToolResult {
+	c.finish()
+	c.wg.Wait()
+	c.cancel(nil)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.stopped {
+		return nil
+	}
+	text := "The tool call was stopped before it finished because its output showed it was not worth completing."
+	if c.stopReason != "" {
+		text = fmt.Sprintf("The tool call was stopped before it finished: %s", c.stopReason)
+	}
+	if len(c.output) > 0 {
+		text += fmt.Sprintf("\n\nOutput before stopping:\n%s", c.output)
+	}
+	return &ToolResult{
+		Content: []*ToolResultContent{{Type: ToolResultContentTypeText, Text: text}},
+		Display: fmt.Sprintf("Stopped %s at checkpoint", c.tool.Name()),
+		IsError: true,
+	}
+}
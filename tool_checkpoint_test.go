@@ -0,0 +1,152 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// checkpointTool is a mockTool that opts in to checkpoints.
+type checkpointTool struct {
+	mockTool
+	policy *ToolCheckpointPolicy
+}
+
+func (t *checkpointTool) CheckpointPolicy() *ToolCheckpointPolicy { return t.policy }
+
+// toolThenAnswerLLM calls the named tool once, then answers.
+func toolThenAnswerLLM(toolName string) *mockLLM {
+	var calls atomic.Int32
+	return &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			if calls.Add(1) == 1 {
+				return &llm.Response{
+					Role:       llm.Assistant,
+					StopReason: "tool_use",
+					Content: []llm.Content{&llm.ToolUseContent{
+						ID: "call_1", Name: toolName, Input: json.RawMessage(`{}`),
+					}},
+				}, nil
+			}
+			return &llm.Response{Role: llm.Assistant, Content: []llm.Content{&llm.TextContent{Text: "done"}}}, nil
+		},
+	}
+}
+
+// runCheckpointed runs a checkpointed tool through an agent and returns its
+// result and the checkpoint and stream events.
+func runCheckpointed(t *testing.T, tool *checkpointTool) (*ToolCallResult, []*ToolCheckpointEvent, int) {
+	t.Helper()
+	agent, err := NewAgent(AgentOptions{Model: toolThenAnswerLLM(tool.name), Tools: []Tool{tool}})
+	assert.NoError(t, err)
+
+	var mu sync.Mutex
+	var checkpoints []*ToolCheckpointEvent
+	streamed := 0
+	response, err := agent.CreateResponse(context.Background(), WithInput("run it"),
+		WithEventCallback(func(_ context.Context, item *ResponseItem) error {
+			mu.Lock()
+			defer mu.Unlock()
+			switch item.Type {
+			case ResponseItemTypeToolCheckpoint:
+				checkpoints = append(checkpoints, item.ToolCheckpoint)
+			case ResponseItemTypeToolStream:
+				streamed++
+			}
+			return nil
+		}))
+	assert.NoError(t, err)
+	for _, item := range response.Items {
+		if item.Type == ResponseItemTypeToolCallResult {
+			return item.ToolCallResult, checkpoints, streamed
+		}
+	}
+	t.Fatal("no tool call result")
+	return nil, nil, 0
+}
+
+func TestToolCheckpointStopsTool(t *testing.T) {
+	var cause error
+	tool := &checkpointTool{
+		mockTool: mockTool{name: "run_tests", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			for {
+				StreamOutput(ctx, "FAIL TestParse\n")
+				select {
+				case <-ctx.Done():
+					cause = context.Cause(ctx)
+					return nil, ctx.Err()
+				case <-time.After(2 * time.Millisecond):
+				}
+			}
+		}},
+		policy: &ToolCheckpointPolicy{
+			Interval: 10 * time.Millisecond,
+			Model:    decisionModel(false, "every test is failing"),
+		},
+	}
+
+	result, checkpoints, streamed := runCheckpointed(t, tool)
+	assert.Equal(t, errToolCheckpointStop, cause)
+	assert.NoError(t, result.Error)
+	assert.True(t, result.Result.IsError)
+	text := result.Result.Content[0].Text
+	assert.Contains(t, text, "stopped before it finished: every test is failing")
+	assert.Contains(t, text, "FAIL TestParse")
+	assert.Len(t, checkpoints, 1)
+	assert.Equal(t, &ToolCheckpointEvent{
+		ToolCallID: "call_1",
+		ToolName:   "run_tests",
+		Checkpoint: 1,
+		Continue:   false,
+		Reason:     "every test is failing",
+	}, checkpoints[0])
+	assert.True(t, streamed > 0)
+}
+
+func TestToolCheckpointBounded(t *testing.T) {
+	judge := decisionModel(true, "")
+	var judged atomic.Int32
+	generate := judge.generateFunc
+	judge.generateFunc = func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		judged.Add(1)
+		return generate(ctx, opts...)
+	}
+	tool := &checkpointTool{
+		mockTool: mockTool{name: "build", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			for i := 0; i < 40; i++ {
+				StreamOutput(ctx, "compiling\n")
+				time.Sleep(2 * time.Millisecond)
+			}
+			return NewToolResultText("built"), nil
+		}},
+		policy: &ToolCheckpointPolicy{Interval: 5 * time.Millisecond, MaxCheckpoints: 2, Model: judge},
+	}
+
+	result, checkpoints, _ := runCheckpointed(t, tool)
+	assert.False(t, result.Result.IsError)
+	assert.Equal(t, "built", result.Result.Content[0].Text)
+	assert.Len(t, checkpoints, 2)
+	assert.True(t, checkpoints[1].Continue)
+	assert.Equal(t, int32(2), judged.Load())
+}
+
+func TestToolCheckpointSkippedWithoutOutput(t *testing.T) {
+	judge := decisionModel(false, "should not be asked")
+	tool := &checkpointTool{
+		mockTool: mockTool{name: "quiet", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			time.Sleep(30 * time.Millisecond)
+			return NewToolResultText("ok"), nil
+		}},
+		policy: &ToolCheckpointPolicy{Interval: 5 * time.Millisecond, Model: judge},
+	}
+
+	result, checkpoints, _ := runCheckpointed(t, tool)
+	assert.Equal(t, "ok", result.Result.Content[0].Text)
+	assert.Len(t, checkpoints, 0)
+}
@@ -0,0 +1,269 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// Defaults for ToolCircuitBreakerOptions.
+const (
+	DefaultToolFailureThreshold = 3
+	DefaultToolCooldown         = time.Minute
+)
+
+// ToolCircuitState is the state of a tool's circuit breaker.
+type ToolCircuitState string
+
+const (
+	// ToolCircuitClosed means the tool runs normally.
+	ToolCircuitClosed ToolCircuitState = "closed"
+
+	// ToolCircuitOpen means the tool failed too many times in a row and
+	// calls are rejected until the cooldown ends.
+	ToolCircuitOpen ToolCircuitState = "open"
+)
+
+// ToolCircuitEvent reports a circuit breaker state change. It is emitted as
+// a ResponseItemTypeToolCircuit item when a breaker trips (State is
+// ToolCircuitOpen) or resets after a successful call (ToolCircuitClosed).
+type ToolCircuitEvent struct {
+	ToolName string           `json:"tool_name"`
+	State    ToolCircuitState `json:"state"`
+
+	// ConsecutiveFailures is the failure count that tripped the breaker.
+	// Zero on reset.
+	ConsecutiveFailures int `json:"consecutive_failures,omitempty"`
+
+	// RetryAt is when calls will be attempted again. Nil on reset.
+	RetryAt *time.Time `json:"retry_at,omitempty"`
+}
+
+// ToolCircuitBreakerOptions configures a ToolCircuitBreaker.
+type ToolCircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed calls of a tool
+	// that trips its breaker. Defaults to DefaultToolFailureThreshold.
+	FailureThreshold int
+
+	// Cooldown is how long a tripped tool stays unavailable. After the
+	// cooldown the next call is attempted: success resets the breaker and
+	// failure trips it again. Defaults to DefaultToolCooldown.
+	Cooldown time.Duration
+
+	// Clock is used to time cooldowns. Defaults to SystemClock.
+	Clock Clock
+}
+
+// ToolCircuitBreaker temporarily disables tools that keep failing, so a
+// flaky external service isn't called on every turn. After FailureThreshold
+// consecutive failures of a tool (an error or an IsError result), further
+// calls return a "temporarily unavailable" error result to the model until
+// the cooldown passes. Calls denied by hooks or rate limits don't count.
+//
+// Set it on AgentOptions.ToolCircuitBreaker. State is tracked per tool name
+// and persists across CreateResponse calls, so a breaker may be shared by
+// several agents. It is safe for concurrent use.
+type ToolCircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     Clock
+
+	mu    sync.Mutex
+	tools map[string]*toolCircuit
+}
+
+type toolCircuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+// NewToolCircuitBreaker creates a ToolCircuitBreaker with the given options.
+func NewToolCircuitBreaker(opts ...ToolCircuitBreakerOptions) *ToolCircuitBreaker {
+	var options ToolCircuitBreakerOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = DefaultToolFailureThreshold
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = DefaultToolCooldown
+	}
+	return &ToolCircuitBreaker{
+		threshold: options.FailureThreshold,
+		cooldown:  options.Cooldown,
+		clock:     ClockOrDefault(options.Clock),
+		tools:     make(map[string]*toolCircuit),
+	}
+}
+
+// State returns the current state of the named tool's breaker. A tripped
+// breaker whose cooldown has passed reports ToolCircuitClosed.
+func (b *ToolCircuitBreaker) State(toolName string) ToolCircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.tools[toolName]; ok && b.clock.Now().Before(c.openUntil) {
+		return ToolCircuitOpen
+	}
+	return ToolCircuitClosed
+}
+
+// Reset clears the failure history of the named tool, closing its breaker.
+func (b *ToolCircuitBreaker) Reset(toolName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.tools, toolName)
+}
+
+// allow reports whether the named tool may run. When it may not, it returns
+// the time the cooldown ends.
+func (b *ToolCircuitBreaker) allow(toolName string) (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.tools[toolName]
+	if ok && b.clock.Now().Before(c.openUntil) {
+		return false, c.openUntil
+	}
+	return true, time.Time{}
+}
+
+// record updates the named tool's breaker with the outcome of a call. It
+// returns an event if the breaker tripped or reset.
+func (b *ToolCircuitBreaker) record(toolName string, failed bool) *ToolCircuitEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.tools[toolName]
+	if !failed {
+		if !ok {
+			return nil
+		}
+		delete(b.tools, toolName)
+		if c.failures < b.threshold {
+			return nil
+		}
+		return &ToolCircuitEvent{ToolName: toolName, State: ToolCircuitClosed}
+	}
+	if !ok {
+		c = &toolCircuit{}
+		b.tools[toolName] = c
+	}
+	c.failures++
+	if c.failures < b.threshold {
+		return nil
+	}
+	retryAt := b.clock.Now().Add(b.cooldown)
+	c.openUntil = retryAt
+	return &ToolCircuitEvent{
+		ToolName:            toolName,
+		State:               ToolCircuitOpen,
+		ConsecutiveFailures: c.failures,
+		RetryAt:             &retryAt,
+	}
+}
+
+// toolCallCounter counts tool calls within one CreateResponse call for
+// AgentOptions.ToolCallLimits.
+type toolCallCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// take counts a call of the named tool, returning false without counting
+// it if the tool already made limit calls.
+func (c *toolCallCounter) take(toolName string, limit int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts[toolName] >= limit {
+		return false
+	}
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[toolName]++
+	return true
+}
+
+// executeGuardedTool runs executeTool unless the call's input is invalid
+// JSON, its result is in the run's ToolCache, or the call is blocked by the
+// agent's per-run tool call limits or circuit breaker. Rejected calls return
+// an error result that tells the model why, without running the tool.
+// invalidInput marks input that repairToolInputs couldn't salvage.
+func (a *Agent) executeGuardedTool(
+	ctx context.Context,
+	hctx *HookContext,
+	tool Tool,
+	call *llm.ToolUseContent,
+	input []byte,
+	invalidInput bool,
+	preview *ToolCallPreview,
+	callback EventCallback,
+) *ToolCallResult {
+	name := call.Name
+	if invalidInput || (len(input) > 0 && !json.Valid(input)) {
+		a.logger.Warn("tool call input is invalid JSON", "tool_name", name, "tool_id", call.ID)
+		return a.createDeniedResult(call, invalidToolInputMessage(name), preview)
+	}
+
+	// Cached results don't count against limits or the circuit breaker.
+	var cacheKey string
+	var cacheFiles map[string]fileStamp
+	if hctx.toolCache != nil {
+		if policy, ok := toolCachePolicy(tool, input); ok {
+			cacheKey = toolCacheKey(name, input)
+			if cached, ok := hctx.toolCache.get(cacheKey); ok {
+				a.logger.Debug("tool result served from cache", "tool_name", name, "tool_id", call.ID)
+				return &ToolCallResult{
+					ID:      call.ID,
+					Name:    name,
+					Input:   call.Input,
+					Preview: preview,
+					Result:  cached,
+					Cached:  true,
+				}
+			}
+			cacheFiles = stampFiles(policy.Files)
+		}
+	}
+	if limit, ok := a.toolCallLimits[name]; ok && hctx.toolCalls != nil && !hctx.toolCalls.take(name, limit) {
+		a.logger.Warn("tool call limit reached", "tool_name", name, "limit", limit)
+		return a.createDeniedResult(call, fmt.Sprintf(
+			"Tool %s has reached its limit of %d calls for this response. Continue without it.", name, limit), preview)
+	}
+	if a.circuitBreaker != nil {
+		if ok, retryAt := a.circuitBreaker.allow(name); !ok {
+			return a.createDeniedResult(call, fmt.Sprintf(
+				"Tool %s is temporarily unavailable after repeated failures. It can be retried after %s; until then, continue without it.",
+				name, retryAt.Format(time.RFC3339)), preview)
+		}
+	}
+
+	result := a.executeTool(ctx, tool, call, input, preview, callback)
+	if cacheKey != "" && cacheableResult(result) {
+		hctx.toolCache.put(cacheKey, result.Result, cacheFiles)
+	}
+
+	if a.circuitBreaker != nil {
+		failed := result.Error != nil || (result.Result != nil && result.Result.IsError)
+		if event := a.circuitBreaker.record(name, failed); event != nil {
+			if event.State == ToolCircuitOpen {
+				a.logger.Warn("tool circuit breaker tripped",
+					"tool_name", name,
+					"consecutive_failures", event.ConsecutiveFailures,
+					"retry_at", *event.RetryAt)
+			} else {
+				a.logger.Info("tool circuit breaker reset", "tool_name", name)
+			}
+			if callback != nil {
+				_ = callback(ctx, &ResponseItem{
+					Type:        ResponseItemTypeToolCircuit,
+					ToolCircuit: event,
+				})
+			}
+		}
+	}
+	return result
+}
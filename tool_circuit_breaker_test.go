@@ -0,0 +1,157 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestToolCircuitBreaker(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	breaker := NewToolCircuitBreaker(ToolCircuitBreakerOptions{
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+		Clock:            clock,
+	})
+
+	assert.Nil(t, breaker.record("search", true))
+	event := breaker.record("search", true)
+	assert.NotNil(t, event)
+	assert.Equal(t, ToolCircuitOpen, event.State)
+	assert.Equal(t, 2, event.ConsecutiveFailures)
+	assert.Equal(t, start.Add(time.Minute), *event.RetryAt)
+	assert.Equal(t, ToolCircuitOpen, breaker.State("search"))
+	assert.Equal(t, ToolCircuitClosed, breaker.State("other"))
+
+	ok, retryAt := breaker.allow("search")
+	assert.False(t, ok)
+	assert.Equal(t, start.Add(time.Minute), retryAt)
+
+	// After the cooldown a trial call is allowed; failing it trips again.
+	clock.Advance(time.Minute)
+	ok, _ = breaker.allow("search")
+	assert.True(t, ok)
+	event = breaker.record("search", true)
+	assert.Equal(t, ToolCircuitOpen, event.State)
+
+	// A success after the cooldown resets the breaker.
+	clock.Advance(time.Minute)
+	event = breaker.record("search", false)
+	assert.Equal(t, ToolCircuitClosed, event.State)
+	assert.Nil(t, breaker.record("search", false))
+
+	// Successes in between reset the consecutive count.
+	assert.Nil(t, breaker.record("search", true))
+	assert.Nil(t, breaker.record("search", false))
+	assert.Nil(t, breaker.record("search", true))
+	assert.Equal(t, ToolCircuitClosed, breaker.State("search"))
+}
+
+// toolCallingLLM requests one call of toolName per turn, for turns turns,
+// then finishes.
+func toolCallingLLM(toolName string, turns int) *mockLLM {
+	callCount := 0
+	return &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			callCount++
+			if callCount > turns {
+				return &llm.Response{
+					Role:       llm.Assistant,
+					Content:    []llm.Content{&llm.TextContent{Text: "done"}},
+					StopReason: llm.StopReasonEndTurn,
+				}, nil
+			}
+			return &llm.Response{
+				Role: llm.Assistant,
+				Content: []llm.Content{&llm.ToolUseContent{
+					ID:    fmt.Sprintf("call_%d", callCount),
+					Name:  toolName,
+					Input: []byte(`{}`),
+				}},
+				StopReason: llm.StopReasonToolUse,
+			}, nil
+		},
+	}
+}
+
+func toolResultTexts(resp *Response) []string {
+	var texts []string
+	for _, item := range resp.Items {
+		if item.Type == ResponseItemTypeToolCallResult {
+			texts = append(texts, item.ToolCallResult.Result.Content[0].Text)
+		}
+	}
+	return texts
+}
+
+func TestAgentToolCircuitBreaker(t *testing.T) {
+	calls := 0
+	tool := &mockTool{
+		name: "search",
+		callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			calls++
+			return nil, errors.New("service down")
+		},
+	}
+	agent, err := NewAgent(AgentOptions{
+		Model:              toolCallingLLM("search", 4),
+		Tools:              []Tool{tool},
+		ToolCircuitBreaker: NewToolCircuitBreaker(ToolCircuitBreakerOptions{FailureThreshold: 2}),
+	})
+	assert.NoError(t, err)
+
+	var events []*ToolCircuitEvent
+	resp, err := agent.CreateResponse(context.Background(), WithInput("Go"),
+		WithEventCallback(func(ctx context.Context, item *ResponseItem) error {
+			if item.Type == ResponseItemTypeToolCircuit {
+				events = append(events, item.ToolCircuit)
+			}
+			return nil
+		}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "search", events[0].ToolName)
+	assert.Equal(t, ToolCircuitOpen, events[0].State)
+
+	texts := toolResultTexts(resp)
+	assert.Len(t, texts, 4)
+	assert.Contains(t, texts[2], "temporarily unavailable")
+	assert.Contains(t, texts[3], "temporarily unavailable")
+}
+
+func TestAgentToolCallLimits(t *testing.T) {
+	calls := 0
+	tool := &mockTool{
+		name: "search",
+		callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			calls++
+			return NewToolResultText("results"), nil
+		},
+	}
+	agent, err := NewAgent(AgentOptions{
+		Model:          toolCallingLLM("search", 3),
+		Tools:          []Tool{tool},
+		ToolCallLimits: map[string]int{"search": 2},
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("Go"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	texts := toolResultTexts(resp)
+	assert.Len(t, texts, 3)
+	assert.Contains(t, texts[2], "limit of 2 calls")
+
+	// Limits apply per CreateResponse call.
+	agent.SetModel(toolCallingLLM("search", 1))
+	_, err = agent.CreateResponse(context.Background(), WithInput("Again"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
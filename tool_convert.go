@@ -0,0 +1,118 @@
+package dive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// The llm and dive packages describe tools at two layers:
+//
+//   - llm.Tool is a definition only: a name, description, and input schema.
+//     It is what providers send to the model. The llm package never runs
+//     tools; callers of Generate and Stream execute tool calls themselves.
+//   - dive.Tool extends llm.Tool with Annotations and Call, so an Agent can
+//     run it. Every dive.Tool is therefore also an llm.Tool.
+//
+// AsLLMTool and AsLLMTools pass dive tools (including toolkit tools) to raw
+// llm calls, CallLLMTool runs a tool call returned by such a call, and
+// FromLLMTool lifts a bare definition into a dive.Tool.
+var _ llm.Tool = Tool(nil)
+
+// AsLLMTool returns the definition of tool as sent to providers. Because a
+// Tool is an llm.Tool, this returns tool itself, so provider-specific
+// configuration (llm.ToolConfiguration) is kept.
+//
+//	response, err := model.Generate(ctx,
+//	    llm.WithUserTextMessage("What's in main.go?"),
+//	    llm.WithTools(dive.AsLLMTool(toolkit.NewReadFileTool())),
+//	)
+func AsLLMTool(tool Tool) llm.Tool {
+	return tool
+}
+
+// AsLLMTools converts tools to llm.Tool definitions. The Agent uses it to
+// build the tool list for each LLM request.
+func AsLLMTools(tools []Tool) []llm.Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	defs := make([]llm.Tool, len(tools))
+	for i, tool := range tools {
+		defs[i] = AsLLMTool(tool)
+	}
+	return defs
+}
+
+// FromLLMTool makes a Tool from an llm.Tool definition and a function that
+// implements it. If call is nil and def is already a Tool, def is returned
+// unchanged. If call is nil otherwise, the tool is definition-only: calling it
+// returns an error result. The returned tool has no annotations and keeps
+// def's provider-specific configuration.
+//
+// The input passed to call is the raw JSON of the tool call's arguments.
+func FromLLMTool(def llm.Tool, call func(ctx context.Context, input any) (*ToolResult, error)) Tool {
+	if tool, ok := def.(Tool); ok && call == nil {
+		return tool
+	}
+	return &llmTool{Tool: def, call: call}
+}
+
+// llmTool adapts an llm.Tool definition to the Tool interface.
+type llmTool struct {
+	llm.Tool
+	call func(ctx context.Context, input any) (*ToolResult, error)
+}
+
+func (t *llmTool) Annotations() *ToolAnnotations {
+	return nil
+}
+
+func (t *llmTool) Call(ctx context.Context, input any) (*ToolResult, error) {
+	if t.call == nil {
+		return NewToolResultError(fmt.Sprintf("Tool %s has no implementation.", t.Name())), nil
+	}
+	return t.call(ctx, input)
+}
+
+func (t *llmTool) ToolConfiguration(providerName string) map[string]any {
+	if toolWithConfig, ok := t.Tool.(llm.ToolConfiguration); ok {
+		return toolWithConfig.ToolConfiguration(providerName)
+	}
+	return nil
+}
+
+// CallLLMTool runs tool for a tool call returned by an llm Generate or Stream
+// call and converts the outcome to the tool result content to send back, the
+// same way an Agent does. An error from the tool becomes an error result.
+// Unlike the Agent, it does not run hooks or recover from panics, and
+// suspend and background results are not supported.
+func CallLLMTool(ctx context.Context, tool Tool, call *llm.ToolUseContent) *llm.ToolResultContent {
+	result, err := tool.Call(WithToolCallID(ctx, call.ID), []byte(call.Input))
+	return NewLLMToolResult(call.ID, result, err)
+}
+
+// NewLLMToolResult converts the outcome of a tool call to llm tool result
+// content. The result is marked as an error if err is set or result.IsError
+// is true. If result is nil and err is set, the content is the error text.
+func NewLLMToolResult(toolUseID string, result *ToolResult, err error) *llm.ToolResultContent {
+	var content any
+	var isError bool
+	if result != nil {
+		content = result.Content
+		isError = result.IsError
+	} else if err != nil {
+		content = []*ToolResultContent{{
+			Type: ToolResultContentTypeText,
+			Text: fmt.Sprintf("Tool execution error: %v", err),
+		}}
+	}
+	// IsError is true if either the tool crashed (err) or the tool reported
+	// a protocol-level error (result.IsError).
+	return &llm.ToolResultContent{
+		ToolUseID: toolUseID,
+		Content:   content,
+		IsError:   err != nil || isError,
+	}
+}
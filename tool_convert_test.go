@@ -0,0 +1,89 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+type configuredToolDefinition struct {
+	*llm.ToolDefinition
+}
+
+func (t *configuredToolDefinition) ToolConfiguration(providerName string) map[string]any {
+	return map[string]any{"provider": providerName}
+}
+
+func TestAsLLMTools(t *testing.T) {
+	tool := &mockTool{name: "search"}
+	assert.Equal(t, llm.Tool(tool), AsLLMTool(tool))
+
+	defs := AsLLMTools([]Tool{tool, &mockTool{name: "fetch"}})
+	assert.Len(t, defs, 2)
+	assert.Equal(t, "search", defs[0].Name())
+	assert.Equal(t, "fetch", defs[1].Name())
+	assert.Nil(t, AsLLMTools(nil))
+}
+
+func TestFromLLMTool(t *testing.T) {
+	tool := &mockTool{name: "search"}
+	assert.Equal(t, Tool(tool), FromLLMTool(tool, nil))
+
+	def := &configuredToolDefinition{llm.NewToolDefinition().
+		WithName("lookup").
+		WithDescription("Look up a record").
+		WithSchema(&Schema{Type: Object})}
+
+	lookup := FromLLMTool(def, func(ctx context.Context, input any) (*ToolResult, error) {
+		return NewToolResultText("found " + string(input.([]byte))), nil
+	})
+	assert.Equal(t, "lookup", lookup.Name())
+	assert.Equal(t, "Look up a record", lookup.Description())
+	assert.Equal(t, Object, lookup.Schema().Type)
+	assert.Nil(t, lookup.Annotations())
+	config := lookup.(llm.ToolConfiguration).ToolConfiguration("anthropic")
+	assert.Equal(t, "anthropic", config["provider"])
+
+	result, err := lookup.Call(context.Background(), []byte(`{"id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `found {"id":1}`, result.Content[0].Text)
+
+	// A definition without an implementation reports an error result.
+	result, err = FromLLMTool(def, nil).Call(context.Background(), []byte(`{}`))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "no implementation")
+}
+
+func TestCallLLMTool(t *testing.T) {
+	var gotID string
+	tool := &mockTool{
+		name: "search",
+		callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			gotID = ToolCallID(ctx)
+			return NewToolResultText("results for " + string(input.([]byte))), nil
+		},
+	}
+	content := CallLLMTool(context.Background(), tool, &llm.ToolUseContent{
+		ID:    "call_1",
+		Name:  "search",
+		Input: []byte(`{"q":"go"}`),
+	})
+	assert.Equal(t, "call_1", gotID)
+	assert.Equal(t, "call_1", content.ToolUseID)
+	assert.False(t, content.IsError)
+	assert.Equal(t, `results for {"q":"go"}`, content.Content.([]*ToolResultContent)[0].Text)
+
+	failing := &mockTool{
+		name: "search",
+		callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			return nil, errors.New("service down")
+		},
+	}
+	content = CallLLMTool(context.Background(), failing, &llm.ToolUseContent{ID: "call_2", Input: []byte(`{}`)})
+	assert.True(t, content.IsError)
+	assert.Equal(t, "Tool execution error: service down", content.Content.([]*ToolResultContent)[0].Text)
+}
@@ -0,0 +1,48 @@
+package dive
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// repairToolInputs salvages tool call inputs in response whose JSON is
+// incomplete, typically because the output token limit cut the response off
+// mid-call. Streaming responses are already repaired by the accumulator;
+// this covers non-streaming providers. Repairable inputs are completed in
+// place and marked Repaired, so hooks can inspect Call.Repaired and decide
+// whether to trust them.
+//
+// Inputs that can't be repaired are replaced with "{}", so the assistant
+// message stays encodable in later requests, and their call IDs are
+// returned. Pass them to executeToolCalls so the calls are rejected with a
+// request to retry rather than run.
+func repairToolInputs(response *llm.Response) map[string]bool {
+	var invalid map[string]bool
+	for _, content := range response.Content {
+		toolUse, ok := content.(*llm.ToolUseContent)
+		if !ok || len(toolUse.Input) == 0 || json.Valid(toolUse.Input) {
+			continue
+		}
+		if repaired, ok := llm.RepairJSON(toolUse.Input); ok {
+			toolUse.Input = repaired
+			toolUse.Repaired = true
+			continue
+		}
+		if invalid == nil {
+			invalid = make(map[string]bool)
+		}
+		invalid[toolUse.ID] = true
+		toolUse.Input = json.RawMessage("{}")
+	}
+	return invalid
+}
+
+// invalidToolInputMessage is the error result returned to the model for a
+// tool call whose input isn't valid JSON.
+func invalidToolInputMessage(toolName string) string {
+	return fmt.Sprintf("The input for tool %s was incomplete or invalid JSON, most likely because "+
+		"the response reached the output token limit. Call the tool again with complete input; "+
+		"if the input is long, split the work into several smaller calls.", toolName)
+}
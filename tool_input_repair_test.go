@@ -0,0 +1,74 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestTruncatedToolInputs(t *testing.T) {
+	callCount := 0
+	var secondRequest []*llm.Message
+	mock := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			callCount++
+			if callCount == 1 {
+				return &llm.Response{
+					Role: llm.Assistant,
+					Content: []llm.Content{
+						&llm.ToolUseContent{ID: "call_1", Name: "echo", Input: []byte(`{"text": "hel`)},
+						&llm.ToolUseContent{ID: "call_2", Name: "echo", Input: []byte(`{"text": x`)},
+					},
+					StopReason: llm.StopReasonMaxTokens,
+				}, nil
+			}
+			config := &llm.Config{}
+			config.Apply(opts...)
+			secondRequest = config.Messages
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "done"}},
+				StopReason: llm.StopReasonEndTurn,
+			}, nil
+		},
+	}
+
+	var inputs []string
+	var repaired []bool
+	tool := &mockTool{
+		name: "echo",
+		callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+			inputs = append(inputs, string(input.([]byte)))
+			return NewToolResultText("ok"), nil
+		},
+	}
+	agent, err := NewAgent(AgentOptions{
+		Model: mock,
+		Tools: []Tool{tool},
+		Hooks: Hooks{PreToolUse: []PreToolUseHook{func(ctx context.Context, hctx *HookContext) error {
+			repaired = append(repaired, hctx.Call.Repaired)
+			return nil
+		}}},
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("Go"))
+	assert.NoError(t, err)
+
+	// The repairable call runs with completed input; the other is rejected.
+	assert.Equal(t, []string{`{"text": "hel"}`}, inputs)
+	assert.Equal(t, []bool{true, false}, repaired)
+	texts := toolResultTexts(resp)
+	assert.Len(t, texts, 2)
+	assert.Equal(t, "ok", texts[0])
+	assert.Contains(t, texts[1], "Call the tool again")
+
+	// History sent back to the model stays encodable.
+	for _, msg := range secondRequest {
+		_, err := json.Marshal(msg)
+		assert.NoError(t, err)
+	}
+}
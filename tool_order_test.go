@@ -0,0 +1,238 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// multiToolLLM returns the given tool calls in one response, then "done".
+func multiToolLLM(calls ...*llm.ToolUseContent) *mockLLM {
+	turn := 0
+	return &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			turn++
+			if turn == 1 {
+				content := make([]llm.Content, len(calls))
+				for i, call := range calls {
+					content[i] = call
+				}
+				return &llm.Response{Role: llm.Assistant, Content: content, StopReason: llm.StopReasonToolUse}, nil
+			}
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "done"}},
+				StopReason: llm.StopReasonEndTurn,
+			}, nil
+		},
+	}
+}
+
+// toolResultIDs returns the tool use IDs of the results sent back to the
+// model, in message order.
+func toolResultIDs(resp *Response) []string {
+	var ids []string
+	for _, msg := range resp.OutputMessages {
+		for _, c := range msg.Content {
+			if result, ok := c.(*llm.ToolResultContent); ok {
+				ids = append(ids, result.ToolUseID)
+			}
+		}
+	}
+	return ids
+}
+
+func TestToolCallsExecuteInEmittedOrder(t *testing.T) {
+	// A write, a read of what was written, then an overwrite: the read must
+	// see the first write and not the second.
+	var mu sync.Mutex
+	var value string
+	var executed []string
+	write := &mockTool{name: "write", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		var args struct{ Value string }
+		if err := json.Unmarshal(input.([]byte), &args); err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		value = args.Value
+		executed = append(executed, "write "+value)
+		return NewToolResultText("ok"), nil
+	}}
+	read := &mockTool{name: "read", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		executed = append(executed, "read "+value)
+		return NewToolResultText(value), nil
+	}}
+
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "write", Input: []byte(`{"value":"first"}`)},
+			&llm.ToolUseContent{ID: "call_2", Name: "read", Input: []byte(`{}`)},
+			&llm.ToolUseContent{ID: "call_3", Name: "write", Input: []byte(`{"value":"second"}`)},
+		),
+		Tools: []Tool{write, read},
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"write first", "read first", "write second"}, executed)
+	assert.Equal(t, []string{"call_1", "call_2", "call_3"}, toolResultIDs(resp))
+	assert.Equal(t, []string{"ok", "first", "ok"}, toolResultTexts(resp))
+}
+
+func TestParallelToolResultsKeepEmittedOrder(t *testing.T) {
+	// The first call finishes last, but its result still comes first
+	sleepy := &mockTool{name: "sleep", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		var args struct{ MS int }
+		if err := json.Unmarshal(input.([]byte), &args); err != nil {
+			return nil, err
+		}
+		time.Sleep(time.Duration(args.MS) * time.Millisecond)
+		return NewToolResultText("slept"), nil
+	}}
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "sleep", Input: []byte(`{"ms":60}`)},
+			&llm.ToolUseContent{ID: "call_2", Name: "sleep", Input: []byte(`{"ms":30}`)},
+			&llm.ToolUseContent{ID: "call_3", Name: "sleep", Input: []byte(`{"ms":0}`)},
+		),
+		Tools:                 []Tool{sleepy},
+		ParallelToolExecution: true,
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"call_1", "call_2", "call_3"}, toolResultIDs(resp))
+}
+
+// sleepTool sleeps for the given duration and records how many sleepTools
+// were running at once.
+func sleepTool(name string, d time.Duration, annotations *ToolAnnotations, running, peak *atomic.Int32) *mockTool {
+	return &mockTool{name: name, annotations: annotations, callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(d)
+		return NewToolResultText("slept"), nil
+	}}
+}
+
+func TestParallelToolExecutionTakesMaxNotSum(t *testing.T) {
+	var running, peak atomic.Int32
+	readOnly := &ToolAnnotations{ReadOnlyHint: true}
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "sleep_a", Input: []byte(`{}`)},
+			&llm.ToolUseContent{ID: "call_2", Name: "sleep_b", Input: []byte(`{}`)},
+		),
+		Tools: []Tool{
+			sleepTool("sleep_a", 200*time.Millisecond, readOnly, &running, &peak),
+			sleepTool("sleep_b", 200*time.Millisecond, readOnly, &running, &peak),
+		},
+		ParallelToolExecution: true,
+	})
+	assert.NoError(t, err)
+
+	start := time.Now()
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	elapsed := time.Since(start)
+	assert.NoError(t, err)
+	assert.True(t, elapsed < 350*time.Millisecond, "elapsed %s, want about 200ms", elapsed)
+	assert.Equal(t, int32(2), peak.Load())
+	assert.Equal(t, []string{"call_1", "call_2"}, toolResultIDs(resp))
+}
+
+// toolSpan is when one tool call ran.
+type toolSpan struct {
+	name       string
+	start, end time.Time
+}
+
+// spanTool returns a tool that sleeps for d and records when it ran.
+func spanTool(name string, d time.Duration, annotations *ToolAnnotations, mu *sync.Mutex, spans *[]toolSpan) *mockTool {
+	return &mockTool{name: name, annotations: annotations, callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		start := time.Now()
+		time.Sleep(d)
+		mu.Lock()
+		*spans = append(*spans, toolSpan{name: name, start: start, end: time.Now()})
+		mu.Unlock()
+		return NewToolResultText("slept"), nil
+	}}
+}
+
+func TestParallelToolExecutionSerializesWriteTools(t *testing.T) {
+	var mu sync.Mutex
+	var spans []toolSpan
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "read", Input: []byte(`{}`)},
+			&llm.ToolUseContent{ID: "call_2", Name: "write", Input: []byte(`{}`)},
+			&llm.ToolUseContent{ID: "call_3", Name: "read", Input: []byte(`{}`)},
+		),
+		Tools: []Tool{
+			spanTool("read", 50*time.Millisecond, &ToolAnnotations{ReadOnlyHint: true}, &mu, &spans),
+			spanTool("write", 50*time.Millisecond, &ToolAnnotations{}, &mu, &spans),
+		},
+		ParallelToolExecution: true,
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"call_1", "call_2", "call_3"}, toolResultIDs(resp))
+
+	// The reads may overlap each other, but nothing runs while the write does
+	assert.Len(t, spans, 3)
+	var write toolSpan
+	for _, span := range spans {
+		if span.name == "write" {
+			write = span
+		}
+	}
+	assert.False(t, write.start.IsZero())
+	for _, span := range spans {
+		if span.name != "write" {
+			assert.True(t, !span.end.After(write.start) || !span.start.Before(write.end),
+				"read ran from %s to %s, during the write from %s to %s",
+				span.start.Format(time.StampMicro), span.end.Format(time.StampMicro),
+				write.start.Format(time.StampMicro), write.end.Format(time.StampMicro))
+		}
+	}
+}
+
+func TestMaxParallelToolsLimitsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	sleepy := sleepTool("sleep", 30*time.Millisecond, nil, &running, &peak)
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "sleep", Input: []byte(`{}`)},
+			&llm.ToolUseContent{ID: "call_2", Name: "sleep", Input: []byte(`{}`)},
+			&llm.ToolUseContent{ID: "call_3", Name: "sleep", Input: []byte(`{}`)},
+			&llm.ToolUseContent{ID: "call_4", Name: "sleep", Input: []byte(`{}`)},
+		),
+		Tools:                 []Tool{sleepy},
+		ParallelToolExecution: true,
+		MaxParallelTools:      2,
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), peak.Load())
+	assert.Equal(t, []string{"call_1", "call_2", "call_3", "call_4"}, toolResultIDs(resp))
+}
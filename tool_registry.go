@@ -0,0 +1,296 @@
+package dive
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+var _ Toolset = &ToolRegistry{}
+
+// ToolCollisionPolicy controls what a ToolRegistry does when two sources
+// provide tools with the same name.
+type ToolCollisionPolicy string
+
+const (
+	// ToolCollisionReject fails tool resolution with a *ToolCollisionError.
+	// This is the default.
+	ToolCollisionReject ToolCollisionPolicy = "reject"
+
+	// ToolCollisionKeepFirst keeps the tool from the source added first and
+	// drops the later one, logging a warning.
+	ToolCollisionKeepFirst ToolCollisionPolicy = "keep_first"
+
+	// ToolCollisionPrefix renames the later tool to "<source>_<name>".
+	ToolCollisionPrefix ToolCollisionPolicy = "prefix"
+)
+
+// ToolCollisionError is returned when two registry sources provide a tool
+// with the same name under ToolCollisionReject.
+type ToolCollisionError struct {
+	Name   string
+	First  string
+	Second string
+}
+
+func (e *ToolCollisionError) Error() string {
+	return fmt.Sprintf("tool %q is provided by both %q and %q", e.Name, e.First, e.Second)
+}
+
+// ToolRegistryOptions configures a ToolRegistry.
+type ToolRegistryOptions struct {
+	// Name identifies the registry as a Toolset. Defaults to "registry".
+	Name string
+
+	// OnCollision sets how duplicate tool names are handled. Defaults to
+	// ToolCollisionReject.
+	OnCollision ToolCollisionPolicy
+
+	// Logger receives collision warnings. Defaults to a null logger.
+	Logger llm.Logger
+}
+
+// ToolRegistry assembles an agent's tools from several sources: static tools
+// such as toolkit built-ins and custom tools, and Toolsets such as MCP
+// managers. It resolves name collisions, lets tools be disabled and
+// re-enabled by name or tag, and implements Toolset, so it can be set on
+// AgentOptions.Toolsets.
+//
+// Toolset sources are resolved on every call to Tools, so tools from MCP
+// servers that connect or disconnect appear and disappear on the agent's
+// next LLM request. To get a fixed list for AgentOptions.Tools instead, call
+// Tools once.
+//
+//	registry := dive.NewToolRegistry()
+//	registry.Add("toolkit", toolkit.NewReadFileTool(), toolkit.NewBashTool())
+//	registry.AddToolset(mcpManager)
+//	registry.Tag("dangerous", "Bash")
+//	registry.DisableTag("dangerous")
+//
+//	agent, err := dive.NewAgent(dive.AgentOptions{
+//	    Model:    model,
+//	    Toolsets: []dive.Toolset{registry},
+//	})
+//
+// Each tool is implicitly tagged with the name of its source. A tool is
+// excluded if its name or any of its tags is disabled. ToolRegistry is safe
+// for concurrent use.
+type ToolRegistry struct {
+	name        string
+	onCollision ToolCollisionPolicy
+	logger      llm.Logger
+
+	mu            sync.RWMutex
+	sources       []*toolSource
+	tags          map[string][]string // tool name -> tags
+	disabledNames map[string]bool
+	disabledTags  map[string]bool
+}
+
+type toolSource struct {
+	name    string
+	tools   []Tool
+	toolset Toolset
+}
+
+// NewToolRegistry creates an empty ToolRegistry.
+func NewToolRegistry(opts ...ToolRegistryOptions) *ToolRegistry {
+	var options ToolRegistryOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.Name == "" {
+		options.Name = "registry"
+	}
+	if options.OnCollision == "" {
+		options.OnCollision = ToolCollisionReject
+	}
+	if options.Logger == nil {
+		options.Logger = &llm.NullLogger{}
+	}
+	return &ToolRegistry{
+		name:          options.Name,
+		onCollision:   options.OnCollision,
+		logger:        options.Logger,
+		tags:          make(map[string][]string),
+		disabledNames: make(map[string]bool),
+		disabledTags:  make(map[string]bool),
+	}
+}
+
+// Add registers static tools under the given source name. Adding to an
+// existing source appends to it.
+func (r *ToolRegistry) Add(source string, tools ...Tool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sources {
+		if s.name == source && s.toolset == nil {
+			s.tools = append(s.tools, tools...)
+			return
+		}
+	}
+	r.sources = append(r.sources, &toolSource{name: source, tools: tools})
+}
+
+// AddToolset registers a dynamic source, named after toolset.Name(). Its
+// tools are resolved on every call to Tools.
+func (r *ToolRegistry) AddToolset(toolset Toolset) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, &toolSource{name: toolset.Name(), toolset: toolset})
+}
+
+// Remove unregisters all sources with the given name.
+func (r *ToolRegistry) Remove(source string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = slices.DeleteFunc(r.sources, func(s *toolSource) bool { return s.name == source })
+}
+
+// Tag adds tag to the named tools. Tags may be applied before the tools are
+// registered.
+func (r *ToolRegistry) Tag(tag string, toolNames ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range toolNames {
+		r.tags[name] = append(r.tags[name], tag)
+	}
+}
+
+// Disable excludes the named tools.
+func (r *ToolRegistry) Disable(toolNames ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range toolNames {
+		r.disabledNames[name] = true
+	}
+}
+
+// Enable re-includes tools excluded by Disable. It does not override a
+// disabled tag.
+func (r *ToolRegistry) Enable(toolNames ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range toolNames {
+		delete(r.disabledNames, name)
+	}
+}
+
+// DisableTag excludes all tools with the given tag or source name.
+func (r *ToolRegistry) DisableTag(tag string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disabledTags[tag] = true
+}
+
+// EnableTag re-includes tools excluded by DisableTag.
+func (r *ToolRegistry) EnableTag(tag string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.disabledTags, tag)
+}
+
+// Name returns the registry name.
+func (r *ToolRegistry) Name() string {
+	return r.name
+}
+
+// Tools returns the enabled tools from all sources, in the order sources
+// were added, with collisions resolved according to the registry's policy.
+func (r *ToolRegistry) Tools(ctx context.Context) ([]Tool, error) {
+	r.mu.RLock()
+	sources := make([]toolSource, len(r.sources))
+	for i, s := range r.sources {
+		sources[i] = *s
+	}
+	r.mu.RUnlock()
+
+	// Resolve toolsets without holding the lock, since they may block.
+	for i := range sources {
+		if sources[i].toolset == nil {
+			continue
+		}
+		tools, err := sources[i].toolset.Tools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("tool source %s: %w", sources[i].name, err)
+		}
+		sources[i].tools = tools
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var result []Tool
+	owners := make(map[string]string) // tool name -> source name
+	for _, source := range sources {
+		for _, tool := range source.tools {
+			name := tool.Name()
+			if r.isDisabled(name, source.name) {
+				continue
+			}
+			if owner, exists := owners[name]; exists {
+				switch r.onCollision {
+				case ToolCollisionKeepFirst:
+					r.logger.Warn("dropping duplicate tool",
+						"tool_name", name, "kept_source", owner, "dropped_source", source.name)
+					continue
+				case ToolCollisionPrefix:
+					prefixed := sanitizeToolName(source.name) + "_" + name
+					if _, taken := owners[prefixed]; taken {
+						return nil, &ToolCollisionError{Name: prefixed, First: owners[prefixed], Second: source.name}
+					}
+					tool = &renamedTool{Tool: tool, name: prefixed}
+					name = prefixed
+				default:
+					return nil, &ToolCollisionError{Name: name, First: owner, Second: source.name}
+				}
+			}
+			owners[name] = source.name
+			result = append(result, tool)
+		}
+	}
+	return result, nil
+}
+
+func (r *ToolRegistry) isDisabled(toolName, source string) bool {
+	if r.disabledNames[toolName] || r.disabledTags[source] {
+		return true
+	}
+	for _, tag := range r.tags[toolName] {
+		if r.disabledTags[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeToolName replaces characters that providers reject in tool names.
+func sanitizeToolName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// renamedTool overrides the name of a tool, preserving any provider-specific
+// configuration.
+type renamedTool struct {
+	Tool
+	name string
+}
+
+func (t *renamedTool) Name() string {
+	return t.name
+}
+
+func (t *renamedTool) ToolConfiguration(providerName string) map[string]any {
+	if toolWithConfig, ok := t.Tool.(llm.ToolConfiguration); ok {
+		return toolWithConfig.ToolConfiguration(providerName)
+	}
+	return nil
+}
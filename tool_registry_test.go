@@ -0,0 +1,101 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func toolNames(tools []Tool) []string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name()
+	}
+	return names
+}
+
+func TestToolRegistry(t *testing.T) {
+	ctx := context.Background()
+	live := []Tool{&mockTool{name: "remote_search"}}
+	registry := NewToolRegistry()
+	registry.Add("toolkit", &mockTool{name: "Read"}, &mockTool{name: "Bash"})
+	registry.AddToolset(&ToolsetFunc{
+		ToolsetName: "mcp",
+		Resolve:     func(ctx context.Context) ([]Tool, error) { return live, nil },
+	})
+	registry.Add("custom", &mockTool{name: "deploy"})
+
+	tools, err := registry.Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Read", "Bash", "remote_search", "deploy"}, toolNames(tools))
+
+	// Toolset sources are resolved on every call.
+	live = append(live, &mockTool{name: "remote_fetch"})
+	tools, err = registry.Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Read", "Bash", "remote_search", "remote_fetch", "deploy"}, toolNames(tools))
+
+	t.Run("disable by name and tag", func(t *testing.T) {
+		registry.Disable("Bash")
+		registry.Tag("production", "deploy")
+		registry.DisableTag("production")
+		registry.DisableTag("mcp")
+		tools, err := registry.Tools(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Read"}, toolNames(tools))
+
+		registry.Enable("Bash")
+		registry.EnableTag("production")
+		registry.EnableTag("mcp")
+		tools, err = registry.Tools(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, tools, 5)
+	})
+
+	t.Run("remove source", func(t *testing.T) {
+		registry.Remove("custom")
+		tools, err := registry.Tools(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Read", "Bash", "remote_search", "remote_fetch"}, toolNames(tools))
+	})
+}
+
+func TestToolRegistryCollisions(t *testing.T) {
+	ctx := context.Background()
+	newRegistry := func(policy ToolCollisionPolicy) *ToolRegistry {
+		registry := NewToolRegistry(ToolRegistryOptions{OnCollision: policy})
+		registry.Add("toolkit", &mockTool{name: "search"})
+		registry.Add("my server", &mockTool{name: "search"})
+		return registry
+	}
+
+	_, err := newRegistry("").Tools(ctx)
+	var collisionErr *ToolCollisionError
+	assert.True(t, errors.As(err, &collisionErr))
+	assert.Equal(t, "search", collisionErr.Name)
+	assert.Equal(t, "toolkit", collisionErr.First)
+	assert.Equal(t, "my server", collisionErr.Second)
+
+	tools, err := newRegistry(ToolCollisionKeepFirst).Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"search"}, toolNames(tools))
+
+	tools, err = newRegistry(ToolCollisionPrefix).Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"search", "my_server_search"}, toolNames(tools))
+}
+
+func TestToolRegistryAsAgentToolset(t *testing.T) {
+	registry := NewToolRegistry(ToolRegistryOptions{OnCollision: ToolCollisionPrefix})
+	registry.Add("a", &mockTool{name: "echo"})
+	registry.Add("b", &mockTool{name: "echo"})
+
+	agent, err := NewAgent(AgentOptions{Model: &mockLLM{}, Toolsets: []Toolset{registry}})
+	assert.NoError(t, err)
+	tools, toolsByName, err := agent.resolveTools(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, tools, 2)
+	assert.NotNil(t, toolsByName["b_echo"])
+}
@@ -0,0 +1,318 @@
+package dive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/deepnoodle-ai/dive/llm"
+)
+
+// ToolSchemaStrategy controls what the agent does when its tool definitions
+// exceed the limits of the provider (see llm.ToolLimiter). The zero value
+// sends tools unchanged and leaves any limit errors to the provider.
+type ToolSchemaStrategy string
+
+const (
+	// ToolSchemaTruncate shortens descriptions that are too long and, when
+	// there are too many tools, sends only the first tools up to the limit.
+	// Dropped tools are logged.
+	ToolSchemaTruncate ToolSchemaStrategy = "truncate"
+
+	// ToolSchemaError fails the request with a *ToolSchemaLimitError naming
+	// the offending tool, before anything is sent to the provider.
+	ToolSchemaError ToolSchemaStrategy = "error"
+
+	// ToolSchemaSplit shortens descriptions like ToolSchemaTruncate but, when
+	// there are too many tools, moves the tools past the limit behind two
+	// meta tools: list_tools, which describes them, and call_tool, which
+	// invokes one by name. Calls made through call_tool run as ordinary
+	// calls of the underlying tool, so hooks and permissions still apply.
+	ToolSchemaSplit ToolSchemaStrategy = "split"
+)
+
+// Names of the meta tools used by ToolSchemaSplit.
+const (
+	ListToolsToolName = "list_tools"
+	CallToolToolName  = "call_tool"
+)
+
+// ToolSchemaLimitError is returned when the agent's tools exceed a provider
+// limit that the active ToolSchemaStrategy cannot work around. Tool is empty
+// when the limit applies to the tool set as a whole.
+type ToolSchemaLimitError struct {
+	Provider string
+	Tool     string
+	Reason   string
+}
+
+func (e *ToolSchemaLimitError) Error() string {
+	if e.Tool == "" {
+		return fmt.Sprintf("tools exceed provider %q limits: %s", e.Provider, e.Reason)
+	}
+	return fmt.Sprintf("tool %q exceeds provider %q limits: %s", e.Tool, e.Provider, e.Reason)
+}
+
+// WithToolSchemaStrategy sets how the agent handles tool definitions that
+// exceed the provider's limits for this call.
+func WithToolSchemaStrategy(strategy ToolSchemaStrategy) CreateResponseOption {
+	return func(opts *CreateResponseOptions) {
+		opts.ToolSchemaStrategy = strategy
+	}
+}
+
+// fittedTools is the tool set to advertise to the model after applying a
+// ToolSchemaStrategy.
+type fittedTools struct {
+	// Tools are the definitions sent to the model.
+	Tools []Tool
+
+	// Hidden holds the tools reachable only through call_tool, keyed by
+	// name. Nil unless ToolSchemaSplit moved tools behind the meta tools.
+	Hidden map[string]Tool
+}
+
+// fitToolSchemas applies strategy to tools using the model's tool limits.
+// Models that don't implement llm.ToolLimiter get their tools unchanged.
+func (a *Agent) fitToolSchemas(model llm.LLM, tools []Tool, strategy ToolSchemaStrategy) (*fittedTools, error) {
+	limiter, ok := model.(llm.ToolLimiter)
+	if strategy == "" || !ok {
+		return &fittedTools{Tools: tools}, nil
+	}
+	limits := limiter.ToolLimits()
+	provider := model.Name()
+
+	fitted := make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		name := tool.Name()
+		if limits.MaxNameLength > 0 && len(name) > limits.MaxNameLength {
+			return nil, &ToolSchemaLimitError{
+				Provider: provider,
+				Tool:     name,
+				Reason:   fmt.Sprintf("name is %d characters (max %d)", len(name), limits.MaxNameLength),
+			}
+		}
+		description := tool.Description()
+		if limits.MaxDescriptionLength > 0 && len(description) > limits.MaxDescriptionLength {
+			if strategy == ToolSchemaError {
+				return nil, &ToolSchemaLimitError{
+					Provider: provider,
+					Tool:     name,
+					Reason:   fmt.Sprintf("description is %d characters (max %d)", len(description), limits.MaxDescriptionLength),
+				}
+			}
+			tool = &describedTool{Tool: tool, description: truncateDescription(description, limits.MaxDescriptionLength)}
+		}
+		fitted = append(fitted, tool)
+	}
+
+	if limits.MaxTools <= 0 || len(fitted) <= limits.MaxTools {
+		return &fittedTools{Tools: fitted}, nil
+	}
+	switch strategy {
+	case ToolSchemaError:
+		return nil, &ToolSchemaLimitError{
+			Provider: provider,
+			Reason:   fmt.Sprintf("%d tools defined (max %d)", len(fitted), limits.MaxTools),
+		}
+	case ToolSchemaSplit:
+		if limits.MaxTools < 3 {
+			return nil, &ToolSchemaLimitError{
+				Provider: provider,
+				Reason:   fmt.Sprintf("%d tools defined (max %d, too few to split)", len(fitted), limits.MaxTools),
+			}
+		}
+		keep := limits.MaxTools - 2
+		hidden := make(map[string]Tool, len(fitted)-keep)
+		for _, tool := range fitted[keep:] {
+			hidden[tool.Name()] = tool
+		}
+		visible := append(fitted[:keep:keep], &listToolsTool{tools: fitted[keep:]}, &callToolTool{})
+		return &fittedTools{Tools: visible, Hidden: hidden}, nil
+	default:
+		var dropped []string
+		for _, tool := range fitted[limits.MaxTools:] {
+			dropped = append(dropped, tool.Name())
+		}
+		a.logger.Warn("dropping tools that exceed provider limit",
+			"agent_name", a.name,
+			"provider", provider,
+			"max_tools", limits.MaxTools,
+			"dropped", dropped,
+		)
+		return &fittedTools{Tools: fitted[:limits.MaxTools]}, nil
+	}
+}
+
+// resolveCallTool returns toolCalls with call_tool invocations of hidden
+// tools rewritten into direct calls, so they run through the normal
+// execution path. Rewritten calls are copies; the model's call_tool request
+// stays in the history as it was sent. The call ID is kept so the tool
+// result still pairs with that request. Calls that don't name a hidden tool
+// are left for callToolTool to reject.
+func (f *fittedTools) resolveCallTool(toolCalls []*llm.ToolUseContent) []*llm.ToolUseContent {
+	if f.Hidden == nil {
+		return toolCalls
+	}
+	resolved := slices.Clone(toolCalls)
+	for i, call := range toolCalls {
+		if call.Name != CallToolToolName {
+			continue
+		}
+		var input callToolInput
+		if err := json.Unmarshal(call.Input, &input); err != nil {
+			continue
+		}
+		if _, ok := f.Hidden[input.Name]; !ok {
+			continue
+		}
+		direct := *call
+		direct.Name = input.Name
+		direct.Input = input.Input
+		if len(direct.Input) == 0 {
+			direct.Input = json.RawMessage("{}")
+		}
+		resolved[i] = &direct
+	}
+	return resolved
+}
+
+// truncateDescription shortens description to at most maxLen bytes without
+// splitting a UTF-8 character.
+func truncateDescription(description string, maxLen int) string {
+	const ellipsis = "..."
+	if maxLen <= len(ellipsis) {
+		return description[:maxLen]
+	}
+	cut := maxLen - len(ellipsis)
+	for cut > 0 && !isRuneStart(description[cut]) {
+		cut--
+	}
+	return description[:cut] + ellipsis
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// withToolExamples returns tools with usage hints and examples from their
+// annotations appended to their descriptions. See DescribeToolUsage.
+func withToolExamples(tools []Tool) []Tool {
+	result := make([]Tool, len(tools))
+	for i, tool := range tools {
+		result[i] = tool
+		description := tool.Description()
+		if described := DescribeToolUsage(description, tool.Annotations()); described != description {
+			result[i] = &describedTool{Tool: tool, description: described}
+		}
+	}
+	return result
+}
+
+// describedTool overrides the description of a tool, preserving any
+// provider-specific configuration.
+type describedTool struct {
+	Tool
+	description string
+}
+
+func (t *describedTool) Description() string {
+	return t.description
+}
+
+func (t *describedTool) ToolConfiguration(providerName string) map[string]any {
+	if toolWithConfig, ok := t.Tool.(llm.ToolConfiguration); ok {
+		return toolWithConfig.ToolConfiguration(providerName)
+	}
+	return nil
+}
+
+// listToolsTool describes the tools hidden behind call_tool.
+type listToolsTool struct {
+	tools []Tool
+}
+
+func (t *listToolsTool) Name() string {
+	return ListToolsToolName
+}
+
+func (t *listToolsTool) Description() string {
+	return "Lists additional tools that can be used through call_tool, with their descriptions and input schemas. Use this when none of your other tools fit the task."
+}
+
+func (t *listToolsTool) Schema() *Schema {
+	return &Schema{Type: Object, Properties: map[string]*SchemaProperty{}}
+}
+
+func (t *listToolsTool) Annotations() *ToolAnnotations {
+	return &ToolAnnotations{
+		Title:          "List Tools",
+		ReadOnlyHint:   true,
+		IdempotentHint: true,
+	}
+}
+
+func (t *listToolsTool) Call(ctx context.Context, input any) (*ToolResult, error) {
+	type toolInfo struct {
+		Name        string  `json:"name"`
+		Description string  `json:"description"`
+		InputSchema *Schema `json:"input_schema,omitempty"`
+	}
+	infos := make([]toolInfo, 0, len(t.tools))
+	for _, tool := range t.tools {
+		infos = append(infos, toolInfo{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			InputSchema: tool.Schema(),
+		})
+	}
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return NewToolResultText(string(data)), nil
+}
+
+type callToolInput struct {
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// callToolTool is the definition of the call_tool meta tool. Valid calls are
+// rewritten by resolveCallTool before execution, so Call only runs when the
+// model names a tool that doesn't exist.
+type callToolTool struct{}
+
+func (t *callToolTool) Name() string {
+	return CallToolToolName
+}
+
+func (t *callToolTool) Description() string {
+	return "Calls one of the tools listed by list_tools. Provide the tool name and an input object matching that tool's input schema."
+}
+
+func (t *callToolTool) Schema() *Schema {
+	return &Schema{
+		Type:     Object,
+		Required: []string{"name", "input"},
+		Properties: map[string]*SchemaProperty{
+			"name": {
+				Type:        String,
+				Description: "Name of the tool to call, as returned by list_tools.",
+			},
+			"input": {
+				Type:        Object,
+				Description: "Input for the tool, matching its input schema.",
+			},
+		},
+	}
+}
+
+func (t *callToolTool) Annotations() *ToolAnnotations {
+	return &ToolAnnotations{Title: "Call Tool"}
+}
+
+func (t *callToolTool) Call(ctx context.Context, input any) (*ToolResult, error) {
+	return NewToolResultError("Unknown tool. Use list_tools to see the tools available through call_tool."), nil
+}
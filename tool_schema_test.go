@@ -0,0 +1,162 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+type limitedLLM struct {
+	mockLLM
+	limits llm.ToolLimits
+}
+
+func (m *limitedLLM) ToolLimits() llm.ToolLimits { return m.limits }
+
+func mockTools(n int) []Tool {
+	tools := make([]Tool, n)
+	for i := range tools {
+		name := fmt.Sprintf("tool_%d", i)
+		tools[i] = &mockTool{
+			name: name,
+			callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+				return NewToolResultText("ran " + name), nil
+			},
+		}
+	}
+	return tools
+}
+
+func TestFitToolSchemas(t *testing.T) {
+	agent, err := NewAgent(AgentOptions{Model: &mockLLM{}})
+	assert.NoError(t, err)
+	model := &limitedLLM{limits: llm.ToolLimits{MaxTools: 3, MaxNameLength: 8, MaxDescriptionLength: 10}}
+	long := FuncTool("long", "a description that is too long",
+		func(ctx context.Context, input struct{}) (*ToolResult, error) { return nil, nil })
+
+	t.Run("no strategy", func(t *testing.T) {
+		fitted, err := agent.fitToolSchemas(model, mockTools(5), "")
+		assert.NoError(t, err)
+		assert.Len(t, fitted.Tools, 5)
+	})
+
+	t.Run("error names tool", func(t *testing.T) {
+		_, err := agent.fitToolSchemas(model, []Tool{long}, ToolSchemaError)
+		var limitErr *ToolSchemaLimitError
+		assert.True(t, errors.As(err, &limitErr))
+		assert.Equal(t, "long", limitErr.Tool)
+		assert.Contains(t, err.Error(), `tool "long"`)
+
+		countLimited := &limitedLLM{limits: llm.ToolLimits{MaxTools: 3}}
+		_, err = agent.fitToolSchemas(countLimited, mockTools(4), ToolSchemaError)
+		assert.True(t, errors.As(err, &limitErr))
+		assert.Equal(t, "", limitErr.Tool)
+	})
+
+	t.Run("long names always error", func(t *testing.T) {
+		tool := &mockTool{name: "much_too_long_name"}
+		_, err := agent.fitToolSchemas(model, []Tool{tool}, ToolSchemaTruncate)
+		assert.Error(t, err)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		fitted, err := agent.fitToolSchemas(model, append([]Tool{long}, mockTools(4)...), ToolSchemaTruncate)
+		assert.NoError(t, err)
+		assert.Len(t, fitted.Tools, 3)
+		assert.Equal(t, "a descr...", fitted.Tools[0].Description())
+		assert.Nil(t, fitted.Hidden)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		fitted, err := agent.fitToolSchemas(model, mockTools(5), ToolSchemaSplit)
+		assert.NoError(t, err)
+		var names []string
+		for _, tool := range fitted.Tools {
+			names = append(names, tool.Name())
+		}
+		assert.Equal(t, []string{"tool_0", ListToolsToolName, CallToolToolName}, names)
+		assert.Len(t, fitted.Hidden, 4)
+
+		result, err := fitted.Tools[1].Call(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].Text, "tool_4")
+	})
+}
+
+func TestTruncateDescription(t *testing.T) {
+	assert.Equal(t, "abc...", truncateDescription("abcdefgh", 6))
+	// Never splits a multi-byte character.
+	assert.Equal(t, "aé...", truncateDescription("aéééé", 6))
+	assert.Equal(t, "aé...", truncateDescription("aéééé", 7))
+	assert.True(t, len(truncateDescription(strings.Repeat("é", 100), 11)) <= 11)
+}
+
+func TestToolSchemaSplitCallTool(t *testing.T) {
+	callCount := 0
+	var advertised []string
+	model := &limitedLLM{limits: llm.ToolLimits{MaxTools: 3}}
+	model.generateFunc = func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+		callCount++
+		if callCount == 1 {
+			config := &llm.Config{}
+			config.Apply(opts...)
+			for _, tool := range config.Tools {
+				advertised = append(advertised, tool.Name())
+			}
+			return &llm.Response{
+				Role: llm.Assistant,
+				Content: []llm.Content{&llm.ToolUseContent{
+					ID:    "call_1",
+					Name:  CallToolToolName,
+					Input: []byte(`{"name": "tool_3", "input": {}}`),
+				}},
+				StopReason: llm.StopReasonToolUse,
+			}, nil
+		}
+		return &llm.Response{
+			Role:       llm.Assistant,
+			Content:    []llm.Content{&llm.TextContent{Text: "done"}},
+			StopReason: llm.StopReasonEndTurn,
+		}, nil
+	}
+
+	agent, err := NewAgent(AgentOptions{Model: model, Tools: mockTools(5)})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("Go"), WithToolSchemaStrategy(ToolSchemaSplit))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tool_0", ListToolsToolName, CallToolToolName}, advertised)
+
+	var results []*ToolCallResult
+	for _, item := range resp.Items {
+		if item.Type == ResponseItemTypeToolCallResult {
+			results = append(results, item.ToolCallResult)
+		}
+	}
+	assert.Len(t, results, 1)
+	assert.Equal(t, "tool_3", results[0].Name)
+	assert.Equal(t, "call_1", results[0].ID)
+	assert.Equal(t, "ran tool_3", results[0].Result.Content[0].Text)
+}
+
+func TestResolveCallToolCopies(t *testing.T) {
+	fitted := &fittedTools{Hidden: map[string]Tool{"tool_3": nil}}
+	call := &llm.ToolUseContent{ID: "call_1", Name: CallToolToolName, Input: []byte(`{"name": "tool_3"}`)}
+	other := &llm.ToolUseContent{ID: "call_2", Name: "tool_0", Input: []byte(`{}`)}
+
+	resolved := fitted.resolveCallTool([]*llm.ToolUseContent{call, other})
+	assert.Len(t, resolved, 2)
+	assert.Equal(t, "tool_3", resolved[0].Name)
+	assert.Equal(t, "call_1", resolved[0].ID)
+	assert.Equal(t, "{}", string(resolved[0].Input))
+	assert.Equal(t, other, resolved[1])
+
+	// The model's call_tool request is unchanged
+	assert.Equal(t, CallToolToolName, call.Name)
+	assert.Equal(t, `{"name": "tool_3"}`, string(call.Input))
+}
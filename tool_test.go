@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/wonton/assert"
 )
 
@@ -146,3 +147,71 @@ func TestToolAnnotations_UnmarshalInvalidBoolHint(t *testing.T) {
 	assert.False(t, ann.EditHint)
 	assert.Equal(t, "x", ann.Extra["custom"])
 }
+
+func TestToolAnnotations_ExamplesRoundTrip(t *testing.T) {
+	ann := &ToolAnnotations{
+		Title:     "Read",
+		UsageHint: "Read files before editing them.",
+		Examples:  []ToolExample{{Description: "Read a file", Input: map[string]any{"path": "main.go"}}},
+	}
+	data, err := json.Marshal(ann)
+	assert.NoError(t, err)
+
+	var decoded ToolAnnotations
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, ann.UsageHint, decoded.UsageHint)
+	assert.Len(t, decoded.Examples, 1)
+	assert.Equal(t, "Read a file", decoded.Examples[0].Description)
+	assert.Empty(t, decoded.Extra)
+}
+
+func TestDescribeToolUsage(t *testing.T) {
+	assert.Equal(t, "Reads a file.", DescribeToolUsage("Reads a file.", nil))
+	assert.Equal(t, "Reads a file.", DescribeToolUsage("Reads a file.", &ToolAnnotations{Title: "Read"}))
+
+	described := DescribeToolUsage("Reads a file.", &ToolAnnotations{
+		UsageHint: "Use absolute paths.",
+		Examples: []ToolExample{
+			{Description: "Read a config file", Input: map[string]any{"path": "/etc/app.yaml"}},
+			{Input: map[string]any{"path": "/tmp/x", "limit": 10}},
+		},
+	})
+	assert.Equal(t, "Reads a file.\n\nUsage: Use absolute paths.\n\nExamples:\n"+
+		`- Read a config file: {"path":"/etc/app.yaml"}`+"\n"+
+		`- {"limit":10,"path":"/tmp/x"}`, described)
+}
+
+func TestIncludeToolExamples(t *testing.T) {
+	var descriptions []string
+	mock := &mockLLM{
+		generateFunc: func(ctx context.Context, opts ...llm.Option) (*llm.Response, error) {
+			config := &llm.Config{}
+			config.Apply(opts...)
+			for _, tool := range config.Tools {
+				descriptions = append(descriptions, tool.Description())
+			}
+			return &llm.Response{
+				Role:       llm.Assistant,
+				Content:    []llm.Content{&llm.TextContent{Text: "ok"}},
+				StopReason: llm.StopReasonEndTurn,
+			}, nil
+		},
+	}
+	tool := &mockTool{name: "read", annotations: &ToolAnnotations{
+		Examples: []ToolExample{{Input: map[string]any{"path": "a.txt"}}},
+	}}
+
+	for _, include := range []bool{false, true} {
+		descriptions = nil
+		agent, err := NewAgent(AgentOptions{Model: mock, Tools: []Tool{tool}, IncludeToolExamples: include})
+		assert.NoError(t, err)
+		_, err = agent.CreateResponse(context.Background(), WithInput("Hi"))
+		assert.NoError(t, err)
+		assert.Len(t, descriptions, 1)
+		if include {
+			assert.Equal(t, "mock tool\n\nExamples:\n- {\"path\":\"a.txt\"}", descriptions[0])
+		} else {
+			assert.Equal(t, "mock tool", descriptions[0])
+		}
+	}
+}
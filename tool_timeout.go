@@ -0,0 +1,107 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ToolTimeoutError is the error of a tool call that ran longer than its
+// timeout. See AgentOptions.ToolTimeout.
+type ToolTimeoutError struct {
+	Tool    string
+	Timeout time.Duration
+}
+
+func (e *ToolTimeoutError) Error() string {
+	return fmt.Sprintf("tool %s timed out after %s", e.Tool, e.Timeout)
+}
+
+// toolExitDelay bounds how long a timed-out tool call is waited for after
+// its context is canceled, before it's abandoned as ignoring cancellation.
+const toolExitDelay = time.Second
+
+// errToolTimeout is the cancellation cause of a tool call's context when
+// its timeout expires.
+var errToolTimeout = errors.New("tool timed out")
+
+// toolTimeoutFor returns the timeout for the named tool, or zero if it has
+// none.
+func (a *Agent) toolTimeoutFor(name string) time.Duration {
+	if timeout, ok := a.toolTimeouts[name]; ok {
+		return timeout
+	}
+	return a.toolTimeout
+}
+
+// timedToolCall is the outcome of a tool call run in its own goroutine. A
+// recovered panic is carried back so it can be re-raised on the caller's
+// goroutine, where executeTool recovers it.
+type timedToolCall struct {
+	output   *ToolResult
+	err      error
+	panicked *toolPanic
+}
+
+// toolPanic is a panic recovered on a tool call's own goroutine, with the
+// stack of that goroutine, since a re-raised panic only has the caller's.
+type toolPanic struct {
+	value any
+	stack []byte
+}
+
+// callTool calls the tool, bounded by its timeout. The tool's context is
+// canceled when the timeout expires and the call reports a
+// *ToolTimeoutError. A tool that doesn't return within toolExitDelay of
+// the cancellation is abandoned, so one that ignores its context can't
+// hang the agent.
+func (a *Agent) callTool(ctx context.Context, tool Tool, input []byte) (*ToolResult, error) {
+	timeout := a.toolTimeoutFor(tool.Name())
+	if timeout <= 0 {
+		return tool.Call(ctx, input)
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, errToolTimeout)
+	defer cancel()
+
+	done := make(chan timedToolCall, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- timedToolCall{panicked: &toolPanic{value: r, stack: debug.Stack()}}
+			}
+		}()
+		output, err := tool.Call(ctx, input)
+		done <- timedToolCall{output: output, err: err}
+	}()
+
+	var call timedToolCall
+	select {
+	case call = <-done:
+	case <-ctx.Done():
+		if !errors.Is(context.Cause(ctx), errToolTimeout) {
+			// The caller was canceled; let the tool finish as it would
+			// without a timeout
+			call = <-done
+			break
+		}
+		a.logger.Warn("tool call timed out", "tool_name", tool.Name(), "timeout", timeout)
+		timer := time.NewTimer(toolExitDelay)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			a.logger.Warn("abandoning tool call that ignored cancellation",
+				"tool_name", tool.Name(), "wait", toolExitDelay)
+		}
+		return nil, &ToolTimeoutError{Tool: tool.Name(), Timeout: timeout}
+	}
+	if call.panicked != nil {
+		panic(call.panicked)
+	}
+	if call.err != nil && errors.Is(context.Cause(ctx), errToolTimeout) {
+		return nil, &ToolTimeoutError{Tool: tool.Name(), Timeout: timeout}
+	}
+	return call.output, call.err
+}
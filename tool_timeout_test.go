@@ -0,0 +1,155 @@
+package dive
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/dive/llm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestToolTimeoutReturnsErrorResult(t *testing.T) {
+	// The tool ignores its context, so only the timeout can end the call
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	hung := &mockTool{name: "hung", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		<-release
+		return NewToolResultText("too late"), nil
+	}}
+
+	var preCalls, postFailureCalls int
+	var hookErr error
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "hung", Input: []byte(`{}`)},
+		),
+		Tools:       []Tool{hung},
+		ToolTimeout: 50 * time.Millisecond,
+		Hooks: Hooks{
+			PreToolUse: []PreToolUseHook{
+				func(ctx context.Context, hctx *HookContext) error {
+					preCalls++
+					return nil
+				},
+			},
+			PostToolUseFailure: []PostToolUseFailureHook{
+				func(ctx context.Context, hctx *HookContext) error {
+					postFailureCalls++
+					hookErr = hctx.Result.Error
+					return nil
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, preCalls)
+	assert.Equal(t, 1, postFailureCalls)
+
+	var timeoutErr *ToolTimeoutError
+	assert.True(t, errors.As(hookErr, &timeoutErr))
+	assert.Equal(t, "hung", timeoutErr.Tool)
+	assert.Equal(t, 50*time.Millisecond, timeoutErr.Timeout)
+
+	texts := toolResultTexts(resp)
+	assert.Len(t, texts, 1)
+	assert.True(t, strings.Contains(texts[0], "timed out after 50ms"))
+	for _, item := range resp.Items {
+		if item.Type == ResponseItemTypeToolCallResult {
+			assert.True(t, item.ToolCallResult.Result.IsError)
+		}
+	}
+}
+
+func TestToolTimeoutCancelsToolContext(t *testing.T) {
+	var deadline bool
+	waiting := &mockTool{name: "wait", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		_, deadline = ctx.Deadline()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "wait", Input: []byte(`{}`)},
+		),
+		Tools:       []Tool{waiting},
+		ToolTimeout: 20 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	assert.True(t, deadline)
+	assert.True(t, strings.Contains(toolResultTexts(resp)[0], "timed out after 20ms"))
+}
+
+func TestToolTimeoutWaitsForToolToExit(t *testing.T) {
+	// The tool takes a moment to clean up after its context is canceled
+	var exited atomic.Bool
+	slowExit := &mockTool{name: "wait", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		exited.Store(true)
+		return nil, ctx.Err()
+	}}
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "wait", Input: []byte(`{}`)},
+		),
+		Tools:       []Tool{slowExit},
+		ToolTimeout: 10 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	_, err = agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	assert.True(t, exited.Load())
+}
+
+func TestToolTimeoutPanicKeepsStack(t *testing.T) {
+	panicky := &mockTool{name: "panicky", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		panic("boom")
+	}}
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "panicky", Input: []byte(`{}`)},
+		),
+		Tools:       []Tool{panicky},
+		ToolTimeout: time.Second,
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	results := resp.ToolCallResults()
+	assert.Len(t, results, 1)
+	assert.Contains(t, results[0].Result.Content[0].Text, "boom")
+	// The stack is the tool's goroutine, not the one that re-raised the panic
+	assert.Contains(t, results[0].Error.Error(), "TestToolTimeoutPanicKeepsStack")
+}
+
+func TestToolTimeoutsOverrideDefault(t *testing.T) {
+	slow := &mockTool{name: "slow", callFunc: func(ctx context.Context, input any) (*ToolResult, error) {
+		time.Sleep(60 * time.Millisecond)
+		return NewToolResultText("finished"), nil
+	}}
+	agent, err := NewAgent(AgentOptions{
+		Model: multiToolLLM(
+			&llm.ToolUseContent{ID: "call_1", Name: "slow", Input: []byte(`{}`)},
+		),
+		Tools:        []Tool{slow},
+		ToolTimeout:  10 * time.Millisecond,
+		ToolTimeouts: map[string]time.Duration{"slow": 0},
+	})
+	assert.NoError(t, err)
+
+	resp, err := agent.CreateResponse(context.Background(), WithInput("go"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"finished"}, toolResultTexts(resp))
+}
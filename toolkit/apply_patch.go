@@ -0,0 +1,306 @@
+package toolkit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+var (
+	_ dive.TypedTool[*ApplyPatchInput]          = &ApplyPatchTool{}
+	_ dive.TypedToolPreviewer[*ApplyPatchInput] = &ApplyPatchTool{}
+)
+
+// ApplyPatchInput represents the input parameters for the ApplyPatch tool.
+type ApplyPatchInput struct {
+	// Patch is a unified diff, as produced by "diff -u" or "git diff",
+	// that may change several files. Required.
+	Patch string `json:"patch"`
+}
+
+// ApplyPatchToolOptions configures the behavior of [ApplyPatchTool].
+type ApplyPatchToolOptions struct {
+	// MaxFileSize is the maximum size in bytes of a file the patch changes.
+	// Defaults to 10MB if not specified.
+	MaxFileSize int64
+
+	// WorkspaceDir is the directory patch paths are relative to. Patches
+	// may only change files within it. Defaults to the current working
+	// directory. Ignored when Validator is set.
+	WorkspaceDir string
+
+	// Validator is an optional shared PathValidator. When set, its
+	// workspace is used instead of WorkspaceDir.
+	Validator *PathValidator
+}
+
+// ApplyPatchTool applies a unified diff to files in the workspace.
+//
+// Models often produce one diff more reliably than many Edit calls. The
+// patch may create, delete, rename, and change several files. Each hunk is
+// matched against the file's current content, allowing for lines that
+// moved and for differences in whitespace. Every file is checked before
+// any is written: if a hunk doesn't match or a path is outside the
+// workspace, nothing changes.
+type ApplyPatchTool struct {
+	fileEditor
+}
+
+// NewApplyPatchTool creates a new ApplyPatchTool with the given options.
+// If no options are provided, defaults are used.
+func NewApplyPatchTool(opts ...ApplyPatchToolOptions) *dive.TypedToolAdapter[*ApplyPatchInput] {
+	var resolvedOpts ApplyPatchToolOptions
+	if len(opts) > 0 {
+		resolvedOpts = opts[0]
+	}
+	validator := resolvedOpts.Validator
+	var configErr error
+	if validator == nil && resolvedOpts.WorkspaceDir == "" {
+		// Patch paths are relative, so there is always a workspace
+		validator, configErr = NewPathValidator("")
+	}
+	editor := newFileEditor(resolvedOpts.MaxFileSize, resolvedOpts.WorkspaceDir, validator)
+	if configErr != nil {
+		editor.configErr = fmt.Errorf("invalid workspace configuration: %w", configErr)
+	}
+	return dive.ToolAdapter(&ApplyPatchTool{fileEditor: editor})
+}
+
+// Name returns "ApplyPatch" as the tool identifier.
+func (t *ApplyPatchTool) Name() string {
+	return "ApplyPatch"
+}
+
+// Description returns detailed usage instructions for the LLM.
+func (t *ApplyPatchTool) Description() string {
+	return `Apply a unified diff to one or more files in the workspace.
+
+Use this for changes that span many places or files. The patch uses the
+format of "diff -u" and "git diff": a "--- old" and "+++ new" header for
+each file, followed by "@@ -start,count +start,count @@" hunks of context
+(" "), removed ("-"), and added ("+") lines.
+
+- Paths are relative to the workspace; git's a/ and b/ prefixes are fine
+- Use /dev/null as the old path to create a file, or the new path to delete one
+- Include about 3 lines of context around each change
+- Hunks still apply if their lines moved, or differ only in whitespace
+
+Either every file is changed or none is: if any hunk doesn't match the
+current file, the error names it and nothing is written. Read the file
+again before retrying.`
+}
+
+// Schema returns the JSON schema describing the tool's input parameters.
+func (t *ApplyPatchTool) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type:     "object",
+		Required: []string{"patch"},
+		Properties: map[string]*schema.Property{
+			"patch": {
+				Type:        "string",
+				Description: "The unified diff to apply",
+			},
+		},
+	}
+}
+
+// Annotations returns metadata hints about the tool's behavior.
+// ApplyPatch is marked as destructive (modifies and may delete files) and
+// has EditHint set for special UI treatment. It is not idempotent: a hunk
+// can match again after it was applied.
+func (t *ApplyPatchTool) Annotations() *dive.ToolAnnotations {
+	return &dive.ToolAnnotations{
+		Title:           "ApplyPatch",
+		ReadOnlyHint:    false,
+		DestructiveHint: true,
+		IdempotentHint:  false,
+		OpenWorldHint:   false,
+		EditHint:        true,
+	}
+}
+
+// PreviewCall returns a summary of the patch for permission prompts.
+func (t *ApplyPatchTool) PreviewCall(ctx context.Context, input *ApplyPatchInput) *dive.ToolCallPreview {
+	patches, err := parsePatch(input.Patch)
+	if err != nil {
+		return &dive.ToolCallPreview{Summary: "Apply patch"}
+	}
+	paths := make([]string, len(patches))
+	for i, fp := range patches {
+		paths[i] = fp.displayPath()
+	}
+	return &dive.ToolCallPreview{
+		Summary: fmt.Sprintf("Apply patch to %s", strings.Join(paths, ", ")),
+		Details: input.Patch,
+	}
+}
+
+// patchedFile is the planned result of applying one file patch.
+type patchedFile struct {
+	fp       *filePatch
+	source   string // absolute path read, or "" when creating
+	target   string // absolute path written, or "" when deleting
+	content  string
+	mode     os.FileMode
+	added    int
+	removed  int
+	tempPath string
+}
+
+// Call applies the patch and returns a summary of the changed files.
+//
+// The patch is applied in two phases. First every file is validated, read,
+// and patched in memory, and the new content is written to temporary
+// files. Only when all of that succeeds are the temporary files renamed
+// into place and deleted files removed.
+func (t *ApplyPatchTool) Call(ctx context.Context, input *ApplyPatchInput) (*dive.ToolResult, error) {
+	if t.configErr != nil {
+		return dive.NewToolResultError(fmt.Sprintf("error: %s", t.configErr.Error())), nil
+	}
+	patches, err := parsePatch(input.Patch)
+	if err != nil {
+		return dive.NewToolResultError(fmt.Sprintf("Invalid patch: %v", err)), nil
+	}
+
+	files := make([]*patchedFile, 0, len(patches))
+	seen := map[string]bool{}
+	for _, fp := range patches {
+		pf, errResult := t.planFile(fp, seen)
+		if errResult != nil {
+			return errResult, nil
+		}
+		files = append(files, pf)
+	}
+
+	// Stage every write before changing anything
+	cleanup := func() {
+		for _, pf := range files {
+			if pf.tempPath != "" {
+				os.Remove(pf.tempPath)
+			}
+		}
+	}
+	for _, pf := range files {
+		if pf.target == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(pf.target), 0755); err != nil {
+			cleanup()
+			return dive.NewToolResultError(fmt.Sprintf("Error creating directory for %s: %v. No files were changed.", pf.fp.displayPath(), err)), nil
+		}
+		pf.tempPath, err = stageFile(pf.target, []byte(pf.content), pf.mode)
+		if err != nil {
+			cleanup()
+			return dive.NewToolResultError(fmt.Sprintf("Error writing %s: %v. No files were changed.", pf.fp.displayPath(), err)), nil
+		}
+	}
+
+	for i, pf := range files {
+		if pf.tempPath != "" {
+			if err := os.Rename(pf.tempPath, pf.target); err != nil {
+				cleanup()
+				return dive.NewToolResultError(fmt.Sprintf("Error writing %s: %v. %d of %d files were changed.", pf.fp.displayPath(), err, i, len(files))), nil
+			}
+			pf.tempPath = ""
+		}
+		if pf.source != "" && pf.source != pf.target {
+			if err := os.Remove(pf.source); err != nil {
+				cleanup()
+				return dive.NewToolResultError(fmt.Sprintf("Error removing %s: %v. %d of %d files were changed.", pf.fp.oldPath, err, i, len(files))), nil
+			}
+		}
+	}
+
+	summary := patchSummary(files)
+	return dive.NewToolResultText(summary).WithDisplay(summary), nil
+}
+
+// planFile validates the paths of fp, reads the file it changes, and
+// applies its hunks in memory. seen tracks the files already planned, so
+// a patch can't change one file twice.
+func (t *ApplyPatchTool) planFile(fp *filePatch, seen map[string]bool) (*patchedFile, *dive.ToolResult) {
+	pf := &patchedFile{fp: fp, mode: 0644}
+	var err error
+	if fp.oldPath != "" {
+		if pf.source, err = t.resolvePatchPath(fp.oldPath); err != nil {
+			return nil, dive.NewToolResultError(fmt.Sprintf("Error: %s: %v. No files were changed.", fp.oldPath, err))
+		}
+	}
+	if fp.newPath != "" {
+		if pf.target, err = t.resolvePatchPath(fp.newPath); err != nil {
+			return nil, dive.NewToolResultError(fmt.Sprintf("Error: %s: %v. No files were changed.", fp.newPath, err))
+		}
+	}
+	paths := []string{pf.source}
+	if pf.target != pf.source {
+		paths = append(paths, pf.target)
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if seen[path] {
+			return nil, dive.NewToolResultError(fmt.Sprintf("Error: the patch changes %s more than once. No files were changed.", path))
+		}
+		seen[path] = true
+	}
+
+	var content string
+	if pf.source != "" {
+		var errResult *dive.ToolResult
+		content, pf.mode, errResult = t.readFile(pf.source)
+		if errResult != nil {
+			return nil, errResult
+		}
+	}
+	if pf.target != "" && pf.target != pf.source {
+		if _, err := os.Lstat(pf.target); err == nil {
+			return nil, dive.NewToolResultError(fmt.Sprintf("Error: %s already exists. No files were changed.", fp.newPath))
+		}
+	}
+
+	pf.content, pf.added, pf.removed, err = applyHunks(content, fp)
+	if err != nil {
+		return nil, dive.NewToolResultError(fmt.Sprintf("Error: %s: %v. No files were changed.", fp.displayPath(), err))
+	}
+	if pf.target == "" && pf.content != "" {
+		return nil, dive.NewToolResultError(fmt.Sprintf("Error: the patch deletes %s but does not remove all of its lines. No files were changed.", fp.oldPath))
+	}
+	return pf, nil
+}
+
+// resolvePatchPath returns the absolute path of a patch path, which is
+// relative to the workspace, and checks it can be written.
+func (t *ApplyPatchTool) resolvePatchPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.pathValidator.WorkspaceDir, path)
+	}
+	if err := t.pathValidator.ValidateWrite(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// patchSummary lists the changed files with their line counts.
+func patchSummary(files []*patchedFile) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Applied patch to %d file%s:", len(files), pluralize(len(files)))
+	for _, pf := range files {
+		status := "modified"
+		switch {
+		case pf.source == "":
+			status = "created"
+		case pf.target == "":
+			status = "deleted"
+		case pf.source != pf.target:
+			status = "renamed"
+		}
+		fmt.Fprintf(&b, "\n  %s %s (+%d -%d)", status, pf.fp.displayPath(), pf.added, pf.removed)
+	}
+	return b.String()
+}
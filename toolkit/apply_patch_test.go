@@ -0,0 +1,272 @@
+package toolkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func readTestFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestApplyPatchTool_Metadata(t *testing.T) {
+	tool := NewApplyPatchTool()
+	assert.Equal(t, "ApplyPatch", tool.Name())
+	assert.Contains(t, tool.Schema().Required, "patch")
+
+	annotations := tool.Annotations()
+	assert.True(t, annotations.DestructiveHint)
+	assert.True(t, annotations.EditHint)
+}
+
+func TestApplyPatchTool_MultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.go":     "package main\n\nfunc main() {\n\tgreet()\n}\n",
+		"greet.go":    "package main\n\nimport \"fmt\"\n\nfunc greet() {\n\tfmt.Println(\"hi\")\n}\n",
+		"obsolete.go": "package main\n\nvar unused = 1\n",
+	})
+
+	patch := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,5 +1,6 @@
+ package main
+
+ func main() {
+ 	greet()
++	farewell()
+ }
+--- a/greet.go
++++ b/greet.go
+@@ -4,4 +4,8 @@ import "fmt"
+
+ func greet() {
+-	fmt.Println("hi")
++	fmt.Println("hello")
++}
++
++func farewell() {
++	fmt.Println("bye")
+ }
+--- /dev/null
++++ b/util/util.go
+@@ -0,0 +1 @@
++package util
+--- a/obsolete.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package main
+-
+-var unused = 1
+`
+	tool := NewApplyPatchTool(ApplyPatchToolOptions{WorkspaceDir: dir})
+	result, err := tool.Call(context.Background(), &ApplyPatchInput{Patch: patch})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError, result.Content[0].Text)
+	assert.Equal(t, "Applied patch to 4 files:\n"+
+		"  modified main.go (+1 -0)\n"+
+		"  modified greet.go (+5 -1)\n"+
+		"  created util/util.go (+1 -0)\n"+
+		"  deleted obsolete.go (+0 -3)", result.Content[0].Text)
+
+	assert.Equal(t, "package main\n\nfunc main() {\n\tgreet()\n\tfarewell()\n}\n", readTestFile(t, dir, "main.go"))
+	assert.Equal(t, "package main\n\nimport \"fmt\"\n\nfunc greet() {\n\tfmt.Println(\"hello\")\n}\n\nfunc farewell() {\n\tfmt.Println(\"bye\")\n}\n", readTestFile(t, dir, "greet.go"))
+	assert.Equal(t, "package util\n", readTestFile(t, dir, "util/util.go"))
+	_, err = os.Stat(filepath.Join(dir, "obsolete.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestApplyPatchTool_FuzzyContext(t *testing.T) {
+	dir := t.TempDir()
+	// Two lines were added above the hunk since the patch was made, and
+	// the context uses spaces where the file uses a tab
+	writeTestFiles(t, dir, map[string]string{
+		"a.py": "import os\nimport sys\n\ndef run():\n\tx = 1\n\treturn x\n",
+	})
+	patch := `--- a.py
++++ a.py
+@@ -1,3 +1,3 @@
+ def run():
+-    x = 1
++    x = 2
+     return x
+`
+	tool := NewApplyPatchTool(ApplyPatchToolOptions{WorkspaceDir: dir})
+	result, err := tool.Call(context.Background(), &ApplyPatchInput{Patch: patch})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError, result.Content[0].Text)
+	assert.Equal(t, "import os\nimport sys\n\ndef run():\n    x = 2\n\treturn x\n", readTestFile(t, dir, "a.py"))
+}
+
+func TestApplyPatchTool_PreservesCRLFAndMissingNewline(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"crlf.txt":   "one\r\ntwo\r\nthree\r\n",
+		"no_eol.txt": "first\nlast",
+	})
+	patch := `--- a/crlf.txt
++++ b/crlf.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+--- a/no_eol.txt
++++ b/no_eol.txt
+@@ -1,2 +1,2 @@
+ first
+-last
+\ No newline at end of file
++LAST
+\ No newline at end of file
+`
+	tool := NewApplyPatchTool(ApplyPatchToolOptions{WorkspaceDir: dir})
+	result, err := tool.Call(context.Background(), &ApplyPatchInput{Patch: patch})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError, result.Content[0].Text)
+	assert.Equal(t, "one\r\nTWO\r\nthree\r\n", readTestFile(t, dir, "crlf.txt"))
+	assert.Equal(t, "first\nLAST", readTestFile(t, dir, "no_eol.txt"))
+}
+
+func TestApplyPatchTool_ConflictingHunkChangesNothing(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt": "alpha\nbeta\ngamma\n",
+		"b.txt": "one\ntwo\nthree\n",
+	}
+	writeTestFiles(t, dir, files)
+
+	// The first file applies cleanly, but the second hunk of b.txt
+	// expects content the file doesn't have
+	patch := `--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ alpha
+-beta
++BETA
+ gamma
+--- a/b.txt
++++ b/b.txt
+@@ -1,1 +1,1 @@
+-one
++ONE
+@@ -3,1 +3,1 @@
+-four
++FOUR
+`
+	tool := NewApplyPatchTool(ApplyPatchToolOptions{WorkspaceDir: dir})
+	result, err := tool.Call(context.Background(), &ApplyPatchInput{Patch: patch})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "b.txt: hunk 2 (@@ -3,1 +3,1 @@) does not match the file")
+	assert.Contains(t, result.Content[0].Text, "No files were changed")
+
+	for name, content := range files {
+		assert.Equal(t, content, readTestFile(t, dir, name))
+	}
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestApplyPatchTool_OverlappingHunksConflict(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "x\ny\n"})
+
+	// Both hunks remove the same line
+	patch := `--- a/a.txt
++++ b/a.txt
+@@ -1,1 +1,1 @@
+-x
++X
+@@ -1,1 +1,1 @@
+-x
++Z
+`
+	tool := NewApplyPatchTool(ApplyPatchToolOptions{WorkspaceDir: dir})
+	result, err := tool.Call(context.Background(), &ApplyPatchInput{Patch: patch})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "hunk 2")
+	assert.Equal(t, "x\ny\n", readTestFile(t, dir, "a.txt"))
+}
+
+func TestApplyPatchTool_RejectsPathsOutsideWorkspace(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "workspace")
+	writeTestFiles(t, parent, map[string]string{
+		"secret.txt":       "secret\n",
+		"workspace/ok.txt": "ok\n",
+	})
+
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"relative escape", "--- a/../secret.txt\n+++ b/../secret.txt\n@@ -1 +1 @@\n-secret\n+leaked\n"},
+		{"absolute path", "--- " + filepath.Join(parent, "secret.txt") + "\n+++ " + filepath.Join(parent, "secret.txt") + "\n@@ -1 +1 @@\n-secret\n+leaked\n"},
+		{"create outside", "--- /dev/null\n+++ b/../new.txt\n@@ -0,0 +1 @@\n+new\n"},
+	}
+	tool := NewApplyPatchTool(ApplyPatchToolOptions{WorkspaceDir: dir})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Call(context.Background(), &ApplyPatchInput{
+				Patch: "--- a/ok.txt\n+++ b/ok.txt\n@@ -1 +1 @@\n-ok\n+changed\n" + tt.patch,
+			})
+			assert.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].Text, "outside workspace")
+		})
+	}
+	assert.Equal(t, "secret\n", readTestFile(t, parent, "secret.txt"))
+	assert.Equal(t, "ok\n", readTestFile(t, dir, "ok.txt"))
+	_, err := os.Stat(filepath.Join(parent, "new.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestApplyPatchTool_InvalidPatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a\n"})
+	tool := NewApplyPatchTool(ApplyPatchToolOptions{WorkspaceDir: dir})
+
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"empty", "", "no file changes found"},
+		{"hunk without header", "@@ -1 +1 @@\n-a\n+b\n", "hunk before any file header"},
+		{"short hunk", "--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n-a\n+b\n", "expected 2 old and 2 new lines"},
+		{"bad line", "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n*a\n+b\n", "unexpected line in hunk"},
+		{"create existing", "--- /dev/null\n+++ b/a.txt\n@@ -0,0 +1 @@\n+b\n", "a.txt already exists"},
+		{"partial delete", "--- a/a.txt\n+++ /dev/null\n@@ -1 +1 @@\n-a\n+b\n", "does not remove all of its lines"},
+		{"same file twice", "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-a\n+b\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-b\n+c\n", "more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Call(context.Background(), &ApplyPatchInput{Patch: tt.patch})
+			assert.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].Text, tt.want)
+		})
+	}
+	assert.Equal(t, "a\n", readTestFile(t, dir, "a.txt"))
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -65,6 +66,41 @@ type BashToolOptions struct {
 	// Output exceeding this limit is truncated with a warning.
 	// Defaults to [DefaultMaxOutputLength] (30000 characters).
 	MaxOutputLength int
+
+	// AllowedCommands, when set, restricts commands to these programs. Each
+	// program a command runs, including every part of a compound command
+	// and the command run by wrappers such as env or sudo and by bash -c,
+	// must be listed. Entries are names, such as "git", or paths; a name
+	// matches commands that run the program by that name or by the path
+	// it has on PATH, and a path matches commands that resolve to it after
+	// following symlinks. Shell builtins such as cd and echo are matched by
+	// name and must be listed too. Commands whose program can't be
+	// determined, such as those using command substitution, a variable as
+	// the command name, or wrapper options that aren't recognized, are
+	// refused.
+	AllowedCommands []string
+
+	// DeniedCommands are programs commands may not run, matched like
+	// AllowedCommands and also by the name of the program a path resolves
+	// to. A denylist can't anticipate every way to run a program, such as
+	// through xargs, find -exec, or an interpreter like python, so prefer
+	// AllowedCommands when the commands come from an untrusted model.
+	DeniedCommands []string
+
+	// MaxCPUTime limits the CPU time of a command and each process it
+	// starts. A process exceeding it is killed. Zero means no limit.
+	MaxCPUTime time.Duration
+
+	// MaxMemoryBytes limits the virtual memory of a command and each
+	// process it starts; allocations beyond it fail. Runtimes that reserve
+	// a large address space up front, such as Go and the JVM, need a
+	// generous limit. Zero means no limit.
+	MaxMemoryBytes int64
+
+	// EnvFilter reports whether an environment variable, by name, is passed
+	// to commands. Use [ExcludeSecretEnv] to withhold credentials. When
+	// nil, commands inherit the whole environment.
+	EnvFilter func(name string) bool
 }
 
 // BashTool executes shell commands and captures their output.
@@ -76,16 +112,23 @@ type BashToolOptions struct {
 //   - Configurable timeout (default 2 minutes, max 10 minutes)
 //   - Output truncation to prevent overwhelming the LLM
 //   - Working directory validation when workspace restrictions are enabled
+//   - Optional command allowlist and denylist, CPU and memory limits, and
+//     environment filtering
 //   - Non-interactive only (no stdin support)
 //
 // Security: This tool can execute arbitrary shell commands. Use workspace
-// restrictions and the agent permission system to control what commands
-// are allowed.
+// restrictions, AllowedCommands, and the agent permission system to control
+// what commands are allowed. A command refused by the allowlist or denylist
+// returns an error result without running.
 type BashTool struct {
 	pathValidator *PathValidator
 	maxOutputLen  int
 	workspaceDir  string
 	configErr     error
+	policy        *commandPolicy
+	maxCPUTime    time.Duration
+	maxMemory     int64
+	envFilter     func(name string) bool
 }
 
 // NewBashTool creates a new BashTool with the given options.
@@ -110,11 +153,19 @@ func NewBashTool(opts ...BashToolOptions) *dive.TypedToolAdapter[*BashInput] {
 		}
 	}
 
+	if configErr == nil && !resourceLimitsSupported && (resolvedOpts.MaxCPUTime > 0 || resolvedOpts.MaxMemoryBytes > 0) {
+		configErr = fmt.Errorf("MaxCPUTime and MaxMemoryBytes are not supported on %s", runtime.GOOS)
+	}
+
 	return dive.ToolAdapter(&BashTool{
 		pathValidator: pathValidator,
 		maxOutputLen:  resolvedOpts.MaxOutputLength,
 		workspaceDir:  resolvedOpts.WorkspaceDir,
 		configErr:     configErr,
+		policy:        newCommandPolicy(resolvedOpts.AllowedCommands, resolvedOpts.DeniedCommands),
+		maxCPUTime:    resolvedOpts.MaxCPUTime,
+		maxMemory:     resolvedOpts.MaxMemoryBytes,
+		envFilter:     resolvedOpts.EnvFilter,
 	})
 }
 
@@ -222,6 +273,18 @@ func (t *BashTool) Call(ctx context.Context, input *BashInput) (*dive.ToolResult
 		}
 	}
 
+	// Refuse commands outside the allowed and denied commands
+	if t.policy != nil {
+		dir := input.WorkingDirectory
+		if dir == "" {
+			dir, _ = os.Getwd()
+		}
+		if err := t.policy.check(input.Command, dir); err != nil {
+			display := fmt.Sprintf("Blocked `%s`", truncateCommand(input.Command, 40))
+			return dive.NewToolResultError(fmt.Sprintf("error: command not permitted: %s", err.Error())).WithDisplay(display), nil
+		}
+	}
+
 	// Calculate timeout
 	timeout := DefaultBashTimeout
 	if input.Timeout > 0 {
@@ -273,12 +336,16 @@ func (t *BashTool) execute(ctx context.Context, command, workingDir string, time
 
 	// Determine shell based on OS
 	shell, shellArgs := shellCommand()
-	shellArgs = append(shellArgs, command)
+	shellArgs = append(shellArgs, limitResources(command, t.maxCPUTime, t.maxMemory))
 
 	cmd := exec.CommandContext(ctx, shell, shellArgs...)
+	reapProcessGroup(cmd)
 	if workingDir != "" {
 		cmd.Dir = workingDir
 	}
+	if t.envFilter != nil {
+		cmd.Env = filterEnv(t.envFilter)
+	}
 
 	// Set up stdout streaming via pipe if streaming is available
 	var stdoutBuf bytes.Buffer
@@ -306,7 +373,8 @@ func (t *BashTool) execute(ctx context.Context, command, workingDir string, time
 	if stdoutPipe != nil {
 		scanner := bufio.NewScanner(stdoutPipe)
 		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-		start := time.Now()
+		clock := dive.ClockFromContext(ctx)
+		start := clock.Now()
 		var lineCount, byteCount int
 		var lastProgress time.Time
 		for scanner.Scan() {
@@ -317,17 +385,20 @@ func (t *BashTool) execute(ctx context.Context, command, workingDir string, time
 			// StreamOutput above carries the raw text deltas. ReportProgress is
 			// the parallel structured channel: a latest-wins snapshot of how far
 			// the command has gotten. Throttled to ~10/sec so the cadence tracks
-			// elapsed time rather than output volume.
+			// elapsed time rather than output volume. The throttle uses the
+			// monotonic wall clock, since a fake agent clock that doesn't
+			// advance would otherwise suppress every report after the first;
+			// elapsed_ms comes from the agent clock.
 			lineCount++
 			byteCount += len(line)
-			if now := time.Now(); now.Sub(lastProgress) >= 100*time.Millisecond {
-				lastProgress = now
+			if time.Since(lastProgress) >= 100*time.Millisecond {
+				lastProgress = time.Now()
 				dive.ReportProgress(ctx, &dive.ToolProgress{
 					Display: fmt.Sprintf("%d lines · %s", lineCount, humanizeBytes(byteCount)),
 					Metadata: map[string]any{
 						"lines":      lineCount,
 						"bytes":      byteCount,
-						"elapsed_ms": time.Since(start).Milliseconds(),
+						"elapsed_ms": clock.Now().Sub(start).Milliseconds(),
 					},
 				})
 			}
@@ -364,6 +435,10 @@ func (t *BashTool) execute(ctx context.Context, command, workingDir string, time
 	return stdout, stderr, exitCode, nil
 }
 
+// processWaitDelay bounds how long a killed command's output is drained
+// before its pipes are closed, in case a subprocess escaped the kill.
+const processWaitDelay = 2 * time.Second
+
 // shellCommand returns the shell and arguments for command execution.
 func shellCommand() (string, []string) {
 	if runtime.GOOS == "windows" {
@@ -0,0 +1,470 @@
+package toolkit
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive/permission"
+)
+
+// commandPolicy checks the programs a shell command runs against the
+// allowed and denied commands of a BashTool.
+type commandPolicy struct {
+	allowed *commandList
+	denied  *commandList
+}
+
+// commandList is a set of commands, given by name or by path.
+type commandList struct {
+	// names are entries given by name, such as "git"
+	names map[string]bool
+	// paths are the resolved paths of all entries, including those given
+	// by name that are found on PATH
+	paths map[string]bool
+}
+
+// maxCommandDepth bounds how deeply scripts passed to shells and eval are
+// checked.
+const maxCommandDepth = 5
+
+var (
+	// shellKeywords may precede the command of a segment, or make up a
+	// segment that runs no command, like "fi" or "done".
+	shellKeywords = map[string]bool{
+		"if": true, "then": true, "else": true, "elif": true, "fi": true,
+		"do": true, "done": true, "while": true, "until": true,
+		"!": true, "{": true, "}": true, "(": true, ")": true, "time": true,
+	}
+
+	// commandWrappers run the command that follows their options, so both
+	// the wrapper and the command are checked. Options a wrapper doesn't
+	// list can't be told apart from the command, so commands using them
+	// are refused.
+	commandWrappers = map[string]wrapperOptions{
+		"env": {
+			flags:  "i0v",
+			values: "uCS",
+			long: map[string]bool{
+				"ignore-environment": false, "null": false, "debug": false,
+				"unset": true, "chdir": true, "split-string": true,
+			},
+		},
+		"command": {flags: "pvV"},
+		"builtin": {},
+		"exec":    {flags: "cl", values: "a"},
+		"nohup":   {},
+		"sudo": {
+			flags:  "AbEHiKkLlnPSsVv",
+			values: "CDghpRrTtUu",
+			long: map[string]bool{
+				"askpass": false, "background": false, "bell": false,
+				"preserve-env": false, "set-home": false, "login": false,
+				"remove-timestamp": false, "reset-timestamp": false,
+				"non-interactive": false, "preserve-groups": false,
+				"shell": false, "stdin": false,
+				"close-from": true, "chdir": true, "group": true, "host": true,
+				"prompt": true, "chroot": true, "role": true,
+				"command-timeout": true, "type": true, "other-user": true,
+				"user": true,
+			},
+		},
+		"time": {
+			flags:  "apqv",
+			values: "fo",
+			long: map[string]bool{
+				"append": false, "portability": false, "quiet": false,
+				"verbose": false, "format": true, "output": true,
+			},
+		},
+	}
+
+	// shells run the script given with -c, alone or in a cluster of flags
+	// such as -ec, which is checked in turn.
+	shells = map[string]bool{
+		"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+	}
+
+	envAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+	redirection   = regexp.MustCompile(`^[0-9]*(?:[<>]|&>)`)
+	// bareRedirection is a redirection operator whose target is the next
+	// word, unlike 2>&1 or >log
+	bareRedirection = regexp.MustCompile(`^[0-9]*(?:[<>]+|[<>]&|&>>?)$`)
+)
+
+// newCommandPolicy returns a policy for the given lists, or nil when both
+// are empty.
+func newCommandPolicy(allowed, denied []string) *commandPolicy {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil
+	}
+	policy := &commandPolicy{}
+	if len(allowed) > 0 {
+		policy.allowed = newCommandList(allowed)
+	}
+	if len(denied) > 0 {
+		policy.denied = newCommandList(denied)
+	}
+	return policy
+}
+
+func newCommandList(entries []string) *commandList {
+	list := &commandList{names: map[string]bool{}, paths: map[string]bool{}}
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			list.names[entry] = true
+		}
+		if resolved := resolveCommand(entry, ""); resolved != "" {
+			list.paths[resolved] = true
+		}
+	}
+	return list
+}
+
+// resolveCommand returns the path of the program that word runs, with
+// symlinks resolved, or "" if it isn't found. Names are looked up on PATH
+// and relative paths resolved from dir.
+func resolveCommand(word, dir string) string {
+	path := word
+	if !strings.Contains(word, "/") {
+		found, err := exec.LookPath(word)
+		if err != nil {
+			return ""
+		}
+		path = found
+	} else if !filepath.IsAbs(word) && dir != "" {
+		path = filepath.Join(dir, word)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return ""
+	}
+	if abs, err := filepath.Abs(resolved); err == nil {
+		resolved = abs
+	}
+	return resolved
+}
+
+// allows reports whether a command word is in the list. A name must be
+// listed by name, or resolve to a listed path; a path must resolve to a
+// listed path, so an allowed name can't be impersonated by another program
+// of the same name.
+func (l *commandList) allows(word, dir string) bool {
+	if !strings.Contains(word, "/") && l.names[word] {
+		return true
+	}
+	resolved := resolveCommand(word, dir)
+	return resolved != "" && l.paths[resolved]
+}
+
+// denies reports whether a command word matches the list by name, by the
+// name of the program it resolves to, or by resolved path.
+func (l *commandList) denies(word, dir string) bool {
+	if l.names[filepath.Base(word)] {
+		return true
+	}
+	resolved := resolveCommand(word, dir)
+	return resolved != "" && (l.paths[resolved] || l.names[filepath.Base(resolved)])
+}
+
+// check returns an error describing why command may not run, or nil if
+// every program it runs is allowed and none is denied. dir is the working
+// directory relative paths are resolved from.
+func (p *commandPolicy) check(command, dir string) error {
+	return p.checkScript(command, dir, 0)
+}
+
+func (p *commandPolicy) checkScript(script, dir string, depth int) error {
+	if depth > maxCommandDepth {
+		return fmt.Errorf("commands are nested too deeply to check")
+	}
+	segments, hasSubstitution := permission.SplitCommand(script)
+	if hasSubstitution {
+		return fmt.Errorf("command substitution can't be checked against the allowed and denied commands")
+	}
+	for _, segment := range segments {
+		if err := p.checkWords(shellWords(segment), dir, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWords checks the words of one simple command.
+func (p *commandPolicy) checkWords(words []string, dir string, depth int) error {
+	if p.allowed != nil {
+		for _, word := range words {
+			if strings.HasPrefix(word, "PATH=") {
+				return fmt.Errorf("setting PATH isn't permitted when commands are restricted to an allowlist")
+			}
+		}
+	}
+	words = skipCommandPrefix(words)
+	for len(words) > 0 {
+		// A subshell's closing parenthesis can end the command name
+		name := strings.TrimRight(words[0], ")")
+		if strings.Contains(name, "$") {
+			return fmt.Errorf("the command name %q uses a variable and can't be checked", name)
+		}
+		if p.denied != nil && p.denied.denies(name, dir) {
+			return fmt.Errorf("%s is a denied command", name)
+		}
+		if p.allowed != nil && !p.allowed.allows(name, dir) {
+			return fmt.Errorf("%s is not an allowed command", name)
+		}
+
+		base := filepath.Base(name)
+		args := words[1:]
+		switch {
+		case base == "eval":
+			return p.checkScript(strings.Join(args, " "), dir, depth+1)
+		case shells[base]:
+			script, ok, err := shellScript(base, args)
+			if err != nil || !ok {
+				return err
+			}
+			return p.checkScript(script, dir, depth+1)
+		default:
+			options, ok := commandWrappers[base]
+			if !ok {
+				return nil
+			}
+			// Check the command the wrapper runs, after its options and
+			// variable assignments
+			wrapped, err := options.command(base, args)
+			if err != nil {
+				return err
+			}
+			words = wrapped
+		}
+	}
+	return nil
+}
+
+// wrapperOptions are the options a command wrapper accepts.
+type wrapperOptions struct {
+	// flags are the short options that take no value
+	flags string
+	// values are the short options that take a value, either the rest of
+	// their cluster or the next word
+	values string
+	// long are the long options, mapped to whether they take a value
+	long map[string]bool
+}
+
+// command returns the words of the command a wrapper named name runs with
+// args, or an error if args use options the wrapper doesn't list.
+func (o wrapperOptions) command(name string, args []string) ([]string, error) {
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--":
+			return args[1:], nil
+		case envAssignment.MatchString(arg):
+			args = args[1:]
+		case strings.HasPrefix(arg, "--"):
+			option, _, hasValue := strings.Cut(arg[2:], "=")
+			takesValue, ok := o.long[option]
+			if !ok {
+				return nil, fmt.Errorf("%s option %s can't be checked", name, arg)
+			}
+			args = args[1:]
+			if takesValue && !hasValue {
+				if len(args) == 0 {
+					return nil, fmt.Errorf("%s option %s is missing its value", name, arg)
+				}
+				value := args[0]
+				args = args[1:]
+				if name == "env" && option == "split-string" {
+					args = append(shellWords(value), args...)
+				}
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			args = args[1:]
+			for i := 1; i < len(arg); i++ {
+				c := arg[i]
+				if strings.IndexByte(o.flags, c) >= 0 {
+					continue
+				}
+				if strings.IndexByte(o.values, c) < 0 {
+					return nil, fmt.Errorf("%s option -%c can't be checked", name, c)
+				}
+				value := arg[i+1:]
+				if value == "" {
+					if len(args) == 0 {
+						return nil, fmt.Errorf("%s option -%c is missing its value", name, c)
+					}
+					value, args = args[0], args[1:]
+				}
+				// env -S splits its value into the command and its
+				// first arguments
+				if name == "env" && c == 'S' {
+					args = append(shellWords(value), args...)
+				}
+				break
+			}
+		default:
+			return args, nil
+		}
+	}
+	return args, nil
+}
+
+// shellScript returns the script a shell runs with args: the first operand
+// after a cluster of short flags that contains c, such as -c, -ec, or -lc.
+// It reports false if the shell runs a script file or standard input
+// instead.
+func shellScript(name string, args []string) (string, bool, error) {
+	hasScript := false
+	i := 0
+options:
+	for ; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--" || arg == "-":
+			i++
+			break options
+		case strings.HasPrefix(arg, "--"):
+			// Long options that take their value as the next word
+			if arg == "--rcfile" || arg == "--init-file" {
+				i++
+			}
+		case (arg[0] == '-' || arg[0] == '+') && len(arg) > 1:
+			for _, c := range arg[1:] {
+				switch c {
+				case 'c':
+					hasScript = hasScript || arg[0] == '-'
+				case 'o', 'O':
+					// -o and -O name an option in the next word
+					i++
+				}
+			}
+		default:
+			break options
+		}
+	}
+	if !hasScript {
+		return "", false, nil
+	}
+	if i >= len(args) {
+		return "", false, fmt.Errorf("the script %s runs with -c can't be found", name)
+	}
+	return args[i], true, nil
+}
+
+// skipCommandPrefix drops the keywords, variable assignments, and
+// redirections before the command name of a simple command.
+func skipCommandPrefix(words []string) []string {
+	for len(words) > 0 {
+		word := words[0]
+		switch {
+		case word == "time":
+			// The time keyword takes options too; if they can't be parsed,
+			// leave it to be checked as a wrapper, which reports why
+			rest, err := commandWrappers["time"].command(word, words[1:])
+			if err != nil {
+				return words
+			}
+			words = rest
+		case shellKeywords[word], envAssignment.MatchString(word):
+			words = words[1:]
+		case strings.HasPrefix(word, "(") || strings.HasPrefix(word, "{"):
+			words[0] = strings.TrimLeft(word, "({")
+			if words[0] == "" {
+				words = words[1:]
+			}
+		case redirection.MatchString(word):
+			// A bare operator is followed by its target
+			if bareRedirection.MatchString(word) && len(words) > 1 {
+				words = words[2:]
+			} else {
+				words = words[1:]
+			}
+		default:
+			return words
+		}
+	}
+	return words
+}
+
+// shellWords splits a simple command into words on unquoted whitespace,
+// removing quotes and backslash escapes.
+func shellWords(command string) []string {
+	var words []string
+	var cur strings.Builder
+	inWord, inSingle, inDouble := false, false, false
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case inSingle:
+			if c == '\'' {
+				inSingle = false
+			} else {
+				cur.WriteByte(c)
+			}
+		case c == '\\' && i+1 < len(command):
+			i++
+			cur.WriteByte(command[i])
+			inWord = true
+		case inDouble:
+			if c == '"' {
+				inDouble = false
+			} else {
+				cur.WriteByte(c)
+			}
+		case c == '\'':
+			inSingle, inWord = true, true
+		case c == '"':
+			inDouble, inWord = true, true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words
+}
+
+// ExcludeSecretEnv is an [BashToolOptions.EnvFilter] that keeps environment
+// variables whose names suggest a credential, such as ANTHROPIC_API_KEY,
+// GH_TOKEN, or PGPASSWORD, out of commands. Detection is by name only, so
+// a secret in an innocuously named variable, such as a DATABASE_URL with a
+// password, is still passed.
+func ExcludeSecretEnv(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "CREDENTIAL", "APIKEY", "PRIVATE_KEY"} {
+		if strings.Contains(upper, marker) {
+			return false
+		}
+	}
+	for _, part := range strings.Split(upper, "_") {
+		if part == "KEY" || part == "AUTH" {
+			return false
+		}
+	}
+	return true
+}
+
+// filterEnv returns the variables of the current environment that filter
+// keeps.
+func filterEnv(filter func(name string) bool) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if filter(name) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
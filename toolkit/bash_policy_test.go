@@ -0,0 +1,269 @@
+package toolkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestCommandPolicy_Denylist(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows - bash not available")
+	}
+	rm := resolveCommand("rm", "")
+	if rm == "" {
+		t.Skip("rm not found on PATH")
+	}
+	policy := newCommandPolicy(nil, []string{"rm"})
+
+	denied := []string{
+		"rm -rf build",
+		"ls; rm -rf build",
+		"ls && rm -rf build",
+		"ls || rm -rf build",
+		"ls | rm -rf build",
+		"ls & rm -rf build",
+		"ls\nrm -rf build",
+		"echo $(rm -rf build)",
+		"echo `rm -rf build`",
+		`echo "$(rm -rf build)"`,
+		"cat <(rm -rf build)",
+		rm + " -rf build",
+		`"rm" -rf build`,
+		`r\m -rf build`,
+		"'rm' -rf build",
+		"FOO=1 rm -rf build",
+		"env FOO=1 rm -rf build",
+		"sudo -n rm -rf build",
+		"command rm -rf build",
+		"(rm -rf build)",
+		"(cd src && rm -rf build)",
+		"{ rm -rf build; }",
+		"if true; then rm -rf build; fi",
+		"> log rm -rf build",
+		`bash -c "rm -rf build"`,
+		`sh -c 'ls; rm -rf build'`,
+		"eval rm -rf build",
+		`sh -ec 'rm -rf x'`,
+		`bash -lc 'rm x'`,
+		`bash -o pipefail -c 'rm x'`,
+		`bash --norc -c 'rm x'`,
+		"sudo -u root rm x",
+		"sudo -uroot rm x",
+		"sudo --user root rm x",
+		"sudo --user=root rm x",
+		"sudo -g wheel -u root rm x",
+		"env -u HOME rm x",
+		"env -C /tmp rm x",
+		"env --unset HOME rm x",
+		"env -S 'rm -rf x'",
+		"exec -a foo rm x",
+		"exec -cl rm x",
+		"time -o out rm x",
+		"sudo --frobnicate rm x",
+		"env -Z rm x",
+		"sudo -u",
+		"bash -c",
+		"$CMD -rf build",
+		"r${x}m -rf build",
+		"ls 2>&1 | rm -rf build",
+		"ls &> log & rm -rf build",
+		">&2 rm -rf build",
+		"2>&1 rm -rf build",
+		"cat <<EOF\n$(rm -rf build)\nEOF",
+		"cat <<EOF\nhi\nEOF\nrm -rf build",
+	}
+	for _, command := range denied {
+		assert.Error(t, policy.check(command, t.TempDir()), "command %q", command)
+	}
+
+	allowed := []string{
+		"ls -la",
+		"echo rm -rf build",
+		"echo 'rm -rf build; rm'",
+		"git rm --cached file",
+		"grep -r 'a;rm' .",
+		"sudo -u root ls",
+		"env -u HOME ls",
+		"exec -a foo ls",
+		"bash script.sh",
+		"cat <<EOF\nrm -rf build\nEOF",
+		"cat <<'EOF'\n$(rm -rf build)\nEOF",
+	}
+	for _, command := range allowed {
+		assert.NoError(t, policy.check(command, t.TempDir()), "command %q", command)
+	}
+}
+
+func TestCommandPolicy_DenylistResolvedPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows - symlinks and bash not available")
+	}
+	rm := resolveCommand("rm", "")
+	if rm == "" {
+		t.Skip("rm not found on PATH")
+	}
+	dir := t.TempDir()
+	assert.NoError(t, os.Symlink(rm, filepath.Join(dir, "del")))
+
+	policy := newCommandPolicy(nil, []string{"rm"})
+	err := policy.check("./del -rf build", dir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "./del is a denied command")
+}
+
+func TestCommandPolicy_Allowlist(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows - bash not available")
+	}
+	ls := resolveCommand("ls", "")
+	if ls == "" {
+		t.Skip("ls not found on PATH")
+	}
+	dir := t.TempDir()
+	policy := newCommandPolicy([]string{"ls", "echo", "cd"}, nil)
+
+	for _, command := range []string{
+		"ls -la",
+		"cd src && ls",
+		"ls | echo done",
+		ls + " -la",
+	} {
+		assert.NoError(t, policy.check(command, dir), "command %q", command)
+	}
+
+	// A program named like an allowed one, but elsewhere, is refused
+	fake := filepath.Join(dir, "ls")
+	assert.NoError(t, os.WriteFile(fake, []byte("#!/bin/sh\n"), 0755))
+
+	for _, command := range []string{
+		"cat file",
+		"ls; cat file",
+		"ls $(cat file)",
+		"env ls",
+		"ls && env cat file",
+		"./ls",
+		fake,
+		"PATH=. ls",
+		`bash -c "ls"`,
+	} {
+		assert.Error(t, policy.check(command, dir), "command %q", command)
+	}
+
+	// Redirections and here-document bodies aren't commands
+	policy = newCommandPolicy([]string{"go", "head", "cat", "echo"}, nil)
+	for _, command := range []string{
+		"go test ./... 2>&1",
+		"go test ./... 2>&1 | head -50",
+		"go vet ./... >&2",
+		"cat <&0",
+		"go build &> build.log",
+		">&2 echo failed",
+		"cat <<EOF\nhi\nEOF",
+		"cat <<-EOF | head -1\n\tfirst line\n\tEOF\necho done",
+	} {
+		assert.NoError(t, policy.check(command, dir), "command %q", command)
+	}
+	for _, command := range []string{
+		"go test ./... 2>&1 | tail -5",
+		"cat <<EOF\nhi\nEOF\nls",
+	} {
+		assert.Error(t, policy.check(command, dir), "command %q", command)
+	}
+
+	// Scripts passed to an allowed shell are checked, however -c is given
+	policy = newCommandPolicy([]string{"ls", "bash"}, nil)
+	assert.NoError(t, policy.check(`bash -ec 'ls -la'`, dir))
+	for _, command := range []string{
+		`bash -c 'cat file'`,
+		`bash -ec 'cat file'`,
+		`bash -lc 'cat file'`,
+		`bash -e -c 'cat file'`,
+	} {
+		assert.Error(t, policy.check(command, dir), "command %q", command)
+	}
+}
+
+func TestBashTool_DeniedCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows - bash not available")
+	}
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+	assert.NoError(t, os.WriteFile(marker, nil, 0644))
+
+	tool := NewBashTool(BashToolOptions{WorkspaceDir: dir, DeniedCommands: []string{"rm"}})
+	result, err := tool.Call(context.Background(), &BashInput{
+		Command:          "echo hi; rm marker",
+		WorkingDirectory: dir,
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "command not permitted: rm is a denied command")
+	assert.Contains(t, result.Display, "Blocked")
+
+	// The command didn't run
+	_, err = os.Stat(marker)
+	assert.NoError(t, err)
+}
+
+func TestBashTool_EnvFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows - bash not available")
+	}
+	t.Setenv("DIVE_TEST_API_KEY", "sk-secret")
+	t.Setenv("DIVE_TEST_GREETING", "hello")
+
+	tool := NewBashTool(BashToolOptions{EnvFilter: ExcludeSecretEnv})
+	result, err := tool.Call(context.Background(), &BashInput{
+		Command: `echo "[$DIVE_TEST_API_KEY] [$DIVE_TEST_GREETING]"`,
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "[] [hello]")
+}
+
+func TestExcludeSecretEnv(t *testing.T) {
+	for _, name := range []string{
+		"ANTHROPIC_API_KEY", "OPENAI_API_KEY", "GH_TOKEN", "AWS_SECRET_ACCESS_KEY",
+		"AWS_SESSION_TOKEN", "PGPASSWORD", "GOOGLE_APPLICATION_CREDENTIALS",
+		"SSH_AUTH_SOCK", "STRIPE_APIKEY", "GPG_PRIVATE_KEY", "KEY",
+	} {
+		assert.False(t, ExcludeSecretEnv(name), "name %q", name)
+	}
+	for _, name := range []string{"PATH", "HOME", "PWD", "LANG", "KEYBOARD_LAYOUT", "GOPATH", "AUTHOR_NAME"} {
+		assert.True(t, ExcludeSecretEnv(name), "name %q", name)
+	}
+}
+
+func TestBashTool_ResourceLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Skipping - ulimit -v is only reliable on Linux")
+	}
+	tool := NewBashTool(BashToolOptions{MaxCPUTime: 1500 * time.Millisecond, MaxMemoryBytes: 512 << 20})
+
+	result, err := tool.Call(context.Background(), &BashInput{Command: "ulimit -t; ulimit -v"})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, `"stdout":"2\n524288\n"`)
+
+	// The command can't raise its limits, unless privileged
+	if os.Geteuid() != 0 {
+		result, err = tool.Call(context.Background(), &BashInput{Command: "ulimit -v unlimited"})
+		assert.NoError(t, err)
+		assert.True(t, result.IsError)
+	}
+
+	// Exceeding the CPU limit kills the command
+	tool = NewBashTool(BashToolOptions{MaxCPUTime: time.Second})
+	result, err = tool.Call(context.Background(), &BashInput{Command: "while :; do :; done"})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.False(t, strings.Contains(result.Content[0].Text, `"return_code":0`))
+}
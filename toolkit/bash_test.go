@@ -6,7 +6,9 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/deepnoodle-ai/dive"
 	"github.com/deepnoodle-ai/wonton/assert"
 )
 
@@ -103,6 +105,37 @@ func TestBashTool_Call_SimpleCommand(t *testing.T) {
 	assert.Contains(t, result.Content[0].Text, "return_code")
 }
 
+func TestBashTool_Call_ProgressUsesContextClock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows - bash not available")
+	}
+
+	run := func(clock dive.Clock) []*dive.ToolProgress {
+		var progress []*dive.ToolProgress
+		ctx := dive.WithClock(context.Background(), clock)
+		ctx = dive.WithToolProgressFunc(ctx, func(_ string, p *dive.ToolProgress) {
+			progress = append(progress, p)
+		})
+		result, err := NewBashTool().Call(ctx, &BashInput{Command: "echo one; sleep 0.2; echo two"})
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+		return progress
+	}
+
+	clock := dive.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.SetAutoAdvance(time.Second)
+	progress := run(clock)
+	assert.Len(t, progress, 2)
+	assert.Equal(t, int64(1000), progress[0].Metadata["elapsed_ms"])
+	assert.Equal(t, int64(2000), progress[1].Metadata["elapsed_ms"])
+
+	// A stopped clock doesn't hold back later reports
+	progress = run(dive.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Len(t, progress, 2)
+	assert.Equal(t, 2, progress[1].Metadata["lines"])
+	assert.Equal(t, int64(0), progress[1].Metadata["elapsed_ms"])
+}
+
 func TestBashTool_Call_CommandWithExitCode(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Skipping on Windows - bash not available")
@@ -198,6 +231,24 @@ func TestBashTool_Call_Timeout(t *testing.T) {
 	assert.Contains(t, result.Content[0].Text, `"return_code":-1`)
 }
 
+func TestBashTool_Call_CancelKillsSubprocesses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows - process groups not available")
+	}
+
+	tool := NewBashTool()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The background sleep inherits stdout. Unless it is killed with the
+	// shell, the call waits for it to exit.
+	start := time.Now()
+	result, err := tool.Call(ctx, &BashInput{Command: "sleep 30 & sleep 30"})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
 func TestTruncateCommand(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -0,0 +1,234 @@
+package toolkit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/fetch"
+)
+
+// DefaultFetchMaxBodySize is the default maximum response body size in bytes
+// (10 MB) read by a ContentFetcher.
+const DefaultFetchMaxBodySize = 10 * 1024 * 1024
+
+var _ fetch.Fetcher = &ContentFetcher{}
+
+// ContentFetcher is a [fetch.Fetcher] that converts responses to markdown
+// according to their content type:
+//
+//   - HTML is converted to markdown, as with [fetch.HTTPFetcher].
+//   - PDF text is extracted from its pages. Scanned PDFs have no text and
+//     return an error.
+//   - Plain text, markdown, JSON, XML, and other text/* types are returned
+//     as-is.
+//
+// Other content types, such as images and archives, return an error. With
+// Readability set, HTML pages are reduced to their main article content
+// before conversion (see [NewReadabilityFetcher]).
+//
+// ContentFetcher is the default fetcher of [FetchTool]. Browser automation
+// options (MaxAge, WaitFor, Mobile, Actions, StorageState) are unsupported.
+type ContentFetcher struct {
+	client      *http.Client
+	timeout     time.Duration
+	headers     map[string]string
+	maxBodySize int64
+	readability bool
+}
+
+// ContentFetcherOptions configures a [ContentFetcher].
+type ContentFetcherOptions struct {
+	// Client is the HTTP client used for requests. Defaults to
+	// [SafeHTTPClient], which blocks requests to private networks.
+	Client *http.Client
+
+	// Timeout is the request timeout. Defaults to [DefaultFetchTimeout].
+	Timeout time.Duration
+
+	// Headers are sent with every request. Request headers override them.
+	Headers map[string]string
+
+	// MaxBodySize is the maximum response body size in bytes. Larger
+	// responses return an error. Defaults to [DefaultFetchMaxBodySize].
+	MaxBodySize int64
+
+	// Readability extracts the main article content of HTML pages using a
+	// readability heuristic, dropping navigation, sidebars, and comments.
+	// Pages where no article is found are converted in full.
+	Readability bool
+}
+
+// NewContentFetcher creates a new ContentFetcher with the given options.
+func NewContentFetcher(opts ...ContentFetcherOptions) *ContentFetcher {
+	var options ContentFetcherOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultFetchTimeout
+	}
+	if options.Client == nil {
+		options.Client = SafeHTTPClient(options.Timeout)
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = DefaultFetchMaxBodySize
+	}
+	return &ContentFetcher{
+		client:      options.Client,
+		timeout:     options.Timeout,
+		headers:     options.Headers,
+		maxBodySize: options.MaxBodySize,
+		readability: options.Readability,
+	}
+}
+
+// NewReadabilityFetcher creates a ContentFetcher that extracts the main
+// article content of HTML pages. It suits articles, blog posts, and
+// documentation, where page chrome would otherwise dominate the markdown.
+func NewReadabilityFetcher(opts ...ContentFetcherOptions) *ContentFetcher {
+	var options ContentFetcherOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	options.Readability = true
+	return NewContentFetcher(options)
+}
+
+// Fetch retrieves req.URL and converts it according to its content type.
+func (f *ContentFetcher) Fetch(ctx context.Context, req *fetch.Request) (*fetch.Response, error) {
+	if err := validateContentFetchRequest(req); err != nil {
+		return nil, err
+	}
+	timeout := f.timeout
+	if req.Timeout > 0 {
+		timeout = time.Duration(req.Timeout) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range f.headers {
+		httpReq.Header.Set(key, value)
+	}
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, &fetch.Error{StatusCode: resp.StatusCode, URL: req.URL}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.maxBodySize {
+		return nil, fmt.Errorf("response size exceeds limit of %d bytes", f.maxBodySize)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+
+	var response *fetch.Response
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		response, err = f.processHTML(req, string(body))
+	case mediaType == "application/pdf":
+		var text string
+		text, err = extractPDFText(body)
+		response = &fetch.Response{Markdown: text}
+	case isTextMediaType(mediaType):
+		response = &fetch.Response{Markdown: string(body)}
+	default:
+		err = fmt.Errorf("unsupported content type: %s", mediaType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		if len(values) > 0 {
+			headers[name] = values[0]
+		}
+	}
+	response.URL = resp.Request.URL.String()
+	response.StatusCode = resp.StatusCode
+	response.Headers = headers
+	response.Timestamp = time.Now()
+	return response, nil
+}
+
+// processHTML converts an HTML page, reducing it to its main content first
+// when readability extraction is enabled.
+func (f *ContentFetcher) processHTML(req *fetch.Request, page string) (*fetch.Response, error) {
+	response, err := fetch.ProcessRequest(req, page)
+	if err != nil || !f.readability {
+		return response, err
+	}
+	article, ok := extractReadableHTML(page)
+	if !ok {
+		return response, nil
+	}
+	articleReq := *req
+	articleReq.OnlyMainContent = false
+	content, err := fetch.ProcessRequest(&articleReq, article)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the page metadata, which lives in <head>
+	response.HTML = content.HTML
+	response.Markdown = content.Markdown
+	response.Links = content.Links
+	response.Images = content.Images
+	return response, nil
+}
+
+func validateContentFetchRequest(req *fetch.Request) error {
+	switch {
+	case req.MaxAge > 0:
+		return fetch.ErrUnsupportedOption("MaxAge")
+	case req.WaitFor > 0:
+		return fetch.ErrUnsupportedOption("WaitFor")
+	case req.Mobile:
+		return fetch.ErrUnsupportedOption("Mobile")
+	case len(req.Actions) > 0:
+		return fetch.ErrUnsupportedOption("Actions")
+	case len(req.StorageState) > 0:
+		return fetch.ErrUnsupportedOption("StorageState")
+	}
+	for _, format := range req.Formats {
+		switch format {
+		case "screenshot", "json", "summary":
+			return fetch.ErrUnsupportedOption("format " + format)
+		}
+	}
+	return nil
+}
+
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-yaml", "application/yaml", "application/toml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
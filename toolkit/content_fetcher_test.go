@@ -0,0 +1,104 @@
+package toolkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/fetch"
+)
+
+const testArticlePage = `<html><head><title>Test Article</title></head><body>
+<div class="menu"><a href="/a">Home</a> <a href="/b">About</a> <a href="/c">Contact us today</a></div>
+<div class="article-content">
+<p>This is the first paragraph of the article, with enough text to count.</p>
+<p>The second paragraph continues the story, adding detail, commas, and length.</p>
+</div>
+<div class="sidebar"><p>Subscribe to our newsletter for weekly updates and offers.</p></div>
+</body></html>`
+
+func newContentTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(testArticlePage))
+	})
+	mux.HandleFunc("/doc.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(buildTestPDF(true, "BT (PDF body text) Tj ET"))
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestContentFetcher(t *testing.T) {
+	server := newContentTestServer(t)
+	fetcher := NewContentFetcher(ContentFetcherOptions{Client: server.Client()})
+	ctx := context.Background()
+
+	t.Run("html", func(t *testing.T) {
+		resp, err := fetcher.Fetch(ctx, &fetch.Request{URL: server.URL + "/article", Formats: []string{"markdown"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "Test Article", resp.Metadata.Title)
+		assert.Contains(t, resp.Markdown, "first paragraph")
+		assert.Contains(t, resp.Markdown, "Subscribe")
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+
+	t.Run("pdf", func(t *testing.T) {
+		resp, err := fetcher.Fetch(ctx, &fetch.Request{URL: server.URL + "/doc.pdf"})
+		assert.NoError(t, err)
+		assert.Equal(t, "PDF body text", resp.Markdown)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		resp, err := fetcher.Fetch(ctx, &fetch.Request{URL: server.URL + "/data.json"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"ok":true}`, resp.Markdown)
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		_, err := fetcher.Fetch(ctx, &fetch.Request{URL: server.URL + "/image.png"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "image/png")
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		_, err := fetcher.Fetch(ctx, &fetch.Request{URL: server.URL + "/missing"})
+		var fetchErr *fetch.Error
+		assert.True(t, errors.As(err, &fetchErr))
+		assert.Equal(t, http.StatusNotFound, fetchErr.StatusCode)
+	})
+
+	t.Run("unsupported option", func(t *testing.T) {
+		_, err := fetcher.Fetch(ctx, &fetch.Request{URL: server.URL + "/article", Mobile: true})
+		assert.True(t, errors.Is(err, fetch.ErrUnsupported))
+	})
+}
+
+func TestReadabilityFetcher(t *testing.T) {
+	server := newContentTestServer(t)
+	fetcher := NewReadabilityFetcher(ContentFetcherOptions{Client: server.Client()})
+
+	resp, err := fetcher.Fetch(context.Background(), &fetch.Request{URL: server.URL + "/article", Formats: []string{"markdown"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Article", resp.Metadata.Title)
+	assert.Contains(t, resp.Markdown, "first paragraph")
+	assert.Contains(t, resp.Markdown, "second paragraph")
+	assert.False(t, strings.Contains(resp.Markdown, "Subscribe"))
+	assert.False(t, strings.Contains(resp.Markdown, "Contact us"))
+}
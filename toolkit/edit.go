@@ -33,6 +33,9 @@ type EditInput struct {
 	// ReplaceAll enables replacing all occurrences of OldString.
 	// When false (default), OldString must be unique in the file.
 	ReplaceAll bool `json:"replace_all,omitempty"`
+
+	// DryRun returns a unified diff of the edit without writing the file.
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 // EditToolOptions configures the behavior of [EditTool].
@@ -63,15 +66,14 @@ type EditToolOptions struct {
 //   - Exact string matching (no regex interpretation)
 //   - Uniqueness validation to prevent ambiguous edits
 //   - Diff output showing the context around changes
+//   - Dry runs that return a unified diff without writing
+//   - Line endings matched to the file, so LF edits apply to CRLF files
 //   - Preserves file permissions
 //
 // Security: File paths are validated against the workspace boundary when
 // WorkspaceDir is configured.
 type EditTool struct {
-	maxFileSize   int64
-	pathValidator *PathValidator
-	workspaceDir  string
-	configErr     error
+	fileEditor
 }
 
 // NewEditTool creates a new EditTool with the given options.
@@ -81,27 +83,95 @@ func NewEditTool(opts ...EditToolOptions) *dive.TypedToolAdapter[*EditInput] {
 	if len(opts) > 0 {
 		resolvedOpts = opts[0]
 	}
-	if resolvedOpts.MaxFileSize == 0 {
-		resolvedOpts.MaxFileSize = 10 * 1024 * 1024 // 10MB
-	}
-	var pathValidator *PathValidator
-	var configErr error
-	if resolvedOpts.Validator != nil {
-		pathValidator = resolvedOpts.Validator
-	} else if resolvedOpts.WorkspaceDir != "" {
-		pathValidator, configErr = NewPathValidator(resolvedOpts.WorkspaceDir)
-		if configErr != nil {
-			configErr = fmt.Errorf("invalid workspace configuration for WorkspaceDir %q: %w", resolvedOpts.WorkspaceDir, configErr)
-		}
-	}
 	return dive.ToolAdapter(&EditTool{
-		maxFileSize:   resolvedOpts.MaxFileSize,
-		pathValidator: pathValidator,
-		workspaceDir:  resolvedOpts.WorkspaceDir,
-		configErr:     configErr,
+		fileEditor: newFileEditor(resolvedOpts.MaxFileSize, resolvedOpts.WorkspaceDir, resolvedOpts.Validator),
 	})
 }
 
+// fileEditor validates and reads the files that EditTool and MultiEditTool
+// change.
+type fileEditor struct {
+	maxFileSize   int64
+	pathValidator *PathValidator
+	workspaceDir  string
+	configErr     error
+}
+
+// newFileEditor returns a fileEditor limited to files of maxFileSize bytes,
+// or 10MB if zero, within workspaceDir unless validator is set.
+func newFileEditor(maxFileSize int64, workspaceDir string, validator *PathValidator) fileEditor {
+	if maxFileSize == 0 {
+		maxFileSize = 10 * 1024 * 1024 // 10MB
+	}
+	e := fileEditor{
+		maxFileSize:   maxFileSize,
+		pathValidator: validator,
+		workspaceDir:  workspaceDir,
+	}
+	if validator == nil && workspaceDir != "" {
+		e.pathValidator, e.configErr = NewPathValidator(workspaceDir)
+		if e.configErr != nil {
+			e.configErr = fmt.Errorf("invalid workspace configuration for WorkspaceDir %q: %w", workspaceDir, e.configErr)
+		}
+	}
+	return e
+}
+
+// readFile checks that path is an absolute path to a writable file within
+// the workspace and size limit, and returns its content and mode. If it
+// isn't, the returned error result explains why.
+func (e *fileEditor) readFile(path string) (string, os.FileMode, *dive.ToolResult) {
+	if e.configErr != nil {
+		return "", 0, dive.NewToolResultError(fmt.Sprintf("error: %s", e.configErr.Error()))
+	}
+	if e.workspaceDir != "" && e.pathValidator == nil {
+		return "", 0, dive.NewToolResultError(fmt.Sprintf("error: invalid workspace configuration for WorkspaceDir %q: path validator is not initialized", e.workspaceDir))
+	}
+
+	// Validate path
+	if !filepath.IsAbs(path) {
+		return "", 0, dive.NewToolResultError(fmt.Sprintf("file_path must be absolute, got: %s", path))
+	}
+
+	// Validate path is within workspace (skip validation if no validator configured)
+	if e.pathValidator != nil {
+		if err := e.pathValidator.ValidateWrite(path); err != nil {
+			return "", 0, dive.NewToolResultError(fmt.Sprintf("Error: %s", err.Error()))
+		}
+	}
+
+	// Open file first to avoid TOCTOU race conditions
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", 0, dive.NewToolResultError(fmt.Sprintf("File does not exist: %s", path))
+		}
+		return "", 0, dive.NewToolResultError(fmt.Sprintf("Error accessing file: %v", err))
+	}
+	defer file.Close()
+
+	// Stat the open file handle to avoid TOCTOU issues
+	info, err := file.Stat()
+	if err != nil {
+		return "", 0, dive.NewToolResultError(fmt.Sprintf("Error getting file info: %v", err))
+	}
+
+	if info.IsDir() {
+		return "", 0, dive.NewToolResultError(fmt.Sprintf("Path is a directory, not a file: %s", path))
+	}
+
+	if info.Size() > e.maxFileSize {
+		return "", 0, dive.NewToolResultError(fmt.Sprintf("File too large: %d bytes (max %d bytes)", info.Size(), e.maxFileSize))
+	}
+
+	// Read file from the already-open handle
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return "", 0, dive.NewToolResultError(fmt.Sprintf("Error reading file: %v", err))
+	}
+	return string(content), info.Mode(), nil
+}
+
 // Name returns "Edit" as the tool identifier.
 func (t *EditTool) Name() string {
 	return "Edit"
@@ -120,7 +190,8 @@ Requirements:
 - The file must exist
 
 Use replace_all: true when you want to replace all occurrences, such as
-renaming a variable throughout a file.
+renaming a variable throughout a file. Use dry_run: true to see the unified
+diff an edit would make without changing the file.
 
 Examples:
 - Fix a typo: {"file_path": "/path/to/file.go", "old_string": "teh", "new_string": "the"}
@@ -150,6 +221,10 @@ func (t *EditTool) Schema() *schema.Schema {
 				Type:        "boolean",
 				Description: "Replace all occurrences of old_string (default: false). Use for renaming variables.",
 			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the unified diff of the edit without writing the file (default: false)",
+			},
 		},
 	}
 }
@@ -175,6 +250,9 @@ func (t *EditTool) PreviewCall(ctx context.Context, input *EditInput) *dive.Tool
 	if input.ReplaceAll {
 		action = "Replace all"
 	}
+	if input.DryRun {
+		action = "Preview: " + action
+	}
 
 	// Truncate strings for preview
 	oldStr := input.OldString
@@ -196,90 +274,44 @@ func (t *EditTool) PreviewCall(ctx context.Context, input *EditInput) *dive.Tool
 //  3. Checks file exists and is within size limits
 //  4. Verifies old_string appears exactly once (unless replace_all is true)
 //
-// On success, returns the replacement count and a diff showing context
-// around the changes.
+// If old_string isn't found in a file with CRLF line endings, the edit is
+// retried with the line endings of old_string and new_string converted to
+// CRLF. On success, returns the replacement count and a diff showing
+// context around the changes. With dry_run, the file is left unchanged and
+// the result is a unified diff of the edit.
 func (t *EditTool) Call(ctx context.Context, input *EditInput) (*dive.ToolResult, error) {
-	if t.configErr != nil {
-		return dive.NewToolResultError(fmt.Sprintf("error: %s", t.configErr.Error())), nil
-	}
-	if t.workspaceDir != "" && t.pathValidator == nil {
-		return dive.NewToolResultError(fmt.Sprintf("error: invalid workspace configuration for WorkspaceDir %q: path validator is not initialized", t.workspaceDir)), nil
-	}
-
 	// Validate inputs
 	if input.OldString == input.NewString {
 		return dive.NewToolResultError("old_string and new_string must be different"), nil
 	}
 
-	// Validate path
-	if !filepath.IsAbs(input.FilePath) {
-		return dive.NewToolResultError(fmt.Sprintf("file_path must be absolute, got: %s", input.FilePath)), nil
-	}
-
-	// Validate path is within workspace (skip validation if no validator configured)
-	if t.pathValidator != nil {
-		if err := t.pathValidator.ValidateWrite(input.FilePath); err != nil {
-			return dive.NewToolResultError(fmt.Sprintf("Error: %s", err.Error())), nil
-		}
-	}
-
-	// Open file first to avoid TOCTOU race conditions
-	file, err := os.Open(input.FilePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return dive.NewToolResultError(fmt.Sprintf("File does not exist: %s", input.FilePath)), nil
-		}
-		return dive.NewToolResultError(fmt.Sprintf("Error accessing file: %v", err)), nil
-	}
-	defer file.Close()
-
-	// Stat the open file handle to avoid TOCTOU issues
-	info, err := file.Stat()
-	if err != nil {
-		return dive.NewToolResultError(fmt.Sprintf("Error getting file info: %v", err)), nil
-	}
-
-	if info.IsDir() {
-		return dive.NewToolResultError(fmt.Sprintf("Path is a directory, not a file: %s", input.FilePath)), nil
-	}
-
-	if info.Size() > t.maxFileSize {
-		return dive.NewToolResultError(fmt.Sprintf("File too large: %d bytes (max %d bytes)", info.Size(), t.maxFileSize)), nil
-	}
-
-	// Read file from the already-open handle
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return dive.NewToolResultError(fmt.Sprintf("Error reading file: %v", err)), nil
+	contentStr, mode, errResult := t.readFile(input.FilePath)
+	if errResult != nil {
+		return errResult, nil
 	}
 
-	contentStr := string(content)
-
 	// Count occurrences
-	count := strings.Count(contentStr, input.OldString)
+	oldString, newString := input.OldString, input.NewString
+	count := strings.Count(contentStr, oldString)
+	if count == 0 {
+		oldString, newString = matchLineEndings(contentStr, oldString, newString)
+		count = strings.Count(contentStr, oldString)
+	}
 
 	if count == 0 {
 		return dive.NewToolResultError(fmt.Sprintf("old_string not found in file: %q", truncateForError(input.OldString, 50))), nil
 	}
 
 	if count > 1 && !input.ReplaceAll {
-		// Find line numbers of occurrences
-		lines := strings.Split(contentStr, "\n")
-		var lineNumbers []int
-		for i, line := range lines {
-			if strings.Contains(line, input.OldString) {
-				lineNumbers = append(lineNumbers, i+1)
-			}
-		}
 		return dive.NewToolResultError(fmt.Sprintf(
 			"old_string appears %d times (lines %v). Use replace_all: true to replace all, or provide a more specific string.",
-			count, lineNumbers,
+			count, occurrenceLines(contentStr, oldString),
 		)), nil
 	}
 
 	// Find line number where old_string starts (for diff context)
 	lineNum := 1
-	idx := strings.Index(contentStr, input.OldString)
+	idx := strings.Index(contentStr, oldString)
 	if idx >= 0 {
 		lineNum = strings.Count(contentStr[:idx], "\n") + 1
 	}
@@ -287,18 +319,26 @@ func (t *EditTool) Call(ctx context.Context, input *EditInput) (*dive.ToolResult
 	// Perform replacement
 	var newContent string
 	if input.ReplaceAll {
-		newContent = strings.ReplaceAll(contentStr, input.OldString, input.NewString)
+		newContent = strings.ReplaceAll(contentStr, oldString, newString)
 	} else {
-		newContent = strings.Replace(contentStr, input.OldString, input.NewString, 1)
+		newContent = strings.Replace(contentStr, oldString, newString, 1)
+	}
+
+	if input.DryRun {
+		diff := unifiedDiff(input.FilePath, contentStr, newContent)
+		return dive.NewToolResultText(fmt.Sprintf(
+			"Dry run: would replace %d occurrence(s) in %s. The file was not changed.\n\n%s",
+			count, input.FilePath, diff,
+		)).WithDisplay(diff), nil
 	}
 
 	// Write file back
-	if err := os.WriteFile(input.FilePath, []byte(newContent), info.Mode()); err != nil {
+	if err := os.WriteFile(input.FilePath, []byte(newContent), mode); err != nil {
 		return dive.NewToolResultError(fmt.Sprintf("Error writing file: %v", err)), nil
 	}
 
 	// Generate diff for display
-	diff := t.generateDiff(contentStr, newContent, input.OldString, input.NewString, lineNum)
+	diff := t.generateDiff(contentStr, newContent, oldString, newString, lineNum)
 
 	var resultMsg string
 	if input.ReplaceAll {
@@ -313,6 +353,40 @@ func (t *EditTool) Call(ctx context.Context, input *EditInput) (*dive.ToolResult
 	return dive.NewToolResultText(resultMsg).WithDisplay(diff), nil
 }
 
+// matchLineEndings converts the LF line endings of oldString and newString
+// to CRLF when content uses CRLF and oldString has only LF, so an edit
+// written with LF applies to a CRLF file. Otherwise it returns them as is.
+func matchLineEndings(content, oldString, newString string) (string, string) {
+	if !strings.Contains(content, "\r\n") || !strings.Contains(oldString, "\n") ||
+		strings.Contains(oldString, "\r\n") {
+		return oldString, newString
+	}
+	toCRLF := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+	}
+	return toCRLF(oldString), toCRLF(newString)
+}
+
+// occurrenceLines returns the line number on which each non-overlapping
+// occurrence of s in content starts.
+func occurrenceLines(content, s string) []int {
+	if s == "" {
+		return nil
+	}
+	var lines []int
+	line, offset := 1, 0
+	for {
+		idx := strings.Index(content[offset:], s)
+		if idx < 0 {
+			return lines
+		}
+		line += strings.Count(content[offset:offset+idx], "\n")
+		lines = append(lines, line)
+		line += strings.Count(s, "\n")
+		offset += idx + len(s)
+	}
+}
+
 // Diff generation limits
 const (
 	maxDiffLines     = 50  // Maximum lines to show in diff output
@@ -2,8 +2,10 @@ package toolkit
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/deepnoodle-ai/wonton/assert"
@@ -306,3 +308,170 @@ func TestEditTool_LargeFile(t *testing.T) {
 	assert.True(t, result.IsError)
 	assert.Contains(t, result.Content[0].Text, "too large")
 }
+
+func TestEditTool_MultipleOccurrencesReportsLines(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+	original := "if err != nil {\n\treturn err\n}\nx++\nif err != nil {\n\treturn err\n}\n"
+	err := os.WriteFile(testFile, []byte(original), 0644)
+	assert.NoError(t, err)
+
+	tool := NewEditTool(EditToolOptions{WorkspaceDir: tempDir})
+
+	result, err := tool.Call(context.Background(), &EditInput{
+		FilePath:  testFile,
+		OldString: "if err != nil {\n\treturn err\n}",
+		NewString: "if err != nil {\n\treturn fmt.Errorf(\"wrap: %w\", err)\n}",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "appears 2 times (lines [1 5])")
+
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, original, string(content))
+}
+
+func TestEditTool_DryRun(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	err := os.WriteFile(testFile, []byte(original), 0644)
+	assert.NoError(t, err)
+
+	tool := NewEditTool(EditToolOptions{WorkspaceDir: tempDir})
+
+	result, err := tool.Call(context.Background(), &EditInput{
+		FilePath:  testFile,
+		OldString: "five\n",
+		NewString: "FIVE\n5\n",
+		DryRun:    true,
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	want := "--- " + testFile + "\n+++ " + testFile + "\n" +
+		"@@ -2,7 +2,8 @@\n" +
+		" two\n three\n four\n-five\n+FIVE\n+5\n six\n seven\n eight\n"
+	assert.Equal(t, want, result.Display)
+	assert.Contains(t, result.Content[0].Text, "would replace 1 occurrence(s)")
+
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, original, string(content))
+}
+
+func TestEditTool_CRLF(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	err := os.WriteFile(testFile, []byte("alpha\r\nbeta\r\ngamma\r\n"), 0644)
+	assert.NoError(t, err)
+
+	tool := NewEditTool(EditToolOptions{WorkspaceDir: tempDir})
+
+	// An LF edit applies to a CRLF file and keeps its line endings
+	result, err := tool.Call(context.Background(), &EditInput{
+		FilePath:  testFile,
+		OldString: "alpha\nbeta\n",
+		NewString: "alpha\nBETA\nbeta2\n",
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "alpha\r\nBETA\r\nbeta2\r\ngamma\r\n", string(content))
+
+	// A CRLF edit still matches exactly
+	result, err = tool.Call(context.Background(), &EditInput{
+		FilePath:  testFile,
+		OldString: "gamma\r\n",
+		NewString: "delta\r\n",
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	content, err = os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "alpha\r\nBETA\r\nbeta2\r\ndelta\r\n", string(content))
+}
+
+func TestEditTool_LFFileIgnoresCRLFEdit(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	err := os.WriteFile(testFile, []byte("alpha\nbeta\n"), 0644)
+	assert.NoError(t, err)
+
+	tool := NewEditTool(EditToolOptions{WorkspaceDir: tempDir})
+
+	result, err := tool.Call(context.Background(), &EditInput{
+		FilePath:  testFile,
+		OldString: "alpha\r\nbeta",
+		NewString: "beta",
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "not found")
+}
+
+func TestEditTool_TrailingNewline(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	err := os.WriteFile(testFile, []byte("first\nlast"), 0644)
+	assert.NoError(t, err)
+
+	tool := NewEditTool(EditToolOptions{WorkspaceDir: tempDir})
+
+	// Editing the last line keeps the missing trailing newline
+	result, err := tool.Call(context.Background(), &EditInput{
+		FilePath:  testFile,
+		OldString: "last",
+		NewString: "final",
+		DryRun:    true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "--- "+testFile+"\n+++ "+testFile+"\n"+
+		"@@ -1,2 +1,2 @@\n first\n-last\n\\ No newline at end of file\n+final\n\\ No newline at end of file\n",
+		result.Display)
+
+	result, err = tool.Call(context.Background(), &EditInput{
+		FilePath:  testFile,
+		OldString: "last",
+		NewString: "final",
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "first\nfinal", string(content))
+
+	// Adding a trailing newline shows in the diff
+	result, err = tool.Call(context.Background(), &EditInput{
+		FilePath:  testFile,
+		OldString: "final",
+		NewString: "final\n",
+		DryRun:    true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "--- "+testFile+"\n+++ "+testFile+"\n"+
+		"@@ -1,2 +1,2 @@\n first\n-final\n\\ No newline at end of file\n+final\n",
+		result.Display)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Equal(t, "", unifiedDiff("f", "same\n", "same\n"))
+
+	// Distant changes get separate hunks
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d\n", i))
+	}
+	oldText := strings.Join(lines, "")
+	newText := strings.Replace(strings.Replace(oldText, "line 2\n", "", 1), "line 18\n", "line 18\nnew\n", 1)
+	assert.Equal(t, "--- f\n+++ f\n"+
+		"@@ -1,5 +1,4 @@\n line 1\n-line 2\n line 3\n line 4\n line 5\n"+
+		"@@ -16,5 +15,6 @@\n line 16\n line 17\n line 18\n+new\n line 19\n line 20\n",
+		unifiedDiff("f", oldText, newText))
+
+	// Creating a file from nothing
+	assert.Equal(t, "--- f\n+++ f\n@@ -0,0 +1 @@\n+hello\n", unifiedDiff("f", "", "hello\n"))
+}
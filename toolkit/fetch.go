@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/wonton/fetch"
 	"github.com/deepnoodle-ai/wonton/retry"
 	"github.com/deepnoodle-ai/wonton/schema"
@@ -52,7 +53,9 @@ var _ dive.TypedToolPreviewer[*fetch.Request] = &FetchTool{}
 //
 // This tool is useful for giving LLMs access to web content. It converts
 // HTML to clean markdown, strips non-content elements (scripts, styles,
-// navigation), and optionally extracts only the main content area.
+// navigation), and optionally extracts only the main content area. With the
+// default fetcher, PDFs are converted to text and other text formats are
+// returned as-is.
 //
 // Security: The tool validates URLs to prevent SSRF attacks. It blocks:
 //   - Non-HTTP(S) schemes (file://, javascript://, etc.)
@@ -88,9 +91,10 @@ type FetchToolOptions struct {
 	// ignoring sidebars, headers, and footers.
 	OnlyMainContent bool `json:"only_main_content,omitempty"`
 
-	// Fetcher is the underlying HTTP fetcher implementation.
-	// If not provided, a default HTTPFetcher with SSRF-safe redirect
-	// validation is used.
+	// Fetcher retrieves and converts pages. Any [fetch.Fetcher] works, such
+	// as a [ContentFetcher], a [NewReadabilityFetcher], or the firecrawl
+	// client, optionally wrapped in a [CachingFetcher]. If not provided, a
+	// [ContentFetcher] using [SafeHTTPClient] is used.
 	Fetcher fetch.Fetcher `json:"-"`
 }
 
@@ -153,8 +157,8 @@ func SafeHTTPClient(timeout time.Duration) *http.Client {
 
 // NewFetchTool creates a new FetchTool with the given options.
 //
-// If no Fetcher is provided, a default HTTPFetcher with SSRF-safe redirect
-// validation is used.
+// If no Fetcher is provided, a [ContentFetcher] using [SafeHTTPClient] is
+// used, which handles HTML, PDF, and text responses.
 func NewFetchTool(opts ...FetchToolOptions) *dive.TypedToolAdapter[*fetch.Request] {
 	var options FetchToolOptions
 	if len(opts) > 0 {
@@ -167,8 +171,7 @@ func NewFetchTool(opts ...FetchToolOptions) *dive.TypedToolAdapter[*fetch.Reques
 		options.Timeout = DefaultFetchTimeout
 	}
 	if options.Fetcher == nil {
-		options.Fetcher = fetch.NewHTTPFetcher(fetch.HTTPFetcherOptions{
-			Client:  SafeHTTPClient(options.Timeout),
+		options.Fetcher = NewContentFetcher(ContentFetcherOptions{
 			Timeout: options.Timeout,
 		})
 	}
@@ -275,7 +278,13 @@ func (t *FetchTool) Call(ctx context.Context, req *fetch.Request) (*dive.ToolRes
 	}
 	display = fmt.Sprintf("%s - %d chars", display, contentLen)
 
-	return NewToolResultText(content).WithDisplay(display), nil
+	source := &llm.CitationContent{
+		Type:    string(llm.CitationTypeContent),
+		Title:   response.Metadata.Title,
+		URL:     req.URL,
+		Snippet: response.Metadata.Description,
+	}
+	return NewToolResultText(content).WithDisplay(display).WithSources(source), nil
 }
 
 // Annotations returns metadata hints about the tool's behavior.
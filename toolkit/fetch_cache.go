@@ -0,0 +1,138 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/fetch"
+)
+
+const (
+	// DefaultFetchCacheTTL is how long a CachingFetcher keeps a response.
+	DefaultFetchCacheTTL = 15 * time.Minute
+
+	// DefaultFetchCacheMaxEntries is the default number of responses a
+	// CachingFetcher keeps.
+	DefaultFetchCacheMaxEntries = 100
+)
+
+var _ fetch.Fetcher = &CachingFetcher{}
+
+// CachingFetcher wraps a [fetch.Fetcher] and reuses its responses for
+// repeated requests, so an agent that reads the same page several times in
+// a run fetches it once. Requests are keyed on all their options, not just
+// the URL. Errors are never cached.
+//
+// Callers must not modify returned responses, which are shared. A
+// CachingFetcher is safe for concurrent use.
+type CachingFetcher struct {
+	fetcher    fetch.Fetcher
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*fetchCacheEntry
+}
+
+type fetchCacheEntry struct {
+	response *fetch.Response
+	expires  time.Time
+}
+
+// CachingFetcherOptions configures a [CachingFetcher].
+type CachingFetcherOptions struct {
+	// TTL is how long a response is reused. Defaults to
+	// [DefaultFetchCacheTTL].
+	TTL time.Duration
+
+	// MaxEntries limits the number of cached responses. When full, the
+	// response closest to expiring is evicted. Defaults to
+	// [DefaultFetchCacheMaxEntries].
+	MaxEntries int
+}
+
+// NewCachingFetcher creates a CachingFetcher around fetcher.
+func NewCachingFetcher(fetcher fetch.Fetcher, opts ...CachingFetcherOptions) *CachingFetcher {
+	var options CachingFetcherOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.TTL <= 0 {
+		options.TTL = DefaultFetchCacheTTL
+	}
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = DefaultFetchCacheMaxEntries
+	}
+	return &CachingFetcher{
+		fetcher:    fetcher,
+		ttl:        options.TTL,
+		maxEntries: options.MaxEntries,
+		entries:    make(map[string]*fetchCacheEntry),
+	}
+}
+
+// Fetch returns a cached response for req if one hasn't expired, and
+// otherwise fetches and caches it.
+func (f *CachingFetcher) Fetch(ctx context.Context, req *fetch.Request) (*fetch.Response, error) {
+	key, err := json.Marshal(req)
+	if err != nil {
+		return f.fetcher.Fetch(ctx, req)
+	}
+	now := time.Now()
+
+	f.mu.Lock()
+	entry, ok := f.entries[string(key)]
+	if ok && now.Before(entry.expires) {
+		f.mu.Unlock()
+		return entry.response, nil
+	}
+	f.mu.Unlock()
+
+	response, err := f.fetcher.Fetch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries[string(key)] = &fetchCacheEntry{response: response, expires: now.Add(f.ttl)}
+	f.evict(now)
+	return response, nil
+}
+
+// Clear removes all cached responses.
+func (f *CachingFetcher) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.entries)
+}
+
+// Len returns the number of cached responses, including expired ones not
+// yet evicted.
+func (f *CachingFetcher) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.entries)
+}
+
+// evict removes expired entries, then the entries closest to expiring until
+// the cache is within its size limit. The caller must hold f.mu.
+func (f *CachingFetcher) evict(now time.Time) {
+	for key, entry := range f.entries {
+		if !now.Before(entry.expires) {
+			delete(f.entries, key)
+		}
+	}
+	for len(f.entries) > f.maxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for key, entry := range f.entries {
+			if oldestKey == "" || entry.expires.Before(oldest) {
+				oldestKey, oldest = key, entry.expires
+			}
+		}
+		delete(f.entries, oldestKey)
+	}
+}
@@ -0,0 +1,76 @@
+package toolkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/fetch"
+)
+
+type countingFetcher struct {
+	calls int
+	err   error
+}
+
+func (f *countingFetcher) Fetch(ctx context.Context, req *fetch.Request) (*fetch.Response, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &fetch.Response{URL: req.URL, Markdown: "content of " + req.URL}, nil
+}
+
+func TestCachingFetcher(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reuses responses per request", func(t *testing.T) {
+		inner := &countingFetcher{}
+		fetcher := NewCachingFetcher(inner)
+
+		for range 3 {
+			resp, err := fetcher.Fetch(ctx, &fetch.Request{URL: "https://example.com/a"})
+			assert.NoError(t, err)
+			assert.Equal(t, "content of https://example.com/a", resp.Markdown)
+		}
+		assert.Equal(t, 1, inner.calls)
+
+		// Different options are a different request
+		_, err := fetcher.Fetch(ctx, &fetch.Request{URL: "https://example.com/a", OnlyMainContent: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, inner.calls)
+
+		fetcher.Clear()
+		_, err = fetcher.Fetch(ctx, &fetch.Request{URL: "https://example.com/a"})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, inner.calls)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		inner := &countingFetcher{err: errors.New("boom")}
+		fetcher := NewCachingFetcher(inner)
+		for range 2 {
+			_, err := fetcher.Fetch(ctx, &fetch.Request{URL: "https://example.com/a"})
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 2, inner.calls)
+		assert.Equal(t, 0, fetcher.Len())
+	})
+
+	t.Run("expires and evicts entries", func(t *testing.T) {
+		inner := &countingFetcher{}
+		fetcher := NewCachingFetcher(inner, CachingFetcherOptions{TTL: time.Millisecond, MaxEntries: 2})
+		for _, url := range []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"} {
+			_, err := fetcher.Fetch(ctx, &fetch.Request{URL: url})
+			assert.NoError(t, err)
+		}
+		assert.True(t, fetcher.Len() <= 2)
+
+		time.Sleep(5 * time.Millisecond)
+		_, err := fetcher.Fetch(ctx, &fetch.Request{URL: "https://example.com/3"})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, inner.calls)
+	})
+}
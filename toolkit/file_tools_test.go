@@ -511,6 +511,17 @@ func TestReadFileTool_Annotations(t *testing.T) {
 	assert.True(t, annotations.IdempotentHint)
 }
 
+func TestReadFileTool_CachePolicy(t *testing.T) {
+	tool := NewReadFileTool(ReadFileToolOptions{MaxSize: 10000})
+
+	policy := tool.CachePolicy([]byte(`{"file_path":"/path/to/file.txt"}`))
+	assert.False(t, policy.Disabled)
+	assert.Equal(t, []string{filepath.Clean("/path/to/file.txt")}, policy.Files)
+
+	policy = tool.CachePolicy([]byte(`not json`))
+	assert.True(t, policy.Disabled)
+}
+
 func TestWriteFileTool_OverwriteExisting(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "write_overwrite_test")
 	assert.NoError(t, err)
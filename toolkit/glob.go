@@ -191,6 +191,12 @@ func (t *GlobTool) PreviewCall(ctx context.Context, input *GlobInput) *dive.Tool
 	}
 }
 
+// CachePolicy opts out of dive.ToolCache: matches change as files are
+// created and deleted.
+func (t *GlobTool) CachePolicy(input *GlobInput) dive.ToolCachePolicy {
+	return dive.ToolCachePolicy{Disabled: true}
+}
+
 // Call searches for files matching the glob pattern.
 //
 // Returns matching file paths as newline-separated text, sorted by
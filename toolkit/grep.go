@@ -111,6 +111,76 @@ type GrepToolOptions struct {
 	// Validator is an optional shared PathValidator. When set, it is used
 	// instead of creating one from WorkspaceDir.
 	Validator *PathValidator
+
+	// OutputMode is the output mode used when a call doesn't choose one.
+	// Defaults to GrepOutputFilesWithMatches.
+	OutputMode GrepOutputMode
+
+	// Structured returns results as a JSON-encoded [GrepResult] instead of
+	// text, for callers that parse the output. Matches in content mode
+	// carry their line number, column, and context lines.
+	Structured bool
+}
+
+// GrepResult is the result of a search when [GrepToolOptions.Structured]
+// is set. Only the list for Mode is present: Files for
+// GrepOutputFilesWithMatches, Counts for GrepOutputCount, and Matches for
+// GrepOutputContent. Offset and head_limit apply to that list.
+type GrepResult struct {
+	// Mode is the output mode of the search.
+	Mode GrepOutputMode `json:"mode"`
+
+	// TotalMatches is the number of matches found, before pagination.
+	TotalMatches int `json:"total_matches"`
+
+	// Truncated reports that the search stopped at the tool's MaxResults.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Files are the paths of files with matches, sorted.
+	Files []string `json:"files,omitempty"`
+
+	// Counts are the match counts per file, sorted by path.
+	Counts []GrepFileCount `json:"counts,omitempty"`
+
+	// Matches are the matches, sorted by path and then line.
+	Matches []GrepMatch `json:"matches,omitempty"`
+}
+
+// GrepFileCount is the number of matches in a file.
+type GrepFileCount struct {
+	File  string `json:"file"`
+	Count int    `json:"count"`
+}
+
+// GrepMatch is a single match found during a search.
+type GrepMatch struct {
+	// File is the path of the file, relative to the search path.
+	File string `json:"file"`
+
+	// Line is the 1-based number of the line where the match starts.
+	Line int `json:"line"`
+
+	// Column is the 1-based byte offset of the match within Line.
+	Column int `json:"column"`
+
+	// Text is the matching line, without its line ending. A multiline
+	// match spanning several lines includes all of them, joined by "\n".
+	Text string `json:"text"`
+
+	// Before and After are the context lines requested with -B, -A, or -C.
+	Before []GrepLine `json:"before,omitempty"`
+	After  []GrepLine `json:"after,omitempty"`
+}
+
+// GrepLine is a numbered line of a file.
+type GrepLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// endLine returns the number of the last line of the match.
+func (m GrepMatch) endLine() int {
+	return m.Line + strings.Count(m.Text, "\n")
 }
 
 // GrepTool searches file contents using regular expressions.
@@ -136,6 +206,8 @@ type GrepTool struct {
 	pathValidator   *PathValidator
 	workspaceDir    string
 	configErr       error
+	outputMode      GrepOutputMode
+	structured      bool
 }
 
 // NewGrepTool creates a new GrepTool with the given options.
@@ -187,6 +259,8 @@ func NewGrepTool(opts ...GrepToolOptions) *dive.TypedToolAdapter[*GrepInput] {
 		pathValidator:   pathValidator,
 		workspaceDir:    resolvedOpts.WorkspaceDir,
 		configErr:       configErr,
+		outputMode:      resolvedOpts.OutputMode,
+		structured:      resolvedOpts.Structured,
 	})
 }
 
@@ -247,7 +321,7 @@ func (t *GrepTool) Schema() *schema.Schema {
 			"output_mode": {
 				Type:        "string",
 				Enum:        []any{"content", "files_with_matches", "count"},
-				Description: "Output mode: \"content\" shows matching lines, \"files_with_matches\" shows file paths (default), \"count\" shows match counts",
+				Description: fmt.Sprintf("Output mode: \"content\" shows matching lines, \"files_with_matches\" shows file paths, \"count\" shows match counts. Defaults to %q.", t.defaultOutputMode()),
 			},
 			"-i": {
 				Type:        "boolean",
@@ -285,6 +359,13 @@ func (t *GrepTool) Schema() *schema.Schema {
 	}
 }
 
+func (t *GrepTool) defaultOutputMode() GrepOutputMode {
+	if t.outputMode != "" {
+		return t.outputMode
+	}
+	return GrepOutputFilesWithMatches
+}
+
 // Annotations returns metadata hints about the tool's behavior.
 // Grep is marked as read-only and idempotent.
 func (t *GrepTool) Annotations() *dive.ToolAnnotations {
@@ -314,6 +395,12 @@ func (t *GrepTool) PreviewCall(ctx context.Context, input *GrepInput) *dive.Tool
 	}
 }
 
+// CachePolicy opts out of dive.ToolCache: matches depend on file contents
+// the cache can't track.
+func (t *GrepTool) CachePolicy(input *GrepInput) dive.ToolCachePolicy {
+	return dive.ToolCachePolicy{Disabled: true}
+}
+
 // Call searches for the pattern and returns matches in the requested format.
 //
 // The search is performed using ripgrep if available and enabled, otherwise
@@ -402,7 +489,7 @@ func (t *GrepTool) callRipgrep(ctx context.Context, input *GrepInput) (*dive.Too
 		if exitError, ok := err.(*exec.ExitError); ok {
 			// Exit code 1 means no matches, which is fine
 			if exitError.ExitCode() == 1 {
-				return t.formatNoMatches(input), nil
+				return t.formatResults(nil, input)
 			}
 		}
 		return dive.NewToolResultError(fmt.Sprintf("ripgrep error: %v\n%s", err, stderr.String())), nil
@@ -423,37 +510,68 @@ type ripgrepMatch struct {
 			Text string `json:"text"`
 		} `json:"lines"`
 		LineNumber int `json:"line_number"`
+		Submatches []struct {
+			Start int `json:"start"`
+		} `json:"submatches"`
 	} `json:"data"`
 }
 
 func (t *GrepTool) parseRipgrepOutput(output, basePath string, input *GrepInput) (*dive.ToolResult, error) {
-	var matches []grepMatch
+	var matches []GrepMatch
+	// Lines seen per file, from both match and context events, to attach
+	// context lines to matches once all events are read.
+	fileLines := map[string]map[int]string{}
 	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
 	for scanner.Scan() {
 		var m ripgrepMatch
 		if err := json.Unmarshal([]byte(scanner.Text()), &m); err != nil {
 			continue
 		}
-		if m.Type != "match" {
+		if m.Type != "match" && m.Type != "context" {
 			continue
 		}
 		relPath, _ := filepath.Rel(basePath, m.Data.Path.Text)
 		if relPath == "" {
 			relPath = m.Data.Path.Text
 		}
-		matches = append(matches, grepMatch{
-			file:       relPath,
-			lineNumber: m.Data.LineNumber,
-			line:       strings.TrimRight(m.Data.Lines.Text, "\n\r"),
-		})
-
-		// Collect up to maxResults; offset/head_limit pagination is applied
-		// in formatResults so both search paths paginate identically.
-		if len(matches) >= t.maxResults {
-			break
+		lines := fileLines[relPath]
+		if lines == nil {
+			lines = map[int]string{}
+			fileLines[relPath] = lines
 		}
+		text := trimLines(strings.TrimRight(m.Data.Lines.Text, "\n\r"))
+		for i, line := range strings.Split(text, "\n") {
+			lines[m.Data.LineNumber+i] = line
+		}
+		if m.Type != "match" {
+			continue
+		}
+		column := 1
+		if len(m.Data.Submatches) > 0 {
+			column = m.Data.Submatches[0].Start + 1
+		}
+		matches = append(matches, GrepMatch{
+			File:   relPath,
+			Line:   m.Data.LineNumber,
+			Column: column,
+			Text:   text,
+		})
 	}
 
+	// Collect up to maxResults; offset/head_limit pagination is applied in
+	// formatResults so both search paths paginate identically.
+	if len(matches) > t.maxResults {
+		matches = matches[:t.maxResults]
+	}
+	before, after := contextLines(input)
+	for i := range matches {
+		lines := fileLines[matches[i].File]
+		addContext(&matches[i], before, after, func(n int) (string, bool) {
+			line, ok := lines[n]
+			return line, ok
+		})
+	}
 	return t.formatResults(matches, input)
 }
 
@@ -539,7 +657,8 @@ func (t *GrepTool) callPureGo(ctx context.Context, input *GrepInput) (*dive.Tool
 		}
 	}
 
-	var matches []grepMatch
+	var matches []GrepMatch
+	before, after := contextLines(input)
 
 	err = filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -601,21 +720,37 @@ func (t *GrepTool) callPureGo(ctx context.Context, input *GrepInput) (*dive.Tool
 		}
 
 		lines := strings.Split(string(content), "\n")
-		for i, line := range lines {
-			if re.MatchString(line) {
-				matches = append(matches, grepMatch{
-					file:       relPath,
-					lineNumber: i + 1,
-					line:       strings.TrimRight(line, "\r"),
-				})
-				// Collect up to maxResults; offset/head_limit pagination is
-				// applied in formatResults so both search paths paginate
-				// identically.
-				if len(matches) >= t.maxResults {
-					return filepath.SkipAll
+		lineAt := func(n int) (string, bool) {
+			if n < 1 || n > len(lines) {
+				return "", false
+			}
+			return strings.TrimRight(lines[n-1], "\r"), true
+		}
+		var fileMatches []GrepMatch
+		if input.Multiline {
+			fileMatches = multilineMatches(re, string(content), lines)
+		} else {
+			for i, line := range lines {
+				if loc := re.FindStringIndex(line); loc != nil {
+					fileMatches = append(fileMatches, GrepMatch{
+						Line:   i + 1,
+						Column: loc[0] + 1,
+						Text:   strings.TrimRight(line, "\r"),
+					})
 				}
 			}
 		}
+		for _, m := range fileMatches {
+			m.File = relPath
+			addContext(&m, before, after, lineAt)
+			matches = append(matches, m)
+			// Collect up to maxResults; offset/head_limit pagination is
+			// applied in formatResults so both search paths paginate
+			// identically.
+			if len(matches) >= t.maxResults {
+				return filepath.SkipAll
+			}
+		}
 
 		return nil
 	})
@@ -627,11 +762,76 @@ func (t *GrepTool) callPureGo(ctx context.Context, input *GrepInput) (*dive.Tool
 	return t.formatResults(matches, input)
 }
 
-// grepMatch represents a single match found during a search.
-type grepMatch struct {
-	file       string // Relative path to the file containing the match
-	lineNumber int    // 1-based line number of the match
-	line       string // Content of the matching line
+// multilineMatches finds the matches of re in content, which is split into
+// lines. Like ripgrep, a match is reported once with all the lines it
+// spans, and a match starting on a line an earlier match covers is merged
+// into it.
+func multilineMatches(re *regexp.Regexp, content string, lines []string) []GrepMatch {
+	// lineStarts[i] is the byte offset of line i+1 in content
+	lineStarts := make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		lineStarts[i] = offset
+		offset += len(line) + 1
+	}
+	lineOf := func(pos int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > pos })
+	}
+
+	var matches []GrepMatch
+	lastEnd := 0
+	for _, loc := range re.FindAllStringIndex(content, -1) {
+		start := lineOf(loc[0])
+		if start <= lastEnd {
+			continue
+		}
+		end := start
+		if loc[1] > loc[0] {
+			end = lineOf(loc[1] - 1)
+		}
+		matches = append(matches, GrepMatch{
+			Line:   start,
+			Column: loc[0] - lineStarts[start-1] + 1,
+			Text:   trimLines(strings.Join(lines[start-1:end], "\n")),
+		})
+		lastEnd = end
+	}
+	return matches
+}
+
+// trimLines removes the carriage returns ending the lines of text.
+func trimLines(text string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(text, "\r"), "\r\n", "\n")
+}
+
+// contextLines returns the number of context lines to show before and after
+// each match. -B and -A override -C.
+func contextLines(input *GrepInput) (before, after int) {
+	before, after = input.Context, input.Context
+	if input.Before > 0 {
+		before = input.Before
+	}
+	if input.After > 0 {
+		after = input.After
+	}
+	return max(before, 0), max(after, 0)
+}
+
+// addContext sets the context lines of m, reading lines with lineAt.
+func addContext(m *GrepMatch, before, after int, lineAt func(n int) (string, bool)) {
+	for n := max(m.Line-before, 1); n < m.Line; n++ {
+		if text, ok := lineAt(n); ok {
+			m.Before = append(m.Before, GrepLine{Line: n, Text: text})
+		}
+	}
+	end := m.endLine()
+	for n := end + 1; n <= end+after; n++ {
+		text, ok := lineAt(n)
+		if !ok {
+			break
+		}
+		m.After = append(m.After, GrepLine{Line: n, Text: text})
+	}
 }
 
 // paginate applies offset and limit to a slice of entries.
@@ -654,14 +854,14 @@ func paginate[T any](entries []T, offset, limit int) []T {
 // Pagination (offset + head_limit) is applied here, after the full result set
 // is grouped into entries, so both the ripgrep and pure-Go paths paginate
 // identically.
-func (t *GrepTool) formatResults(matches []grepMatch, input *GrepInput) (*dive.ToolResult, error) {
-	if len(matches) == 0 {
+func (t *GrepTool) formatResults(matches []GrepMatch, input *GrepInput) (*dive.ToolResult, error) {
+	if len(matches) == 0 && !t.structured {
 		return t.formatNoMatches(input), nil
 	}
 
 	outputMode := input.OutputMode
 	if outputMode == "" {
-		outputMode = GrepOutputFilesWithMatches
+		outputMode = t.defaultOutputMode()
 	}
 
 	offset := input.Offset
@@ -675,6 +875,11 @@ func (t *GrepTool) formatResults(matches []grepMatch, input *GrepInput) (*dive.T
 		showLines = *input.ShowLines
 	}
 
+	grepResult := &GrepResult{
+		Mode:         outputMode,
+		TotalMatches: len(matches),
+		Truncated:    len(matches) >= t.maxResults,
+	}
 	var shown int
 	var result strings.Builder
 
@@ -684,14 +889,15 @@ func (t *GrepTool) formatResults(matches []grepMatch, input *GrepInput) (*dive.T
 		seen := make(map[string]bool)
 		var files []string
 		for _, m := range matches {
-			if !seen[m.file] {
-				seen[m.file] = true
-				files = append(files, m.file)
+			if !seen[m.File] {
+				seen[m.File] = true
+				files = append(files, m.File)
 			}
 		}
 		sort.Strings(files)
 		files = paginate(files, offset, limit)
 		shown = len(files)
+		grepResult.Files = files
 		for _, f := range files {
 			result.WriteString(f)
 			result.WriteString("\n")
@@ -701,7 +907,7 @@ func (t *GrepTool) formatResults(matches []grepMatch, input *GrepInput) (*dive.T
 		// Count by file
 		counts := make(map[string]int)
 		for _, m := range matches {
-			counts[m.file]++
+			counts[m.File]++
 		}
 		var files []string
 		for f := range counts {
@@ -711,52 +917,102 @@ func (t *GrepTool) formatResults(matches []grepMatch, input *GrepInput) (*dive.T
 		files = paginate(files, offset, limit)
 		shown = len(files)
 		for _, f := range files {
+			grepResult.Counts = append(grepResult.Counts, GrepFileCount{File: f, Count: counts[f]})
 			result.WriteString(fmt.Sprintf("%s:%d\n", f, counts[f]))
 		}
 
 	case GrepOutputContent:
 		// Paginate matches, then group by file
+		before, after := contextLines(input)
+		separate := before > 0 || after > 0
 		paged := paginate(matches, offset, limit)
 		shown = len(paged)
-		byFile := make(map[string][]grepMatch)
+		byFile := make(map[string][]GrepMatch)
 		var files []string
 		for _, m := range paged {
-			if _, ok := byFile[m.file]; !ok {
-				files = append(files, m.file)
+			if _, ok := byFile[m.File]; !ok {
+				files = append(files, m.File)
 			}
-			byFile[m.file] = append(byFile[m.file], m)
+			byFile[m.File] = append(byFile[m.File], m)
 		}
 		sort.Strings(files)
 
 		for _, f := range files {
+			grepResult.Matches = append(grepResult.Matches, byFile[f]...)
 			result.WriteString(fmt.Sprintf("## %s\n", f))
-			for _, m := range byFile[f] {
-				if showLines {
-					result.WriteString(fmt.Sprintf("%d: %s\n", m.lineNumber, m.line))
-				} else {
-					result.WriteString(m.line)
-					result.WriteString("\n")
-				}
-			}
+			writeFileMatches(&result, byFile[f], showLines, separate)
 			result.WriteString("\n")
 		}
 	}
 
+	display := fmt.Sprintf("Found %d match(es) for %q", len(matches), input.Pattern)
+	if len(matches) == 0 {
+		display = fmt.Sprintf("No matches found for %q", input.Pattern)
+	} else if shown == 0 {
+		display = fmt.Sprintf("No results for %q at offset %d", input.Pattern, offset)
+	} else {
+		if len(matches) >= t.maxResults {
+			display += fmt.Sprintf(" (limited to %d)", t.maxResults)
+		}
+		if offset > 0 || limit > 0 {
+			display += fmt.Sprintf(" (showing %d)", shown)
+		}
+	}
+
+	if t.structured {
+		data, err := json.Marshal(grepResult)
+		if err != nil {
+			return nil, err
+		}
+		return dive.NewToolResultText(string(data)).WithDisplay(display), nil
+	}
 	if shown == 0 {
 		text := fmt.Sprintf("No results at offset %d (%d total match(es))", offset, len(matches))
-		display := fmt.Sprintf("No results for %q at offset %d", input.Pattern, offset)
 		return dive.NewToolResultText(text).WithDisplay(display), nil
 	}
+	return dive.NewToolResultText(strings.TrimSpace(result.String())).WithDisplay(display), nil
+}
 
-	display := fmt.Sprintf("Found %d match(es) for %q", len(matches), input.Pattern)
-	if len(matches) >= t.maxResults {
-		display += fmt.Sprintf(" (limited to %d)", t.maxResults)
+// writeFileMatches writes the matches of one file, in line order, with
+// their context. As in ripgrep, match lines are marked "N:" and context
+// lines "N-", and context shared by nearby matches is written once. When
+// separate is set, "--" separates groups of lines that aren't adjacent.
+func writeFileMatches(w *strings.Builder, matches []GrepMatch, showLines, separate bool) {
+	writeLine := func(n int, sep, text string) {
+		if showLines {
+			fmt.Fprintf(w, "%d%s %s\n", n, sep, text)
+		} else {
+			w.WriteString(text)
+			w.WriteString("\n")
+		}
 	}
-	if offset > 0 || limit > 0 {
-		display += fmt.Sprintf(" (showing %d)", shown)
+	written := 0
+	for i, m := range matches {
+		for _, line := range m.Before {
+			if line.Line <= written {
+				continue
+			}
+			if separate && written > 0 && line.Line > written+1 {
+				w.WriteString("--\n")
+			}
+			writeLine(line.Line, "-", line.Text)
+			written = line.Line
+		}
+		if separate && written > 0 && m.Line > written+1 {
+			w.WriteString("--\n")
+		}
+		for j, text := range strings.Split(m.Text, "\n") {
+			writeLine(m.Line+j, ":", text)
+		}
+		written = m.endLine()
+		for _, line := range m.After {
+			if i+1 < len(matches) && line.Line >= matches[i+1].Line {
+				break
+			}
+			writeLine(line.Line, "-", line.Text)
+			written = line.Line
+		}
 	}
-
-	return dive.NewToolResultText(strings.TrimSpace(result.String())).WithDisplay(display), nil
 }
 
 // formatNoMatches returns a result indicating no matches were found.
@@ -2,6 +2,7 @@ package toolkit
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -587,3 +588,146 @@ func TestGrepTool_Ripgrep_PaginationAndShowLines(t *testing.T) {
 	assert.Contains(t, result.Content[0].Text, "match c")
 	assert.NotContains(t, result.Content[0].Text, "3: match c")
 }
+
+func TestGrepTool_ContextLines(t *testing.T) {
+	tempDir := t.TempDir()
+
+	content := "one\ntwo\nmatch three\nfour\nfive\nsix\nseven\nmatch eight\nmatch nine\nten"
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte(content), 0644))
+
+	tool := NewGrepTool(GrepToolOptions{WorkspaceDir: tempDir})
+
+	result, err := tool.Call(context.Background(), &GrepInput{
+		Pattern:    "match",
+		Path:       tempDir,
+		OutputMode: GrepOutputContent,
+		Before:     1,
+		After:      1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "## file.txt\n"+
+		"2- two\n3: match three\n4- four\n"+
+		"--\n"+
+		"7- seven\n8: match eight\n9: match nine\n10- ten", result.Content[0].Text)
+
+	// -B and -A override -C
+	result, err = tool.Call(context.Background(), &GrepInput{
+		Pattern:    "three",
+		Path:       tempDir,
+		OutputMode: GrepOutputContent,
+		Context:    2,
+		After:      1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "## file.txt\n1- one\n2- two\n3: match three\n4- four", result.Content[0].Text)
+}
+
+func TestGrepTool_Multiline(t *testing.T) {
+	tempDir := t.TempDir()
+
+	content := "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "main.go"), []byte(content), 0644))
+
+	tool := NewGrepTool(GrepToolOptions{WorkspaceDir: tempDir, Structured: true})
+
+	result, err := tool.Call(context.Background(), &GrepInput{
+		Pattern:    `main\(\) \{.*?\}`,
+		Path:       tempDir,
+		OutputMode: GrepOutputContent,
+		Multiline:  true,
+	})
+	assert.NoError(t, err)
+
+	var grepResult GrepResult
+	assert.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &grepResult))
+	assert.Len(t, grepResult.Matches, 1)
+	match := grepResult.Matches[0]
+	assert.Equal(t, 3, match.Line)
+	assert.Equal(t, 6, match.Column)
+	assert.Equal(t, "func main() {\n\tprintln(\"hi\")\n}", match.Text)
+
+	// Without multiline, a pattern can't span lines
+	result, err = tool.Call(context.Background(), &GrepInput{
+		Pattern:    `main\(\) \{.*?\}`,
+		Path:       tempDir,
+		OutputMode: GrepOutputContent,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &grepResult))
+	assert.Equal(t, 0, grepResult.TotalMatches)
+}
+
+func TestGrepTool_Structured(t *testing.T) {
+	tempDir := t.TempDir()
+
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("x foo\nbar\nfoo foo"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "b.txt"), []byte("nothing\nfoo"), 0644))
+
+	tool := NewGrepTool(GrepToolOptions{WorkspaceDir: tempDir, Structured: true})
+
+	call := func(input *GrepInput) string {
+		input.Pattern = "foo"
+		input.Path = tempDir
+		result, err := tool.Call(context.Background(), input)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+		return result.Content[0].Text
+	}
+
+	assert.Equal(t, `{"mode":"content","total_matches":3,"matches":[`+
+		`{"file":"a.txt","line":1,"column":3,"text":"x foo","after":[{"line":2,"text":"bar"}]},`+
+		`{"file":"a.txt","line":3,"column":1,"text":"foo foo","before":[{"line":2,"text":"bar"}]},`+
+		`{"file":"b.txt","line":2,"column":1,"text":"foo","before":[{"line":1,"text":"nothing"}]}]}`,
+		call(&GrepInput{OutputMode: GrepOutputContent, Context: 1}))
+
+	assert.Equal(t, `{"mode":"count","total_matches":3,"counts":[{"file":"a.txt","count":2},{"file":"b.txt","count":1}]}`,
+		call(&GrepInput{OutputMode: GrepOutputCount}))
+
+	assert.Equal(t, `{"mode":"files_with_matches","total_matches":3,"files":["b.txt"]}`,
+		call(&GrepInput{Offset: 1}))
+
+	result, err := tool.Call(context.Background(), &GrepInput{Pattern: "missing", Path: tempDir})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"mode":"files_with_matches","total_matches":0}`, result.Content[0].Text)
+}
+
+func TestGrepTool_DefaultOutputMode(t *testing.T) {
+	tempDir := t.TempDir()
+
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("foo\nfoo"), 0644))
+
+	tool := NewGrepTool(GrepToolOptions{WorkspaceDir: tempDir, OutputMode: GrepOutputCount})
+
+	result, err := tool.Call(context.Background(), &GrepInput{Pattern: "foo", Path: tempDir})
+	assert.NoError(t, err)
+	assert.Equal(t, "test.txt:2", result.Content[0].Text)
+
+	// The call's output mode wins
+	result, err = tool.Call(context.Background(), &GrepInput{Pattern: "foo", Path: tempDir, OutputMode: GrepOutputFilesWithMatches})
+	assert.NoError(t, err)
+	assert.Equal(t, "test.txt", result.Content[0].Text)
+}
+
+func TestGrepTool_ParseRipgrepContext(t *testing.T) {
+	tool := &GrepTool{maxResults: 1000, structured: true}
+	output := strings.Join([]string{
+		`{"type":"begin","data":{"path":{"text":"/src/a.go"}}}`,
+		`{"type":"context","data":{"path":{"text":"/src/a.go"},"lines":{"text":"package a\n"},"line_number":1,"submatches":[]}}`,
+		`{"type":"match","data":{"path":{"text":"/src/a.go"},"lines":{"text":"func A() {\n\treturn\n"},"line_number":2,"submatches":[{"match":{"text":"A() {\n\tret"},"start":5,"end":15}]}}`,
+		`{"type":"context","data":{"path":{"text":"/src/a.go"},"lines":{"text":"}\n"},"line_number":4,"submatches":[]}}`,
+		`{"type":"end","data":{"path":{"text":"/src/a.go"}}}`,
+	}, "\n")
+
+	result, err := tool.parseRipgrepOutput(output, "/src", &GrepInput{Pattern: "A", OutputMode: GrepOutputContent, Context: 1, Multiline: true})
+	assert.NoError(t, err)
+	var grepResult GrepResult
+	assert.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &grepResult))
+	assert.Equal(t, []GrepMatch{{
+		File:   "a.go",
+		Line:   2,
+		Column: 6,
+		Text:   "func A() {\n\treturn",
+		Before: []GrepLine{{Line: 1, Text: "package a"}},
+		After:  []GrepLine{{Line: 4, Text: "}"}},
+	}}, grepResult.Matches)
+}
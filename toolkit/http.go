@@ -0,0 +1,512 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+const (
+	// DefaultHTTPTimeout is the default timeout of an HTTPTool request.
+	DefaultHTTPTimeout = 30 * time.Second
+
+	// DefaultHTTPMaxBodySize is the default maximum response body size in
+	// bytes (1 MB) returned by an HTTPTool. Longer bodies are truncated.
+	DefaultHTTPMaxBodySize = 1024 * 1024
+)
+
+// DefaultHTTPRedactedHeaders are the headers whose values [HTTPTool]
+// replaces with [DefaultRedactionText] in results by default.
+var DefaultHTTPRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// httpMethods are the methods HTTPTool accepts.
+var httpMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+var (
+	_ dive.TypedTool[*HTTPInput]          = &HTTPTool{}
+	_ dive.TypedToolPreviewer[*HTTPInput] = &HTTPTool{}
+)
+
+// HTTPInput represents the input parameters for the HTTPRequest tool.
+type HTTPInput struct {
+	// Method is the HTTP method. Defaults to GET.
+	Method string `json:"method,omitempty"`
+
+	// URL is the http or https URL to request. Required.
+	URL string `json:"url"`
+
+	// Headers are the request headers.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the request body, typically JSON.
+	Body string `json:"body,omitempty"`
+}
+
+// HTTPResponse is the result of an HTTPRequest tool call, returned to the
+// LLM as JSON.
+type HTTPResponse struct {
+	// Status is the HTTP status code.
+	Status int `json:"status"`
+
+	// Headers are the response headers, with multiple values joined by
+	// ", " and sensitive values redacted.
+	Headers map[string]string `json:"headers"`
+
+	// Body is the response body when it is valid UTF-8.
+	Body string `json:"body,omitempty"`
+
+	// BodyBase64 is the base64-encoded response body when it isn't valid
+	// UTF-8.
+	BodyBase64 string `json:"body_base64,omitempty"`
+
+	// Truncated reports that the body was cut off at the tool's
+	// MaxBodySize.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// HTTPToolOptions configures the behavior of [HTTPTool].
+type HTTPToolOptions struct {
+	// AllowedHosts restricts requests, including redirects, to these hosts.
+	// Entries are hostnames such as "api.github.com", wildcards such as
+	// "*.example.com" that match any subdomain, or either with a port, such
+	// as "localhost:8080", which then matches only that port. When empty,
+	// requests may go to any public host.
+	//
+	// Localhost and private, link-local, and cloud metadata addresses are
+	// blocked unless a hostname or IP address is listed exactly, without a
+	// wildcard; listing one allows requests to it whatever it resolves to.
+	AllowedHosts []string
+
+	// Timeout is the request timeout. Defaults to [DefaultHTTPTimeout].
+	Timeout time.Duration
+
+	// MaxBodySize is the maximum response body size in bytes. Longer bodies
+	// are truncated. Defaults to [DefaultHTTPMaxBodySize].
+	MaxBodySize int64
+
+	// Headers are sent with every request, such as credentials the LLM
+	// shouldn't see. Headers given in a call override them. Headers
+	// require AllowedHosts: without it, the tool refuses every request
+	// rather than send them to whatever host the LLM picks.
+	Headers map[string]string
+
+	// HostHeaders are sent only with requests, including redirects, to
+	// hosts matching their key, which takes the same forms as an
+	// AllowedHosts entry. Use them to scope each credential to the host
+	// it's for. Headers given in a call override them.
+	HostHeaders map[string]map[string]string
+
+	// RedactHeaders are response headers whose values are replaced with
+	// [DefaultRedactionText] in results. Defaults to
+	// [DefaultHTTPRedactedHeaders].
+	RedactHeaders []string
+}
+
+// HTTPTool sends HTTP requests and returns the status, headers, and body of
+// the response.
+//
+// Unlike [FetchTool], which converts web pages to readable markdown,
+// HTTPTool is meant for calling APIs: it supports any common method,
+// request headers and bodies, and returns the raw response.
+//
+// Security: requests are restricted to AllowedHosts, when set, and requests
+// to localhost and private networks are blocked unless explicitly allowed.
+// As with [SafeHTTPClient], addresses are checked when connecting, so DNS
+// rebinding can't bypass the policy, and every redirect is checked too.
+type HTTPTool struct {
+	client        *http.Client
+	hosts         []hostPattern
+	maxBodySize   int64
+	headers       map[string]string
+	hostHeaders   []hostHeaders
+	redactHeaders []string
+}
+
+// hostHeaders are the headers of an HTTPToolOptions.HostHeaders entry.
+type hostHeaders struct {
+	pattern hostPattern
+	headers map[string]string
+}
+
+// hostPattern is a parsed entry of HTTPToolOptions.AllowedHosts.
+type hostPattern struct {
+	host     string // lowercase hostname, without the "*." of a wildcard
+	port     string // empty to match any port
+	wildcard bool
+}
+
+// NewHTTPTool creates a new HTTPTool with the given options.
+func NewHTTPTool(opts ...HTTPToolOptions) *dive.TypedToolAdapter[*HTTPInput] {
+	var options HTTPToolOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultHTTPTimeout
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = DefaultHTTPMaxBodySize
+	}
+	if options.RedactHeaders == nil {
+		options.RedactHeaders = DefaultHTTPRedactedHeaders
+	}
+	tool := &HTTPTool{
+		maxBodySize: options.MaxBodySize,
+		headers:     options.Headers,
+	}
+	for _, entry := range options.AllowedHosts {
+		tool.hosts = append(tool.hosts, parseHostPattern(entry))
+	}
+	for entry, headers := range options.HostHeaders {
+		tool.hostHeaders = append(tool.hostHeaders, hostHeaders{pattern: parseHostPattern(entry), headers: headers})
+	}
+	for _, name := range options.RedactHeaders {
+		tool.redactHeaders = append(tool.redactHeaders, http.CanonicalHeaderKey(name))
+	}
+	tool.client = tool.newClient(options.Timeout)
+	return dive.ToolAdapter(tool)
+}
+
+func parseHostPattern(entry string) hostPattern {
+	var pattern hostPattern
+	host := strings.ToLower(strings.TrimSpace(entry))
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		host, pattern.port = h, port
+	}
+	if rest, ok := strings.CutPrefix(host, "*."); ok {
+		host, pattern.wildcard = rest, true
+	}
+	pattern.host = strings.Trim(host, "[]")
+	return pattern
+}
+
+func (p hostPattern) matches(host, port string) bool {
+	if p.port != "" && p.port != port {
+		return false
+	}
+	if p.wildcard {
+		return strings.HasSuffix(host, "."+p.host)
+	}
+	return host == p.host
+}
+
+// newClient returns a client that checks every connection and redirect
+// against the tool's hosts, like SafeHTTPClient, except that connections to
+// explicitly allowed hosts may reach private addresses.
+func (t *HTTPTool) newClient(timeout time.Duration) *http.Client {
+	safeDialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   validateConnAddress,
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(address)
+			if err == nil && t.explicitlyAllowed(strings.ToLower(host), port) {
+				return dialer.DialContext(ctx, network, address)
+			}
+			return safeDialer.DialContext(ctx, network, address)
+		},
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &headerTransport{tool: t, base: transport},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return t.validateURL(req.URL)
+		},
+	}
+}
+
+// headerTransport adds the tool's configured headers to each request,
+// including redirects, for the host the request goes to. The client copies
+// a request's headers to its redirects, so setting them on the request
+// instead would send a host's credentials to wherever it redirects.
+type headerTransport struct {
+	tool *HTTPTool
+	base http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host, port := urlHostPort(req.URL)
+	var headers []map[string]string
+	if len(t.tool.headers) > 0 {
+		headers = append(headers, t.tool.headers)
+	}
+	for _, entry := range t.tool.hostHeaders {
+		if entry.pattern.matches(host, port) {
+			headers = append(headers, entry.headers)
+		}
+	}
+	if len(headers) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper mustn't modify the request it's given
+	req = req.Clone(req.Context())
+	for _, set := range headers {
+		for key, value := range set {
+			// Headers given in the call take precedence
+			if req.Header.Get(key) == "" {
+				req.Header.Set(key, value)
+			}
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// urlHostPort returns the lowercase hostname of u and its port, defaulting
+// to the scheme's.
+func urlHostPort(u *url.URL) (string, string) {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if strings.EqualFold(u.Scheme, "https") {
+			port = "443"
+		}
+	}
+	return strings.ToLower(u.Hostname()), port
+}
+
+// explicitlyAllowed reports whether an AllowedHosts entry names host
+// exactly, permitting requests to it even at a private address.
+func (t *HTTPTool) explicitlyAllowed(host, port string) bool {
+	for _, pattern := range t.hosts {
+		if !pattern.wildcard && pattern.matches(host, port) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateURL checks u against the allowed hosts and, unless its host is
+// explicitly allowed, the SSRF rules of validateFetchURL.
+func (t *HTTPTool) validateURL(u *url.URL) error {
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return fmt.Errorf("invalid URL scheme %q: only http and https are allowed", u.Scheme)
+	}
+	host, port := urlHostPort(u)
+	if host == "" {
+		return fmt.Errorf("URL must include a hostname")
+	}
+	if t.explicitlyAllowed(host, port) {
+		return nil
+	}
+	if len(t.hosts) > 0 && !slices.ContainsFunc(t.hosts, func(p hostPattern) bool { return p.matches(host, port) }) {
+		return fmt.Errorf("host %q is not in the allowed hosts", u.Host)
+	}
+	return validateFetchURL(u.String())
+}
+
+// Name returns "HTTPRequest" as the tool identifier.
+func (t *HTTPTool) Name() string {
+	return "HTTPRequest"
+}
+
+// Description returns usage instructions for the LLM.
+func (t *HTTPTool) Description() string {
+	desc := `Send an HTTP request, such as a call to a JSON API, and return the response status, headers, and body.
+
+Use WebFetch instead to read web pages; this tool returns raw responses.`
+	if len(t.hosts) > 0 {
+		var hosts []string
+		for _, pattern := range t.hosts {
+			host := pattern.host
+			if pattern.wildcard {
+				host = "*." + host
+			}
+			if pattern.port != "" {
+				host = net.JoinHostPort(host, pattern.port)
+			}
+			hosts = append(hosts, host)
+		}
+		desc += fmt.Sprintf("\n\nRequests are limited to these hosts: %s.", strings.Join(hosts, ", "))
+	}
+	return desc
+}
+
+// Schema returns the JSON schema describing the tool's input parameters.
+func (t *HTTPTool) Schema() *schema.Schema {
+	methods := make([]any, len(httpMethods))
+	for i, method := range httpMethods {
+		methods[i] = method
+	}
+	return &schema.Schema{
+		Type:     "object",
+		Required: []string{"url"},
+		Properties: map[string]*schema.Property{
+			"method": {
+				Type:        "string",
+				Enum:        methods,
+				Description: "The HTTP method. Defaults to GET.",
+			},
+			"url": {
+				Type:        "string",
+				Description: "The http or https URL to request",
+			},
+			"headers": {
+				Type:        "object",
+				Description: "Request headers, e.g. {\"Content-Type\": \"application/json\"}",
+				AdditionalPropertiesSchema: &schema.Property{
+					Type: "string",
+				},
+			},
+			"body": {
+				Type:        "string",
+				Description: "The request body",
+			},
+		},
+	}
+}
+
+// Annotations returns metadata hints about the tool's behavior.
+// HTTPRequest can change remote state, so it is neither read-only nor
+// idempotent.
+func (t *HTTPTool) Annotations() *dive.ToolAnnotations {
+	return &dive.ToolAnnotations{
+		Title:           "HTTPRequest",
+		ReadOnlyHint:    false,
+		DestructiveHint: true,
+		IdempotentHint:  false,
+		OpenWorldHint:   true,
+	}
+}
+
+// PreviewCall returns a summary of the request for permission prompts.
+func (t *HTTPTool) PreviewCall(ctx context.Context, input *HTTPInput) *dive.ToolCallPreview {
+	return &dive.ToolCallPreview{
+		Summary: fmt.Sprintf("%s %s", httpMethod(input), input.URL),
+	}
+}
+
+// Call sends the request and returns the response as a JSON [HTTPResponse].
+// A response with a status of 400 or above is returned as an error result,
+// so the LLM sees the error body.
+func (t *HTTPTool) Call(ctx context.Context, input *HTTPInput) (*dive.ToolResult, error) {
+	if len(t.headers) > 0 && len(t.hosts) == 0 {
+		return NewToolResultError("the HTTP tool is misconfigured: Headers requires AllowedHosts"), nil
+	}
+	method := httpMethod(input)
+	if !slices.Contains(httpMethods, method) {
+		return NewToolResultError(fmt.Sprintf("unsupported method %q", input.Method)), nil
+	}
+	u, err := url.Parse(input.URL)
+	if err != nil {
+		return NewToolResultError(fmt.Sprintf("invalid URL: %s", err)), nil
+	}
+	if err := t.validateURL(u); err != nil {
+		return NewToolResultError(fmt.Sprintf("URL validation failed: %s", err)), nil
+	}
+
+	var body io.Reader
+	if input.Body != "" {
+		body = strings.NewReader(input.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return NewToolResultError(fmt.Sprintf("invalid request: %s", err)), nil
+	}
+	for key, value := range input.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return NewToolResultError(fmt.Sprintf("request failed: %s", err)), nil
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBodySize+1))
+	if err != nil {
+		return NewToolResultError(fmt.Sprintf("failed to read response body: %s", err)), nil
+	}
+	result := &HTTPResponse{
+		Status:  resp.StatusCode,
+		Headers: make(map[string]string, len(resp.Header)),
+	}
+	if int64(len(data)) > t.maxBodySize {
+		data = trimPartialRune(data[:t.maxBodySize])
+		result.Truncated = true
+	}
+	if utf8.Valid(data) {
+		result.Body = string(data)
+	} else {
+		result.BodyBase64 = base64.StdEncoding.EncodeToString(data)
+	}
+	for name, values := range resp.Header {
+		if slices.Contains(t.redactHeaders, name) {
+			result.Headers[name] = DefaultRedactionText
+		} else {
+			result.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	display := fmt.Sprintf("%s %s (%d, %s)", method, truncateCommand(input.URL, 60), resp.StatusCode, humanizeBytes(len(data)))
+	if resp.StatusCode >= 400 {
+		return NewToolResultError(string(resultJSON)).WithDisplay(display), nil
+	}
+	return NewToolResultText(string(resultJSON)).WithDisplay(display), nil
+}
+
+// trimPartialRune drops a UTF-8 character cut off at the end of data, so
+// truncating a text body leaves it valid.
+func trimPartialRune(data []byte) []byte {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return data[:i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+func httpMethod(input *HTTPInput) string {
+	if input.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(input.Method)
+}
@@ -0,0 +1,248 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// callHTTP calls tool and decodes its result.
+func callHTTP(t *testing.T, tool *HTTPTool, input *HTTPInput) (*HTTPResponse, bool) {
+	t.Helper()
+	result, err := tool.Call(context.Background(), input)
+	assert.NoError(t, err)
+	var response HTTPResponse
+	assert.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &response), result.Content[0].Text)
+	return &response, result.IsError
+}
+
+func serverHost(t *testing.T, server *httptest.Server) string {
+	u, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	return u.Host
+}
+
+func TestHTTPTool_Request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Header().Set("X-Request-Id", "req_1")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{
+			"method": r.Method,
+			"body":   string(body),
+			"auth":   r.Header.Get("Authorization"),
+			"accept": r.Header.Get("Accept"),
+		})
+	}))
+	defer server.Close()
+
+	tool := NewHTTPTool(HTTPToolOptions{
+		AllowedHosts: []string{"127.0.0.1"},
+		Headers:      map[string]string{"Authorization": "Bearer secret", "Accept": "text/plain"},
+	}).Unwrap().(*HTTPTool)
+
+	response, isError := callHTTP(t, tool, &HTTPInput{
+		Method:  "post",
+		URL:     server.URL + "/items",
+		Headers: map[string]string{"Accept": "application/json"},
+		Body:    `{"name":"widget"}`,
+	})
+	assert.False(t, isError)
+	assert.Equal(t, http.StatusCreated, response.Status)
+	assert.Equal(t, "req_1", response.Headers["X-Request-Id"])
+	assert.Equal(t, DefaultRedactionText, response.Headers["Set-Cookie"])
+
+	var echo map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(response.Body), &echo))
+	assert.Equal(t, map[string]string{
+		"method": "POST",
+		"body":   `{"name":"widget"}`,
+		"auth":   "Bearer secret",
+		"accept": "application/json",
+	}, echo)
+}
+
+func TestHTTPTool_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tool := NewHTTPTool(HTTPToolOptions{AllowedHosts: []string{"127.0.0.1"}}).Unwrap().(*HTTPTool)
+	response, isError := callHTTP(t, tool, &HTTPInput{URL: server.URL})
+	assert.True(t, isError)
+	assert.Equal(t, http.StatusNotFound, response.Status)
+	assert.Contains(t, response.Body, "not found")
+}
+
+func TestHTTPTool_TruncatesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789abcdef"))
+	}))
+	defer server.Close()
+
+	tool := NewHTTPTool(HTTPToolOptions{AllowedHosts: []string{"127.0.0.1"}, MaxBodySize: 10}).Unwrap().(*HTTPTool)
+	response, _ := callHTTP(t, tool, &HTTPInput{URL: server.URL})
+	assert.Equal(t, "0123456789", response.Body)
+	assert.True(t, response.Truncated)
+}
+
+func TestHTTPTool_TruncatesMultibyteBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("price: 10€ each"))
+	}))
+	defer server.Close()
+
+	// The limit falls inside the three bytes of €
+	tool := NewHTTPTool(HTTPToolOptions{AllowedHosts: []string{"127.0.0.1"}, MaxBodySize: 10}).Unwrap().(*HTTPTool)
+	response, _ := callHTTP(t, tool, &HTTPInput{URL: server.URL})
+	assert.Equal(t, "price: 10", response.Body)
+	assert.Equal(t, "", response.BodyBase64)
+	assert.True(t, response.Truncated)
+
+	// A binary body is still base64 encoded
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0xff, 0xfe, 0x00, 0x01})
+	}))
+	defer server.Close()
+	response, _ = callHTTP(t, tool, &HTTPInput{URL: server.URL})
+	assert.Equal(t, "//4AAQ==", response.BodyBase64)
+}
+
+func TestHTTPTool_HostHeaders(t *testing.T) {
+	var other http.Header
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		other = r.Header.Clone()
+	}))
+	defer otherServer.Close()
+	var api http.Header
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api = r.Header.Clone()
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, otherServer.URL, http.StatusFound)
+		}
+	}))
+	defer apiServer.Close()
+
+	tool := NewHTTPTool(HTTPToolOptions{
+		AllowedHosts: []string{"127.0.0.1"},
+		HostHeaders: map[string]map[string]string{
+			serverHost(t, apiServer): {"X-Api-Key": "secret"},
+		},
+	}).Unwrap().(*HTTPTool)
+
+	_, isError := callHTTP(t, tool, &HTTPInput{URL: apiServer.URL})
+	assert.False(t, isError)
+	assert.Equal(t, "secret", api.Get("X-Api-Key"))
+
+	// A host the headers aren't for gets none of them, directly or through
+	// a redirect
+	_, isError = callHTTP(t, tool, &HTTPInput{URL: otherServer.URL})
+	assert.False(t, isError)
+	assert.Equal(t, "", other.Get("X-Api-Key"))
+
+	other = nil
+	_, isError = callHTTP(t, tool, &HTTPInput{URL: apiServer.URL + "/redirect"})
+	assert.False(t, isError)
+	assert.Equal(t, "secret", api.Get("X-Api-Key"))
+	assert.NotNil(t, other)
+	assert.Equal(t, "", other.Get("X-Api-Key"))
+}
+
+func TestHTTPTool_HeadersRequireAllowedHosts(t *testing.T) {
+	tool := NewHTTPTool(HTTPToolOptions{
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	}).Unwrap().(*HTTPTool)
+	result, err := tool.Call(context.Background(), &HTTPInput{URL: "https://attacker.example/"})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "Headers requires AllowedHosts")
+}
+
+func TestHTTPTool_BlocksHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should have been blocked")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		allowed []string
+		url     string
+		want    string
+	}{
+		{"loopback by default", nil, server.URL, "private/internal IP address"},
+		{"localhost by default", nil, "http://localhost:8080/", "localhost is not allowed"},
+		{"metadata endpoint", nil, "http://169.254.169.254/latest/meta-data/", "private/internal IP address"},
+		{"host not allowed", []string{"api.example.com"}, server.URL, "not in the allowed hosts"},
+		{"wildcard doesn't allow private", []string{"*.0.0.1"}, server.URL, "private/internal IP address"},
+		{"other port", []string{"127.0.0.1:1"}, server.URL, "not in the allowed hosts"},
+		{"scheme", []string{"127.0.0.1"}, "file:///etc/passwd", "only http and https"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := NewHTTPTool(HTTPToolOptions{AllowedHosts: tt.allowed}).Unwrap().(*HTTPTool)
+			result, err := tool.Call(context.Background(), &HTTPInput{URL: tt.url})
+			assert.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].Text, tt.want)
+		})
+	}
+}
+
+func TestHTTPTool_ChecksRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect should have been blocked")
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	tool := NewHTTPTool(HTTPToolOptions{AllowedHosts: []string{serverHost(t, server)}}).Unwrap().(*HTTPTool)
+	result, err := tool.Call(context.Background(), &HTTPInput{URL: server.URL})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "not in the allowed hosts")
+}
+
+func TestHTTPTool_UnsupportedMethod(t *testing.T) {
+	tool := NewHTTPTool().Unwrap().(*HTTPTool)
+	result, err := tool.Call(context.Background(), &HTTPInput{Method: "CONNECT", URL: "https://example.com"})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, `unsupported method "CONNECT"`)
+}
+
+func TestHostPattern(t *testing.T) {
+	tests := []struct {
+		entry string
+		host  string
+		port  string
+		want  bool
+	}{
+		{"api.example.com", "api.example.com", "443", true},
+		{"API.example.com", "api.example.com", "443", true},
+		{"api.example.com", "example.com", "443", false},
+		{"*.example.com", "api.example.com", "443", true},
+		{"*.example.com", "a.b.example.com", "80", true},
+		{"*.example.com", "example.com", "443", false},
+		{"*.example.com", "badexample.com", "443", false},
+		{"localhost:8080", "localhost", "8080", true},
+		{"localhost:8080", "localhost", "9090", false},
+		{"[::1]:8080", "::1", "8080", true},
+		{"::1", "::1", "80", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseHostPattern(tt.entry).matches(tt.host, tt.port), "%s matching %s:%s", tt.entry, tt.host, tt.port)
+	}
+}
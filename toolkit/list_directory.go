@@ -162,6 +162,12 @@ func (t *ListDirectoryTool) PreviewCall(ctx context.Context, input *ListDirector
 	}
 }
 
+// CachePolicy opts out of dive.ToolCache: directory listings change as
+// files are created and deleted.
+func (t *ListDirectoryTool) CachePolicy(input *ListDirectoryInput) dive.ToolCachePolicy {
+	return dive.ToolCachePolicy{Disabled: true}
+}
+
 // Call lists the directory contents and returns them as JSON.
 //
 // The result includes a message indicating the directory path and an
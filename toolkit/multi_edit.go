@@ -0,0 +1,281 @@
+package toolkit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+var (
+	_ dive.TypedTool[*MultiEditInput]          = &MultiEditTool{}
+	_ dive.TypedToolPreviewer[*MultiEditInput] = &MultiEditTool{}
+)
+
+// MultiEditInput represents the input parameters for the MultiEdit tool.
+type MultiEditInput struct {
+	// FilePath is the absolute path to the file to modify. Required.
+	FilePath string `json:"file_path"`
+
+	// Edits are applied in order, each to the result of the ones before it.
+	// Required.
+	Edits []MultiEditOperation `json:"edits"`
+
+	// DryRun returns a unified diff of the edits without writing the file.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// MultiEditOperation is one replacement in a MultiEdit call. Its fields
+// work as in [EditInput].
+type MultiEditOperation struct {
+	// OldString is the exact text to find and replace. Required.
+	// Must appear exactly once unless ReplaceAll is true.
+	OldString string `json:"old_string"`
+
+	// NewString is the replacement text. Required.
+	// Must be different from OldString.
+	NewString string `json:"new_string"`
+
+	// ReplaceAll enables replacing all occurrences of OldString.
+	ReplaceAll bool `json:"replace_all,omitempty"`
+}
+
+// MultiEditToolOptions configures the behavior of [MultiEditTool].
+type MultiEditToolOptions struct {
+	// MaxFileSize is the maximum file size in bytes that can be edited.
+	// Files larger than this limit will be rejected.
+	// Defaults to 10MB if not specified.
+	MaxFileSize int64
+
+	// WorkspaceDir restricts file edits to paths within this directory.
+	// If empty, no workspace restriction is applied (access to the entire
+	// filesystem). Ignored when Validator is set.
+	WorkspaceDir string
+
+	// Validator is an optional shared PathValidator. When set, it is used
+	// instead of creating one from WorkspaceDir.
+	Validator *PathValidator
+}
+
+// MultiEditTool makes several exact string replacements in one file as a
+// single change.
+//
+// The edits are applied in memory, in order, and the file is written once,
+// atomically, by renaming a temporary file over it. If any edit fails to
+// match, nothing is written and the error names the failing edit, so the
+// file is never left half-edited. Each edit follows the same rules as
+// [EditTool], and the file is validated the same way.
+type MultiEditTool struct {
+	fileEditor
+}
+
+// NewMultiEditTool creates a new MultiEditTool with the given options.
+// If no options are provided, defaults are used.
+func NewMultiEditTool(opts ...MultiEditToolOptions) *dive.TypedToolAdapter[*MultiEditInput] {
+	var resolvedOpts MultiEditToolOptions
+	if len(opts) > 0 {
+		resolvedOpts = opts[0]
+	}
+	return dive.ToolAdapter(&MultiEditTool{
+		fileEditor: newFileEditor(resolvedOpts.MaxFileSize, resolvedOpts.WorkspaceDir, resolvedOpts.Validator),
+	})
+}
+
+// Name returns "MultiEdit" as the tool identifier.
+func (t *MultiEditTool) Name() string {
+	return "MultiEdit"
+}
+
+// Description returns detailed usage instructions for the LLM.
+func (t *MultiEditTool) Description() string {
+	return `Make several exact string replacements in one file at once.
+
+Prefer this over repeated Edit calls when changing one file in several
+places. The edits are applied in order, each to the result of the ones
+before it, and the file is written only if every edit succeeds.
+
+Requirements for each edit:
+- old_string must be unique in the file (unless using replace_all: true)
+- new_string must be different from old_string
+- Earlier edits change the text later edits must match
+
+Use dry_run: true to see the unified diff without changing the file.
+
+Example:
+{"file_path": "/path/to/file.go", "edits": [
+  {"old_string": "func oldName(", "new_string": "func newName("},
+  {"old_string": "oldName(ctx)", "new_string": "newName(ctx)", "replace_all": true}
+]}`
+}
+
+// Schema returns the JSON schema describing the tool's input parameters.
+func (t *MultiEditTool) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type:     "object",
+		Required: []string{"file_path", "edits"},
+		Properties: map[string]*schema.Property{
+			"file_path": {
+				Type:        "string",
+				Description: "The absolute path to the file to modify",
+			},
+			"edits": {
+				Type:        "array",
+				Description: "Replacements to apply in order",
+				Items: &schema.Property{
+					Type:     "object",
+					Required: []string{"old_string", "new_string"},
+					Properties: map[string]*schema.Property{
+						"old_string": {
+							Type:        "string",
+							Description: "The exact text to replace (must be unique in the file unless using replace_all)",
+						},
+						"new_string": {
+							Type:        "string",
+							Description: "The text to replace it with (must be different from old_string)",
+						},
+						"replace_all": {
+							Type:        "boolean",
+							Description: "Replace all occurrences of old_string (default: false)",
+						},
+					},
+				},
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the unified diff of the edits without writing the file (default: false)",
+			},
+		},
+	}
+}
+
+// Annotations returns metadata hints about the tool's behavior.
+// MultiEdit is marked as destructive (modifies files) and has EditHint set
+// for special UI treatment. It is not idempotent: later edits may match
+// text that earlier ones produced.
+func (t *MultiEditTool) Annotations() *dive.ToolAnnotations {
+	return &dive.ToolAnnotations{
+		Title:           "MultiEdit",
+		ReadOnlyHint:    false,
+		DestructiveHint: true,
+		IdempotentHint:  false,
+		OpenWorldHint:   false,
+		EditHint:        true,
+	}
+}
+
+// PreviewCall returns a summary of the edit operation for permission prompts.
+func (t *MultiEditTool) PreviewCall(ctx context.Context, input *MultiEditInput) *dive.ToolCallPreview {
+	action := "Apply"
+	if input.DryRun {
+		action = "Preview"
+	}
+	return &dive.ToolCallPreview{
+		Summary: fmt.Sprintf("%s %d edit%s to %s", action, len(input.Edits),
+			pluralize(len(input.Edits)), filepath.Base(input.FilePath)),
+	}
+}
+
+// Call applies the edits and returns a unified diff of the changes.
+func (t *MultiEditTool) Call(ctx context.Context, input *MultiEditInput) (*dive.ToolResult, error) {
+	if len(input.Edits) == 0 {
+		return dive.NewToolResultError("edits must contain at least one edit"), nil
+	}
+	for i, edit := range input.Edits {
+		if edit.OldString == edit.NewString {
+			return dive.NewToolResultError(fmt.Sprintf("edits[%d]: old_string and new_string must be different", i)), nil
+		}
+	}
+
+	original, mode, errResult := t.readFile(input.FilePath)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	content := original
+	replaced := 0
+	for i, edit := range input.Edits {
+		oldString, newString := edit.OldString, edit.NewString
+		count := strings.Count(content, oldString)
+		if count == 0 {
+			oldString, newString = matchLineEndings(content, oldString, newString)
+			count = strings.Count(content, oldString)
+		}
+		if count == 0 {
+			return dive.NewToolResultError(fmt.Sprintf(
+				"edits[%d]: old_string not found in file: %q. No edits were applied.",
+				i, truncateForError(edit.OldString, 50),
+			)), nil
+		}
+		if count > 1 && !edit.ReplaceAll {
+			return dive.NewToolResultError(fmt.Sprintf(
+				"edits[%d]: old_string appears %d times (lines %v). Use replace_all: true to replace all, or provide a more specific string. No edits were applied.",
+				i, count, occurrenceLines(content, oldString),
+			)), nil
+		}
+		if edit.ReplaceAll {
+			content = strings.ReplaceAll(content, oldString, newString)
+		} else {
+			content = strings.Replace(content, oldString, newString, 1)
+		}
+		replaced += count
+	}
+
+	diff := unifiedDiff(input.FilePath, original, content)
+	if input.DryRun {
+		return dive.NewToolResultText(fmt.Sprintf(
+			"Dry run: %d edit%s would replace %d occurrence(s) in %s. The file was not changed.\n\n%s",
+			len(input.Edits), pluralize(len(input.Edits)), replaced, input.FilePath, diff,
+		)).WithDisplay(diff), nil
+	}
+
+	if err := writeFileAtomic(input.FilePath, []byte(content), mode); err != nil {
+		return dive.NewToolResultError(fmt.Sprintf("Error writing file: %v. No edits were applied.", err)), nil
+	}
+	return dive.NewToolResultText(fmt.Sprintf(
+		"Applied %d edit%s (%d occurrence(s)) to %s\n\n%s",
+		len(input.Edits), pluralize(len(input.Edits)), replaced, input.FilePath, diff,
+	)).WithDisplay(diff), nil
+}
+
+// writeFileAtomic replaces the file at path with data by writing a
+// temporary file in the same directory and renaming it over path, so
+// readers see either the old content or the new, never a partial write.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmpPath, err := stageFile(path, data, mode)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// stageFile writes data to a new temporary file next to path, ready to be
+// renamed over it, and returns the temporary file's path.
+func stageFile(path string, data []byte, mode os.FileMode) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(mode.Perm())
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
@@ -0,0 +1,175 @@
+package toolkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestMultiEditTool_Metadata(t *testing.T) {
+	tool := NewMultiEditTool()
+	assert.Equal(t, "MultiEdit", tool.Name())
+
+	schema := tool.Schema()
+	assert.Contains(t, schema.Required, "file_path")
+	assert.Contains(t, schema.Required, "edits")
+
+	annotations := tool.Annotations()
+	assert.True(t, annotations.DestructiveHint)
+	assert.False(t, annotations.IdempotentHint)
+	assert.True(t, annotations.EditHint)
+}
+
+func TestMultiEditTool_AppliesEditsInOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+	err := os.WriteFile(testFile, []byte("func oldName() {}\n\nfunc main() {\n\toldName()\n\toldName()\n}\n"), 0600)
+	assert.NoError(t, err)
+
+	tool := NewMultiEditTool(MultiEditToolOptions{WorkspaceDir: tempDir})
+	result, err := tool.Call(context.Background(), &MultiEditInput{
+		FilePath: testFile,
+		Edits: []MultiEditOperation{
+			{OldString: "func oldName()", NewString: "func newName()"},
+			{OldString: "oldName()", NewString: "newName()", ReplaceAll: true},
+			// Matches text produced by the first edit
+			{OldString: "func newName() {}", NewString: "func newName() { return }"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "Applied 3 edits (4 occurrence(s))")
+	assert.Contains(t, result.Content[0].Text, "+func newName() { return }")
+
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "func newName() { return }\n\nfunc main() {\n\tnewName()\n\tnewName()\n}\n", string(content))
+
+	info, err := os.Stat(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestMultiEditTool_FailingEditWritesNothing(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	original := "alpha\nbeta\nbeta\ngamma\n"
+	err := os.WriteFile(testFile, []byte(original), 0644)
+	assert.NoError(t, err)
+
+	tool := NewMultiEditTool(MultiEditToolOptions{WorkspaceDir: tempDir})
+
+	result, err := tool.Call(context.Background(), &MultiEditInput{
+		FilePath: testFile,
+		Edits: []MultiEditOperation{
+			{OldString: "alpha", NewString: "ALPHA"},
+			{OldString: "delta", NewString: "DELTA"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "edits[1]: old_string not found")
+
+	result, err = tool.Call(context.Background(), &MultiEditInput{
+		FilePath: testFile,
+		Edits: []MultiEditOperation{
+			{OldString: "alpha", NewString: "ALPHA"},
+			{OldString: "gamma", NewString: "GAMMA"},
+			{OldString: "beta", NewString: "BETA"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "edits[2]: old_string appears 2 times (lines [2 3])")
+
+	result, err = tool.Call(context.Background(), &MultiEditInput{
+		FilePath: testFile,
+		Edits: []MultiEditOperation{
+			{OldString: "alpha", NewString: "ALPHA"},
+			{OldString: "beta", NewString: "beta"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "edits[1]: old_string and new_string must be different")
+
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, original, string(content))
+}
+
+func TestMultiEditTool_DryRun(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	original := "one\ntwo\nthree\n"
+	err := os.WriteFile(testFile, []byte(original), 0644)
+	assert.NoError(t, err)
+
+	tool := NewMultiEditTool(MultiEditToolOptions{WorkspaceDir: tempDir})
+	result, err := tool.Call(context.Background(), &MultiEditInput{
+		FilePath: testFile,
+		DryRun:   true,
+		Edits: []MultiEditOperation{
+			{OldString: "one", NewString: "1"},
+			{OldString: "three", NewString: "3"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "The file was not changed")
+	assert.Contains(t, result.Display, "@@ -1,3 +1,3 @@\n-one\n+1\n two\n-three\n+3\n")
+
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, original, string(content))
+}
+
+func TestMultiEditTool_CRLF(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	err := os.WriteFile(testFile, []byte("a\r\nb\r\nc\r\n"), 0644)
+	assert.NoError(t, err)
+
+	tool := NewMultiEditTool(MultiEditToolOptions{WorkspaceDir: tempDir})
+	result, err := tool.Call(context.Background(), &MultiEditInput{
+		FilePath: testFile,
+		Edits: []MultiEditOperation{
+			{OldString: "a\nb\n", NewString: "A\nB\n"},
+			{OldString: "c", NewString: "C"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "A\r\nB\r\nC\r\n", string(content))
+}
+
+func TestMultiEditTool_OutsideWorkspace(t *testing.T) {
+	workspaceDir := t.TempDir()
+	outsideFile := filepath.Join(t.TempDir(), "outside.txt")
+	err := os.WriteFile(outsideFile, []byte("content"), 0644)
+	assert.NoError(t, err)
+
+	tool := NewMultiEditTool(MultiEditToolOptions{WorkspaceDir: workspaceDir})
+	result, err := tool.Call(context.Background(), &MultiEditInput{
+		FilePath: outsideFile,
+		Edits:    []MultiEditOperation{{OldString: "content", NewString: "changed"}},
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "outside workspace")
+
+	result, err = tool.Call(context.Background(), &MultiEditInput{FilePath: outsideFile})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "at least one edit")
+}
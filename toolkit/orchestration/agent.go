@@ -22,7 +22,6 @@ import (
 	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/dive/subagent"
 	"github.com/deepnoodle-ai/wonton/schema"
-	"github.com/google/uuid"
 )
 
 // AgentFactory creates an agent for a subagent spawn. It receives the subagent
@@ -209,7 +208,7 @@ func (t *agentTool) Call(ctx context.Context, input *AgentToolInput) (*dive.Tool
 	}
 
 	if input.RunInBackground {
-		return t.runBackground(input, agent), nil
+		return t.runBackground(ctx, input, agent), nil
 	}
 	return t.runSync(ctx, input, agent), nil
 }
@@ -234,8 +233,8 @@ func (t *agentTool) runSync(ctx context.Context, input *AgentToolInput, agent *d
 // context and returns immediately. Dive delivers the final result automatically
 // when the goroutine completes. The run is registered in Runs (if configured)
 // so TaskStop can cancel it by its task_id.
-func (t *agentTool) runBackground(input *AgentToolInput, agent *dive.Agent) *dive.ToolResult {
-	taskID := fmt.Sprintf("task_%s", uuid.New().String()[:8])
+func (t *agentTool) runBackground(ctx context.Context, input *AgentToolInput, agent *dive.Agent) *dive.ToolResult {
+	taskID := newTaskID(ctx, "task")
 	runCtx, cancel := context.WithCancel(context.Background())
 	if t.runs != nil {
 		t.runs.add(taskID, input.Description, cancel)
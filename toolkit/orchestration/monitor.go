@@ -9,7 +9,6 @@ import (
 
 	"github.com/deepnoodle-ai/dive"
 	"github.com/deepnoodle-ai/wonton/schema"
-	"github.com/google/uuid"
 )
 
 const (
@@ -112,7 +111,7 @@ func (t *monitorTool) Call(ctx context.Context, input *MonitorToolInput) (*dive.
 	}
 	timeout := time.Duration(timeoutMs) * time.Millisecond
 
-	taskID := fmt.Sprintf("monitor_%s", uuid.New().String()[:8])
+	taskID := newTaskID(ctx, "monitor")
 
 	// Independent of the parent context so the monitor survives the turn that
 	// started it; bounded by its own timeout and cancellable via TaskStop.
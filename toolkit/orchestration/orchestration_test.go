@@ -113,6 +113,18 @@ func TestRuns(t *testing.T) {
 	})
 }
 
+// fixedIDSource returns the same ID every time.
+type fixedIDSource string
+
+func (s fixedIDSource) NewID() string { return string(s) }
+
+func TestNewTaskID(t *testing.T) {
+	ctx := dive.WithIDSource(context.Background(), fixedIDSource("abc"))
+	assert.Equal(t, "task_abc", newTaskID(ctx, "task"))
+	ctx = dive.WithIDSource(context.Background(), fixedIDSource("0123456789"))
+	assert.Equal(t, "monitor_01234567", newTaskID(ctx, "monitor"))
+}
+
 func TestTaskStopTool(t *testing.T) {
 	ctx := context.Background()
 
@@ -3,6 +3,8 @@ package orchestration
 import (
 	"context"
 	"sync"
+
+	"github.com/deepnoodle-ai/dive"
 )
 
 // run is a single cancellable background run tracked by Runs.
@@ -25,6 +27,16 @@ func NewRuns() *Runs {
 	return &Runs{m: make(map[string]run)}
 }
 
+// newTaskID returns a task_id with the given prefix and a short ID from the
+// IDSource carried by ctx. IDs of eight characters or fewer are used whole.
+func newTaskID(ctx context.Context, prefix string) string {
+	id := dive.NewID(ctx)
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return prefix + "_" + id
+}
+
 // add registers a cancellable run under id. Called by the tools that start
 // background work (the Agent spawner and Monitor).
 func (r *Runs) add(id, description string, cancel context.CancelFunc) {
@@ -0,0 +1,295 @@
+package toolkit
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkHeaderPattern matches a unified diff hunk header such as
+// "@@ -12,5 +12,6 @@ func main() {".
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// filePatch is the part of a unified diff that changes one file. oldPath
+// is empty when the patch creates the file, and newPath is empty when it
+// deletes it.
+type filePatch struct {
+	oldPath string
+	newPath string
+	hunks   []patchHunk
+}
+
+// patchHunk is one "@@" section of a file patch.
+type patchHunk struct {
+	header   string
+	oldStart int
+	oldCount int
+	newStart int
+	newCount int
+	lines    []patchLine
+}
+
+// patchLine is one line of a hunk: kind is ' ' for context, '-' for a
+// removed line, and '+' for an added one. text has no line ending.
+// noNewline is set when the line is the last in its file and has no
+// newline.
+type patchLine struct {
+	kind      byte
+	text      string
+	noNewline bool
+}
+
+// parsePatch parses a unified diff that may change several files. Lines
+// outside file headers and hunks, such as "diff --git" and "index" lines,
+// are ignored. The "a/" and "b/" prefixes git adds to paths are removed.
+func parsePatch(patch string) ([]*filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	var patches []*filePatch
+	var current *filePatch
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			current = &filePatch{
+				oldPath: patchPath(strings.TrimPrefix(line, "--- ")),
+				newPath: patchPath(strings.TrimPrefix(lines[i+1], "+++ ")),
+			}
+			if current.oldPath == "" && current.newPath == "" {
+				return nil, fmt.Errorf("line %d: both file paths are /dev/null", i+1)
+			}
+			stripGitPrefixes(current)
+			patches = append(patches, current)
+			i += 2
+
+		case strings.HasPrefix(line, "@@"):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: hunk before any file header", i+1)
+			}
+			hunk, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			current.hunks = append(current.hunks, hunk)
+			i = next
+
+		default:
+			i++
+		}
+	}
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file changes found; expected \"--- \" and \"+++ \" headers followed by hunks")
+	}
+	for _, fp := range patches {
+		if len(fp.hunks) == 0 {
+			return nil, fmt.Errorf("%s: no hunks", fp.displayPath())
+		}
+	}
+	return patches, nil
+}
+
+// parseHunk parses the hunk whose header is lines[start], returning it and
+// the index of the line after it.
+func parseHunk(lines []string, start int) (patchHunk, int, error) {
+	m := hunkHeaderPattern.FindStringSubmatch(lines[start])
+	if m == nil {
+		return patchHunk{}, 0, fmt.Errorf("line %d: malformed hunk header %q", start+1, lines[start])
+	}
+	hunk := patchHunk{
+		header:   strings.TrimSpace(m[0]),
+		oldStart: atoiDefault(m[1], 0),
+		oldCount: atoiDefault(m[2], 1),
+		newStart: atoiDefault(m[3], 0),
+		newCount: atoiDefault(m[4], 1),
+	}
+	oldSeen, newSeen := 0, 0
+	i := start + 1
+	for ; i < len(lines) && (oldSeen < hunk.oldCount || newSeen < hunk.newCount); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, `\`) {
+			if len(hunk.lines) > 0 {
+				hunk.lines[len(hunk.lines)-1].noNewline = true
+			}
+			continue
+		}
+		if line == "" {
+			if i == len(lines)-1 {
+				break // end of the patch
+			}
+			// Some tools strip the space from empty context lines
+			line = " "
+		}
+		kind := line[0]
+		switch kind {
+		case ' ':
+			oldSeen++
+			newSeen++
+		case '-':
+			oldSeen++
+		case '+':
+			newSeen++
+		default:
+			return patchHunk{}, 0, fmt.Errorf("line %d: unexpected line in hunk %s: %q", i+1, hunk.header, line)
+		}
+		hunk.lines = append(hunk.lines, patchLine{kind: kind, text: line[1:]})
+	}
+	if oldSeen != hunk.oldCount || newSeen != hunk.newCount {
+		return patchHunk{}, 0, fmt.Errorf("hunk %s: expected %d old and %d new lines, found %d and %d",
+			hunk.header, hunk.oldCount, hunk.newCount, oldSeen, newSeen)
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], `\`) && len(hunk.lines) > 0 {
+		hunk.lines[len(hunk.lines)-1].noNewline = true
+		i++
+	}
+	return hunk, i, nil
+}
+
+// patchPath returns the path in a "---" or "+++" header, without any
+// timestamp, or "" for /dev/null.
+func patchPath(name string) string {
+	if tab := strings.IndexByte(name, '\t'); tab >= 0 {
+		name = name[:tab]
+	}
+	name = strings.TrimSpace(name)
+	if name == "/dev/null" {
+		return ""
+	}
+	return name
+}
+
+// stripGitPrefixes removes the "a/" and "b/" prefixes git adds to paths.
+func stripGitPrefixes(fp *filePatch) {
+	oldOK := fp.oldPath == "" || strings.HasPrefix(fp.oldPath, "a/")
+	newOK := fp.newPath == "" || strings.HasPrefix(fp.newPath, "b/")
+	if !oldOK || !newOK {
+		return
+	}
+	fp.oldPath = strings.TrimPrefix(fp.oldPath, "a/")
+	fp.newPath = strings.TrimPrefix(fp.newPath, "b/")
+}
+
+// displayPath names the file a patch changes.
+func (fp *filePatch) displayPath() string {
+	switch {
+	case fp.newPath == "":
+		return fp.oldPath
+	case fp.oldPath == "" || fp.oldPath == fp.newPath:
+		return fp.newPath
+	}
+	return fp.oldPath + " -> " + fp.newPath
+}
+
+// applyHunks applies the hunks of fp to content and returns the result
+// with the number of lines added and removed.
+//
+// Each hunk is looked for at the line its header names, then at
+// increasing distances from it, so hunks still apply after unrelated lines
+// were added or removed above them. If the lines don't match exactly,
+// they are compared again ignoring differences in whitespace. Hunks must
+// match in order without overlapping; a hunk that doesn't match anywhere
+// is an error. Context lines keep the file's text, and added lines take
+// the file's line endings.
+func applyHunks(content string, fp *filePatch) (string, int, int, error) {
+	fileLines := splitLinesKeepEnds(content)
+	eol := "\n"
+	if strings.Contains(content, "\r\n") {
+		eol = "\r\n"
+	}
+
+	var out []string
+	added, removed := 0, 0
+	pos := 0
+	for i, hunk := range fp.hunks {
+		var old []string
+		for _, line := range hunk.lines {
+			if line.kind != '+' {
+				old = append(old, line.text)
+			}
+		}
+		expected := hunk.oldStart - 1
+		if hunk.oldCount == 0 {
+			// The hunk inserts after line oldStart
+			expected = hunk.oldStart
+		}
+		at, ok := findHunk(fileLines, old, expected, pos)
+		if !ok {
+			return "", 0, 0, fmt.Errorf("hunk %d (%s) does not match the file", i+1, hunk.header)
+		}
+
+		out = append(out, fileLines[pos:at]...)
+		j := at
+		for _, line := range hunk.lines {
+			switch line.kind {
+			case ' ':
+				out = append(out, fileLines[j])
+				j++
+			case '-':
+				j++
+				removed++
+			case '+':
+				text := line.text
+				if !line.noNewline {
+					text += eol
+				}
+				out = append(out, text)
+				added++
+			}
+		}
+		pos = j
+	}
+	out = append(out, fileLines[pos:]...)
+	return strings.Join(out, ""), added, removed, nil
+}
+
+// findHunk returns the index of the first of fileLines at which old
+// matches, searching outward from expected and not before from.
+func findHunk(fileLines, old []string, expected, from int) (int, bool) {
+	last := len(fileLines) - len(old)
+	if last < from {
+		return 0, false
+	}
+	expected = min(max(expected, from), last)
+	if len(old) == 0 {
+		return expected, true
+	}
+	for _, normalize := range []func(string) string{trimLineEnding, collapseWhitespace} {
+		for d := 0; expected-d >= from || expected+d <= last; d++ {
+			for _, at := range []int{expected - d, expected + d} {
+				if at >= from && at <= last && linesMatch(fileLines[at:at+len(old)], old, normalize) {
+					return at, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// linesMatch reports whether fileLines and old are equal after normalize.
+func linesMatch(fileLines, old []string, normalize func(string) string) bool {
+	for i := range old {
+		if normalize(fileLines[i]) != normalize(old[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// trimLineEnding removes a trailing LF or CRLF.
+func trimLineEnding(s string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")
+}
+
+// collapseWhitespace trims s and reduces each run of whitespace in it to a
+// single space.
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// atoiDefault parses s as a decimal integer, returning def if s is empty.
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,448 @@
+package toolkit
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// errNoPDFText is returned when a PDF has no text that extractPDFText can
+// read, typically because it is scanned or uses custom font encodings.
+var errNoPDFText = errors.New("no extractable text in PDF (it may be scanned or use embedded font encodings)")
+
+// maxPDFStreamSize caps the decompressed size of a single PDF stream.
+const maxPDFStreamSize = 32 * 1024 * 1024
+
+// maxPDFArrayDepth caps how deeply arrays in a content stream may nest.
+// Real text arrays are flat; the cap keeps a hostile PDF from exhausting
+// the stack of the recursive lexer.
+const maxPDFArrayDepth = 64
+
+// errPDFArrayDepth is returned for content streams whose arrays nest more
+// than maxPDFArrayDepth deep.
+var errPDFArrayDepth = errors.New("PDF content stream arrays are nested too deeply")
+
+var pdfStreamStart = regexp.MustCompile(`stream\r?\n`)
+
+// extractPDFText extracts the text of a PDF's page content streams. It
+// handles uncompressed and Flate-compressed streams and the standard text
+// operators (Tj, TJ, ', "), which covers most PDFs produced from documents.
+// Text drawn with composite fonts is only recovered when the strings are
+// UTF-16 encoded; scanned PDFs have no text to extract.
+func extractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("not a PDF file")
+	}
+	var out strings.Builder
+	for _, loc := range pdfStreamStart.FindAllIndex(data, -1) {
+		dict := pdfStreamDict(data[:loc[0]])
+		if !isPDFContentStream(dict) {
+			continue
+		}
+		rest := data[loc[1]:]
+		end := bytes.Index(rest, []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+		stream := rest[:end]
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			decoded, err := inflatePDFStream(stream)
+			if err != nil {
+				continue
+			}
+			stream = decoded
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			// Other filters (DCT, LZW, ...) don't hold text streams we read
+			continue
+		}
+		text, err := pdfContentText(stream)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(text) != "" {
+			out.WriteString(text)
+			out.WriteString("\n")
+		}
+	}
+	text := strings.TrimSpace(collapseBlankLines(out.String()))
+	if text == "" {
+		return "", errNoPDFText
+	}
+	return text, nil
+}
+
+// pdfStreamDict returns the dictionary that precedes a stream keyword.
+func pdfStreamDict(before []byte) []byte {
+	end := bytes.LastIndex(before, []byte(">>"))
+	if end < 0 {
+		return nil
+	}
+	start := bytes.LastIndex(before[:end], []byte("obj"))
+	if start < 0 {
+		start = 0
+	}
+	return before[start : end+2]
+}
+
+// isPDFContentStream reports whether a stream dictionary may describe page
+// content, as opposed to images, fonts, or cross-reference data.
+func isPDFContentStream(dict []byte) bool {
+	for _, marker := range []string{"/Image", "/XRef", "/ObjStm", "/Length1", "/Length2", "/Length3", "/FontFile", "/Metadata", "/EmbeddedFile"} {
+		if bytes.Contains(dict, []byte(marker)) {
+			return false
+		}
+	}
+	return true
+}
+
+func inflatePDFStream(stream []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	decoded, err := io.ReadAll(io.LimitReader(r, maxPDFStreamSize))
+	if err != nil && len(decoded) == 0 {
+		return nil, err
+	}
+	// Streams often carry trailing bytes after the compressed data; keep
+	// whatever decoded cleanly.
+	return decoded, nil
+}
+
+// pdfContentText interprets the text operators of a content stream.
+func pdfContentText(content []byte) (string, error) {
+	var out strings.Builder
+	var operands []any
+	lex := &pdfLexer{data: content}
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		op, isOp := tok.(pdfOperator)
+		if !isOp {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "Tj", "'", "\"":
+			if op != "Tj" {
+				out.WriteString("\n")
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					out.WriteString(s.text())
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				if arr, ok := operands[len(operands)-1].([]any); ok {
+					for _, item := range arr {
+						switch v := item.(type) {
+						case pdfString:
+							out.WriteString(v.text())
+						case float64:
+							// A large negative adjustment is a word gap
+							if v < -200 {
+								out.WriteString(" ")
+							}
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if ty, ok := operands[len(operands)-1].(float64); ok && ty != 0 {
+					out.WriteString("\n")
+				} else {
+					out.WriteString(" ")
+				}
+			}
+		case "T*", "ET":
+			out.WriteString("\n")
+		case "BI":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+	if lex.err != nil {
+		return "", lex.err
+	}
+	return out.String(), nil
+}
+
+type pdfOperator string
+
+type pdfString []byte
+
+// text decodes a PDF string. UTF-16 strings (with a byte order mark, or
+// all-zero high bytes as in composite fonts with Unicode encodings) are
+// decoded as UTF-16BE; others are treated as PDFDocEncoding, which matches
+// Latin-1 for printable characters.
+func (s pdfString) text() string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		return decodeUTF16BE(s[2:])
+	}
+	if len(s) >= 4 && len(s)%2 == 0 {
+		zeros := 0
+		for i := 0; i < len(s); i += 2 {
+			if s[i] == 0 {
+				zeros++
+			}
+		}
+		if zeros == len(s)/2 {
+			return decodeUTF16BE(s)
+		}
+	}
+	runes := make([]rune, 0, len(s))
+	for _, b := range s {
+		if b >= 0x20 || b == '\t' || b == '\n' {
+			runes = append(runes, rune(b))
+		}
+	}
+	return string(runes)
+}
+
+func decodeUTF16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfLexer tokenizes a PDF content stream into numbers, strings, names,
+// arrays, and operators.
+type pdfLexer struct {
+	data  []byte
+	pos   int
+	depth int   // arrays open at pos
+	err   error // set when the stream can't be read, ending it
+}
+
+func (l *pdfLexer) next() (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) || l.err != nil {
+		return nil, false
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return l.literalString(), true
+	case c == '<' && l.peek(1) == '<':
+		l.pos += 2
+		return pdfOperator("<<"), true
+	case c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return pdfOperator(">>"), true
+	case c == '<':
+		l.pos++
+		return l.hexString(), true
+	case c == '[':
+		if l.depth >= maxPDFArrayDepth {
+			l.err = errPDFArrayDepth
+			return nil, false
+		}
+		l.pos++
+		l.depth++
+		defer func() { l.depth-- }()
+		var arr []any
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return arr, true
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, true
+			}
+			tok, ok := l.next()
+			if !ok {
+				return arr, true
+			}
+			arr = append(arr, tok)
+		}
+	case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return pdfOperator(string(c)), true
+	case c == '/':
+		start := l.pos
+		l.pos++
+		for l.pos < len(l.data) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return string(l.data[start:l.pos]), true
+	}
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if n, err := strconv.ParseFloat(word, 64); err == nil {
+		return n, true
+	}
+	return pdfOperator(word), true
+}
+
+func (l *pdfLexer) peek(offset int) byte {
+	if l.pos+offset < len(l.data) {
+		return l.data[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	var buf []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return buf
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case '\r':
+				if l.peek(0) == '\n' {
+					l.pos++
+				}
+			case '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					n := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					buf = append(buf, byte(n))
+				} else {
+					buf = append(buf, e)
+				}
+			}
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // closing '>'
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	buf := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		n, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			break
+		}
+		buf = append(buf, byte(n))
+	}
+	return buf
+}
+
+// skipInlineImage skips the binary data of an inline image (BI ... ID
+// <data> EI).
+func (l *pdfLexer) skipInlineImage() {
+	id := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if id < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += id + 2
+	for l.pos < len(l.data) {
+		ei := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if ei < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		l.pos += ei + 2
+		if isPDFSpace(l.data[l.pos-3]) && (l.pos >= len(l.data) || isPDFSpace(l.data[l.pos])) {
+			return
+		}
+	}
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return isPDFSpace(c) || strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// collapseBlankLines trims trailing spaces and reduces runs of blank lines
+// to a single blank line.
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
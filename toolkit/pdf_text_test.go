@@ -0,0 +1,76 @@
+package toolkit
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// buildTestPDF returns a minimal PDF with one page content stream per
+// argument, compressed when compress is true.
+func buildTestPDF(compress bool, contents ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	for i, content := range contents {
+		stream := []byte(content)
+		filter := ""
+		if compress {
+			var z bytes.Buffer
+			w := zlib.NewWriter(&z)
+			w.Write(stream)
+			w.Close()
+			stream = z.Bytes()
+			filter = " /Filter /FlateDecode"
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Length %d%s >>\nstream\n", i+1, len(stream), filter)
+		buf.Write(stream)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+	buf.WriteString("%%EOF\n")
+	return buf.Bytes()
+}
+
+func TestExtractPDFText(t *testing.T) {
+	t.Run("uncompressed", func(t *testing.T) {
+		pdf := buildTestPDF(false, "BT /F1 12 Tf 72 720 Td (Hello, PDF!) Tj 0 -14 Td (Second line) Tj ET")
+		text, err := extractPDFText(pdf)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello, PDF!\nSecond line", text)
+	})
+
+	t.Run("compressed with TJ arrays and escapes", func(t *testing.T) {
+		pdf := buildTestPDF(true,
+			`BT [(Kerned)-300(words) 20 (here)] TJ T* (A \(nested\) string\041) Tj ET`,
+			`BT <FEFF00480069> Tj ET`)
+		text, err := extractPDFText(pdf)
+		assert.NoError(t, err)
+		assert.Equal(t, "Kerned wordshere\nA (nested) string!\n\nHi", text)
+	})
+
+	t.Run("no text", func(t *testing.T) {
+		pdf := buildTestPDF(false, "0 0 m 100 100 l S")
+		_, err := extractPDFText(pdf)
+		assert.Error(t, err)
+	})
+
+	t.Run("deeply nested arrays", func(t *testing.T) {
+		nested := strings.Repeat("[", 1_000_000) + "(deep)" + strings.Repeat("]", 1_000_000)
+		pdf := buildTestPDF(false, "BT "+nested+" TJ ET")
+		_, err := extractPDFText(pdf)
+		assert.ErrorIs(t, err, errPDFArrayDepth)
+
+		pdf = buildTestPDF(false, "BT [[(shallow)]] TJ [(ok)] TJ ET")
+		text, err := extractPDFText(pdf)
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", text)
+	})
+
+	t.Run("not a PDF", func(t *testing.T) {
+		_, err := extractPDFText([]byte("hello"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package toolkit
+
+import (
+	"os/exec"
+	"time"
+)
+
+// resourceLimitsSupported reports whether limitResources can enforce
+// limits on this platform.
+const resourceLimitsSupported = false
+
+// reapProcessGroup bounds how long cmd waits for its output after its
+// context is done. Process groups aren't available on this platform, so
+// only the command itself is killed.
+func reapProcessGroup(cmd *exec.Cmd) {
+	cmd.WaitDelay = processWaitDelay
+}
+
+// limitResources returns command unchanged: resource limits aren't
+// supported on this platform, and NewBashTool rejects them.
+func limitResources(command string, cpu time.Duration, memory int64) string {
+	return command
+}
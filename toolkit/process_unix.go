@@ -0,0 +1,47 @@
+//go:build unix
+
+package toolkit
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// resourceLimitsSupported reports whether limitResources can enforce
+// limits on this platform.
+const resourceLimitsSupported = true
+
+// reapProcessGroup runs cmd in its own process group and kills the whole
+// group when cmd's context is done, so subprocesses started by a command
+// don't outlive a canceled tool call.
+func reapProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = processWaitDelay
+}
+
+// limitResources prefixes command with ulimit calls that cap the CPU time
+// and virtual memory of the shell, and so of every process it starts. Go
+// can't set rlimits on a child between fork and exec, so the shell sets
+// them before running command; setting both the soft and hard limits keeps
+// command from raising them. If a limit can't be set, the command doesn't
+// run.
+func limitResources(command string, cpu time.Duration, memory int64) string {
+	var limits []string
+	if cpu > 0 {
+		seconds := int64((cpu + time.Second - 1) / time.Second)
+		limits = append(limits, fmt.Sprintf("ulimit -t %d", seconds))
+	}
+	if memory > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -v %d", (memory+1023)/1024))
+	}
+	if len(limits) == 0 {
+		return command
+	}
+	return strings.Join(limits, " && ") + " || exit 1\n" + command
+}
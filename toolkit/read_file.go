@@ -142,6 +142,16 @@ func (t *ReadFileTool) PreviewCall(ctx context.Context, input *ReadFileInput) *d
 	}
 }
 
+// CachePolicy ties cached reads to the file, so a dive.ToolCache discards
+// them when the file changes.
+func (t *ReadFileTool) CachePolicy(input *ReadFileInput) dive.ToolCachePolicy {
+	absPath, err := filepath.Abs(input.FilePath)
+	if err != nil {
+		return dive.ToolCachePolicy{Disabled: true}
+	}
+	return dive.ToolCachePolicy{Files: []string{absPath}}
+}
+
 // Call reads the file contents and returns them.
 //
 // When Offset and Limit are not specified, reads the entire file (subject to
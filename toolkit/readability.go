@@ -0,0 +1,164 @@
+package toolkit
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	readablePositive = regexp.MustCompile(`(?i)article|body|content|entry|hentry|main|page|post|text|blog|story`)
+	readableNegative = regexp.MustCompile(`(?i)comment|com-|contact|foot|footer|footnote|masthead|media|meta|menu|nav|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|ad-break|agegate|pagination|pager|popup|widget|cookie|banner`)
+)
+
+// readableMinTextLength is the shortest paragraph that counts toward a
+// candidate's score.
+const readableMinTextLength = 25
+
+// extractReadableHTML finds the element holding a page's main content using
+// a readability-style heuristic, and returns it rendered as HTML. Paragraphs
+// award points to their parent and grandparent by length and comma count;
+// class and id names like "article" or "sidebar" adjust the score; and each
+// candidate is penalized by the share of its text inside links. It returns
+// false when no element looks like content, such as on link-only index
+// pages.
+func extractReadableHTML(htmlContent string) (string, bool) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", false
+	}
+	removeUnreadable(doc)
+
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node // in document order, for deterministic ties
+	addScore := func(n *html.Node, score float64) {
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialReadableScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.P || n.DataAtom == atom.Pre || n.DataAtom == atom.Td) {
+			text := strings.TrimSpace(nodeText(n))
+			if len(text) >= readableMinTextLength {
+				score := 1 + float64(strings.Count(text, ",")) + float64(min(len(text)/100, 3))
+				if parent := n.Parent; parent != nil {
+					addScore(parent, score)
+					if grandparent := parent.Parent; grandparent != nil {
+						addScore(grandparent, score/2)
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, n := range candidates {
+		score := scores[n] * (1 - linkDensity(n))
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	var buf strings.Builder
+	if err := html.Render(&buf, best); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// initialReadableScore returns a candidate's starting score from its tag
+// and its class and id names.
+func initialReadableScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score += 10
+	case atom.Div:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Form, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+	for _, attr := range n.Attr {
+		if attr.Key != "class" && attr.Key != "id" {
+			continue
+		}
+		if readableNegative.MatchString(attr.Val) {
+			score -= 25
+		}
+		if readablePositive.MatchString(attr.Val) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// removeUnreadable removes elements that never hold readable content.
+func removeUnreadable(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode {
+			n.RemoveChild(c)
+		} else if c.Type == html.ElementNode {
+			switch c.DataAtom {
+			case atom.Script, atom.Style, atom.Noscript, atom.Iframe, atom.Nav,
+				atom.Header, atom.Footer, atom.Aside, atom.Form, atom.Svg, atom.Button:
+				n.RemoveChild(c)
+			default:
+				removeUnreadable(c)
+			}
+		}
+		c = next
+	}
+}
+
+// linkDensity returns the fraction of n's text that is inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(nodeText(n))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			links += len(nodeText(n))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return float64(links) / float64(total)
+}
+
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
@@ -0,0 +1,227 @@
+package toolkit
+
+import (
+	"context"
+	"math"
+	"regexp"
+
+	"github.com/deepnoodle-ai/dive"
+)
+
+var _ dive.EarlyHooksExtension = &SecretRedactor{}
+
+// DefaultRedactionText replaces each secret found by [SecretRedactor].
+const DefaultRedactionText = "[REDACTED]"
+
+// DefaultEntropyMinLength is the default minimum token length considered by
+// entropy-based detection.
+const DefaultEntropyMinLength = 20
+
+// DefaultSecretPatterns match known credential formats, so hashes, UUIDs,
+// and other long identifiers pass through. A pattern with a capture group
+// named "secret" redacts only that group, which keeps the surrounding key
+// name visible (e.g. "aws_secret_access_key=[REDACTED]").
+var DefaultSecretPatterns = []*regexp.Regexp{
+	// PEM private keys
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	// AWS access key IDs
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	// AWS secret access keys in assignments
+	regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`),
+	// Bearer tokens in headers and logs
+	regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]{8,}=*)`),
+	// GitHub tokens
+	regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	// Anthropic and OpenAI style API keys
+	regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_\-]{20,}`),
+	// Slack tokens
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	// Google API keys
+	regexp.MustCompile(`\bAIza[0-9A-Za-z\-_]{35}\b`),
+}
+
+// SecretAssignmentPattern matches any value assigned to a name containing
+// api_key, secret, token, or password, such as "DB_PASSWORD=hunter2". It
+// isn't a default pattern because it also matches checksums, commit SHAs,
+// and token counts; add it to SecretRedactorOptions.Patterns to opt in.
+var SecretAssignmentPattern = regexp.MustCompile(`(?i)\b[A-Z0-9_]*(?:api[_-]?key|secret|token|password|passwd)["']?\s*[=:]\s*["']?(?P<secret>[^\s"']{8,})`)
+
+// entropyCandidate matches runs of characters typical of encoded secrets.
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+// SecretRedactorOptions configures a [SecretRedactor].
+type SecretRedactorOptions struct {
+	// Patterns are additional patterns to redact. A capture group named
+	// "secret" limits redaction to that group.
+	Patterns []*regexp.Regexp
+
+	// DisableDefaultPatterns skips [DefaultSecretPatterns], so only Patterns
+	// are applied.
+	DisableDefaultPatterns bool
+
+	// EntropyThreshold enables entropy-based detection when greater than
+	// zero: any token of at least EntropyMinLength characters whose Shannon
+	// entropy (bits per character) meets the threshold is redacted. Values
+	// around 4.5 catch random keys while sparing most words and paths, but
+	// expect some false positives on hashes and encoded data.
+	EntropyThreshold float64
+
+	// EntropyMinLength is the minimum token length for entropy detection.
+	// Defaults to [DefaultEntropyMinLength] (20).
+	EntropyMinLength int
+
+	// Replacement is the text substituted for each secret. Defaults to
+	// [DefaultRedactionText].
+	Replacement string
+}
+
+// SecretRedactor removes secrets such as API keys, bearer tokens, and private
+// keys from tool outputs before they reach the model, logs, or the session.
+//
+// SecretRedactor implements [dive.EarlyHooksExtension]: add it to
+// AgentOptions.Extensions and it redacts the text of every tool result,
+// successful or failed, via PostToolUse and PostToolUseFailure hooks that
+// run before any other hook. It can also be used directly through
+// [SecretRedactor.Redact].
+//
+//	agent, err := dive.NewAgent(dive.AgentOptions{
+//	    Model:      model,
+//	    Tools:      tools,
+//	    Extensions: []dive.Extension{toolkit.NewSecretRedactor()},
+//	})
+type SecretRedactor struct {
+	patterns         []*regexp.Regexp
+	entropyThreshold float64
+	entropyMinLength int
+	replacement      string
+}
+
+// NewSecretRedactor creates a SecretRedactor with the given options.
+func NewSecretRedactor(opts ...SecretRedactorOptions) *SecretRedactor {
+	var options SecretRedactorOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.EntropyMinLength == 0 {
+		options.EntropyMinLength = DefaultEntropyMinLength
+	}
+	if options.Replacement == "" {
+		options.Replacement = DefaultRedactionText
+	}
+	var patterns []*regexp.Regexp
+	if !options.DisableDefaultPatterns {
+		patterns = append(patterns, DefaultSecretPatterns...)
+	}
+	patterns = append(patterns, options.Patterns...)
+	return &SecretRedactor{
+		patterns:         patterns,
+		entropyThreshold: options.EntropyThreshold,
+		entropyMinLength: options.EntropyMinLength,
+		replacement:      options.Replacement,
+	}
+}
+
+// Redact returns text with all detected secrets replaced.
+func (r *SecretRedactor) Redact(text string) string {
+	for _, re := range r.patterns {
+		text = r.redactPattern(re, text)
+	}
+	if r.entropyThreshold > 0 {
+		text = entropyCandidate.ReplaceAllStringFunc(text, func(token string) string {
+			if len(token) >= r.entropyMinLength && shannonEntropy(token) >= r.entropyThreshold {
+				return r.replacement
+			}
+			return token
+		})
+	}
+	return text
+}
+
+// RedactResult redacts the text content and display of a tool result in
+// place. It reports whether anything was redacted.
+func (r *SecretRedactor) RedactResult(result *dive.ToolResult) bool {
+	if result == nil {
+		return false
+	}
+	changed := false
+	for _, content := range result.Content {
+		if content == nil || content.Type != dive.ToolResultContentTypeText {
+			continue
+		}
+		if redacted := r.Redact(content.Text); redacted != content.Text {
+			content.Text = redacted
+			changed = true
+		}
+	}
+	if redacted := r.Redact(result.Display); redacted != result.Display {
+		result.Display = redacted
+		changed = true
+	}
+	return changed
+}
+
+// Tools returns no tools.
+func (r *SecretRedactor) Tools() []dive.Tool { return nil }
+
+// Rules returns no system prompt rules.
+func (r *SecretRedactor) Rules() string { return "" }
+
+// Hooks returns PostToolUse and PostToolUseFailure hooks that redact every
+// tool result.
+func (r *SecretRedactor) Hooks() dive.Hooks {
+	return dive.Hooks{
+		PostToolUse:        []dive.PostToolUseHook{r.redactHook},
+		PostToolUseFailure: []dive.PostToolUseFailureHook{r.redactHook},
+	}
+}
+
+// EarlyHooks returns true, so tool results are redacted before other hooks
+// see them.
+func (r *SecretRedactor) EarlyHooks() bool { return true }
+
+func (r *SecretRedactor) redactHook(ctx context.Context, hctx *dive.HookContext) error {
+	if hctx.Result != nil {
+		r.RedactResult(hctx.Result.Result)
+	}
+	return nil
+}
+
+func (r *SecretRedactor) redactPattern(re *regexp.Regexp, text string) string {
+	group := re.SubexpIndex("secret")
+	if group < 0 {
+		return re.ReplaceAllLiteralString(text, r.replacement)
+	}
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 {
+			continue
+		}
+		out = append(out, text[last:start]...)
+		out = append(out, r.replacement...)
+		last = end
+	}
+	out = append(out, text[last:]...)
+	return string(out)
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, c := range s {
+		counts[c]++
+		total++
+	}
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,80 @@
+package toolkit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+var _ dive.TypedTool[*StatusInput] = &StatusTool{}
+
+// StatusInput represents the input parameters for the Status tool.
+type StatusInput struct {
+	// Message is the status to show, e.g. "Running the test suite...".
+	Message string `json:"message"`
+}
+
+// StatusTool lets the model tell the user what it is doing during a long run,
+// such as "Searching the web..." or "Writing tests...", without putting it in
+// the final answer.
+//
+// Each call is reported with [dive.ReportStatus] and reaches the
+// CreateResponse EventCallback as a ResponseItem of type
+// [dive.ResponseItemTypeStatus]. UIs should render it as an ephemeral status
+// line. The model only gets a short acknowledgement back, so the status text
+// does not grow the conversation beyond the tool call itself.
+type StatusTool struct{}
+
+// NewStatusTool creates a new StatusTool.
+func NewStatusTool() *dive.TypedToolAdapter[*StatusInput] {
+	return dive.ToolAdapter(&StatusTool{})
+}
+
+// Name returns "Status" as the tool identifier.
+func (t *StatusTool) Name() string {
+	return "Status"
+}
+
+// Description returns usage instructions for the LLM.
+func (t *StatusTool) Description() string {
+	return "Shows the user a short status line about what you are doing, such as \"Searching the web for pricing data...\". Use it at the start of each major step of a long task. The status is displayed transiently and is not part of your answer, so don't use it to communicate results."
+}
+
+// Schema returns the JSON schema describing the tool's input parameters.
+func (t *StatusTool) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type:     "object",
+		Required: []string{"message"},
+		Properties: map[string]*schema.Property{
+			"message": {
+				Type:        "string",
+				Description: "A short, present-tense status, e.g. \"Writing tests...\"",
+			},
+		},
+	}
+}
+
+// Annotations returns metadata hints about the tool's behavior.
+// Status is marked as read-only; it is not idempotent, since each call
+// shows a status.
+func (t *StatusTool) Annotations() *dive.ToolAnnotations {
+	return &dive.ToolAnnotations{
+		Title:           "Status",
+		ReadOnlyHint:    true,
+		DestructiveHint: false,
+		IdempotentHint:  false,
+		OpenWorldHint:   false,
+	}
+}
+
+// Call reports the status to the user.
+func (t *StatusTool) Call(ctx context.Context, input *StatusInput) (*dive.ToolResult, error) {
+	message := strings.TrimSpace(input.Message)
+	if message == "" {
+		return NewToolResultError("Error: message is required"), nil
+	}
+	dive.ReportStatus(ctx, message)
+	return NewToolResultText("Status shown.").WithDisplay(message), nil
+}
@@ -0,0 +1,30 @@
+package toolkit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestStatusTool(t *testing.T) {
+	tool := NewStatusTool()
+	assert.Equal(t, "Status", tool.Name())
+
+	var toolCallID, status string
+	ctx := dive.WithToolCallID(context.Background(), "call_1")
+	ctx = dive.WithStatusFunc(ctx, func(id, text string) {
+		toolCallID, status = id, text
+	})
+
+	result, err := tool.Call(ctx, &StatusInput{Message: " Writing tests... "})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "call_1", toolCallID)
+	assert.Equal(t, "Writing tests...", status)
+
+	result, err = tool.Call(ctx, &StatusInput{})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+}
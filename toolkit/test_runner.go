@@ -0,0 +1,677 @@
+package toolkit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+var (
+	_ dive.TypedTool[*TestRunnerInput]          = &TestRunnerTool{}
+	_ dive.TypedToolPreviewer[*TestRunnerInput] = &TestRunnerTool{}
+)
+
+// DefaultTestRunnerTimeout is the default time limit for a test run.
+const DefaultTestRunnerTimeout = 10 * time.Minute
+
+// TestFramework identifies how a project's tests are run.
+type TestFramework string
+
+const (
+	// TestFrameworkGo runs "go test -json ./...".
+	TestFrameworkGo TestFramework = "go"
+
+	// TestFrameworkPytest runs "pytest" or, when it isn't on the PATH,
+	// "python3 -m pytest".
+	TestFrameworkPytest TestFramework = "pytest"
+
+	// TestFrameworkNpm runs "npm test". Results are read from Jest and
+	// Vitest output.
+	TestFrameworkNpm TestFramework = "npm"
+)
+
+// TestRunResult is the structured outcome of a test run.
+type TestRunResult struct {
+	Framework TestFramework `json:"framework"`
+
+	// Command is the command that was run, and Dir where it ran.
+	Command string `json:"command"`
+	Dir     string `json:"dir"`
+
+	// ExitCode is the exit code of the command, or -1 if it timed out.
+	ExitCode int `json:"exit_code"`
+
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+
+	// Failures describes each failing test with its output.
+	Failures []*TestFailure `json:"failures,omitempty"`
+
+	// Output is the end of the raw output. It is only included when the
+	// command failed without reporting failing tests, such as when the
+	// code doesn't compile.
+	Output string `json:"output,omitempty"`
+
+	// TimedOut is true if the run was stopped by the timeout. Counts and
+	// failures then cover the tests that finished.
+	TimedOut bool `json:"timed_out,omitempty"`
+
+	// Truncated is true if failure output was cut to fit the output limit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// TestFailure is a failing test.
+type TestFailure struct {
+	// Name is the test name, such as "TestParse/empty" for Go or
+	// "tests/test_api.py::test_login" for pytest. For a Go package that
+	// fails without a failing test, such as on a build error, it is the
+	// package path.
+	Name string `json:"name"`
+
+	// Package is the Go package of the test.
+	Package string `json:"package,omitempty"`
+
+	// Output is the test's failure output.
+	Output string `json:"output,omitempty"`
+}
+
+// RunTestsOptions configures RunTests.
+type RunTestsOptions struct {
+	// Framework selects how to run the tests. Detected with
+	// DetectTestFramework if empty.
+	Framework TestFramework
+
+	// Filter limits the run to matching tests: the -run pattern for Go,
+	// the -k expression for pytest, and arguments passed to the npm test
+	// script.
+	Filter string
+
+	// Timeout limits the run. Defaults to DefaultTestRunnerTimeout.
+	Timeout time.Duration
+
+	// MaxOutputLength caps the failure output in the result, in bytes.
+	// Defaults to DefaultMaxOutputLength.
+	MaxOutputLength int
+}
+
+// DetectTestFramework reports the test framework of the project containing
+// dir, looking in dir and then its parents for go.mod, pytest configuration
+// (pytest.ini, conftest.py, tox.ini, or pyproject.toml), or a package.json
+// with a test script.
+func DetectTestFramework(dir string) (TestFramework, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if fileExists(filepath.Join(dir, "go.mod")) {
+			return TestFrameworkGo, true
+		}
+		for _, name := range []string{"pytest.ini", "conftest.py", "tox.ini", "pyproject.toml"} {
+			if fileExists(filepath.Join(dir, name)) {
+				return TestFrameworkPytest, true
+			}
+		}
+		if hasNpmTestScript(filepath.Join(dir, "package.json")) {
+			return TestFrameworkNpm, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// hasNpmTestScript reports whether the package.json at path defines a test
+// script other than the placeholder npm init writes.
+func hasNpmTestScript(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return false
+	}
+	script := pkg.Scripts["test"]
+	return script != "" && !strings.Contains(script, "no test specified")
+}
+
+// RunTests runs the tests of the project in dir and parses the output into
+// counts and failures, so a coding agent gets a clean signal instead of raw
+// logs. Failing tests are a normal result. RunTests returns an error only
+// when the tests can't be run, such as when no framework is detected or
+// the tool isn't installed, or when ctx is canceled.
+func RunTests(ctx context.Context, dir string, opts RunTestsOptions) (*TestRunResult, error) {
+	framework := opts.Framework
+	if framework == "" {
+		detected, ok := DetectTestFramework(dir)
+		if !ok {
+			return nil, errors.New("no test framework detected: expected go.mod, pytest configuration, or a package.json test script")
+		}
+		framework = detected
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTestRunnerTimeout
+	}
+	maxOutput := opts.MaxOutputLength
+	if maxOutput <= 0 {
+		maxOutput = DefaultMaxOutputLength
+	}
+
+	var args []string
+	switch framework {
+	case TestFrameworkGo:
+		args = []string{"go", "test", "-json"}
+		if opts.Filter != "" {
+			args = append(args, "-run", opts.Filter)
+		}
+		args = append(args, "./...")
+	case TestFrameworkPytest:
+		args = []string{"pytest"}
+		if _, err := exec.LookPath("pytest"); err != nil {
+			args = []string{"python3", "-m", "pytest"}
+		}
+		args = append(args, "-q", "-rfE", "--tb=short", "--color=no")
+		if opts.Filter != "" {
+			args = append(args, "-k", opts.Filter)
+		}
+	case TestFrameworkNpm:
+		args = []string{"npm", "test", "--silent"}
+		if opts.Filter != "" {
+			args = append(args, "--", opts.Filter)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported test framework %q", framework)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, args[0], args[1:]...)
+	reapProcessGroup(cmd)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CI=true", "NO_COLOR=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if framework != TestFrameworkGo {
+		// Test runners interleave results on both streams
+		cmd.Stderr = &stdout
+	}
+
+	result := &TestRunResult{Framework: framework, Command: strings.Join(args, " "), Dir: dir}
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case runCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil:
+		result.TimedOut = true
+		result.ExitCode = -1
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		return nil, fmt.Errorf("run %s: %w", args[0], err)
+	}
+
+	var misc string
+	switch framework {
+	case TestFrameworkGo:
+		misc = parseGoTestOutput(result, stdout.Bytes()) + stderr.String()
+	case TestFrameworkPytest:
+		parsePytestOutput(result, stdout.String())
+		misc = stdout.String()
+	case TestFrameworkNpm:
+		parseJestOutput(result, stdout.String())
+		misc = stdout.String()
+	}
+
+	budget := maxOutput
+	for _, failure := range result.Failures {
+		failure.Output = strings.TrimSpace(failure.Output)
+		if len(failure.Output) > budget {
+			failure.Output = truncateOutput(failure.Output, budget)
+			result.Truncated = true
+		}
+		budget = max(0, budget-len(failure.Output))
+	}
+	if len(result.Failures) == 0 && (result.ExitCode != 0 || result.TimedOut) {
+		result.Output = tailOutput(strings.TrimSpace(misc), maxOutput)
+	}
+	return result, nil
+}
+
+// tailOutput keeps the last maxLen bytes of output, where errors usually
+// are.
+func tailOutput(output string, maxLen int) string {
+	if len(output) <= maxLen {
+		return output
+	}
+	return "(output truncated) ...\n" + output[len(output)-maxLen:]
+}
+
+// goTestEvent is an event from "go test -json".
+type goTestEvent struct {
+	Action     string
+	Package    string
+	Test       string
+	Output     string
+	ImportPath string
+}
+
+// parseGoTestOutput reads "go test -json" output into result and returns
+// output that isn't attributed to a test, such as build errors. Only leaf
+// tests are counted, so a failing subtest isn't also reported through its
+// parent.
+func parseGoTestOutput(result *TestRunResult, data []byte) string {
+	type testKey struct{ pkg, test string }
+	var order []testKey
+	status := map[testKey]string{}
+	outputs := map[testKey]*strings.Builder{}
+	packageOutput := map[string]*strings.Builder{}
+	packageFailed := map[string]bool{}
+	var misc strings.Builder
+
+	appendTo := func(m map[testKey]*strings.Builder, key testKey, text string) {
+		if m[key] == nil {
+			m[key] = &strings.Builder{}
+		}
+		m[key].WriteString(text)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var event goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			misc.WriteString(scanner.Text() + "\n")
+			continue
+		}
+		key := testKey{event.Package, event.Test}
+		switch event.Action {
+		case "build-output":
+			pkg := strings.Fields(event.ImportPath + " ")[0]
+			if packageOutput[pkg] == nil {
+				packageOutput[pkg] = &strings.Builder{}
+			}
+			packageOutput[pkg].WriteString(event.Output)
+		case "output":
+			if event.Test == "" {
+				if packageOutput[event.Package] == nil {
+					packageOutput[event.Package] = &strings.Builder{}
+				}
+				packageOutput[event.Package].WriteString(event.Output)
+			} else if !strings.HasPrefix(event.Output, "=== ") {
+				appendTo(outputs, key, event.Output)
+			}
+		case "pass", "fail", "skip":
+			if event.Test == "" {
+				packageFailed[event.Package] = event.Action == "fail"
+				continue
+			}
+			if _, seen := status[key]; !seen {
+				order = append(order, key)
+			}
+			status[key] = event.Action
+		}
+	}
+
+	hasChild := map[testKey]bool{}
+	for _, key := range order {
+		if i := strings.LastIndex(key.test, "/"); i >= 0 {
+			hasChild[testKey{key.pkg, key.test[:i]}] = true
+		}
+	}
+	failedPackages := map[string]bool{}
+	for _, key := range order {
+		if hasChild[key] {
+			continue
+		}
+		switch status[key] {
+		case "pass":
+			result.Passed++
+		case "skip":
+			result.Skipped++
+		case "fail":
+			result.Failed++
+			failedPackages[key.pkg] = true
+			failure := &TestFailure{Name: key.test, Package: key.pkg}
+			if out := outputs[key]; out != nil {
+				failure.Output = out.String()
+			}
+			result.Failures = append(result.Failures, failure)
+		}
+	}
+	// Packages that failed without a failing test didn't build or crashed
+	for pkg, failed := range packageFailed {
+		if failed && !failedPackages[pkg] {
+			failure := &TestFailure{Name: pkg, Package: pkg}
+			if out := packageOutput[pkg]; out != nil {
+				failure.Output = out.String()
+			}
+			result.Failures = append(result.Failures, failure)
+		}
+	}
+	return misc.String()
+}
+
+var (
+	testCountPattern     = regexp.MustCompile(`(\d+) (passed|failed|skipped|errors?|xfailed|xpassed|todo)`)
+	pytestSectionPattern = regexp.MustCompile(`^_{3,} (.+?) _{3,}$`)
+	pytestSummaryPattern = regexp.MustCompile(`^(FAILED|ERROR) (\S+)`)
+)
+
+// parsePytestOutput reads pytest output, run with -rfE, into result.
+func parsePytestOutput(result *TestRunResult, output string) {
+	lines := strings.Split(output, "\n")
+	sections := map[string]string{}
+	var current string
+	var body strings.Builder
+	flush := func() {
+		if current != "" {
+			sections[current] = body.String()
+		}
+		current = ""
+		body.Reset()
+	}
+	for _, line := range lines {
+		switch {
+		case pytestSectionPattern.MatchString(line):
+			flush()
+			current = pytestSectionPattern.FindStringSubmatch(line)[1]
+		case strings.HasPrefix(line, "====="):
+			flush()
+		case current != "":
+			body.WriteString(line + "\n")
+		}
+	}
+	flush()
+
+	for _, line := range lines {
+		match := pytestSummaryPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		nodeID := match[2]
+		// Sections are titled by the test name without the file, with
+		// classes joined by dots
+		parts := strings.Split(nodeID, "::")
+		title := strings.Join(parts[1:], ".")
+		if match[1] == "ERROR" {
+			title = "ERROR at setup of " + title
+		}
+		result.Failures = append(result.Failures, &TestFailure{Name: nodeID, Output: sections[title]})
+	}
+	// The final line summarizes the counts, e.g. "1 failed, 2 passed in 0.1s"
+	for i := len(lines) - 1; i >= 0; i-- {
+		if matches := testCountPattern.FindAllStringSubmatch(lines[i], -1); matches != nil {
+			addTestCounts(result, matches)
+			break
+		}
+	}
+}
+
+var (
+	jestFailurePattern   = regexp.MustCompile(`^\s*● (.+)$`)
+	vitestFailurePattern = regexp.MustCompile(`^\s*FAIL\s+(\S+ > .+)$`)
+	jsTestsLinePattern   = regexp.MustCompile(`^\s*Tests:?\s`)
+)
+
+// parseJestOutput reads the output of a Jest or Vitest run into result.
+func parseJestOutput(result *TestRunResult, output string) {
+	var current *TestFailure
+	var body strings.Builder
+	seen := map[string]*TestFailure{}
+	flush := func() {
+		if current != nil {
+			current.Output += body.String()
+		}
+		current = nil
+		body.Reset()
+	}
+	for _, line := range strings.Split(output, "\n") {
+		match := jestFailurePattern.FindStringSubmatch(line)
+		if match == nil {
+			match = vitestFailurePattern.FindStringSubmatch(line)
+		}
+		switch {
+		case match != nil:
+			flush()
+			name := strings.TrimSpace(match[1])
+			current = seen[name]
+			if current == nil {
+				current = &TestFailure{Name: name}
+				seen[name] = current
+				result.Failures = append(result.Failures, current)
+			}
+		case jsTestsLinePattern.MatchString(line):
+			flush()
+			addTestCounts(result, testCountPattern.FindAllStringSubmatch(line, -1))
+		case strings.HasPrefix(strings.TrimSpace(line), "Test Suites:"):
+			flush()
+		case current != nil:
+			body.WriteString(line + "\n")
+		}
+	}
+	flush()
+}
+
+// addTestCounts adds counts matched by testCountPattern to result. Errors
+// count as failures.
+func addTestCounts(result *TestRunResult, matches [][]string) {
+	for _, match := range matches {
+		n, _ := strconv.Atoi(match[1])
+		switch match[2] {
+		case "passed", "xpassed":
+			result.Passed += n
+		case "failed", "error", "errors":
+			result.Failed += n
+		case "skipped", "xfailed", "todo":
+			result.Skipped += n
+		}
+	}
+}
+
+// TestRunnerInput represents the input parameters for the RunTests tool.
+type TestRunnerInput struct {
+	// Path is the directory to run the tests in. Defaults to the workspace.
+	Path string `json:"path,omitempty"`
+
+	// Filter limits the run to matching tests.
+	Filter string `json:"filter,omitempty"`
+
+	// Framework overrides the detected test framework: "go", "pytest", or
+	// "npm".
+	Framework string `json:"framework,omitempty"`
+}
+
+// TestRunnerOptions configures the behavior of [TestRunnerTool].
+type TestRunnerOptions struct {
+	// WorkspaceDir restricts test runs to directories within this path.
+	// Defaults to the current working directory if empty. Ignored when
+	// Validator is set.
+	WorkspaceDir string
+
+	// Validator is an optional shared PathValidator. When set, it is used
+	// instead of creating one from WorkspaceDir.
+	Validator *PathValidator
+
+	// Timeout limits each test run. Defaults to [DefaultTestRunnerTimeout].
+	Timeout time.Duration
+
+	// MaxOutputLength caps the failure output returned to the model.
+	// Defaults to [DefaultMaxOutputLength] (30000 characters).
+	MaxOutputLength int
+}
+
+// TestRunnerTool runs a project's tests and returns structured results:
+// pass, fail, and skip counts, the failing tests with their output, and the
+// command that was run. It detects Go, pytest, and npm projects with
+// [DetectTestFramework] and runs them with [RunTests].
+//
+// Failing tests are returned as a normal result, not a tool error, since
+// they are the information the model asked for. Errors are reserved for
+// runs that couldn't start or timed out.
+//
+// Security: Running tests executes project code. Use workspace restrictions
+// and the agent permission system to control when it runs.
+type TestRunnerTool struct {
+	pathValidator *PathValidator
+	timeout       time.Duration
+	maxOutputLen  int
+	workspaceDir  string
+	configErr     error
+}
+
+// NewTestRunnerTool creates a new TestRunnerTool with the given options.
+func NewTestRunnerTool(opts ...TestRunnerOptions) *dive.TypedToolAdapter[*TestRunnerInput] {
+	var resolvedOpts TestRunnerOptions
+	if len(opts) > 0 {
+		resolvedOpts = opts[0]
+	}
+	if resolvedOpts.Timeout <= 0 {
+		resolvedOpts.Timeout = DefaultTestRunnerTimeout
+	}
+	if resolvedOpts.MaxOutputLength <= 0 {
+		resolvedOpts.MaxOutputLength = DefaultMaxOutputLength
+	}
+
+	pathValidator := resolvedOpts.Validator
+	var configErr error
+	if pathValidator == nil {
+		pathValidator, configErr = NewPathValidator(resolvedOpts.WorkspaceDir)
+		if configErr != nil {
+			configErr = fmt.Errorf("invalid workspace configuration for WorkspaceDir %q: %w", resolvedOpts.WorkspaceDir, configErr)
+		}
+	}
+
+	return dive.ToolAdapter(&TestRunnerTool{
+		pathValidator: pathValidator,
+		timeout:       resolvedOpts.Timeout,
+		maxOutputLen:  resolvedOpts.MaxOutputLength,
+		workspaceDir:  resolvedOpts.WorkspaceDir,
+		configErr:     configErr,
+	})
+}
+
+// Name returns "RunTests" as the tool identifier.
+func (t *TestRunnerTool) Name() string {
+	return "RunTests"
+}
+
+// Description returns usage instructions for the LLM.
+func (t *TestRunnerTool) Description() string {
+	return fmt.Sprintf(`Run the project's tests and get structured results instead of raw logs: pass, fail, and skip counts, each failing test with its failure output, and the command that was run.
+
+Go (go test), pytest, and npm test projects are detected automatically. Use filter to run only matching tests: a -run pattern for Go, a -k expression for pytest, or arguments for the npm test script. Prefer this tool over Bash for running tests. Runs time out after %s.`, t.timeout)
+}
+
+// Schema returns the JSON schema describing the tool's input parameters.
+func (t *TestRunnerTool) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type: "object",
+		Properties: map[string]*schema.Property{
+			"path": {
+				Type:        "string",
+				Description: "Directory to run the tests in. Defaults to the workspace root.",
+			},
+			"filter": {
+				Type:        "string",
+				Description: "Run only matching tests.",
+			},
+			"framework": {
+				Type:        "string",
+				Description: "Test framework to use instead of the detected one.",
+				Enum:        []any{string(TestFrameworkGo), string(TestFrameworkPytest), string(TestFrameworkNpm)},
+			},
+		},
+	}
+}
+
+// Annotations returns metadata hints about the tool's behavior. Running
+// tests executes project code, so it isn't marked read-only.
+func (t *TestRunnerTool) Annotations() *dive.ToolAnnotations {
+	return &dive.ToolAnnotations{
+		Title:           "RunTests",
+		ReadOnlyHint:    false,
+		IdempotentHint:  true,
+		DestructiveHint: false,
+		OpenWorldHint:   false,
+	}
+}
+
+// PreviewCall returns a summary of the test run for permission prompts.
+func (t *TestRunnerTool) PreviewCall(ctx context.Context, input *TestRunnerInput) *dive.ToolCallPreview {
+	summary := "Run tests"
+	if input.Path != "" {
+		summary += " in " + input.Path
+	}
+	if input.Filter != "" {
+		summary += fmt.Sprintf(" matching %q", input.Filter)
+	}
+	return &dive.ToolCallPreview{Summary: summary}
+}
+
+// Call runs the tests and returns the TestRunResult as JSON.
+func (t *TestRunnerTool) Call(ctx context.Context, input *TestRunnerInput) (*dive.ToolResult, error) {
+	if t.configErr != nil {
+		return NewToolResultError(fmt.Sprintf("error: %s", t.configErr.Error())), nil
+	}
+	dir := t.pathValidator.WorkspaceDir
+	if input.Path != "" {
+		dir = input.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(t.pathValidator.WorkspaceDir, dir)
+		}
+	}
+	if err := t.pathValidator.ValidateRead(dir); err != nil {
+		return NewToolResultError(fmt.Sprintf("error: %s", err.Error())), nil
+	}
+
+	result, err := RunTests(ctx, dir, RunTestsOptions{
+		Framework:       TestFramework(input.Framework),
+		Filter:          input.Filter,
+		Timeout:         t.timeout,
+		MaxOutputLength: t.maxOutputLen,
+	})
+	if err != nil {
+		return NewToolResultError(fmt.Sprintf("error: %s", err.Error())), nil
+	}
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return NewToolResultError(fmt.Sprintf("error marshaling result: %s", err.Error())), nil
+	}
+	display := fmt.Sprintf("Tests: %d passed, %d failed", result.Passed, result.Failed)
+	if result.Skipped > 0 {
+		display += fmt.Sprintf(", %d skipped", result.Skipped)
+	}
+	if result.TimedOut {
+		return NewToolResultError(string(resultJSON)).WithDisplay(fmt.Sprintf("Tests timed out after %s", t.timeout)), nil
+	}
+	if result.Failed == 0 && result.ExitCode != 0 {
+		display = fmt.Sprintf("Tests failed to run (exit %d)", result.ExitCode)
+	}
+	return NewToolResultText(string(resultJSON)).WithDisplay(display), nil
+}
@@ -0,0 +1,221 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func writeRunnerFile(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestDetectTestFramework(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  TestFramework
+		found bool
+	}{
+		{"go module", map[string]string{"go.mod": "module example"}, TestFrameworkGo, true},
+		{"pytest", map[string]string{"pyproject.toml": "[tool.pytest.ini_options]"}, TestFrameworkPytest, true},
+		{"conftest", map[string]string{"conftest.py": ""}, TestFrameworkPytest, true},
+		{"npm", map[string]string{"package.json": `{"scripts": {"test": "jest"}}`}, TestFrameworkNpm, true},
+		{"npm placeholder", map[string]string{"package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`}, "", false},
+		{"empty", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeRunnerFile(t, filepath.Join(dir, name), content)
+			}
+			got, found := DetectTestFramework(dir)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// Detection walks up from subdirectories
+	dir := t.TempDir()
+	writeRunnerFile(t, filepath.Join(dir, "go.mod"), "module example")
+	got, found := DetectTestFramework(filepath.Join(dir, "internal", "pkg"))
+	assert.True(t, found)
+	assert.Equal(t, TestFrameworkGo, got)
+}
+
+func TestRunTestsGo(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	writeRunnerFile(t, filepath.Join(dir, "go.mod"), "module example\n\ngo 1.21\n")
+	writeRunnerFile(t, filepath.Join(dir, "math_test.go"), `package example
+
+import "testing"
+
+func TestAdd(t *testing.T) {}
+
+func TestSkip(t *testing.T) { t.Skip("later") }
+
+func TestTable(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {})
+	t.Run("bad", func(t *testing.T) { t.Errorf("got 3, want 4") })
+}
+`)
+	writeRunnerFile(t, filepath.Join(dir, "broken", "broken_test.go"), "package broken\n\nfunc TestBroken(t *testing.T) { undefined() }\n")
+
+	result, err := RunTests(context.Background(), dir, RunTestsOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, TestFrameworkGo, result.Framework)
+	assert.Equal(t, "go test -json ./...", result.Command)
+	assert.Equal(t, 2, result.Passed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+	assert.NotEqual(t, 0, result.ExitCode)
+	assert.Len(t, result.Failures, 2)
+	assert.Equal(t, "TestTable/bad", result.Failures[0].Name)
+	assert.Equal(t, "example", result.Failures[0].Package)
+	assert.Contains(t, result.Failures[0].Output, "got 3, want 4")
+	assert.Equal(t, "example/broken", result.Failures[1].Name)
+	assert.Contains(t, result.Failures[1].Output, "undefined")
+
+	filtered, err := RunTests(context.Background(), dir, RunTestsOptions{Filter: "TestAdd"})
+	assert.NoError(t, err)
+	assert.Equal(t, "go test -json -run TestAdd ./...", filtered.Command)
+	assert.Equal(t, 1, filtered.Passed)
+}
+
+func TestRunTestsTimeout(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	writeRunnerFile(t, filepath.Join(dir, "go.mod"), "module example\n\ngo 1.21\n")
+	writeRunnerFile(t, filepath.Join(dir, "slow_test.go"), `package example
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlow(t *testing.T) { time.Sleep(time.Minute) }
+`)
+	result, err := RunTests(context.Background(), dir, RunTestsOptions{Timeout: 200 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, -1, result.ExitCode)
+}
+
+func TestParsePytestOutput(t *testing.T) {
+	output := `..F.s
+==================================== ERRORS ====================================
+___________________________ ERROR at setup of test_db __________________________
+E   fixture 'database' not found
+=================================== FAILURES ===================================
+__________________________ TestLogin.test_bad_password _________________________
+tests/test_auth.py:12: in test_bad_password
+    assert resp.status == 401
+E   assert 200 == 401
+=========================== short test summary info ============================
+FAILED tests/test_auth.py::TestLogin::test_bad_password - assert 200 == 401
+ERROR tests/test_db.py::test_db
+1 failed, 3 passed, 1 skipped, 1 error in 0.12s
+`
+	result := &TestRunResult{}
+	parsePytestOutput(result, output)
+	assert.Equal(t, 3, result.Passed)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Len(t, result.Failures, 2)
+	assert.Equal(t, "tests/test_auth.py::TestLogin::test_bad_password", result.Failures[0].Name)
+	assert.Contains(t, result.Failures[0].Output, "assert 200 == 401")
+	assert.Equal(t, "tests/test_db.py::test_db", result.Failures[1].Name)
+	assert.Contains(t, result.Failures[1].Output, "fixture 'database' not found")
+}
+
+func TestParseJestOutput(t *testing.T) {
+	output := `FAIL src/math.test.js
+  math
+    ✓ adds (2 ms)
+    ✕ divides (3 ms)
+
+  ● math › divides
+
+    expect(received).toBe(expected)
+
+    Expected: 2
+    Received: 3
+
+Test Suites: 1 failed, 1 total
+Tests:       1 failed, 4 passed, 1 skipped, 6 total
+`
+	result := &TestRunResult{}
+	parseJestOutput(result, output)
+	assert.Equal(t, 4, result.Passed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Len(t, result.Failures, 1)
+	assert.Equal(t, "math › divides", result.Failures[0].Name)
+	assert.Contains(t, result.Failures[0].Output, "Received: 3")
+
+	vitest := ` FAIL  src/math.test.ts > math > divides
+AssertionError: expected 3 to be 2
+ Test Files  1 failed (1)
+      Tests  1 failed | 2 passed (3)
+`
+	result = &TestRunResult{}
+	parseJestOutput(result, vitest)
+	assert.Equal(t, 2, result.Passed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Len(t, result.Failures, 1)
+	assert.Equal(t, "src/math.test.ts > math > divides", result.Failures[0].Name)
+	assert.Contains(t, result.Failures[0].Output, "expected 3 to be 2")
+}
+
+func TestTestRunnerTool(t *testing.T) {
+	tool := NewTestRunnerTool(TestRunnerOptions{WorkspaceDir: t.TempDir()})
+	assert.Equal(t, "RunTests", tool.Name())
+	assert.False(t, tool.Annotations().ReadOnlyHint)
+	assert.Contains(t, tool.Schema().Properties, "filter")
+
+	// A workspace without a test framework is a tool error
+	result, err := tool.Call(context.Background(), &TestRunnerInput{})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "no test framework detected")
+
+	// Paths outside the workspace are rejected
+	result, err = tool.Call(context.Background(), &TestRunnerInput{Path: "/"})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestTestRunnerToolResult(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	writeRunnerFile(t, filepath.Join(dir, "go.mod"), "module example\n\ngo 1.21\n")
+	writeRunnerFile(t, filepath.Join(dir, "pkg", "a_test.go"), "package pkg\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) { t.Fatal(\"boom\") }\n")
+
+	tool := NewTestRunnerTool(TestRunnerOptions{WorkspaceDir: dir})
+	result, err := tool.Call(context.Background(), &TestRunnerInput{Path: "pkg"})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Tests: 0 passed, 1 failed", result.Display)
+
+	var run TestRunResult
+	assert.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &run))
+	assert.Equal(t, filepath.Join(dir, "pkg"), run.Dir)
+	assert.Equal(t, "TestA", run.Failures[0].Name)
+	assert.Contains(t, run.Failures[0].Output, "boom")
+}
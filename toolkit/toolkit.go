@@ -10,20 +10,33 @@
 //   - [ReadFileTool]: Read file contents with optional line range
 //   - [WriteFileTool]: Write content to files
 //   - [EditTool]: Perform exact string replacements in files
+//   - [MultiEditTool]: Apply several replacements to one file atomically
+//   - [ApplyPatchTool]: Apply a unified diff to files in the workspace
 //   - [GlobTool]: Find files using glob patterns
 //   - [GrepTool]: Search file contents using regular expressions
 //   - [ListDirectoryTool]: List directory contents with metadata
+//   - [WorkspaceInfoTool]: Summarize OS, git state, and project layout
 //   - [TextEditorTool]: Advanced file editor (Anthropic-compatible)
 //
 // Shell Execution:
 //   - [BashTool]: Execute shell commands with timeout and output capture
+//   - [TestRunnerTool]: Run go test, pytest, or npm test with structured results
 //
 // Web Operations:
 //   - [FetchTool]: Fetch and extract content from web pages
+//   - [HTTPTool]: Send HTTP requests to APIs, restricted to allowed hosts
 //   - [WebSearchTool]: Search the web using a configured search provider
 //
 // User Interaction:
 //   - [AskUserTool]: Ask users questions with various input types
+//   - [StatusTool]: Show the user ephemeral status lines during long runs
+//
+// # Secret Redaction
+//
+// [SecretRedactor] strips API keys, bearer tokens, private keys, and other
+// credentials from tool outputs before they reach the model. It is a
+// [dive.EarlyHooksExtension]; add it to AgentOptions.Extensions to cover
+// every tool, ahead of other hooks.
 //
 // # Path Validation
 //
@@ -1,22 +1,59 @@
 package toolkit
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/dive/llm"
 	"github.com/deepnoodle-ai/wonton/schema"
 	"github.com/deepnoodle-ai/wonton/web"
 )
 
 var _ dive.TypedTool[*SearchInput] = &WebSearchTool{}
 
+const (
+	// DefaultWebSearchMaxResults is the default maximum number of results a
+	// WebSearchTool returns per search.
+	DefaultWebSearchMaxResults = 30
+
+	// DefaultWebSearchCacheSize is the default number of searches a
+	// WebSearchTool's cache holds.
+	DefaultWebSearchCacheSize = 100
+
+	// defaultWebSearchLimit is the number of results requested when the
+	// LLM gives no limit.
+	defaultWebSearchLimit = 10
+)
+
 // WebSearchToolOptions configures the behavior of [WebSearchTool].
 type WebSearchToolOptions struct {
 	// Searcher is the underlying search implementation (e.g., Google, Kagi).
 	// Required - the tool will fail at call time if not provided.
 	Searcher web.Searcher
+
+	// CacheTTL enables an in-memory cache of search results for this long,
+	// so repeating a query doesn't call the search API again. Queries are
+	// compared ignoring case and extra whitespace, and the cache key covers
+	// the whole search input, so searches with different limits are cached
+	// apart. The cache belongs to the tool, so a tool whose searcher is set
+	// up for one locale or region never returns another's results. Zero
+	// disables caching. Expiry follows the clock from [dive.ClockFromContext].
+	CacheTTL time.Duration
+
+	// CacheSize caps the number of searches the cache holds, evicting the
+	// least recently used. Defaults to [DefaultWebSearchCacheSize].
+	CacheSize int
+
+	// MaxResults caps the number of results per search, whatever limit the
+	// LLM asks for. Defaults to [DefaultWebSearchMaxResults].
+	MaxResults int
 }
 
 // SearchInput represents the input parameters for the WebSearch tool.
@@ -37,19 +74,50 @@ type SearchInput struct {
 //
 // Features:
 //   - Configurable search provider (Google, Kagi, etc.)
-//   - Result limit control (10-30 results)
+//   - Result limit control (10-30 results by default)
 //   - JSON output with URL, title, and description per result
+//   - Optional result caching for repeated queries
+//   - Results already returned earlier in the same agent response are
+//     dropped, so repeated searches only add new pages
 //
 // The tool requires a [web.Searcher] implementation to be provided
 // via options. Without a searcher, the tool cannot function.
 type WebSearchTool struct {
-	searcher web.Searcher
+	searcher   web.Searcher
+	maxResults int
+	cacheTTL   time.Duration
+	cacheSize  int
+
+	mu         sync.Mutex
+	cache      map[string]*list.Element
+	cacheOrder *list.List // front is most recently used
+}
+
+type searchCacheEntry struct {
+	key     string
+	items   []web.SearchItem
+	expires time.Time
+}
+
+// seenSearchResultsKey keys the URLs returned so far in a
+// [dive.ResponseScope]. It is shared by all WebSearchTools, so searches
+// with different tools in one response are deduplicated too.
+type seenSearchResultsKey struct{}
+
+// seenSearchResults is the set of normalized result URLs returned so far
+// in one response.
+type seenSearchResults struct {
+	mu   sync.Mutex
+	urls map[string]bool
 }
 
 // NewWebSearchTool creates a new WebSearchTool with the given options.
 func NewWebSearchTool(options WebSearchToolOptions) *dive.TypedToolAdapter[*SearchInput] {
 	return dive.ToolAdapter(&WebSearchTool{
-		searcher: options.Searcher,
+		searcher:   options.Searcher,
+		maxResults: options.MaxResults,
+		cacheTTL:   options.CacheTTL,
+		cacheSize:  options.CacheSize,
 	})
 }
 
@@ -75,7 +143,7 @@ func (t *WebSearchTool) Schema() *schema.Schema {
 			},
 			"limit": {
 				Type:        "number",
-				Description: "The maximum number of results to return (Default: 10, Min: 10, Max: 30)",
+				Description: fmt.Sprintf("The maximum number of results to return (Default: %d, Max: %d)", t.defaultLimit(), t.maxLimit()),
 			},
 		},
 	}
@@ -85,33 +153,183 @@ func (t *WebSearchTool) Schema() *schema.Schema {
 //
 // Results include the URL, title, and description for each matching
 // web page. If no results are found, an error result is returned.
+// Within an agent response, results whose URLs an earlier search already
+// returned are left out.
 func (t *WebSearchTool) Call(ctx context.Context, input *SearchInput) (*dive.ToolResult, error) {
 	limit := input.Limit
 	if limit <= 0 {
-		limit = 10
+		limit = t.defaultLimit()
 	}
-	if limit > 30 {
-		limit = 30
+	if limit > t.maxLimit() {
+		limit = t.maxLimit()
 	}
-	results, err := t.searcher.Search(ctx, &web.SearchInput{
+	items, err := t.search(ctx, &web.SearchInput{
 		Query: input.Query,
 		Limit: limit,
 	})
 	if err != nil {
 		return nil, err
 	}
-	if len(results.Items) == 0 {
+	if len(items) == 0 {
 		return NewToolResultError("No search results found"), nil
 	}
-	if len(results.Items) > limit {
-		results.Items = results.Items[:limit]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	found := len(items)
+	items = dropSeenResults(ctx, items)
+	if len(items) == 0 {
+		display := fmt.Sprintf("Found %d results for %q, all returned by earlier searches", found, input.Query)
+		return NewToolResultText(fmt.Sprintf("All %d results for this query were already returned by earlier searches.", found)).WithDisplay(display), nil
 	}
-	data, err := json.Marshal(results.Items)
+	data, err := json.Marshal(items)
 	if err != nil {
 		return nil, err
 	}
-	display := fmt.Sprintf("Found %d results for %q", len(results.Items), input.Query)
-	return NewToolResultText(string(data)).WithDisplay(display), nil
+	sources := make([]*llm.CitationContent, 0, len(items))
+	for _, item := range items {
+		sources = append(sources, &llm.CitationContent{
+			Type:    string(llm.CitationTypeContent),
+			Title:   item.Title,
+			URL:     item.URL,
+			Snippet: item.Description,
+		})
+	}
+	display := fmt.Sprintf("Found %d results for %q", len(items), input.Query)
+	if len(items) < found {
+		display += fmt.Sprintf(" (%d already returned)", found-len(items))
+	}
+	return NewToolResultText(string(data)).WithDisplay(display).WithSources(sources...), nil
+}
+
+func (t *WebSearchTool) maxLimit() int {
+	if t.maxResults > 0 {
+		return t.maxResults
+	}
+	return DefaultWebSearchMaxResults
+}
+
+func (t *WebSearchTool) defaultLimit() int {
+	return min(defaultWebSearchLimit, t.maxLimit())
+}
+
+func (t *WebSearchTool) maxCacheSize() int {
+	if t.cacheSize > 0 {
+		return t.cacheSize
+	}
+	return DefaultWebSearchCacheSize
+}
+
+// search runs the search, or returns the cached results of an identical
+// one when caching is enabled. Errors are never cached.
+func (t *WebSearchTool) search(ctx context.Context, input *web.SearchInput) ([]web.SearchItem, error) {
+	if t.cacheTTL <= 0 {
+		output, err := t.searcher.Search(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return output.Items, nil
+	}
+
+	keyInput := *input
+	keyInput.Query = strings.ToLower(strings.Join(strings.Fields(input.Query), " "))
+	key, err := json.Marshal(keyInput)
+	if err != nil {
+		return nil, err
+	}
+	now := dive.ClockFromContext(ctx).Now()
+	if items, ok := t.cachedSearch(string(key), now); ok {
+		return items, nil
+	}
+
+	output, err := t.searcher.Search(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	t.cacheSearch(string(key), output.Items, now.Add(t.cacheTTL))
+	return output.Items, nil
+}
+
+// cachedSearch returns the cached results under key, unless they expired
+// by now.
+func (t *WebSearchTool) cachedSearch(key string, now time.Time) ([]web.SearchItem, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	element, ok := t.cache[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*searchCacheEntry)
+	if !now.Before(entry.expires) {
+		t.cacheOrder.Remove(element)
+		delete(t.cache, key)
+		return nil, false
+	}
+	t.cacheOrder.MoveToFront(element)
+	return entry.items, true
+}
+
+// cacheSearch stores results under key, evicting the least recently used
+// search if the cache is full.
+func (t *WebSearchTool) cacheSearch(key string, items []web.SearchItem, expires time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cache == nil {
+		t.cache = make(map[string]*list.Element)
+		t.cacheOrder = list.New()
+	}
+	entry := &searchCacheEntry{key: key, items: items, expires: expires}
+	if element, ok := t.cache[key]; ok {
+		element.Value = entry
+		t.cacheOrder.MoveToFront(element)
+		return
+	}
+	t.cache[key] = t.cacheOrder.PushFront(entry)
+	for t.cacheOrder.Len() > t.maxCacheSize() {
+		oldest := t.cacheOrder.Back()
+		t.cacheOrder.Remove(oldest)
+		delete(t.cache, oldest.Value.(*searchCacheEntry).key)
+	}
+}
+
+// dropSeenResults removes the items whose URLs were returned earlier in
+// the response ctx belongs to, and records the rest as returned. Outside
+// an agent response, items are returned unchanged.
+func dropSeenResults(ctx context.Context, items []web.SearchItem) []web.SearchItem {
+	scope := dive.ResponseScopeFrom(ctx)
+	if scope == nil {
+		return items
+	}
+	seen := scope.LoadOrStore(seenSearchResultsKey{}, &seenSearchResults{urls: map[string]bool{}}).(*seenSearchResults)
+	seen.mu.Lock()
+	defer seen.mu.Unlock()
+	kept := make([]web.SearchItem, 0, len(items))
+	for _, item := range items {
+		key := normalizeResultURL(item.URL)
+		if seen.urls[key] {
+			continue
+		}
+		seen.urls[key] = true
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+// normalizeResultURL returns a key for rawURL under which equivalent URLs,
+// differing only in the case of the host, a fragment, or a trailing slash,
+// compare equal.
+func normalizeResultURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String()
 }
 
 // Annotations returns metadata hints about the tool's behavior.
@@ -3,7 +3,9 @@ package toolkit
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/deepnoodle-ai/dive"
 	"github.com/deepnoodle-ai/wonton/assert"
 	"github.com/deepnoodle-ai/wonton/web"
 )
@@ -141,3 +143,127 @@ func TestWebSearchTool_NoResults(t *testing.T) {
 	assert.True(t, result.IsError)
 	assert.Contains(t, result.Content[0].Text, "No search results found")
 }
+
+func TestWebSearchTool_Sources(t *testing.T) {
+	tool := &WebSearchTool{searcher: &mockSearcher{itemCount: 2}}
+	result, err := tool.Call(context.Background(), &SearchInput{Query: "test query"})
+	assert.NoError(t, err)
+	assert.Len(t, result.Sources, 2)
+	assert.Equal(t, "https://example.com", result.Sources[0].URL)
+	assert.Equal(t, "Test Result", result.Sources[0].Title)
+	assert.Equal(t, "Test description", result.Sources[0].Snippet)
+}
+
+// urlSearcher returns one result per URL and counts its calls.
+type urlSearcher struct {
+	urls  []string
+	calls int
+}
+
+func (s *urlSearcher) Search(ctx context.Context, input *web.SearchInput) (*web.SearchOutput, error) {
+	s.calls++
+	output := &web.SearchOutput{}
+	for _, u := range s.urls {
+		output.Items = append(output.Items, web.SearchItem{URL: u, Title: u})
+	}
+	return output, nil
+}
+
+func TestWebSearchTool_Cache(t *testing.T) {
+	searcher := &urlSearcher{urls: []string{"https://example.com/a"}}
+	tool := &WebSearchTool{searcher: searcher, cacheTTL: time.Minute}
+	clock := dive.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	ctx := dive.WithClock(context.Background(), clock)
+
+	_, err := tool.Call(ctx, &SearchInput{Query: "Go generics"})
+	assert.NoError(t, err)
+	result, err := tool.Call(ctx, &SearchInput{Query: "  go   GENERICS "})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, searcher.calls)
+	assert.Len(t, result.Sources, 1)
+
+	// A different limit is a different search
+	_, err = tool.Call(ctx, &SearchInput{Query: "go generics", Limit: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, searcher.calls)
+
+	// Expired entries are searched again
+	clock.Advance(time.Minute)
+	_, err = tool.Call(ctx, &SearchInput{Query: "go generics"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, searcher.calls)
+}
+
+func TestWebSearchTool_CacheSize(t *testing.T) {
+	searcher := &urlSearcher{urls: []string{"https://example.com/a"}}
+	tool := &WebSearchTool{searcher: searcher, cacheTTL: time.Minute, cacheSize: 2}
+	ctx := context.Background()
+	search := func(query string) {
+		t.Helper()
+		_, err := tool.Call(ctx, &SearchInput{Query: query})
+		assert.NoError(t, err)
+	}
+
+	search("a")
+	search("b")
+	search("a") // a is now more recently used than b
+	search("c") // evicts b
+	assert.Equal(t, 3, searcher.calls)
+	assert.Len(t, tool.cache, 2)
+
+	search("a")
+	assert.Equal(t, 3, searcher.calls)
+	search("b")
+	assert.Equal(t, 4, searcher.calls)
+}
+
+func TestWebSearchTool_NoCacheByDefault(t *testing.T) {
+	searcher := &urlSearcher{urls: []string{"https://example.com/a"}}
+	tool := &WebSearchTool{searcher: searcher}
+	for range 2 {
+		_, err := tool.Call(context.Background(), &SearchInput{Query: "go"})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, searcher.calls)
+}
+
+func TestWebSearchTool_MaxResults(t *testing.T) {
+	searcher := &mockSearcher{itemCount: 5}
+	tool := &WebSearchTool{searcher: searcher, maxResults: 5}
+
+	_, err := tool.Call(context.Background(), &SearchInput{Query: "go", Limit: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, searcher.receivedLimit)
+
+	_, err = tool.Call(context.Background(), &SearchInput{Query: "go"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, searcher.receivedLimit)
+}
+
+func TestWebSearchTool_DeduplicatesWithinResponse(t *testing.T) {
+	searcher := &urlSearcher{urls: []string{"https://example.com/a", "https://Example.com/b/#intro"}}
+	tool := &WebSearchTool{searcher: searcher}
+	ctx := dive.WithResponseScope(context.Background(), &dive.ResponseScope{})
+
+	first, err := tool.Call(ctx, &SearchInput{Query: "first"})
+	assert.NoError(t, err)
+	assert.Len(t, first.Sources, 2)
+
+	searcher.urls = []string{"https://example.com/b", "https://example.com/c"}
+	second, err := tool.Call(ctx, &SearchInput{Query: "second"})
+	assert.NoError(t, err)
+	assert.Len(t, second.Sources, 1)
+	assert.Equal(t, "https://example.com/c", second.Sources[0].URL)
+	assert.Contains(t, second.Display, "1 already returned")
+
+	third, err := tool.Call(ctx, &SearchInput{Query: "third"})
+	assert.NoError(t, err)
+	assert.False(t, third.IsError)
+	assert.Len(t, third.Sources, 0)
+	assert.Contains(t, third.Content[0].Text, "already returned by earlier searches")
+
+	// A new response starts with no results seen
+	fresh, err := tool.Call(dive.WithResponseScope(context.Background(), &dive.ResponseScope{}), &SearchInput{Query: "third"})
+	assert.NoError(t, err)
+	assert.Len(t, fresh.Sources, 2)
+}
@@ -0,0 +1,294 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/dive"
+	"github.com/deepnoodle-ai/wonton/schema"
+)
+
+var _ dive.TypedTool[*WorkspaceInfoInput] = &WorkspaceInfoTool{}
+
+// DefaultWorkspaceInfoMaxEntries is the default limit on top-level entries
+// included in the workspace listing.
+const DefaultWorkspaceInfoMaxEntries = 100
+
+// workspaceGitTimeout bounds each git command so a slow or hung repository
+// never stalls the tool.
+const workspaceGitTimeout = 5 * time.Second
+
+// WorkspaceInfoInput represents the input parameters for the WorkspaceInfo tool.
+type WorkspaceInfoInput struct {
+	// Refresh discards the cached snapshot and re-collects workspace info.
+	Refresh bool `json:"refresh,omitempty"`
+}
+
+// WorkspaceInfo is the structured snapshot returned by [WorkspaceInfoTool].
+type WorkspaceInfo struct {
+	// WorkspaceDir is the absolute path of the workspace.
+	WorkspaceDir string `json:"workspace_dir"`
+
+	// OS and Arch are the runtime operating system and architecture.
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+
+	// Shell is the user's shell from $SHELL, if set.
+	Shell string `json:"shell,omitempty"`
+
+	// Git describes the repository state. Nil if the workspace is not a git
+	// repository or git is unavailable.
+	Git *WorkspaceGitInfo `json:"git,omitempty"`
+
+	// Languages lists languages detected from well-known build files.
+	Languages []string `json:"languages,omitempty"`
+
+	// BuildFiles lists well-known build and manifest files found at the
+	// workspace root.
+	BuildFiles []string `json:"build_files,omitempty"`
+
+	// Entries lists top-level entries. Directories have a trailing slash.
+	Entries []string `json:"entries"`
+
+	// Truncated is true if Entries was limited to MaxEntries.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// WorkspaceGitInfo describes the git state of the workspace.
+type WorkspaceGitInfo struct {
+	// Branch is the current branch, or "HEAD" when detached.
+	Branch string `json:"branch"`
+
+	// Dirty is true if the working tree has uncommitted changes.
+	Dirty bool `json:"dirty"`
+}
+
+// WorkspaceInfoToolOptions configures the behavior of [WorkspaceInfoTool].
+type WorkspaceInfoToolOptions struct {
+	// WorkspaceDir is the directory to describe. Defaults to the current
+	// working directory.
+	WorkspaceDir string
+
+	// MaxEntries limits the number of top-level entries returned.
+	// Defaults to [DefaultWorkspaceInfoMaxEntries] (100).
+	MaxEntries int
+}
+
+// WorkspaceInfoTool reports orienting information about the workspace: OS and
+// architecture, git branch and dirty status, detected languages and build
+// files, and the top-level directory listing.
+//
+// The snapshot is collected on first use and cached for the lifetime of the
+// tool, so repeated calls are cheap. Pass refresh to re-collect it.
+type WorkspaceInfoTool struct {
+	workspaceDir string
+	maxEntries   int
+
+	mutex  sync.Mutex
+	cached *WorkspaceInfo
+}
+
+// NewWorkspaceInfoTool creates a new WorkspaceInfoTool with the given options.
+func NewWorkspaceInfoTool(opts ...WorkspaceInfoToolOptions) *dive.TypedToolAdapter[*WorkspaceInfoInput] {
+	var options WorkspaceInfoToolOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.MaxEntries == 0 {
+		options.MaxEntries = DefaultWorkspaceInfoMaxEntries
+	}
+	return dive.ToolAdapter(&WorkspaceInfoTool{
+		workspaceDir: options.WorkspaceDir,
+		maxEntries:   options.MaxEntries,
+	})
+}
+
+// Name returns "WorkspaceInfo" as the tool identifier.
+func (t *WorkspaceInfoTool) Name() string {
+	return "WorkspaceInfo"
+}
+
+// Description returns usage instructions for the LLM.
+func (t *WorkspaceInfoTool) Description() string {
+	return "Returns orienting information about the workspace in one call: OS and architecture, shell, git branch and whether there are uncommitted changes, languages and build files detected at the root, and the top-level directory listing. Use this at the start of a task instead of several separate commands."
+}
+
+// Schema returns the JSON schema describing the tool's input parameters.
+func (t *WorkspaceInfoTool) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type: "object",
+		Properties: map[string]*schema.Property{
+			"refresh": {
+				Type:        "boolean",
+				Description: "Re-collect the information instead of returning the cached snapshot. Use after making changes such as switching branches.",
+			},
+		},
+	}
+}
+
+// Annotations returns metadata hints about the tool's behavior.
+// WorkspaceInfo is marked as read-only and idempotent.
+func (t *WorkspaceInfoTool) Annotations() *dive.ToolAnnotations {
+	return &dive.ToolAnnotations{
+		Title:           "WorkspaceInfo",
+		ReadOnlyHint:    true,
+		DestructiveHint: false,
+		IdempotentHint:  true,
+		OpenWorldHint:   false,
+	}
+}
+
+// CachePolicy opts out of dive.ToolCache: the tool keeps its own snapshot,
+// and a refresh request must bypass it.
+func (t *WorkspaceInfoTool) CachePolicy(input *WorkspaceInfoInput) dive.ToolCachePolicy {
+	return dive.ToolCachePolicy{Disabled: true}
+}
+
+// Call returns the workspace snapshot as JSON.
+func (t *WorkspaceInfoTool) Call(ctx context.Context, input *WorkspaceInfoInput) (*dive.ToolResult, error) {
+	info, err := t.snapshot(ctx, input.Refresh)
+	if err != nil {
+		return NewToolResultError(fmt.Sprintf("Error: %s", err.Error())), nil
+	}
+	jsonResult, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return NewToolResultError(fmt.Sprintf("Failed to format workspace info. %s", err.Error())), nil
+	}
+	display := fmt.Sprintf("Workspace %s", info.WorkspaceDir)
+	if info.Git != nil {
+		display += fmt.Sprintf(" on %s", info.Git.Branch)
+	}
+	return NewToolResultText(string(jsonResult)).WithDisplay(display), nil
+}
+
+// snapshot returns the cached workspace info, collecting it if needed.
+func (t *WorkspaceInfoTool) snapshot(ctx context.Context, refresh bool) (*WorkspaceInfo, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.cached != nil && !refresh {
+		return t.cached, nil
+	}
+	info, err := t.collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.cached = info
+	return info, nil
+}
+
+func (t *WorkspaceInfoTool) collect(ctx context.Context) (*WorkspaceInfo, error) {
+	dir := t.workspaceDir
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine working directory: %w", err)
+		}
+		dir = wd
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve workspace directory: %w", err)
+	}
+	entries, err := os.ReadDir(absDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace directory %s: %w", absDir, err)
+	}
+
+	info := &WorkspaceInfo{
+		WorkspaceDir: absDir,
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		Shell:        os.Getenv("SHELL"),
+		Git:          workspaceGitInfo(ctx, absDir),
+		Entries:      []string{},
+	}
+
+	languages := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() {
+			if language, ok := workspaceBuildFiles[name]; ok {
+				info.BuildFiles = append(info.BuildFiles, name)
+				if language != "" {
+					languages[language] = true
+				}
+			}
+		}
+		if len(info.Entries) >= t.maxEntries {
+			info.Truncated = true
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		info.Entries = append(info.Entries, name)
+	}
+	for language := range languages {
+		info.Languages = append(info.Languages, language)
+	}
+	sort.Strings(info.Languages)
+	return info, nil
+}
+
+// workspaceBuildFiles maps well-known root-level build and manifest files to
+// the language they indicate, or "" for language-neutral files.
+var workspaceBuildFiles = map[string]string{
+	"go.mod":           "Go",
+	"package.json":     "JavaScript/TypeScript",
+	"tsconfig.json":    "JavaScript/TypeScript",
+	"pyproject.toml":   "Python",
+	"setup.py":         "Python",
+	"requirements.txt": "Python",
+	"Pipfile":          "Python",
+	"Cargo.toml":       "Rust",
+	"pom.xml":          "Java",
+	"build.gradle":     "Java/Kotlin",
+	"build.gradle.kts": "Kotlin",
+	"Gemfile":          "Ruby",
+	"composer.json":    "PHP",
+	"mix.exs":          "Elixir",
+	"Package.swift":    "Swift",
+	"CMakeLists.txt":   "C/C++",
+	"Makefile":         "",
+	"Dockerfile":       "",
+}
+
+// workspaceGitInfo returns the git branch and dirty status for dir, or nil if
+// dir is not inside a git repository or git is not installed.
+func workspaceGitInfo(ctx context.Context, dir string) *WorkspaceGitInfo {
+	status, err := runWorkspaceGit(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return nil
+	}
+	// symbolic-ref also works on an unborn branch, unlike rev-parse; it fails
+	// only when HEAD is detached.
+	branch, err := runWorkspaceGit(ctx, dir, "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		branch = "HEAD"
+	}
+	return &WorkspaceGitInfo{
+		Branch: branch,
+		Dirty:  status != "",
+	}
+}
+
+func runWorkspaceGit(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, workspaceGitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
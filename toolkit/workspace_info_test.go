@@ -0,0 +1,76 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestWorkspaceInfoTool(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "cmd"), 0755))
+
+	tool := NewWorkspaceInfoTool(WorkspaceInfoToolOptions{WorkspaceDir: dir})
+	result, err := tool.Call(context.Background(), &WorkspaceInfoInput{})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	var info WorkspaceInfo
+	assert.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &info))
+	assert.Equal(t, runtime.GOOS, info.OS)
+	assert.Equal(t, runtime.GOARCH, info.Arch)
+	assert.Equal(t, []string{"Go", "JavaScript/TypeScript"}, info.Languages)
+	assert.Equal(t, []string{"go.mod", "package.json"}, info.BuildFiles)
+	assert.Equal(t, []string{"cmd/", "go.mod", "package.json"}, info.Entries)
+	assert.Nil(t, info.Git)
+}
+
+func TestWorkspaceInfoToolCachesUntilRefresh(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewWorkspaceInfoTool(WorkspaceInfoToolOptions{WorkspaceDir: dir})
+
+	first, err := tool.Call(context.Background(), &WorkspaceInfoInput{})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte(""), 0644))
+
+	cached, err := tool.Call(context.Background(), &WorkspaceInfoInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, first.Content[0].Text, cached.Content[0].Text)
+
+	refreshed, err := tool.Call(context.Background(), &WorkspaceInfoInput{Refresh: true})
+	assert.NoError(t, err)
+	assert.Contains(t, refreshed.Content[0].Text, "Cargo.toml")
+}
+
+func TestWorkspaceInfoToolGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q", "-b", "trunk")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		t.Skipf("git init failed: %v", err)
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("hi"), 0644))
+
+	tool := NewWorkspaceInfoTool(WorkspaceInfoToolOptions{WorkspaceDir: dir, MaxEntries: 1})
+	result, err := tool.Call(context.Background(), &WorkspaceInfoInput{})
+	assert.NoError(t, err)
+
+	var info WorkspaceInfo
+	assert.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &info))
+	assert.NotNil(t, info.Git)
+	assert.Equal(t, "trunk", info.Git.Branch)
+	assert.True(t, info.Git.Dirty)
+	assert.Len(t, info.Entries, 1)
+	assert.True(t, info.Truncated)
+}
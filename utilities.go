@@ -18,20 +18,7 @@ func DateTimeString(t time.Time) string {
 func getToolResultContent(callResults []*ToolCallResult) []*llm.ToolResultContent {
 	results := make([]*llm.ToolResultContent, len(callResults))
 	for i, callResult := range callResults {
-		var content any
-		var isError bool
-		if callResult.Result != nil {
-			content = callResult.Result.Content
-			isError = callResult.Result.IsError
-		}
-		// IsError is true if either the tool crashed (Error) or the tool
-		// reported a protocol-level error (Result.IsError).
-		resultContent := &llm.ToolResultContent{
-			ToolUseID: callResult.ID,
-			Content:   content,
-			IsError:   callResult.Error != nil || isError,
-		}
-		results[i] = resultContent
+		results[i] = NewLLMToolResult(callResult.ID, callResult.Result, callResult.Error)
 	}
 	return results
 }